@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/registry"
+)
+
+// targetKubeContext returns spec.kubeContext, or the current kubeconfig context.
+func targetKubeContext(cfg *config.DeploymentConfig) string {
+	if cfg.Spec.KubeContext != "" {
+		return cfg.Spec.KubeContext
+	}
+	return getCurrentContext()
+}
+
+// printStartupBanner summarizes where up/watch are about to deploy, so
+// nobody deploys to the wrong cluster by surprise.
+// builderName is empty when the build is skipped.
+func printStartupBanner(cfg *config.DeploymentConfig, kubeContext, builderName string) {
+	clusterType, _ := registry.NewRegistry(kubeContext, logger).GetClusterType()
+
+	tagPolicy := "content hash (kudev-<hash>)"
+	switch {
+	case cfg.PrebuiltImage() != "":
+		builderName = "none (spec.image.ref)"
+		tagPolicy = cfg.PrebuiltImage()
+	case builderName == "":
+		builderName = "none (--no-build)"
+		tagPolicy = "existing :latest image"
+	}
+
+	fmt.Println("───────────────────────────────────────────────────")
+	fmt.Printf("  Context:   %s\n", kubeContext)
+	fmt.Printf("  Cluster:   %s\n", clusterType)
+	fmt.Printf("  Namespace: %s\n", cfg.Spec.Namespace)
+	if cfg.Profile != "" {
+		fmt.Printf("  Profile:   %s\n", cfg.Profile)
+	}
+	fmt.Printf("  Image tag: %s\n", tagPolicy)
+	fmt.Printf("  Builder:   %s\n", builderName)
+	fmt.Println("───────────────────────────────────────────────────")
+
+	if forceContext {
+		fmt.Printf("\033[31m⚠ --force-context is set: context safety checks are OFF.\n"+
+			"  Deploying to %q, which may not be a local cluster.\033[0m\n", kubeContext)
+	}
+	fmt.Println()
+}
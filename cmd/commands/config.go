@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the .kudev.yaml configuration file",
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite .kudev.yaml in the newest config schema",
+	Long: `Rewrite .kudev.yaml in the newest config schema (` + config.DefaultAPIVersion + `).
+
+Configs written for an older apiVersion keep working: they are upgraded in
+memory every time they are loaded. This command upgrades the file itself,
+so the upgrade and its warning go away.
+
+Supported versions: ` + strings.Join(config.APIVersions(), ", ") + `
+
+Migrated documents are re-serialized, which drops their comments; the
+original file is kept next to it as .kudev.yaml.bak. Documents already in
+the newest schema are left as written.
+
+With --dry-run, the migrated file is printed instead of written.
+
+Examples:
+  kudev config migrate
+  kudev config migrate --config dev.yaml --dry-run`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneAnnotation: "true"},
+	RunE:        runConfigMigrate,
+}
+
+var configSchemaOutput string
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of .kudev.yaml for editors",
+	Long: `Print the JSON Schema of .kudev.yaml (` + config.DefaultAPIVersion + `), so YAML plugins of
+editors and IDEs can complete fields and flag mistakes as you type.
+
+The schema covers structure, enums and ranges; the validation kudev runs
+when loading the config still has the final word (it also checks things
+like the Dockerfile existing).
+
+With the YAML language server (VS Code's YAML extension and others),
+write the schema next to the config and reference it from the file:
+
+  kudev config schema -o .kudev.schema.json
+
+  # yaml-language-server: $schema=.kudev.schema.json
+  apiVersion: ` + config.DefaultAPIVersion + `
+  ...
+
+Examples:
+  kudev config schema
+  kudev config schema -o .kudev.schema.json`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneAnnotation: "true"},
+	RunE:        runConfigSchema,
+}
+
+func init() {
+	configSchemaCmd.Flags().StringVarP(&configSchemaOutput, "output", "o", "", "Write the schema to this file instead of stdout")
+
+	markDryRun(configMigrateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	path := configPath
+	if path == "" {
+		var err error
+		if path, err = config.FindConfigFile(""); err != nil {
+			return err
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	migrated, from, err := config.Migrate(content)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+	if len(from) == 0 {
+		fmt.Printf("✓ %s already uses %s\n", path, config.DefaultAPIVersion)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Print(string(migrated))
+		return nil
+	}
+
+	backup := path + ".bak"
+	if err := os.WriteFile(backup, content, 0644); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, migrated, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	fmt.Printf("✓ Migrated %s from %s to %s\n", path, strings.Join(uniqueStrings(from), ", "), config.DefaultAPIVersion)
+	fmt.Printf("  Original saved as %s\n", backup)
+	return nil
+}
+
+// uniqueStrings returns values without repeats, in order.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	schema, err := config.MarshalSchema()
+	if err != nil {
+		return err
+	}
+
+	if configSchemaOutput == "" {
+		fmt.Print(string(schema))
+		return nil
+	}
+	if err := os.WriteFile(configSchemaOutput, schema, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configSchemaOutput, err)
+	}
+	fmt.Printf("✓ Wrote schema to %s\n", configSchemaOutput)
+	return nil
+}
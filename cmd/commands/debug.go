@@ -0,0 +1,119 @@
+// cmd/commands/debug.go
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/diag"
+	"github.com/nanaki-93/kudev/pkg/state"
+)
+
+var debugDumpCmd = &cobra.Command{
+	Use:   "debug-dump",
+	Short: "Capture goroutine stacks and a heap profile from a running kudev",
+	Long: `Capture goroutine stacks and a heap profile from a running 'kudev watch'
+or 'kudev up' started with --pprof, e.g. when it appears to hang.
+
+The address is read from .kudev/state.json unless --addr is given.
+Both files are written to --output-dir:
+
+  kudev-<timestamp>-goroutines.txt   Stacks of every goroutine
+  kudev-<timestamp>-heap.pprof       Heap profile (go tool pprof <file>)
+
+Examples:
+  kudev watch --pprof localhost:6060   (in one terminal)
+  kudev debug-dump                     (in another)
+  kudev debug-dump --addr localhost:6060 -o /tmp`,
+	RunE: runDebugDump,
+}
+
+var (
+	pprofAddr string
+
+	debugDumpAddr   string
+	debugDumpOutDir string
+)
+
+func init() {
+	debugDumpCmd.Flags().StringVar(&debugDumpAddr, "addr", "", "pprof address of the running kudev (default: from .kudev/state.json)")
+	debugDumpCmd.Flags().StringVarP(&debugDumpOutDir, "output-dir", "o", ".", "Directory to write the dump files to")
+
+	rootCmd.AddCommand(debugDumpCmd)
+}
+
+// addPprofFlag registers --pprof on a long-running command.
+func addPprofFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&pprofAddr, "pprof", "", "Serve net/http/pprof on this localhost address (e.g. localhost:6060); off by default")
+}
+
+// startPprof serves pprof on --pprof and records the address for
+// 'kudev debug-dump'. The returned function stops both.
+func startPprof(ctx context.Context, cfg *config.DeploymentConfig) (func(), error) {
+	if pprofAddr == "" {
+		return func() {}, nil
+	}
+
+	server, err := diag.NewServer(pprofAddr, logger)
+	if err != nil {
+		return nil, err
+	}
+	serveCtx, cancel := context.WithCancel(ctx)
+	if err := server.Start(serveCtx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start pprof endpoint: %w", err)
+	}
+	fmt.Printf("✓ pprof: http://%s/debug/pprof/ (capture with 'kudev debug-dump')\n", server.Addr())
+
+	store := state.NewStore(cfg.ProjectRoot)
+	setDebugAddr(store, server.Addr())
+
+	return func() {
+		cancel()
+		setDebugAddr(store, "")
+	}, nil
+}
+
+// setDebugAddr records addr in the project state; failures only matter
+// to debug-dump, so they are logged, not returned.
+func setDebugAddr(store *state.Store, addr string) {
+	if err := store.Update(func(st *state.State) error {
+		st.DebugAddr = addr
+		return nil
+	}); err != nil {
+		logger.Debug("failed to record pprof address", "error", err)
+	}
+}
+
+func runDebugDump(cmd *cobra.Command, args []string) error {
+	addr := debugDumpAddr
+	if addr == "" {
+		st, err := state.NewStore(getLoadedConfig().ProjectRoot).Load()
+		if err != nil {
+			return err
+		}
+		if st.DebugAddr == "" {
+			return fmt.Errorf("no running kudev with pprof found\n\n" +
+				"Start it with 'kudev watch --pprof localhost:6060', or pass --addr")
+		}
+		addr = st.DebugAddr
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+
+	dump, err := diag.Capture(ctx, addr, debugDumpOutDir, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to capture dump from %s: %w\n\n"+
+			"Is 'kudev watch' or 'kudev up' still running with --pprof?", addr, err)
+	}
+
+	fmt.Printf("✓ Goroutine stacks: %s\n", dump.Goroutines)
+	fmt.Printf("✓ Heap profile:     %s (view with: go tool pprof %s)\n", dump.Heap, dump.Heap)
+	return nil
+}
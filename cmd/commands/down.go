@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
 
 	"github.com/nanaki-93/kudev/pkg/deployer"
 	"github.com/nanaki-93/kudev/templates"
@@ -17,17 +19,35 @@ var downCmd = &cobra.Command{
 This command:
 1. Deletes the Deployment
 2. Deletes the Service
-3. Waits for pods to terminate`,
+3. Deletes ConfigMaps, Secrets, Ingresses and HPAs labelled for the app
+4. Waits for pods to terminate
+
+With --dry-run, the resources that would be removed are listed and
+nothing is deleted.
+
+With --all, every kudev-managed resource in the namespace is removed
+(not only this app's). The resources are listed first and the command
+returns once their pods are gone:
+  kudev down --all --namespace dev
+
+Works without .kudev.yaml when the app is given by flags:
+  kudev down --name myapp --namespace dev`,
 	RunE: runDown,
 }
 
 var (
 	forceDelete bool
+	downAll     bool
 )
 
 func init() {
 	downCmd.Flags().BoolVar(&forceDelete, "force", false, "Force delete without confirmation")
+	downCmd.Flags().BoolVarP(&forceDelete, "yes", "y", false, "Delete without confirmation (same as --force)")
+	downCmd.Flags().BoolVar(&downAll, "all", false, "Delete every kudev-managed resource in the namespace")
 
+	addTargetFlags(downCmd)
+
+	markDryRun(downCmd)
 	rootCmd.AddCommand(downCmd)
 }
 
@@ -38,6 +58,14 @@ func runDown(cmd *cobra.Command, args []string) error {
 	fmt.Println("Loading configuration...")
 	cfg := getLoadedConfig()
 
+	if dryRun {
+		return runDownDryRun(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
+	}
+
+	if downAll {
+		return runDownAll(ctx, cfg.Spec.Namespace)
+	}
+
 	// 2. Confirm deletion (unless --force)
 	if !forceDelete {
 		fmt.Printf("This will delete deployment '%s' in namespace '%s'\n",
@@ -56,15 +84,10 @@ func runDown(cmd *cobra.Command, args []string) error {
 	// 3. Delete resources
 	fmt.Println("Deleting resources...")
 
-	clientset, _, err := getKubernetesClient()
+	dep, err := newCleanupDeployer()
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		return err
 	}
-	renderer, _ := deployer.NewRenderer(
-		templates.DeploymentTemplate,
-		templates.ServiceTemplate,
-	)
-	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
 
 	if err := dep.Delete(ctx, cfg.Metadata.Name, cfg.Spec.Namespace); err != nil {
 		return fmt.Errorf("failed to delete: %w", err)
@@ -79,3 +102,115 @@ func runDown(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// newCleanupDeployer returns a deployer that also removes the ConfigMaps,
+// Secrets, Ingresses, ... kudev created (see deployer.DefaultCleanupResources).
+func newCleanupDeployer() (*deployer.KubernetesDeployer, error) {
+	clientset, restConfig, err := getKubernetesClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	renderer, _ := deployer.NewRenderer(
+		templates.DeploymentTemplate,
+		templates.ServiceTemplate,
+	)
+	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+	dep.SetDynamicClient(dynamicClient)
+	return dep, nil
+}
+
+// runDownDryRun lists what 'kudev down' (or 'kudev down --all') would
+// delete without calling the delete API.
+func runDownDryRun(ctx context.Context, appName, namespace string) error {
+	dep, err := newCleanupDeployer()
+	if err != nil {
+		return err
+	}
+
+	var resources []deployer.ManagedResource
+	if downAll {
+		resources, err = dep.ListManaged(ctx, namespace)
+	} else {
+		resources, err = dep.ListApp(ctx, appName, namespace)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if len(resources) == 0 {
+		fmt.Printf("Dry run: nothing to delete in namespace '%s'\n", namespace)
+		return nil
+	}
+
+	fmt.Printf("Dry run: %d resource(s) would be deleted:\n", len(resources))
+	printResources(resources, namespace)
+	return nil
+}
+
+// printResources lists resources as kind/name with their namespace.
+func printResources(resources []deployer.ManagedResource, namespace string) {
+	for _, r := range resources {
+		fmt.Printf("  - %s (namespace %s)\n", r, namespace)
+	}
+}
+
+// runDownAll deletes every kudev-managed resource in namespace, with a
+// confirmation prompt and per-resource progress, then waits for the pods.
+func runDownAll(ctx context.Context, namespace string) error {
+	dep, err := newCleanupDeployer()
+	if err != nil {
+		return err
+	}
+
+	resources, err := dep.ListManaged(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	if len(resources) == 0 {
+		fmt.Printf("No kudev-managed resources in namespace '%s'\n", namespace)
+		return nil
+	}
+
+	fmt.Printf("This will delete %d kudev-managed resource(s) in namespace '%s':\n", len(resources), namespace)
+	printResources(resources, namespace)
+
+	if !forceDelete {
+		fmt.Print("Continue? [y/N]: ")
+
+		var response string
+		fmt.Scanln(&response)
+
+		if response != "y" && response != "Y" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Println()
+	deleteErr := dep.DeleteManaged(ctx, namespace, resources, func(r deployer.ManagedResource, err error) {
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", r, err)
+			return
+		}
+		fmt.Printf("✓ %s deleted\n", r)
+	})
+
+	fmt.Println("Waiting for pods to terminate...")
+	if err := dep.WaitForDeletion(ctx, deployer.WaitOptions{Namespace: namespace}); err != nil {
+		return fmt.Errorf("failed waiting for deletion: %w", err)
+	}
+
+	if deleteErr != nil {
+		return fmt.Errorf("failed to delete: %w", deleteErr)
+	}
+
+	fmt.Println()
+	fmt.Printf("Namespace '%s' is clean\n", namespace)
+	return nil
+}
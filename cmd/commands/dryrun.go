@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// validateServer dry-runs each deploy's objects on the server before
+// applying them (see deployer.SetServerValidation).
+var validateServer bool
+
+// addValidateServerFlag registers --validate-server on cmd.
+func addValidateServerFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&validateServer, "validate-server", false, "Validate manifests with a server-side dry run before each deploy (surfaces admission webhook denials first)")
+}
+
+// runDryRun prints what up/watch would build, load and deploy, then
+// validates the manifests with a server-side dry run. Docker is never
+// called and nothing in the cluster changes.
+// builderName is empty when the build is skipped (--no-build).
+func runDryRun(ctx context.Context, cfg *config.DeploymentConfig, kubeContext, builderName string) error {
+	prebuilt := cfg.PrebuiltImage() != ""
+	imageRef := ""
+	if builderName == "" && !prebuilt {
+		imageRef = fmt.Sprintf("%s:latest", cfg.Spec.ImageName)
+	}
+
+	renderer, data, err := manifestData(ctx, cfg, imageRef)
+	if err != nil {
+		return err
+	}
+	manifests, err := renderer.RenderAll(data)
+	if err != nil {
+		return fmt.Errorf("failed to render manifests: %w", err)
+	}
+
+	fmt.Println("Dry run: nothing will be built, loaded or deployed.")
+	fmt.Println()
+	switch {
+	case prebuilt:
+		fmt.Printf("Would use:    %s (spec.image.ref)\n", data.ImageRef)
+	case builderName != "":
+		fmt.Printf("Would build:  %s (%s)\n", data.ImageRef, builderName)
+		fmt.Printf("  Context:    %s\n", cfg.BuildContextDir())
+		fmt.Printf("  Dockerfile: %s\n", cfg.BuildDockerfilePath())
+		fmt.Printf("Would load:   %s into %s\n", data.ImageRef, kubeContext)
+	default:
+		fmt.Printf("Would use:    %s (--no-build)\n", data.ImageRef)
+	}
+	fmt.Printf("Would deploy: %s to namespace %s\n", cfg.Metadata.Name, cfg.Spec.Namespace)
+	fmt.Println()
+	fmt.Println(logging.Redact(manifests))
+
+	// Let the API server validate the manifests and run admission
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		fmt.Printf("⚠ Skipping server-side validation: %v\n", err)
+		return nil
+	}
+	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+
+	status, err := dep.Upsert(ctx, deployer.DeploymentOptions{
+		Config:    cfg,
+		ImageRef:  data.ImageRef,
+		ImageHash: data.ImageHash,
+		DryRun:    true,
+	})
+	if err != nil {
+		return fmt.Errorf("dry run failed: %w", err)
+	}
+
+	fmt.Printf("✓ %s\n", status.Message)
+	return nil
+}
@@ -0,0 +1,72 @@
+// cmd/commands/export.go
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/export"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export production-ready manifests",
+	Long: `Generate a starting point for real deployment pipelines from .kudev.yaml.
+
+Formats:
+  plain       Rendered Deployment and Service manifests
+  kustomize   A kustomize base with image and replica overrides
+  helm        A Helm chart with image, replicas and env in values.yaml
+
+The image defaults to the current source hash tag; override with --image
+once the image is pushed to a real registry.
+
+Examples:
+  kudev export --format helm
+  kudev export --format kustomize --output deploy/
+  kudev export --image ghcr.io/org/myapp:1.0.0`,
+	RunE: runExport,
+}
+
+var (
+	exportFormat string
+	exportOutput string
+	exportImage  string
+)
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "plain", "Output format: plain, kustomize, helm")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "deploy", "Output directory")
+	exportCmd.Flags().StringVar(&exportImage, "image", "", "Image reference to use (default: <imageName>:<kudev tag>)")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+
+	format, err := export.ParseFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+
+	renderer, data, err := manifestData(ctx, cfg, exportImage)
+	if err != nil {
+		return err
+	}
+
+	written, err := export.NewExporter(renderer).Export(format, data, exportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	fmt.Printf("✓ Exported %s manifests for '%s'\n", format, cfg.Metadata.Name)
+	for _, path := range written {
+		fmt.Printf("  %s\n", path)
+	}
+
+	return nil
+}
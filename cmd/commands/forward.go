@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/portfwd"
+)
+
+var forwardCmd = &cobra.Command{
+	Use:     "portfwd",
+	Aliases: []string{"forward"},
+	Short:   "Forward local ports to services of the project",
+	Long: `Forward local ports to a deployed service in .kudev.yaml, or to all of
+them with --all.
+
+With a multi-service config file (several '---' separated configs),
+--all forwards every service at once, so the whole stack is reachable
+with one command. A service keeps its configured local ports when they
+are free; busy ports, and ports already given to an earlier service,
+move to a free port nearby (unless --strict-ports). A table lists where
+each service is reached.
+
+Examples:
+  kudev portfwd                 # the only service of the file
+  kudev portfwd --service api   # one service
+  kudev portfwd --all           # every service
+
+Press Ctrl+C to stop forwarding.`,
+	Annotations: map[string]string{projectAnnotation: "true"},
+	RunE:        runForward,
+}
+
+// forwardAll forwards every service of the project.
+var forwardAll bool
+
+func init() {
+	forwardCmd.Flags().BoolVar(&forwardAll, "all", false, "Forward every service of the project")
+	addStrictPortsFlag(forwardCmd)
+
+	rootCmd.AddCommand(forwardCmd)
+}
+
+// serviceForward is the forward of one service of the project.
+type serviceForward struct {
+	cfg   *config.DeploymentConfig
+	ports []portfwd.Port
+
+	// moved maps a substituted local port to the configured one
+	moved map[int32]int32
+
+	forwarder *portfwd.KubernetesPortForwarder
+	err       error
+}
+
+func runForward(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	services := loadedProject.Services
+	if !forwardAll {
+		svc, err := loadedProject.Select(serviceName)
+		if err != nil {
+			return fmt.Errorf("%w, or forward them all with --all", err)
+		}
+		services = []*config.DeploymentConfig{svc}
+	} else if serviceName != "" {
+		return fmt.Errorf("--all and --service cannot be used together")
+	}
+
+	for _, svc := range services {
+		if err := svc.ApplyInstance(instanceName); err != nil {
+			return err
+		}
+		if err := resolveLocalPort(svc); err != nil {
+			return err
+		}
+	}
+
+	forwards, err := planForwards(services)
+	if err != nil {
+		return err
+	}
+
+	running, err := startForwards(ctx, forwards)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	printForwardTable(forwards)
+	fmt.Println()
+
+	if running == 0 {
+		return fmt.Errorf("no service could be forwarded")
+	}
+
+	fmt.Println("Press Ctrl+C to stop forwarding...")
+	<-ctx.Done()
+
+	fmt.Println("\nShutting down...")
+	stopForwards(forwards)
+	return nil
+}
+
+// startForwards starts the planned forwards of deployed services. A
+// service that cannot be forwarded keeps the reason in its err; it returns
+// how many services are forwarded.
+func startForwards(ctx context.Context, forwards []*serviceForward) (int, error) {
+	clientset, restConfig, err := getKubernetesClient()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	dep := deployer.NewKubernetesDeployer(clientset, nil, logger)
+
+	var running int
+	for _, f := range forwards {
+		name, namespace := f.cfg.Metadata.Name, f.cfg.Spec.Namespace
+		if len(f.ports) == 0 {
+			f.err = fmt.Errorf("no TCP ports to forward")
+			continue
+		}
+		// Pod discovery would wait minutes for a service that isn't deployed
+		if _, err := dep.Status(ctx, name, namespace); err != nil {
+			f.err = err
+			continue
+		}
+
+		fmt.Printf("✓ Forwarding %s...\n", name)
+		f.forwarder = portfwd.NewKubernetesPortForwarder(clientset, restConfig, logger)
+		f.forwarder.Address = f.cfg.Spec.PortForward.EffectiveAddress()
+		if err := f.forwarder.ForwardPorts(ctx, name, namespace, f.ports); err != nil {
+			f.err = err
+			continue
+		}
+		for _, p := range f.ports {
+			claimPort(p.Local, name, f.cfg.ProjectRoot)
+		}
+		registerHostname(f.cfg)
+		running++
+	}
+	return running, nil
+}
+
+// stopForwards stops the running forwards and reports their reconnects.
+func stopForwards(forwards []*serviceForward) {
+	for _, f := range forwards {
+		if f.forwarder == nil || f.err != nil {
+			continue
+		}
+		f.forwarder.Stop()
+		printForwardStats(f.forwarder)
+	}
+	fmt.Println("✓ Port forwards stopped")
+}
+
+// planForwards assigns collision-free local ports to the TCP ports of
+// services (see portfwd.PlanPorts). With --strict-ports a busy or
+// repeated port is an error instead of moving.
+func planForwards(services []*config.DeploymentConfig) ([]*serviceForward, error) {
+	requests := make([]portfwd.PortRequest, 0, len(services))
+	for _, svc := range services {
+		req := portfwd.PortRequest{
+			Service: svc.Metadata.Name,
+			Address: svc.Spec.PortForward.EffectiveAddress(),
+		}
+		for _, m := range svc.PortMappings() {
+			if m.EffectiveProtocol() != "TCP" {
+				fmt.Printf("⚠ %s: %s port %d not forwarded (port-forward is TCP-only; 'kudev up' exposes it through a NodePort)\n",
+					svc.Metadata.Name, m.EffectiveProtocol(), m.Container)
+				continue
+			}
+			req.Ports = append(req.Ports, portfwd.Port{Local: m.Local, Pod: m.Container, Protocol: corev1.ProtocolTCP})
+		}
+		requests = append(requests, req)
+	}
+
+	plans, err := portfwd.PlanPorts(requests, strictPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	forwards := make([]*serviceForward, len(services))
+	for i, svc := range services {
+		forwards[i] = &serviceForward{cfg: svc, ports: plans[i].Ports, moved: plans[i].Moved}
+	}
+	return forwards, nil
+}
+
+// printForwardTable lists every forwarded port, and the services that
+// could not be forwarded with the reason.
+func printForwardTable(forwards []*serviceForward) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tLOCAL\tPOD PORT\tNOTE\t")
+	for _, f := range forwards {
+		name := f.cfg.Metadata.Name
+		if f.err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t❌ %v\t\n", name, f.err)
+			continue
+		}
+		for _, p := range f.ports {
+			note := ""
+			if configured, ok := f.moved[p.Local]; ok {
+				note = "⚠ moved from " + strconv.Itoa(int(configured))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t\n", name, forwardHostPort(f.cfg, p.Local), p.Pod, note)
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,71 @@
+// cmd/commands/freeze.go
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+)
+
+var freezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Pause automatic redeploys from 'kudev watch'",
+	Long: `Mark the deployment as frozen so 'kudev watch' stops replacing pods.
+
+Watch keeps rebuilding on changes and reports when each build is ready,
+but does not deploy it. Useful while a debugger is attached to the pod.
+
+Run 'kudev unfreeze' to resume; the next rebuild is deployed.
+
+Works without .kudev.yaml when the app is given by flags:
+  kudev freeze --name myapp --namespace dev`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFreeze(cmd, true)
+	},
+}
+
+var unfreezeCmd = &cobra.Command{
+	Use:   "unfreeze",
+	Short: "Resume automatic redeploys from 'kudev watch'",
+	Long:  `Remove the freeze set by 'kudev freeze'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFreeze(cmd, false)
+	},
+}
+
+func init() {
+	addTargetFlags(freezeCmd)
+	addTargetFlags(unfreezeCmd)
+
+	rootCmd.AddCommand(freezeCmd)
+	rootCmd.AddCommand(unfreezeCmd)
+}
+
+func runFreeze(cmd *cobra.Command, frozen bool) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	renderer, err := deployer.NewRendererForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create renderer: %w", err)
+	}
+	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+
+	if err := dep.SetFrozen(ctx, cfg.Metadata.Name, cfg.Spec.Namespace, frozen); err != nil {
+		return err
+	}
+
+	if frozen {
+		fmt.Printf("❄ %s is frozen: 'kudev watch' will build but not redeploy\n", cfg.Metadata.Name)
+	} else {
+		fmt.Printf("✓ %s is unfrozen: 'kudev watch' will redeploy on the next change\n", cfg.Metadata.Name)
+	}
+	return nil
+}
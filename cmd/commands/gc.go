@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete kudev apps whose spec.ttl has expired",
+	Long: `Delete kudev apps whose spec.ttl has expired.
+
+Apps opt in with spec.ttl in .kudev.yaml:
+
+  spec:
+    ttl: 72h
+
+Every deploy pushes the expiry back, so only apps nobody deployed for
+that long are removed, with everything 'kudev down' would delete. Apps
+without spec.ttl are never touched.
+
+By default every namespace is checked; limit it with --namespace.
+With --dry-run, the expired apps are listed and nothing is deleted.
+
+To do this periodically inside the cluster, see 'kudev install-reaper'.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{clusterAnnotation: "true"},
+	RunE:        runGC,
+}
+
+var (
+	gcNamespace string
+	gcYes       bool
+)
+
+func init() {
+	gcCmd.Flags().StringVarP(&gcNamespace, "namespace", "n", "", "Only check this namespace (default: all namespaces)")
+	gcCmd.Flags().BoolVarP(&gcYes, "yes", "y", false, "Delete without confirmation")
+
+	markDryRun(gcCmd)
+	rootCmd.AddCommand(gcCmd)
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	dep, err := newCleanupDeployer()
+	if err != nil {
+		return err
+	}
+
+	expired, err := dep.ExpiredApps(ctx, gcNamespace, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(expired) == 0 {
+		fmt.Println("✓ No expired kudev apps")
+		return nil
+	}
+
+	fmt.Printf("%d expired kudev app(s):\n", len(expired))
+	for _, app := range expired {
+		fmt.Printf("  %s/%s (expired %s ago)\n", app.Namespace, app.Name, formatAge(app.ExpiresAt))
+	}
+
+	if dryRun {
+		fmt.Println()
+		fmt.Println("Dry run: nothing was deleted")
+		return nil
+	}
+
+	if !gcYes {
+		fmt.Print("Delete them? [y/N]: ")
+
+		var response string
+		fmt.Scanln(&response)
+
+		if response != "y" && response != "Y" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Println()
+	failed := 0
+	for _, app := range expired {
+		if err := dep.Delete(ctx, app.Name, app.Namespace); err != nil {
+			fmt.Printf("❌ %s/%s: %v\n", app.Namespace, app.Name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s/%s deleted\n", app.Namespace, app.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d expired apps", failed, len(expired))
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// cmd/commands/history.go
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/state"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show rebuild and deploy history",
+	Long: `Show the deploys and failures recorded by 'kudev watch'.
+
+Events are stored in .kudev/state.json in the project root.
+
+Examples:
+  kudev history                        Last 20 events
+  kudev history -n 50                  Last 50 events
+  kudev history --timeline             Today's dev session
+  kudev history --timeline --date 2026-03-10`,
+	RunE: runHistory,
+}
+
+var (
+	historyLimit    int
+	historyTimeline bool
+	historyDate     string
+)
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Number of events to show")
+	historyCmd.Flags().BoolVar(&historyTimeline, "timeline", false, "Show a day's session with failure streaks and cycle times")
+	historyCmd.Flags().StringVar(&historyDate, "date", "", "Day to show with --timeline (YYYY-MM-DD, default: today)")
+
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	cfg := getLoadedConfig()
+
+	st, err := state.NewStore(cfg.ProjectRoot).Load()
+	if err != nil {
+		return err
+	}
+
+	if historyTimeline {
+		day := time.Now()
+		if historyDate != "" {
+			day, err = time.ParseInLocation("2006-01-02", historyDate, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --date %q (expected YYYY-MM-DD): %w", historyDate, err)
+			}
+		}
+		printTimeline(state.BuildTimeline(st.Events, day))
+		return nil
+	}
+
+	if len(st.Events) == 0 {
+		fmt.Println("No history yet. Run 'kudev watch' to start recording.")
+		return nil
+	}
+
+	events := st.Events
+	if historyLimit > 0 && len(events) > historyLimit {
+		events = events[len(events)-historyLimit:]
+	}
+	for _, e := range events {
+		printHistoryEvent(e, "2006-01-02 15:04:05")
+	}
+	return nil
+}
+
+func printTimeline(tl *state.Timeline) {
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Printf("  Dev session %s\n", tl.Day.Format("Mon 2006-01-02"))
+	if len(tl.Events) > 0 {
+		first := tl.Events[0].Time
+		last := tl.Events[len(tl.Events)-1].Time
+		fmt.Printf("  %s → %s\n", first.Local().Format("15:04"), last.Local().Format("15:04"))
+	}
+	fmt.Println("═══════════════════════════════════════════════════")
+
+	if len(tl.Events) == 0 {
+		fmt.Println("No events recorded on this day.")
+		return
+	}
+
+	for _, e := range tl.Events {
+		printHistoryEvent(e, "15:04:05")
+	}
+
+	fmt.Println()
+	fmt.Println("Summary:")
+	fmt.Printf("  Deploys:   %d\n", tl.Deploys)
+	fmt.Printf("  Failures:  %d\n", tl.Failures)
+	if tl.Rollbacks > 0 {
+		fmt.Printf("  Rollbacks: %d\n", tl.Rollbacks)
+	}
+	if tl.AvgCycle > 0 {
+		fmt.Printf("  Avg cycle: %s\n", tl.AvgCycle.Round(100*time.Millisecond))
+	}
+
+	if len(tl.Streaks) > 0 {
+		fmt.Println()
+		fmt.Println("Failure streaks:")
+		for _, s := range tl.Streaks {
+			fmt.Printf("  %s–%s  %d failures in a row\n",
+				s.Start.Local().Format("15:04"),
+				s.End.Local().Format("15:04"),
+				s.Count,
+			)
+		}
+	}
+}
+
+func printHistoryEvent(e state.Event, timeLayout string) {
+	ts := e.Time.Local().Format(timeLayout)
+
+	switch e.Type {
+	case state.EventDeploy:
+		fmt.Printf("  %s  \033[32m✓ deploy\033[0m    %s  %s\n", ts, e.Hash, e.Duration().Round(100*time.Millisecond))
+	case state.EventFailure:
+		fmt.Printf("  %s  \033[31m✗ failure\033[0m   %s: %s\n", ts, e.Stage, firstLine(e.Message))
+	case state.EventRollback:
+		fmt.Printf("  %s  \033[33m↺ rollback\033[0m  %s\n", ts, e.Hash)
+	default:
+		fmt.Printf("  %s  %s  %s\n", ts, e.Type, e.Message)
+	}
+}
+
+// firstLine truncates multi-line error messages for one-line display.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
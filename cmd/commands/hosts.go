@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/hosts"
+)
+
+var hostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "Manage <app>.kudev.local hostnames",
+	Long: `List and remove the hostnames kudev registers in the system hosts file.
+
+With spec.portForward.hosts set, 'kudev up', 'watch' and 'forward'
+register <metadata.name>.kudev.local → 127.0.0.1 (or the forward's
+address), so multi-service frontends can use hostnames that mirror
+cluster DNS:
+
+  spec:
+    portForward:
+      hosts: true
+
+Entries are kept in a marked block of the hosts file; the rest of the
+file is never changed. Editing it may prompt for sudo.`,
+}
+
+var hostsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered hostnames",
+	Args:  cobra.NoArgs,
+	RunE:  runHostsList,
+}
+
+var hostsRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the project's hostnames (every kudev hostname with --all)",
+	Args:  cobra.NoArgs,
+	RunE:  runHostsRemove,
+}
+
+var hostsRemoveAll bool
+
+func init() {
+	hostsRemoveCmd.Flags().BoolVar(&hostsRemoveAll, "all", false, "Remove the hostnames of every kudev project")
+
+	for _, cmd := range []*cobra.Command{hostsListCmd, hostsRemoveCmd} {
+		cmd.Annotations = map[string]string{projectAnnotation: "true"}
+	}
+	hostsCmd.AddCommand(hostsListCmd, hostsRemoveCmd)
+	rootCmd.AddCommand(hostsCmd)
+}
+
+func runHostsList(cmd *cobra.Command, args []string) error {
+	file := hosts.NewFile(hosts.DefaultPath())
+	entries, err := file.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No kudev hostnames in %s\n", file.Path())
+		return nil
+	}
+
+	for _, e := range entries {
+		fmt.Printf("  %s → %s\n", e.Hostname, e.Address)
+	}
+	return nil
+}
+
+func runHostsRemove(cmd *cobra.Command, args []string) error {
+	file := hosts.NewFile(hosts.DefaultPath())
+
+	var names []string
+	if !hostsRemoveAll {
+		for _, svc := range loadedProject.Services {
+			if err := svc.ApplyInstance(instanceName); err != nil {
+				return err
+			}
+			names = append(names, hosts.Hostname(svc.Metadata.Name))
+		}
+	}
+
+	changed, err := file.Remove(names...)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Println("No hostnames to remove")
+		return nil
+	}
+	fmt.Printf("✓ Hostnames removed from %s\n", file.Path())
+	return nil
+}
@@ -0,0 +1,219 @@
+// cmd/commands/image.go
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/hash"
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage images built by kudev",
+	Long: `List, inspect and load the local images kudev has built.
+
+A tag can be given as the bare kudev tag (kudev-a1b2c3d4), in which case
+spec.imageName is used as the repository, or as a full image reference.
+
+Examples:
+  kudev image list
+  kudev image inspect kudev-a1b2c3d4
+  kudev image load kudev-a1b2c3d4`,
+}
+
+var imageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List kudev-built images with hash, age and size",
+	Args:  cobra.NoArgs,
+	RunE:  runImageList,
+}
+
+var imageInspectCmd = &cobra.Command{
+	Use:   "inspect <tag>",
+	Short: "Show details about a kudev-built image",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImageInspect,
+}
+
+var imageLoadCmd = &cobra.Command{
+	Use:   "load <tag>",
+	Short: "Load an image into the current cluster",
+	Long: `Load a local image into the current cluster (kind, minikube or
+Docker Desktop) without building or deploying.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImageLoad,
+}
+
+var (
+	imageListAll bool
+)
+
+func init() {
+	imageListCmd.Flags().BoolVarP(&imageListAll, "all", "a", false, "List kudev images from every repository, not just spec.imageName")
+
+	imageCmd.AddCommand(imageListCmd)
+	imageCmd.AddCommand(imageInspectCmd)
+	imageCmd.AddCommand(imageLoadCmd)
+	rootCmd.AddCommand(imageCmd)
+}
+
+func runImageList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+
+	repository := cfg.Spec.ImageName
+	if imageListAll {
+		repository = ""
+	}
+
+	dockerBuilder, err := newDockerBuilder(cfg)
+	if err != nil {
+		return err
+	}
+	images, err := dockerBuilder.ListImages(ctx, repository)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		fmt.Println("No kudev images found. Run 'kudev up' to build one.")
+		return nil
+	}
+
+	// Mark the image matching the current source tree
+	currentHash, err := hash.ForConfig(cfg).Calculate(ctx)
+	if err != nil {
+		logger.Debug("failed to calculate source hash", "error", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tHASH\tAGE\tSIZE\t")
+	for _, img := range images {
+		marker := ""
+		if img.Info.Hash == currentHash {
+			marker = "(current)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", img.Ref(), img.Info.Hash, formatAge(img.CreatedAt), img.Size, marker)
+	}
+	return w.Flush()
+}
+
+func runImageInspect(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ref := imageRefFromArg(args[0])
+
+	dockerBuilder, err := newDockerBuilder(getLoadedConfig())
+	if err != nil {
+		return err
+	}
+	details, err := dockerBuilder.InspectImage(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Printf("  Image:    %s\n", ref)
+	fmt.Printf("  ID:       %s\n", details.ID)
+	if info, err := builder.ParseTagInfo(imageTag(ref)); err == nil {
+		fmt.Printf("  Hash:     %s\n", info.Hash)
+		if info.HasTimestamp {
+			fmt.Printf("  Forced:   %s\n", info.Timestamp.Format(time.RFC3339))
+		}
+	} else {
+		fmt.Println("  Hash:     (not a kudev tag)")
+	}
+	fmt.Printf("  Created:  %s (%s ago)\n", details.Created.Local().Format("2006-01-02 15:04:05"), formatAge(details.Created))
+	fmt.Printf("  Size:     %.1f MB\n", float64(details.Size)/(1024*1024))
+	fmt.Printf("  Platform: %s/%s\n", details.Os, details.Architecture)
+	if len(details.RepoTags) > 1 {
+		fmt.Printf("  Tags:     %s\n", strings.Join(details.RepoTags, ", "))
+	}
+	fmt.Println("═══════════════════════════════════════════════════")
+
+	if len(details.Config.Labels) > 0 {
+		fmt.Println()
+		fmt.Println("Labels:")
+		keys := make([]string, 0, len(details.Config.Labels))
+		for k := range details.Config.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, details.Config.Labels[k])
+		}
+	}
+
+	return nil
+}
+
+func runImageLoad(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+	ref := imageRefFromArg(args[0])
+
+	// Fail early with a clear message rather than a loader error
+	dockerBuilder, err := newDockerBuilder(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := dockerBuilder.InspectImage(ctx, ref); err != nil {
+		return err
+	}
+
+	kubeContext := targetKubeContext(cfg)
+
+	fmt.Printf("✓ Loading %s into %s...\n", ref, kubeContext)
+	if err := newRegistry(kubeContext, dockerBuilder).Load(ctx, ref); err != nil {
+		return fmt.Errorf("failed to load image: %w", err)
+	}
+	fmt.Println("✓ Image loaded")
+	return nil
+}
+
+// imageRefFromArg expands a bare tag to <spec.imageName>:<tag>.
+func imageRefFromArg(arg string) string {
+	if strings.Contains(arg, ":") || strings.Contains(arg, "/") {
+		return arg
+	}
+	return getLoadedConfig().Spec.ImageName + ":" + arg
+}
+
+// imageTag returns the tag part of an image reference.
+func imageTag(ref string) string {
+	idx := strings.LastIndex(ref, ":")
+	if idx == -1 || strings.Contains(ref[idx:], "/") {
+		return ""
+	}
+	return ref[idx+1:]
+}
+
+// formatAge renders how long ago t was, e.g. "3h" or "2d".
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+
+	return formatDuration(time.Since(t))
+}
+
+// formatDuration renders d in its largest whole unit, e.g. "3h" or "2d".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/framework"
 	"github.com/nanaki-93/kudev/pkg/logging"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +24,13 @@ This command guides you through setup:
   - Kubernetes namespace
   - Container ports
 
+The port defaults follow the framework detected in the current
+directory: Spring Boot 8080 (or its server.port), Express and Next.js
+3000, Rails 3000, Flask 5000, Django and FastAPI 8000.
+
+If the local port is already used by another kudev project on this
+machine (see ~/.kudev/ports.json), a free port is suggested.
+
 The configuration is saved to .kudev.yaml in the current directory.
 
 Examples:
@@ -57,6 +65,10 @@ Examples:
 			return fmt.Errorf("failed to save configuration: %w", err)
 		}
 
+		if projectRoot, err := os.Getwd(); err == nil && !cfg.Spec.AutoLocalPort {
+			claimPort(cfg.Spec.LocalPort, cfg.Metadata.Name, projectRoot)
+		}
+
 		logger.Info(
 			"configuration file created successfully",
 			"path", configPath,
@@ -117,11 +129,20 @@ func interactiveSetup(appName string) (*config.DeploymentConfig, error) {
 		}
 	}
 
+	// Port defaults follow the detected framework
+	defaultPort := int32(8080)
+	if projectRoot, err := os.Getwd(); err == nil {
+		if fw := framework.Detect(projectRoot); fw != nil {
+			fmt.Printf("✓ Detected %s (%s), listening on port %d by default\n", fw.Name, fw.Source, fw.Port)
+			defaultPort = fw.Port
+		}
+	}
+
 	// Service port
-	fmt.Print("Container port [8080]: ")
+	fmt.Printf("Container port [%d]: ", defaultPort)
 	servicePortStr, _ := reader.ReadString('\n')
 	servicePortStr = strings.TrimSpace(servicePortStr)
-	servicePort := int32(8080)
+	servicePort := defaultPort
 	if servicePortStr != "" {
 		if p, err := strconv.ParseInt(servicePortStr, 10, 32); err == nil {
 			servicePort = int32(p)
@@ -129,19 +150,38 @@ func interactiveSetup(appName string) (*config.DeploymentConfig, error) {
 	}
 
 	// Local port
-	fmt.Print("Local port for forwarding [8080]: ")
+	fmt.Printf("Local port for forwarding, or auto [%d]: ", servicePort)
 	localPortStr, _ := reader.ReadString('\n')
 	localPortStr = strings.TrimSpace(localPortStr)
-	localPort := int32(8080)
-	if localPortStr != "" {
+	localPort := servicePort
+	autoLocalPort := localPortStr == config.LocalPortAuto
+	if localPortStr != "" && !autoLocalPort {
 		if p, err := strconv.ParseInt(localPortStr, 10, 32); err == nil {
 			localPort = int32(p)
 		}
 	}
+	if autoLocalPort {
+		localPort = 0
+	}
+
+	// Another kudev project may already forward this port
+	if projectRoot, err := os.Getwd(); err == nil && !autoLocalPort {
+		if warning, free := portConflict(localPort, projectRoot); warning != "" {
+			fmt.Printf("⚠ %s\n", warning)
+			if free != 0 {
+				fmt.Printf("Use local port %d instead? [Y/n]: ", free)
+				answer, _ := reader.ReadString('\n')
+				answer = strings.ToLower(strings.TrimSpace(answer))
+				if answer == "" || answer == "y" || answer == "yes" {
+					localPort = free
+				}
+			}
+		}
+	}
 
 	// Build config
 	cfg := &config.DeploymentConfig{
-		APIVersion: "kudev.io/v1alpha1",
+		APIVersion: config.DefaultAPIVersion,
 		Kind:       "DeploymentConfig",
 		Metadata: config.MetadataConfig{
 			Name: appName,
@@ -152,6 +192,7 @@ func interactiveSetup(appName string) (*config.DeploymentConfig, error) {
 			Namespace:      namespace,
 			Replicas:       replicas,
 			LocalPort:      localPort,
+			AutoLocalPort:  autoLocalPort,
 			ServicePort:    servicePort,
 		},
 	}
@@ -166,7 +207,11 @@ func interactiveSetup(appName string) (*config.DeploymentConfig, error) {
 	fmt.Printf("  Namespace: %s\n", cfg.Spec.Namespace)
 	fmt.Printf("  Replicas: %d\n", cfg.Spec.Replicas)
 	fmt.Printf("  Service Port: %d\n", cfg.Spec.ServicePort)
-	fmt.Printf("  Local Port: %d\n", cfg.Spec.LocalPort)
+	if cfg.Spec.AutoLocalPort {
+		fmt.Printf("  Local Port: %s\n", config.LocalPortAuto)
+	} else {
+		fmt.Printf("  Local Port: %d\n", cfg.Spec.LocalPort)
+	}
 	fmt.Println(strings.Repeat("=", 40))
 
 	return cfg, nil
@@ -0,0 +1,99 @@
+// cmd/commands/logs.go
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/logs"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Stream logs of all the app's pods",
+	Long: `Stream the logs of every pod of the deployed application.
+
+Each line is prefixed with its pod name; pods started later (scale-ups,
+restarts, redeploys) are picked up automatically.
+
+Examples:
+  kudev logs                           Last 100 lines of each pod, then follow
+  kudev logs --since 10m --tail -1     Everything from the last 10 minutes
+  kudev logs --grep 'ERROR|WARN'       Only lines matching a regex
+  kudev logs --container sidecar       A specific container
+  kudev logs --all-containers          Every container, prefixed pod/container
+
+Works without .kudev.yaml when the app is given by flags:
+  kudev logs --name myapp --namespace dev`,
+	RunE: runLogs,
+}
+
+var (
+	logsNoColor       bool
+	logsTail          int64
+	logsSince         time.Duration
+	logsGrep          string
+	logsContainer     string
+	logsAllContainers bool
+)
+
+func init() {
+	logsCmd.Flags().BoolVar(&logsNoColor, "no-color", false, "Don't color pod name prefixes")
+	logsCmd.Flags().Int64Var(&logsTail, "tail", logs.DefaultTailLines, "Existing log lines to show per pod (-1 for all)")
+	logsCmd.Flags().DurationVar(&logsSince, "since", 0, "Only show existing lines newer than this (e.g. 10m, 1h)")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Only show lines whose message matches this regular expression")
+	logsCmd.Flags().StringVar(&logsContainer, "container", "", "Container to stream (default: spec.containerName, else the pod's only container)")
+	logsCmd.Flags().BoolVar(&logsAllContainers, "all-containers", false, "Stream every container of each pod")
+	logsCmd.MarkFlagsMutuallyExclusive("container", "all-containers")
+
+	addTargetFlags(logsCmd)
+
+	rootCmd.AddCommand(logsCmd)
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+
+	if logsSince < 0 {
+		return fmt.Errorf("--since must be positive, got %s", logsSince)
+	}
+	var filter *regexp.Regexp
+	if logsGrep != "" {
+		re, err := regexp.Compile(logsGrep)
+		if err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+		filter = re
+	}
+
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	tailer := logs.NewKubernetesLogTailer(clientset, logger, os.Stdout)
+	tailer.SetColor(!logsNoColor)
+	tailer.SetTailLines(logsTail)
+	tailer.SetSince(logsSince)
+	container := logsContainer
+	if container == "" {
+		container = cfg.Spec.ContainerName
+	}
+	tailer.SetContainer(container)
+	tailer.SetAllContainers(logsAllContainers)
+	tailer.SetFilter(filter)
+
+	fmt.Printf("Streaming logs of %s in %s (Ctrl+C to stop)...\n", cfg.Metadata.Name, cfg.Spec.Namespace)
+	if err := tailer.TailAllPods(ctx, cfg.Metadata.Name, cfg.Spec.Namespace); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,340 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/hosts"
+	"github.com/nanaki-93/kudev/pkg/portfwd"
+	"github.com/nanaki-93/kudev/pkg/ports"
+	"github.com/nanaki-93/kudev/pkg/state"
+)
+
+// strictPorts disables substituteBusyPorts: a busy local port is reported
+// and left unforwarded instead.
+var strictPorts bool
+
+// addStrictPortsFlag registers --strict-ports on cmd.
+func addStrictPortsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&strictPorts, "strict-ports", false, "Don't move busy local ports to a free port nearby")
+}
+
+// portRegistry opens the machine-wide registry of claimed local ports.
+func portRegistry() (*ports.Registry, error) {
+	path, err := ports.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return ports.NewRegistry(path), nil
+}
+
+// portConflict returns a warning and a free alternative when another kudev
+// project already forwards localPort. An empty warning means no conflict.
+// Registry problems are not fatal: port checks are advisory.
+func portConflict(localPort int32, projectRoot string) (string, int32) {
+	reg, err := portRegistry()
+	if err != nil {
+		logger.Debug("port registry unavailable", "error", err)
+		return "", 0
+	}
+
+	claim, err := reg.Conflict(localPort, projectRoot)
+	if err != nil {
+		logger.Debug("port conflict check skipped", "error", err)
+		return "", 0
+	}
+	if claim == nil {
+		return "", 0
+	}
+
+	warning := fmt.Sprintf("local port %d is already used by kudev project %q (%s)",
+		localPort, claim.App, claim.ProjectRoot)
+
+	free, err := reg.SuggestFree(localPort+1, projectRoot)
+	if err != nil {
+		logger.Debug("no free port suggestion", "error", err)
+		return warning, 0
+	}
+	return warning, free
+}
+
+// claimPort records that this project forwards localPort, so other
+// projects are warned before picking it.
+func claimPort(localPort int32, app, projectRoot string) {
+	reg, err := portRegistry()
+	if err != nil {
+		logger.Debug("port registry unavailable", "error", err)
+		return
+	}
+	if err := reg.Claim(localPort, app, projectRoot); err != nil {
+		logger.Debug("failed to claim local port", "port", localPort, "error", err)
+	}
+}
+
+// resolveLocalPort allocates the port for `localPort: auto` and stores it
+// in .kudev/state.json, so the project keeps the same port across runs.
+func resolveLocalPort(cfg *config.DeploymentConfig) error {
+	if !cfg.Spec.AutoLocalPort || cfg.Spec.LocalPort != 0 {
+		return nil
+	}
+
+	store := state.NewStore(cfg.ProjectRoot)
+	st, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	reg, err := portRegistry()
+	if err != nil {
+		return err
+	}
+	port, err := reg.Allocate(cfg.Metadata.Name, cfg.ProjectRoot, st.LocalPort)
+	if err != nil {
+		return fmt.Errorf("failed to allocate local port: %w", err)
+	}
+
+	if port != st.LocalPort {
+		if err := store.Update(func(st *state.State) error {
+			st.LocalPort = port
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	cfg.Spec.LocalPort = port
+	fmt.Printf("✓ Using local port %d (auto)\n", port)
+	return nil
+}
+
+// forwardHostPort is the local end of a forward, e.g. "localhost:8080"
+// or "[::1]:8080".
+func forwardHostPort(cfg *config.DeploymentConfig, localPort int32) string {
+	return net.JoinHostPort(cfg.Spec.PortForward.EffectiveAddress(), strconv.Itoa(int(localPort)))
+}
+
+// startPortForward prints, claims and forwards the TCP ports of
+// cfg.PortMappings over one connection. Busy local ports are first moved
+// to free ones (see substituteBusyPorts). UDP and SCTP ports, which
+// port-forward can't carry, are exposed through a temporary NodePort
+// Service instead. The returned cleanup removes that Service.
+func startPortForward(ctx context.Context, cfg *config.DeploymentConfig, clientset kubernetes.Interface, restConfig *rest.Config) (portfwd.PortForwarder, func()) {
+	clearSubstitutions := substituteBusyPorts(cfg)
+
+	var tcp, other []portfwd.Port
+	for _, m := range cfg.PortMappings() {
+		p := portfwd.Port{Local: m.Local, Pod: m.Container, Protocol: corev1.Protocol(m.EffectiveProtocol())}
+		if !p.Forwardable() {
+			other = append(other, p)
+			continue
+		}
+		fmt.Printf("✓ Port forwarding %s → pod:%d\n", forwardHostPort(cfg, m.Local), m.Container)
+		claimPort(m.Local, cfg.Metadata.Name, cfg.ProjectRoot)
+		tcp = append(tcp, p)
+	}
+
+	forwarder := portfwd.NewKubernetesPortForwarder(clientset, restConfig, logger)
+	forwarder.Address = cfg.Spec.PortForward.EffectiveAddress()
+	if err := forwarder.ForwardPorts(ctx, cfg.Metadata.Name, cfg.Spec.Namespace, tcp); err != nil {
+		fmt.Printf("⚠ Port forwarding failed: %v\n", err)
+		// Continue anyway - user can forward manually
+	}
+	registerHostname(cfg)
+
+	if len(other) == 0 {
+		return forwarder, clearSubstitutions
+	}
+	removeNodePorts := exposeNodePorts(ctx, cfg, clientset, other)
+	return forwarder, func() {
+		removeNodePorts()
+		clearSubstitutions()
+	}
+}
+
+// substituteBusyPorts moves forwarded TCP ports that are already in use
+// to a free port nearby, unless --strict-ports is set, and records the
+// substitutions in .kudev/state.json for 'kudev status'. The returned
+// function clears them from the state file.
+func substituteBusyPorts(cfg *config.DeploymentConfig) func() {
+	if strictPorts {
+		return func() {}
+	}
+
+	address := cfg.Spec.PortForward.EffectiveAddress()
+	mappings := cfg.PortMappings()
+
+	// A substitute must not collide with another forwarded port
+	taken := make(map[int32]bool, len(mappings))
+	for _, m := range mappings {
+		taken[m.Local] = true
+	}
+
+	var subs []state.PortSubstitution
+	for i, m := range mappings {
+		if m.EffectiveProtocol() != "TCP" || portfwd.PortAvailable(address, m.Local) {
+			continue
+		}
+		free, err := portfwd.SuggestPortExcluding(m.Local, taken)
+		if err != nil {
+			fmt.Printf("⚠ Local port %d is busy: %v\n", m.Local, err)
+			continue
+		}
+		fmt.Printf("⚠ Local port %d is busy, using %d instead (--strict-ports to disable)\n", m.Local, free)
+		taken[free] = true
+		setLocalPort(cfg, i == 0, m, free)
+		subs = append(subs, state.PortSubstitution{Configured: m.Local, Actual: free})
+	}
+	if len(subs) == 0 {
+		return func() {}
+	}
+
+	store := state.NewStore(cfg.ProjectRoot)
+	setPortSubstitutions(store, subs)
+	return func() {
+		setPortSubstitutions(store, nil)
+	}
+}
+
+// setLocalPort moves mapping m to local port. spec.ports entries repeating
+// the primary mapping move with it, so they stay deduplicated.
+func setLocalPort(cfg *config.DeploymentConfig, primary bool, m config.PortMapping, port int32) {
+	if primary {
+		cfg.Spec.LocalPort = port
+	}
+	for i, p := range cfg.Spec.Ports {
+		if p.Local == m.Local && p.Container == m.Container && p.EffectiveProtocol() == m.EffectiveProtocol() {
+			cfg.Spec.Ports[i].Local = port
+		}
+	}
+}
+
+// setPortSubstitutions records subs in the project state; failures only
+// matter to 'kudev status', so they are logged, not returned.
+func setPortSubstitutions(store *state.Store, subs []state.PortSubstitution) {
+	if err := store.Update(func(st *state.State) error {
+		st.PortSubstitutions = subs
+		return nil
+	}); err != nil {
+		logger.Debug("failed to record port substitutions", "error", err)
+	}
+}
+
+// exposeNodePorts exposes ports through a temporary NodePort Service and
+// prints how to reach them, making clear they are not port-forwarded.
+func exposeNodePorts(ctx context.Context, cfg *config.DeploymentConfig, clientset kubernetes.Interface, ports []portfwd.Port) func() {
+	for _, p := range ports {
+		fmt.Printf("⚠ %s port %d can't be port-forwarded (Kubernetes port-forward is TCP-only)\n", p.Protocol, p.Pod)
+	}
+
+	exposer := portfwd.NewNodePortExposer(clientset, logger)
+	exposure, err := exposer.Expose(ctx, cfg.Metadata.Name, cfg.Spec.Namespace, ports)
+	if err != nil {
+		fmt.Printf("⚠ NodePort fallback failed: %v\n", err)
+		return func() {}
+	}
+
+	host := "<node-ip>"
+	if len(exposure.NodeAddresses) > 0 {
+		host = exposure.NodeAddresses[0]
+	}
+	fmt.Printf("✓ Exposed instead through temporary NodePort service %s (NOT a port-forward, removed on exit):\n", exposure.Service)
+	for _, e := range exposure.Ports {
+		fmt.Printf("    %s %s → pod:%d\n",
+			strings.ToLower(string(e.Port.Protocol)),
+			net.JoinHostPort(host, strconv.Itoa(int(e.NodePort))), e.Port.Pod)
+	}
+	fmt.Println("  Node ports are reached at a node address, not localhost (Docker Desktop")
+	fmt.Println("  and Rancher Desktop also publish them on localhost).")
+
+	return func() {
+		// ctx may be cancelled by now
+		if err := exposer.Remove(context.Background(), cfg.Metadata.Name, cfg.Spec.Namespace); err != nil {
+			fmt.Printf("⚠ %v\n", err)
+			return
+		}
+		fmt.Println("✓ Temporary NodePort service removed")
+	}
+}
+
+// printForwardURLs prints where the forwarded app can be reached: locally,
+// on the network when listening on all interfaces, and the extra ports.
+func printForwardURLs(cfg *config.DeploymentConfig) {
+	address := cfg.Spec.PortForward.EffectiveAddress()
+	port := strconv.Itoa(int(cfg.Spec.LocalPort))
+
+	if ip := net.ParseIP(address); ip == nil || !ip.IsUnspecified() {
+		fmt.Printf("  Local:   http://%s\n", net.JoinHostPort(address, port))
+	} else {
+		fmt.Printf("  Local:   http://%s\n", net.JoinHostPort("localhost", port))
+		if lan := lanIP(); lan != "" {
+			fmt.Printf("  Network: http://%s\n", net.JoinHostPort(lan, port))
+		}
+	}
+
+	if cfg.Spec.PortForward.HostsEnabled() {
+		fmt.Printf("  Host:    http://%s\n", net.JoinHostPort(hosts.Hostname(cfg.Metadata.Name), port))
+	}
+
+	// Extra ports (debuggers etc.) aren't necessarily HTTP
+	for _, m := range cfg.PortMappings()[1:] {
+		if m.EffectiveProtocol() == "TCP" {
+			fmt.Printf("  Port:    %s → pod:%d\n", forwardHostPort(cfg, m.Local), m.Container)
+		}
+	}
+}
+
+// registerHostname adds <app>.kudev.local to the hosts file when
+// spec.portForward.hosts is set. Failures are reported, not fatal: the
+// app stays reachable on localhost.
+func registerHostname(cfg *config.DeploymentConfig) {
+	if !cfg.Spec.PortForward.HostsEnabled() {
+		return
+	}
+
+	entry := hosts.Entry{Address: hostAddress(cfg), Hostname: hosts.Hostname(cfg.Metadata.Name)}
+	changed, err := hosts.NewFile(hosts.DefaultPath()).Add(entry)
+	if err != nil {
+		fmt.Printf("⚠ Hostname not registered: %v\n", err)
+		return
+	}
+	if changed {
+		fmt.Printf("✓ Registered %s → %s in %s ('kudev hosts remove' to undo)\n", entry.Hostname, entry.Address, hosts.DefaultPath())
+	}
+}
+
+// hostAddress is the address the app's hostname points at: the forward's
+// listen address, or loopback for localhost and all interfaces.
+func hostAddress(cfg *config.DeploymentConfig) string {
+	ip := net.ParseIP(cfg.Spec.PortForward.EffectiveAddress())
+	if ip == nil || ip.IsUnspecified() {
+		return "127.0.0.1"
+	}
+	return ip.String()
+}
+
+// lanIP returns the first non-loopback IPv4 address of this machine,
+// or "" when there is none.
+func lanIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		logger.Debug("failed to list interface addresses", "error", err)
+		return ""
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		return ipNet.IP.String()
+	}
+	return ""
+}
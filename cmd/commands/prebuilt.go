@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/builder/docker"
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/logs"
+	"github.com/nanaki-93/kudev/pkg/portfwd"
+	"github.com/nanaki-93/kudev/pkg/state"
+	"github.com/nanaki-93/kudev/pkg/watch"
+)
+
+// prebuiltImage returns spec.image.ref with its current registry digest.
+// Deploying by digest makes the nodes pull what the tag points to now
+// despite the IfNotPresent pull policy; when the digest can't be
+// resolved (no docker, no registry access) the tag is deployed as is.
+func prebuiltImage(ctx context.Context, cfg *config.DeploymentConfig, b *docker.Builder) *builder.ImageRef {
+	ref := cfg.PrebuiltImage()
+	if _, digest, pinned := strings.Cut(ref, "@"); pinned {
+		return &builder.ImageRef{FullRef: ref, Digest: digest}
+	}
+
+	digest, err := b.RemoteDigest(ctx, ref)
+	if err != nil {
+		fmt.Printf("⚠ Could not resolve the digest of %s, deploying by tag\n", ref)
+		logger.Debug("digest lookup failed", "image", ref, "error", err)
+	}
+	return &builder.ImageRef{FullRef: ref, Digest: digest}
+}
+
+// prebuiltHash is the kudev-hash label of a pre-built image: the start
+// of its digest, so every new digest is a new rollout.
+func prebuiltHash(ref *builder.ImageRef) string {
+	hex := strings.TrimPrefix(ref.Digest, "sha256:")
+	if len(hex) < 8 {
+		return "prebuilt"
+	}
+	return hex[:8]
+}
+
+// prebuiltWatchUnsupported are the watch flags that need a build.
+var prebuiltWatchUnsupported = []string{"tui", "max-cycles", "listen", "blue-green", "force-initial-build", "auto-prune"}
+
+// runWatchPrebuilt is watch for spec.image.ref: nothing is built, so the
+// registry is polled instead of the files, and the app is redeployed
+// whenever the tag moves to a new digest.
+func runWatchPrebuilt(cmd *cobra.Command, cfg *config.DeploymentConfig, dockerBuilder *docker.Builder) error {
+	ctx := cmd.Context()
+	for _, name := range prebuiltWatchUnsupported {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with spec.image.ref: the image is not built", name)
+		}
+	}
+
+	clientset, restConfig, err := getKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	renderer, err := deployer.NewRendererForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create renderer: %w", err)
+	}
+	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+	recordArtifacts(dep, cfg)
+	dep.SetServerValidation(validateServer)
+	history := state.NewStore(cfg.ProjectRoot)
+
+	deploy := func(ctx context.Context, imageRef *builder.ImageRef) error {
+		start := time.Now()
+		opts := deployer.DeploymentOptions{
+			Config:    cfg,
+			ImageRef:  imageRef.DeployRef(),
+			ImageHash: prebuiltHash(imageRef),
+		}
+		status, err := dep.Upsert(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to deploy: %w", err)
+		}
+
+		fmt.Printf("✓ Deployed %s: %s (%d/%d replicas)\n", opts.ImageRef, status.Status, status.ReadyReplicas, status.DesiredReplicas)
+		emitEvent(ctx, dep, cfg, deployer.ReasonDeployed, fmt.Sprintf("Deployed pre-built image %s", opts.ImageRef))
+		if err := history.Record(state.Event{
+			Type:       state.EventDeploy,
+			Hash:       opts.ImageHash,
+			Image:      imageRef.FullRef,
+			Digest:     imageRef.Digest,
+			DurationMs: time.Since(start).Milliseconds(),
+		}); err != nil {
+			logger.Debug("failed to record history event", "error", err)
+		}
+		return nil
+	}
+
+	fmt.Printf("✓ Deploying pre-built image %s...\n", cfg.PrebuiltImage())
+	imageRef := prebuiltImage(ctx, cfg, dockerBuilder)
+	if err := deploy(ctx, imageRef); err != nil {
+		return err
+	}
+
+	if watchOnce {
+		fmt.Println("Waiting for readiness...")
+		err := dep.WaitForReady(ctx, deployer.WaitOptions{
+			AppName:   cfg.Metadata.Name,
+			Namespace: cfg.Spec.Namespace,
+			Timeout:   readinessTimeout(cfg),
+			Readiness: cfg.Spec.Readiness,
+			WorkDir:   cfg.ProjectRoot,
+			Progress:  printPodProgress,
+		})
+		if err != nil {
+			return fmt.Errorf("deployment is not ready: %w", err)
+		}
+		fmt.Println("✓ Deployment is ready")
+		return nil
+	}
+
+	if !watchNoPortFwd {
+		var forwarder portfwd.PortForwarder
+		var cleanupPorts func()
+		forwarder, cleanupPorts = startPortForward(ctx, cfg, clientset, restConfig)
+		defer func() {
+			forwarder.Stop()
+			printForwardStats(forwarder)
+			cleanupPorts()
+		}()
+	}
+
+	var logStream watch.LogSwitcher
+	if !watchNoLogs {
+		tailer := logs.NewKubernetesLogTailer(clientset, logger, os.Stdout)
+		tailer.SetTailLines(watchTailLines)
+		tailer.SetContainer(cfg.Spec.ContainerName)
+		handle := tailer.Start(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
+		defer handle.Stop()
+		logStream = handle
+	}
+
+	interval := cfg.Spec.Image.EffectivePollInterval()
+	fmt.Println()
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Printf("  Application is running!\n")
+	printForwardURLs(cfg)
+	fmt.Printf("  Polling %s every %s for new digests\n", cfg.PrebuiltImage(), interval)
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Println()
+
+	poller := watch.NewDigestPoller(cfg.PrebuiltImage(), interval, dockerBuilder.RemoteDigest, logger)
+	err = poller.Run(ctx, imageRef.Digest, func(ctx context.Context, digest string) error {
+		fmt.Printf("\n↻ %s now points to %s, redeploying...\n", cfg.PrebuiltImage(), digest)
+		next := &builder.ImageRef{FullRef: cfg.PrebuiltImage(), Digest: digest}
+		if err := deploy(ctx, next); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return err
+		}
+		if logStream != nil {
+			logStream.SwitchRollout(prebuiltHash(next))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	fmt.Println("\nShutting down...")
+	return nil
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/timing"
+)
+
+var (
+	profileRun bool
+	profileOut string
+
+	// profiler is the active --profile-run recorder (nil when disabled)
+	profiler *timing.Recorder
+
+	// cpuProfile is the open --profile-out .pprof file
+	cpuProfile *os.File
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&profileRun, "profile-run", false, "Print how long each phase (hash, build, load, deploy, ...) took when the command ends")
+	rootCmd.PersistentFlags().StringVar(&profileOut, "profile-out", "", "With --profile-run, also write a Chrome trace (.json) or CPU profile (.pprof) to this file")
+}
+
+// startProfile attaches a timing recorder to the command's context when
+// --profile-run is set, and starts CPU profiling for a .pprof --profile-out.
+func startProfile(cmd *cobra.Command) error {
+	if !profileRun {
+		if profileOut != "" {
+			return fmt.Errorf("--profile-out requires --profile-run")
+		}
+		return nil
+	}
+
+	profiler = timing.NewRecorder()
+	cmd.SetContext(timing.WithRecorder(cmd.Context(), profiler))
+
+	if isPprofPath(profileOut) {
+		f, err := os.Create(profileOut)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		cpuProfile = f
+	}
+	return nil
+}
+
+// finishProfile prints the timing table and writes --profile-out.
+// Called once the command has returned.
+func finishProfile() {
+	if profiler == nil {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr)
+	if err := profiler.WriteTable(os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Failed to print timing: %v\n", err)
+	}
+
+	switch {
+	case cpuProfile != nil:
+		pprof.StopCPUProfile()
+		cpuProfile.Close()
+		fmt.Fprintf(os.Stderr, "CPU profile written to %s (view with: go tool pprof %s)\n", profileOut, profileOut)
+	case profileOut != "":
+		if err := writeChromeTrace(profileOut); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Failed to write trace: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Trace written to %s (open in chrome://tracing or ui.perfetto.dev)\n", profileOut)
+	}
+}
+
+// writeChromeTrace writes the recorded phases to path.
+func writeChromeTrace(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := profiler.WriteChromeTrace(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// isPprofPath reports whether --profile-out asks for a CPU profile.
+func isPprofPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".pprof" || ext == ".prof"
+}
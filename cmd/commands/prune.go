@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/builder/docker"
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/hash"
+	"github.com/nanaki-93/kudev/pkg/watch"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old kudev-built images locally and from the cluster",
+	Long: `Delete old kudev-built images from the local Docker daemon and the
+cluster's nodes.
+
+Every rebuild creates a new kudev-<hash> image. prune keeps the newest
+--keep images of spec.imageName (of every repository with --all) and
+deletes the rest. The image of the current source tree and the one the
+cluster runs are always kept.
+
+Images are removed from kind nodes, minikube and Rancher Desktop's
+containerd as well; Docker Desktop shares the local daemon. Skip the
+cluster with --local.
+
+With --dry-run, the images are listed and nothing is deleted. In watch
+mode, 'kudev watch --auto-prune N' prunes after every rebuild.`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+var (
+	pruneKeep  int
+	pruneAll   bool
+	pruneLocal bool
+	pruneYes   bool
+)
+
+func init() {
+	pruneCmd.Flags().IntVar(&pruneKeep, "keep", 3, "Newest images to keep per repository")
+	pruneCmd.Flags().BoolVarP(&pruneAll, "all", "a", false, "Prune kudev images of every repository, not just spec.imageName")
+	pruneCmd.Flags().BoolVar(&pruneLocal, "local", false, "Only delete from the local Docker daemon, not from the cluster")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Delete without confirmation")
+
+	markDryRun(pruneCmd)
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+	if pruneKeep < 0 {
+		return fmt.Errorf("--keep must not be negative, got %d", pruneKeep)
+	}
+
+	dockerBuilder, err := newDockerBuilder(cfg)
+	if err != nil {
+		return err
+	}
+	var protect []string
+	if current, err := hash.ForConfig(cfg).Calculate(ctx); err == nil {
+		protect = append(protect, current)
+	} else {
+		logger.Debug("failed to calculate source hash", "error", err)
+	}
+
+	candidates, err := pruneCandidates(ctx, dockerBuilder, cfg, pruneKeep, pruneAll, protect)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		fmt.Println("✓ No kudev images to prune")
+		return nil
+	}
+
+	fmt.Printf("%d image(s) to delete:\n", len(candidates))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, img := range candidates {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", img.Ref(), formatAge(img.CreatedAt), img.Size)
+	}
+	w.Flush()
+
+	if dryRun {
+		fmt.Println()
+		fmt.Println("Dry run: nothing was deleted")
+		return nil
+	}
+
+	if !pruneYes {
+		fmt.Print("Delete them? [y/N]: ")
+
+		var response string
+		fmt.Scanln(&response)
+
+		if response != "y" && response != "Y" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	fmt.Println()
+	return removeImages(ctx, dockerBuilder, cfg, candidates, !pruneLocal)
+}
+
+// pruneCandidates lists the kudev images beyond the newest keep of
+// spec.imageName (of every repository when all is set), sparing the
+// images built from the protect hashes and every image still deployed.
+func pruneCandidates(ctx context.Context, dockerBuilder *docker.Builder, cfg *config.DeploymentConfig, keep int, all bool, protect []string) ([]docker.Image, error) {
+	protect = append(protect, deployedHashes(ctx, cfg)...)
+
+	repository := cfg.Spec.ImageName
+	if all {
+		repository = ""
+	}
+
+	images, err := dockerBuilder.ListImages(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	protected := map[string]bool{}
+	for _, h := range protect {
+		if h != "" {
+			protected[h] = true
+		}
+	}
+	return docker.PruneCandidates(images, keep, protected), nil
+}
+
+// removeImages deletes images from the local daemon and, with cluster
+// set, from the nodes of the target cluster.
+func removeImages(ctx context.Context, dockerBuilder *docker.Builder, cfg *config.DeploymentConfig, images []docker.Image, cluster bool) error {
+	refs := make([]string, 0, len(images))
+	for _, img := range images {
+		refs = append(refs, img.Ref())
+	}
+
+	if cluster {
+		kubeContext := targetKubeContext(cfg)
+		loader, err := newRegistry(kubeContext, dockerBuilder).Remove(ctx, refs)
+		if err != nil {
+			fmt.Printf("⚠ Could not delete all images from %s: %v\n", kubeContext, err)
+		} else {
+			fmt.Printf("✓ Deleted %d image(s) from %s (%s)\n", len(refs), kubeContext, loader)
+		}
+	}
+
+	if err := dockerBuilder.RemoveImages(ctx, refs); err != nil {
+		return fmt.Errorf("failed to delete some local images:\n%w", err)
+	}
+	fmt.Printf("✓ Deleted %d local image(s)\n", len(refs))
+	return nil
+}
+
+// deployedHashes returns the source hashes of the images kudev-managed
+// Deployments run, in every namespace when allowed and in cfg's namespace
+// otherwise. Local images cannot be pulled back once removed from the
+// nodes, so blue/green slots and --instance variants are spared too.
+func deployedHashes(ctx context.Context, cfg *config.DeploymentConfig) []string {
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		logger.Debug("cannot check the deployed images", "error", err)
+		return nil
+	}
+	dep := deployer.NewKubernetesDeployer(clientset, nil, logger)
+
+	hashes, err := dep.DeployedImageHashes(ctx, "")
+	if err != nil {
+		logger.Debug("cannot list deployments in all namespaces", "error", err)
+		hashes, err = dep.DeployedImageHashes(ctx, cfg.Spec.Namespace)
+	}
+	if err != nil {
+		logger.Debug("cannot check the deployed images", "error", err)
+		return nil
+	}
+	return hashes
+}
+
+// newWatchPruner returns the pruner for --auto-prune, or nil when it is
+// off.
+func newWatchPruner(cfg *config.DeploymentConfig, dockerBuilder *docker.Builder) watch.ImagePruner {
+	if watchAutoPrune <= 0 || cfg.PrebuiltImage() != "" {
+		return nil
+	}
+	return &watchPruner{cfg: cfg, builder: dockerBuilder, keep: watchAutoPrune}
+}
+
+// watchPruner deletes old images after each rebuild in watch mode
+// (--auto-prune).
+type watchPruner struct {
+	cfg     *config.DeploymentConfig
+	builder *docker.Builder
+	keep    int
+}
+
+// Prune keeps the newest images of the app plus keepHashes.
+func (p *watchPruner) Prune(ctx context.Context, keepHashes []string) {
+	candidates, err := pruneCandidates(ctx, p.builder, p.cfg, p.keep, false, keepHashes)
+	if err != nil {
+		fmt.Printf("⚠ Auto-prune skipped: %v\n", err)
+		return
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	if err := removeImages(ctx, p.builder, p.cfg, candidates, true); err != nil {
+		fmt.Printf("⚠ %v\n", err)
+	}
+}
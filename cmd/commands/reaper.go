@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/reaper"
+)
+
+var installReaperCmd = &cobra.Command{
+	Use:   "install-reaper",
+	Short: "Install a CronJob that deletes expired kudev apps",
+	Long: `Install a CronJob that deletes kudev apps nobody has deployed for a
+while, keeping shared dev clusters tidy when 'kudev down' is forgotten.
+
+Apps opt in with spec.ttl:
+
+  spec:
+    ttl: 72h
+
+Every 'kudev up' or watch redeploy stamps the Deployment with
+` + deployer.AnnotationExpiresAt + ` (now + ttl). The CronJob periodically
+deletes the resources of every app whose expiry has passed, the same
+ones 'kudev down' removes. Apps without spec.ttl are never touched.
+
+The reaper runs in its own namespace with a ServiceAccount allowed to list
+and delete kudev resource types cluster-wide. Running the command again
+updates it.
+
+With --dry-run the manifests are printed instead of applied. To reap
+once from your machine instead, run 'kudev gc'.
+
+Remove it with:
+  kubectl delete namespace ` + reaper.DefaultNamespace + `
+  kubectl delete clusterrole,clusterrolebinding ` + reaper.Name,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{clusterAnnotation: "true"},
+	RunE:        runInstallReaper,
+}
+
+var reaperOpts reaper.Options
+
+func init() {
+	installReaperCmd.Flags().StringVar(&reaperOpts.Namespace, "namespace", reaper.DefaultNamespace, "Namespace for the CronJob")
+	installReaperCmd.Flags().StringVar(&reaperOpts.Schedule, "schedule", reaper.DefaultSchedule, "CronJob schedule (cron format)")
+	installReaperCmd.Flags().StringVar(&reaperOpts.Image, "image", reaper.DefaultImage, "Image providing bash and kubectl")
+
+	markDryRun(installReaperCmd)
+	rootCmd.AddCommand(installReaperCmd)
+}
+
+func runInstallReaper(cmd *cobra.Command, args []string) error {
+	if dryRun {
+		for _, obj := range reaper.Objects(reaperOpts) {
+			data, err := yaml.Marshal(obj)
+			if err != nil {
+				return fmt.Errorf("failed to render manifest: %w", err)
+			}
+			fmt.Printf("---\n%s", data)
+		}
+		return nil
+	}
+
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	if err := reaper.Install(cmd.Context(), clientset, reaperOpts, logger); err != nil {
+		return fmt.Errorf("failed to install reaper: %w", err)
+	}
+
+	fmt.Printf("✓ Reaper installed in namespace %s (schedule %q)\n", reaperOpts.Namespace, reaperOpts.Schedule)
+	fmt.Println("  Apps with spec.ttl are deleted once not deployed for that long")
+	return nil
+}
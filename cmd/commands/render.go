@@ -0,0 +1,112 @@
+// cmd/commands/render.go
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/export"
+	"github.com/nanaki-93/kudev/pkg/hash"
+)
+
+var renderCmd = &cobra.Command{
+	Use:     "render",
+	Aliases: []string{"manifests"},
+	Short:   "Print the generated Kubernetes manifests",
+	Long: `Render the Deployment and Service kudev would apply, without touching
+Docker or the cluster.
+
+The manifests are printed to stdout as one multi-document YAML stream, or
+written as deployment.yaml and service.yaml with --output. Use them to
+commit, diff or 'kubectl apply' the exact resources kudev generates.
+
+The image defaults to the current source hash tag; override with --image.
+
+Examples:
+  kudev render
+  kudev render | kubectl diff -f -
+  kudev render --output manifests/`,
+	RunE: runRender,
+}
+
+var (
+	renderOutput string
+	renderImage  string
+)
+
+func init() {
+	renderCmd.Flags().StringVarP(&renderOutput, "output", "o", "", "Write manifests to this directory instead of stdout")
+	renderCmd.Flags().StringVar(&renderImage, "image", "", "Image reference to use (default: <imageName>:<kudev tag>)")
+
+	rootCmd.AddCommand(renderCmd)
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+
+	renderer, data, err := manifestData(ctx, cfg, renderImage)
+	if err != nil {
+		return err
+	}
+
+	if renderOutput == "" {
+		manifests, err := renderer.RenderAll(data)
+		if err != nil {
+			return fmt.Errorf("failed to render manifests: %w", err)
+		}
+		fmt.Print(manifests)
+		return nil
+	}
+
+	written, err := export.NewExporter(renderer).Export(export.FormatPlain, data, renderOutput)
+	if err != nil {
+		return fmt.Errorf("failed to write manifests: %w", err)
+	}
+
+	fmt.Printf("✓ Rendered manifests for '%s'\n", cfg.Metadata.Name)
+	for _, path := range written {
+		fmt.Printf("  %s\n", path)
+	}
+
+	return nil
+}
+
+// manifestData prepares the renderer and template data for offline
+// rendering. An empty imageRef means <imageName>:<current kudev tag>.
+func manifestData(ctx context.Context, cfg *config.DeploymentConfig, imageRef string) (*deployer.Renderer, deployer.TemplateData, error) {
+	imageHash := ""
+	if imageRef == "" && cfg.PrebuiltImage() != "" {
+		imageRef = cfg.PrebuiltImage()
+	}
+	if imageRef == "" {
+		calculator := hash.ForConfig(cfg)
+		tag, err := builder.NewTagger(calculator).GenerateTag(ctx, false)
+		if err != nil {
+			return nil, deployer.TemplateData{}, fmt.Errorf("failed to generate tag: %w", err)
+		}
+		imageRef = fmt.Sprintf("%s:%s", cfg.Spec.ImageName, tag)
+		if imageHash, err = calculator.Calculate(ctx); err != nil {
+			return nil, deployer.TemplateData{}, fmt.Errorf("failed to calculate hash: %w", err)
+		}
+	}
+
+	renderer, err := deployer.NewRendererForConfig(cfg)
+	if err != nil {
+		return nil, deployer.TemplateData{}, fmt.Errorf("failed to create renderer: %w", err)
+	}
+
+	data := deployer.NewTemplateData(deployer.DeploymentOptions{
+		Config:    cfg,
+		ImageRef:  imageRef,
+		ImageHash: imageHash,
+	})
+
+	return renderer, data, nil
+}
@@ -12,10 +12,10 @@ import (
 	kudevErrors "github.com/nanaki-93/kudev/pkg/errors"
 	"github.com/nanaki-93/kudev/pkg/kubeconfig"
 	"github.com/nanaki-93/kudev/pkg/logging"
+	"github.com/nanaki-93/kudev/pkg/timing"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 var rootCmd = &cobra.Command{
@@ -54,16 +54,31 @@ Documentation:
 var (
 	configPath   string
 	debugMode    bool
+	dryRun       bool
 	forceContext bool
-	logger       logging.LoggerInterface
+	instanceName string
+	serviceName  string
+	profileName  string
+	traceAPI     bool
+	// logger starts as a no-op so early paths (e.g. signal handling) never
+	// hit a nil logger; rootPersistentPreRun swaps in the real one.
+	logger       logging.LoggerInterface = logging.NopLogger{}
 	loadedConfig *config.DeploymentConfig
-	validator    *kubeconfig.ContextValidator
+	// loadedProject is set instead of loadedConfig for project commands
+	// (see projectAnnotation)
+	loadedProject *config.ProjectConfig
+	validator     *kubeconfig.ContextValidator
 )
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Config file path")
 	rootCmd.PersistentFlags().BoolVarP(&debugMode, "debug", "d", false, "Enable debug logging")
 	rootCmd.PersistentFlags().BoolVar(&forceContext, "force-context", false, "Skip K8s context safety check (use with caution!)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what the command would do without building or changing anything (up, watch, down, prune, gc, install-reaper, config migrate)")
+	rootCmd.PersistentFlags().BoolVar(&traceAPI, "trace-api", false, "Log every Kubernetes API request (method, path, status, latency)")
+	rootCmd.PersistentFlags().StringVar(&instanceName, "instance", "", "Instance suffix for resource names (run several variants side by side)")
+	rootCmd.PersistentFlags().StringVar(&serviceName, "service", "", "Service to use when the config file holds several '---' separated configs")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to merge over spec (e.g. staging, see 'profiles' in .kudev.yaml)")
 }
 
 // rootPersistentPreRun is the global initialization hook.
@@ -75,7 +90,16 @@ func init() {
 //  4. Store for use by subcommands
 func rootPersistentPreRun(cmd *cobra.Command, args []string) error {
 	// Step 1: Setup logging
-	logging.InitLogger(debugMode)
+	logger = logging.InitLogger(debugMode)
+
+	if err := startProfile(cmd); err != nil {
+		return err
+	}
+
+	// --dry-run is a root flag, but only some commands honor it
+	if dryRun && !supportsDryRun(cmd) {
+		return fmt.Errorf("--dry-run is not supported by '%s'", cmd.CommandPath())
+	}
 
 	// Step 2: Skip config loading for certain commands
 	// These commands don't need config:
@@ -92,21 +116,107 @@ func rootPersistentPreRun(cmd *cobra.Command, args []string) error {
 		return nil // Let Cobra handle help
 	}
 
+	// Standalone commands need neither configuration nor cluster
+	if isStandaloneCommand(cmd) {
+		return nil
+	}
+
+	// Cluster-wide commands only need a safe context
+	if isClusterCommand(cmd) {
+		return validateContext()
+	}
+
 	// Step 3: Load configuration
 	ctx := context.Background()
-	cfg, err := config.LoadConfig(ctx, configPath)
+	if isProjectCommand(cmd) {
+		endLoad := timing.Phase(cmd.Context(), "config")
+		project, err := config.LoadProjectConfig(ctx, configPath, profileName)
+		endLoad()
+		if err != nil {
+			return fmt.Errorf(
+				"failed to load configuration: %w\n\n"+
+					"Run 'kudev init' to create a new .kudev.yaml configuration",
+				err,
+			)
+		}
+		loadedProject = project
+
+		for _, svc := range project.Services {
+			warnMigrated(svc)
+			for _, env := range svc.Spec.Env {
+				logging.DefaultRedactor().AddEnv(env.Name, env.Value)
+			}
+		}
+		return validateContext()
+	}
+
+	// Service arguments (kudev up api worker) select from the project
+	if selectsServices(cmd, args) {
+		endLoad := timing.Phase(cmd.Context(), "config")
+		several, err := loadTargets(ctx, args)
+		endLoad()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if several {
+			return validateContext()
+		}
+	}
+
+	endLoad := timing.Phase(cmd.Context(), "config")
+	cfg, err := config.LoadServiceConfig(ctx, configPath, serviceName, profileName)
+	endLoad()
 	if err != nil {
-		// Helpful error message
-		return fmt.Errorf(
-			"failed to load configuration: %w\n\n"+
-				"Run 'kudev init' to create a new .kudev.yaml configuration",
-			err,
+		// Read-only/cleanup commands can target an app via --name alone,
+		// but only when there is no config file: a broken one is reported
+		if !isConfigOptional(cmd) || targetName == "" || !errors.Is(err, config.ErrConfigNotFound) {
+			// Helpful error message
+			return fmt.Errorf(
+				"failed to load configuration: %w\n\n"+
+					"Run 'kudev init' to create a new .kudev.yaml configuration",
+				err,
+			)
+		}
+		logger.Debug("no configuration found, using flag-based targeting",
+			"name", targetName,
+			"namespace", targetNamespace,
 		)
+		cfg = config.NewDeploymentConfig(targetName)
+	}
+
+	warnMigrated(cfg)
+	applyTargetFlags(cfg)
+
+	// Suffix resource names when targeting a specific instance
+	if err := cfg.ApplyInstance(instanceName); err != nil {
+		return err
 	}
 
 	loadedConfig = cfg
 
+	// Mask sensitive env values in all log output
+	for _, env := range cfg.Spec.Env {
+		logging.DefaultRedactor().AddEnv(env.Name, env.Value)
+	}
+
 	// Step 4: Validate context safety
+	return validateContext()
+}
+
+// warnMigrated points at 'kudev config migrate' when cfg was upgraded
+// from an older apiVersion on load.
+func warnMigrated(cfg *config.DeploymentConfig) {
+	if cfg.MigratedFrom != "" {
+		logger.Warn("config uses an older apiVersion; run 'kudev config migrate' to update the file",
+			"service", cfg.Metadata.Name,
+			"apiVersion", cfg.MigratedFrom,
+		)
+	}
+}
+
+// validateContext refuses to run against a non-local Kubernetes context
+// (unless --force-context) and stores the validator.
+func validateContext() error {
 	ctxValidator, err := kubeconfig.NewContextValidator(forceContext)
 	if err != nil {
 		return fmt.Errorf("failed to check Kubernetes context: %w", err)
@@ -121,6 +231,86 @@ func rootPersistentPreRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// projectAnnotation marks commands working on every service of the
+// config file: the whole project is loaded instead of one service.
+const projectAnnotation = "kudev/project"
+
+// isProjectCommand reports whether the command loads the whole project.
+func isProjectCommand(cmd *cobra.Command) bool {
+	return cmd.Annotations[projectAnnotation] == "true"
+}
+
+// clusterAnnotation marks commands acting on the cluster as a whole,
+// which run without .kudev.yaml.
+const clusterAnnotation = "kudev/cluster"
+
+// isClusterCommand reports whether the command needs no configuration.
+func isClusterCommand(cmd *cobra.Command) bool {
+	return cmd.Annotations[clusterAnnotation] == "true"
+}
+
+// standaloneAnnotation marks commands that load neither .kudev.yaml nor
+// the Kubernetes context (e.g. 'kudev config migrate').
+const standaloneAnnotation = "kudev/standalone"
+
+// isStandaloneCommand reports whether the command skips config and context.
+func isStandaloneCommand(cmd *cobra.Command) bool {
+	return cmd.Annotations[standaloneAnnotation] == "true"
+}
+
+// dryRunAnnotation marks commands that honor --dry-run; the others
+// refuse the flag rather than silently doing the real thing.
+const dryRunAnnotation = "kudev/dry-run"
+
+// markDryRun marks cmd as honoring --dry-run.
+func markDryRun(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[dryRunAnnotation] = "true"
+}
+
+// supportsDryRun reports whether the command honors --dry-run.
+func supportsDryRun(cmd *cobra.Command) bool {
+	return cmd.Annotations[dryRunAnnotation] == "true"
+}
+
+// configOptionalAnnotation marks commands that can run without .kudev.yaml
+// when the target app is given via --name/--namespace.
+const configOptionalAnnotation = "kudev/config-optional"
+
+var (
+	targetName      string
+	targetNamespace string
+)
+
+// addTargetFlags registers --name/--namespace on a command and marks its
+// configuration as optional.
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&targetName, "name", "", "Target app name (overrides metadata.name; config optional when set)")
+	cmd.Flags().StringVarP(&targetNamespace, "namespace", "n", "", "Target namespace (overrides spec.namespace)")
+
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[configOptionalAnnotation] = "true"
+}
+
+// isConfigOptional reports whether the command accepts flag-based targeting.
+func isConfigOptional(cmd *cobra.Command) bool {
+	return cmd.Annotations[configOptionalAnnotation] == "true"
+}
+
+// applyTargetFlags overrides name/namespace from --name/--namespace.
+func applyTargetFlags(cfg *config.DeploymentConfig) {
+	if targetName != "" {
+		cfg.Metadata.Name = targetName
+	}
+	if targetNamespace != "" {
+		cfg.Spec.Namespace = targetNamespace
+	}
+}
+
 // GetLoadedConfig returns the configuration loaded in PersistentPreRun.
 //
 // Use this in subcommands to get the shared config instance.
@@ -138,18 +328,19 @@ func GetValidator() *kubeconfig.ContextValidator {
 // This is called from main().
 func Execute() int {
 	// Create context that cancels on SIGINT/SIGTERM
-	ctx := setupSignalContext()
+	ctx, cancel := setupSignalContext()
+	defer cancel()
 
 	err := rootCmd.ExecuteContext(ctx)
+	finishProfile()
 	if err == nil {
 		return 0
 	}
 	// Pass context to all commands
 	return handleError(err)
 }
-func setupSignalContext() context.Context {
+func setupSignalContext() (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	// Set up signal handling
 	sigChan := make(chan os.Signal, 1)
@@ -159,7 +350,9 @@ func setupSignalContext() context.Context {
 	go func() {
 		sig := <-sigChan
 		fmt.Println() // New line after ^C
-		logger.Debug("received signal", "signal", sig)
+		// Use the mutex-guarded global: this goroutine can run while
+		// rootPersistentPreRun is still swapping the package logger.
+		logging.Get().Debug("received signal", "signal", sig)
 		cancel()
 
 		// If second signal, force exit
@@ -167,7 +360,7 @@ func setupSignalContext() context.Context {
 		fmt.Println("\nForce exit...")
 		os.Exit(1)
 	}()
-	return ctx
+	return ctx, cancel
 }
 
 func handleError(err error) int {
@@ -195,23 +388,30 @@ func printKudevError(err kudevErrors.KudevError) {
 
 	fmt.Fprintln(os.Stderr)
 }
-func getKubernetesClient() (kubernetes.Interface, *rest.Config, error) {
-	// Load kubeconfig from default location (~/.kube/config)
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
 
-	restConfig, err := kubeConfig.ClientConfig()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load kubeconfig: %w", err)
-	}
+// clientContext, when set, is the kubeconfig context clusterClients
+// connects to instead of the current one. Long-running commands set it
+// to their pinned context, so clients rebuilt after a Reset keep
+// targeting the same cluster.
+var clientContext string
 
-	clientset, err := kubernetes.NewForConfig(restConfig)
+// clusterClients shares one Kubernetes client per process, so long-running
+// commands reuse its connection pool instead of rebuilding clients.
+var clusterClients = kubeconfig.NewClientCache(func() (*rest.Config, error) {
+	restConfig, err := kubeconfig.LoadRESTConfigForContext(clientContext)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+		return nil, err
+	}
+	if traceAPI {
+		kubeconfig.EnableTracing(restConfig, logger)
 	}
+	return restConfig, nil
+})
 
-	return clientset, restConfig, nil
+// getKubernetesClient returns the process-wide Kubernetes client,
+// creating it from kubeconfig on first use.
+func getKubernetesClient() (kubernetes.Interface, *rest.Config, error) {
+	return clusterClients.Get()
 }
 
 func getCurrentContext() string {
@@ -0,0 +1,92 @@
+// cmd/commands/secrets.go
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Inspect Secrets referenced by the configuration",
+}
+
+var secretsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Warn about missing Secrets referenced by env vars",
+	Long: `Check that every Secret referenced by an env var's
+valueFrom.secretKeyRef exists in the target namespace and has the key.
+
+Only key names are checked; secret values are never printed.
+
+Example .kudev.yaml:
+  env:
+    - name: DB_PASSWORD
+      valueFrom:
+        secretKeyRef:
+          name: db-credentials
+          key: password`,
+	RunE: runSecretsCheck,
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsCheckCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+func runSecretsCheck(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	cfg := getLoadedConfig()
+
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	renderer, err := deployer.NewRendererForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create renderer: %w", err)
+	}
+	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+
+	sources, err := dep.CheckEnvSources(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	checked, missing := 0, 0
+	for _, src := range sources {
+		if src.Kind != deployer.EnvSourceSecret {
+			continue
+		}
+		checked++
+
+		if src.Problem == "" {
+			fmt.Printf("✓ %s: secret %s/%s\n", src.Env, src.Name, src.Key)
+			continue
+		}
+		missing++
+		if src.Optional {
+			fmt.Printf("⚠ %s: %s (optional, the variable will be unset)\n", src.Env, src.Problem)
+		} else {
+			fmt.Printf("⚠ %s: %s\n", src.Env, src.Problem)
+		}
+	}
+
+	if checked == 0 {
+		fmt.Println("No env vars reference Secrets.")
+		return nil
+	}
+
+	fmt.Println()
+	if missing > 0 {
+		fmt.Printf("%d of %d secret references in namespace %q need attention.\n", missing, checked, cfg.Spec.Namespace)
+		fmt.Printf("Create one with: kubectl create secret generic <name> --from-literal=<key>=<value> -n %s\n", cfg.Spec.Namespace)
+	} else {
+		fmt.Printf("All %d secret references resolve in namespace %q.\n", checked, cfg.Spec.Namespace)
+	}
+	return nil
+}
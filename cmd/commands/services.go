@@ -0,0 +1,409 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/nanaki-93/kudev/pkg/builder/docker"
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/filesync"
+	"github.com/nanaki-93/kudev/pkg/kubeconfig"
+	"github.com/nanaki-93/kudev/pkg/logging"
+	"github.com/nanaki-93/kudev/pkg/logs"
+	"github.com/nanaki-93/kudev/pkg/state"
+	"github.com/nanaki-93/kudev/pkg/watch"
+)
+
+// servicesAnnotation marks commands taking service names as arguments
+// (kudev up api worker) and --exclude, for multi-service config files.
+const servicesAnnotation = "kudev/services"
+
+var (
+	excludeServices []string
+
+	// loadedTargets are the services selected by arguments and --exclude
+	// when more than one is left; a single one is loaded into loadedConfig.
+	loadedTargets []*config.DeploymentConfig
+)
+
+// addServiceArgs lets a command select services of a multi-service
+// config file by name, and leave services out with --exclude.
+func addServiceArgs(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&excludeServices, "exclude", nil, "Services of the config file to leave untouched (repeatable)")
+	cmd.Args = cobra.ArbitraryArgs
+
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[servicesAnnotation] = "true"
+}
+
+// selectsServices reports whether the command line picks services by
+// argument or --exclude.
+func selectsServices(cmd *cobra.Command, args []string) bool {
+	if cmd.Annotations[servicesAnnotation] != "true" {
+		return false
+	}
+	return len(args) > 0 || len(excludeServices) > 0
+}
+
+// loadTargets loads the project and selects the services named in args,
+// minus --exclude. A single selected service is then loaded like
+// --service, so it keeps every single-service feature; several are
+// stored in loadedTargets and true is returned.
+func loadTargets(ctx context.Context, args []string) (bool, error) {
+	if serviceName != "" {
+		return false, fmt.Errorf("--service cannot be combined with service arguments or --exclude")
+	}
+
+	project, err := config.LoadProjectConfig(ctx, configPath, profileName)
+	if err != nil {
+		return false, err
+	}
+	targets, err := project.Targets(args, excludeServices)
+	if err != nil {
+		return false, err
+	}
+	if len(targets) == 1 {
+		serviceName = targets[0].Metadata.Name
+		return false, nil
+	}
+
+	for _, svc := range targets {
+		warnMigrated(svc)
+		if err := svc.ApplyInstance(instanceName); err != nil {
+			return false, err
+		}
+		for _, env := range svc.Spec.Env {
+			logging.DefaultRedactor().AddEnv(env.Name, env.Value)
+		}
+	}
+	loadedProject = project
+	loadedTargets = targets
+	return true, nil
+}
+
+// serviceNames returns the metadata.name of every service.
+func serviceNames(services []*config.DeploymentConfig) string {
+	names := make([]string, 0, len(services))
+	for _, svc := range services {
+		names = append(names, svc.Metadata.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// runUpServices is up for several services: each is built, deployed and
+// waited for in file order, then all of them are forwarded (see 'kudev
+// forward') and their logs streamed, prefixed with the service name.
+func runUpServices(cmd *cobra.Command, targets []*config.DeploymentConfig) error {
+	ctx := cmd.Context()
+
+	fmt.Printf("✓ Selected services: %s\n", serviceNames(targets))
+	for _, cfg := range targets {
+		applyBuildFlags(cfg)
+		dockerBuilder, err := newDockerBuilder(cfg)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", cfg.Metadata.Name, err)
+		}
+		kubeContext := targetKubeContext(cfg)
+		build := !noBuild && cfg.PrebuiltImage() == ""
+		builderName := ""
+		if build {
+			builderName = dockerBuilder.Name()
+		}
+
+		if dryRun {
+			printStartupBanner(cfg, kubeContext, builderName)
+			if err := runDryRun(ctx, cfg, kubeContext, builderName); err != nil {
+				return fmt.Errorf("service %s: %w", cfg.Metadata.Name, err)
+			}
+			continue
+		}
+
+		if err := resolveLocalPort(cfg); err != nil {
+			return err
+		}
+		printStartupBanner(cfg, kubeContext, builderName)
+
+		status, err := deployService(ctx, cfg, dockerBuilder, kubeContext, build)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", cfg.Metadata.Name, err)
+		}
+		fmt.Printf("✓ %s is running (%d/%d replicas)\n", cfg.Metadata.Name, status.ReadyReplicas, status.DesiredReplicas)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if !noPortFwd {
+		forwards, err := forwardServices(ctx, targets)
+		if err != nil {
+			return err
+		}
+		defer stopForwards(forwards)
+	}
+
+	if !noLogs {
+		fmt.Println("Streaming logs (Ctrl+C to stop)...")
+		fmt.Println()
+		if err := tailServices(ctx, targets, tailLines); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println("Press Ctrl+C to stop port forwarding...")
+		<-ctx.Done()
+	}
+
+	fmt.Println("\nShutting down...")
+	fmt.Println("✓ Deployments remain running (use 'kudev down --service <name>' to remove)")
+	return nil
+}
+
+// forwardServices forwards every service and prints where each is reached.
+func forwardServices(ctx context.Context, targets []*config.DeploymentConfig) ([]*serviceForward, error) {
+	forwards, err := planForwards(targets)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := startForwards(ctx, forwards); err != nil {
+		return nil, err
+	}
+
+	fmt.Println()
+	printForwardTable(forwards)
+	fmt.Println()
+	return forwards, nil
+}
+
+// serviceTailer returns a log tailer for one of several services, whose
+// lines are prefixed with the service name.
+func serviceTailer(cfg *config.DeploymentConfig, mu *sync.Mutex, tail int64) (*logs.KubernetesLogTailer, error) {
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	out := logs.NewPrefixWriter(os.Stdout, "["+cfg.Metadata.Name+"] ", mu)
+	tailer := logs.NewKubernetesLogTailer(clientset, logger, out)
+	tailer.SetTailLines(tail)
+	tailer.SetContainer(cfg.Spec.ContainerName)
+	return tailer, nil
+}
+
+// tailServices streams the logs of every service until ctx is cancelled.
+func tailServices(ctx context.Context, targets []*config.DeploymentConfig, tail int64) error {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, cfg := range targets {
+		tailer, err := serviceTailer(cfg, &mu, tail)
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func(cfg *config.DeploymentConfig) {
+			defer wg.Done()
+			if err := tailer.TailLogsWithRetry(ctx, cfg.Metadata.Name, cfg.Spec.Namespace); err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("Log streaming of %s ended: %v\n", cfg.Metadata.Name, err)
+			}
+		}(cfg)
+	}
+	wg.Wait()
+	return nil
+}
+
+// checkWatchServices rejects watch flags that only work for one service.
+func checkWatchServices(targets []*config.DeploymentConfig) error {
+	singleOnly := []struct {
+		flag string
+		set  bool
+	}{
+		{"--tui", watchTUIEnabled},
+		{"--listen", watchListen != ""},
+		{"--once", watchOnce},
+		{"--max-cycles", watchMaxCycles != 0},
+		{"--blue-green", watchBlueGreen},
+	}
+	for _, f := range singleOnly {
+		if f.set {
+			return fmt.Errorf("%s needs a single service; select one (kudev watch <service>)", f.flag)
+		}
+	}
+	for _, cfg := range targets {
+		if cfg.PrebuiltImage() != "" {
+			return fmt.Errorf("service %s uses spec.image.ref; watch it on its own (kudev watch %s)", cfg.Metadata.Name, cfg.Metadata.Name)
+		}
+		if cfg.Spec.Watch != nil && cfg.Spec.Watch.BlueGreen {
+			fmt.Printf("⚠ %s: spec.watch.blueGreen is ignored when watching several services\n", cfg.Metadata.Name)
+		}
+	}
+	return nil
+}
+
+// runWatchServices is watch for several services: each gets its initial
+// build and deploy in file order and its own orchestrator, so a change
+// only rebuilds the services whose build context it is in. Ports are
+// forwarded as with 'kudev portfwd --all' and logs are prefixed with the
+// service name.
+func runWatchServices(cmd *cobra.Command, targets []*config.DeploymentConfig) error {
+	ctx := cmd.Context()
+	if err := checkWatchServices(targets); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Selected services: %s\n", serviceNames(targets))
+	builders := make(map[*config.DeploymentConfig]*docker.Builder, len(targets))
+	for _, cfg := range targets {
+		applyBuildFlags(cfg)
+		dockerBuilder, err := newDockerBuilder(cfg)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", cfg.Metadata.Name, err)
+		}
+		builders[cfg] = dockerBuilder
+	}
+
+	if dryRun {
+		for _, cfg := range targets {
+			dockerBuilder := builders[cfg]
+			kubeContext := targetKubeContext(cfg)
+			printStartupBanner(cfg, kubeContext, dockerBuilder.Name())
+			if err := runDryRun(ctx, cfg, kubeContext, dockerBuilder.Name()); err != nil {
+				return fmt.Errorf("service %s: %w", cfg.Metadata.Name, err)
+			}
+		}
+		return nil
+	}
+
+	// Deploys are refused if the kubeconfig context changes mid-session
+	contextPin, err := kubeconfig.PinCurrentContext()
+	if err != nil {
+		fmt.Printf("⚠ Context drift detection disabled: %v\n", err)
+	} else {
+		clientContext = contextPin.Name()
+	}
+
+	clientset, restConfig, err := getKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	var (
+		configs []watch.OrchestratorConfig
+		mu      sync.Mutex
+	)
+	for _, cfg := range targets {
+		if err := resolveLocalPort(cfg); err != nil {
+			return err
+		}
+		kubeContext := targetKubeContext(cfg)
+		dockerBuilder := builders[cfg]
+		printStartupBanner(cfg, kubeContext, dockerBuilder.Name())
+
+		renderer, err := deployer.NewRendererForConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("service %s: failed to create renderer: %w", cfg.Metadata.Name, err)
+		}
+		dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+		recordArtifacts(dep, cfg)
+		dep.SetServerValidation(validateServer)
+		reg := newRegistry(kubeContext, dockerBuilder)
+		history := state.NewStore(cfg.ProjectRoot)
+
+		warnIfPlatformMismatch(ctx, cfg, dep, dockerBuilder)
+		deployOpts, err := initialWatchDeploy(ctx, cfg, dockerBuilder, dep, dep, reg, history)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", cfg.Metadata.Name, err)
+		}
+
+		var syncer watch.FileSyncer
+		if len(cfg.Spec.Sync) > 0 {
+			syncer = filesync.NewPodSyncer(clientset, restConfig, logger)
+		}
+		configs = append(configs, watch.OrchestratorConfig{
+			Config:   cfg,
+			Builder:  dockerBuilder,
+			Deployer: dep,
+			Registry: reg,
+			Logger:   logger,
+			State:    history,
+
+			LastDeploy:     &deployOpts,
+			ContextPin:     contextPin,
+			OnContextDrift: clusterClients.Reset,
+			Syncer:         syncer,
+			Pruner:         newWatchPruner(cfg, dockerBuilder),
+			Bell:           watchBell,
+		})
+	}
+
+	if !watchNoPortFwd {
+		forwards, err := forwardServices(ctx, targets)
+		if err != nil {
+			return err
+		}
+		defer stopForwards(forwards)
+	}
+
+	// The orchestrators move each service's logs to its new pods
+	if !watchNoLogs {
+		for i := range configs {
+			tailer, err := serviceTailer(configs[i].Config, &mu, watchTailLines)
+			if err != nil {
+				return err
+			}
+			handle := tailer.Start(ctx, configs[i].Config.Metadata.Name, configs[i].Config.Spec.Namespace)
+			defer handle.Stop()
+			configs[i].Logs = handle
+		}
+	}
+
+	orchestrators := make([]*watch.Orchestrator, 0, len(configs))
+	for _, oc := range configs {
+		orchestrator, err := watch.NewOrchestrator(oc)
+		if err != nil {
+			return fmt.Errorf("failed to create orchestrator for %s: %w", oc.Config.Metadata.Name, err)
+		}
+		defer orchestrator.Close()
+		orchestrators = append(orchestrators, orchestrator)
+	}
+
+	fmt.Printf("✓ Watching %s (Ctrl+C to stop)\n", serviceNames(targets))
+
+	// One failing watcher stops them all
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(orchestrators))
+	for i, orchestrator := range orchestrators {
+		go func(name string, o *watch.Orchestrator) {
+			err := o.Run(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				err = fmt.Errorf("service %s: %w", name, err)
+				cancel()
+			} else {
+				err = nil
+			}
+			errs <- err
+		}(targets[i].Metadata.Name, orchestrator)
+	}
+
+	var firstErr error
+	for range orchestrators {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	fmt.Println("\nShutting down...")
+	return nil
+}
@@ -4,27 +4,41 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/pkg/deployer"
-	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/pkg/output"
+	"github.com/nanaki-93/kudev/pkg/state"
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show deployment status",
-	Long:  `Show the current status of the deployed application.`,
-	RunE:  runStatus,
+	Long: `Show the current status of the deployed application.
+
+Works without .kudev.yaml when the app is given by flags:
+  kudev status --name myapp --namespace dev
+
+With -o json or -o yaml the status (including pods) is printed for
+scripts and editor extensions; with --watch, one document per refresh.`,
+	RunE: runStatus,
 }
 
 var (
-	watchStatus bool
+	watchStatus  bool
+	statusOutput string
 )
 
 func init() {
 	statusCmd.Flags().BoolVarP(&watchStatus, "watch", "w", false, "Watch status continuously")
+	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", "text", "Output format: text, json or yaml")
+
+	addTargetFlags(statusCmd)
 
 	rootCmd.AddCommand(statusCmd)
 }
@@ -32,6 +46,11 @@ func init() {
 func runStatus(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	format, err := output.ParseFormat(statusOutput)
+	if err != nil {
+		return err
+	}
+
 	// 1. Load configuration
 	cfg := getLoadedConfig()
 
@@ -40,13 +59,20 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
-	renderer, _ := deployer.NewRenderer(
-		templates.DeploymentTemplate,
-		templates.ServiceTemplate,
-	)
+	renderer, err := deployer.NewRendererForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create renderer: %w", err)
+	}
 	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
 
 	// 3. Print status
+	formatter, err := output.New(format, func(w io.Writer, v any) error {
+		return writeStatusText(w, v.(*deployer.DeploymentStatus), portSubstitutions(cfg))
+	})
+	if err != nil {
+		return err
+	}
+
 	printStatus := func() error {
 		status, err := dep.Status(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
 		if err != nil {
@@ -54,39 +80,11 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		}
 
 		// Clear screen if watching
-		if watchStatus {
+		if watchStatus && format == output.FormatText {
 			fmt.Print("\033[H\033[2J")
 		}
 
-		fmt.Println("═══════════════════════════════════════════════════")
-		fmt.Printf("  Deployment: %s\n", status.DeploymentName)
-		fmt.Printf("  Namespace:  %s\n", status.Namespace)
-		fmt.Printf("  Status:     %s\n", colorStatus(status.Status))
-		fmt.Printf("  Replicas:   %d/%d ready\n", status.ReadyReplicas, status.DesiredReplicas)
-		if status.ImageHash != "" {
-			fmt.Printf("  Version:    %s\n", status.ImageHash)
-		}
-		fmt.Println("═══════════════════════════════════════════════════")
-
-		if len(status.Pods) > 0 {
-			fmt.Println()
-			fmt.Println("Pods:")
-			for _, pod := range status.Pods {
-				ready := "○"
-				if pod.Ready {
-					ready = "●"
-				}
-				fmt.Printf("  %s %s (%s, restarts: %d)\n",
-					ready, pod.Name, pod.Status, pod.Restarts)
-			}
-		}
-
-		if status.Message != "" {
-			fmt.Println()
-			fmt.Println(status.Message)
-		}
-
-		return nil
+		return formatter.Write(os.Stdout, status)
 	}
 
 	// Initial status
@@ -96,8 +94,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Watch mode
 	if watchStatus {
-		fmt.Println()
-		fmt.Println("Watching for changes (Ctrl+C to stop)...")
+		if format == output.FormatText {
+			fmt.Println()
+			fmt.Println("Watching for changes (Ctrl+C to stop)...")
+		}
 
 		ticker := time.NewTicker(2 * time.Second)
 		defer ticker.Stop()
@@ -108,7 +108,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 				return nil
 			case <-ticker.C:
 				if err := printStatus(); err != nil {
-					fmt.Printf("Error: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				}
 			}
 		}
@@ -117,6 +117,83 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// writeStatusText prints the human-readable status banner and pods.
+func writeStatusText(w io.Writer, status *deployer.DeploymentStatus, subs []state.PortSubstitution) error {
+	fmt.Fprintln(w, "═══════════════════════════════════════════════════")
+	fmt.Fprintf(w, "  Deployment: %s\n", status.DeploymentName)
+	fmt.Fprintf(w, "  Namespace:  %s\n", status.Namespace)
+	fmt.Fprintf(w, "  Status:     %s\n", colorStatus(status.Status))
+	fmt.Fprintf(w, "  Replicas:   %d/%d ready\n", status.ReadyReplicas, status.DesiredReplicas)
+	if status.ImageHash != "" {
+		fmt.Fprintf(w, "  Version:    %s\n", status.ImageHash)
+	}
+	for _, sub := range subs {
+		fmt.Fprintf(w, "  Port:       %d (configured %d was busy)\n", sub.Actual, sub.Configured)
+	}
+	if status.ExpiresAt != nil {
+		fmt.Fprintf(w, "  Expires:    %s\n", formatExpiry(*status.ExpiresAt))
+	}
+	fmt.Fprintln(w, "═══════════════════════════════════════════════════")
+
+	if len(status.Pods) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Pods:")
+		for _, pod := range status.Pods {
+			ready := "○"
+			if pod.Ready {
+				ready = "●"
+			}
+			fmt.Fprintf(w, "  %s %s (%s, restarts: %d)\n",
+				ready, pod.Name, pod.Status, pod.Restarts)
+			if pod.Message != "" {
+				fmt.Fprintf(w, "      %s\n", pod.Message)
+			}
+		}
+	}
+
+	if len(status.Events) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Recent warnings:")
+		for _, ev := range status.Events {
+			count := ""
+			if ev.Count > 1 {
+				count = fmt.Sprintf(" (x%d)", ev.Count)
+			}
+			fmt.Fprintf(w, "  ⚠ %s ago  %s  %s: %s%s\n",
+				formatAge(ev.LastSeen), ev.Object, ev.Reason, ev.Message, count)
+		}
+	}
+
+	if status.Message != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, status.Message)
+	}
+
+	return nil
+}
+
+// formatExpiry renders a spec.ttl expiry, e.g. "in 2d" or "expired 3h ago".
+func formatExpiry(t time.Time) string {
+	if until := time.Until(t); until > 0 {
+		return fmt.Sprintf("in %s (redeploy to extend)", formatDuration(until))
+	}
+	return fmt.Sprintf("expired %s ago (removed by 'kudev gc' or the reaper)", formatAge(t))
+}
+
+// portSubstitutions returns the busy local ports a running 'kudev up/watch'
+// moved, or nil when unknown.
+func portSubstitutions(cfg *config.DeploymentConfig) []state.PortSubstitution {
+	if cfg.ProjectRoot == "" {
+		return nil
+	}
+	st, err := state.NewStore(cfg.ProjectRoot).Load()
+	if err != nil {
+		logger.Debug("failed to load state", "error", err)
+		return nil
+	}
+	return st.PortSubstitutions
+}
+
 func colorStatus(status string) string {
 	switch status {
 	case "Running":
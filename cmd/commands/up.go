@@ -9,18 +9,21 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/nanaki-93/kudev/pkg/artifacts"
 	"github.com/nanaki-93/kudev/pkg/builder"
 	"github.com/nanaki-93/kudev/pkg/builder/docker"
+	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/pkg/deployer"
 	"github.com/nanaki-93/kudev/pkg/hash"
+	"github.com/nanaki-93/kudev/pkg/logging"
 	"github.com/nanaki-93/kudev/pkg/logs"
 	"github.com/nanaki-93/kudev/pkg/portfwd"
 	"github.com/nanaki-93/kudev/pkg/registry"
-	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/pkg/timing"
 )
 
 var upCmd = &cobra.Command{
-	Use:   "up",
+	Use:   "up [service...]",
 	Short: "Build and deploy application to Kubernetes",
 	Long: `Build and deploy application to Kubernetes.
 
@@ -28,9 +31,29 @@ This command:
 1. Builds a Docker image from your source code
 2. Loads the image to your local Kubernetes cluster
 3. Deploys or updates the Deployment and Service
-4. Forwards a local port to the pod
+4. Forwards a local port to the pod (a free one nearby if it is busy,
+   unless --strict-ports is set)
 5. Streams pod logs to your terminal
 
+With spec.image.ref, steps 1 and 2 are skipped: the pre-built image is
+deployed (by its current digest, when it can be resolved) and the
+cluster pulls it.
+
+With --dry-run, the image tag and manifests are printed and validated
+with a server-side dry run; nothing is built or changed.
+With --validate-server, the deploy itself is preceded by that dry run,
+so an admission webhook denial stops it before anything is applied.
+
+The applied manifests are kept in .kudev/artifacts/<timestamp>-<hash>/,
+so two deploys can be compared with diff.
+
+With a multi-service config file (several '---' separated configs),
+name the services to deploy, or leave some out with --exclude; the
+others are not touched. Several services are deployed in file order,
+then forwarded like 'kudev portfwd --all', with their logs prefixed:
+  kudev up api web
+  kudev up --exclude db
+
 Press Ctrl+C to stop log streaming and port forwarding.
 The deployment will remain running.`,
 	RunE: runUp,
@@ -40,19 +63,56 @@ var (
 	noLogs    bool
 	noPortFwd bool
 	noBuild   bool
+	tailLines int64
 )
 
 func init() {
 	upCmd.Flags().BoolVar(&noLogs, "no-logs", false, "Don't stream logs after deployment")
 	upCmd.Flags().BoolVar(&noPortFwd, "no-port-forward", false, "Don't start port forwarding")
 	upCmd.Flags().BoolVar(&noBuild, "no-build", false, "Skip build step (use existing image)")
+	upCmd.Flags().Int64Var(&tailLines, "tail", logs.DefaultTailLines, "Existing log lines to show when streaming starts (-1 for all)")
+	addPprofFlag(upCmd)
+	addStrictPortsFlag(upCmd)
+	addBuildFlags(upCmd)
+	addValidateServerFlag(upCmd)
+	addServiceArgs(upCmd)
 
+	markDryRun(upCmd)
 	rootCmd.AddCommand(upCmd)
 }
 
 func runUp(cmd *cobra.Command, args []string) error {
+	if len(loadedTargets) > 1 {
+		return runUpServices(cmd, loadedTargets)
+	}
 	ctx := cmd.Context()
 
+	// 1. Load configuration
+	fmt.Println("✓ Loading configuration...")
+	cfg := getLoadedConfig()
+	applyBuildFlags(cfg)
+
+	kubeContext := targetKubeContext(cfg)
+	dockerBuilder, err := newDockerBuilder(cfg)
+	if err != nil {
+		return err
+	}
+	builderName := dockerBuilder.Name()
+	build := !noBuild && cfg.PrebuiltImage() == ""
+	if !build {
+		builderName = ""
+	}
+
+	if dryRun {
+		printStartupBanner(cfg, kubeContext, builderName)
+		return runDryRun(ctx, cfg, kubeContext, builderName)
+	}
+
+	if err := resolveLocalPort(cfg); err != nil {
+		return err
+	}
+	printStartupBanner(cfg, kubeContext, builderName)
+
 	// Create cleanup list
 	var cleanups []func()
 	defer func() {
@@ -62,55 +122,127 @@ func runUp(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
-	// 1. Load configuration
-	fmt.Println("✓ Loading configuration...")
-	cfg := getLoadedConfig()
+	stopPprof, err := startPprof(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	cleanups = append(cleanups, stopPprof)
+
+	status, err := deployService(ctx, cfg, dockerBuilder, kubeContext, build)
+	if err != nil {
+		return err
+	}
+	clientset, restConfig, err := getKubernetesClient()
+	if err != nil {
+		return fmt.Errorf("failed to get kubernetes client: %w", err)
+	}
 
-	projectRoot := cfg.ProjectRoot
+	// 8. Start port forwarding (if enabled)
+	var forwarder portfwd.PortForwarder
+	if !noPortFwd {
+		var cleanupPorts func()
+		forwarder, cleanupPorts = startPortForward(ctx, cfg, clientset, restConfig)
+		cleanups = append(cleanups, func() {
+			forwarder.Stop()
+			fmt.Println("✓ Port forward stopped")
+			printForwardStats(forwarder)
+			cleanupPorts()
+		})
+	}
 
+	// Print success message
+	fmt.Println()
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Printf("  Application is running!\n")
+	printForwardURLs(cfg)
+	fmt.Printf("  Status:  %s (%d/%d replicas)\n", status.Status, status.ReadyReplicas, status.DesiredReplicas)
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Println()
+
+	// 9. Stream logs (if enabled)
+	if !noLogs {
+		fmt.Println("Streaming logs (Ctrl+C to stop)...")
+		fmt.Println()
+
+		tailer := logs.NewKubernetesLogTailer(clientset, logger, os.Stdout)
+		tailer.SetTailLines(tailLines)
+		tailer.SetContainer(cfg.Spec.ContainerName)
+		if err := tailer.TailLogsWithRetry(ctx, cfg.Metadata.Name, cfg.Spec.Namespace); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				fmt.Printf("Log streaming ended: %v\n", err)
+			}
+		}
+	} else {
+		fmt.Println("Press Ctrl+C to stop port forwarding...")
+		<-ctx.Done()
+	}
+
+	// Cleanup
+	if forwarder != nil {
+		forwarder.Stop()
+	}
+
+	fmt.Println("\nShutting down...")
+	fmt.Println("✓ Port forward stopped")
+	fmt.Println("✓ Deployment remains running (use 'kudev down' to remove)")
+
+	return nil
+}
+
+// deployService runs steps 2 to 7 of up for one service: hash, build and
+// load the image (unless build is false), deploy, and wait until ready.
+func deployService(ctx context.Context, cfg *config.DeploymentConfig, dockerBuilder *docker.Builder, kubeContext string, build bool) (*deployer.DeploymentStatus, error) {
 	var imageRef *builder.ImageRef
 	var imageHash string
 	var err error
-	if !noBuild {
+	if build {
 		// 2. Calculate source hash
 		fmt.Println("✓ Calculating source hash...")
-		calculator := hash.NewCalculator(projectRoot, cfg.Spec.BuildContextExclusions)
+		calculator := hash.ForConfig(cfg)
+		endHash := timing.Phase(ctx, "hash")
 		imageHash, err = calculator.Calculate(ctx)
+		endHash()
 		if err != nil {
-			return fmt.Errorf("failed to calculate hash: %w", err)
+			return nil, fmt.Errorf("failed to calculate hash: %w", err)
 		}
 
 		// 3. Generate image tag
 		tagger := builder.NewTagger(calculator)
 		tag, err := tagger.GenerateTag(ctx, false)
 		if err != nil {
-			return fmt.Errorf("failed to generate tag: %w", err)
+			return nil, fmt.Errorf("failed to generate tag: %w", err)
 		}
 
 		// 4. Build image
 		fmt.Printf("✓ Building image %s:%s...\n", cfg.Spec.ImageName, tag)
-		dockerBuilder := docker.NewBuilder(logger)
-		opts := builder.BuildOptions{
-			SourceDir:      projectRoot,
-			DockerfilePath: cfg.Spec.DockerfilePath,
-			ImageName:      cfg.Spec.ImageName,
-			ImageTag:       tag,
-		}
+		opts := builder.NewBuildOptions(cfg, tag)
+		opts.Output = cfg.Spec.Watch.EffectiveBuildOutput()
 
+		endBuild := timing.Phase(ctx, "build")
 		imageRef, err = dockerBuilder.Build(ctx, opts)
+		endBuild()
 		if err != nil {
-			return fmt.Errorf("failed to build image: %w", err)
+			emitBuildFailed(ctx, cfg, tag, err)
+			return nil, fmt.Errorf("failed to build image: %w", err)
 		}
+		fmt.Printf("✓ Image ID: %s\n", imageRef.ID)
 
 		// 5. Load image to cluster
 		fmt.Println("✓ Loading image to cluster...")
-		kubeContext := cfg.Spec.KubeContext
-		if kubeContext == "" {
-			kubeContext = getCurrentContext()
+		reg := newRegistry(kubeContext, dockerBuilder)
+		endLoad := timing.Phase(ctx, "load")
+		err = reg.Load(ctx, imageRef.FullRef)
+		endLoad()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load image: %w", err)
 		}
-		reg := registry.NewRegistry(kubeContext, logger)
-		if err := reg.Load(ctx, imageRef.FullRef); err != nil {
-			return fmt.Errorf("failed to load image: %w", err)
+	} else if cfg.PrebuiltImage() != "" {
+		// Built elsewhere: the cluster pulls it
+		fmt.Printf("✓ Using pre-built image %s...\n", cfg.PrebuiltImage())
+		imageRef = prebuiltImage(ctx, cfg, dockerBuilder)
+		imageHash = prebuiltHash(imageRef)
+		if imageRef.Digest != "" {
+			fmt.Printf("✓ Image digest: %s\n", imageRef.Digest)
 		}
 	} else {
 		// Use existing image
@@ -122,85 +254,220 @@ func runUp(cmd *cobra.Command, args []string) error {
 
 	// 6. Deploy to Kubernetes
 	fmt.Println("✓ Deploying to Kubernetes...")
-	clientset, restConfig, err := getKubernetesClient()
+	clientset, _, err := getKubernetesClient()
 	if err != nil {
-		return fmt.Errorf("failed to get kubernetes client: %w", err)
+		return nil, fmt.Errorf("failed to get kubernetes client: %w", err)
+	}
+	renderer, err := deployer.NewRendererForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create renderer: %w", err)
 	}
-	renderer, _ := deployer.NewRenderer(
-		templates.DeploymentTemplate,
-		templates.ServiceTemplate,
-	)
 	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+	recordArtifacts(dep, cfg)
+	dep.SetServerValidation(validateServer)
 
 	deployOpts := deployer.DeploymentOptions{
 		Config:    cfg,
-		ImageRef:  imageRef.FullRef,
+		ImageRef:  imageRef.DeployRef(),
 		ImageHash: imageHash,
 	}
 
+	if build {
+		warnIfPlatformMismatch(ctx, cfg, dep, dockerBuilder)
+	}
+	warnIfUnschedulable(ctx, dep, deployOpts)
+
+	endDeploy := timing.Phase(ctx, "deploy")
 	status, err := dep.Upsert(ctx, deployOpts)
+	endDeploy()
 	if err != nil {
-		return fmt.Errorf("failed to deploy: %w", err)
+		return nil, fmt.Errorf("failed to deploy: %w", err)
 	}
+	emitEvent(ctx, dep, cfg, deployer.ReasonDeployed, fmt.Sprintf("Deployed %s (source hash %s)", deployOpts.ImageRef, imageHash))
 
 	// 7. Wait for deployment to be ready
 	fmt.Println("✓ Waiting for pods to be ready...")
-	if err := dep.WaitForReady(ctx, cfg.Metadata.Name, cfg.Spec.Namespace, 5*time.Minute); err != nil {
-		return fmt.Errorf("deployment not ready: %w", err)
+	endReady := timing.Phase(ctx, "ready")
+	err = dep.WaitForReady(ctx, deployer.WaitOptions{
+		AppName:   cfg.Metadata.Name,
+		Namespace: cfg.Spec.Namespace,
+		Timeout:   readinessTimeout(cfg),
+		Readiness: cfg.Spec.Readiness,
+		WorkDir:   cfg.ProjectRoot,
+		Progress:  printPodProgress,
+	})
+	endReady()
+	if err != nil {
+		return nil, fmt.Errorf("deployment not ready: %w", err)
 	}
+	return status, nil
+}
 
-	// 8. Start port forwarding (if enabled)
-	var forwarder portfwd.PortForwarder
-	if !noPortFwd {
-		fmt.Printf("✓ Port forwarding localhost:%d → pod:%d\n",
-			cfg.Spec.LocalPort, cfg.Spec.ServicePort)
-
-		forwarder = portfwd.NewKubernetesPortForwarder(clientset, restConfig, logger)
-		if err := forwarder.Forward(ctx, cfg.Metadata.Name, cfg.Spec.Namespace,
-			cfg.Spec.LocalPort, cfg.Spec.ServicePort); err != nil {
-			fmt.Printf("⚠ Port forwarding failed: %v\n", err)
-			// Continue anyway - user can forward manually
-			//fixme return error or not?
+var (
+	buildNoCache bool
+	buildPull    bool
+	buildVerbose bool
+)
+
+// addBuildFlags registers --no-cache and --pull, the one-run forms of
+// spec.build.noCache and spec.build.pull, and --verbose for
+// spec.watch.buildOutput: verbose.
+func addBuildFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&buildNoCache, "no-cache", false, "Build without the layer cache (every build of this run)")
+	cmd.Flags().BoolVar(&buildPull, "pull", false, "Always pull base images when building, instead of using local copies")
+	cmd.Flags().BoolVar(&buildVerbose, "verbose", false, "Show the raw docker build output instead of the step progress display")
+}
+
+// applyBuildFlags turns on spec.build.noCache and spec.build.pull for
+// --no-cache and --pull, and sets spec.watch.buildOutput to verbose for
+// --verbose. Pre-built images are not built.
+func applyBuildFlags(cfg *config.DeploymentConfig) {
+	if buildVerbose {
+		if cfg.Spec.Watch == nil {
+			cfg.Spec.Watch = &config.WatchConfig{}
 		}
-		cleanups = append(cleanups, func() {
-			forwarder.Stop()
-			fmt.Println("✓ Port forward stopped")
-		})
+		cfg.Spec.Watch.BuildOutput = config.BuildOutputVerbose
+	}
+	if (!buildNoCache && !buildPull) || cfg.PrebuiltImage() != "" {
+		return
+	}
+	if cfg.Spec.Build == nil {
+		cfg.Spec.Build = &config.BuildConfig{}
 	}
+	cfg.Spec.Build.NoCache = cfg.Spec.Build.NoCache || buildNoCache
+	cfg.Spec.Build.Pull = cfg.Spec.Build.Pull || buildPull
+}
 
-	// Print success message
-	fmt.Println()
-	fmt.Println("═══════════════════════════════════════════════════")
-	fmt.Printf("  Application is running!\n")
-	fmt.Printf("  Local:   http://localhost:%d\n", cfg.Spec.LocalPort)
-	fmt.Printf("  Status:  %s (%d/%d replicas)\n", status.Status, status.ReadyReplicas, status.DesiredReplicas)
-	fmt.Println("═══════════════════════════════════════════════════")
-	fmt.Println()
+// newDockerBuilder returns the image builder for spec.build.backend: the
+// docker CLI, or the Engine API through the Docker SDK.
+func newDockerBuilder(cfg *config.DeploymentConfig) (*docker.Builder, error) {
+	if cfg == nil || cfg.BuildBackend() != config.BuildBackendAPI {
+		return docker.NewBuilder(logger), nil
+	}
+	dockerBuilder, err := docker.NewAPIBuilder(logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up the api build backend: %w", err)
+	}
+	return dockerBuilder, nil
+}
 
-	// 9. Stream logs (if enabled)
-	if !noLogs {
-		fmt.Println("Streaming logs (Ctrl+C to stop)...")
-		fmt.Println()
+// newRegistry returns the image loader for kubeContext. With the api
+// build backend it reaches the daemon through the builder's SDK client
+// instead of the docker CLI.
+func newRegistry(kubeContext string, dockerBuilder *docker.Builder) *registry.Registry {
+	reg := registry.NewRegistry(kubeContext, logger)
+	// A nil *APIClient must not become a non-nil Engine
+	if engine := dockerBuilder.Engine(); engine != nil {
+		reg.WithEngine(engine)
+	}
+	return reg
+}
 
-		tailer := logs.NewKubernetesLogTailer(clientset, logger, os.Stdout)
-		if err := tailer.TailLogsWithRetry(ctx, cfg.Metadata.Name, cfg.Spec.Namespace); err != nil {
-			if !errors.Is(err, context.Canceled) {
-				fmt.Printf("Log streaming ended: %v\n", err)
-			}
-		}
-	} else {
-		fmt.Println("Press Ctrl+C to stop port forwarding...")
-		<-ctx.Done()
+// emitEvent records a Kubernetes Event on the app's Deployment if dep
+// supports it. Best-effort: errors are only logged.
+func emitEvent(ctx context.Context, dep deployer.Deployer, cfg *config.DeploymentConfig, reason, message string) {
+	emitter, ok := dep.(deployer.EventEmitter)
+	if !ok {
+		return
+	}
+	if err := emitter.Emit(ctx, cfg.Metadata.Name, cfg.Spec.Namespace, reason, message); err != nil {
+		logger.Debug("failed to emit event", "reason", reason, "error", err)
 	}
+}
 
-	// Cleanup
-	if forwarder != nil {
-		forwarder.Stop()
+// emitBuildFailed records a failed build as a Warning event. The build runs
+// before the deployer exists, so a client is created just for it.
+func emitBuildFailed(ctx context.Context, cfg *config.DeploymentConfig, tag string, buildErr error) {
+	clientset, _, err := getKubernetesClient()
+	if err != nil {
+		logger.Debug("failed to emit event", "error", err)
+		return
 	}
+	emitEvent(ctx, deployer.NewKubernetesDeployer(clientset, nil, logger), cfg, deployer.ReasonBuildFailed,
+		logging.Redact(fmt.Sprintf("Build of %s failed: %v", tag, buildErr)))
+}
 
-	fmt.Println("\nShutting down...")
-	fmt.Println("✓ Port forward stopped")
-	fmt.Println("✓ Deployment remains running (use 'kudev down' to remove)")
+// readinessTimeout returns spec.readiness.timeout (zero means the
+// WaitOptions default).
+func readinessTimeout(cfg *config.DeploymentConfig) time.Duration {
+	if cfg.Spec.Readiness == nil {
+		return 0
+	}
+	return cfg.Spec.Readiness.Timeout.Duration
+}
 
-	return nil
+// printPodProgress prints the pods' startup state while waiting for
+// readiness, so slow image pulls and init containers don't look like a hang.
+func printPodProgress(pods []deployer.PodProgress) {
+	for _, p := range pods {
+		fmt.Printf("  %s\n", p)
+	}
+}
+
+// printForwardStats reports port-forward reconnects, if there were any.
+func printForwardStats(forwarder portfwd.PortForwarder) {
+	kpf, ok := forwarder.(*portfwd.KubernetesPortForwarder)
+	if !ok {
+		return
+	}
+	stats := kpf.Stats()
+	if stats.Disconnects == 0 {
+		return
+	}
+	fmt.Printf("  Port forward dropped %d time(s), reconnected %d time(s), %d failed attempt(s)\n",
+		stats.Disconnects, stats.Reconnects, stats.FailedAttempts)
+	if stats.GaveUp {
+		fmt.Printf("  Gave up reconnecting: %s\n", stats.LastError)
+	}
+}
+
+// recordArtifacts archives each deploy's manifests under .kudev/artifacts
+// unless spec.artifacts.disabled is set.
+func recordArtifacts(dep *deployer.KubernetesDeployer, cfg *config.DeploymentConfig) {
+	if !cfg.Spec.Artifacts.Enabled() {
+		return
+	}
+	dep.SetManifestRecorder(artifacts.NewStore(cfg.ProjectRoot, cfg.Spec.Artifacts.EffectiveKeep()))
+}
+
+// warnIfUnschedulable prints a warning when the cluster cannot fit the
+// requested replicas, so Pending pods don't go unexplained.
+// Preflight errors (e.g. no permission to list nodes) are not fatal.
+func warnIfUnschedulable(ctx context.Context, dep *deployer.KubernetesDeployer, opts deployer.DeploymentOptions) {
+	result, err := dep.Preflight(ctx, opts)
+	if err != nil {
+		logger.Debug("resource preflight skipped", "error", err)
+		return
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Printf("⚠ Insufficient cluster resources: %s\n", w)
+	}
+}
+
+// warnIfPlatformMismatch warns when the image is built for another
+// platform than the cluster nodes run, e.g. arm64 on Apple Silicon
+// against an amd64 kind cluster in a CI VM.
+func warnIfPlatformMismatch(ctx context.Context, cfg *config.DeploymentConfig, dep *deployer.KubernetesDeployer, b *docker.Builder) {
+	platform := cfg.BuildPlatform()
+	if platform == "" {
+		var err error
+		if platform, err = b.Platform(ctx); err != nil {
+			logger.Debug("platform check skipped", "error", err)
+			return
+		}
+	}
+
+	nodes, err := dep.NodePlatforms(ctx)
+	if err != nil {
+		logger.Debug("platform check skipped", "error", err)
+		return
+	}
+
+	if warning := deployer.PlatformMismatch(platform, nodes); warning != "" {
+		fmt.Printf("⚠ Platform mismatch: %s\n", warning)
+		if cfg.BuildPlatform() == "" && len(nodes) == 1 {
+			fmt.Printf("  Suggestion: set spec.build.platform to %s to cross-build with buildx\n", nodes[0])
+		}
+	}
 }
@@ -1,12 +1,18 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/pkg/logging"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+var validateCluster bool
+
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate configuration",
@@ -18,13 +24,22 @@ Checks:
   - All values are in valid ranges
   - Dockerfile exists
   - Kubernetes context is safe
+  - Local port is not used by another kudev project
+
+With --cluster, also checks against the target cluster:
+  - The Kubernetes context is reachable
+  - The namespace exists (kudev up creates it otherwise)
+  - Images can be loaded into the detected cluster type
+    (e.g. kind or minikube CLI installed, Docker running)
 
 Examples:
-  kudev validate              Validate .kudev.yaml in current dir
+  kudev validate                    Validate .kudev.yaml in current dir
   kudev validate --config dev.yaml  Validate specific config
+  kudev validate --cluster          Also check the cluster is ready
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		logger := logging.Get()
+		ctx := cmd.Context()
 
 		// Config is already loaded in PersistentPreRun
 		cfg := getLoadedConfig()
@@ -34,24 +49,116 @@ Examples:
 		}
 
 		logger.Info("configuration loaded successfully")
-		fmt.Printf("Configuration is valid ✓\n\n")
+
+		failed := 0
+		check := func(name string, err error) {
+			if err != nil {
+				failed++
+				fmt.Printf("❌ %s: %v\n", name, err)
+				return
+			}
+			fmt.Printf("✓ %s\n", name)
+		}
+
+		check("Configuration values", cfg.Validate(ctx))
+		check("Project files", cfg.ValidateWithContext(cfg.ProjectRoot))
+		if validateCluster {
+			validateAgainstCluster(ctx, cfg, check)
+		}
+		fmt.Println()
 
 		// Print summary
 		fmt.Printf("Project: %s\n", cfg.Metadata.Name)
-		fmt.Printf("Image: %s\n", cfg.Spec.ImageName)
-		fmt.Printf("Dockerfile: %s\n", cfg.Spec.DockerfilePath)
+		if cfg.PrebuiltImage() != "" {
+			fmt.Printf("Image: %s (pre-built)\n", cfg.PrebuiltImage())
+		} else {
+			fmt.Printf("Image: %s\n", cfg.Spec.ImageName)
+			fmt.Printf("Dockerfile: %s\n", cfg.Spec.DockerfilePath)
+		}
 		fmt.Printf("Namespace: %s\n", cfg.Spec.Namespace)
 		fmt.Printf("Replicas: %d\n", cfg.Spec.Replicas)
 		fmt.Printf("Service Port: %d\n", cfg.Spec.ServicePort)
-		fmt.Printf("Local Port: %d\n", cfg.Spec.LocalPort)
+		if cfg.Spec.AutoLocalPort {
+			fmt.Printf("Local Port: %s\n", config.LocalPortAuto)
+		} else {
+			fmt.Printf("Local Port: %d\n", cfg.Spec.LocalPort)
+		}
+
+		// An automatic port is allocated on first up/watch; nothing to check yet
+		if !cfg.Spec.AutoLocalPort {
+			if warning, free := portConflict(cfg.Spec.LocalPort, cfg.ProjectRoot); warning != "" {
+				fmt.Printf("\n⚠ Warning: %s\n", warning)
+				if free != 0 {
+					fmt.Printf("  Suggestion: set spec.localPort to %d (or auto)\n", free)
+				}
+			}
+		}
 
 		if len(cfg.Spec.Env) > 0 {
 			fmt.Printf("Environment Variables:\n")
 			for _, env := range cfg.Spec.Env {
+				if env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil {
+					ref := env.ValueFrom.ConfigMapKeyRef
+					fmt.Printf("  - %s from configmap %s/%s\n", env.Name, ref.Name, ref.Key)
+					continue
+				}
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					ref := env.ValueFrom.SecretKeyRef
+					fmt.Printf("  - %s from secret %s/%s\n", env.Name, ref.Name, ref.Key)
+					continue
+				}
 				fmt.Printf("  - %s=%s\n", env.Name, env.Value)
 			}
 		}
 
+		if failed > 0 {
+			return fmt.Errorf("validation failed: %d check(s) did not pass", failed)
+		}
 		return nil
 	},
 }
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateCluster, "cluster", false, "Also check the context is reachable, the namespace exists and images can be loaded")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateAgainstCluster runs the --cluster checks, reporting each
+// through check. A missing namespace is only a warning: up creates it.
+func validateAgainstCluster(ctx context.Context, cfg *config.DeploymentConfig, check func(string, error)) {
+	kubeContext := targetKubeContext(cfg)
+
+	clientset, _, err := getKubernetesClient()
+	if err == nil {
+		_, err = clientset.Discovery().ServerVersion()
+	}
+	check(fmt.Sprintf("Context %s is reachable", kubeContext), err)
+
+	// Without a cluster the namespace cannot be looked up
+	if err == nil {
+		_, err := clientset.CoreV1().Namespaces().Get(ctx, cfg.Spec.Namespace, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			fmt.Printf("⚠ Namespace %s does not exist (kudev up will create it)\n", cfg.Spec.Namespace)
+		default:
+			check(fmt.Sprintf("Namespace %s exists", cfg.Spec.Namespace), err)
+		}
+	}
+
+	// The cluster pulls a pre-built image itself
+	if cfg.PrebuiltImage() != "" {
+		return
+	}
+
+	dockerBuilder, err := newDockerBuilder(cfg)
+	if err != nil {
+		check("Docker build backend is set up", err)
+		return
+	}
+	loader, err := newRegistry(kubeContext, dockerBuilder).Check(ctx)
+	name := fmt.Sprintf("Image %s can be loaded", cfg.Spec.ImageName)
+	if loader != "" {
+		name = fmt.Sprintf("Image %s can be loaded (%s)", cfg.Spec.ImageName, loader)
+	}
+	check(name, err)
+}
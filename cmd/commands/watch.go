@@ -6,32 +6,88 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/nanaki-93/kudev/pkg/builder"
 	"github.com/nanaki-93/kudev/pkg/builder/docker"
+	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/filesync"
 	"github.com/nanaki-93/kudev/pkg/hash"
+	"github.com/nanaki-93/kudev/pkg/kubeconfig"
+	"github.com/nanaki-93/kudev/pkg/logging"
 	"github.com/nanaki-93/kudev/pkg/logs"
 	"github.com/nanaki-93/kudev/pkg/portfwd"
 	"github.com/nanaki-93/kudev/pkg/registry"
+	"github.com/nanaki-93/kudev/pkg/state"
+	"github.com/nanaki-93/kudev/pkg/timing"
 	"github.com/nanaki-93/kudev/pkg/watch"
-	"github.com/nanaki-93/kudev/templates"
 )
 
 var watchCmd = &cobra.Command{
-	Use:   "watch",
+	Use:   "watch [service...]",
 	Short: "Watch for changes and auto-rebuild",
 	Long: `Watch for file changes and automatically rebuild and redeploy.
 
 This command:
-1. Does an initial build and deploy
+1. Does an initial build and deploy. When the cluster already runs a
+   healthy deploy of the current source and .kudev.yaml, it is resumed
+   instead: restarting watch only reattaches logs and port forwarding
+   (see --force-initial-build)
 2. Starts port forwarding
 3. Watches for file changes
 4. Automatically rebuilds and redeploys on changes
 5. Shows logs from the running application
 
+If a redeployed pod keeps crashing, the crash reason and its last logs
+are shown inline (see spec.watch.crashLoop, which can also roll back).
+
+The cluster's capacity is checked every minute: when most of its CPU or
+memory is requested, or kudev pods are Pending for lack of it, watch
+warns and suggests which apps to scale down (see
+spec.watch.resourceBudget).
+
+Changes that only touch files listed in spec.sync are copied straight
+into the running pods instead of rebuilding the image.
+
+The kubeconfig context is pinned at startup: if it changes (e.g. after
+'kubectl config use-context'), rebuilds are refused until you switch
+back or restart watch.
+
+If the namespace or the app's resources are deleted while watching, the
+next cycle re-creates them from the last deploy.
+
+With --dry-run, the initial build and deploy are previewed (see 'kudev up
+--dry-run') and watch exits without watching.
+
+With --tui, watch takes over the terminal with a status header (build,
+deploy, pod health, source hash) above the logs. Keys: r rebuilds,
+p pauses/resumes watching, q quits.
+
+For CI and scripted integration tests:
+  --once            initial build and deploy, wait until ready, exit
+  --max-cycles N    also exit after N rebuilds; the exit status is
+                    non-zero if any of them failed or ended not ready
+
+Each rebuild ends with a one-line summary for tmux status lines and
+grep, e.g. "CYCLE 14 OK 8.2s hash=ab12cd34" (results: OK, NOTREADY,
+FROZEN, FAIL with stage=...). --bell also rings the terminal bell.
+
+With spec.image.ref (a pre-built image), nothing is built or watched:
+the registry is polled every spec.image.pollInterval and the app is
+redeployed when the tag points to a new digest.
+
+With a multi-service config file, name the services to watch, or leave
+some out with --exclude (kudev watch api web). Each selected service is
+rebuilt on its own changes; --tui, --listen, --once, --max-cycles and
+--blue-green need a single service.
+
+Deploys and failures are recorded for 'kudev history'.
+Run 'kudev freeze' to keep building without redeploying, e.g. while a
+debugger is attached.
+
 Press Ctrl+C to stop watching and exit.`,
 	RunE: runWatch,
 }
@@ -39,132 +95,382 @@ Press Ctrl+C to stop watching and exit.`,
 var (
 	watchNoLogs    bool
 	watchNoPortFwd bool
+	watchListen    string
+	watchBlueGreen bool
+	watchTailLines int64
+
+	watchForceInitialBuild bool
+	watchTUIEnabled        bool
+	watchBell              bool
+	watchAutoPrune         int
+	watchOnce              bool
+	watchMaxCycles         int
 )
 
 func init() {
 	watchCmd.Flags().BoolVar(&watchNoLogs, "no-logs", false, "Don't stream logs")
 	watchCmd.Flags().BoolVar(&watchNoPortFwd, "no-port-forward", false, "Don't start port forwarding")
+	watchCmd.Flags().Int64Var(&watchTailLines, "tail", logs.DefaultTailLines, "Existing log lines to show when streaming starts (-1 for all)")
+	watchCmd.Flags().BoolVar(&watchBlueGreen, "blue-green", false, "Deploy rebuilds to alternating blue/green slots and switch traffic when ready (experimental)")
+	watchCmd.Flags().BoolVar(&watchForceInitialBuild, "force-initial-build", false, "Build and deploy on startup even if the cluster already runs the current source")
+	watchCmd.Flags().BoolVar(&watchTUIEnabled, "tui", false, "Full-screen view with build/deploy status, pod health and logs (keys: r rebuild, p pause, q quit)")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Do the initial build and deploy, wait until ready and exit (for CI)")
+	watchCmd.Flags().IntVar(&watchMaxCycles, "max-cycles", 0, "Exit after this many rebuilds, with a non-zero status if any failed (for CI)")
+	watchCmd.MarkFlagsMutuallyExclusive("once", "max-cycles")
+	watchCmd.Flags().BoolVar(&watchBell, "bell", false, "Ring the terminal bell after each rebuild")
+	watchCmd.Flags().IntVar(&watchAutoPrune, "auto-prune", 0, "After each rebuild, delete all but the newest N images of the app (see 'kudev prune'; 0 disables)")
+	watchCmd.Flags().StringVar(&watchListen, "listen", "", "Expose POST /trigger on this address to force rebuilds (e.g. :4848, which binds 127.0.0.1)")
+	addPprofFlag(watchCmd)
+	addStrictPortsFlag(watchCmd)
+	addBuildFlags(watchCmd)
+	addValidateServerFlag(watchCmd)
+	addServiceArgs(watchCmd)
 
+	markDryRun(watchCmd)
 	rootCmd.AddCommand(watchCmd)
 }
 
 func runWatch(cmd *cobra.Command, args []string) error {
+	if len(loadedTargets) > 1 {
+		return runWatchServices(cmd, loadedTargets)
+	}
 	ctx := cmd.Context()
 
 	// 1. Load configuration
 	fmt.Println("✓ Loading configuration...")
 	cfg := loadedConfig
 	projectRoot := cfg.ProjectRoot
+	applyBuildFlags(cfg)
 
-	// 2. Get Kubernetes client
-	clientset, restConfig, err := getKubernetesClient()
-
+	kubeContext := targetKubeContext(cfg)
+	dockerBuilder, err := newDockerBuilder(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		return err
 	}
-	// 3. Create components
-	dockerBuilder := docker.NewBuilder(logger)
 
-	renderer, _ := deployer.NewRenderer(
-		templates.DeploymentTemplate,
-		templates.ServiceTemplate,
-	)
-	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+	// Only the initial build and deploy are previewed; nothing is watched
+	if dryRun {
+		printStartupBanner(cfg, kubeContext, dockerBuilder.Name())
+		return runDryRun(ctx, cfg, kubeContext, dockerBuilder.Name())
+	}
+
+	if cfg.PrebuiltImage() != "" {
+		if err := resolveLocalPort(cfg); err != nil {
+			return err
+		}
+		printStartupBanner(cfg, kubeContext, "")
+		return runWatchPrebuilt(cmd, cfg, dockerBuilder)
+	}
 
-	kubeContext := cfg.Spec.KubeContext
-	if kubeContext == "" {
-		kubeContext = getCurrentContext()
+	if watchTUIEnabled {
+		if err := checkTUITerminal(); err != nil {
+			return err
+		}
+	}
+	if watchMaxCycles < 0 {
+		return fmt.Errorf("--max-cycles cannot be negative, got %d", watchMaxCycles)
 	}
-	reg := registry.NewRegistry(kubeContext, logger)
 
-	// 4. Do initial build and deploy
-	fmt.Println("✓ Doing initial build and deploy...")
+	if err := resolveLocalPort(cfg); err != nil {
+		return err
+	}
+	printStartupBanner(cfg, kubeContext, dockerBuilder.Name())
 
-	calculator := hash.NewCalculator(projectRoot, cfg.Spec.BuildContextExclusions)
-	tagger := builder.NewTagger(calculator)
-	tag, err := tagger.GenerateTag(ctx, false)
+	stopPprof, err := startPprof(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to generate tag: %w", err)
+		return err
 	}
+	defer stopPprof()
 
-	opts := builder.BuildOptions{
-		SourceDir:      projectRoot,
-		DockerfilePath: cfg.Spec.DockerfilePath,
-		ImageName:      cfg.Spec.ImageName,
-		ImageTag:       tag,
+	// Deploys are refused if the kubeconfig context changes mid-session
+	contextPin, err := kubeconfig.PinCurrentContext()
+	if err != nil {
+		fmt.Printf("⚠ Context drift detection disabled: %v\n", err)
+	} else {
+		clientContext = contextPin.Name()
 	}
 
-	imageRef, err := dockerBuilder.Build(ctx, opts)
+	// 2. Get Kubernetes client
+	clientset, restConfig, err := getKubernetesClient()
+
 	if err != nil {
-		return fmt.Errorf("failed to build: %w", err)
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
+	// 3. Create components
 
-	if err := reg.Load(ctx, imageRef.FullRef); err != nil {
-		return fmt.Errorf("failed to load image: %w", err)
+	renderer, err := deployer.NewRendererForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create renderer: %w", err)
 	}
+	dep := deployer.NewKubernetesDeployer(clientset, renderer, logger)
+	recordArtifacts(dep, cfg)
+	dep.SetServerValidation(validateServer)
 
-	imageHash, _ := tagger.GetHash(ctx)
-	deployOpts := deployer.DeploymentOptions{
-		Config:    cfg,
-		ImageRef:  imageRef.FullRef,
-		ImageHash: imageHash,
+	// Blue/green swaps slots on every rebuild instead of rolling in place
+	var watchDep deployer.Deployer = dep
+	if watchBlueGreen || (cfg.Spec.Watch != nil && cfg.Spec.Watch.BlueGreen) {
+		watchDep = deployer.NewBlueGreenDeployer(dep)
+		fmt.Println("✓ Blue/green redeploys enabled (experimental)")
 	}
 
-	status, err := dep.Upsert(ctx, deployOpts)
+	reg := newRegistry(kubeContext, dockerBuilder)
+
+	// 4. Do initial build and deploy, unless the cluster already runs
+	// this exact source
+	history := state.NewStore(projectRoot)
+
+	// Every rebuild uses the same platform, so check it once
+	warnIfPlatformMismatch(ctx, cfg, dep, dockerBuilder)
+
+	deployOpts, err := initialWatchDeploy(ctx, cfg, dockerBuilder, dep, watchDep, reg, history)
 	if err != nil {
-		return fmt.Errorf("failed to deploy: %w", err)
+		return err
 	}
 
-	fmt.Printf("✓ Deployed: %s (%d/%d replicas)\n", status.Status, status.ReadyReplicas, status.DesiredReplicas)
+	// Scripted runs need the initial deploy to be ready, not just applied
+	if watchOnce || watchMaxCycles > 0 {
+		fmt.Println("Waiting for readiness...")
+		err := watchDep.WaitForReady(ctx, deployer.WaitOptions{
+			AppName:   cfg.Metadata.Name,
+			Namespace: cfg.Spec.Namespace,
+			Timeout:   readinessTimeout(cfg),
+			Readiness: cfg.Spec.Readiness,
+			WorkDir:   cfg.ProjectRoot,
+			Progress:  printPodProgress,
+		})
+		if err != nil {
+			return fmt.Errorf("deployment is not ready: %w", err)
+		}
+		fmt.Println("✓ Deployment is ready")
+	}
+	if watchOnce {
+		return nil
+	}
 
 	// 5. Start port forwarding (if enabled)
 	var forwarder portfwd.PortForwarder
 	if !watchNoPortFwd {
-		fmt.Printf("✓ Port forwarding localhost:%d → pod:%d\n",
-			cfg.Spec.LocalPort, cfg.Spec.ServicePort)
+		var cleanupPorts func()
+		forwarder, cleanupPorts = startPortForward(ctx, cfg, clientset, restConfig)
+		defer func() {
+			forwarder.Stop()
+			printForwardStats(forwarder)
+			cleanupPorts()
+		}()
+	}
 
-		forwarder = portfwd.NewKubernetesPortForwarder(clientset, restConfig, logger)
-		if err := forwarder.Forward(ctx, cfg.Metadata.Name, cfg.Spec.Namespace,
-			cfg.Spec.LocalPort, cfg.Spec.ServicePort); err != nil {
-			fmt.Printf("⚠ Port forwarding failed: %v\n", err)
+	// From here on, output goes to the TUI's log pane
+	var screen *watchTUI
+	if watchTUIEnabled {
+		screen, err = newWatchTUI(cfg)
+		if err != nil {
+			return err
 		}
-		defer forwarder.Stop()
+		defer screen.restore()
 	}
 
-	// 6. Start log streaming in background (if enabled)
+	// 6. Start log streaming in background (if enabled); the
+	// orchestrator moves it to the new pods after each redeploy
+	var logStream watch.LogSwitcher
 	if !watchNoLogs {
-		go func() {
-			tailer := logs.NewKubernetesLogTailer(clientset, logger, os.Stdout)
-			tailer.TailLogsWithRetry(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
-		}()
+		tailer := logs.NewKubernetesLogTailer(clientset, logger, os.Stdout)
+		tailer.SetTailLines(watchTailLines)
+		tailer.SetContainer(cfg.Spec.ContainerName)
+		handle := tailer.Start(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
+		defer handle.Stop()
+		logStream = handle
 	}
 
 	// 7. Print ready message
 	fmt.Println()
 	fmt.Println("═══════════════════════════════════════════════════")
 	fmt.Printf("  Application is running!\n")
-	fmt.Printf("  Local:   http://localhost:%d\n", cfg.Spec.LocalPort)
+	printForwardURLs(cfg)
 	fmt.Println("═══════════════════════════════════════════════════")
 	fmt.Println()
 
 	// 8. Create and run orchestrator
-	orchestrator, err := watch.NewOrchestrator(watch.OrchestratorConfig{
+	var syncer watch.FileSyncer
+	if len(cfg.Spec.Sync) > 0 {
+		syncer = filesync.NewPodSyncer(clientset, restConfig, logger)
+	}
+
+	orchestratorConfig := watch.OrchestratorConfig{
 		Config:   cfg,
 		Builder:  dockerBuilder,
-		Deployer: dep,
+		Deployer: watchDep,
 		Registry: reg,
 		Logger:   logger,
-	})
+		State:    history,
+
+		LastDeploy:     &deployOpts,
+		ContextPin:     contextPin,
+		OnContextDrift: clusterClients.Reset,
+		Syncer:         syncer,
+		Logs:           logStream,
+		Pruner:         newWatchPruner(cfg, dockerBuilder),
+		Bell:           watchBell,
+		MaxCycles:      watchMaxCycles,
+	}
+	if screen != nil {
+		orchestratorConfig.OnStatus = screen.ui.SetStatus
+	}
+	orchestrator, err := watch.NewOrchestrator(orchestratorConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create orchestrator: %w", err)
 	}
 	defer orchestrator.Close()
 
-	// Run until cancelled
+	// Optional HTTP trigger endpoint for external tools
+	if watchListen != "" {
+		triggerServer := watch.NewTriggerServer(watchListen, orchestrator, logger)
+		if err := triggerServer.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start trigger endpoint: %w", err)
+		}
+		fmt.Printf("✓ Rebuild trigger: curl -X POST http://%s/trigger\n", triggerServer.Addr())
+	}
+
+	// Run until cancelled (or q is pressed in the TUI)
+	if screen != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- screen.run(ctx, orchestrator, watchDep, cfg, cancel)
+		}()
+		// Leave the full-screen view before the deferred cleanup output
+		defer func() {
+			cancel()
+			if err := <-done; err != nil {
+				logger.Debug("tui failed", "error", err)
+			}
+		}()
+	}
+
 	if err := orchestrator.Run(ctx); err != nil && err != context.Canceled {
 		return err
 	}
+	if watchMaxCycles > 0 && ctx.Err() == nil {
+		fmt.Printf("\n✓ All %d cycles passed\n", watchMaxCycles)
+		return nil
+	}
 
 	fmt.Println("\nShutting down...")
 	return nil
 }
+
+// initialWatchDeploy is step 4 of watch: build and deploy cfg, unless
+// the cluster already runs this exact source (see --force-initial-build).
+func initialWatchDeploy(ctx context.Context, cfg *config.DeploymentConfig, dockerBuilder *docker.Builder, dep *deployer.KubernetesDeployer, watchDep deployer.Deployer, reg *registry.Registry, history *state.Store) (deployer.DeploymentOptions, error) {
+	start := time.Now()
+	tagger := builder.NewTagger(hash.ForConfig(cfg))
+
+	var deployOpts deployer.DeploymentOptions
+	var running *deployer.DeploymentOptions
+	if !watchForceInitialBuild {
+		var reason string
+		var err error
+		running, reason, err = runningDeploy(ctx, dep, cfg, tagger)
+		if err != nil {
+			logger.Debug("failed to check the running deployment", "error", err)
+		}
+		if reason != "" {
+			fmt.Printf("  Not resuming: %s\n", reason)
+		}
+	}
+	if running != nil {
+		deployOpts = *running
+		fmt.Printf("✓ Resuming: the cluster already runs this source and config (kudev-hash %s), skipping initial build\n", running.ImageHash)
+		fmt.Println("  Use --force-initial-build to rebuild anyway")
+	} else {
+		fmt.Println("✓ Doing initial build and deploy...")
+		tag, err := tagger.GenerateTag(ctx, false)
+		if err != nil {
+			return deployOpts, fmt.Errorf("failed to generate tag: %w", err)
+		}
+
+		opts := builder.NewBuildOptions(cfg, tag)
+		opts.Output = cfg.Spec.Watch.EffectiveBuildOutput()
+
+		endBuild := timing.Phase(ctx, "build")
+		imageRef, err := dockerBuilder.Build(ctx, opts)
+		endBuild()
+		if err != nil {
+			emitEvent(ctx, watchDep, cfg, deployer.ReasonBuildFailed,
+				logging.Redact(fmt.Sprintf("Build of %s failed: %v", tag, err)))
+			return deployOpts, fmt.Errorf("failed to build: %w", err)
+		}
+
+		endLoad := timing.Phase(ctx, "load")
+		err = reg.Load(ctx, imageRef.FullRef)
+		endLoad()
+		if err != nil {
+			return deployOpts, fmt.Errorf("failed to load image: %w", err)
+		}
+
+		imageHash, _ := tagger.GetHash(ctx)
+		deployOpts = deployer.DeploymentOptions{
+			Config:    cfg,
+			ImageRef:  imageRef.FullRef,
+			ImageHash: imageHash,
+		}
+
+		warnIfUnschedulable(ctx, dep, deployOpts)
+
+		endDeploy := timing.Phase(ctx, "deploy")
+		status, err := watchDep.Upsert(ctx, deployOpts)
+		endDeploy()
+		if err != nil {
+			return deployOpts, fmt.Errorf("failed to deploy: %w", err)
+		}
+
+		fmt.Printf("✓ Deployed: %s (%d/%d replicas)\n", status.Status, status.ReadyReplicas, status.DesiredReplicas)
+		emitEvent(ctx, watchDep, cfg, deployer.ReasonDeployed, fmt.Sprintf("Deployed %s (source hash %s)", deployOpts.ImageRef, imageHash))
+
+		if err := history.Record(state.Event{
+			Type:       state.EventDeploy,
+			Hash:       imageHash,
+			Image:      imageRef.FullRef,
+			ImageID:    imageRef.ID,
+			DurationMs: time.Since(start).Milliseconds(),
+		}); err != nil {
+			logger.Debug("failed to record history event", "error", err)
+		}
+	}
+	return deployOpts, nil
+}
+
+// runningDeploy returns the deploy already in the cluster when watch can
+// resume it (see deployer.CanResume), so restarting kudev only reattaches
+// logs and port forwards. Otherwise it returns nil and, when the app is
+// deployed, the reason it cannot be resumed.
+func runningDeploy(ctx context.Context, dep *deployer.KubernetesDeployer, cfg *config.DeploymentConfig, tagger *builder.Tagger) (*deployer.DeploymentOptions, string, error) {
+	sourceHash, err := tagger.GetHash(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	status, err := dep.Status(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
+	if err != nil {
+		// Not deployed yet
+		return nil, "", nil
+	}
+
+	// The manifests a redeploy of the running image would apply
+	opts := &deployer.DeploymentOptions{
+		Config:    cfg,
+		ImageRef:  status.Image,
+		ImageHash: sourceHash,
+	}
+	configHash, err := dep.ConfigHash(*opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if ok, reason := deployer.CanResume(status, sourceHash, configHash); !ok {
+		return nil, reason, nil
+	}
+	return opts, "", nil
+}
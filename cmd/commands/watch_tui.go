@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/tui"
+	"github.com/nanaki-93/kudev/pkg/watch"
+)
+
+// podHealthInterval is how long the TUI waits before retrying when no
+// cluster client is available for pod health.
+const podHealthInterval = 2 * time.Second
+
+// checkTUITerminal fails early when --tui can't take over the terminal.
+func checkTUITerminal() error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("--tui needs an interactive terminal")
+	}
+	return nil
+}
+
+// watchTUI is the full-screen view of 'kudev watch --tui'. While it
+// runs, everything printed to stdout and stderr (build output, pod logs,
+// klog) goes to its log pane.
+type watchTUI struct {
+	ui *tui.UI
+
+	stdout, stderr *os.File
+	pipe           *os.File
+	copied         chan struct{}
+}
+
+// newWatchTUI creates the UI and redirects stdout and stderr into it.
+// Call restore once done.
+func newWatchTUI(cfg *config.DeploymentConfig) (*watchTUI, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture output: %w", err)
+	}
+
+	t := &watchTUI{
+		ui:     tui.New(os.Stdout, cfg.Metadata.Name, cfg.Spec.Namespace),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		pipe:   w,
+		copied: make(chan struct{}),
+	}
+	go func() {
+		defer close(t.copied)
+		io.Copy(t.ui, r)
+		r.Close()
+	}()
+
+	os.Stdout, os.Stderr = w, w
+	return t, nil
+}
+
+// run shows the UI until ctx is done or q is pressed (which calls quit),
+// refreshing pod health in the background.
+func (t *watchTUI) run(ctx context.Context, orchestrator *watch.Orchestrator, dep deployer.Deployer, cfg *config.DeploymentConfig, quit func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go t.watchPods(ctx, dep, cfg)
+
+	return t.ui.Run(ctx, os.Stdin, tui.Keys{
+		Rebuild: func() {
+			orchestrator.Trigger("r pressed")
+		},
+		TogglePause: func() {
+			if orchestrator.Status().Paused {
+				orchestrator.Resume()
+				fmt.Println("▶ Watching resumed")
+				return
+			}
+			orchestrator.Pause()
+			fmt.Println("⏸ Watching paused: press p to resume, r to rebuild")
+		},
+		Quit: quit,
+	})
+}
+
+// watchPods refreshes the pod health shown in the header whenever the
+// shared informers see a pod or deployment change in the namespace. When
+// the cached clients are reset (the context drifted), it subscribes to
+// the new factory.
+func (t *watchTUI) watchPods(ctx context.Context, dep deployer.Deployer, cfg *config.DeploymentConfig) {
+	refresh := func() {
+		t.ui.SetDeployment(dep.Status(ctx, cfg.Metadata.Name, cfg.Spec.Namespace))
+	}
+
+	changed := make(chan struct{}, 1)
+	notify := func(obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		if o, ok := obj.(metav1.Object); ok && o.GetNamespace() != cfg.Spec.Namespace {
+			return
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    notify,
+		UpdateFunc: func(_, obj interface{}) { notify(obj) },
+		DeleteFunc: notify,
+	}
+
+	for {
+		factory, stop, err := clusterClients.Informers()
+		if err != nil {
+			t.ui.SetDeployment(nil, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(podHealthInterval):
+				continue
+			}
+		}
+
+		var removers []func()
+		for _, informer := range []cache.SharedIndexInformer{
+			factory.Core().V1().Pods().Informer(),
+			factory.Apps().V1().Deployments().Informer(),
+		} {
+			reg, err := informer.AddEventHandler(handler)
+			if err != nil {
+				continue
+			}
+			removers = append(removers, func() { informer.RemoveEventHandler(reg) })
+		}
+		factory.Start(stop)
+		refresh()
+
+	events:
+		for {
+			select {
+			case <-ctx.Done():
+				// The factory is shared: leave it running for other users
+				for _, remove := range removers {
+					remove()
+				}
+				return
+			case <-stop:
+				break events
+			case <-changed:
+				refresh()
+			}
+		}
+	}
+}
+
+// restore gives stdout and stderr back to the terminal.
+func (t *watchTUI) restore() {
+	os.Stdout, os.Stderr = t.stdout, t.stderr
+	t.pipe.Close()
+	<-t.copied
+}
@@ -0,0 +1,112 @@
+// Package artifacts keeps the manifests applied by recent deploys under
+// .kudev/artifacts/, so two rebuilds can be compared with a plain diff.
+package artifacts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirName is the artifacts directory inside .kudev.
+const DirName = "artifacts"
+
+// timestampFormat sorts lexicographically in chronological order.
+const timestampFormat = "20060102-150405.000"
+
+// Store writes one directory per deploy cycle and prunes old ones.
+type Store struct {
+	dir  string
+	keep int
+
+	// now is replaceable in tests
+	now func() time.Time
+}
+
+// NewStore creates a store for the given project root that retains
+// the last keep cycles. keep <= 0 retains everything.
+//
+// Cycles live at <projectRoot>/.kudev/artifacts/<timestamp>-<hash>/.
+func NewStore(projectRoot string, keep int) *Store {
+	return &Store{
+		dir:  filepath.Join(projectRoot, ".kudev", DirName),
+		keep: keep,
+		now:  time.Now,
+	}
+}
+
+// Dir returns the artifacts directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Write stores files (name → content) for one deploy cycle and prunes
+// cycles beyond the retention limit. Returns the cycle directory.
+func (s *Store) Write(hash string, files map[string]string) (string, error) {
+	if hash == "" {
+		hash = "unknown"
+	}
+	name := s.now().UTC().Format(timestampFormat) + "-" + hash
+	cycleDir := filepath.Join(s.dir, name)
+
+	if err := os.MkdirAll(cycleDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create artifacts directory %s: %w", cycleDir, err)
+	}
+
+	for fileName, content := range files {
+		path := filepath.Join(cycleDir, fileName)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if err := s.prune(); err != nil {
+		return cycleDir, err
+	}
+
+	return cycleDir, nil
+}
+
+// List returns the cycle directory names, oldest first.
+func (s *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read artifacts directory %s: %w", s.dir, err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// prune removes the oldest cycles beyond the retention limit.
+func (s *Store) prune() error {
+	if s.keep <= 0 {
+		return nil
+	}
+
+	names, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	for len(names) > s.keep {
+		path := filepath.Join(s.dir, names[0])
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove old artifacts %s: %w", path, err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
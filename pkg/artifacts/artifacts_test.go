@@ -0,0 +1,99 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, keep int) *Store {
+	t.Helper()
+	s := NewStore(t.TempDir(), keep)
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	calls := 0
+	s.now = func() time.Time {
+		calls++
+		return base.Add(time.Duration(calls) * time.Second)
+	}
+	return s
+}
+
+func TestStore_Write(t *testing.T) {
+	s := newTestStore(t, 5)
+
+	dir, err := s.Write("abc123", map[string]string{
+		"deployment.yaml": "kind: Deployment\n",
+		"service.yaml":    "kind: Service\n",
+	})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if got, want := filepath.Base(dir), "20240115-100001.000-abc123"; got != want {
+		t.Errorf("cycle dir = %q, want %q", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "deployment.yaml"))
+	if err != nil {
+		t.Fatalf("deployment.yaml not written: %v", err)
+	}
+	if string(data) != "kind: Deployment\n" {
+		t.Errorf("deployment.yaml = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "service.yaml")); err != nil {
+		t.Errorf("service.yaml not written: %v", err)
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	tests := []struct {
+		name   string
+		keep   int
+		writes int
+		want   int
+	}{
+		{"under limit", 3, 2, 2},
+		{"at limit", 3, 3, 3},
+		{"over limit", 3, 7, 3},
+		{"unlimited", 0, 7, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t, tt.keep)
+
+			var last string
+			for i := 0; i < tt.writes; i++ {
+				dir, err := s.Write("hash", map[string]string{"deployment.yaml": "x"})
+				if err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+				last = filepath.Base(dir)
+			}
+
+			names, err := s.List()
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if len(names) != tt.want {
+				t.Fatalf("got %d cycles, want %d: %v", len(names), tt.want, names)
+			}
+			if names[len(names)-1] != last {
+				t.Errorf("newest cycle = %q, want %q", names[len(names)-1], last)
+			}
+		})
+	}
+}
+
+func TestStore_ListMissingDir(t *testing.T) {
+	s := NewStore(t.TempDir(), 5)
+
+	names, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("expected no cycles, got %v", names)
+	}
+}
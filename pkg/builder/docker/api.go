@@ -0,0 +1,401 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// DefaultDockerHost is the daemon address used when neither DOCKER_HOST
+// nor a docker context names one.
+const DefaultDockerHost = client.DefaultDockerHost
+
+// APIClient reaches the Docker Engine API through the Docker SDK: the
+// calls kudev needs to build, inspect, list, remove and export images and
+// to run commands in cluster node containers, without the docker CLI.
+type APIClient struct {
+	client *client.Client
+}
+
+// BuildStepError is a build the daemon ran but that failed, e.g. a RUN
+// instruction exiting non-zero.
+type BuildStepError struct {
+	// Step is the last step that was started ("[2/3] RUN go build"), if any.
+	Step    string
+	Message string
+}
+
+func (e *BuildStepError) Error() string {
+	if e.Step == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Step, e.Message)
+}
+
+// BuildParams are the settings of an image build.
+type BuildParams struct {
+	Tag        string
+	Dockerfile string
+	BuildArgs  map[string]string
+	Target     string
+	NoCache    bool
+	Pull       bool
+}
+
+// NewAPIClientFromEnv returns a client for the daemon the docker CLI
+// would use: DOCKER_HOST (with DOCKER_TLS_VERIFY and DOCKER_CERT_PATH),
+// else the endpoint and TLS material of the active docker context
+// (DOCKER_CONTEXT, or currentContext in the docker config), else
+// DefaultDockerHost.
+func NewAPIClientFromEnv() (*APIClient, error) {
+	opts := []client.Opt{client.FromEnv}
+	if os.Getenv("DOCKER_HOST") == "" {
+		endpoint, err := contextEndpoint(dockerConfigDir(), os.Getenv("DOCKER_CONTEXT"))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithHost(endpoint.host))
+		if endpoint.tlsDir != "" {
+			opts = append(opts, client.WithTLSClientConfig(
+				existingFile(filepath.Join(endpoint.tlsDir, "ca.pem")),
+				existingFile(filepath.Join(endpoint.tlsDir, "cert.pem")),
+				existingFile(filepath.Join(endpoint.tlsDir, "key.pem")),
+			))
+		}
+	}
+	return newAPIClient(opts...)
+}
+
+// NewAPIClient returns a client for a daemon address such as
+// unix:///var/run/docker.sock or tcp://host:port.
+func NewAPIClient(host string) (*APIClient, error) {
+	return newAPIClient(client.WithHost(host))
+}
+
+// newAPIClient creates the SDK client. The API version is negotiated
+// with the daemon on the first call.
+func newAPIClient(opts ...client.Opt) (*APIClient, error) {
+	c, err := client.NewClientWithOpts(append(opts, client.WithAPIVersionNegotiation())...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+	// Dialing over ssh needs the docker CLI's connection helper
+	if strings.HasPrefix(c.DaemonHost(), "ssh://") {
+		c.Close()
+		return nil, fmt.Errorf("docker host %s uses ssh, which the api backend does not support; use spec.build.backend: cli", c.DaemonHost())
+	}
+	return &APIClient{client: c}, nil
+}
+
+// dockerConfigDir returns DOCKER_CONFIG, or ~/.docker.
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// dockerEndpoint is the docker endpoint of a docker context.
+type dockerEndpoint struct {
+	host string
+
+	// tlsDir holds the context's ca.pem, cert.pem and key.pem, or is ""
+	// when the endpoint has no TLS material.
+	tlsDir string
+}
+
+// contextEndpoint returns the docker endpoint of context name, or of the
+// config's currentContext when name is empty, as stored by 'docker
+// context create' under configDir. The default context is
+// DefaultDockerHost.
+func contextEndpoint(configDir, name string) (dockerEndpoint, error) {
+	if name == "" && configDir != "" {
+		var cfg struct {
+			CurrentContext string `json:"currentContext"`
+		}
+		if content, err := os.ReadFile(filepath.Join(configDir, "config.json")); err == nil {
+			if err := json.Unmarshal(content, &cfg); err != nil {
+				return dockerEndpoint{}, fmt.Errorf("failed to parse docker config: %w", err)
+			}
+		}
+		name = cfg.CurrentContext
+	}
+	if name == "" || name == "default" {
+		return dockerEndpoint{host: DefaultDockerHost}, nil
+	}
+
+	// Contexts are stored by the SHA-256 of their name
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+	content, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", id, "meta.json"))
+	if err != nil {
+		return dockerEndpoint{}, fmt.Errorf("failed to read docker context %q: %w", name, err)
+	}
+	var meta struct {
+		Endpoints map[string]struct {
+			Host string `json:"Host"`
+		} `json:"Endpoints"`
+	}
+	if err := json.Unmarshal(content, &meta); err != nil {
+		return dockerEndpoint{}, fmt.Errorf("failed to parse docker context %q: %w", name, err)
+	}
+
+	endpoint := dockerEndpoint{host: meta.Endpoints["docker"].Host}
+	if endpoint.host == "" {
+		return dockerEndpoint{}, fmt.Errorf("docker context %q has no docker endpoint", name)
+	}
+	tlsDir := filepath.Join(configDir, "contexts", "tls", id, "docker")
+	if info, err := os.Stat(tlsDir); err == nil && info.IsDir() {
+		endpoint.tlsDir = tlsDir
+	}
+	return endpoint, nil
+}
+
+// existingFile returns path if it exists, "" otherwise.
+func existingFile(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// Host returns the daemon address.
+func (c *APIClient) Host() string {
+	return c.client.DaemonHost()
+}
+
+// Close releases the client's connections.
+func (c *APIClient) Close() error {
+	return c.client.Close()
+}
+
+// Ping checks that the daemon answers.
+func (c *APIClient) Ping(ctx context.Context) error {
+	_, err := c.client.Ping(ctx)
+	return err
+}
+
+// Version returns the daemon version and the os/arch it runs on.
+func (c *APIClient) Version(ctx context.Context) (version, platform string, err error) {
+	v, err := c.client.ServerVersion(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	return v.Version, v.Os + "/" + v.Arch, nil
+}
+
+// Build sends the build context tarball to BuildKit and returns the
+// built image ID. Every progress line is passed to onLine.
+func (c *APIClient) Build(ctx context.Context, params BuildParams, buildContext io.Reader, onLine func(string)) (string, error) {
+	buildArgs := make(map[string]*string, len(params.BuildArgs))
+	for key, value := range params.BuildArgs {
+		buildArgs[key] = &value
+	}
+
+	resp, err := c.client.ImageBuild(ctx, buildContext, build.ImageBuildOptions{
+		Version:    build.BuilderBuildKit,
+		Tags:       []string{params.Tag},
+		Dockerfile: params.Dockerfile,
+		BuildArgs:  buildArgs,
+		Target:     params.Target,
+		NoCache:    params.NoCache,
+		PullParent: params.Pull,
+		Remove:     true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	return readBuildStream(resp.Body, onLine)
+}
+
+// readBuildStream follows the JSON messages of a build until the end of
+// the stream, returning the image ID BuildKit reports.
+func readBuildStream(r io.Reader, onLine func(string)) (string, error) {
+	if onLine == nil {
+		onLine = func(string) {}
+	}
+	trace := newBuildTrace(onLine)
+
+	var imageID string
+	decoder := json.NewDecoder(r)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("failed to read build output: %w", err)
+		}
+
+		if msg.Error != nil {
+			return "", &BuildStepError{Step: trace.lastStep, Message: strings.TrimSpace(msg.Error.Message)}
+		}
+		switch {
+		case msg.ID == "moby.buildkit.trace" && msg.Aux != nil:
+			if err := trace.add(*msg.Aux); err != nil {
+				return "", err
+			}
+		case msg.ID == "moby.image.id" && msg.Aux != nil:
+			var result build.Result
+			if err := json.Unmarshal(*msg.Aux, &result); err != nil {
+				return "", fmt.Errorf("failed to read build result: %w", err)
+			}
+			imageID = result.ID
+		case msg.Stream != "":
+			for _, line := range strings.Split(strings.TrimRight(msg.Stream, "\n"), "\n") {
+				onLine(line)
+			}
+		}
+	}
+	if imageID == "" {
+		return "", errors.New("build finished without reporting an image ID")
+	}
+	return imageID, nil
+}
+
+// InspectImage returns details about a local image.
+func (c *APIClient) InspectImage(ctx context.Context, ref string) (*ImageDetails, error) {
+	resp, err := c.client.ImageInspect(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &ImageDetails{
+		ID:           resp.ID,
+		RepoTags:     resp.RepoTags,
+		RepoDigests:  resp.RepoDigests,
+		Size:         resp.Size,
+		Architecture: resp.Architecture,
+		Os:           resp.Os,
+	}
+	// Unparseable dates only affect the age display
+	details.Created, _ = time.Parse(time.RFC3339Nano, resp.Created)
+	if resp.Config != nil {
+		details.Config.Labels = resp.Config.Labels
+	}
+	return details, nil
+}
+
+// ListImages returns the local images, of repository only when it is
+// non-empty.
+func (c *APIClient) ListImages(ctx context.Context, repository string) ([]image.Summary, error) {
+	opts := image.ListOptions{}
+	if repository != "" {
+		opts.Filters = filters.NewArgs(filters.Arg("reference", repository))
+	}
+	return c.client.ImageList(ctx, opts)
+}
+
+// RemoveImage deletes a local image by reference. An image still used
+// by a container is refused with a conflict error.
+func (c *APIClient) RemoveImage(ctx context.Context, ref string) error {
+	_, err := c.client.ImageRemove(ctx, ref, image.RemoveOptions{})
+	return err
+}
+
+// SaveImage exports a local image as a 'docker save' tarball.
+func (c *APIClient) SaveImage(ctx context.Context, ref string) (io.ReadCloser, error) {
+	return c.client.ImageSave(ctx, []string{ref})
+}
+
+// ContainersWithLabel returns the names of the running containers
+// carrying label (key=value).
+func (c *APIClient) ContainersWithLabel(ctx context.Context, label string) ([]string, error) {
+	containers, err := c.client.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", label)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ctr := range containers {
+		if len(ctr.Names) > 0 {
+			names = append(names, strings.TrimPrefix(ctr.Names[0], "/"))
+		}
+	}
+	return names, nil
+}
+
+// Exec runs cmd in a running container, feeding it stdin when non-nil,
+// and returns its combined output. A non-zero exit is an error.
+func (c *APIClient) Exec(ctx context.Context, containerName string, cmd []string, stdin io.Reader) (string, error) {
+	created, err := c.client.ContainerExecCreate(ctx, containerName, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	attach, err := c.client.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer attach.Close()
+
+	copied := make(chan error, 1)
+	go func() {
+		if stdin == nil {
+			copied <- nil
+			return
+		}
+		_, err := io.Copy(attach.Conn, stdin)
+		attach.CloseWrite()
+		copied <- err
+	}()
+
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, attach.Reader); err != nil {
+		return output.String(), fmt.Errorf("failed to read output: %w", err)
+	}
+	if err := <-copied; err != nil {
+		return output.String(), fmt.Errorf("failed to send input: %w", err)
+	}
+
+	inspect, err := c.client.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return output.String(), err
+	}
+	if inspect.ExitCode != 0 {
+		return output.String(), fmt.Errorf("%s exited with code %d", cmd[0], inspect.ExitCode)
+	}
+	return output.String(), nil
+}
+
+// formatSize renders bytes like the docker CLI (e.g. "12.3MB").
+func formatSize(bytes int64) string {
+	const unit = 1000
+	if bytes < unit {
+		return strconv.FormatInt(bytes, 10) + "B"
+	}
+	value, exp := float64(bytes), 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.3g%s", value, []string{"", "kB", "MB", "GB", "TB"}[exp])
+}
@@ -0,0 +1,356 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+// fakeAPIVersion is the API version the fake daemon negotiates.
+const fakeAPIVersion = "1.47"
+
+// fakeDaemon serves the Engine API endpoints the api backend uses.
+func fakeDaemon(t *testing.T, buildStream string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var contextFiles []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", fakeAPIVersion)
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("POST /v"+fakeAPIVersion+"/build", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("t"); got != "myapp:kudev-abc12345" {
+			t.Errorf("tag = %q", got)
+		}
+		if got := r.URL.Query().Get("version"); got != "2" {
+			t.Errorf("builder version = %q, want BuildKit", got)
+		}
+		tr := tar.NewReader(r.Body)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("reading build context: %v", err)
+				break
+			}
+			contextFiles = append(contextFiles, header.Name)
+		}
+		w.Write([]byte(buildStream))
+	})
+	mux.HandleFunc("GET /v"+fakeAPIVersion+"/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("filters"); !strings.Contains(got, "io.x-k8s.kind.cluster=dev") {
+			t.Errorf("filters = %s", got)
+		}
+		w.Write([]byte(`[{"Id":"abc","Names":["/dev-control-plane"]}]`))
+	})
+	mux.HandleFunc("DELETE /v"+fakeAPIVersion+"/images/{ref}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"image is being used by running container 1234"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &contextFiles
+}
+
+func newTestAPIClient(t *testing.T, server *httptest.Server) *APIClient {
+	t.Helper()
+	c, err := newAPIClient(
+		client.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")),
+		client.WithHTTPClient(server.Client()),
+	)
+	if err != nil {
+		t.Fatalf("newAPIClient failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func newTestAPIBuilder(t *testing.T, server *httptest.Server) *Builder {
+	return NewBuilderWithClient(newTestAPIClient(t, server), &util.MockLogger{})
+}
+
+// traceMessage encodes a BuildKit status update as the daemon streams it.
+func traceMessage(t *testing.T, status *controlapi.StatusResponse) string {
+	t.Helper()
+	data, err := proto.Marshal(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aux, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `{"id":"moby.buildkit.trace","aux":` + string(aux) + "}\n"
+}
+
+func buildOpts(t *testing.T) builder.BuildOptions {
+	t.Helper()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644)
+	return builder.BuildOptions{
+		SourceDir:      dir,
+		DockerfilePath: "./Dockerfile",
+		ImageName:      "myapp",
+		ImageTag:       "kudev-abc12345",
+	}
+}
+
+func TestAPIBuilder_Build(t *testing.T) {
+	start := time.Now()
+	stream := traceMessage(t, &controlapi.StatusResponse{
+		Vertexes: []*controlapi.Vertex{{
+			Digest:    "sha256:v1",
+			Name:      "[1/1] FROM scratch",
+			Started:   timestamppb.New(start),
+			Completed: timestamppb.New(start.Add(time.Second)),
+		}},
+	}) + `{"id":"moby.image.id","aux":{"ID":"sha256:0123456789abcdef"}}
+`
+	server, contextFiles := fakeDaemon(t, stream)
+	b := newTestAPIBuilder(t, server)
+
+	ref, err := b.Build(context.Background(), buildOpts(t))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if ref.FullRef != "myapp:kudev-abc12345" || ref.ID != "sha256:0123456789abcdef" {
+		t.Errorf("image ref = %+v", ref)
+	}
+	if got := strings.Join(*contextFiles, ","); got != "Dockerfile,main.go" {
+		t.Errorf("build context = %s, want Dockerfile,main.go", got)
+	}
+	if b.Name() != "docker-api" {
+		t.Errorf("Name() = %q", b.Name())
+	}
+}
+
+func TestAPIBuilder_BuildStepError(t *testing.T) {
+	start := time.Now()
+	stream := traceMessage(t, &controlapi.StatusResponse{
+		Vertexes: []*controlapi.Vertex{
+			{Digest: "sha256:v1", Name: "[1/2] FROM golang", Cached: true},
+			{Digest: "sha256:v2", Name: "[2/2] RUN go build", Started: timestamppb.New(start)},
+		},
+		Logs: []*controlapi.VertexLog{{Vertex: "sha256:v2", Msg: []byte("main.go:3: syntax error\n")}},
+	}) + traceMessage(t, &controlapi.StatusResponse{
+		Vertexes: []*controlapi.Vertex{{
+			Digest:    "sha256:v2",
+			Name:      "[2/2] RUN go build",
+			Started:   timestamppb.New(start),
+			Completed: timestamppb.New(start.Add(time.Second)),
+			Error:     "process did not complete successfully: exit code: 1",
+		}},
+	}) + `{"errorDetail":{"message":"process \"/bin/sh -c go build\" did not complete successfully: exit code: 1"},"error":"process \"/bin/sh -c go build\" did not complete successfully: exit code: 1"}
+`
+	server, _ := fakeDaemon(t, stream)
+	b := newTestAPIBuilder(t, server)
+
+	_, err := b.Build(context.Background(), buildOpts(t))
+	var stepErr *BuildStepError
+	if !errors.As(err, &stepErr) {
+		t.Fatalf("Build error = %v, want a BuildStepError", err)
+	}
+	if stepErr.Step != "[2/2] RUN go build" {
+		t.Errorf("step = %q", stepErr.Step)
+	}
+	if !strings.Contains(stepErr.Message, "exit code: 1") {
+		t.Errorf("message = %q", stepErr.Message)
+	}
+}
+
+func TestBuildTrace(t *testing.T) {
+	start := time.Now()
+	updates := []*controlapi.StatusResponse{
+		{Vertexes: []*controlapi.Vertex{
+			{Digest: "a", Name: "[internal] load build definition from Dockerfile", Started: timestamppb.New(start)},
+			{Digest: "b", Name: "[1/2] FROM golang", Cached: true},
+		}},
+		{
+			Vertexes: []*controlapi.Vertex{
+				{Digest: "a", Name: "[internal] load build definition from Dockerfile", Started: timestamppb.New(start), Completed: timestamppb.New(start.Add(200 * time.Millisecond))},
+				{Digest: "c", Name: "[2/2] RUN go build", Started: timestamppb.New(start)},
+			},
+			Logs:     []*controlapi.VertexLog{{Vertex: "c", Msg: []byte("compiling\r\ndone\n")}},
+			Warnings: []*controlapi.VertexWarning{{Vertex: "c", Short: []byte("JSONArgsRecommended")}},
+		},
+		{Vertexes: []*controlapi.Vertex{
+			{Digest: "c", Name: "[2/2] RUN go build", Started: timestamppb.New(start), Completed: timestamppb.New(start.Add(2 * time.Second)), Error: "exit code: 2"},
+		}},
+	}
+
+	var lines []string
+	trace := newBuildTrace(func(line string) { lines = append(lines, line) })
+	for _, update := range updates {
+		data, err := proto.Marshal(update)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aux, _ := json.Marshal(data)
+		if err := trace.add(aux); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+
+	want := []string{
+		"#1 [internal] load build definition from Dockerfile",
+		"#2 [1/2] FROM golang",
+		"#2 CACHED",
+		"#1 DONE 0.2s",
+		"#3 [2/2] RUN go build",
+		"#3 compiling",
+		"#3 done",
+		"WARNING: JSONArgsRecommended",
+		"#3 ERROR: exit code: 2",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("lines =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if trace.lastStep != "[2/2] RUN go build" {
+		t.Errorf("lastStep = %q", trace.lastStep)
+	}
+}
+
+func TestAPIBuilder_BuildRejectsBuildx(t *testing.T) {
+	server, _ := fakeDaemon(t, "")
+	opts := buildOpts(t)
+	opts.Buildx = true
+
+	if _, err := newTestAPIBuilder(t, server).Build(context.Background(), opts); err == nil {
+		t.Error("buildx builds should need the cli backend")
+	}
+}
+
+func TestAPIBuilder_RemoveImagesError(t *testing.T) {
+	server, _ := fakeDaemon(t, "")
+
+	err := newTestAPIBuilder(t, server).RemoveImages(context.Background(), []string{"myapp:kudev-abc12345"})
+	if !cerrdefs.IsConflict(err) {
+		t.Fatalf("RemoveImages error = %v, want a conflict", err)
+	}
+	if !strings.Contains(err.Error(), "running container") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestAPIClient_ContainersWithLabel(t *testing.T) {
+	server, _ := fakeDaemon(t, "")
+
+	names, err := newTestAPIClient(t, server).ContainersWithLabel(context.Background(), "io.x-k8s.kind.cluster=dev")
+	if err != nil {
+		t.Fatalf("ContainersWithLabel failed: %v", err)
+	}
+	if strings.Join(names, ",") != "dev-control-plane" {
+		t.Errorf("names = %v", names)
+	}
+}
+
+func TestImagesFromSummaries(t *testing.T) {
+	summaries := []image.Summary{
+		{ID: "sha256:aaaaaaaaaaaaaaaa", RepoTags: []string{"myapp:kudev-aaaaaaaa", "myapp:latest"}, Created: 100, Size: 12_300_000},
+		{ID: "sha256:bbbbbbbbbbbbbbbb", RepoTags: []string{"localhost:5000/myapp:kudev-bbbbbbbb"}, Created: 200, Size: 512},
+		{ID: "sha256:cccccccccccccccc", RepoTags: []string{"<none>:<none>"}, Created: 300},
+	}
+
+	images := imagesFromSummaries(summaries)
+	if len(images) != 2 {
+		t.Fatalf("got %d images, want 2: %+v", len(images), images)
+	}
+	if images[0].Repository != "localhost:5000/myapp" || images[0].Tag != "kudev-bbbbbbbb" || images[0].Size != "512B" {
+		t.Errorf("newest image = %+v", images[0])
+	}
+	if images[1].ID != "aaaaaaaaaaaa" || images[1].Size != "12.3MB" || images[1].Info.Hash != "aaaaaaaa" {
+		t.Errorf("oldest image = %+v", images[1])
+	}
+}
+
+func TestNewAPIClient(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr bool
+	}{
+		{host: "unix:///var/run/docker.sock"},
+		{host: "tcp://127.0.0.1:2375"},
+		{host: "ssh://user@host", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			_, err := NewAPIClient(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAPIClient(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContextEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	writeContext := func(name, host string, tls bool) {
+		sum := sha256.Sum256([]byte(name))
+		id := hex.EncodeToString(sum[:])
+		meta := filepath.Join(dir, "contexts", "meta", id)
+		if err := os.MkdirAll(meta, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		content := `{"Name":"` + name + `","Endpoints":{"docker":{"Host":"` + host + `"}}}`
+		if err := os.WriteFile(filepath.Join(meta, "meta.json"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if tls {
+			if err := os.MkdirAll(filepath.Join(dir, "contexts", "tls", id, "docker"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	const host = "unix:///home/dev/.colima/default/docker.sock"
+	writeContext("colima", host, false)
+	writeContext("remote", "tcp://build.example.com:2376", true)
+
+	if got, err := contextEndpoint(dir, "colima"); err != nil || got.host != host || got.tlsDir != "" {
+		t.Errorf("contextEndpoint(colima) = %+v, %v, want %q without TLS", got, err, host)
+	}
+	if got, err := contextEndpoint(dir, ""); err != nil || got.host != DefaultDockerHost {
+		t.Errorf("contextEndpoint without config = %+v, %v, want %q", got, err, DefaultDockerHost)
+	}
+	if got, err := contextEndpoint(dir, "remote"); err != nil || filepath.Base(got.tlsDir) != "docker" {
+		t.Errorf("contextEndpoint(remote) = %+v, %v, want its TLS directory", got, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"currentContext":"colima"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := contextEndpoint(dir, ""); err != nil || got.host != host {
+		t.Errorf("contextEndpoint(currentContext) = %+v, %v, want %q", got, err, host)
+	}
+	if got, err := contextEndpoint(dir, "default"); err != nil || got.host != DefaultDockerHost {
+		t.Errorf("contextEndpoint(default) = %+v, %v, want %q", got, err, DefaultDockerHost)
+	}
+	if _, err := contextEndpoint(dir, "missing"); err == nil {
+		t.Error("contextEndpoint(missing) expected an error")
+	}
+}
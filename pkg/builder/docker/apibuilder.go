@@ -0,0 +1,162 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/dockerignore"
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// NewAPIBuilder returns a Builder that talks to the Docker Engine API
+// through the Docker SDK instead of running the docker CLI
+// (spec.build.backend: api).
+func NewAPIBuilder(logger logging.LoggerInterface) (*Builder, error) {
+	client, err := NewAPIClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewBuilderWithClient(client, logger), nil
+}
+
+// NewBuilderWithClient returns a Builder using client for every daemon
+// call.
+func NewBuilderWithClient(client *APIClient, logger logging.LoggerInterface) *Builder {
+	return &Builder{logger: logging.OrDefault(logger), api: client}
+}
+
+// buildWithAPI builds with BuildKit through the Engine API, streaming
+// the build context from opts.SourceDir.
+func (b *Builder) buildWithAPI(ctx context.Context, opts builder.BuildOptions) (*builder.ImageRef, error) {
+	if opts.Buildx || opts.BakeFile != "" || opts.Platform != "" || len(opts.CacheFrom) > 0 || len(opts.CacheTo) > 0 {
+		return nil, errors.New("buildx, bake, platform and cache settings need the cli build backend")
+	}
+
+	if err := b.checkDockerDaemon(ctx); err != nil {
+		return nil, err
+	}
+
+	changed, err := dockerignore.Sync(opts.SourceDir, opts.Exclusions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare build context: %w", err)
+	}
+	if changed {
+		b.logger.Info("updated .dockerignore from spec.exclude", "dir", opts.SourceDir)
+	}
+
+	b.logger.Info("starting docker build",
+		"image", opts.ImageName,
+		"tag", opts.ImageTag,
+		"dockerfile", opts.DockerfilePath,
+		"host", b.api.Host(),
+	)
+
+	dockerfile, err := contextDockerfile(opts.SourceDir, opts.DockerfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare build context: %w", err)
+	}
+	buildContext, err := buildContext(opts.SourceDir, opts.DockerfilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare build context: %w", err)
+	}
+	defer buildContext.Close()
+
+	fullRef := fmt.Sprintf("%s:%s", opts.ImageName, opts.ImageTag)
+	params := BuildParams{
+		Tag:        fullRef,
+		Dockerfile: dockerfile,
+		BuildArgs:  opts.BuildArgs,
+		Target:     opts.Target,
+		NoCache:    opts.NoCache,
+		Pull:       opts.Pull,
+	}
+
+	log := newBuildLog(b.logger, opts.Output)
+	imageID, err := b.api.Build(ctx, params, buildContext, func(line string) {
+		log.add("stream", line)
+	})
+	if err != nil {
+		log.failed()
+		return nil, fmt.Errorf("docker build failed: %w", err)
+	}
+	log.succeeded()
+
+	b.logger.Info("docker build completed successfully", "id", imageID)
+
+	return &builder.ImageRef{
+		FullRef: fullRef,
+		ID:      imageID,
+	}, nil
+}
+
+// listImagesWithAPI is ListImages for the api backend.
+func (b *Builder) listImagesWithAPI(ctx context.Context, repository string) ([]Image, error) {
+	summaries, err := b.api.ListImages(ctx, repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	return imagesFromSummaries(summaries), nil
+}
+
+// imagesFromSummaries keeps the kudev-tagged references of the API's
+// image list, one Image per tag, newest first.
+func imagesFromSummaries(summaries []image.Summary) []Image {
+	var images []Image
+	for _, s := range summaries {
+		for _, ref := range s.RepoTags {
+			i := strings.LastIndex(ref, ":")
+			if i == -1 || strings.Contains(ref[i:], "/") {
+				continue
+			}
+			repository, tag := ref[:i], ref[i+1:]
+
+			info, err := builder.ParseTagInfo(tag)
+			if err != nil {
+				continue // Not built by kudev
+			}
+
+			images = append(images, Image{
+				Repository: repository,
+				Tag:        tag,
+				ID:         shortImageID(s.ID),
+				Size:       formatSize(s.Size),
+				CreatedAt:  time.Unix(s.Created, 0),
+				Info:       info,
+			})
+		}
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].CreatedAt.After(images[j].CreatedAt)
+	})
+	return images
+}
+
+// shortImageID shortens "sha256:<hex>" to the 12 characters 'docker
+// images' shows.
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// removeImagesWithAPI is RemoveImages for the api backend.
+func (b *Builder) removeImagesWithAPI(ctx context.Context, refs []string) error {
+	var errs []error
+	for _, ref := range refs {
+		if err := b.api.RemoveImage(ctx, ref); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+			continue
+		}
+		b.logger.Debug("removed image", "image", ref)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/go-archive"
+	"github.com/moby/patternmatcher/ignorefile"
+)
+
+// outsideDockerfileName is the name a Dockerfile from outside the build
+// context is sent under, as the docker CLI does.
+const outsideDockerfileName = ".kudev.Dockerfile"
+
+// loadDockerignore reads the patterns of dir/.dockerignore; a missing
+// file ignores nothing.
+func loadDockerignore(dir string) ([]string, error) {
+	file, err := os.Open(filepath.Join(dir, ".dockerignore"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ignorefile.ReadAll(file)
+}
+
+// contextDockerfile returns the name the Dockerfile has in the build
+// context of dir: its relative path, or outsideDockerfileName when it
+// lies outside dir.
+func contextDockerfile(dir, dockerfile string) (string, error) {
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(dir, dockerfile)
+	}
+	rel, err := filepath.Rel(dir, dockerfile)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return outsideDockerfileName, nil
+	}
+	return rel, nil
+}
+
+// buildContext returns dir as a tar stream, leaving out what
+// .dockerignore excludes. The Dockerfile and .dockerignore are always
+// sent, like the docker CLI does; a Dockerfile outside dir is added
+// under the name contextDockerfile returns.
+func buildContext(dir, dockerfile string) (io.ReadCloser, error) {
+	excludes, err := loadDockerignore(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+	dockerfileName, err := contextDockerfile(dir, dockerfile)
+	if err != nil {
+		return nil, err
+	}
+	excludes = append(excludes, "!.dockerignore")
+	if dockerfileName != outsideDockerfileName {
+		excludes = append(excludes, "!"+dockerfileName)
+	}
+
+	// Owners would only defeat the daemon's layer cache
+	tarball, err := archive.TarWithOptions(dir, &archive.TarOptions{
+		ExcludePatterns: excludes,
+		ChownOpts:       &archive.ChownOpts{UID: 0, GID: 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dockerfileName != outsideDockerfileName {
+		return tarball, nil
+	}
+
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(dir, dockerfile)
+	}
+	info, err := os.Stat(dockerfile)
+	if err != nil {
+		tarball.Close()
+		return nil, fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+	content, err := os.ReadFile(dockerfile)
+	if err != nil {
+		tarball.Close()
+		return nil, fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+	return archive.ReplaceFileTarWrapper(tarball, map[string]archive.TarModifierFunc{
+		outsideDockerfileName: func(_ string, _ *tar.Header, _ io.Reader) (*tar.Header, []byte, error) {
+			header := &tar.Header{
+				Name:     outsideDockerfileName,
+				Mode:     0o600,
+				Size:     int64(len(content)),
+				Typeflag: tar.TypeReg,
+				ModTime:  info.ModTime(),
+			}
+			return header, content, nil
+		},
+	}), nil
+}
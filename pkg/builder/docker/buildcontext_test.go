@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBuildContext(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "app")
+	files := map[string]string{
+		"app/.dockerignore":       "secrets\nDockerfile\n**/*.log\n*.md\n!README.md\n",
+		"app/README.md":           "# app\n",
+		"app/CHANGES.md":          "v1\n",
+		"app/internal/trace.log":  "trace\n",
+		"app/Dockerfile":          "FROM scratch\n",
+		"app/main.go":             "package main\n",
+		"app/secrets/key.pem":     "secret",
+		"app/internal/util/x.go":  "package util\n",
+		"docker/Dockerfile.debug": "FROM busybox\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		dockerfile     string
+		wantDockerfile string
+		wantFiles      []string
+	}{
+		{
+			name:           "dockerfile in context",
+			dockerfile:     "./Dockerfile",
+			wantDockerfile: "Dockerfile",
+			wantFiles:      []string{".dockerignore", "Dockerfile", "README.md", "internal/", "internal/util/", "internal/util/x.go", "main.go"},
+		},
+		{
+			name:           "dockerfile outside context",
+			dockerfile:     filepath.Join(root, "docker/Dockerfile.debug"),
+			wantDockerfile: outsideDockerfileName,
+			wantFiles:      []string{".dockerignore", outsideDockerfileName, "README.md", "internal/", "internal/util/", "internal/util/x.go", "main.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := contextDockerfile(dir, tt.dockerfile)
+			if err != nil {
+				t.Fatalf("contextDockerfile failed: %v", err)
+			}
+			if name != tt.wantDockerfile {
+				t.Errorf("Dockerfile name = %q, want %q", name, tt.wantDockerfile)
+			}
+
+			tarball, err := buildContext(dir, tt.dockerfile)
+			if err != nil {
+				t.Fatalf("buildContext failed: %v", err)
+			}
+			defer tarball.Close()
+
+			var got []string
+			tr := tar.NewReader(tarball)
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("reading tar: %v", err)
+				}
+				got = append(got, header.Name)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("context files = %v, want %v", got, tt.wantFiles)
+			}
+		})
+	}
+}
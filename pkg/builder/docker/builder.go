@@ -7,20 +7,35 @@ import (
 	"io"
 	"os/exec"
 	"strings"
+	"sync"
 
 	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/dockerignore"
 	"github.com/nanaki-93/kudev/pkg/logging"
 )
 
 type Builder struct {
 	logger logging.LoggerInterface
+
+	// api, when set, replaces the docker CLI (see NewAPIBuilder)
+	api *APIClient
 }
 
 func NewBuilder(logger logging.LoggerInterface) *Builder {
-	return &Builder{logger: logger}
+	return &Builder{logger: logging.OrDefault(logger)}
+}
+
+// Engine returns the SDK client of the api backend, or nil when the
+// docker CLI is used.
+func (b *Builder) Engine() *APIClient {
+	return b.api
 }
 
 func (b *Builder) Name() string {
+	if b.api != nil {
+		return "docker-api"
+	}
 	return "docker"
 }
 
@@ -29,26 +44,38 @@ func (b *Builder) Build(ctx context.Context, opts builder.BuildOptions) (*builde
 	if err := opts.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid build options: %w", err)
 	}
+	if b.api != nil {
+		return b.buildWithAPI(ctx, opts)
+	}
 
 	// 1. Verify Docker daemon is running
 	if err := b.checkDockerDaemon(ctx); err != nil {
 		return nil, err
 	}
 
+	// 2. Keep spec.exclude out of the build context
+	changed, err := dockerignore.Sync(opts.SourceDir, opts.Exclusions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare build context: %w", err)
+	}
+	if changed {
+		b.logger.Info("updated .dockerignore from spec.exclude", "dir", opts.SourceDir)
+	}
+
 	b.logger.Info("starting docker build",
 		"image", opts.ImageName,
 		"tag", opts.ImageTag,
 		"dockerfile", opts.DockerfilePath,
 	)
 
-	// 2. Build docker command arguments
+	// 3. Build docker command arguments
 	args := b.buildCommandArgs(opts)
 
-	// 3. Create command with context for cancellation
+	// 4. Create command with context for cancellation
 	cmd := exec.CommandContext(ctx, "docker", args...)
 	cmd.Dir = opts.SourceDir // Set working directory to source
 
-	// 4. Get stdout and stderr pipes for streaming
+	// 5. Get stdout and stderr pipes for streaming
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stdout pipe: %w", err)
@@ -59,23 +86,30 @@ func (b *Builder) Build(ctx context.Context, opts builder.BuildOptions) (*builde
 		return nil, fmt.Errorf("failed to get stderr pipe: %w", err)
 	}
 
-	// 5. Start the command
+	// 6. Start the command
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start docker build: %w", err)
 	}
 
-	// 6. Stream output in goroutines
-	go b.streamOutput("stdout", stdout)
-	go b.streamOutput("stderr", stderr)
+	// 7. Stream output in goroutines; both pipes must be drained
+	// before Wait closes them
+	log := newBuildLog(b.logger, opts.Output)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go b.streamOutput("stdout", stdout, log, &wg)
+	go b.streamOutput("stderr", stderr, log, &wg)
+	wg.Wait()
 
-	// 7. Wait for completion
+	// 8. Wait for completion
 	if err := cmd.Wait(); err != nil {
+		log.failed()
 		return nil, fmt.Errorf("docker build failed: %w", err)
 	}
+	log.succeeded()
 
 	b.logger.Info("docker build completed successfully")
 
-	// 8. Get image ID
+	// 9. Get image ID
 	fullRef := fmt.Sprintf("%s:%s", opts.ImageName, opts.ImageTag)
 	imageID, err := b.getImageID(ctx, fullRef)
 	if err != nil {
@@ -90,6 +124,20 @@ func (b *Builder) Build(ctx context.Context, opts builder.BuildOptions) (*builde
 
 // checkDockerDaemon verifies the Docker daemon is running and accessible.
 func (b *Builder) checkDockerDaemon(ctx context.Context) error {
+	if b.api != nil {
+		if err := b.api.Ping(ctx); err != nil {
+			return fmt.Errorf(
+				"docker daemon is not running or not accessible\n\n"+
+					"Troubleshooting:\n"+
+					"  1. Ensure Docker Desktop is running\n"+
+					"  2. Or start Docker daemon: sudo systemctl start docker\n"+
+					"  3. Check DOCKER_HOST (using %s)\n\n"+
+					"Error: %w", b.api.Host(), err,
+			)
+		}
+		return nil
+	}
+
 	cmd := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -107,9 +155,51 @@ func (b *Builder) checkDockerDaemon(ctx context.Context) error {
 	return nil
 }
 
+// Platform returns the os/arch the docker daemon builds for by default
+// (e.g. linux/arm64 on Apple Silicon), without spec.build.platform.
+func (b *Builder) Platform(ctx context.Context) (string, error) {
+	if b.api != nil {
+		_, platform, err := b.api.Version(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get docker server platform: %w", err)
+		}
+		return platform, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Os}}/{{.Server.Arch}}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get docker server platform: %w", err)
+	}
+
+	platform := strings.TrimSpace(string(output))
+	if os, arch, ok := strings.Cut(platform, "/"); !ok || os == "" || arch == "" {
+		return "", fmt.Errorf("unexpected docker server platform %q", platform)
+	}
+	return platform, nil
+}
+
 // buildCommandArgs constructs the docker build command arguments.
 func (b *Builder) buildCommandArgs(opts builder.BuildOptions) []string {
+	if opts.BakeFile != "" {
+		return b.bakeCommandArgs(opts)
+	}
+
 	args := []string{"build"}
+	if opts.Buildx {
+		// --load puts the result in the local image store, where the
+		// registry loaders and 'docker inspect' expect it
+		args = []string{"buildx", "build", "--load"}
+		if opts.Platform != "" {
+			args = append(args, "--platform", opts.Platform)
+		}
+		for _, c := range opts.CacheFrom {
+			args = append(args, "--cache-from", c)
+		}
+		for _, c := range opts.CacheTo {
+			args = append(args, "--cache-to", c)
+		}
+	}
 
 	// Add tag
 	args = append(args, "-t", fmt.Sprintf("%s:%s", opts.ImageName, opts.ImageTag))
@@ -132,14 +222,66 @@ func (b *Builder) buildCommandArgs(opts builder.BuildOptions) []string {
 		args = append(args, "--no-cache")
 	}
 
+	// Always pull base images if specified
+	if opts.Pull {
+		args = append(args, "--pull")
+	}
+
+	// Verbose output expands every step's output instead of the
+	// collapsed BuildKit view
+	if opts.Output == config.BuildOutputVerbose {
+		args = append(args, "--progress=plain")
+	}
+
 	// Add build context (current directory since we set cmd.Dir)
 	args = append(args, ".")
 
 	return args
 }
 
-// streamOutput reads from a reader and logs each line.
-func (b *Builder) streamOutput(source string, r io.Reader) {
+// bakeCommandArgs constructs the 'docker buildx bake' arguments. The
+// bake file defines the build; kudev's tag and settings are applied to
+// the target with --set, and --load puts the image in the local store.
+func (b *Builder) bakeCommandArgs(opts builder.BuildOptions) []string {
+	target := opts.BakeTarget
+	set := func(args []string, key, value string) []string {
+		return append(args, "--set", fmt.Sprintf("%s.%s=%s", target, key, value))
+	}
+
+	args := []string{"buildx", "bake", "-f", opts.BakeFile, "--load"}
+	args = set(args, "tags", fmt.Sprintf("%s:%s", opts.ImageName, opts.ImageTag))
+	if opts.Platform != "" {
+		args = set(args, "platform", opts.Platform)
+	}
+	for _, c := range opts.CacheFrom {
+		args = set(args, "cache-from", c)
+	}
+	for _, c := range opts.CacheTo {
+		args = set(args, "cache-to", c)
+	}
+	for key, val := range opts.BuildArgs {
+		args = set(args, "args."+key, val)
+	}
+	if opts.Target != "" {
+		args = set(args, "target", opts.Target)
+	}
+	if opts.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if opts.Pull {
+		args = append(args, "--pull")
+	}
+	if opts.Output == config.BuildOutputVerbose {
+		args = append(args, "--progress=plain")
+	}
+
+	return append(args, target)
+}
+
+// streamOutput reads from a reader and passes each line to log.
+func (b *Builder) streamOutput(source string, r io.Reader, log *buildLog, wg *sync.WaitGroup) {
+	defer wg.Done()
+
 	scanner := bufio.NewScanner(r)
 	// Increase buffer size for long lines
 	buf := make([]byte, 0, 64*1024)
@@ -148,7 +290,7 @@ func (b *Builder) streamOutput(source string, r io.Reader) {
 	for scanner.Scan() {
 		line := scanner.Text()
 		if line != "" {
-			b.logger.Info(line, "source", source)
+			log.add(source, line)
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/test/util"
 )
 
@@ -67,11 +68,90 @@ func TestBuildCommandArgs(t *testing.T) {
 				".",
 			},
 		},
+		{
+			name: "with pull",
+			opts: builder.BuildOptions{
+				SourceDir:      "/project",
+				DockerfilePath: "./Dockerfile",
+				ImageName:      "myapp",
+				ImageTag:       "kudev-abc123",
+				Pull:           true,
+			},
+			expected: []string{
+				"build",
+				"-t", "myapp:kudev-abc123",
+				"--pull",
+				".",
+			},
+		},
+		{
+			name: "with buildx",
+			opts: builder.BuildOptions{
+				SourceDir:      "/project",
+				DockerfilePath: "./Dockerfile",
+				ImageName:      "myapp",
+				ImageTag:       "kudev-abc123",
+				Buildx:         true,
+				Platform:       "linux/amd64",
+				CacheFrom:      []string{"type=registry,ref=reg/myapp:cache"},
+				CacheTo:        []string{"type=registry,ref=reg/myapp:cache,mode=max"},
+			},
+			expected: []string{
+				"buildx", "build", "--load",
+				"--platform", "linux/amd64",
+				"--cache-from", "type=registry,ref=reg/myapp:cache",
+				"--cache-to", "type=registry,ref=reg/myapp:cache,mode=max",
+				"-t", "myapp:kudev-abc123",
+				".",
+			},
+		},
+		{
+			name: "with bake file",
+			opts: builder.BuildOptions{
+				SourceDir:      "/project",
+				DockerfilePath: "./Dockerfile",
+				ImageName:      "myapp",
+				ImageTag:       "kudev-abc123",
+				BakeFile:       "/project/docker-bake.hcl",
+				BakeTarget:     "api",
+				Platform:       "linux/amd64",
+				CacheFrom:      []string{"type=local,src=.cache"},
+				NoCache:        true,
+				Pull:           true,
+			},
+			expected: []string{
+				"buildx", "bake", "-f", "/project/docker-bake.hcl", "--load",
+				"api.tags=myapp:kudev-abc123",
+				"api.platform=linux/amd64",
+				"api.cache-from=type=local,src=.cache",
+				"--no-cache", "--pull",
+				"api",
+			},
+		},
+		{
+			name: "verbose output",
+			opts: builder.BuildOptions{
+				SourceDir:      "/project",
+				DockerfilePath: "./Dockerfile",
+				ImageName:      "myapp",
+				ImageTag:       "kudev-abc123",
+				Output:         config.BuildOutputVerbose,
+			},
+			expected: []string{
+				"build",
+				"-t", "myapp:kudev-abc123",
+				"--progress=plain",
+				".",
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			args := db.buildCommandArgs(tt.opts)
+			if args[0] != tt.expected[0] {
+				t.Errorf("command = %q, want %q", args[0], tt.expected[0])
+			}
 
 			// Check essential args are present
 			// Note: BuildArgs map iteration order is random
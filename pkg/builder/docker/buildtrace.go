@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	controlapi "github.com/moby/buildkit/api/services/control"
+	"google.golang.org/protobuf/proto"
+)
+
+// buildTrace renders the BuildKit status updates of an Engine API build
+// as the plain progress lines of 'docker build --progress=plain':
+// "#3 [2/4] RUN go build", then the vertex's output and its DONE,
+// CACHED or ERROR line.
+type buildTrace struct {
+	onLine func(string)
+
+	// numbers are the vertex numbers by digest, in order of appearance
+	numbers map[string]int
+	started map[string]bool
+	done    map[string]bool
+
+	// lastStep is the name of the last vertex that was started
+	lastStep string
+}
+
+func newBuildTrace(onLine func(string)) *buildTrace {
+	return &buildTrace{
+		onLine:  onLine,
+		numbers: make(map[string]int),
+		started: make(map[string]bool),
+		done:    make(map[string]bool),
+	}
+}
+
+// add renders one moby.buildkit.trace message: a JSON string holding a
+// protobuf-encoded StatusResponse.
+func (t *buildTrace) add(aux json.RawMessage) error {
+	var data []byte
+	if err := json.Unmarshal(aux, &data); err != nil {
+		return fmt.Errorf("failed to read build progress: %w", err)
+	}
+	var status controlapi.StatusResponse
+	if err := proto.Unmarshal(data, &status); err != nil {
+		return fmt.Errorf("failed to read build progress: %w", err)
+	}
+
+	for _, v := range status.Vertexes {
+		n := t.number(v.Digest)
+		if (v.Started != nil || v.Cached) && !t.started[v.Digest] {
+			t.started[v.Digest] = true
+			t.lastStep = v.Name
+			t.onLine(fmt.Sprintf("#%d %s", n, v.Name))
+		}
+		if t.done[v.Digest] {
+			continue
+		}
+		switch {
+		case v.Error != "":
+			t.done[v.Digest] = true
+			t.lastStep = v.Name
+			t.onLine(fmt.Sprintf("#%d ERROR: %s", n, v.Error))
+		case v.Cached:
+			t.done[v.Digest] = true
+			t.onLine(fmt.Sprintf("#%d CACHED", n))
+		case v.Completed != nil && v.Started != nil:
+			t.done[v.Digest] = true
+			duration := v.Completed.AsTime().Sub(v.Started.AsTime())
+			t.onLine(fmt.Sprintf("#%d DONE %.1fs", n, duration.Seconds()))
+		}
+	}
+
+	for _, log := range status.Logs {
+		n := t.number(log.Vertex)
+		for _, line := range strings.Split(strings.TrimRight(string(log.Msg), "\n"), "\n") {
+			if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+				t.onLine(fmt.Sprintf("#%d %s", n, line))
+			}
+		}
+	}
+
+	for _, warning := range status.Warnings {
+		t.onLine(fmt.Sprintf("WARNING: %s", warning.Short))
+	}
+	return nil
+}
+
+// number returns the vertex number of digest, assigning the next one on
+// first sight.
+func (t *buildTrace) number(digest string) int {
+	n, ok := t.numbers[digest]
+	if !ok {
+		n = len(t.numbers) + 1
+		t.numbers[digest] = n
+	}
+	return n
+}
@@ -0,0 +1,217 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/builder"
+)
+
+// dockerTimeFormat is the CreatedAt format of 'docker images --format {{json .}}'.
+const dockerTimeFormat = "2006-01-02 15:04:05 -0700 MST"
+
+// Image is a local image with a kudev-generated tag.
+type Image struct {
+	Repository string
+	Tag        string
+	ID         string
+	Size       string
+	CreatedAt  time.Time
+
+	// Info is parsed from Tag.
+	Info *builder.TagInfo
+}
+
+// Ref returns the full image reference (repository:tag).
+func (i Image) Ref() string {
+	return i.Repository + ":" + i.Tag
+}
+
+// ImageDetails is the subset of 'docker image inspect' kudev reports.
+type ImageDetails struct {
+	ID           string    `json:"Id"`
+	RepoTags     []string  `json:"RepoTags"`
+	RepoDigests  []string  `json:"RepoDigests"`
+	Created      time.Time `json:"Created"`
+	Size         int64     `json:"Size"`
+	Architecture string    `json:"Architecture"`
+	Os           string    `json:"Os"`
+	Config       struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// ListImages returns local images with kudev tags, newest first.
+// When repository is non-empty, only that repository is listed.
+func (b *Builder) ListImages(ctx context.Context, repository string) ([]Image, error) {
+	if err := b.checkDockerDaemon(ctx); err != nil {
+		return nil, err
+	}
+
+	if b.api != nil {
+		return b.listImagesWithAPI(ctx, repository)
+	}
+
+	args := []string{"images", "--format", "{{json .}}"}
+	if repository != "" {
+		args = append(args, repository)
+	}
+
+	output, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	return parseImageList(string(output))
+}
+
+// InspectImage returns details about a local image.
+func (b *Builder) InspectImage(ctx context.Context, imageRef string) (*ImageDetails, error) {
+	if err := b.checkDockerDaemon(ctx); err != nil {
+		return nil, err
+	}
+
+	if b.api != nil {
+		details, err := b.api.InspectImage(ctx, imageRef)
+		if err != nil {
+			return nil, fmt.Errorf("image %s not found locally: %w", imageRef, err)
+		}
+		return details, nil
+	}
+
+	output, err := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{json .}}", imageRef).Output()
+	if err != nil {
+		return nil, fmt.Errorf("image %s not found locally: %w", imageRef, err)
+	}
+
+	details := &ImageDetails{}
+	if err := json.Unmarshal(output, details); err != nil {
+		return nil, fmt.Errorf("failed to parse image details: %w", err)
+	}
+	return details, nil
+}
+
+// RemoveImages deletes local images by reference. Every reference is
+// tried; the ones that could not be removed (e.g. still used by a
+// container) are reported together.
+func (b *Builder) RemoveImages(ctx context.Context, refs []string) error {
+	if err := b.checkDockerDaemon(ctx); err != nil {
+		return err
+	}
+	if b.api != nil {
+		return b.removeImagesWithAPI(ctx, refs)
+	}
+
+	var errs []error
+	for _, ref := range refs {
+		output, err := exec.CommandContext(ctx, "docker", "rmi", ref).CombinedOutput()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", ref, strings.TrimSpace(string(output))))
+			continue
+		}
+		b.logger.Debug("removed image", "image", ref)
+	}
+	return errors.Join(errs...)
+}
+
+// PruneCandidates returns the images to delete so that only the newest
+// keep images of each repository remain. images must be newest first,
+// as ListImages returns them. Images whose hash is in protect are always
+// kept.
+func PruneCandidates(images []Image, keep int, protect map[string]bool) []Image {
+	kept := map[string]int{}
+	var prune []Image
+	for _, img := range images {
+		if protect[img.Info.Hash] || kept[img.Repository] < keep {
+			kept[img.Repository]++
+			continue
+		}
+		prune = append(prune, img)
+	}
+	return prune
+}
+
+// RemoteDigest returns the registry manifest digest (sha256:...) that
+// imageRef currently points to, without pulling it. For a multi-platform
+// image this is the digest of its index. It needs the docker CLI with
+// buildx, whatever the build backend.
+func (b *Builder) RemoteDigest(ctx context.Context, imageRef string) (string, error) {
+	output, err := exec.CommandContext(ctx, "docker", "buildx", "imagetools", "inspect",
+		"--format", "{{json .Manifest}}", imageRef).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect %s in its registry: %w", imageRef, err)
+	}
+	return parseManifestDigest(output)
+}
+
+// parseManifestDigest reads the digest of an OCI descriptor as printed
+// by 'docker buildx imagetools inspect --format {{json .Manifest}}'.
+func parseManifestDigest(output []byte) (string, error) {
+	var descriptor struct {
+		Digest string `json:"digest"`
+	}
+	if err := json.Unmarshal(output, &descriptor); err != nil {
+		return "", fmt.Errorf("failed to parse image manifest: %w", err)
+	}
+	if !strings.HasPrefix(descriptor.Digest, "sha256:") {
+		return "", fmt.Errorf("unexpected manifest digest %q", descriptor.Digest)
+	}
+	return descriptor.Digest, nil
+}
+
+// imageListEntry is one line of 'docker images --format {{json .}}'.
+type imageListEntry struct {
+	Repository string `json:"Repository"`
+	Tag        string `json:"Tag"`
+	ID         string `json:"ID"`
+	Size       string `json:"Size"`
+	CreatedAt  string `json:"CreatedAt"`
+}
+
+// parseImageList keeps the kudev-tagged entries of 'docker images' JSON lines.
+func parseImageList(output string) ([]Image, error) {
+	var images []Image
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry imageListEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse image list: %w", err)
+		}
+
+		info, err := builder.ParseTagInfo(entry.Tag)
+		if err != nil {
+			continue // Not built by kudev
+		}
+
+		// Unparseable dates only affect ordering and age display
+		created, _ := time.Parse(dockerTimeFormat, entry.CreatedAt)
+
+		images = append(images, Image{
+			Repository: entry.Repository,
+			Tag:        entry.Tag,
+			ID:         entry.ID,
+			Size:       entry.Size,
+			CreatedAt:  created,
+			Info:       info,
+		})
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].CreatedAt.After(images[j].CreatedAt)
+	})
+
+	return images, nil
+}
@@ -0,0 +1,113 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nanaki-93/kudev/pkg/builder"
+)
+
+func TestParseImageList(t *testing.T) {
+	output := `{"Containers":"N/A","CreatedAt":"2024-01-15 10:00:00 +0000 UTC","ID":"aaa111","Repository":"myapp","Size":"12.3MB","Tag":"kudev-a1b2c3d4"}
+{"Containers":"N/A","CreatedAt":"2024-01-15 12:00:00 +0000 UTC","ID":"bbb222","Repository":"myapp","Size":"12.4MB","Tag":"kudev-e5f6a7b8-20240115-120000"}
+{"Containers":"N/A","CreatedAt":"2024-01-14 09:00:00 +0000 UTC","ID":"ccc333","Repository":"myapp","Size":"12.0MB","Tag":"latest"}
+{"Containers":"N/A","CreatedAt":"2024-01-13 09:00:00 +0000 UTC","ID":"ddd444","Repository":"<none>","Size":"11MB","Tag":"<none>"}
+`
+
+	images, err := parseImageList(output)
+	if err != nil {
+		t.Fatalf("parseImageList failed: %v", err)
+	}
+
+	if len(images) != 2 {
+		t.Fatalf("got %d images, want 2 (non-kudev tags skipped): %+v", len(images), images)
+	}
+
+	// Newest first
+	if images[0].Tag != "kudev-e5f6a7b8-20240115-120000" {
+		t.Errorf("images[0].Tag = %q, want the newest image", images[0].Tag)
+	}
+	if images[0].Info.Hash != "e5f6a7b8" || !images[0].Info.HasTimestamp {
+		t.Errorf("images[0].Info = %+v", images[0].Info)
+	}
+	if images[1].Ref() != "myapp:kudev-a1b2c3d4" {
+		t.Errorf("images[1].Ref() = %q", images[1].Ref())
+	}
+	if images[1].Size != "12.3MB" || images[1].ID != "aaa111" {
+		t.Errorf("images[1] = %+v", images[1])
+	}
+	if images[1].CreatedAt.IsZero() {
+		t.Error("CreatedAt was not parsed")
+	}
+}
+
+func TestParseImageList_Invalid(t *testing.T) {
+	if _, err := parseImageList("not json\n"); err == nil {
+		t.Error("expected error for malformed output")
+	}
+}
+
+func TestParseManifestDigest(t *testing.T) {
+	output := `{"mediaType":"application/vnd.oci.image.index.v1+json","digest":"sha256:4a5b","size":856}`
+	digest, err := parseManifestDigest([]byte(output))
+	if err != nil || digest != "sha256:4a5b" {
+		t.Errorf("parseManifestDigest() = %q, %v", digest, err)
+	}
+
+	for _, bad := range []string{"not json", `{"size":856}`} {
+		if _, err := parseManifestDigest([]byte(bad)); err == nil {
+			t.Errorf("parseManifestDigest(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPruneCandidates(t *testing.T) {
+	image := func(repo, hash string) Image {
+		return Image{Repository: repo, Tag: "kudev-" + hash, Info: &builder.TagInfo{Hash: hash}}
+	}
+	// Newest first, as ListImages returns them
+	images := []Image{
+		image("myapp", "aaaaaaa5"),
+		image("other", "bbbbbbb2"),
+		image("myapp", "aaaaaaa4"),
+		image("myapp", "aaaaaaa3"),
+		image("other", "bbbbbbb1"),
+		image("myapp", "aaaaaaa2"),
+		image("myapp", "aaaaaaa1"),
+	}
+
+	tests := []struct {
+		name    string
+		keep    int
+		protect map[string]bool
+		want    []string
+	}{
+		{
+			name: "keep per repository",
+			keep: 2,
+			want: []string{"myapp:kudev-aaaaaaa3", "myapp:kudev-aaaaaaa2", "myapp:kudev-aaaaaaa1"},
+		},
+		{
+			name:    "protected images stay",
+			keep:    1,
+			protect: map[string]bool{"aaaaaaa2": true},
+			want:    []string{"myapp:kudev-aaaaaaa4", "myapp:kudev-aaaaaaa3", "other:kudev-bbbbbbb1", "myapp:kudev-aaaaaaa1"},
+		},
+		{
+			name: "keep all",
+			keep: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, img := range PruneCandidates(images, tt.keep, tt.protect) {
+				got = append(got, img.Ref())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("PruneCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
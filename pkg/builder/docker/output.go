@@ -0,0 +1,218 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+var (
+	// vertexPattern splits BuildKit plain progress into vertex and message.
+	vertexPattern = regexp.MustCompile(`^#(\d+) (.+)$`)
+
+	// vertexStepPattern matches a vertex that is a Dockerfile step,
+	// "[builder 2/5] RUN go build"; internal vertices have no x/y.
+	vertexStepPattern = regexp.MustCompile(`^\[((?:[^\]]* )?\d+/\d+)\] (.+)$`)
+
+	// vertexDonePattern matches "DONE 1.2s".
+	vertexDonePattern = regexp.MustCompile(`^DONE (\d+(?:\.\d+)?)s$`)
+
+	// classicStepPattern matches "Step 2/5 : RUN go build".
+	classicStepPattern = regexp.MustCompile(`^Step (\d+/\d+) : (.+)$`)
+)
+
+// buildStep is one Dockerfile step, followed through the build output.
+type buildStep struct {
+	// label is "2/5", or "builder 2/5" in a named BuildKit stage
+	label   string
+	command string
+	started time.Time
+	cached  bool
+	done    bool
+}
+
+// buildLog handles docker build output according to the output mode
+// (see config.WatchConfig.BuildOutput). In quiet mode the output is
+// parsed into steps and each finished step is printed on one line, with
+// its duration or "cached"; the raw output is kept for failures.
+type buildLog struct {
+	logger logging.LoggerInterface
+	quiet  bool
+
+	// out receives the progress display (nil means os.Stdout at the
+	// time of writing, which the TUI redirects)
+	out io.Writer
+	now func() time.Time
+
+	mu      sync.Mutex
+	lines   []string
+	started time.Time
+
+	// steps are keyed by BuildKit vertex ("#7") or classic step ("2/5"),
+	// order lists them as they started and current is the classic
+	// builder's running step
+	steps    map[string]*buildStep
+	order    []*buildStep
+	current  *buildStep
+	finished int
+	cached   int
+}
+
+func newBuildLog(logger logging.LoggerInterface, mode string) *buildLog {
+	return &buildLog{
+		logger:  logger,
+		quiet:   mode == config.BuildOutputQuiet,
+		now:     time.Now,
+		started: time.Now(),
+		steps:   make(map[string]*buildStep),
+	}
+}
+
+// add handles one line of output from source (stdout or stderr).
+func (l *buildLog) add(source, line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.quiet {
+		l.logger.Info(line, "source", source)
+		return
+	}
+
+	l.lines = append(l.lines, line)
+	if m := vertexPattern.FindStringSubmatch(line); m != nil {
+		l.addVertex("#"+m[1], m[2])
+	} else {
+		l.addClassic(line)
+	}
+}
+
+// addVertex follows a line of BuildKit plain progress output.
+func (l *buildLog) addVertex(vertex, message string) {
+	step := l.steps[vertex]
+	if step == nil {
+		// BuildKit repeats a step's line when its output resumes
+		if m := vertexStepPattern.FindStringSubmatch(message); m != nil {
+			l.start(vertex, &buildStep{label: m[1], command: m[2]})
+		}
+		return
+	}
+
+	switch {
+	case message == "CACHED":
+		step.cached = true
+		l.finish(step, 0)
+	case vertexDonePattern.MatchString(message):
+		seconds, _ := strconv.ParseFloat(vertexDonePattern.FindStringSubmatch(message)[1], 64)
+		l.finish(step, time.Duration(seconds*float64(time.Second)))
+	}
+}
+
+// addClassic follows a line of the classic builder's output, where a
+// step ends when the next one starts.
+func (l *buildLog) addClassic(line string) {
+	if m := classicStepPattern.FindStringSubmatch(line); m != nil {
+		l.finishCurrent()
+		if l.steps[m[1]] != nil {
+			return
+		}
+		l.current = &buildStep{label: m[1], command: m[2]}
+		l.start(m[1], l.current)
+		return
+	}
+	if l.current == nil {
+		return
+	}
+	switch {
+	case line == " ---> Using cache":
+		l.current.cached = true
+	case strings.HasPrefix(line, "Successfully built"):
+		l.finishCurrent()
+	}
+}
+
+// start records a step that began now.
+func (l *buildLog) start(key string, step *buildStep) {
+	step.started = l.now()
+	l.steps[key] = step
+	l.order = append(l.order, step)
+}
+
+// finishCurrent ends the classic builder's running step.
+func (l *buildLog) finishCurrent() {
+	if l.current != nil {
+		l.finish(l.current, l.now().Sub(l.current.started))
+		l.current = nil
+	}
+}
+
+// finish prints a finished step; duration is ignored for cached steps.
+func (l *buildLog) finish(step *buildStep, duration time.Duration) {
+	if step.done {
+		return
+	}
+	step.done = true
+	l.finished++
+
+	result := formatStepDuration(duration)
+	if step.cached {
+		l.cached++
+		result = "cached"
+	}
+	fmt.Fprintf(l.writer(), "  ✓ [%s] %s (%s)\n", step.label, step.command, result)
+}
+
+// succeeded ends the progress display with a summary line.
+func (l *buildLog) succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.quiet {
+		return
+	}
+	l.finishCurrent()
+	fmt.Fprintf(l.writer(), "  %d step(s) in %s, %d cached\n", l.finished, formatStepDuration(l.now().Sub(l.started)), l.cached)
+}
+
+// failed marks the steps that were still running and logs the output
+// quiet mode held back, so the failing step's output is not lost.
+func (l *buildLog) failed() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.quiet {
+		return
+	}
+	for _, step := range l.order {
+		if !step.done {
+			fmt.Fprintf(l.writer(), "  ❌ [%s] %s\n", step.label, step.command)
+		}
+	}
+	l.logger.Info("docker build output:")
+	for _, line := range l.lines {
+		l.logger.Info(line, "source", "build")
+	}
+}
+
+// writer returns where the progress display goes.
+func (l *buildLog) writer() io.Writer {
+	if l.out == nil {
+		return os.Stdout
+	}
+	return l.out
+}
+
+// formatStepDuration rounds d for display, e.g. "1.2s" or "2m3s".
+func formatStepDuration(d time.Duration) string {
+	if d >= time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
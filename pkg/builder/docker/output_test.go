@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+var buildOutput = []string{
+	"#5 [internal] load metadata for docker.io/library/golang:1.25",
+	"#5 DONE 0.4s",
+	"#6 [builder 1/3] FROM docker.io/library/golang:1.25",
+	"#6 CACHED",
+	"#7 [builder 2/3] COPY . .",
+	"#7 DONE 0.1s",
+	"#8 [builder 3/3] RUN go build ./...",
+	"#8 0.512 main.go:3:1: syntax error",
+	"#6 [builder 1/3] FROM docker.io/library/golang:1.25",
+	"#8 ERROR: process \"/bin/sh -c go build ./...\" did not complete successfully: exit code: 1",
+}
+
+// quietLog returns a quiet buildLog writing its progress to out, whose
+// clock advances by a second on every reading.
+func quietLog(logger *util.MockLogger, out *bytes.Buffer) *buildLog {
+	log := newBuildLog(logger, config.BuildOutputQuiet)
+	log.out = out
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	log.started = now
+	log.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return log
+}
+
+func TestBuildLog_Quiet(t *testing.T) {
+	logger := &util.MockLogger{}
+	var out bytes.Buffer
+	log := quietLog(logger, &out)
+	for _, line := range buildOutput {
+		log.add("stderr", line)
+	}
+
+	if len(logger.Messages) != 0 {
+		t.Errorf("quiet mode logged %q before the build ended", logger.Messages)
+	}
+	want := "  ✓ [builder 1/3] FROM docker.io/library/golang:1.25 (cached)\n" +
+		"  ✓ [builder 2/3] COPY . . (100ms)\n"
+	if out.String() != want {
+		t.Errorf("progress = %q, want %q", out.String(), want)
+	}
+
+	// A failure marks the failed step and shows everything held back
+	log.failed()
+	if !strings.HasSuffix(out.String(), "  ❌ [builder 3/3] RUN go build ./...\n") {
+		t.Errorf("progress lacks the failed step: %q", out.String())
+	}
+	if got := logger.Messages[len(logger.Messages)-3]; got != "#8 0.512 main.go:3:1: syntax error" {
+		t.Errorf("failure output lacks the error, got %q", logger.Messages)
+	}
+}
+
+func TestBuildLog_ClassicBuilder(t *testing.T) {
+	logger := &util.MockLogger{}
+	var out bytes.Buffer
+	log := quietLog(logger, &out)
+	for _, line := range []string{
+		"Step 1/3 : FROM golang:1.25",
+		" ---> 1a2b3c4d5e6f",
+		"Step 2/3 : COPY . .",
+		" ---> Using cache",
+		" ---> 2b3c4d5e6f7a",
+		"Step 3/3 : RUN go build",
+		" ---> Running in 3c4d5e6f7a8b",
+		"Successfully built 4d5e6f7a8b9c",
+	} {
+		log.add("stdout", line)
+	}
+	log.succeeded()
+
+	want := "  ✓ [1/3] FROM golang:1.25 (1s)\n" +
+		"  ✓ [2/3] COPY . . (cached)\n" +
+		"  ✓ [3/3] RUN go build (1s)\n" +
+		"  3 step(s) in 7s, 1 cached\n"
+	if out.String() != want {
+		t.Errorf("progress = %q, want %q", out.String(), want)
+	}
+}
+
+func TestFormatStepDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{1234 * time.Millisecond, "1.2s"},
+		{123456 * time.Millisecond, "2m3s"},
+	}
+	for _, tt := range tests {
+		if got := formatStepDuration(tt.d); got != tt.want {
+			t.Errorf("formatStepDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestBuildLog_Normal(t *testing.T) {
+	logger := &util.MockLogger{}
+	log := newBuildLog(logger, config.BuildOutputNormal)
+	for _, line := range buildOutput {
+		log.add("stderr", line)
+	}
+	log.failed()
+
+	if len(logger.Messages) != len(buildOutput) {
+		t.Errorf("logged %d lines, want all %d", len(logger.Messages), len(buildOutput))
+	}
+}
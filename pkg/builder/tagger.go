@@ -20,8 +20,9 @@ const (
 	TimestampFormat = "20060102-150405"
 )
 
-// tagPattern validates kudev tag format.
-var tagPattern = regexp.MustCompile(`^kudev-[a-f0-9]{8}(-\d{8}-\d{6})?$`)
+// tagPattern validates kudev tag format. The hash length follows
+// spec.hash.length (8 to 63 hex characters).
+var tagPattern = regexp.MustCompile(`^kudev-([a-f0-9]{8,63})(-(\d{8}-\d{6}))?$`)
 
 // Tagger generates image tags based on source code hash.
 type Tagger struct {
@@ -70,24 +71,16 @@ func IsKudevTag(tag string) bool {
 // ParseTag extracts the hash from a kudev tag.
 // Returns empty string if not a valid kudev tag.
 func ParseTag(tag string) (hash string, hasTimestamp bool) {
-	if !IsKudevTag(tag) {
+	m := tagPattern.FindStringSubmatch(tag)
+	if m == nil {
 		return "", false
 	}
-
-	// Remove prefix
-	remainder := tag[len(TagPrefix):]
-
-	// Check for timestamp
-	if len(remainder) > 8 {
-		return remainder[:8], true
-	}
-
-	return remainder, false
+	return m[1], m[3] != ""
 }
 
 // TagInfo contains parsed information from a kudev tag.
 type TagInfo struct {
-	// Hash is the 8-character source hash.
+	// Hash is the source hash (8 characters unless spec.hash.length).
 	Hash string
 
 	// HasTimestamp indicates if timestamp suffix was present.
@@ -99,23 +92,20 @@ type TagInfo struct {
 
 // ParseTagInfo extracts detailed information from a kudev tag.
 func ParseTagInfo(tag string) (*TagInfo, error) {
-	if !IsKudevTag(tag) {
+	m := tagPattern.FindStringSubmatch(tag)
+	if m == nil {
 		return nil, fmt.Errorf("not a kudev tag: %s", tag)
 	}
 
-	// Remove prefix
-	remainder := tag[len(TagPrefix):]
-
 	info := &TagInfo{
-		Hash: remainder[:8],
+		Hash: m[1],
 	}
 
 	// Check for timestamp
-	if len(remainder) > 8 {
+	if m[3] != "" {
 		info.HasTimestamp = true
-		timestampStr := remainder[9:] // Skip the hyphen
 
-		ts, err := time.Parse(TimestampFormat, timestampStr)
+		ts, err := time.Parse(TimestampFormat, m[3])
 		if err != nil {
 			return nil, fmt.Errorf("invalid timestamp in tag: %w", err)
 		}
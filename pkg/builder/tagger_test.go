@@ -108,6 +108,9 @@ func TestIsKudevTag(t *testing.T) {
 		{"kudev-12345678", true},
 		{"kudev-abcdef00", true},
 		{"kudev-a1b2c3d4-20250209-143025", true},
+		{"kudev-a1b2c3d4e5f6", true},                 // spec.hash.length: 12
+		{"kudev-a1b2c3d4e5f6-20250209-143025", true}, // spec.hash.length: 12
+		{"kudev-" + strings.Repeat("a", 64), false},  // Longer than a label value
 		{"latest", false},
 		{"v1.0.0", false},
 		{"kudev-", false},
@@ -137,6 +140,8 @@ func TestParseTag(t *testing.T) {
 		{"kudev-a1b2c3d4", "a1b2c3d4", false},
 		{"kudev-12345678", "12345678", false},
 		{"kudev-a1b2c3d4-20250209-143025", "a1b2c3d4", true},
+		{"kudev-a1b2c3d4e5f6", "a1b2c3d4e5f6", false},
+		{"kudev-a1b2c3d4e5f6-20250209-143025", "a1b2c3d4e5f6", true},
 		{"latest", "", false},
 		{"", "", false},
 	}
@@ -183,6 +188,15 @@ func TestParseTagInfo(t *testing.T) {
 	if !info.Timestamp.Equal(expectedTime) {
 		t.Errorf("Timestamp = %v, want %v", info.Timestamp, expectedTime)
 	}
+
+	// Test longer hash (spec.hash.length)
+	info, err = ParseTagInfo("kudev-a1b2c3d4e5f6-20250209-143025")
+	if err != nil {
+		t.Fatalf("ParseTagInfo failed: %v", err)
+	}
+	if info.Hash != "a1b2c3d4e5f6" || !info.Timestamp.Equal(expectedTime) {
+		t.Errorf("ParseTagInfo = %+v, want hash a1b2c3d4e5f6 at %v", info, expectedTime)
+	}
 }
 
 func TestCompareHashes(t *testing.T) {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/nanaki-93/kudev/pkg/config"
 )
 
 type Builder interface {
@@ -19,18 +21,94 @@ type BuildOptions struct {
 	BuildArgs      map[string]string
 	Target         string
 	NoCache        bool
+
+	// Pull always pulls base images instead of using local copies.
+	Pull bool
+
+	// Exclusions (spec.exclude) are kept out of the build context
+	// through a kudev-managed block in SourceDir/.dockerignore.
+	Exclusions []string
+
+	// Buildx selects 'docker buildx build'; Platform, CacheFrom and
+	// CacheTo only apply with it (see config.BuildConfig).
+	Buildx    bool
+	Platform  string
+	CacheFrom []string
+	CacheTo   []string
+
+	// BakeFile selects 'docker buildx bake' with this file, building
+	// BakeTarget; the settings above are applied to it with --set.
+	BakeFile   string
+	BakeTarget string
+
+	// Output is a config.BuildOutput* mode; empty means normal.
+	Output string
+}
+
+// NewBuildOptions returns the options to build cfg's image with tag.
+func NewBuildOptions(cfg *config.DeploymentConfig, tag string) BuildOptions {
+	opts := BuildOptions{
+		SourceDir:      cfg.BuildContextDir(),
+		DockerfilePath: cfg.BuildDockerfilePath(),
+		ImageName:      cfg.Spec.ImageName,
+		ImageTag:       tag,
+		Exclusions:     cfg.Spec.BuildContextExclusions,
+	}
+	if b := cfg.Spec.Build; b != nil {
+		opts.NoCache = b.NoCache
+		opts.Pull = b.Pull
+	}
+	if cfg.UsesBuildx() {
+		b := cfg.Spec.Build
+		opts.Buildx = true
+		opts.Platform = b.Platform
+		opts.CacheFrom = b.CacheFrom
+		opts.CacheTo = b.CacheTo
+	}
+	if bakeFile := cfg.BuildBakeFile(); bakeFile != "" {
+		b := cfg.Spec.Build
+		opts.BakeFile = bakeFile
+		opts.BakeTarget = cfg.BuildBakeTarget()
+		opts.Platform = b.Platform
+		opts.CacheFrom = b.CacheFrom
+		opts.CacheTo = b.CacheTo
+	}
+	return opts
 }
 
 type ImageRef struct {
 	FullRef string
 	ID      string
-	Digest  string
+
+	// Digest is the registry manifest digest (sha256:...) of a pre-built
+	// image (spec.image.ref). Built images are never pushed, so they
+	// have none and are deployed by their content-hash tag.
+	Digest string
 }
 
 func (r *ImageRef) String() string {
 	return r.FullRef
 }
 
+// Repository returns FullRef without its tag or digest.
+func (r *ImageRef) Repository() string {
+	ref, _, _ := strings.Cut(r.FullRef, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// DeployRef returns the reference pods should run. With a known digest
+// it is repository@digest, so the kubelet cannot pick up a different
+// image that reuses the tag; otherwise it is FullRef.
+func (r *ImageRef) DeployRef() string {
+	if r.Digest != "" {
+		return r.Repository() + "@" + r.Digest
+	}
+	return r.FullRef
+}
+
 type Factory func() (Builder, error)
 
 func (o BuildOptions) Validate() error {
@@ -1,7 +1,10 @@
 package builder
 
 import (
+	"path/filepath"
 	"testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
 )
 
 func TestBuildOptionsValidate(t *testing.T) {
@@ -96,3 +99,77 @@ func TestImageRefString(t *testing.T) {
 		t.Errorf("String() = %v, want %v", ref.String(), "myapp:kudev-abc123")
 	}
 }
+
+func TestImageRef_DeployRef(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  ImageRef
+		want string
+	}{
+		{
+			name: "digest when known",
+			ref:  ImageRef{FullRef: "myapp:kudev-abc123", Digest: "sha256:1234"},
+			want: "myapp@sha256:1234",
+		},
+		{
+			name: "registry with port",
+			ref:  ImageRef{FullRef: "localhost:5000/team/myapp:kudev-abc123", Digest: "sha256:1234"},
+			want: "localhost:5000/team/myapp@sha256:1234",
+		},
+		{
+			name: "already pinned",
+			ref:  ImageRef{FullRef: "ghcr.io/org/api@sha256:1234", Digest: "sha256:1234"},
+			want: "ghcr.io/org/api@sha256:1234",
+		},
+		{
+			name: "tag when digest unknown",
+			ref:  ImageRef{FullRef: "myapp:kudev-abc123"},
+			want: "myapp:kudev-abc123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ref.DeployRef(); got != tt.want {
+				t.Errorf("DeployRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBuildOptions(t *testing.T) {
+	cfg := config.NewDeploymentConfig("myapp")
+	cfg.ProjectRoot = "/project"
+	cfg.Spec.Build = &config.BuildConfig{CacheFrom: []string{"type=local,src=/tmp/cache"}}
+
+	// Buildx settings only apply once buildx is enabled
+	if opts := NewBuildOptions(cfg, "kudev-abc12345"); opts.Buildx || len(opts.CacheFrom) != 0 {
+		t.Errorf("buildx options without buildx: %+v", opts)
+	}
+
+	// A platform alone cross-builds with buildx
+	cfg.Spec.Build.Platform = "linux/amd64"
+	if opts := NewBuildOptions(cfg, "kudev-abc12345"); !opts.Buildx || opts.Platform != "linux/amd64" {
+		t.Errorf("NewBuildOptions() = %+v, want a buildx build for linux/amd64", opts)
+	}
+
+	cfg.Spec.Build.Buildx = true
+	opts := NewBuildOptions(cfg, "kudev-abc12345")
+	if !opts.Buildx || opts.Platform != "linux/amd64" || len(opts.CacheFrom) != 1 {
+		t.Errorf("NewBuildOptions() = %+v, want buildx settings", opts)
+	}
+	if opts.SourceDir != "/project" || opts.ImageTag != "kudev-abc12345" || opts.ImageName != cfg.Spec.ImageName {
+		t.Errorf("NewBuildOptions() = %+v", opts)
+	}
+	// A bake file is resolved against the project root
+	cfg.Spec.Build = &config.BuildConfig{BakeFile: "docker-bake.hcl"}
+	opts = NewBuildOptions(cfg, "kudev-abc12345")
+	if opts.BakeFile != filepath.Join("/project", "docker-bake.hcl") || opts.BakeTarget != config.DefaultBakeTarget {
+		t.Errorf("NewBuildOptions() = %+v, want bake settings", opts)
+	}
+
+	cfg.Spec.Build = &config.BuildConfig{NoCache: true, Pull: true}
+	if opts := NewBuildOptions(cfg, "kudev-abc12345"); !opts.NoCache || !opts.Pull {
+		t.Errorf("NewBuildOptions() = %+v, want no-cache and pull", opts)
+	}
+}
@@ -0,0 +1,138 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to (see Advance).
+// Timers due at the new time fire synchronously inside Advance, in
+// deadline order; AfterFunc callbacks run on the caller's goroutine.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a pending After channel or AfterFunc callback.
+type waiter struct {
+	fake *Fake
+	at   time.Time
+	ch   chan time.Time
+	fn   func()
+}
+
+// NewFake returns a fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel receiving the fake time once it has advanced by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	f.add(&waiter{fake: f, ch: ch}, d)
+	return ch
+}
+
+// AfterFunc calls fn once the fake time has advanced by d.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &waiter{fake: f, fn: fn}
+	f.add(w, d)
+	return w
+}
+
+// add schedules w, firing it right away when d <= 0.
+func (f *Fake) add(w *waiter, d time.Duration) {
+	f.mu.Lock()
+	w.at = f.now.Add(d)
+	if d <= 0 {
+		now := f.now
+		f.mu.Unlock()
+		w.fire(now)
+		return
+	}
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+	f.mu.Unlock()
+}
+
+// Advance moves the fake time forward by d, firing the timers that
+// become due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	now := f.now
+
+	var due, pending []*waiter
+	for _, w := range f.waiters {
+		if w.at.After(now) {
+			pending = append(pending, w)
+		} else {
+			due = append(due, w)
+		}
+	}
+	f.waiters = pending
+	f.cond.Broadcast()
+	f.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+	for _, w := range due {
+		w.fire(now)
+	}
+}
+
+// Waiters returns the number of pending After channels and AfterFunc calls.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least n timers are pending, so a test can
+// advance the clock once the code under test is waiting on it.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+func (w *waiter) fire(now time.Time) {
+	if w.fn != nil {
+		w.fn()
+		return
+	}
+	w.ch <- now
+}
+
+// Stop removes the waiter if it is still pending.
+func (w *waiter) Stop() bool {
+	f := w.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, pending := range f.waiters {
+		if pending == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFake_After(t *testing.T) {
+	f := NewFake(epoch)
+	ch := f.After(time.Second)
+
+	f.Advance(999 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("After fired early")
+	default:
+	}
+
+	f.Advance(time.Millisecond)
+	select {
+	case got := <-ch:
+		if !got.Equal(epoch.Add(time.Second)) {
+			t.Errorf("After sent %v, want %v", got, epoch.Add(time.Second))
+		}
+	default:
+		t.Fatal("After did not fire")
+	}
+	if f.Waiters() != 0 {
+		t.Errorf("Waiters() = %d, want 0", f.Waiters())
+	}
+}
+
+func TestFake_AfterFuncOrderAndStop(t *testing.T) {
+	f := NewFake(epoch)
+	var fired []string
+
+	f.AfterFunc(2*time.Second, func() { fired = append(fired, "b") })
+	f.AfterFunc(time.Second, func() { fired = append(fired, "a") })
+	stopped := f.AfterFunc(time.Second, func() { fired = append(fired, "stopped") })
+
+	if !stopped.Stop() {
+		t.Error("Stop() = false for a pending timer")
+	}
+	if stopped.Stop() {
+		t.Error("Stop() = true for a stopped timer")
+	}
+
+	f.Advance(5 * time.Second)
+	if len(fired) != 2 || fired[0] != "a" || fired[1] != "b" {
+		t.Errorf("fired = %v, want [a b]", fired)
+	}
+	if got := f.Since(epoch); got != 5*time.Second {
+		t.Errorf("Since() = %v, want 5s", got)
+	}
+}
+
+func TestFake_NonPositiveDurationFiresImmediately(t *testing.T) {
+	f := NewFake(epoch)
+	select {
+	case <-f.After(0):
+	default:
+		t.Fatal("After(0) did not fire")
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		<-f.After(time.Minute)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("goroutine was not woken by Advance")
+	}
+}
+
+func TestOrReal(t *testing.T) {
+	if OrReal(nil) != Real {
+		t.Error("OrReal(nil) should be Real")
+	}
+	f := NewFake(epoch)
+	if OrReal(f) != Clock(f) {
+		t.Error("OrReal(f) should be f")
+	}
+}
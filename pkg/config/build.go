@@ -0,0 +1,145 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultBakeTarget is the bake target built when spec.build.bakeTarget
+// is unset.
+const DefaultBakeTarget = "default"
+
+// Values of spec.build.backend.
+const (
+	BuildBackendCLI = "cli"
+	BuildBackendAPI = "api"
+)
+
+// BuildBackend returns spec.build.backend, or BuildBackendCLI if unset.
+func (c *DeploymentConfig) BuildBackend() string {
+	if c.Spec.Build == nil || c.Spec.Build.Backend == "" {
+		return BuildBackendCLI
+	}
+	return c.Spec.Build.Backend
+}
+
+// BuildContextDir returns the directory used as docker build context,
+// for source hashing and for watching.
+//
+// This is spec.build.context resolved against the project root, or the
+// project root itself when unset.
+func (c *DeploymentConfig) BuildContextDir() string {
+	if c.Spec.Build == nil || c.Spec.Build.Context == "" {
+		return c.ProjectRoot
+	}
+	if filepath.IsAbs(c.Spec.Build.Context) {
+		return filepath.Clean(c.Spec.Build.Context)
+	}
+	return filepath.Join(c.ProjectRoot, c.Spec.Build.Context)
+}
+
+// BuildDockerfilePath returns spec.dockerfilePath as passed to docker build.
+//
+// Docker resolves a relative -f against the build context, while
+// spec.dockerfilePath is relative to the project root, so the path is made
+// absolute whenever the two directories differ.
+func (c *DeploymentConfig) BuildDockerfilePath() string {
+	path := c.Spec.DockerfilePath
+	if filepath.IsAbs(path) || c.BuildContextDir() == c.ProjectRoot {
+		return path
+	}
+	return filepath.Join(c.ProjectRoot, path)
+}
+
+// BuildInputsOutsideContext returns the files that shape the image but
+// sit outside BuildContextDir: the Dockerfile and the config file, when
+// spec.build.context points elsewhere. Source hashing and watching cover
+// them on top of the context.
+func (c *DeploymentConfig) BuildInputsOutsideContext() []string {
+	contextDir := c.BuildContextDir()
+	configPath := c.ConfigPath
+	if configPath != "" {
+		if abs, err := filepath.Abs(configPath); err == nil {
+			configPath = abs
+		}
+	}
+
+	var inputs []string
+	for _, path := range []string{c.BuildDockerfilePath(), configPath} {
+		if path == "" {
+			continue
+		}
+		// A relative Dockerfile path is relative to the context
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(contextDir, path)
+		}
+		rel, err := filepath.Rel(contextDir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		inputs = append(inputs, filepath.Clean(path))
+	}
+	return inputs
+}
+
+// UsesBuildx reports whether the image is built with docker buildx:
+// spec.build.buildx is set, or spec.build.platform asks for a cross-build.
+func (c *DeploymentConfig) UsesBuildx() bool {
+	b := c.Spec.Build
+	return b != nil && (b.Buildx || b.Platform != "")
+}
+
+// BuildPlatform returns spec.build.platform, or "" to build for the
+// docker daemon's own platform.
+func (c *DeploymentConfig) BuildPlatform() string {
+	if c.Spec.Build == nil {
+		return ""
+	}
+	return c.Spec.Build.Platform
+}
+
+// BuildBakeFile returns spec.build.bakeFile resolved against the project
+// root, or "" when the image is not built with 'docker buildx bake'.
+func (c *DeploymentConfig) BuildBakeFile() string {
+	if c.Spec.Build == nil || c.Spec.Build.BakeFile == "" {
+		return ""
+	}
+	if filepath.IsAbs(c.Spec.Build.BakeFile) {
+		return filepath.Clean(c.Spec.Build.BakeFile)
+	}
+	return filepath.Join(c.ProjectRoot, c.Spec.Build.BakeFile)
+}
+
+// BuildBakeTarget returns spec.build.bakeTarget, or DefaultBakeTarget.
+func (c *DeploymentConfig) BuildBakeTarget() string {
+	if c.Spec.Build == nil || c.Spec.Build.BakeTarget == "" {
+		return DefaultBakeTarget
+	}
+	return c.Spec.Build.BakeTarget
+}
+
+// DeploymentTemplatePath returns spec.templates.deployment resolved
+// against the project root, or "" for the built-in template.
+func (c *DeploymentConfig) DeploymentTemplatePath() string {
+	if c.Spec.Templates == nil {
+		return ""
+	}
+	return c.resolvePath(c.Spec.Templates.Deployment)
+}
+
+// ServiceTemplatePath returns spec.templates.service resolved against
+// the project root, or "" for the built-in template.
+func (c *DeploymentConfig) ServiceTemplatePath() string {
+	if c.Spec.Templates == nil {
+		return ""
+	}
+	return c.resolvePath(c.Spec.Templates.Service)
+}
+
+// resolvePath makes a path relative to the project root absolute.
+func (c *DeploymentConfig) resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.ProjectRoot, path)
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildContextDir(t *testing.T) {
+	root := filepath.FromSlash("/work/monorepo")
+
+	tests := []struct {
+		name           string
+		build          *BuildConfig
+		wantContext    string
+		wantDockerfile string
+	}{
+		{
+			name:           "default",
+			build:          nil,
+			wantContext:    root,
+			wantDockerfile: "./Dockerfile",
+		},
+		{
+			name:           "empty context",
+			build:          &BuildConfig{},
+			wantContext:    root,
+			wantDockerfile: "./Dockerfile",
+		},
+		{
+			name:           "subdirectory",
+			build:          &BuildConfig{Context: "./services/api"},
+			wantContext:    filepath.Join(root, "services", "api"),
+			wantDockerfile: filepath.Join(root, "Dockerfile"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &DeploymentConfig{
+				Spec:        SpecConfig{DockerfilePath: "./Dockerfile", Build: tt.build},
+				ProjectRoot: root,
+			}
+
+			if got := cfg.BuildContextDir(); got != tt.wantContext {
+				t.Errorf("BuildContextDir() = %q, want %q", got, tt.wantContext)
+			}
+			if got := cfg.BuildDockerfilePath(); got != tt.wantDockerfile {
+				t.Errorf("BuildDockerfilePath() = %q, want %q", got, tt.wantDockerfile)
+			}
+		})
+	}
+}
+
+func TestBuildInputsOutsideContext(t *testing.T) {
+	root := filepath.FromSlash("/work/monorepo")
+	configPath := filepath.Join(root, ".kudev.yaml")
+
+	tests := []struct {
+		name       string
+		build      *BuildConfig
+		dockerfile string
+		want       []string
+	}{
+		{name: "default context", dockerfile: "./Dockerfile"},
+		{
+			name:       "dockerfile and config at the root",
+			build:      &BuildConfig{Context: "./services/api"},
+			dockerfile: "./Dockerfile",
+			want:       []string{filepath.Join(root, "Dockerfile"), configPath},
+		},
+		{
+			name:       "dockerfile inside the context",
+			build:      &BuildConfig{Context: "./services/api"},
+			dockerfile: "./services/api/Dockerfile",
+			want:       []string{configPath},
+		},
+		{
+			name:       "sibling with a common prefix",
+			build:      &BuildConfig{Context: "./services/api"},
+			dockerfile: "./services/api-v2/Dockerfile",
+			want:       []string{filepath.Join(root, "services", "api-v2", "Dockerfile"), configPath},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &DeploymentConfig{
+				Spec:        SpecConfig{DockerfilePath: tt.dockerfile, Build: tt.build},
+				ProjectRoot: root,
+				ConfigPath:  configPath,
+			}
+			got := cfg.BuildInputsOutsideContext()
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("BuildInputsOutsideContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWithContext_BuildContext(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "services", "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewDeploymentConfig("myapp")
+	cfg.Spec.DockerfilePath = "./Dockerfile"
+
+	cfg.Spec.Build = &BuildConfig{Context: "./services/api"}
+	if err := cfg.ValidateWithContext(root); err != nil {
+		t.Errorf("expected valid build context, got %v", err)
+	}
+
+	cfg.Spec.Build = &BuildConfig{Context: "./services/missing"}
+	err := cfg.ValidateWithContext(root)
+	if err == nil || !strings.Contains(err.Error(), "spec.build.context") {
+		t.Errorf("expected build context error, got %v", err)
+	}
+}
+
+func TestBuildBakeFile(t *testing.T) {
+	root := filepath.FromSlash("/work/app")
+	cfg := &DeploymentConfig{ProjectRoot: root}
+
+	if got := cfg.BuildBakeFile(); got != "" {
+		t.Errorf("BuildBakeFile() = %q without a bakeFile", got)
+	}
+	if got := cfg.BuildBakeTarget(); got != DefaultBakeTarget {
+		t.Errorf("BuildBakeTarget() = %q, want %q", got, DefaultBakeTarget)
+	}
+
+	cfg.Spec.Build = &BuildConfig{BakeFile: "build/docker-bake.hcl", BakeTarget: "api"}
+	if got, want := cfg.BuildBakeFile(), filepath.Join(root, "build", "docker-bake.hcl"); got != want {
+		t.Errorf("BuildBakeFile() = %q, want %q", got, want)
+	}
+	if got := cfg.BuildBakeTarget(); got != "api" {
+		t.Errorf("BuildBakeTarget() = %q, want api", got)
+	}
+}
+
+func TestValidateWithContext_BakeFile(t *testing.T) {
+	root := t.TempDir()
+	cfg := NewDeploymentConfig("myapp")
+	cfg.Spec.Build = &BuildConfig{BakeFile: "docker-bake.hcl"}
+
+	// The bake file replaces the Dockerfile check
+	err := cfg.ValidateWithContext(root)
+	if err == nil || !strings.Contains(err.Error(), "spec.build.bakeFile") || strings.Contains(err.Error(), "dockerfilePath") {
+		t.Errorf("expected only a bake file error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "docker-bake.hcl"), []byte(`target "default" {}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateWithContext(root); err != nil {
+		t.Errorf("expected valid bake config, got %v", err)
+	}
+}
+
+func TestTemplatePaths(t *testing.T) {
+	root := filepath.FromSlash("/work/app")
+	cfg := &DeploymentConfig{ProjectRoot: root}
+
+	if cfg.DeploymentTemplatePath() != "" || cfg.ServiceTemplatePath() != "" {
+		t.Error("expected the built-in templates without spec.templates")
+	}
+
+	cfg.Spec.Templates = &TemplatesConfig{Deployment: "deploy/deployment.yaml"}
+	if got, want := cfg.DeploymentTemplatePath(), filepath.Join(root, "deploy", "deployment.yaml"); got != want {
+		t.Errorf("DeploymentTemplatePath() = %q, want %q", got, want)
+	}
+	if got := cfg.ServiceTemplatePath(); got != "" {
+		t.Errorf("ServiceTemplatePath() = %q, want the built-in template", got)
+	}
+}
+
+func TestValidateWithContext_Templates(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewDeploymentConfig("myapp")
+	cfg.Spec.DockerfilePath = "./Dockerfile"
+	cfg.Spec.Templates = &TemplatesConfig{Service: "service.yaml"}
+
+	err := cfg.ValidateWithContext(root)
+	if err == nil || !strings.Contains(err.Error(), "spec.templates.service") {
+		t.Errorf("expected template error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "service.yaml"), []byte("kind: Service"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateWithContext(root); err != nil {
+		t.Errorf("expected valid templates, got %v", err)
+	}
+}
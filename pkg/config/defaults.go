@@ -11,6 +11,11 @@ func ApplyDefaults(cfg *DeploymentConfig) {
 	if cfg.Kind == "" {
 		cfg.Kind = DefaultKind
 	}
+	// A pre-built image names itself
+	if cfg.Spec.ImageName == "" && cfg.PrebuiltImage() != "" {
+		cfg.Spec.ImageName = imageBaseName(cfg.PrebuiltImage())
+	}
+
 	if cfg.Spec.Namespace == "" {
 		cfg.Spec.Namespace = "default"
 	}
@@ -19,7 +24,7 @@ func ApplyDefaults(cfg *DeploymentConfig) {
 		cfg.Spec.Replicas = 1
 	}
 
-	if cfg.Spec.LocalPort <= 0 {
+	if cfg.Spec.LocalPort <= 0 && !cfg.Spec.AutoLocalPort {
 		cfg.Spec.LocalPort = 8080
 	}
 	if cfg.Spec.ServicePort <= 0 {
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestDuration_YAMLRoundTrip(t *testing.T) {
+	var w WatchConfig
+	if err := yaml.Unmarshal([]byte("rebuildEvery: 1h30m\n"), &w); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+
+	if w.RebuildEvery.Duration != 90*time.Minute {
+		t.Errorf("RebuildEvery = %v, want 1h30m", w.RebuildEvery.Duration)
+	}
+
+	out, err := yaml.Marshal(w)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if string(out) != "rebuildEvery: 1h30m0s\n" {
+		t.Errorf("Marshal = %q", out)
+	}
+}
+
+func TestDuration_Invalid(t *testing.T) {
+	var w WatchConfig
+	if err := yaml.Unmarshal([]byte("rebuildEvery: soon\n"), &w); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+	if err := yaml.Unmarshal([]byte("rebuildEvery: 60\n"), &w); err == nil {
+		t.Error("expected error for bare number")
+	}
+}
+
+func TestValidateWatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		every   time.Duration
+		wantErr bool
+	}{
+		{"disabled", 0, false},
+		{"one hour", time.Hour, false},
+		{"minimum", MinRebuildEvery, false},
+		{"too short", time.Second, true},
+		{"negative", -time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateWatch(&WatchConfig{RebuildEvery: Duration{tt.every}})
+			if errs.HasErrors() != tt.wantErr {
+				t.Errorf("validateWatch(%v) errors = %v, wantErr %v", tt.every, errs.Errors, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+// Source hash algorithms (spec.hash.algorithm).
+const (
+	HashSHA256 = "sha256"
+	HashSHA512 = "sha512"
+)
+
+const (
+	// DefaultHashLength is the spec.hash.length default.
+	DefaultHashLength = 8
+
+	// MinHashLength and MaxHashLength bound spec.hash.length. The hash is
+	// also the kudev-hash label value, which holds at most 63 characters.
+	MinHashLength = 8
+	MaxHashLength = 63
+)
+
+// EffectiveLength returns Length, or DefaultHashLength.
+func (h *HashConfig) EffectiveLength() int {
+	if h == nil || h.Length == 0 {
+		return DefaultHashLength
+	}
+	return h.Length
+}
+
+// EffectiveAlgorithm returns Algorithm, or HashSHA256.
+func (h *HashConfig) EffectiveAlgorithm() string {
+	if h == nil || h.Algorithm == "" {
+		return HashSHA256
+	}
+	return h.Algorithm
+}
+
+// HashModes reports whether file permission bits are hashed.
+func (h *HashConfig) HashModes() bool {
+	return h != nil && h.IncludeModes
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultImagePollInterval is how often watch polls spec.image.ref
+// when spec.image.pollInterval is unset.
+const DefaultImagePollInterval = 30 * time.Second
+
+// MinImagePollInterval is the shortest allowed spec.image.pollInterval.
+const MinImagePollInterval = 5 * time.Second
+
+// PrebuiltImage returns spec.image.ref, or "" when kudev builds the image.
+func (c *DeploymentConfig) PrebuiltImage() string {
+	if c.Spec.Image == nil {
+		return ""
+	}
+	return c.Spec.Image.Ref
+}
+
+// EffectivePollInterval returns PollInterval, or DefaultImagePollInterval.
+func (i *ImageConfig) EffectivePollInterval() time.Duration {
+	if i == nil || i.PollInterval.Duration <= 0 {
+		return DefaultImagePollInterval
+	}
+	return i.PollInterval.Duration
+}
+
+// imageBaseName returns the last path element of ref's repository,
+// e.g. "api" for ghcr.io/org/api:main.
+func imageBaseName(ref string) string {
+	ref, _, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
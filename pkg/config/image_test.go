@@ -0,0 +1,84 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyDefaults_PrebuiltImageName(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "ghcr.io/org/api:main", want: "api"},
+		{ref: "localhost:5000/api", want: "api"},
+		{ref: "ghcr.io/org/api@sha256:abc", want: "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			cfg := &DeploymentConfig{Spec: SpecConfig{Image: &ImageConfig{Ref: tt.ref}}}
+			ApplyDefaults(cfg)
+			if cfg.Spec.ImageName != tt.want {
+				t.Errorf("ImageName = %q, want %q", cfg.Spec.ImageName, tt.want)
+			}
+		})
+	}
+
+	// An explicit imageName is kept
+	cfg := &DeploymentConfig{Spec: SpecConfig{ImageName: "api", Image: &ImageConfig{Ref: "ghcr.io/org/api:main"}}}
+	ApplyDefaults(cfg)
+	if cfg.Spec.ImageName != "api" {
+		t.Errorf("ImageName = %q, want api", cfg.Spec.ImageName)
+	}
+}
+
+func TestValidate_Image(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   *ImageConfig
+		build   *BuildConfig
+		wantErr string
+	}{
+		{name: "ref", image: &ImageConfig{Ref: "ghcr.io/org/api:main"}},
+		{name: "poll interval", image: &ImageConfig{Ref: "ghcr.io/org/api:main", PollInterval: Duration{Duration: time.Minute}}},
+		{name: "missing ref", image: &ImageConfig{}, wantErr: "spec.image.ref is required"},
+		{name: "invalid ref", image: &ImageConfig{Ref: "ghcr.io/org/api main"}, wantErr: "must be an image reference"},
+		{name: "with build", image: &ImageConfig{Ref: "api:main"}, build: &BuildConfig{Buildx: true}, wantErr: "spec.build cannot be used"},
+		{name: "short poll interval", image: &ImageConfig{Ref: "api:main", PollInterval: Duration{Duration: time.Second}}, wantErr: "pollInterval must be at least"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.ImageName = ""
+			cfg.Spec.DockerfilePath = ""
+			cfg.Spec.Image = tt.image
+			cfg.Spec.Build = tt.build
+			ApplyDefaults(cfg)
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWithContext_PrebuiltImage(t *testing.T) {
+	cfg := NewDeploymentConfig("myapp")
+	cfg.Spec.Image = &ImageConfig{Ref: "ghcr.io/org/api:main"}
+
+	// No Dockerfile is needed
+	if err := cfg.ValidateWithContext(t.TempDir()); err != nil {
+		t.Errorf("ValidateWithContext() error = %v", err)
+	}
+}
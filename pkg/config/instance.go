@@ -0,0 +1,42 @@
+package config
+
+import "fmt"
+
+// ApplyInstance suffixes the deployment name with an instance identifier.
+//
+// This allows several variants of the same project to run side by side
+// in one namespace (e.g., "myapp-feature-x" next to "myapp").
+// The image name is left untouched so instances share built images.
+//
+// Calling with an empty instance is a no-op.
+func (c *DeploymentConfig) ApplyInstance(instance string) error {
+	if instance == "" {
+		return nil
+	}
+
+	if err := validateInstanceName(instance); err != nil {
+		return fmt.Errorf("invalid instance %q: %w", instance, err)
+	}
+
+	name := fmt.Sprintf("%s-%s", c.Metadata.Name, instance)
+	if len(name) > 63 {
+		return fmt.Errorf("instance name %q is too long: %q exceeds 63 characters", instance, name)
+	}
+
+	c.Metadata.Name = name
+	c.Instance = instance
+	return nil
+}
+
+// validateInstanceName checks instance identifiers.
+// Same rules as DNS-1123 labels, but without the 3 character minimum
+// since instances are suffixes (e.g., "a", "v2").
+func validateInstanceName(instance string) error {
+	if len(instance) > 63 {
+		return fmt.Errorf("must be at most 63 characters long, got %d", len(instance))
+	}
+	if !dnsLabelPattern.MatchString(instance) {
+		return fmt.Errorf("must be lowercase alphanumeric and hyphens only, cannot start/end with hyphen")
+	}
+	return nil
+}
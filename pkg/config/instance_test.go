@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyInstance(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance string
+		wantName string
+		wantErr  bool
+	}{
+		{"empty instance is no-op", "", "myapp", false},
+		{"simple suffix", "feature-x", "myapp-feature-x", false},
+		{"short suffix", "a", "myapp-a", false},
+		{"uppercase rejected", "Feature", "", true},
+		{"underscore rejected", "feature_x", "", true},
+		{"leading hyphen rejected", "-x", "", true},
+		{"too long combined name", strings.Repeat("a", 60), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+
+			err := cfg.ApplyInstance(tt.instance)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ApplyInstance(%q) should return error", tt.instance)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyInstance(%q) error = %v", tt.instance, err)
+			}
+
+			if cfg.Metadata.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", cfg.Metadata.Name, tt.wantName)
+			}
+			if cfg.Instance != tt.instance {
+				t.Errorf("Instance = %q, want %q", cfg.Instance, tt.instance)
+			}
+			if cfg.Spec.ImageName != "myapp" {
+				t.Errorf("ImageName = %q, should not be suffixed", cfg.Spec.ImageName)
+			}
+		})
+	}
+}
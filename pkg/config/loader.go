@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,10 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
+// ErrConfigNotFound is returned (wrapped) when no config file was found
+// in any searched location.
+var ErrConfigNotFound = errors.New("configuration file (.kudev.yaml) not found")
+
 // LoaderConfig loads configuration from files.
 //
 // Interface allows multiple implementations (file, env, flags, etc.)
@@ -32,6 +37,14 @@ type FileConfigLoader struct {
 	Path        string
 	ProjectRoot string
 	WorkingDir  string
+
+	// Service selects the document of a multi-document config file by
+	// metadata.name (see ProjectConfig). Optional for single-document files.
+	Service string
+
+	// Profile selects the overlay merged over each document's spec (see
+	// profiles.go). At least one document must define it.
+	Profile string
 }
 
 func NewFileConfigLoader(configPath, projectRoot, workingDir string) *FileConfigLoader {
@@ -89,19 +102,56 @@ func (fcl *FileConfigLoader) Load(ctx context.Context) (*DeploymentConfig, error
 	return nil, fcl.notFoundError()
 }
 
+// LoadProject is Load for every service of the config file: the file is
+// found the same way (without the home directory fallback) and each
+// service is validated.
+func (fcl *FileConfigLoader) LoadProject(ctx context.Context) (*ProjectConfig, error) {
+	path := fcl.Path
+	if path == "" {
+		var err error
+		if path, err = fcl.Discover(); err != nil {
+			return nil, fcl.notFoundError()
+		}
+	}
+
+	project, err := fcl.LoadProjectFromPath(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration from %s: %w", path, err)
+	}
+	for _, svc := range project.Services {
+		if err := svc.ValidateWithContext(fcl.ProjectRoot); err != nil {
+			return nil, fmt.Errorf("invalid configuration for service %q in %s: %w", svc.Metadata.Name, path, err)
+		}
+	}
+	return project, nil
+}
+
 // LoadFromPath loads configuration from a specific file savePath.
 //
 // Process:
 //  1. Read file
-//  2. Parse YAML
-//  3. Convert to DeploymentConfig
-//  4. Apply defaults
-//  5. Validate
+//  2. Parse YAML (one DeploymentConfig per '---' document)
+//  3. Upgrade older apiVersions and merge the selected profile over spec
+//  4. Convert to DeploymentConfig
+//  5. Resolve name templates ({{ .GitBranch }}, {{ .User }})
+//  6. Apply defaults
+//  7. Validate
+//  8. Select the document named by Service (or the only one)
 //
 // Returns:
 //   - Fully initialized DeploymentConfig
 //   - Clear error if parsing or validation fails
 func (fcl *FileConfigLoader) LoadFromPath(ctx context.Context, path string) (*DeploymentConfig, error) {
+	project, err := fcl.LoadProjectFromPath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return project.Select(fcl.Service)
+}
+
+// LoadProjectFromPath loads every DeploymentConfig document of a file.
+// Service names must be unique within the file.
+func (fcl *FileConfigLoader) LoadProjectFromPath(ctx context.Context, path string) (*ProjectConfig, error) {
 	path = filepath.Clean(path)
 	if !filepath.IsAbs(path) {
 		checkPath := filepath.Join(fcl.WorkingDir, path)
@@ -120,18 +170,74 @@ func (fcl *FileConfigLoader) LoadFromPath(ctx context.Context, path string) (*De
 		}
 		return nil, fmt.Errorf("error reading config file %s: %w", path, err)
 	}
-	if len(content) == 0 {
+
+	docs := splitDocuments(content)
+	if len(docs) == 0 {
 		return nil, fmt.Errorf("config file %s is empty", path)
 	}
 
+	project := &ProjectConfig{Path: path}
+	seen := make(map[string]int)
+	for i, doc := range docs {
+		cfg, err := fcl.parseDocument(ctx, path, doc)
+		if err != nil {
+			if len(docs) > 1 {
+				return nil, fmt.Errorf("document %d of %s: %w", i+1, path, err)
+			}
+			return nil, err
+		}
+
+		if first, dup := seen[cfg.Metadata.Name]; dup {
+			return nil, fmt.Errorf("config file %s: documents %d and %d both define service %q", path, first, i+1, cfg.Metadata.Name)
+		}
+		seen[cfg.Metadata.Name] = i + 1
+		project.Services = append(project.Services, cfg)
+	}
+	if fcl.Profile != "" && !project.hasProfile(fcl.Profile) {
+		return nil, project.unknownProfileError(fcl.Profile)
+	}
+	project.linkSiblings()
+	return project, nil
+}
+
+// parseDocument turns one YAML document into a validated DeploymentConfig.
+func (fcl *FileConfigLoader) parseDocument(ctx context.Context, path string, doc []byte) (*DeploymentConfig, error) {
+	// Older schemas are upgraded first, so profiles use the current one
+	doc, migratedFrom, err := migrateDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	applied := false
+	if fcl.Profile != "" {
+		if doc, applied, err = applyProfile(doc, fcl.Profile); err != nil {
+			return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+		}
+	}
+
 	cfg := &DeploymentConfig{}
-	if err := yaml.Unmarshal(content, &cfg); err != nil {
+	if err := yaml.Unmarshal(doc, &cfg); err != nil {
 		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
 	}
+	if applied {
+		cfg.Profile = fcl.Profile
+	}
+	cfg.MigratedFrom = migratedFrom
 
-	ApplyDefaults(cfg)
 	//fixme Do it better
 	cfg.ProjectRoot = fcl.ProjectRoot
+	cfg.ConfigPath = path
+
+	// Resolve templated names (e.g. namespace: dev-{{ .GitBranch }})
+	templateDir := cfg.ProjectRoot
+	if templateDir == "" {
+		templateDir = filepath.Dir(path)
+	}
+	if err := cfg.resolveNameTemplates(ctx, templateDir); err != nil {
+		return nil, err
+	}
+
+	ApplyDefaults(cfg)
 	if err := cfg.Validate(ctx); err != nil {
 		return nil, err
 	}
@@ -234,9 +340,10 @@ func (fcl *FileConfigLoader) notFoundError() error {
 		"Or place a .kudev.yaml file in your project root.",
 		"Or specify a savePath with: kudev --config <savePath>",
 	}
-	return fmt.Errorf("configuration file (.kudev.yaml) not found \n\n"+
+	return fmt.Errorf("%w \n\n"+
 		"Searched in :\n - %s\n\n"+
 		"Suggestions:\n - %s",
+		ErrConfigNotFound,
 		strings.Join(searched, "\n - "),
 		strings.Join(suggestion, "\n - "))
 }
@@ -295,9 +402,30 @@ func FindConfigFile(startDir string) (string, error) {
 //	loader := NewFileConfigLoader(configPath, projectRoot, workingDir)
 //	return loader.Load(ctx)
 func LoadConfig(ctx context.Context, configPath string) (*DeploymentConfig, error) {
+	return LoadServiceConfig(ctx, configPath, "", "")
+}
+
+// LoadServiceConfig is LoadConfig for multi-document config files:
+// service selects the document by metadata.name (empty when the file
+// holds a single config). profile selects an overlay from the profiles
+// section (empty for none).
+func LoadServiceConfig(ctx context.Context, configPath, service, profile string) (*DeploymentConfig, error) {
 	projectRoot, _ := DiscoverProjectRoot("") // Error ignored - not required
 	cwd, _ := os.Getwd()
 
 	loader := NewFileConfigLoader(configPath, projectRoot, cwd)
+	loader.Service = service
+	loader.Profile = profile
 	return loader.Load(ctx)
 }
+
+// LoadProjectConfig loads every service of the config file, for commands
+// working on the whole project, with the given profile (empty for none).
+func LoadProjectConfig(ctx context.Context, configPath, profile string) (*ProjectConfig, error) {
+	projectRoot, _ := DiscoverProjectRoot("") // Error ignored - not required
+	cwd, _ := os.Getwd()
+
+	loader := NewFileConfigLoader(configPath, projectRoot, cwd)
+	loader.Profile = profile
+	return loader.LoadProject(ctx)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -47,6 +48,44 @@ spec:
 	}
 }
 
+// TestFileConfigLoader_LoadFromPath_TemplateValues tests free-form template values.
+func TestFileConfigLoader_LoadFromPath_TemplateValues(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".kudev.yaml")
+
+	configContent := `apiVersion: kudev.io/v1alpha1
+kind: DeploymentConfig
+metadata:
+  name: test-app
+spec:
+  imageName: test-app
+  dockerfilePath: ./Dockerfile
+  servicePort: 8080
+  templateValues:
+    tier: backend
+    sidecar:
+      enabled: true
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	loader := NewFileConfigLoader("", "", tmpDir)
+	cfg, err := loader.LoadFromPath(context.Background(), configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	if cfg.Spec.TemplateValues["tier"] != "backend" {
+		t.Errorf("tier = %v, want backend", cfg.Spec.TemplateValues["tier"])
+	}
+	sidecar, ok := cfg.Spec.TemplateValues["sidecar"].(map[string]interface{})
+	if !ok || sidecar["enabled"] != true {
+		t.Errorf("sidecar = %v, want map with enabled=true", cfg.Spec.TemplateValues["sidecar"])
+	}
+}
+
 // TestFileConfigLoader_LoadFromPath_NotFound tests error when file doesn't exist.
 func TestFileConfigLoader_LoadFromPath_NotFound(t *testing.T) {
 	loader := NewFileConfigLoader("", "", "")
@@ -159,6 +198,10 @@ func TestFileConfigLoader_Discover_NotFound(t *testing.T) {
 	if !strings.Contains(errStr, "Searched in") {
 		t.Errorf("Error should show search paths, got: %s", errStr)
 	}
+
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Errorf("error should wrap ErrConfigNotFound, got: %v", err)
+	}
 }
 
 // TestFileConfigLoader_ApplyDefaults tests that defaults are applied.
@@ -418,3 +461,204 @@ func TestLoadConfig(t *testing.T) {
 		}
 	}
 }
+
+// serviceDoc returns a minimal DeploymentConfig document for name.
+func serviceDoc(name string) string {
+	return `apiVersion: kudev.io/v1alpha1
+kind: DeploymentConfig
+metadata:
+  name: ` + name + `
+spec:
+  imageName: ` + name + `
+  dockerfilePath: ./Dockerfile
+  localPort: 8080
+  servicePort: 8080
+`
+}
+
+// TestFileConfigLoader_MultiDocument tests '---' separated configs.
+func TestFileConfigLoader_MultiDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		service  string
+		wantName string
+		wantErr  string
+	}{
+		{
+			name:     "select by name",
+			content:  serviceDoc("api") + "---\n" + serviceDoc("worker"),
+			service:  "worker",
+			wantName: "worker",
+		},
+		{
+			name:     "leading separator and comment-only document",
+			content:  "---\n# services\n---\n" + serviceDoc("api"),
+			wantName: "api",
+		},
+		{
+			name:    "ambiguous without service",
+			content: serviceDoc("api") + "--- # second\n" + serviceDoc("worker"),
+			wantErr: "defines 2 services (api, worker); select one with --service",
+		},
+		{
+			name:    "unknown service",
+			content: serviceDoc("api"),
+			service: "web",
+			wantErr: `service "web" not found`,
+		},
+		{
+			name:    "duplicate names",
+			content: serviceDoc("api") + "---\n" + serviceDoc("api"),
+			wantErr: `documents 1 and 2 both define service "api"`,
+		},
+		{
+			name:    "invalid document",
+			content: serviceDoc("api") + "---\nkind: DeploymentConfig\n",
+			wantErr: "document 2 of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			configPath := filepath.Join(tmpDir, ".kudev.yaml")
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			loader := NewFileConfigLoader("", "", tmpDir)
+			loader.Service = tt.service
+			cfg, err := loader.LoadFromPath(context.Background(), configPath)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFromPath() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromPath() error = %v", err)
+			}
+			if cfg.Metadata.Name != tt.wantName {
+				t.Errorf("Name = %s, want %s", cfg.Metadata.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestFileConfigLoader_LoadProjectFromPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".kudev.yaml")
+	content := serviceDoc("api") + "---\n" + serviceDoc("worker")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := NewFileConfigLoader("", "", tmpDir).LoadProjectFromPath(context.Background(), configPath)
+	if err != nil {
+		t.Fatalf("LoadProjectFromPath() error = %v", err)
+	}
+	if got := strings.Join(project.Names(), ","); got != "api,worker" {
+		t.Errorf("Names() = %s, want api,worker", got)
+	}
+}
+
+func TestFileConfigLoader_LoadProject(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := serviceDoc("api") + "---\n" + serviceDoc("worker")
+	if err := os.WriteFile(filepath.Join(tmpDir, ".kudev.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewFileConfigLoader("", tmpDir, tmpDir)
+	if _, err := loader.LoadProject(context.Background()); err == nil || !strings.Contains(err.Error(), `service "api"`) {
+		t.Fatalf("LoadProject() error = %v, want missing Dockerfile of service \"api\"", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := loader.LoadProject(context.Background())
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if got := strings.Join(project.Names(), ","); got != "api,worker" {
+		t.Errorf("Names() = %s, want api,worker", got)
+	}
+}
+
+func TestProjectConfig_ServiceURLEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".kudev.yaml")
+	web := serviceDoc("web") + "  env:\n    - name: AUTH_SVC_URL\n      value: http://localhost:9000\n"
+	content := web + "---\n" + serviceDoc("api") + "---\n" + serviceDoc("auth-svc")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewFileConfigLoader("", "", tmpDir)
+	loader.Service = "web"
+	cfg, err := loader.LoadFromPath(context.Background(), configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	// AUTH_SVC_URL is set explicitly, so only API_URL is injected
+	env := cfg.ServiceURLEnv()
+	want := EnvVar{Name: "API_URL", Value: "http://api.default.svc.cluster.local:8080"}
+	if len(env) != 1 || env[0] != want {
+		t.Errorf("ServiceURLEnv() = %+v, want [%+v]", env, want)
+	}
+
+	if err := cfg.ApplyInstance("pr-1"); err != nil {
+		t.Fatal(err)
+	}
+	if env := cfg.ServiceURLEnv(); len(env) != 1 || env[0].Value != "http://api-pr-1.default.svc.cluster.local:8080" {
+		t.Errorf("ServiceURLEnv() with instance = %+v", env)
+	}
+}
+
+func TestProjectConfig_Targets(t *testing.T) {
+	project := &ProjectConfig{Path: ".kudev.yaml"}
+	for _, name := range []string{"api", "worker", "db"} {
+		project.Services = append(project.Services, NewDeploymentConfig(name))
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		exclude []string
+		want    string
+		wantErr string
+	}{
+		{name: "all", want: "api,worker,db"},
+		{name: "file order", names: []string{"db", "api"}, want: "api,db"},
+		{name: "exclude", exclude: []string{"db"}, want: "api,worker"},
+		{name: "names and exclude", names: []string{"api", "db"}, exclude: []string{"db"}, want: "api"},
+		{name: "unknown name", names: []string{"web"}, wantErr: `service "web" not found`},
+		{name: "unknown exclude", exclude: []string{"web"}, wantErr: `service "web" not found`},
+		{name: "all excluded", names: []string{"db"}, exclude: []string{"db"}, wantErr: "no services selected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := project.Targets(tt.names, tt.exclude)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Targets() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Targets() error = %v", err)
+			}
+			names := make([]string, 0, len(targets))
+			for _, svc := range targets {
+				names = append(names, svc.Metadata.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("Targets() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
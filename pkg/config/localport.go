@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LocalPortAuto is the spec.localPort value that lets kudev pick the port.
+const LocalPortAuto = "auto"
+
+// UnmarshalJSON accepts `localPort: auto` in addition to a port number.
+func (s *SpecConfig) UnmarshalJSON(data []byte) error {
+	type plain SpecConfig
+	aux := struct {
+		*plain
+		LocalPort json.RawMessage `json:"localPort,omitempty"`
+	}{plain: (*plain)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	s.LocalPort, s.AutoLocalPort = 0, false
+	if len(aux.LocalPort) == 0 || string(aux.LocalPort) == "null" {
+		return nil
+	}
+
+	var auto string
+	if err := json.Unmarshal(aux.LocalPort, &auto); err == nil {
+		if auto != LocalPortAuto {
+			return fmt.Errorf("spec.localPort must be a port number or %q, got %q", LocalPortAuto, auto)
+		}
+		s.AutoLocalPort = true
+		return nil
+	}
+
+	if err := json.Unmarshal(aux.LocalPort, &s.LocalPort); err != nil {
+		return fmt.Errorf("spec.localPort must be a port number or %q: %w", LocalPortAuto, err)
+	}
+	return nil
+}
+
+// MarshalJSON writes `localPort: auto` back out for automatic ports, so
+// saving a config never pins the port that was allocated at runtime.
+func (s SpecConfig) MarshalJSON() ([]byte, error) {
+	type plain SpecConfig
+	if !s.AutoLocalPort {
+		return json.Marshal(plain(s))
+	}
+	return json.Marshal(struct {
+		plain
+		LocalPort string `json:"localPort"`
+	}{plain: plain(s), LocalPort: LocalPortAuto})
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestSpecConfig_LocalPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		wantPort int32
+		wantAuto bool
+		wantErr  bool
+	}{
+		{name: "number", yaml: "localPort: 3000\n", wantPort: 3000},
+		{name: "auto", yaml: "localPort: auto\n", wantAuto: true},
+		{name: "unset", yaml: "imageName: app\n"},
+		{name: "other string", yaml: "localPort: random\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec SpecConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if spec.LocalPort != tt.wantPort || spec.AutoLocalPort != tt.wantAuto {
+				t.Errorf("got port=%d auto=%v, want port=%d auto=%v",
+					spec.LocalPort, spec.AutoLocalPort, tt.wantPort, tt.wantAuto)
+			}
+		})
+	}
+}
+
+func TestSpecConfig_LocalPortAutoRoundTrip(t *testing.T) {
+	// An allocated port must not be written back into the config
+	spec := SpecConfig{ImageName: "app", LocalPort: 21234, AutoLocalPort: true}
+
+	out, err := yaml.Marshal(spec)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if !strings.Contains(string(out), "localPort: auto\n") {
+		t.Errorf("Marshal = %q, want localPort: auto", out)
+	}
+
+	var back SpecConfig
+	if err := yaml.Unmarshal(out, &back); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if !back.AutoLocalPort || back.ImageName != "app" {
+		t.Errorf("round trip lost fields: %+v", back)
+	}
+}
+
+func TestValidate_AutoLocalPort(t *testing.T) {
+	cfg := NewDeploymentConfig("myapp")
+	cfg.Spec.LocalPort = 0
+	cfg.Spec.AutoLocalPort = true
+
+	if err := cfg.Validate(context.Background()); err != nil {
+		t.Errorf("auto local port should pass validation, got %v", err)
+	}
+}
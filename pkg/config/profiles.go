@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Profiles are named overlays of spec fields, selected with --profile:
+//
+//	spec:
+//	  namespace: dev
+//	  replicas: 1
+//	profiles:
+//	  staging:
+//	    namespace: staging
+//	    replicas: 3
+//	    kubeContext: staging-cluster
+//	    env:
+//	      - name: LOG_LEVEL
+//	        value: info
+//
+// The profile is deep-merged over spec before defaults and validation:
+// objects merge key by key, lists of named items (env) merge by name and
+// any other value replaces the base one.
+
+// applyProfile merges the named profile over the spec of a config
+// document. It returns the document unchanged, and false, when the
+// document defines no such profile.
+func applyProfile(doc []byte, profile string) ([]byte, bool, error) {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(doc, &raw); err != nil {
+		return nil, false, err
+	}
+
+	profiles, _ := raw["profiles"].(map[string]interface{})
+	overlay, ok := profiles[profile]
+	if !ok {
+		return doc, false, nil
+	}
+	fields, ok := overlay.(map[string]interface{})
+	if !ok && overlay != nil {
+		return nil, false, fmt.Errorf("profiles.%s must be a map of spec fields", profile)
+	}
+
+	raw["spec"] = mergeValues(raw["spec"], fields)
+	merged, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to apply profile %q: %w", profile, err)
+	}
+	return merged, true, nil
+}
+
+// mergeValues deep-merges overlay over base.
+func mergeValues(base, overlay interface{}) interface{} {
+	switch o := overlay.(type) {
+	case map[string]interface{}:
+		b, ok := base.(map[string]interface{})
+		if !ok {
+			return o
+		}
+		merged := make(map[string]interface{}, len(b)+len(o))
+		for k, v := range b {
+			merged[k] = v
+		}
+		for k, v := range o {
+			merged[k] = mergeValues(b[k], v)
+		}
+		return merged
+	case []interface{}:
+		if b, ok := base.([]interface{}); ok && isNamedList(b) && isNamedList(o) {
+			return mergeNamedLists(b, o)
+		}
+		return o
+	default:
+		return overlay
+	}
+}
+
+// isNamedList reports whether every item of list is an object with a name.
+func isNamedList(list []interface{}) bool {
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["name"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeNamedLists merges overlay items into base items of the same name,
+// keeping base order and appending new names.
+func mergeNamedLists(base, overlay []interface{}) []interface{} {
+	merged := append([]interface{}{}, base...)
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		index[item.(map[string]interface{})["name"].(string)] = i
+	}
+	for _, item := range overlay {
+		name := item.(map[string]interface{})["name"].(string)
+		if i, ok := index[name]; ok {
+			merged[i] = mergeValues(merged[i], item)
+			continue
+		}
+		index[name] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// hasProfile reports whether the profile was applied to any service.
+func (p *ProjectConfig) hasProfile(profile string) bool {
+	for _, svc := range p.Services {
+		if svc.Profile == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// profileNames lists the profiles defined by any service, sorted.
+func (p *ProjectConfig) profileNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, svc := range p.Services {
+		for name := range svc.Profiles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unknownProfileError reports a --profile no service defines.
+func (p *ProjectConfig) unknownProfileError(profile string) error {
+	names := p.profileNames()
+	if len(names) == 0 {
+		return fmt.Errorf("profile %q not found: %s defines no profiles", profile, p.Path)
+	}
+	return fmt.Errorf("profile %q not found in %s (available: %s)", profile, p.Path, strings.Join(names, ", "))
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profileConfig = `apiVersion: kudev.io/v1alpha1
+kind: DeploymentConfig
+metadata:
+  name: test-app
+spec:
+  imageName: test-app
+  dockerfilePath: ./Dockerfile
+  namespace: dev
+  replicas: 1
+  localPort: 8080
+  servicePort: 8080
+  env:
+    - name: LOG_LEVEL
+      value: debug
+    - name: REGION
+      value: eu
+profiles:
+  staging:
+    namespace: staging
+    replicas: 3
+    kubeContext: kind-staging
+    env:
+      - name: LOG_LEVEL
+        value: info
+      - name: FEATURE_X
+        value: "on"
+  broken:
+    replicas: 500
+`
+
+func TestFileConfigLoader_Profile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".kudev.yaml")
+	if err := os.WriteFile(configPath, []byte(profileConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	load := func(profile string) (*DeploymentConfig, error) {
+		loader := NewFileConfigLoader("", "", tmpDir)
+		loader.Profile = profile
+		return loader.LoadFromPath(context.Background(), configPath)
+	}
+
+	t.Run("base spec without profile", func(t *testing.T) {
+		cfg, err := load("")
+		if err != nil {
+			t.Fatalf("LoadFromPath() error = %v", err)
+		}
+		if cfg.Spec.Namespace != "dev" || cfg.Spec.Replicas != 1 || cfg.Profile != "" {
+			t.Errorf("got namespace=%s replicas=%d profile=%q, want base spec", cfg.Spec.Namespace, cfg.Spec.Replicas, cfg.Profile)
+		}
+		if len(cfg.Profiles) != 2 {
+			t.Errorf("Profiles = %d, want 2", len(cfg.Profiles))
+		}
+	})
+
+	t.Run("profile merged over spec", func(t *testing.T) {
+		cfg, err := load("staging")
+		if err != nil {
+			t.Fatalf("LoadFromPath() error = %v", err)
+		}
+		if cfg.Profile != "staging" {
+			t.Errorf("Profile = %q, want staging", cfg.Profile)
+		}
+		if cfg.Spec.Namespace != "staging" || cfg.Spec.Replicas != 3 || cfg.Spec.KubeContext != "kind-staging" {
+			t.Errorf("got namespace=%s replicas=%d kubeContext=%s", cfg.Spec.Namespace, cfg.Spec.Replicas, cfg.Spec.KubeContext)
+		}
+		if cfg.Spec.ImageName != "test-app" || cfg.Spec.LocalPort != 8080 {
+			t.Errorf("fields outside the profile changed: imageName=%s localPort=%d", cfg.Spec.ImageName, cfg.Spec.LocalPort)
+		}
+
+		var env []string
+		for _, e := range cfg.Spec.Env {
+			env = append(env, e.Name+"="+e.Value)
+		}
+		if got := strings.Join(env, ","); got != "LOG_LEVEL=info,REGION=eu,FEATURE_X=on" {
+			t.Errorf("Env = %s, want LOG_LEVEL=info,REGION=eu,FEATURE_X=on", got)
+		}
+	})
+
+	t.Run("merged spec is validated", func(t *testing.T) {
+		if _, err := load("broken"); err == nil || !strings.Contains(err.Error(), "replicas") {
+			t.Errorf("LoadFromPath() error = %v, want replicas validation error", err)
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := load("prod")
+		if err == nil || !strings.Contains(err.Error(), `profile "prod" not found`) || !strings.Contains(err.Error(), "available: broken, staging") {
+			t.Errorf("LoadFromPath() error = %v, want unknown profile listing broken, staging", err)
+		}
+	})
+}
+
+func TestFileConfigLoader_ProfileMultiDocument(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".kudev.yaml")
+	content := serviceDoc("api") + "profiles:\n  staging:\n    replicas: 2\n---\n" + serviceDoc("worker")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewFileConfigLoader("", "", tmpDir)
+	loader.Profile = "staging"
+	project, err := loader.LoadProjectFromPath(context.Background(), configPath)
+	if err != nil {
+		t.Fatalf("LoadProjectFromPath() error = %v", err)
+	}
+
+	api, worker := project.Services[0], project.Services[1]
+	if api.Spec.Replicas != 2 || api.Profile != "staging" {
+		t.Errorf("api: replicas=%d profile=%q, want 2 staging", api.Spec.Replicas, api.Profile)
+	}
+	if worker.Profile != "" {
+		t.Errorf("worker: profile=%q, want none (not defined there)", worker.Profile)
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	base := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{"cpu": "1", "memory": "512Mi"},
+		},
+		"buildContextExclusions": []interface{}{"node_modules"},
+	}
+	overlay := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{"memory": "1Gi"},
+		},
+		"buildContextExclusions": []interface{}{"dist"},
+	}
+
+	merged := mergeValues(base, overlay).(map[string]interface{})
+
+	limits := merged["resources"].(map[string]interface{})["limits"].(map[string]interface{})
+	if limits["cpu"] != "1" || limits["memory"] != "1Gi" {
+		t.Errorf("limits = %v, want cpu=1 memory=1Gi", limits)
+	}
+	if got := merged["buildContextExclusions"].([]interface{}); len(got) != 1 || got[0] != "dist" {
+		t.Errorf("buildContextExclusions = %v, want [dist] (lists are replaced)", got)
+	}
+	if base["resources"].(map[string]interface{})["limits"].(map[string]interface{})["memory"] != "512Mi" {
+		t.Error("mergeValues modified the base")
+	}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ProjectConfig is a config file holding several DeploymentConfig
+// documents separated by '---', one per service of the project.
+//
+// Example:
+//
+//	apiVersion: kudev.io/v1alpha1
+//	kind: DeploymentConfig
+//	metadata:
+//	  name: api
+//	spec: ...
+//	---
+//	apiVersion: kudev.io/v1alpha1
+//	kind: DeploymentConfig
+//	metadata:
+//	  name: worker
+//	spec: ...
+//
+// A file with a single document is a project with one service.
+type ProjectConfig struct {
+	// Path is the file the project was loaded from.
+	Path string
+
+	// Services are the documents in file order.
+	Services []*DeploymentConfig
+}
+
+// Names returns the metadata.name of every service, in file order.
+func (p *ProjectConfig) Names() []string {
+	names := make([]string, 0, len(p.Services))
+	for _, svc := range p.Services {
+		names = append(names, svc.Metadata.Name)
+	}
+	return names
+}
+
+// Service returns the service whose metadata.name is name.
+func (p *ProjectConfig) Service(name string) (*DeploymentConfig, error) {
+	for _, svc := range p.Services {
+		if svc.Metadata.Name == name {
+			return svc, nil
+		}
+	}
+	return nil, fmt.Errorf("service %q not found in %s (available: %s)", name, p.Path, strings.Join(p.Names(), ", "))
+}
+
+// Select returns the named service, or the only one when name is empty.
+func (p *ProjectConfig) Select(name string) (*DeploymentConfig, error) {
+	if name != "" {
+		return p.Service(name)
+	}
+	if len(p.Services) == 1 {
+		return p.Services[0], nil
+	}
+	return nil, fmt.Errorf("%s defines %d services (%s); select one with --service",
+		p.Path, len(p.Services), strings.Join(p.Names(), ", "))
+}
+
+// Targets returns the services named in names, or all of them when names
+// is empty, leaving out those in exclude. Services keep their file order.
+// Unknown names are an error, and so is excluding every service.
+func (p *ProjectConfig) Targets(names, exclude []string) ([]*DeploymentConfig, error) {
+	for _, name := range append(append([]string{}, names...), exclude...) {
+		if _, err := p.Service(name); err != nil {
+			return nil, err
+		}
+	}
+
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var targets []*DeploymentConfig
+	for _, svc := range p.Services {
+		name := svc.Metadata.Name
+		if skip[name] || (len(names) > 0 && !wanted[name]) {
+			continue
+		}
+		targets = append(targets, svc)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no services selected in %s (available: %s)", p.Path, strings.Join(p.Names(), ", "))
+	}
+	return targets, nil
+}
+
+// ServiceRef identifies another service of the same project.
+type ServiceRef struct {
+	Name      string
+	Namespace string
+	Port      int32
+}
+
+// linkSiblings records on every service the other services of the
+// project, for ServiceURLEnv.
+func (p *ProjectConfig) linkSiblings() {
+	for _, svc := range p.Services {
+		svc.Siblings = nil
+		for _, other := range p.Services {
+			if other == svc {
+				continue
+			}
+			svc.Siblings = append(svc.Siblings, ServiceRef{
+				Name:      other.Metadata.Name,
+				Namespace: other.Spec.Namespace,
+				Port:      other.Spec.ServicePort,
+			})
+		}
+	}
+}
+
+// ServiceURLEnv returns a <SERVICE>_URL variable for every sibling
+// service, pointing at its cluster DNS name, e.g.
+// API_URL=http://api.default.svc.cluster.local:8080, so services of a
+// multi-service project find each other. With --instance, siblings are
+// expected to run as the same instance. Variables already set in
+// spec.env are left out: the explicit value wins.
+func (c *DeploymentConfig) ServiceURLEnv() []EnvVar {
+	var env []EnvVar
+	for _, sib := range c.Siblings {
+		name := sib.Name
+		if c.Instance != "" {
+			name += "-" + c.Instance
+		}
+
+		varName := strings.ToUpper(strings.ReplaceAll(sib.Name, "-", "_")) + "_URL"
+		if c.hasEnv(varName) {
+			continue
+		}
+		env = append(env, EnvVar{
+			Name:  varName,
+			Value: fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", name, sib.Namespace, sib.Port),
+		})
+	}
+	return env
+}
+
+// hasEnv reports whether spec.env sets name.
+func (c *DeploymentConfig) hasEnv(name string) bool {
+	for _, e := range c.Spec.Env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// documentSeparator matches a YAML document separator line.
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*(#.*)?$`)
+
+// splitDocuments splits a multi-document YAML file. Documents holding only
+// blank lines and comments are dropped.
+func splitDocuments(content []byte) [][]byte {
+	var docs [][]byte
+	for _, doc := range documentSeparator.Split(string(content), -1) {
+		if isBlankDocument(doc) {
+			continue
+		}
+		docs = append(docs, []byte(doc))
+	}
+	return docs
+}
+
+// isBlankDocument reports whether doc has no YAML content.
+func isBlankDocument(doc string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaDraft is the JSON Schema dialect of GenerateSchema.
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a node of a JSON Schema document (the subset kudev emits).
+type Schema struct {
+	Draft       string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type    string        `json:"type,omitempty"`
+	Enum    []interface{} `json:"enum,omitempty"`
+	Pattern string        `json:"pattern,omitempty"`
+	Minimum *int64        `json:"minimum,omitempty"`
+	Maximum *int64        `json:"maximum,omitempty"`
+	OneOf   []*Schema     `json:"oneOf,omitempty"`
+
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+
+	// AdditionalProperties is false for config structs (so typos are
+	// flagged), true for free-form maps or a *Schema for their values
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+
+	Items *Schema `json:"items,omitempty"`
+}
+
+// durationType is reflected as a duration string.
+var durationType = reflect.TypeOf(Duration{})
+
+// durationPattern matches Go duration strings like "90s" or "1h30m".
+const durationPattern = `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// schemaRequired lists the fields that must be written, by path. Fields
+// that get defaults (see ApplyDefaults) are not required, nor is
+// spec.imageName: spec.image.ref can stand in for it.
+var schemaRequired = map[string][]string{
+	"":                                     {"apiVersion", "kind", "metadata", "spec"},
+	"metadata":                             {"name"},
+	"spec.image":                           {"ref"},
+	"spec.env[]":                           {"name"},
+	"spec.env[].valueFrom.configMapKeyRef": {"name", "key"},
+	"spec.env[].valueFrom.secretKeyRef":    {"name", "key"},
+	"spec.ports[]":                         {"local", "container"},
+	"spec.sync[]":                          {"local", "remote"},
+	"spec.probes.liveness":                 {"httpGet"},
+	"spec.probes.readiness":                {"httpGet"},
+	"spec.probes.liveness.httpGet":         {"path"},
+	"spec.probes.readiness.httpGet":        {"path"},
+}
+
+// schemaRefinements add the constraints Validate enforces, by path.
+var schemaRefinements = map[string]func(s *Schema){
+	"apiVersion": func(s *Schema) { s.Enum = stringEnum(APIVersions()...) },
+	"kind":       func(s *Schema) { s.Enum = stringEnum("DeploymentConfig") },
+	"metadata.name": func(s *Schema) {
+		// A DNS-1123 label, or a template (see resolveNameTemplates)
+		s.Pattern = dnsLabelPattern.String() + `|\{\{`
+		s.Description = "App name, used for the Deployment, Service and labels (DNS-1123 label)"
+	},
+	"spec.imageName": func(s *Schema) { s.Description = "Image name without tag; kudev tags it with the source hash" },
+	"spec.replicas":  func(s *Schema) { s.Minimum = int64Ptr(1) },
+	"spec.localPort": func(s *Schema) {
+		*s = Schema{
+			Description: `Port on this machine to forward to, or "auto" to pick a free one`,
+			OneOf:       []*Schema{portSchema(), {Type: "string", Enum: stringEnum(LocalPortAuto)}},
+		}
+	},
+	"spec.servicePort":                   setPort,
+	"spec.ports[].local":                 setPort,
+	"spec.ports[].container":             setPort,
+	"spec.probes.liveness.httpGet.port":  setPort,
+	"spec.probes.readiness.httpGet.port": setPort,
+	"spec.ports[].protocol": func(s *Schema) {
+		s.Enum = stringEnum("TCP", "UDP", "SCTP", "tcp", "udp", "sctp")
+	},
+	"spec.readiness.strategy": func(s *Schema) {
+		s.Enum = stringEnum(ReadinessRollout, ReadinessEndpoints, ReadinessHTTP, ReadinessCommand)
+	},
+	"spec.watch.buildOutput": func(s *Schema) {
+		s.Enum = stringEnum(BuildOutputQuiet, BuildOutputNormal, BuildOutputVerbose)
+	},
+	"spec.watch.resourceBudget.threshold": func(s *Schema) {
+		s.Minimum = int64Ptr(0)
+		s.Maximum = int64Ptr(100)
+	},
+	"spec.hash.length": func(s *Schema) {
+		s.Minimum = int64Ptr(MinHashLength)
+		s.Maximum = int64Ptr(MaxHashLength)
+	},
+	"spec.hash.algorithm":                func(s *Schema) { s.Enum = stringEnum(HashSHA256, HashSHA512) },
+	"spec.build.backend":                 func(s *Schema) { s.Enum = stringEnum(BuildBackendCLI, BuildBackendAPI) },
+	"spec.sync[].remote":                 func(s *Schema) { s.Pattern = "^/" },
+	"spec.probes.liveness.httpGet.path":  func(s *Schema) { s.Pattern = "^/" },
+	"spec.probes.readiness.httpGet.path": func(s *Schema) { s.Pattern = "^/" },
+	"profiles": func(s *Schema) {
+		s.Description = "Overrides merged over the config with --profile <name>"
+	},
+}
+
+// GenerateSchema returns the JSON Schema of .kudev.yaml, derived from
+// the json tags of DeploymentConfig, for editor completion and
+// validation. It checks structure only; Validate remains authoritative.
+func GenerateSchema() *Schema {
+	s := reflectSchema(reflect.TypeOf(DeploymentConfig{}), "")
+	s.Draft = SchemaDraft
+	s.Title = "kudev DeploymentConfig (" + DefaultAPIVersion + ")"
+	return s
+}
+
+// MarshalSchema returns GenerateSchema as indented JSON.
+func MarshalSchema() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(GenerateSchema()); err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// reflectSchema builds the schema of t at path (dot-separated json
+// names, "[]" for list items).
+func reflectSchema(t reflect.Type, path string) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	s := &Schema{}
+	switch {
+	case t == durationType:
+		s.Type = "string"
+		s.Pattern = durationPattern
+	case t.Kind() == reflect.Struct:
+		s.Type = "object"
+		s.AdditionalProperties = false
+		s.Properties = map[string]*Schema{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := jsonName(f)
+			if name == "" {
+				continue
+			}
+			s.Properties[name] = reflectSchema(f.Type, joinPath(path, name))
+		}
+		s.Required = schemaRequired[path]
+	case t.Kind() == reflect.Map:
+		s.Type = "object"
+		if t.Elem().Kind() == reflect.Interface {
+			s.AdditionalProperties = true
+		} else {
+			s.AdditionalProperties = reflectSchema(t.Elem(), path+"{}")
+		}
+	case t.Kind() == reflect.Slice:
+		s.Type = "array"
+		s.Items = reflectSchema(t.Elem(), path+"[]")
+	case t.Kind() == reflect.String:
+		s.Type = "string"
+	case t.Kind() == reflect.Bool:
+		s.Type = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s.Type = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s.Type = "number"
+	}
+
+	if refine, ok := schemaRefinements[path]; ok {
+		refine(s)
+	}
+	return s
+}
+
+// jsonName returns the json name of a struct field, or "" when the
+// field is not serialized.
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func setPort(s *Schema) {
+	*s = *portSchema()
+}
+
+func portSchema() *Schema {
+	return &Schema{Type: "integer", Minimum: int64Ptr(1), Maximum: int64Ptr(65535)}
+}
+
+func stringEnum(values ...string) []interface{} {
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return enum
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+// checkSchema reports where doc violates s. It covers the keywords
+// GenerateSchema emits, enough to catch drift between the schema and
+// the configs kudev accepts.
+func checkSchema(doc interface{}, s *Schema, path string) []string {
+	if len(s.OneOf) > 0 {
+		for _, alt := range s.OneOf {
+			if len(checkSchema(doc, alt, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{path + ": matches no alternative"}
+	}
+
+	var problems []string
+	if len(s.Enum) > 0 {
+		found := false
+		for _, v := range s.Enum {
+			found = found || v == doc
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: %v not in enum", path, doc))
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return append(problems, path+": not an object")
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", path, name))
+			}
+		}
+		for key, value := range obj {
+			if prop, ok := s.Properties[key]; ok {
+				problems = append(problems, checkSchema(value, prop, path+"."+key)...)
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case *Schema:
+				problems = append(problems, checkSchema(value, extra, path+"."+key)...)
+			case bool:
+				if !extra {
+					problems = append(problems, fmt.Sprintf("%s: unknown field %s", path, key))
+				}
+			}
+		}
+	case "array":
+		list, ok := doc.([]interface{})
+		if !ok {
+			return append(problems, path+": not an array")
+		}
+		for i, item := range list {
+			problems = append(problems, checkSchema(item, s.Items, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		str, ok := doc.(string)
+		if !ok {
+			return append(problems, path+": not a string")
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+			problems = append(problems, fmt.Sprintf("%s: %q does not match %s", path, str, s.Pattern))
+		}
+	case "integer":
+		n, ok := doc.(float64)
+		if !ok || n != float64(int64(n)) {
+			return append(problems, path+": not an integer")
+		}
+		if (s.Minimum != nil && int64(n) < *s.Minimum) || (s.Maximum != nil && int64(n) > *s.Maximum) {
+			problems = append(problems, fmt.Sprintf("%s: %v out of range", path, n))
+		}
+	case "boolean":
+		if _, ok := doc.(bool); !ok {
+			problems = append(problems, path+": not a boolean")
+		}
+	}
+	return problems
+}
+
+// parseYAML decodes a YAML document the way the loader sees it.
+func parseYAML(t *testing.T, content string) interface{} {
+	t.Helper()
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		t.Fatalf("invalid YAML: %v", err)
+	}
+	return doc
+}
+
+func TestGenerateSchema_AcceptsValidConfigs(t *testing.T) {
+	full := NewDeploymentConfig("api")
+	full.Spec.Env = []EnvVar{
+		{Name: "LOG_LEVEL", Value: "debug"},
+		{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{SecretKeyRef: &SecretKeySelector{Name: "db", Key: "password"}}},
+	}
+	full.Spec.Ports = []PortMapping{{Local: 5005, Container: 5005}, {Local: 5353, Container: 53, Protocol: "udp"}}
+	full.Spec.TTL = Duration{Duration: 3600e9}
+	full.Spec.Readiness = &ReadinessConfig{Strategy: ReadinessHTTP, Path: "/healthz"}
+	full.Spec.Sync = []SyncRule{{Local: "src", Remote: "/app/src"}}
+	full.Spec.Watch = &WatchConfig{BuildOutput: BuildOutputQuiet}
+	full.Spec.Hash = &HashConfig{Length: 12, Algorithm: HashSHA512}
+	full.Spec.Probes = &ProbesConfig{Liveness: &ProbeConfig{HTTPGet: HTTPGetConfig{Path: "/healthz"}}}
+	full.Profiles = map[string]map[string]interface{}{"ci": {"spec": map[string]interface{}{"replicas": 2}}}
+	fullYAML, err := yaml.Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "fully populated", content: string(fullYAML)},
+		{name: "minimal", content: `apiVersion: kudev.io/v1alpha2
+kind: DeploymentConfig
+metadata:
+  name: "{{ .Git.Branch }}-api"
+spec:
+  imageName: api
+  localPort: auto
+  ttl: 30m
+`},
+	}
+
+	schema := GenerateSchema()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if problems := checkSchema(parseYAML(t, tt.content), schema, ""); len(problems) > 0 {
+				t.Errorf("valid config rejected:\n%s", strings.Join(problems, "\n"))
+			}
+		})
+	}
+}
+
+func TestGenerateSchema_RejectsInvalidConfigs(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{name: "typo", spec: "  imageName: api\n  replica: 2\n", want: "unknown field replica"},
+		{name: "missing image ref", spec: "  image:\n    pollInterval: 1m\n", want: "missing ref"},
+		{name: "port out of range", spec: "  imageName: api\n  servicePort: 70000\n", want: "out of range"},
+		{name: "bad localPort", spec: "  imageName: api\n  localPort: any\n", want: "matches no alternative"},
+		{name: "bad duration", spec: "  imageName: api\n  ttl: 1 hour\n", want: "does not match"},
+		{name: "v1alpha1 field", spec: "  imageName: api\n  buildContextExclusions: [tmp]\n", want: "unknown field buildContextExclusions"},
+		{name: "bad strategy", spec: "  imageName: api\n  readiness:\n    strategy: tcp\n", want: "not in enum"},
+	}
+
+	schema := GenerateSchema()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "apiVersion: kudev.io/v1alpha2\nkind: DeploymentConfig\nmetadata:\n  name: api\nspec:\n" + tt.spec
+			problems := strings.Join(checkSchema(parseYAML(t, content), schema, ""), "\n")
+			if !strings.Contains(problems, tt.want) {
+				t.Errorf("problems = %q, want one containing %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSchema_PathsExist(t *testing.T) {
+	schema := GenerateSchema()
+
+	// lookup resolves a schemaRequired/schemaRefinements path
+	lookup := func(path string) *Schema {
+		s := schema
+		if path == "" {
+			return s
+		}
+		for _, part := range strings.Split(path, ".") {
+			name, items := strings.CutSuffix(part, "[]")
+			if s = s.Properties[name]; s == nil {
+				return nil
+			}
+			if items {
+				s = s.Items
+			}
+		}
+		return s
+	}
+
+	for path := range schemaRequired {
+		if lookup(path) == nil {
+			t.Errorf("schemaRequired path %q not in the schema", path)
+		}
+	}
+	for path := range schemaRefinements {
+		if lookup(path) == nil {
+			t.Errorf("schemaRefinements path %q not in the schema", path)
+		}
+	}
+}
+
+func TestMarshalSchema(t *testing.T) {
+	data, err := MarshalSchema()
+	if err != nil {
+		t.Fatalf("MarshalSchema() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if decoded["$schema"] != SchemaDraft {
+		t.Errorf("$schema = %v", decoded["$schema"])
+	}
+
+	// Runtime-only fields stay out of the schema
+	for _, field := range []string{"ProjectRoot", "Instance", "Profile", "MigratedFrom"} {
+		if strings.Contains(string(data), `"`+field+`"`) {
+			t.Errorf("schema contains runtime field %s", field)
+		}
+	}
+}
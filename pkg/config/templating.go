@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// NameTemplateData is available to templated name fields.
+//
+// Example:
+//
+//	metadata:
+//	  name: myapp-{{ .GitBranch }}
+//	spec:
+//	  namespace: dev-{{ .User }}
+//
+// All values are sanitized to DNS-1123 label format, so
+// "feature/Login_Page" becomes "feature-login-page".
+type NameTemplateData struct {
+	// GitBranch is the current git branch of the project root. Using it
+	// outside a git repository or on a detached HEAD is an error.
+	GitBranch string
+
+	// User is the current OS user name.
+	User string
+}
+
+// invalidLabelChars matches anything not allowed in a DNS-1123 label.
+var invalidLabelChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// resolveNameTemplates expands Go templates in metadata.name and spec.namespace.
+// Fields without "{{" are left untouched, and git is only invoked when a
+// template uses .GitBranch. Using it without a checked out branch (outside
+// a git repository or on a detached HEAD) is an error rather than an
+// empty or "head" fragment.
+func (c *DeploymentConfig) resolveNameTemplates(ctx context.Context, dir string) error {
+	if !isTemplated(c.Metadata.Name) && !isTemplated(c.Spec.Namespace) {
+		return nil
+	}
+
+	data := NameTemplateData{
+		User: SanitizeLabel(currentUser()),
+	}
+	for _, field := range []struct{ name, value string }{
+		{"metadata.name", c.Metadata.Name},
+		{"spec.namespace", c.Spec.Namespace},
+	} {
+		if !isTemplated(field.value) || !strings.Contains(field.value, ".GitBranch") {
+			continue
+		}
+		branch, err := currentGitBranch(ctx, dir)
+		if err != nil {
+			return fmt.Errorf("%s: template %q uses .GitBranch, but %w", field.name, field.value, err)
+		}
+		data.GitBranch = SanitizeLabel(branch)
+		break
+	}
+
+	name, err := renderNameTemplate("metadata.name", c.Metadata.Name, data)
+	if err != nil {
+		return err
+	}
+	namespace, err := renderNameTemplate("spec.namespace", c.Spec.Namespace, data)
+	if err != nil {
+		return err
+	}
+
+	c.Metadata.Name = name
+	c.Spec.Namespace = namespace
+	return nil
+}
+
+func isTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// renderNameTemplate executes a single templated field.
+// Missing keys are errors so typos like {{ .Branch }} fail loudly.
+func renderNameTemplate(field, value string, data NameTemplateData) (string, error) {
+	if !isTemplated(value) {
+		return value, nil
+	}
+
+	tpl, err := template.New(field).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid template %q: %w", field, value, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s: failed to render template %q: %w", field, value, err)
+	}
+
+	return strings.Trim(buf.String(), "-"), nil
+}
+
+// SanitizeLabel converts an arbitrary string into a DNS-1123 label fragment.
+// Lowercases, replaces invalid characters with hyphens and trims to 63 characters.
+func SanitizeLabel(value string) string {
+	value = strings.ToLower(value)
+	value = invalidLabelChars.ReplaceAllString(value, "-")
+	value = strings.Trim(value, "-")
+	if len(value) > 63 {
+		value = strings.TrimRight(value[:63], "-")
+	}
+	return value
+}
+
+// currentGitBranch returns the checked out branch of the git repository
+// containing dir. It fails outside a git repository and on a detached HEAD.
+func currentGitBranch(ctx context.Context, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git is not available: %w", err)
+		}
+		// --quiet: exit code 1 only means HEAD is not a branch
+		if exitErr.ExitCode() == 1 {
+			return "", fmt.Errorf("HEAD is detached (check out a branch)")
+		}
+		return "", fmt.Errorf("%s is not in a git repository", dir)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeLabel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"main", "main"},
+		{"feature/Login_Page", "feature-login-page"},
+		{"--weird--", "weird"},
+		{"", ""},
+		{"UPPER.case", "upper-case"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := SanitizeLabel(tt.input); got != tt.want {
+				t.Errorf("SanitizeLabel(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNameTemplate(t *testing.T) {
+	data := NameTemplateData{GitBranch: "feature-x", User: "alice"}
+
+	got, err := renderNameTemplate("spec.namespace", "dev-{{ .GitBranch }}", data)
+	if err != nil {
+		t.Fatalf("renderNameTemplate() error = %v", err)
+	}
+	if got != "dev-feature-x" {
+		t.Errorf("got %q, want %q", got, "dev-feature-x")
+	}
+
+	// Plain values pass through untouched
+	got, _ = renderNameTemplate("spec.namespace", "default", data)
+	if got != "default" {
+		t.Errorf("got %q, want %q", got, "default")
+	}
+
+	// Unknown keys must fail
+	if _, err := renderNameTemplate("spec.namespace", "dev-{{ .Branch }}", data); err == nil {
+		t.Error("expected error for unknown template key")
+	}
+}
+
+func TestLoadFromPath_TemplatedNamespace(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".kudev.yaml")
+
+	configContent := `apiVersion: kudev.io/v1alpha1
+kind: DeploymentConfig
+metadata:
+  name: test-app
+spec:
+  imageName: test-app
+  dockerfilePath: ./Dockerfile
+  namespace: dev-{{ .User }}
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	loader := NewFileConfigLoader("", tmpDir, tmpDir)
+	cfg, err := loader.LoadFromPath(context.Background(), configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	want := SanitizeLabel("dev-" + SanitizeLabel(currentUser()))
+	if cfg.Spec.Namespace != want {
+		t.Errorf("Namespace = %q, want %q", cfg.Spec.Namespace, want)
+	}
+}
+
+func TestResolveNameTemplates_GitBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	git := func(t *testing.T, dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	resolve := func(dir, namespace string) (string, error) {
+		cfg := &DeploymentConfig{Metadata: MetadataConfig{Name: "myapp"}, Spec: SpecConfig{Namespace: namespace}}
+		err := cfg.resolveNameTemplates(context.Background(), dir)
+		return cfg.Spec.Namespace, err
+	}
+
+	// Outside a git repository
+	outside := t.TempDir()
+	if _, err := resolve(outside, "dev-{{ .GitBranch }}"); err == nil || !strings.Contains(err.Error(), "not in a git repository") {
+		t.Errorf("expected an error outside a git repository, got %v", err)
+	}
+	// Templates without .GitBranch don't need git
+	if _, err := resolve(outside, "dev-{{ .User }}"); err != nil {
+		t.Errorf("resolve without .GitBranch: %v", err)
+	}
+
+	repo := t.TempDir()
+	git(t, repo, "init", "--quiet", "--initial-branch=feature/Login")
+	got, err := resolve(repo, "dev-{{ .GitBranch }}")
+	if err != nil {
+		t.Fatalf("resolve on a branch: %v", err)
+	}
+	if got != "dev-feature-login" {
+		t.Errorf("namespace = %q, want dev-feature-login", got)
+	}
+
+	git(t, repo, "commit", "--quiet", "--allow-empty", "-m", "initial")
+	git(t, repo, "checkout", "--quiet", "--detach")
+	if _, err := resolve(repo, "dev-{{ .GitBranch }}"); err == nil || !strings.Contains(err.Error(), "detached") {
+		t.Errorf("expected an error on a detached HEAD, got %v", err)
+	}
+}
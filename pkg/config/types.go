@@ -1,5 +1,12 @@
 package config
 
+import (
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
 // DeploymentConfig is the root configuration object.
 // It follows K8s API conventions with apiVersion, kind, metadata, and spec.
 // Example:
@@ -30,6 +37,29 @@ type DeploymentConfig struct {
 	Spec SpecConfig `yaml:"spec" json:"spec"`
 
 	ProjectRoot string `yaml:"-" json:"-"`
+
+	// ConfigPath is the file the config was loaded from, or "" when it
+	// was not loaded from a file.
+	ConfigPath string `yaml:"-" json:"-"`
+
+	// Instance is the optional instance suffix set via --instance.
+	// When set, Metadata.Name already includes the suffix.
+	Instance string `yaml:"-" json:"-"`
+
+	// Siblings are the other services of a multi-service config file
+	// (see ServiceURLEnv).
+	Siblings []ServiceRef `yaml:"-" json:"-"`
+
+	// Profiles are named overlays of spec fields (e.g. "staging"),
+	// selected with --profile. See profiles.go for the merge rules.
+	Profiles map[string]map[string]interface{} `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+
+	// Profile is the profile merged into Spec at load time, if any.
+	Profile string `yaml:"-" json:"-"`
+
+	// MigratedFrom is the older apiVersion the document was written in
+	// and upgraded from at load time (see versions.go), if any.
+	MigratedFrom string `yaml:"-" json:"-"`
 }
 
 // MetadataConfig follows K8s naming conventions.
@@ -148,8 +178,18 @@ type SpecConfig struct {
 	//   - Change port in .kudev.yaml and retry
 	//
 	// Note: Requires elevated permissions (sudo) for ports < 1024
+	//
+	// Set to "auto" to let kudev pick a free port that stays stable for
+	// the project (see AutoLocalPort).
 	LocalPort int32 `yaml:"localPort" json:"localPort"`
 
+	// AutoLocalPort is set when the config says `localPort: auto`.
+	//
+	// The port is allocated on first up/watch, preferring one derived from
+	// the app name, and persisted in .kudev/state.json so it stays the same
+	// across runs. Until then LocalPort is 0.
+	AutoLocalPort bool `yaml:"-" json:"-"`
+
 	// ServicePort is the container port inside the pod.
 	//
 	// This is the port your application listens on inside the container.
@@ -174,6 +214,17 @@ type SpecConfig struct {
 	//   Service:8080 → Pod:servicePort
 	ServicePort int32 `yaml:"servicePort" json:"servicePort"`
 
+	// Ports are additional ports forwarded alongside localPort → servicePort,
+	// e.g. a debugger port (JVM 5005, Delve 2345).
+	//
+	// Example:
+	//   ports:
+	//     - local: 5005
+	//       container: 5005
+	//
+	// All ports share one port-forward connection to the pod.
+	Ports []PortMapping `yaml:"ports,omitempty" json:"ports,omitempty"`
+
 	// Env is a list of environment variables for the container.
 	//
 	// These are injected into the Kubernetes Pod spec.
@@ -188,17 +239,25 @@ type SpecConfig struct {
 	//     - name: DATABASE_URL
 	//       value: "postgres://postgres:5432/mydb"
 	//
+	// Values can also come from an existing ConfigMap or Secret:
+	//   env:
+	//     - name: LOG_LEVEL
+	//       valueFrom:
+	//         configMapKeyRef:
+	//           name: myconfig
+	//           key: log_level
+	//     - name: DB_PASSWORD
+	//       valueFrom:
+	//         secretKeyRef:
+	//           name: db-credentials
+	//           key: password
+	//
 	// Notes:
 	//   - Values are ALWAYS strings (converted from YAML)
-	//   - For secrets: use K8s Secrets (future enhancement)
+	//   - For secrets: use secretKeyRef, never a plain value
 	//   - Order doesn't matter
 	//   - Duplicate names: last one wins (validated)
-	//
-	// ValueFrom (ConfigMaps, Secrets) - NOT YET SUPPORTED
-	// Phase 4 will add support for:
-	//   - ConfigMap references
-	//   - Secret references
-	//   - Field references (pod name, namespace, etc.)
+	//   - Referenced ConfigMaps and Secrets must exist before deploying (checked)
 	Env []EnvVar `yaml:"env" json:"env"`
 
 	// KubeContext is the optional Kubernetes context to use.
@@ -248,7 +307,7 @@ type SpecConfig struct {
 	//   - vendor/ (Go)
 	//
 	// Additional exclusions specified here:
-	//   buildContextExclusions:
+	//   exclude:
 	//     - .env
 	//     - __pycache__
 	//     - .pytest_cache
@@ -260,9 +319,767 @@ type SpecConfig struct {
 	//   - Directory: "vendor/" or "vendor"
 	//   - Glob: "*.log" (NOT YET SUPPORTED - Phase 2)
 	//
-	// Note: .dockerignore is the real mechanism
-	// Kudev generates .dockerignore from this list
-	BuildContextExclusions []string `yaml:"buildContextExclusions" json:"buildContextExclusions,omitempty"`
+	// Before each build, kudev writes this list to a delimited block of
+	// .dockerignore in the build context (bare names as "**/<name>"),
+	// leaving the rest of the file alone. The block is removed again
+	// when the list is emptied.
+	//
+	// Named buildContextExclusions before kudev.io/v1alpha2.
+	BuildContextExclusions []string `yaml:"exclude" json:"exclude,omitempty"`
+
+	// UseGitignore also skips what .gitignore files ignore (nested ones
+	// apply below their directory) when hashing sources and watching
+	// for changes, so build output like bin/ doesn't trigger rebuilds
+	// without being listed in exclude.
+	//
+	// The docker build context is not affected; see exclude.
+	//
+	// Default: false
+	UseGitignore bool `yaml:"useGitignore,omitempty" json:"useGitignore,omitempty"`
+
+	// Hash configures the source hash in image tags (kudev-<hash>) and
+	// the kudev-hash label.
+	//
+	// Example:
+	//   hash:
+	//     length: 12
+	//
+	// Omitted: the first 8 hex characters of a SHA-256 over file paths
+	// and contents
+	Hash *HashConfig `yaml:"hash,omitempty" json:"hash,omitempty"`
+
+	// Watch configures 'kudev watch' behavior.
+	//
+	// Example:
+	//   watch:
+	//     rebuildEvery: 1h
+	//
+	// Omitted: defaults apply (rebuild on file changes only)
+	Watch *WatchConfig `yaml:"watch,omitempty" json:"watch,omitempty"`
+
+	// ZeroDowntime keeps the app reachable while it is redeployed.
+	//
+	// When true:
+	//   - Rolling updates use maxUnavailable=0, maxSurge=1
+	//     (the old pod keeps serving until the new one is Ready)
+	//   - A PodDisruptionBudget with minAvailable=1 is created
+	//
+	// Most useful with 'kudev watch', where redeploys happen constantly.
+	// Default: false
+	ZeroDowntime bool `yaml:"zeroDowntime,omitempty" json:"zeroDowntime,omitempty"`
+
+	// TTL marks the app for removal by the cluster reaper
+	// ('kudev install-reaper') once it has not been deployed for this
+	// long, keeping shared clusters tidy when 'kudev down' is forgotten.
+	//
+	// Example:
+	//   ttl: 72h
+	//
+	// Minimum: MinTTL. Omitted: never reaped
+	TTL Duration `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// ContainerName is the name of the app's container in the pod.
+	//
+	// 'kudev logs' and 'kudev watch' stream this container when the pod
+	// has sidecars (e.g. added by a custom template or a service mesh).
+	// DNS-1123 label, at most 63 characters.
+	// Default: metadata.name
+	ContainerName string `yaml:"containerName,omitempty" json:"containerName,omitempty"`
+
+	// TemplateValues is a free-form map passed to templates as .Values.
+	//
+	// Kudev does not interpret these values; they exist so custom
+	// templates can key off user-defined settings.
+	//
+	// Example:
+	//   templateValues:
+	//     tier: backend
+	//     sidecar:
+	//       enabled: true
+	//
+	// In a template:
+	//   {{ .Values.tier }}
+	//   {{ if .Values.sidecar.enabled }}...{{ end }}
+	TemplateValues map[string]interface{} `yaml:"templateValues,omitempty" json:"templateValues,omitempty"`
+
+	// Templates replaces the built-in Deployment and Service templates
+	// with your own, rendered with the same data (including .Values and
+	// .Config).
+	//
+	// Example:
+	//   templates:
+	//     deployment: ./deploy/deployment.yaml
+	//
+	// Omitted: the built-in templates
+	Templates *TemplatesConfig `yaml:"templates,omitempty" json:"templates,omitempty"`
+
+	// Safety sets guard rails for shared local/dev clusters.
+	//
+	// Example:
+	//   safety:
+	//     maxReplicas: 5
+	//     maxManagedDeployments: 10
+	//
+	// Omitted: replicas are capped at DefaultMaxReplicas, no deployment limit
+	Safety *SafetyConfig `yaml:"safety,omitempty" json:"safety,omitempty"`
+
+	// Artifacts controls the rendered manifests kept per deploy.
+	//
+	// Each deploy writes its manifests to
+	// .kudev/artifacts/<timestamp>-<hash>/, so two rebuilds can be
+	// compared with a plain diff.
+	//
+	// Example:
+	//   artifacts:
+	//     keep: 20
+	//
+	// Omitted: the last DefaultArtifactsKeep deploys are kept
+	Artifacts *ArtifactsConfig `yaml:"artifacts,omitempty" json:"artifacts,omitempty"`
+
+	// Build configures the image build.
+	//
+	// Example (monorepo where only services/api belongs to this app):
+	//   build:
+	//     context: ./services/api
+	//
+	// Omitted: the project root is the build context
+	Build *BuildConfig `yaml:"build,omitempty" json:"build,omitempty"`
+
+	// Image deploys an image built elsewhere (e.g. by the backend team's
+	// CI) instead of building one: no Dockerfile, hashing or build.
+	//
+	// Example:
+	//   image:
+	//     ref: ghcr.io/org/api:main
+	//
+	// Omitted: kudev builds spec.imageName from the Dockerfile
+	Image *ImageConfig `yaml:"image,omitempty" json:"image,omitempty"`
+
+	// Readiness decides when a deploy counts as ready, for 'kudev up'
+	// and the 'kudev watch' rebuild banner.
+	//
+	// Example (ready once /healthz answers through the Service):
+	//   readiness:
+	//     strategy: http
+	//     path: /healthz
+	//
+	// Omitted: ready once the Deployment rollout has all replicas ready
+	Readiness *ReadinessConfig `yaml:"readiness,omitempty" json:"readiness,omitempty"`
+
+	// Sync copies changed files straight into the running pods during
+	// 'kudev watch' instead of rebuilding the image.
+	//
+	// When every file in a change batch falls under a sync rule, the
+	// files are copied (or deleted) in each pod of the app and no image
+	// is built. Any other change triggers the usual rebuild. Meant for
+	// interpreted languages (Node, Python) whose server reloads itself.
+	// The container image needs tar.
+	//
+	// Example:
+	//   sync:
+	//     - local: src
+	//       remote: /app/src
+	//
+	// Omitted: every change rebuilds the image
+	Sync []SyncRule `yaml:"sync,omitempty" json:"sync,omitempty"`
+
+	// Resources sets the container's CPU and memory requests and limits.
+	//
+	// Values are Kubernetes quantities ("250m", "1", "256Mi", "1Gi").
+	// Keep them modest on local clusters: every replica reserves its
+	// requests on the node.
+	//
+	// Example:
+	//   resources:
+	//     requests:
+	//       cpu: 250m
+	//       memory: 256Mi
+	//     limits:
+	//       memory: 1Gi
+	//
+	// Omitted (or any value left out): DefaultCPURequest,
+	// DefaultMemoryRequest, DefaultCPULimit and DefaultMemoryLimit
+	Resources *ResourcesConfig `yaml:"resources,omitempty" json:"resources,omitempty"`
+
+	// Probes adds liveness and readiness probes to the container.
+	//
+	// With a readiness probe, a pod only counts as ready (for 'kudev up'
+	// and the watch banner) once the app answers, not merely once the
+	// container started. A liveness probe restarts a hung container.
+	//
+	// Example:
+	//   probes:
+	//     readiness:
+	//       httpGet:
+	//         path: /healthz
+	//       periodSeconds: 5
+	//     liveness:
+	//       httpGet:
+	//         path: /healthz
+	//       initialDelaySeconds: 10
+	//
+	// Omitted: no probes; pods are ready as soon as the container runs
+	Probes *ProbesConfig `yaml:"probes,omitempty" json:"probes,omitempty"`
+
+	// PortForward configures the local end of port forwarding.
+	//
+	// Example (reach the app from a phone on the same network):
+	//   portForward:
+	//     address: 0.0.0.0
+	//
+	// Omitted: forwards listen on localhost (127.0.0.1 and ::1)
+	PortForward *PortForwardConfig `yaml:"portForward,omitempty" json:"portForward,omitempty"`
+}
+
+// PortMapping forwards a local port to a container port.
+type PortMapping struct {
+	// Local is the port on this machine (1-65535).
+	Local int32 `yaml:"local" json:"local"`
+
+	// Container is the port inside the pod (1-65535).
+	Container int32 `yaml:"container" json:"container"`
+
+	// Protocol is TCP, UDP or SCTP. Kubernetes port-forward only carries
+	// TCP, so UDP and SCTP ports are exposed through a temporary NodePort
+	// Service instead, reached at a node address rather than localhost.
+	// Default: TCP
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+}
+
+// EffectiveProtocol returns the upper-cased protocol, applying the default.
+func (m PortMapping) EffectiveProtocol() string {
+	if m.Protocol == "" {
+		return "TCP"
+	}
+	return strings.ToUpper(m.Protocol)
+}
+
+// same reports whether m and o forward the same ports.
+func (m PortMapping) same(o PortMapping) bool {
+	return m.Local == o.Local && m.Container == o.Container && m.EffectiveProtocol() == o.EffectiveProtocol()
+}
+
+// PortMappings returns every forwarded port: localPort → servicePort
+// first, then spec.ports (a repeat of the first mapping is dropped).
+func (c *DeploymentConfig) PortMappings() []PortMapping {
+	primary := PortMapping{Local: c.Spec.LocalPort, Container: c.Spec.ServicePort}
+	mappings := []PortMapping{primary}
+	for _, m := range c.Spec.Ports {
+		if !m.same(primary) {
+			mappings = append(mappings, m)
+		}
+	}
+	return mappings
+}
+
+// DefaultPortForwardAddress is the local address forwards listen on when
+// spec.portForward.address is not set.
+const DefaultPortForwardAddress = "localhost"
+
+// PortForwardConfig configures the local end of port forwarding.
+type PortForwardConfig struct {
+	// Address is the local address to listen on: "localhost", or an IP
+	// such as 127.0.0.1, ::1 (IPv6 loopback) or 0.0.0.0 (all interfaces,
+	// reachable from other devices on the network).
+	Address string `yaml:"address,omitempty" json:"address,omitempty"`
+
+	// Hosts registers <metadata.name>.kudev.local in the system hosts
+	// file, pointing at Address, so apps can be reached by hostnames that
+	// mirror cluster DNS. Editing the hosts file may prompt for sudo.
+	Hosts bool `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+}
+
+// EffectiveAddress returns the listen address, applying the default.
+func (p *PortForwardConfig) EffectiveAddress() string {
+	if p == nil || p.Address == "" {
+		return DefaultPortForwardAddress
+	}
+	return p.Address
+}
+
+// HostsEnabled reports whether the app's hostname is registered.
+func (p *PortForwardConfig) HostsEnabled() bool {
+	return p != nil && p.Hosts
+}
+
+// ProbesConfig holds the container probes. Either may be omitted.
+type ProbesConfig struct {
+	Liveness  *ProbeConfig `yaml:"liveness,omitempty" json:"liveness,omitempty"`
+	Readiness *ProbeConfig `yaml:"readiness,omitempty" json:"readiness,omitempty"`
+}
+
+// ProbeConfig is an HTTP GET probe.
+type ProbeConfig struct {
+	HTTPGet HTTPGetConfig `yaml:"httpGet" json:"httpGet"`
+
+	// InitialDelaySeconds waits before the first probe.
+	// Zero uses the Kubernetes default (0).
+	InitialDelaySeconds int32 `yaml:"initialDelaySeconds,omitempty" json:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds is the probe interval.
+	// Zero uses the Kubernetes default (10).
+	PeriodSeconds int32 `yaml:"periodSeconds,omitempty" json:"periodSeconds,omitempty"`
+}
+
+// HTTPGetConfig is the request a probe sends.
+type HTTPGetConfig struct {
+	// Path must start with '/'.
+	Path string `yaml:"path" json:"path"`
+
+	// Port is the container port. Zero means spec.servicePort.
+	Port int32 `yaml:"port,omitempty" json:"port,omitempty"`
+}
+
+// ResourcesConfig holds container resource requests and limits.
+type ResourcesConfig struct {
+	Requests ResourceValues `yaml:"requests,omitempty" json:"requests,omitempty"`
+
+	// Limits left unset default to the request when it is higher than
+	// the default limit, so raising a request alone stays valid.
+	Limits ResourceValues `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// ResourceValues is a CPU and memory quantity pair.
+type ResourceValues struct {
+	CPU    string `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	Memory string `yaml:"memory,omitempty" json:"memory,omitempty"`
+}
+
+// EffectiveRequests returns the requests, applying the defaults.
+func (r *ResourcesConfig) EffectiveRequests() ResourceValues {
+	values := ResourceValues{CPU: DefaultCPURequest, Memory: DefaultMemoryRequest}
+	if r == nil {
+		return values
+	}
+	if r.Requests.CPU != "" {
+		values.CPU = r.Requests.CPU
+	}
+	if r.Requests.Memory != "" {
+		values.Memory = r.Requests.Memory
+	}
+	return values
+}
+
+// EffectiveLimits returns the limits, applying the defaults.
+func (r *ResourcesConfig) EffectiveLimits() ResourceValues {
+	requests := r.EffectiveRequests()
+	values := ResourceValues{
+		CPU:    atLeast(DefaultCPULimit, requests.CPU),
+		Memory: atLeast(DefaultMemoryLimit, requests.Memory),
+	}
+	if r == nil {
+		return values
+	}
+	if r.Limits.CPU != "" {
+		values.CPU = r.Limits.CPU
+	}
+	if r.Limits.Memory != "" {
+		values.Memory = r.Limits.Memory
+	}
+	return values
+}
+
+// atLeast returns request if it is a larger quantity than def, else def.
+func atLeast(def, request string) string {
+	q, err := resource.ParseQuantity(request)
+	if err != nil || q.Cmp(resource.MustParse(def)) <= 0 {
+		return def
+	}
+	return request
+}
+
+// SyncRule maps a local directory (or file) to a path in the container.
+type SyncRule struct {
+	// Local is relative to the build context (see spec.build.context).
+	Local string `yaml:"local" json:"local"`
+
+	// Remote is the absolute container path Local is copied to.
+	Remote string `yaml:"remote" json:"remote"`
+}
+
+// Readiness strategies.
+const (
+	// ReadinessRollout waits for all Deployment replicas to be ready.
+	ReadinessRollout = "rollout"
+
+	// ReadinessEndpoints waits for the Service to have a ready endpoint.
+	ReadinessEndpoints = "endpoints"
+
+	// ReadinessHTTP waits for an HTTP GET through the Service to succeed.
+	ReadinessHTTP = "http"
+
+	// ReadinessCommand waits for a local command to exit 0.
+	ReadinessCommand = "command"
+)
+
+// ReadinessConfig configures how readiness is determined.
+type ReadinessConfig struct {
+	// Strategy is one of rollout, endpoints, http or command.
+	// Default: rollout
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// Path is requested by the http strategy (via the API server's
+	// Service proxy). Default: /
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// Command is run from the project root by the command strategy,
+	// with KUDEV_APP and KUDEV_NAMESPACE set. Not run through a shell.
+	//
+	// Example:
+	//   command: ["./scripts/smoke-test.sh"]
+	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
+
+	// Timeout bounds the wait.
+	// Zero means 5m for 'kudev up' and DefaultReadinessWatchTimeout for
+	// the 'kudev watch' banner.
+	Timeout Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// EffectiveStrategy returns the strategy, applying the default.
+func (r *ReadinessConfig) EffectiveStrategy() string {
+	if r == nil || r.Strategy == "" {
+		return ReadinessRollout
+	}
+	return r.Strategy
+}
+
+// EffectivePath returns the http probe path, applying the default.
+func (r *ReadinessConfig) EffectivePath() string {
+	if r == nil || r.Path == "" {
+		return "/"
+	}
+	return r.Path
+}
+
+// TemplatesConfig points at custom manifest templates.
+type TemplatesConfig struct {
+	// Deployment is the Deployment template, relative to the project root.
+	// Default: the built-in template
+	Deployment string `yaml:"deployment,omitempty" json:"deployment,omitempty"`
+
+	// Service is the Service template, relative to the project root.
+	// Default: the built-in template
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+}
+
+// BuildConfig configures the image build.
+type BuildConfig struct {
+	// Context is the docker build context, relative to the project root.
+	//
+	// Source hashing and 'kudev watch' are scoped to this directory, so
+	// changes elsewhere in a monorepo don't trigger rebuilds.
+	// spec.dockerfilePath stays relative to the project root and
+	// spec.exclude become relative to the context.
+	//
+	// Default: the project root
+	Context string `yaml:"context,omitempty" json:"context,omitempty"`
+
+	// Buildx builds with 'docker buildx build' (BuildKit) instead of
+	// 'docker build'. The image is always loaded into the local docker
+	// image store (--load), like a plain build.
+	//
+	// Example (arm64 laptop, amd64 cluster, cache in a registry):
+	//   build:
+	//     buildx: true
+	//     platform: linux/amd64
+	//     cacheFrom: ["type=registry,ref=registry.local/myapp:cache"]
+	//     cacheTo: ["type=registry,ref=registry.local/myapp:cache,mode=max"]
+	//
+	// Default: false
+	Buildx bool `yaml:"buildx,omitempty" json:"buildx,omitempty"`
+
+	// Platform is the target platform (e.g. linux/amd64). Setting it
+	// builds with buildx, so an arm64 laptop can cross-build for an amd64
+	// cluster (or the reverse) instead of shipping an image that dies with
+	// "exec format error".
+	// Only one platform: the result must load into the local image store.
+	//
+	// Default: the docker daemon's platform; up and watch warn when it
+	// differs from the cluster nodes'
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+
+	// CacheFrom and CacheTo are passed as --cache-from and --cache-to,
+	// in buildx syntax (type=registry,ref=..., type=local,src=...).
+	// Require buildx or a bakeFile.
+	CacheFrom []string `yaml:"cacheFrom,omitempty" json:"cacheFrom,omitempty"`
+	CacheTo   []string `yaml:"cacheTo,omitempty" json:"cacheTo,omitempty"`
+
+	// BakeFile delegates the build to 'docker buildx bake' with this file,
+	// relative to the project root, for builds a single Dockerfile can't
+	// describe (extra contexts, shared base targets, ...).
+	//
+	// kudev still computes the content-hash tag: it is set on BakeTarget
+	// with --set, together with platform, cacheFrom and cacheTo, and the
+	// result is loaded into the local image store. Contexts in the bake
+	// file are relative to the build context. spec.dockerfilePath is not
+	// used; the bake file names the Dockerfile.
+	//
+	// Example:
+	//   build:
+	//     bakeFile: docker-bake.hcl
+	//     bakeTarget: api
+	BakeFile string `yaml:"bakeFile,omitempty" json:"bakeFile,omitempty"`
+
+	// BakeTarget is the bake target producing the app image.
+	// It must be a single target, not a group.
+	//
+	// Default: "default"
+	BakeTarget string `yaml:"bakeTarget,omitempty" json:"bakeTarget,omitempty"`
+
+	// NoCache builds without the layer cache (--no-cache), every build.
+	// 'kudev up --no-cache' does the same for one run.
+	//
+	// Default: false
+	NoCache bool `yaml:"noCache,omitempty" json:"noCache,omitempty"`
+
+	// Pull always pulls the base images (--pull), so a stale local copy
+	// of e.g. node:20 is never built on. 'kudev up --pull' does the same
+	// for one run.
+	//
+	// Default: false
+	Pull bool `yaml:"pull,omitempty" json:"pull,omitempty"`
+
+	// Backend selects how kudev talks to docker: "cli" runs the docker
+	// CLI, "api" calls the Docker Engine API through the Docker SDK, so
+	// building, listing, pruning and loading images into kind and
+	// Rancher Desktop clusters do not need the docker CLI.
+	//
+	// The api backend reaches the daemon of DOCKER_HOST (with
+	// DOCKER_TLS_VERIFY and DOCKER_CERT_PATH), else of the active docker
+	// context (DOCKER_CONTEXT or 'docker context use') with its TLS
+	// files, else /var/run/docker.sock; ssh hosts need the cli backend.
+	// It builds with BuildKit. buildx, platform, cacheFrom, cacheTo and
+	// bakeFile need the cli backend.
+	//
+	// Default: "cli"
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+}
+
+// ImageConfig selects a pre-built image.
+type ImageConfig struct {
+	// Ref is the image to deploy, e.g. ghcr.io/org/api:main. The
+	// cluster pulls it; when docker can resolve its registry digest it is
+	// deployed by digest, so a moved tag is picked up on the next deploy.
+	// spec.imageName defaults to its last path element ("api").
+	Ref string `yaml:"ref" json:"ref"`
+
+	// PollInterval is how often 'kudev watch' checks the registry for a
+	// new digest of Ref, redeploying when the tag moved.
+	//
+	// Default: 30s
+	PollInterval Duration `yaml:"pollInterval,omitempty" json:"pollInterval,omitempty"`
+}
+
+// HashConfig configures the source hash.
+type HashConfig struct {
+	// Length is the number of hex characters kept. Longer hashes make
+	// two branches with different sources less likely to share a tag.
+	//
+	// Default: 8 (at most 63, the length of a Kubernetes label value)
+	Length int `yaml:"length,omitempty" json:"length,omitempty"`
+
+	// Algorithm is the hash function: "sha256" or "sha512".
+	//
+	// Default: "sha256"
+	Algorithm string `yaml:"algorithm,omitempty" json:"algorithm,omitempty"`
+
+	// IncludeModes also hashes file permission bits, so e.g. making a
+	// script executable changes the hash.
+	//
+	// Default: false
+	IncludeModes bool `yaml:"includeModes,omitempty" json:"includeModes,omitempty"`
+}
+
+// ArtifactsConfig configures the per-deploy manifest archive.
+type ArtifactsConfig struct {
+	// Keep is the number of deploy cycles retained.
+	// Zero means DefaultArtifactsKeep.
+	Keep int `yaml:"keep,omitempty" json:"keep,omitempty"`
+
+	// Disabled turns off writing artifacts.
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// Enabled reports whether artifacts should be written.
+func (a *ArtifactsConfig) Enabled() bool {
+	return a == nil || !a.Disabled
+}
+
+// EffectiveKeep returns the retention limit, applying the default.
+func (a *ArtifactsConfig) EffectiveKeep() int {
+	if a == nil || a.Keep <= 0 {
+		return DefaultArtifactsKeep
+	}
+	return a.Keep
+}
+
+// SafetyConfig limits what a single config may deploy.
+type SafetyConfig struct {
+	// MaxReplicas is the highest allowed spec.replicas.
+	// Catches typos like "replicas: 100" before they hit the cluster.
+	// Zero means DefaultMaxReplicas.
+	MaxReplicas int32 `yaml:"maxReplicas,omitempty" json:"maxReplicas,omitempty"`
+
+	// MaxManagedDeployments caps the number of kudev-managed Deployments
+	// in the target namespace (checked before each deploy).
+	// Zero means no limit.
+	MaxManagedDeployments int `yaml:"maxManagedDeployments,omitempty" json:"maxManagedDeployments,omitempty"`
+}
+
+// EffectiveContainerName returns the app container's name, applying the
+// default.
+func (c *DeploymentConfig) EffectiveContainerName() string {
+	if c.Spec.ContainerName != "" {
+		return c.Spec.ContainerName
+	}
+	return c.Metadata.Name
+}
+
+// EffectiveMaxReplicas returns the replica cap, applying the default.
+func (s *SafetyConfig) EffectiveMaxReplicas() int32 {
+	if s == nil || s.MaxReplicas <= 0 {
+		return DefaultMaxReplicas
+	}
+	return s.MaxReplicas
+}
+
+// WatchConfig configures the watch loop.
+type WatchConfig struct {
+	// RebuildEvery forces a periodic rebuild even without file changes.
+	//
+	// Useful when the image bakes data from external APIs at build time.
+	// Minimum: 10s. Zero or omitted disables scheduled rebuilds.
+	RebuildEvery Duration `yaml:"rebuildEvery,omitempty" json:"rebuildEvery,omitempty"`
+
+	// BlueGreen deploys each rebuild to an alternate Deployment
+	// (<name>-blue / <name>-green) and flips the Service selector once
+	// the new one is Ready. Experimental.
+	//
+	// Can also be enabled with 'kudev watch --blue-green'.
+	BlueGreen bool `yaml:"blueGreen,omitempty" json:"blueGreen,omitempty"`
+
+	// CrashLoop tunes the crash-loop check run after each redeploy.
+	//
+	// Example:
+	//   watch:
+	//     crashLoop:
+	//       restarts: 3
+	//       window: 2m
+	//       rollback: true
+	//
+	// Omitted: DefaultCrashLoopRestarts within DefaultCrashLoopWindow,
+	// no rollback
+	CrashLoop *CrashLoopConfig `yaml:"crashLoop,omitempty" json:"crashLoop,omitempty"`
+
+	// BuildOutput sets how much docker build output up and watch show:
+	// BuildOutputQuiet (default) prints each finished build step with
+	// its duration or "cached", and the full output only when the build
+	// fails, BuildOutputNormal prints every line and BuildOutputVerbose
+	// also expands each step's own output (--progress=plain, or
+	// --verbose).
+	BuildOutput string `yaml:"buildOutput,omitempty" json:"buildOutput,omitempty"`
+
+	// ResourceBudget tunes the periodic check that warns when the
+	// cluster's CPU or memory is nearly all requested, or kudev pods
+	// are Pending for lack of it.
+	//
+	// Example:
+	//   watch:
+	//     resourceBudget:
+	//       interval: 30s
+	//       threshold: 90
+	//
+	// Omitted: every DefaultBudgetInterval at DefaultBudgetThreshold
+	// percent
+	ResourceBudget *ResourceBudgetConfig `yaml:"resourceBudget,omitempty" json:"resourceBudget,omitempty"`
+}
+
+// Build output modes (see WatchConfig.BuildOutput).
+const (
+	BuildOutputQuiet   = "quiet"
+	BuildOutputNormal  = "normal"
+	BuildOutputVerbose = "verbose"
+)
+
+// EffectiveBuildOutput returns BuildOutput, or BuildOutputQuiet if unset.
+func (w *WatchConfig) EffectiveBuildOutput() string {
+	if w == nil || w.BuildOutput == "" {
+		return BuildOutputQuiet
+	}
+	return w.BuildOutput
+}
+
+// CrashLoopConfig configures crash-loop detection in 'kudev watch'.
+type CrashLoopConfig struct {
+	// Restarts within Window after a redeploy that count as a crash loop.
+	// Zero means DefaultCrashLoopRestarts.
+	Restarts int32 `yaml:"restarts,omitempty" json:"restarts,omitempty"`
+
+	// Window is how long new pods are observed after a redeploy.
+	// Zero means DefaultCrashLoopWindow.
+	Window Duration `yaml:"window,omitempty" json:"window,omitempty"`
+
+	// Rollback redeploys the previous image when a crash loop is detected.
+	Rollback bool `yaml:"rollback,omitempty" json:"rollback,omitempty"`
+}
+
+// EffectiveRestarts returns the restart threshold, applying the default.
+func (c *CrashLoopConfig) EffectiveRestarts() int32 {
+	if c == nil || c.Restarts <= 0 {
+		return DefaultCrashLoopRestarts
+	}
+	return c.Restarts
+}
+
+// EffectiveWindow returns the observation window, applying the default.
+func (c *CrashLoopConfig) EffectiveWindow() time.Duration {
+	if c == nil || c.Window.Duration <= 0 {
+		return DefaultCrashLoopWindow
+	}
+	return c.Window.Duration
+}
+
+// RollbackEnabled reports whether crash loops trigger a rollback.
+func (c *CrashLoopConfig) RollbackEnabled() bool {
+	return c != nil && c.Rollback
+}
+
+// ResourceBudgetConfig configures the cluster capacity check of
+// 'kudev watch'.
+type ResourceBudgetConfig struct {
+	// Disabled turns the check off.
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+
+	// Interval is the time between checks. Zero means
+	// DefaultBudgetInterval.
+	Interval Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+
+	// Threshold is the percentage of the nodes' allocatable CPU or
+	// memory that, once requested, triggers a warning. Zero means
+	// DefaultBudgetThreshold.
+	Threshold int32 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+}
+
+// Enabled reports whether watch checks the cluster's capacity.
+func (c *ResourceBudgetConfig) Enabled() bool {
+	return c == nil || !c.Disabled
+}
+
+// EffectiveInterval returns the check interval, applying the default.
+func (c *ResourceBudgetConfig) EffectiveInterval() time.Duration {
+	if c == nil || c.Interval.Duration <= 0 {
+		return DefaultBudgetInterval
+	}
+	return c.Interval.Duration
+}
+
+// EffectiveThreshold returns the warning threshold in percent, applying
+// the default.
+func (c *ResourceBudgetConfig) EffectiveThreshold() int32 {
+	if c == nil || c.Threshold <= 0 {
+		return DefaultBudgetThreshold
+	}
+	return c.Threshold
 }
 
 // EnvVar represents a single environment variable.
@@ -314,20 +1131,63 @@ type EnvVar struct {
 	//     - name: URL
 	//       value: http://localhost:8080  # ← can be unquoted
 	//
-	// Future enhancement (Phase 4):
-	//   Will support valueFrom:
-	//     valueFrom:
-	//       configMapKeyRef:
-	//         name: myconfig
-	//         key: log_level
+	// Mutually exclusive with ValueFrom.
 	Value string `yaml:"value" json:"value,omitempty"`
+
+	// ValueFrom reads the value from another source instead of Value.
+	//
+	// Example:
+	//   valueFrom:
+	//     secretKeyRef:
+	//       name: db-credentials
+	//       key: password
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty" json:"valueFrom,omitempty"`
+}
+
+// EnvVarSource is the source of an environment variable's value.
+// Follows K8s v1.EnvVarSource; exactly one source must be set.
+type EnvVarSource struct {
+	// ConfigMapKeyRef selects a key of an existing ConfigMap in the
+	// deployment namespace.
+	ConfigMapKeyRef *ConfigMapKeySelector `yaml:"configMapKeyRef,omitempty" json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects a key of an existing Secret in the deployment
+	// namespace, so passwords and API keys stay out of .kudev.yaml.
+	// Check them with 'kudev secrets check'.
+	SecretKeyRef *SecretKeySelector `yaml:"secretKeyRef,omitempty" json:"secretKeyRef,omitempty"`
+}
+
+// SecretKeySelector selects a key of a Secret.
+type SecretKeySelector struct {
+	// Name is the Secret name (DNS-1123 subdomain).
+	Name string `yaml:"name" json:"name"`
+
+	// Key is the key within the Secret's data.
+	Key string `yaml:"key" json:"key"`
+
+	// Optional lets the pod start when the Secret or key is missing
+	// (the variable is then unset).
+	Optional bool `yaml:"optional,omitempty" json:"optional,omitempty"`
+}
+
+// ConfigMapKeySelector selects a key of a ConfigMap.
+type ConfigMapKeySelector struct {
+	// Name is the ConfigMap name.
+	Name string `yaml:"name" json:"name"`
+
+	// Key is the key within the ConfigMap's data.
+	Key string `yaml:"key" json:"key"`
+
+	// Optional lets the pod start when the ConfigMap or key is missing
+	// (the variable is then unset).
+	Optional bool `yaml:"optional,omitempty" json:"optional,omitempty"`
 }
 
 // NewDeploymentConfig returns a configuration with K8s API defaults.
 // Used primarily for testing and initialization.
 func NewDeploymentConfig(appName string) *DeploymentConfig {
 	return &DeploymentConfig{
-		APIVersion: "kudev.io/v1alpha1",
+		APIVersion: DefaultAPIVersion,
 		Kind:       "DeploymentConfig",
 		Metadata: MetadataConfig{
 			Name: appName,
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/yaml"
 )
@@ -39,7 +40,7 @@ func assertEqual[T comparable](t *testing.T, got, want T, field string) {
 
 func TestCreateDeploymentConfig(t *testing.T) {
 	cfg := NewDeploymentConfig("test-app")
-	assertEqual(t, cfg.APIVersion, "kudev.io/v1alpha1", "apiVersion")
+	assertEqual(t, cfg.APIVersion, "kudev.io/v1alpha2", "apiVersion")
 	assertEqual(t, cfg.Kind, "DeploymentConfig", "kind")
 	assertEqual(t, cfg.Metadata.Name, "test-app", "metadata.name")
 	assertEqual(t, cfg.Spec.ImageName, "test-app", "spec.imageName")
@@ -55,3 +56,152 @@ func TestCreateDeploymentConfigWithCustomEnv(t *testing.T) {
 	assertEqual(t, cfg.Spec.Env[0].Name, "CUSTOM_ENV", "spec.env[0].name")
 	assertEqual(t, cfg.Spec.Env[0].Value, "custom-value", "spec.env[0].value")
 }
+
+func TestArtifactsConfig(t *testing.T) {
+	var unset *ArtifactsConfig
+	if !unset.Enabled() || unset.EffectiveKeep() != DefaultArtifactsKeep {
+		t.Errorf("nil config: Enabled=%v Keep=%d", unset.Enabled(), unset.EffectiveKeep())
+	}
+
+	custom := &ArtifactsConfig{Keep: 3}
+	if got := custom.EffectiveKeep(); got != 3 {
+		t.Errorf("EffectiveKeep() = %d, want 3", got)
+	}
+
+	disabled := &ArtifactsConfig{Disabled: true}
+	if disabled.Enabled() {
+		t.Error("expected artifacts to be disabled")
+	}
+}
+
+func TestEffectiveContainerName(t *testing.T) {
+	cfg := NewDeploymentConfig("myapp")
+	if got := cfg.EffectiveContainerName(); got != "myapp" {
+		t.Errorf("EffectiveContainerName() = %q, want %q", got, "myapp")
+	}
+
+	cfg.Spec.ContainerName = "web"
+	if got := cfg.EffectiveContainerName(); got != "web" {
+		t.Errorf("EffectiveContainerName() = %q, want %q", got, "web")
+	}
+}
+
+func TestPortMappings(t *testing.T) {
+	cfg := NewDeploymentConfig("myapp")
+	cfg.Spec.LocalPort, cfg.Spec.ServicePort = 8080, 8080
+	cfg.Spec.Ports = []PortMapping{{Local: 8080, Container: 8080}, {Local: 5005, Container: 5005}}
+
+	got := cfg.PortMappings()
+	want := []PortMapping{{Local: 8080, Container: 8080}, {Local: 5005, Container: 5005}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("PortMappings() = %v, want %v", got, want)
+	}
+}
+
+func TestPortForwardConfig(t *testing.T) {
+	var unset *PortForwardConfig
+	if got := unset.EffectiveAddress(); got != DefaultPortForwardAddress {
+		t.Errorf("EffectiveAddress() = %q, want %q", got, DefaultPortForwardAddress)
+	}
+
+	lan := &PortForwardConfig{Address: "0.0.0.0"}
+	if got := lan.EffectiveAddress(); got != "0.0.0.0" {
+		t.Errorf("EffectiveAddress() = %q, want %q", got, "0.0.0.0")
+	}
+
+	if unset.HostsEnabled() || lan.HostsEnabled() {
+		t.Error("HostsEnabled() = true without hosts")
+	}
+	if !(&PortForwardConfig{Hosts: true}).HostsEnabled() {
+		t.Error("HostsEnabled() = false with hosts")
+	}
+}
+
+func TestWatchConfig_BuildOutput(t *testing.T) {
+	var unset *WatchConfig
+	if got := unset.EffectiveBuildOutput(); got != BuildOutputQuiet {
+		t.Errorf("EffectiveBuildOutput() = %q, want %q", got, BuildOutputQuiet)
+	}
+	if got := (&WatchConfig{BuildOutput: BuildOutputVerbose}).EffectiveBuildOutput(); got != BuildOutputVerbose {
+		t.Errorf("EffectiveBuildOutput() = %q, want %q", got, BuildOutputVerbose)
+	}
+
+	if errs := validateWatch(&WatchConfig{BuildOutput: "loud"}); !errs.HasErrors() {
+		t.Error("validateWatch() accepted buildOutput \"loud\"")
+	}
+	if errs := validateWatch(&WatchConfig{BuildOutput: BuildOutputNormal}); errs.HasErrors() {
+		t.Errorf("validateWatch() errors = %v", errs.Errors)
+	}
+}
+
+func TestResourceBudgetConfig(t *testing.T) {
+	var unset *ResourceBudgetConfig
+	if !unset.Enabled() || unset.EffectiveInterval() != DefaultBudgetInterval || unset.EffectiveThreshold() != DefaultBudgetThreshold {
+		t.Errorf("unset budget = %v, %v, %v; want enabled with defaults", unset.Enabled(), unset.EffectiveInterval(), unset.EffectiveThreshold())
+	}
+	set := &ResourceBudgetConfig{Interval: Duration{30 * time.Second}, Threshold: 95}
+	if set.EffectiveInterval() != 30*time.Second || set.EffectiveThreshold() != 95 {
+		t.Errorf("budget = %v, %v; want 30s, 95", set.EffectiveInterval(), set.EffectiveThreshold())
+	}
+	if (&ResourceBudgetConfig{Disabled: true}).Enabled() {
+		t.Error("Enabled() = true with disabled")
+	}
+
+	tests := []struct {
+		name    string
+		budget  ResourceBudgetConfig
+		wantErr bool
+	}{
+		{"defaults", ResourceBudgetConfig{}, false},
+		{"custom", ResourceBudgetConfig{Interval: Duration{MinBudgetInterval}, Threshold: 100}, false},
+		{"interval too short", ResourceBudgetConfig{Interval: Duration{time.Second}}, true},
+		{"threshold above 100", ResourceBudgetConfig{Threshold: 120}, true},
+		{"negative threshold", ResourceBudgetConfig{Threshold: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateWatch(&WatchConfig{ResourceBudget: &tt.budget})
+			if errs.HasErrors() != tt.wantErr {
+				t.Errorf("validateWatch() errors = %v, wantErr %v", errs.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourcesConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		resources    *ResourcesConfig
+		wantRequests ResourceValues
+		wantLimits   ResourceValues
+	}{
+		{
+			name:         "nil uses defaults",
+			wantRequests: ResourceValues{CPU: DefaultCPURequest, Memory: DefaultMemoryRequest},
+			wantLimits:   ResourceValues{CPU: DefaultCPULimit, Memory: DefaultMemoryLimit},
+		},
+		{
+			name:         "partial override",
+			resources:    &ResourcesConfig{Requests: ResourceValues{Memory: "256Mi"}, Limits: ResourceValues{CPU: "2"}},
+			wantRequests: ResourceValues{CPU: DefaultCPURequest, Memory: "256Mi"},
+			wantLimits:   ResourceValues{CPU: "2", Memory: DefaultMemoryLimit},
+		},
+		{
+			name:         "unset limit follows a larger request",
+			resources:    &ResourcesConfig{Requests: ResourceValues{CPU: "1", Memory: "2Gi"}},
+			wantRequests: ResourceValues{CPU: "1", Memory: "2Gi"},
+			wantLimits:   ResourceValues{CPU: "1", Memory: "2Gi"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resources.EffectiveRequests(); got != tt.wantRequests {
+				t.Errorf("EffectiveRequests() = %+v, want %+v", got, tt.wantRequests)
+			}
+			if got := tt.resources.EffectiveLimits(); got != tt.wantLimits {
+				t.Errorf("EffectiveLimits() = %+v, want %+v", got, tt.wantLimits)
+			}
+		})
+	}
+}
@@ -4,21 +4,71 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
-	DefaultAPIVersion     = "kudev.io/v1alpha1"
+	DefaultAPIVersion     = APIVersionV1Alpha2
 	DefaultKind           = "DeploymentConfig"
-	ErrApiVersionRequired = "apiVersion is required (should be: kudev.io/v1alpha1)"
-	ErrApiVersionInvalid  = "apiVersion must be '" + DefaultAPIVersion + "', got '%s'"
+	ErrApiVersionRequired = "apiVersion is required (should be: " + DefaultAPIVersion + ")"
+	ErrApiVersionInvalid  = "apiVersion must be one of %s, got '%s'"
 	ErrKindRequired       = "kind is required (should be: DeploymentConfig)"
 	ErrKindInvalid        = "kind must be 'DeploymentConfig', got '%s'"
 )
 
+// DefaultMaxReplicas caps spec.replicas unless spec.safety.maxReplicas overrides it.
+const DefaultMaxReplicas int32 = 100
+
+// DefaultCrashLoopRestarts and DefaultCrashLoopWindow define when 'kudev watch'
+// reports a freshly deployed pod as crash-looping.
+const (
+	DefaultCrashLoopRestarts int32 = 3
+	DefaultCrashLoopWindow         = 2 * time.Minute
+)
+
+// DefaultBudgetInterval and DefaultBudgetThreshold define how often
+// 'kudev watch' checks the cluster's capacity and at which share of
+// requested CPU or memory it warns.
+const (
+	DefaultBudgetInterval        = time.Minute
+	DefaultBudgetThreshold int32 = 85
+)
+
+// MinBudgetInterval is the shortest allowed spec.watch.resourceBudget.interval.
+const MinBudgetInterval = 10 * time.Second
+
+// Default container resources, used for any value spec.resources leaves
+// unset. Small enough for a laptop cluster, large enough for most dev apps.
+const (
+	DefaultCPURequest    = "100m"
+	DefaultMemoryRequest = "128Mi"
+	DefaultCPULimit      = "500m"
+	DefaultMemoryLimit   = "512Mi"
+)
+
+// DefaultArtifactsKeep is the number of deploy cycles kept under .kudev/artifacts.
+const DefaultArtifactsKeep = 10
+
+// DefaultReadinessWatchTimeout bounds the readiness wait after each
+// 'kudev watch' redeploy, so a broken app doesn't stall the loop.
+const DefaultReadinessWatchTimeout = 1 * time.Minute
+
+// dnsSubdomainPattern matches DNS-1123 subdomains (ConfigMap and Secret names).
+var dnsSubdomainPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// configMapKeyPattern matches valid ConfigMap and Secret data keys.
+var configMapKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// dnsLabelPattern matches DNS-1123 label characters.
+var dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
 func (c *DeploymentConfig) Validate(ctx context.Context) error {
 	var errs ValidationError
 
@@ -27,8 +77,8 @@ func (c *DeploymentConfig) Validate(ctx context.Context) error {
 	}
 	if c.APIVersion == "" {
 		errs.Add(ErrApiVersionRequired)
-	} else if c.APIVersion != DefaultAPIVersion {
-		errs.Add(fmt.Sprintf(ErrApiVersionInvalid, c.APIVersion))
+	} else if !IsKnownAPIVersion(c.APIVersion) {
+		errs.Add(fmt.Sprintf(ErrApiVersionInvalid, strings.Join(APIVersions(), ", "), c.APIVersion))
 	}
 
 	if c.Kind == "" {
@@ -72,10 +122,18 @@ func (c *DeploymentConfig) validateSpec(ctx context.Context) ValidationError {
 		// Don't return early - validate other fields too
 	}
 
-	if spec.DockerfilePath == "" {
+	if spec.Image != nil {
+		errs.Merge(validateImage(spec))
+	}
+	if spec.Hash != nil {
+		errs.Merge(validateHash(spec.Hash))
+	}
+
+	// A pre-built image needs no Dockerfile
+	if spec.DockerfilePath == "" && c.PrebuiltImage() == "" {
 		errs.AddWithExample("spec.dockerfilePath is required",
 			"spec:\n  dockerfilePath: ./Dockerfile")
-	} else {
+	} else if spec.DockerfilePath != "" {
 		// Validate the dockerfile savePath (only if not empty)
 		if err := validateDockerfilePath(spec.DockerfilePath); err != nil {
 			errs.Add(fmt.Sprintf("spec.dockerfilePath: %v", err))
@@ -100,20 +158,37 @@ func (c *DeploymentConfig) validateSpec(ctx context.Context) ValidationError {
 		), "spec:\n  replicas: 1")
 	}
 
-	if spec.Replicas > 100 {
-		// Warning, not error (but we're only doing errors for now) Phase 4 can add warnings system
+	if maxReplicas := spec.Safety.EffectiveMaxReplicas(); spec.Replicas > maxReplicas {
+		errs.AddWithExample(fmt.Sprintf(
+			"spec.replicas is %d, above the safety limit of %d (typo?)",
+			spec.Replicas, maxReplicas,
+		), fmt.Sprintf("spec:\n  replicas: %d\n  safety:\n    maxReplicas: %d  # raise deliberately", spec.Replicas, spec.Replicas))
 	}
 
 	// === Port Validation ===
 
-	if err := validatePort("spec.localPort", spec.LocalPort); err != nil {
-		errs.AddWithExample(err.Error(), "spec:\n  localPort: 8080  # 1-65535")
+	// An automatic local port is allocated at runtime
+	if !spec.AutoLocalPort || spec.LocalPort != 0 {
+		if err := validatePort("spec.localPort", spec.LocalPort); err != nil {
+			errs.AddWithExample(err.Error(), "spec:\n  localPort: 8080  # 1-65535 or auto")
+		}
 	}
 
 	if err := validatePort("spec.servicePort", spec.ServicePort); err != nil {
 		errs.AddWithExample(err.Error(), "spec:\n  servicePort: 8080  # 1-65535")
 	}
 
+	if spec.ContainerName != "" {
+		if !dnsLabelPattern.MatchString(spec.ContainerName) || len(spec.ContainerName) > 63 {
+			errs.AddWithExample(fmt.Sprintf(
+				"spec.containerName %q must be a DNS-1123 label (lowercase alphanumeric and hyphens, at most 63 characters)",
+				spec.ContainerName,
+			), "spec:\n  containerName: app")
+		}
+	}
+
+	errs.Merge(validatePorts(spec))
+
 	// === Environment Variables ===
 
 	if err := validateEnv(spec.Env); err != nil {
@@ -143,6 +218,51 @@ func (c *DeploymentConfig) validateSpec(ctx context.Context) ValidationError {
 		}
 	}
 
+	if spec.Watch != nil {
+		errs.Merge(validateWatch(spec.Watch))
+	}
+
+	if ttl := spec.TTL.Duration; ttl < 0 || (ttl > 0 && ttl < MinTTL) {
+		errs.AddWithExample(fmt.Sprintf("spec.ttl must be at least %s, got %s", MinTTL, ttl),
+			"spec:\n  ttl: 72h")
+	}
+
+	if spec.Safety != nil {
+		errs.Merge(validateSafety(spec.Safety))
+	}
+
+	if spec.Artifacts != nil && spec.Artifacts.Keep < 0 {
+		errs.Add(fmt.Sprintf("spec.artifacts.keep cannot be negative, got %d", spec.Artifacts.Keep))
+	}
+
+	if spec.Readiness != nil {
+		errs.Merge(validateReadiness(spec.Readiness))
+	}
+
+	for i, rule := range spec.Sync {
+		errs.Merge(validateSyncRule(i, rule))
+	}
+
+	if spec.Build != nil {
+		errs.Merge(validateBuild(spec.Build))
+	}
+
+	if spec.Resources != nil {
+		errs.Merge(validateResources(spec.Resources))
+	}
+
+	if spec.Probes != nil {
+		errs.Merge(validateProbe("liveness", spec.Probes.Liveness))
+		errs.Merge(validateProbe("readiness", spec.Probes.Readiness))
+	}
+
+	if spec.PortForward != nil {
+		if addr := spec.PortForward.Address; addr != "" && addr != DefaultPortForwardAddress && net.ParseIP(addr) == nil {
+			errs.AddWithExample(fmt.Sprintf("spec.portForward.address must be localhost or an IP address, got %q", addr),
+				"spec:\n  portForward:\n    address: 0.0.0.0  # or 127.0.0.1, ::1")
+		}
+	}
+
 	return errs
 }
 
@@ -214,6 +334,50 @@ func validatePort(fieldName string, port int32) error {
 	return nil
 }
 
+// validatePorts checks spec.ports, including that no local port is
+// forwarded twice.
+func validatePorts(spec SpecConfig) ValidationError {
+	var errs ValidationError
+
+	// Local ports are per protocol: TCP 5353 and UDP 5353 don't clash
+	type localPort struct {
+		port     int32
+		protocol string
+	}
+
+	primary := PortMapping{Local: spec.LocalPort, Container: spec.ServicePort}
+	seen := map[localPort]string{}
+	if spec.LocalPort != 0 {
+		seen[localPort{spec.LocalPort, "TCP"}] = "spec.localPort"
+	}
+
+	for i, m := range spec.Ports {
+		field := fmt.Sprintf("spec.ports[%d]", i)
+		if err := validatePort(field+".local", m.Local); err != nil {
+			errs.AddWithExample(err.Error(), "spec:\n  ports:\n    - local: 5005\n      container: 5005")
+		}
+		if err := validatePort(field+".container", m.Container); err != nil {
+			errs.AddWithExample(err.Error(), "spec:\n  ports:\n    - local: 5005\n      container: 5005")
+		}
+		switch m.EffectiveProtocol() {
+		case "TCP", "UDP", "SCTP":
+		default:
+			errs.AddWithExample(fmt.Sprintf("%s.protocol must be TCP, UDP or SCTP, got %q", field, m.Protocol),
+				"spec:\n  ports:\n    - local: 5353\n      container: 53\n      protocol: UDP")
+		}
+		if m.same(primary) {
+			continue
+		}
+		key := localPort{m.Local, m.EffectiveProtocol()}
+		if other, ok := seen[key]; ok && m.Local != 0 {
+			errs.Add(fmt.Sprintf("%s.local %d is already forwarded by %s", field, m.Local, other))
+			continue
+		}
+		seen[key] = field
+	}
+	return errs
+}
+
 func validateImageName(name string) error {
 	pattern := `^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
 	if !regexp.MustCompile(pattern).MatchString(name) {
@@ -242,6 +406,10 @@ func validateEnv(vars []EnvVar) *ValidationError {
 			errs.Add(fmt.Sprintf("env[%d].name %q: %v", i, v.Name, err))
 		}
 
+		if v.ValueFrom != nil {
+			errs.Merge(validateEnvVarSource(i, v))
+		}
+
 		if seenNames[v.Name] {
 			errs.Add(fmt.Sprintf("env[%d].name '%q' is not unique (first occurence: env[?].name %q)", i, v.Name, v.Name))
 		}
@@ -250,6 +418,48 @@ func validateEnv(vars []EnvVar) *ValidationError {
 	return &errs
 }
 
+// validateEnvVarSource checks env[i].valueFrom.
+func validateEnvVarSource(i int, v EnvVar) ValidationError {
+	var errs ValidationError
+
+	if v.Value != "" {
+		errs.Add(fmt.Sprintf("env[%d] %q sets both value and valueFrom", i, v.Name))
+	}
+
+	cmRef, secretRef := v.ValueFrom.ConfigMapKeyRef, v.ValueFrom.SecretKeyRef
+	switch {
+	case cmRef == nil && secretRef == nil:
+		errs.AddWithExample(fmt.Sprintf("env[%d].valueFrom must set configMapKeyRef or secretKeyRef", i),
+			"env:\n- name: DB_PASSWORD\n  valueFrom:\n    secretKeyRef:\n      name: db-credentials\n      key: password")
+	case cmRef != nil && secretRef != nil:
+		errs.Add(fmt.Sprintf("env[%d].valueFrom sets both configMapKeyRef and secretKeyRef", i))
+	case cmRef != nil:
+		errs.Merge(validateKeyRef(fmt.Sprintf("env[%d].valueFrom.configMapKeyRef", i), "ConfigMap", cmRef.Name, cmRef.Key))
+	default:
+		errs.Merge(validateKeyRef(fmt.Sprintf("env[%d].valueFrom.secretKeyRef", i), "Secret", secretRef.Name, secretRef.Key))
+	}
+
+	return errs
+}
+
+// validateKeyRef checks the name and key of a ConfigMap or Secret reference.
+func validateKeyRef(field, kind, name, key string) ValidationError {
+	var errs ValidationError
+
+	if name == "" {
+		errs.Add(fmt.Sprintf("%s.name is required", field))
+	} else if len(name) > 253 || !dnsSubdomainPattern.MatchString(name) {
+		errs.Add(fmt.Sprintf("%s.name %q is not a valid %s name (must be DNS-1123 compliant)", field, name, kind))
+	}
+	if key == "" {
+		errs.Add(fmt.Sprintf("%s.key is required", field))
+	} else if !configMapKeyPattern.MatchString(key) {
+		errs.Add(fmt.Sprintf("%s.key %q may only contain letters, digits, '-', '_' and '.'", field, key))
+	}
+
+	return errs
+}
+
 func validateEnvVarName(name string) error {
 	if name == "" {
 		return errors.New("name is required")
@@ -280,35 +490,336 @@ func validateBuildContextExclusions(exclusions []string) *ValidationError {
 
 	for i, exc := range exclusions {
 		if exc == "" {
-			errs.Add(fmt.Sprintf("buildContextExclusions[%d] cannot be empty", i))
+			errs.Add(fmt.Sprintf("exclude[%d] cannot be empty", i))
 			continue
 		}
 
 		if strings.HasPrefix(exc, "/") {
-			errs.Add(fmt.Sprintf("buildContextExclusions[%d] should be relative savePath, not absolute: %q", i, exc))
+			errs.Add(fmt.Sprintf("exclude[%d] should be relative savePath, not absolute: %q", i, exc))
 		}
 
 		if strings.Contains(exc, "\\") {
-			errs.Add(fmt.Sprintf("buildContextExclusions[%d] should use forward slashes, not backslashes: %q (use '%s')",
+			errs.Add(fmt.Sprintf("exclude[%d] should use forward slashes, not backslashes: %q (use '%s')",
 				i, exc, strings.ReplaceAll(exc, "\\", "/")))
 		}
 	}
 	return &errs
 }
 
+// MinTTL is the shortest allowed spec.ttl.
+const MinTTL = 10 * time.Minute
+
+// MinRebuildEvery is the shortest allowed scheduled rebuild interval.
+const MinRebuildEvery = 10 * time.Second
+
+func validateWatch(w *WatchConfig) ValidationError {
+	var errs ValidationError
+
+	every := w.RebuildEvery.Duration
+	if every < 0 || (every > 0 && every < MinRebuildEvery) {
+		errs.AddWithExample(fmt.Sprintf("spec.watch.rebuildEvery must be at least %s, got %s", MinRebuildEvery, every),
+			"spec:\n  watch:\n    rebuildEvery: 1h")
+	}
+
+	if w.CrashLoop != nil {
+		if w.CrashLoop.Restarts < 0 {
+			errs.Add(fmt.Sprintf("spec.watch.crashLoop.restarts cannot be negative, got %d", w.CrashLoop.Restarts))
+		}
+		if w.CrashLoop.Window.Duration < 0 {
+			errs.Add(fmt.Sprintf("spec.watch.crashLoop.window cannot be negative, got %s", w.CrashLoop.Window.Duration))
+		}
+	}
+
+	if b := w.ResourceBudget; b != nil {
+		if interval := b.Interval.Duration; interval < 0 || (interval > 0 && interval < MinBudgetInterval) {
+			errs.AddWithExample(fmt.Sprintf("spec.watch.resourceBudget.interval must be at least %s, got %s", MinBudgetInterval, interval),
+				"spec:\n  watch:\n    resourceBudget:\n      interval: 1m")
+		}
+		if b.Threshold < 0 || b.Threshold > 100 {
+			errs.Add(fmt.Sprintf("spec.watch.resourceBudget.threshold must be a percentage between 1 and 100, got %d", b.Threshold))
+		}
+	}
+
+	switch w.EffectiveBuildOutput() {
+	case BuildOutputQuiet, BuildOutputNormal, BuildOutputVerbose:
+	default:
+		errs.AddWithExample(fmt.Sprintf("spec.watch.buildOutput must be quiet, normal or verbose, got %q", w.BuildOutput),
+			"spec:\n  watch:\n    buildOutput: verbose")
+	}
+
+	return errs
+}
+
+func validateReadiness(r *ReadinessConfig) ValidationError {
+	var errs ValidationError
+
+	switch r.EffectiveStrategy() {
+	case ReadinessRollout, ReadinessEndpoints:
+	case ReadinessHTTP:
+		if !strings.HasPrefix(r.EffectivePath(), "/") {
+			errs.Add(fmt.Sprintf("spec.readiness.path must start with '/', got %q", r.Path))
+		}
+	case ReadinessCommand:
+		if len(r.Command) == 0 {
+			errs.AddWithExample("spec.readiness.command is required for the command strategy",
+				"spec:\n  readiness:\n    strategy: command\n    command: [\"./scripts/smoke-test.sh\"]")
+		}
+	default:
+		errs.Add(fmt.Sprintf("spec.readiness.strategy must be one of %s, %s, %s or %s, got %q",
+			ReadinessRollout, ReadinessEndpoints, ReadinessHTTP, ReadinessCommand, r.Strategy))
+	}
+
+	if r.Timeout.Duration < 0 {
+		errs.Add(fmt.Sprintf("spec.readiness.timeout cannot be negative, got %s", r.Timeout.Duration))
+	}
+
+	return errs
+}
+
+func validateSyncRule(i int, rule SyncRule) ValidationError {
+	var errs ValidationError
+	example := "spec:\n  sync:\n    - local: src\n      remote: /app/src"
+
+	local := filepath.ToSlash(filepath.Clean(rule.Local))
+	switch {
+	case rule.Local == "":
+		errs.AddWithExample(fmt.Sprintf("spec.sync[%d].local is required", i), example)
+	case filepath.IsAbs(rule.Local) || strings.HasPrefix(rule.Local, "/"):
+		errs.Add(fmt.Sprintf("spec.sync[%d].local must be relative to the build context, got %q", i, rule.Local))
+	case local == ".." || strings.HasPrefix(local, "../"):
+		errs.Add(fmt.Sprintf("spec.sync[%d].local must stay inside the build context, got %q", i, rule.Local))
+	}
+
+	if !strings.HasPrefix(rule.Remote, "/") {
+		errs.AddWithExample(fmt.Sprintf("spec.sync[%d].remote must be an absolute container path, got %q", i, rule.Remote), example)
+	}
+
+	return errs
+}
+
+func validateImage(spec SpecConfig) ValidationError {
+	var errs ValidationError
+	example := "spec:\n  image:\n    ref: ghcr.io/org/api:main"
+
+	ref := spec.Image.Ref
+	switch {
+	case ref == "":
+		errs.AddWithExample("spec.image.ref is required when spec.image is set", example)
+	case strings.ContainsAny(ref, " \t\n") || strings.HasPrefix(ref, "-"):
+		errs.AddWithExample(fmt.Sprintf("spec.image.ref must be an image reference, got %q", ref), example)
+	}
+
+	if spec.Build != nil {
+		errs.Add("spec.build cannot be used with spec.image.ref: the image is not built")
+	}
+
+	interval := spec.Image.PollInterval.Duration
+	if interval < 0 || (interval > 0 && interval < MinImagePollInterval) {
+		errs.AddWithExample(fmt.Sprintf("spec.image.pollInterval must be at least %s, got %s", MinImagePollInterval, interval),
+			"spec:\n  image:\n    ref: ghcr.io/org/api:main\n    pollInterval: 1m")
+	}
+
+	return errs
+}
+
+func validateHash(h *HashConfig) ValidationError {
+	var errs ValidationError
+
+	if h.Length != 0 && (h.Length < MinHashLength || h.Length > MaxHashLength) {
+		errs.AddWithExample(fmt.Sprintf("spec.hash.length must be between %d and %d, got %d", MinHashLength, MaxHashLength, h.Length),
+			"spec:\n  hash:\n    length: 12")
+	}
+	switch h.Algorithm {
+	case "", HashSHA256, HashSHA512:
+	default:
+		errs.AddWithExample(fmt.Sprintf("spec.hash.algorithm must be %s or %s, got %q", HashSHA256, HashSHA512, h.Algorithm),
+			"spec:\n  hash:\n    algorithm: sha512")
+	}
+
+	return errs
+}
+
+// platformPattern matches a single os/arch[/variant] platform.
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+func validateBuild(b *BuildConfig) ValidationError {
+	var errs ValidationError
+
+	if !b.Buildx && b.BakeFile == "" && (len(b.CacheFrom) > 0 || len(b.CacheTo) > 0) {
+		errs.AddWithExample("spec.build.cacheFrom and cacheTo require spec.build.buildx",
+			"spec:\n  build:\n    buildx: true\n    cacheFrom: [\"type=local,src=.cache\"]")
+	}
+	if b.BakeTarget != "" && b.BakeFile == "" {
+		errs.AddWithExample("spec.build.bakeTarget requires spec.build.bakeFile",
+			"spec:\n  build:\n    bakeFile: docker-bake.hcl\n    bakeTarget: api")
+	}
+	if b.Platform != "" && !platformPattern.MatchString(b.Platform) {
+		errs.Add(fmt.Sprintf("spec.build.platform must be a single os/arch platform (e.g. linux/amd64), got %q", b.Platform))
+	}
+	for i, c := range b.CacheFrom {
+		if strings.TrimSpace(c) == "" {
+			errs.Add(fmt.Sprintf("spec.build.cacheFrom[%d] cannot be empty", i))
+		}
+	}
+	for i, c := range b.CacheTo {
+		if strings.TrimSpace(c) == "" {
+			errs.Add(fmt.Sprintf("spec.build.cacheTo[%d] cannot be empty", i))
+		}
+	}
+
+	switch b.Backend {
+	case "", BuildBackendCLI:
+	case BuildBackendAPI:
+		if b.Buildx || b.Platform != "" || b.BakeFile != "" || len(b.CacheFrom) > 0 || len(b.CacheTo) > 0 {
+			errs.Add("spec.build.backend api cannot build with buildx, platform, cacheFrom, cacheTo or bakeFile; use the cli backend")
+		}
+	default:
+		errs.AddWithExample(fmt.Sprintf("spec.build.backend must be cli or api, got %q", b.Backend),
+			"spec:\n  build:\n    backend: api")
+	}
+
+	return errs
+}
+
+func validateResources(r *ResourcesConfig) ValidationError {
+	var errs ValidationError
+	example := "spec:\n  resources:\n    requests:\n      cpu: 250m\n      memory: 256Mi"
+
+	valid := true
+	for _, f := range []struct{ field, value string }{
+		{"requests.cpu", r.Requests.CPU},
+		{"requests.memory", r.Requests.Memory},
+		{"limits.cpu", r.Limits.CPU},
+		{"limits.memory", r.Limits.Memory},
+	} {
+		field, value := f.field, f.value
+		if value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			errs.AddWithExample(fmt.Sprintf("spec.resources.%s is not a valid quantity: %q", field, value), example)
+			valid = false
+			continue
+		}
+		if q.Sign() <= 0 {
+			errs.Add(fmt.Sprintf("spec.resources.%s must be positive, got %q", field, value))
+			valid = false
+		}
+	}
+	if !valid {
+		return errs
+	}
+
+	requests, limits := r.EffectiveRequests(), r.EffectiveLimits()
+	if exceeds(requests.CPU, limits.CPU) {
+		errs.Add(fmt.Sprintf("spec.resources.requests.cpu (%s) cannot exceed limits.cpu (%s)", requests.CPU, limits.CPU))
+	}
+	if exceeds(requests.Memory, limits.Memory) {
+		errs.Add(fmt.Sprintf("spec.resources.requests.memory (%s) cannot exceed limits.memory (%s)", requests.Memory, limits.Memory))
+	}
+
+	return errs
+}
+
+func validateProbe(name string, p *ProbeConfig) ValidationError {
+	var errs ValidationError
+	if p == nil {
+		return errs
+	}
+	field := "spec.probes." + name
+
+	if !strings.HasPrefix(p.HTTPGet.Path, "/") {
+		errs.AddWithExample(fmt.Sprintf("%s.httpGet.path must start with '/', got %q", field, p.HTTPGet.Path),
+			fmt.Sprintf("spec:\n  probes:\n    %s:\n      httpGet:\n        path: /healthz", name))
+	}
+	if p.HTTPGet.Port < 0 || p.HTTPGet.Port > 65535 {
+		errs.Add(fmt.Sprintf("%s.httpGet.port must be between 1 and 65535, got %d", field, p.HTTPGet.Port))
+	}
+	if p.InitialDelaySeconds < 0 {
+		errs.Add(fmt.Sprintf("%s.initialDelaySeconds cannot be negative, got %d", field, p.InitialDelaySeconds))
+	}
+	if p.PeriodSeconds < 0 {
+		errs.Add(fmt.Sprintf("%s.periodSeconds cannot be negative, got %d", field, p.PeriodSeconds))
+	}
+
+	return errs
+}
+
+// exceeds reports whether quantity a is larger than b. Both must be valid.
+func exceeds(a, b string) bool {
+	qa, qb := resource.MustParse(a), resource.MustParse(b)
+	return qa.Cmp(qb) > 0
+}
+
+func validateSafety(s *SafetyConfig) ValidationError {
+	var errs ValidationError
+
+	if s.MaxReplicas < 0 {
+		errs.Add(fmt.Sprintf("spec.safety.maxReplicas cannot be negative, got %d", s.MaxReplicas))
+	}
+	if s.MaxManagedDeployments < 0 {
+		errs.Add(fmt.Sprintf("spec.safety.maxManagedDeployments cannot be negative, got %d", s.MaxManagedDeployments))
+	}
+
+	return errs
+}
+
 func (c *DeploymentConfig) ValidateWithContext(projectRoot string) error {
 	if err := c.Validate(context.Background()); err != nil {
 		return err
 	}
 	var errs ValidationError
 
-	dockerfilePath := c.Spec.DockerfilePath
-	if !filepath.IsAbs(dockerfilePath) {
-		dockerfilePath = filepath.Join(projectRoot, dockerfilePath)
+	switch {
+	case c.PrebuiltImage() != "":
+		// A pre-built image has no Dockerfile
+	case c.Spec.Build != nil && c.Spec.Build.BakeFile != "":
+		// A bake file names its own Dockerfile
+		bakeFile := c.Spec.Build.BakeFile
+		if !filepath.IsAbs(bakeFile) {
+			bakeFile = filepath.Join(projectRoot, bakeFile)
+		}
+		if _, err := os.Stat(bakeFile); err != nil {
+			errs.Add(fmt.Sprintf("spec.build.bakeFile %q does not exist at %s", c.Spec.Build.BakeFile, bakeFile))
+		}
+	default:
+		dockerfilePath := c.Spec.DockerfilePath
+		if !filepath.IsAbs(dockerfilePath) {
+			dockerfilePath = filepath.Join(projectRoot, dockerfilePath)
+		}
+
+		if _, err := os.Stat(dockerfilePath); err != nil {
+			errs.Add(fmt.Sprintf("spec.dockerfilePath '%q' does not exist at %s", c.Spec.DockerfilePath, dockerfilePath))
+		}
 	}
 
-	if _, err := os.Stat(dockerfilePath); err != nil {
-		errs.Add(fmt.Sprintf("spec.dockerfilePath '%q' does not exist at %s", c.Spec.DockerfilePath, dockerfilePath))
+	if c.Spec.Build != nil && c.Spec.Build.Context != "" {
+		contextDir := c.Spec.Build.Context
+		if !filepath.IsAbs(contextDir) {
+			contextDir = filepath.Join(projectRoot, contextDir)
+		}
+		if info, err := os.Stat(contextDir); err != nil || !info.IsDir() {
+			errs.Add(fmt.Sprintf("spec.build.context %q is not a directory at %s", c.Spec.Build.Context, contextDir))
+		}
+	}
+
+	if t := c.Spec.Templates; t != nil {
+		for _, tpl := range []struct{ field, path string }{
+			{"spec.templates.deployment", t.Deployment},
+			{"spec.templates.service", t.Service},
+		} {
+			if tpl.path == "" {
+				continue
+			}
+			resolved := tpl.path
+			if !filepath.IsAbs(resolved) {
+				resolved = filepath.Join(projectRoot, resolved)
+			}
+			if _, err := os.Stat(resolved); err != nil {
+				errs.Add(fmt.Sprintf("%s %q does not exist at %s", tpl.field, tpl.path, resolved))
+			}
+		}
 	}
 
 	if errs.HasErrors() {
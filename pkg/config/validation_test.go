@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestValidate_Valid tests validation of correct configurations.
@@ -128,7 +129,7 @@ func TestValidate_Invalid(t *testing.T) {
 				},
 			},
 			expectErrors: []string{
-				"apiVersion must be '" + DefaultAPIVersion + "', got 'kudev.io/aaaa1'",
+				"apiVersion must be one of " + strings.Join(APIVersions(), ", ") + ", got 'kudev.io/aaaa1'",
 				"kind must be '" + DefaultKind + "', got 'Deplroymane'",
 				"metadata.name: must be DNS-1123 compliant (lowercase alphanumeric and hyphens only, cannot start/end with hyphen). ", "-myapp-",
 				"spec.namespace: must be DNS-1123 compliant (lowercase alphanumeric and hyphens only, cannot start/end with hyphen). ", "-default-",
@@ -138,9 +139,9 @@ func TestValidate_Invalid(t *testing.T) {
 				"spec.imageName: must be lowercase alphanumeric and hypens only.", "_MYAPP_",
 				"spec.dockerfilePath: expected filename to contain 'Dockerfile', got 'aaa.yaml'",
 				"spec.kubeContext: invalid context name format:", "invalid context name format: with spaces",
-				"buildContextExclusions[0] cannot be empty",
-				"buildContextExclusions[1] should be relative savePath, not absolute: ", "/exclude1",
-				"buildContextExclusions[2] should use forward slashes, not backslashes: ", "test\\\\exclude2", "test/exclude2",
+				"exclude[0] cannot be empty",
+				"exclude[1] should be relative savePath, not absolute: ", "/exclude1",
+				"exclude[2] should use forward slashes, not backslashes: ", "test\\\\exclude2", "test/exclude2",
 			},
 		},
 	}
@@ -433,6 +434,83 @@ func TestValidate_EnvVars(t *testing.T) {
 			expectError: true,
 			errorMsg:    "unique",
 		},
+		{
+			name: "valueFrom configMapKeyRef",
+			vars: []EnvVar{
+				{Name: "LOG_LEVEL", ValueFrom: &EnvVarSource{
+					ConfigMapKeyRef: &ConfigMapKeySelector{Name: "app.config", Key: "log_level"},
+				}},
+			},
+			expectError: false,
+		},
+		{
+			name: "value and valueFrom",
+			vars: []EnvVar{
+				{Name: "LOG_LEVEL", Value: "info", ValueFrom: &EnvVarSource{
+					ConfigMapKeyRef: &ConfigMapKeySelector{Name: "app-config", Key: "log_level"},
+				}},
+			},
+			expectError: true,
+			errorMsg:    "sets both value and valueFrom",
+		},
+		{
+			name: "valueFrom without source",
+			vars: []EnvVar{
+				{Name: "LOG_LEVEL", ValueFrom: &EnvVarSource{}},
+			},
+			expectError: true,
+			errorMsg:    "must set configMapKeyRef or secretKeyRef",
+		},
+		{
+			name: "configMapKeyRef missing key",
+			vars: []EnvVar{
+				{Name: "LOG_LEVEL", ValueFrom: &EnvVarSource{
+					ConfigMapKeyRef: &ConfigMapKeySelector{Name: "app-config"},
+				}},
+			},
+			expectError: true,
+			errorMsg:    "configMapKeyRef.key is required",
+		},
+		{
+			name: "configMapKeyRef invalid name",
+			vars: []EnvVar{
+				{Name: "LOG_LEVEL", ValueFrom: &EnvVarSource{
+					ConfigMapKeyRef: &ConfigMapKeySelector{Name: "App_Config", Key: "log_level"},
+				}},
+			},
+			expectError: true,
+			errorMsg:    "is not a valid ConfigMap name",
+		},
+		{
+			name: "valueFrom secretKeyRef",
+			vars: []EnvVar{
+				{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{
+					SecretKeyRef: &SecretKeySelector{Name: "db-credentials", Key: "password"},
+				}},
+			},
+			expectError: false,
+		},
+		{
+			name: "secretKeyRef invalid name",
+			vars: []EnvVar{
+				{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{
+					SecretKeyRef: &SecretKeySelector{Name: "DB_Credentials", Key: "password"},
+				}},
+			},
+			expectError: true,
+			errorMsg:    "is not a valid Secret name",
+		},
+		{
+			name: "both configMapKeyRef and secretKeyRef",
+			vars: []EnvVar{
+				{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{
+					ConfigMapKeyRef: &ConfigMapKeySelector{Name: "app-config", Key: "password"},
+					SecretKeyRef:    &SecretKeySelector{Name: "db-credentials", Key: "password"},
+				}},
+			},
+			expectError: true,
+			errorMsg:    "sets both configMapKeyRef and secretKeyRef",
+		},
 	}
 
 	for _, tt := range tests {
@@ -544,3 +622,354 @@ func TestValidate_DockerfilePath(t *testing.T) {
 func stringContains(haystack, needle string) bool {
 	return strings.Contains(haystack, needle)
 }
+
+func TestValidate_SafetyMaxReplicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		safety   *SafetyConfig
+		wantErr  string
+	}{
+		{name: "at default cap", replicas: 100},
+		{name: "above default cap", replicas: 101, wantErr: "above the safety limit of 100"},
+		{name: "above custom cap", replicas: 4, safety: &SafetyConfig{MaxReplicas: 3}, wantErr: "above the safety limit of 3"},
+		{name: "raised cap", replicas: 150, safety: &SafetyConfig{MaxReplicas: 200}},
+		{name: "negative limit", replicas: 1, safety: &SafetyConfig{MaxManagedDeployments: -1}, wantErr: "maxManagedDeployments cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.Replicas = tt.replicas
+			cfg.Spec.Safety = tt.safety
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Readiness(t *testing.T) {
+	tests := []struct {
+		name      string
+		readiness *ReadinessConfig
+		wantErr   string
+	}{
+		{name: "default strategy", readiness: &ReadinessConfig{}},
+		{name: "endpoints", readiness: &ReadinessConfig{Strategy: ReadinessEndpoints}},
+		{name: "http with path", readiness: &ReadinessConfig{Strategy: ReadinessHTTP, Path: "/healthz"}},
+		{name: "http relative path", readiness: &ReadinessConfig{Strategy: ReadinessHTTP, Path: "healthz"}, wantErr: "must start with '/'"},
+		{name: "command", readiness: &ReadinessConfig{Strategy: ReadinessCommand, Command: []string{"./check.sh"}}},
+		{name: "command missing", readiness: &ReadinessConfig{Strategy: ReadinessCommand}, wantErr: "spec.readiness.command is required"},
+		{name: "unknown strategy", readiness: &ReadinessConfig{Strategy: "ping"}, wantErr: "spec.readiness.strategy must be one of"},
+		{name: "negative timeout", readiness: &ReadinessConfig{Timeout: Duration{-time.Second}}, wantErr: "timeout cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.Readiness = tt.readiness
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Resources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *ResourcesConfig
+		wantErr   string
+	}{
+		{name: "defaults", resources: &ResourcesConfig{}},
+		{name: "custom", resources: &ResourcesConfig{Requests: ResourceValues{CPU: "250m", Memory: "256Mi"}, Limits: ResourceValues{CPU: "1", Memory: "1Gi"}}},
+		{name: "invalid quantity", resources: &ResourcesConfig{Requests: ResourceValues{Memory: "256MB!"}}, wantErr: "spec.resources.requests.memory is not a valid quantity"},
+		{name: "zero", resources: &ResourcesConfig{Limits: ResourceValues{CPU: "0"}}, wantErr: "spec.resources.limits.cpu must be positive"},
+		{name: "request above limit", resources: &ResourcesConfig{Requests: ResourceValues{Memory: "2Gi"}, Limits: ResourceValues{Memory: "1Gi"}}, wantErr: "cannot exceed limits.memory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.Resources = tt.resources
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Probes(t *testing.T) {
+	tests := []struct {
+		name    string
+		probes  *ProbesConfig
+		wantErr string
+	}{
+		{name: "empty", probes: &ProbesConfig{}},
+		{name: "valid", probes: &ProbesConfig{
+			Liveness:  &ProbeConfig{HTTPGet: HTTPGetConfig{Path: "/healthz", Port: 9090}, InitialDelaySeconds: 10},
+			Readiness: &ProbeConfig{HTTPGet: HTTPGetConfig{Path: "/ready"}, PeriodSeconds: 5},
+		}},
+		{name: "missing path", probes: &ProbesConfig{Readiness: &ProbeConfig{}}, wantErr: "spec.probes.readiness.httpGet.path must start with '/'"},
+		{name: "bad port", probes: &ProbesConfig{Liveness: &ProbeConfig{HTTPGet: HTTPGetConfig{Path: "/", Port: 70000}}}, wantErr: "spec.probes.liveness.httpGet.port"},
+		{name: "negative period", probes: &ProbesConfig{Liveness: &ProbeConfig{HTTPGet: HTTPGetConfig{Path: "/"}, PeriodSeconds: -1}}, wantErr: "periodSeconds cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.Probes = tt.probes
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_ContainerName(t *testing.T) {
+	tests := []struct {
+		name      string
+		container string
+		wantErr   bool
+	}{
+		{name: "default", container: ""},
+		{name: "short", container: "a"},
+		{name: "label", container: "web-server"},
+		{name: "uppercase", container: "Web", wantErr: true},
+		{name: "dot", container: "web.server", wantErr: true},
+		{name: "too long", container: strings.Repeat("a", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.ContainerName = tt.container
+
+			err := cfg.Validate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_PortMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		ports   []PortMapping
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "debug port", ports: []PortMapping{{Local: 5005, Container: 5005}}},
+		{name: "repeats the service port", ports: []PortMapping{{Local: 8080, Container: 8080}}},
+		{name: "missing container", ports: []PortMapping{{Local: 5005}}, wantErr: "spec.ports[0].container must be between 1 and 65535"},
+		{name: "clashes with localPort", ports: []PortMapping{{Local: 8080, Container: 9090}}, wantErr: "spec.ports[0].local 8080 is already forwarded by spec.localPort"},
+		{name: "duplicate local", ports: []PortMapping{{Local: 5005, Container: 5005}, {Local: 5005, Container: 2345}}, wantErr: "spec.ports[1].local 5005 is already forwarded by spec.ports[0]"},
+		{name: "udp on the service port", ports: []PortMapping{{Local: 8080, Container: 8080, Protocol: "udp"}}},
+		{name: "bad protocol", ports: []PortMapping{{Local: 5005, Container: 5005, Protocol: "ICMP"}}, wantErr: "spec.ports[0].protocol must be TCP, UDP or SCTP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.LocalPort, cfg.Spec.ServicePort = 8080, 8080
+			cfg.Spec.Ports = tt.ports
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_PortForwardAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{address: ""},
+		{address: "localhost"},
+		{address: "127.0.0.1"},
+		{address: "0.0.0.0"},
+		{address: "::1"},
+		{address: "my-laptop", wantErr: true},
+		{address: "127.0.0.1:8080", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.PortForward = &PortForwardConfig{Address: tt.address}
+
+			err := cfg.Validate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   *BuildConfig
+		wantErr string
+	}{
+		{name: "buildx", build: &BuildConfig{Buildx: true, Platform: "linux/arm64/v8", CacheFrom: []string{"type=local,src=.cache"}}},
+		{name: "platform without buildx", build: &BuildConfig{Platform: "linux/amd64"}},
+		{name: "cacheTo without buildx", build: &BuildConfig{CacheTo: []string{"type=inline"}}, wantErr: "require spec.build.buildx"},
+		{name: "multiple platforms", build: &BuildConfig{Buildx: true, Platform: "linux/amd64,linux/arm64"}, wantErr: "single os/arch platform"},
+		{name: "empty cache entry", build: &BuildConfig{Buildx: true, CacheTo: []string{" "}}, wantErr: "spec.build.cacheTo[0] cannot be empty"},
+		{name: "bake with cache", build: &BuildConfig{BakeFile: "docker-bake.hcl", BakeTarget: "api", CacheFrom: []string{"type=local,src=.cache"}}},
+		{name: "bakeTarget without bakeFile", build: &BuildConfig{BakeTarget: "api"}, wantErr: "bakeTarget requires spec.build.bakeFile"},
+		{name: "api backend", build: &BuildConfig{Backend: BuildBackendAPI, NoCache: true}},
+		{name: "api backend with buildx", build: &BuildConfig{Backend: BuildBackendAPI, Buildx: true}, wantErr: "use the cli backend"},
+		{name: "unknown backend", build: &BuildConfig{Backend: "podman"}, wantErr: "spec.build.backend must be cli or api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.Build = tt.build
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Hash(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    *HashConfig
+		wantErr string
+	}{
+		{name: "defaults", hash: &HashConfig{}},
+		{name: "longer sha512", hash: &HashConfig{Length: 16, Algorithm: HashSHA512, IncludeModes: true}},
+		{name: "too short", hash: &HashConfig{Length: 4}, wantErr: "spec.hash.length must be between 8 and 63"},
+		{name: "too long for a label", hash: &HashConfig{Length: 64}, wantErr: "spec.hash.length must be between 8 and 63"},
+		{name: "unknown algorithm", hash: &HashConfig{Algorithm: "md5"}, wantErr: "spec.hash.algorithm must be sha256 or sha512"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.Hash = tt.hash
+
+			err := cfg.Validate(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_SyncRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    SyncRule
+		wantErr string
+	}{
+		{name: "valid", rule: SyncRule{Local: "src", Remote: "/app/src"}},
+		{name: "whole context", rule: SyncRule{Local: ".", Remote: "/app"}},
+		{name: "missing local", rule: SyncRule{Remote: "/app"}, wantErr: "spec.sync[0].local is required"},
+		{name: "absolute local", rule: SyncRule{Local: "/src", Remote: "/app"}, wantErr: "must be relative"},
+		{name: "escaping local", rule: SyncRule{Local: "../shared", Remote: "/app"}, wantErr: "inside the build context"},
+		{name: "relative remote", rule: SyncRule{Local: "src", Remote: "app/src"}, wantErr: "absolute container path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateSyncRule(0, tt.rule)
+			if tt.wantErr == "" {
+				if errs.HasErrors() {
+					t.Errorf("unexpected errors: %v", errs.Error())
+				}
+				return
+			}
+			if !strings.Contains(errs.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", errs.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_TTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{name: "unset", ttl: 0},
+		{name: "minimum", ttl: MinTTL},
+		{name: "days", ttl: 72 * time.Hour},
+		{name: "too short", ttl: time.Minute, wantErr: true},
+		{name: "negative", ttl: -time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDeploymentConfig("myapp")
+			cfg.Spec.TTL = Duration{tt.ttl}
+
+			err := cfg.Validate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
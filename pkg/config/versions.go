@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config schema versions. DeploymentConfig always follows the latest one
+// (DefaultAPIVersion); documents in an older apiVersion are upgraded on
+// load, one version at a time, and rewritten by 'kudev config migrate'.
+const (
+	APIVersionV1Alpha1 = "kudev.io/v1alpha1"
+
+	// APIVersionV1Alpha2 renames spec.buildContextExclusions to
+	// spec.exclude: the list also applies to watching and hashing.
+	APIVersionV1Alpha2 = "kudev.io/v1alpha2"
+)
+
+// Converter upgrades a raw config document to the next schema version.
+// It does not set apiVersion.
+type Converter func(doc map[string]interface{}) error
+
+// schemaVersion is a known apiVersion with the converter to the one after it.
+type schemaVersion struct {
+	apiVersion string
+
+	// upgrade is nil for the latest version
+	upgrade Converter
+}
+
+// schemaVersions are the known apiVersions, oldest first. The last one
+// must be DefaultAPIVersion.
+var schemaVersions = []schemaVersion{
+	{apiVersion: APIVersionV1Alpha1, upgrade: upgradeV1Alpha1},
+	{apiVersion: APIVersionV1Alpha2},
+}
+
+// APIVersions lists the supported apiVersions, oldest first.
+func APIVersions() []string {
+	versions := make([]string, len(schemaVersions))
+	for i, v := range schemaVersions {
+		versions[i] = v.apiVersion
+	}
+	return versions
+}
+
+// IsKnownAPIVersion reports whether apiVersion can be loaded.
+func IsKnownAPIVersion(apiVersion string) bool {
+	return versionIndex(apiVersion) >= 0
+}
+
+// versionIndex returns the position of apiVersion in schemaVersions, or -1.
+func versionIndex(apiVersion string) int {
+	for i, v := range schemaVersions {
+		if v.apiVersion == apiVersion {
+			return i
+		}
+	}
+	return -1
+}
+
+// migrateDocument upgrades a config document to DefaultAPIVersion and
+// returns the version it was in. Documents already current, without an
+// apiVersion or with an unknown one (left to validation) are returned
+// unchanged, with an empty from.
+func migrateDocument(doc []byte) (migrated []byte, from string, err error) {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(doc, &raw); err != nil {
+		return nil, "", err
+	}
+
+	from, _ = raw["apiVersion"].(string)
+	start := versionIndex(from)
+	if start < 0 || from == DefaultAPIVersion {
+		return doc, "", nil
+	}
+
+	for _, v := range schemaVersions[start:] {
+		if v.upgrade == nil {
+			break
+		}
+		if err := v.upgrade(raw); err != nil {
+			return nil, "", fmt.Errorf("failed to migrate from %s: %w", v.apiVersion, err)
+		}
+	}
+	raw["apiVersion"] = DefaultAPIVersion
+
+	migrated, err = yaml.Marshal(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to migrate from %s: %w", from, err)
+	}
+	return migrated, from, nil
+}
+
+// Migrate rewrites the documents of a config file in DefaultAPIVersion.
+// Documents already current are kept as written, comments included;
+// migrated ones are re-serialized, which drops their comments. It returns
+// the new content and the version each migrated document was in.
+func Migrate(content []byte) ([]byte, []string, error) {
+	docs := splitDocuments(content)
+	if len(docs) == 0 {
+		return nil, nil, fmt.Errorf("config file is empty")
+	}
+
+	var from []string
+	parts := make([]string, len(docs))
+	for i, doc := range docs {
+		migrated, version, err := migrateDocument(doc)
+		if err != nil {
+			if len(docs) > 1 {
+				return nil, nil, fmt.Errorf("document %d: %w", i+1, err)
+			}
+			return nil, nil, err
+		}
+		if version != "" {
+			from = append(from, version)
+		}
+		parts[i] = strings.TrimLeft(string(migrated), "\n")
+	}
+	if len(from) == 0 {
+		return content, nil, nil
+	}
+	return []byte(strings.Join(parts, "---\n")), from, nil
+}
+
+// upgradeV1Alpha1 converts a kudev.io/v1alpha1 document to v1alpha2.
+func upgradeV1Alpha1(doc map[string]interface{}) error {
+	renameKey(doc["spec"], "buildContextExclusions", "exclude")
+	if profiles, ok := doc["profiles"].(map[string]interface{}); ok {
+		for _, profile := range profiles {
+			renameKey(profile, "buildContextExclusions", "exclude")
+		}
+	}
+	return nil
+}
+
+// renameKey moves m[from] to m[to] when m is a map holding from.
+func renameKey(m interface{}, from, to string) {
+	fields, ok := m.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if v, ok := fields[from]; ok {
+		fields[to] = v
+		delete(fields, from)
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const v1alpha1Config = `# api service
+apiVersion: kudev.io/v1alpha1
+kind: DeploymentConfig
+metadata:
+  name: api
+spec:
+  imageName: api
+  dockerfilePath: ./Dockerfile
+  localPort: 8080
+  servicePort: 8080
+  buildContextExclusions:
+    - dist
+profiles:
+  ci:
+    buildContextExclusions:
+      - dist
+      - coverage
+`
+
+func TestSchemaVersions(t *testing.T) {
+	versions := APIVersions()
+	if versions[len(versions)-1] != DefaultAPIVersion {
+		t.Errorf("latest version = %s, want DefaultAPIVersion %s", versions[len(versions)-1], DefaultAPIVersion)
+	}
+	for i, v := range schemaVersions {
+		if last := i == len(schemaVersions)-1; last != (v.upgrade == nil) {
+			t.Errorf("%s: only the latest version may lack a converter", v.apiVersion)
+		}
+	}
+	if IsKnownAPIVersion("kudev.io/v1") {
+		t.Error("kudev.io/v1 reported as known")
+	}
+}
+
+func TestFileConfigLoader_LoadFromPath_MigratesOlderVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".kudev.yaml")
+	if err := os.WriteFile(configPath, []byte(v1alpha1Config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewFileConfigLoader("", "", tmpDir)
+	loader.Profile = "ci"
+	cfg, err := loader.LoadFromPath(context.Background(), configPath)
+	if err != nil {
+		t.Fatalf("LoadFromPath() error = %v", err)
+	}
+
+	if cfg.APIVersion != DefaultAPIVersion || cfg.MigratedFrom != APIVersionV1Alpha1 {
+		t.Errorf("apiVersion=%s migratedFrom=%s, want %s from %s", cfg.APIVersion, cfg.MigratedFrom, DefaultAPIVersion, APIVersionV1Alpha1)
+	}
+	// The profile was written in the old schema too
+	if got := strings.Join(cfg.Spec.BuildContextExclusions, ","); got != "dist,coverage" {
+		t.Errorf("exclusions = %s, want dist,coverage", got)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	current := serviceDoc("worker")
+	current = strings.Replace(current, "kudev.io/v1alpha1", DefaultAPIVersion, 1)
+	current = "# worker stays as written\n" + current
+
+	migrated, from, err := Migrate([]byte(v1alpha1Config + "---\n" + current))
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(from) != 1 || from[0] != APIVersionV1Alpha1 {
+		t.Errorf("from = %v, want [%s]", from, APIVersionV1Alpha1)
+	}
+
+	out := string(migrated)
+	if strings.Contains(out, "buildContextExclusions") || !strings.Contains(out, "exclude:") {
+		t.Errorf("spec not converted:\n%s", out)
+	}
+	if !strings.Contains(out, "---\n# worker stays as written\n") {
+		t.Errorf("current document not kept as written:\n%s", out)
+	}
+
+	// The result loads without further migration
+	for _, doc := range splitDocuments(migrated) {
+		_, version, err := migrateDocument(doc)
+		if err != nil || version != "" {
+			t.Errorf("migrated document still needs migration from %q (err %v)", version, err)
+		}
+	}
+
+	// Nothing to do
+	again, from, err := Migrate(migrated)
+	if err != nil || len(from) != 0 || string(again) != out {
+		t.Errorf("Migrate() of a current file: from=%v err=%v changed=%v", from, err, string(again) != out)
+	}
+}
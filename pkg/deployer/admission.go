@@ -0,0 +1,154 @@
+package deployer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kudevErrors "github.com/nanaki-93/kudev/pkg/errors"
+)
+
+// AdmissionDenial is a write the API server refused in admission or
+// validation, parsed from its Status.
+type AdmissionDenial struct {
+	// Webhook is the admission webhook that denied the request, if any.
+	Webhook string
+
+	// Policy is the ValidatingAdmissionPolicy that denied the request,
+	// if any.
+	Policy string
+
+	// Violations are the policy failures named in the denial, e.g.
+	// "[k8srequiredlabels] you must provide labels: {"owner"}".
+	Violations []string
+
+	// Fields are the offending fields from the status causes, as
+	// "field: message".
+	Fields []string
+}
+
+var (
+	webhookDenialPattern = regexp.MustCompile(`(?s)admission webhook "([^"]+)" denied the request:?\s*(.*)`)
+	policyDenialPattern  = regexp.MustCompile(`(?s)ValidatingAdmissionPolicy '([^']+)' with binding '[^']*' denied request:?\s*(.*)`)
+
+	// Gatekeeper prefixes each violation with its constraint: "[name] msg"
+	gatekeeperPattern = regexp.MustCompile(`(?m)^[ \t]*\[([^\]]+)\][ \t]*(.+)$`)
+	// Kyverno lists "policy:" lines followed by "  rule: message" lines
+	kyvernoPolicyPattern = regexp.MustCompile(`(?m)^([\w.-]+):[ \t]*$`)
+	kyvernoRulePattern   = regexp.MustCompile(`(?m)^[ \t]+([\w.-]+):[ \t]*(.+)$`)
+	kyvernoPathPattern   = regexp.MustCompile(`\s*rule [\w.-]+ failed at path (/[^\s']*)`)
+)
+
+// ParseAdmissionDenial extracts the webhook, policy and fields from an
+// API error. Returns false for errors that are not admission denials or
+// field validation failures (e.g. RBAC or quota).
+func ParseAdmissionDenial(err error) (*AdmissionDenial, bool) {
+	var apiStatus apierrors.APIStatus
+	if err == nil || !errors.As(err, &apiStatus) {
+		return nil, false
+	}
+	status := apiStatus.Status()
+	denial := &AdmissionDenial{Fields: statusCauses(status)}
+
+	if m := webhookDenialPattern.FindStringSubmatch(status.Message); m != nil {
+		denial.Webhook = m[1]
+		denial.Violations = webhookViolations(m[2])
+		return denial, true
+	}
+	if m := policyDenialPattern.FindStringSubmatch(status.Message); m != nil {
+		denial.Policy = m[1]
+		if msg := strings.TrimSpace(m[2]); msg != "" {
+			denial.Violations = []string{msg}
+		}
+		return denial, true
+	}
+	// Plain API validation: only useful when the server named the fields
+	if status.Reason != metav1.StatusReasonInvalid || len(denial.Fields) == 0 {
+		return nil, false
+	}
+	return denial, true
+}
+
+// statusCauses formats the field causes of status.
+func statusCauses(status metav1.Status) []string {
+	if status.Details == nil {
+		return nil
+	}
+	var fields []string
+	for _, cause := range status.Details.Causes {
+		switch {
+		case cause.Field != "":
+			fields = append(fields, cause.Field+": "+cause.Message)
+		case cause.Message != "":
+			fields = append(fields, cause.Message)
+		}
+	}
+	return fields
+}
+
+// webhookViolations splits a webhook's denial message into violations,
+// understanding the Gatekeeper and Kyverno formats.
+func webhookViolations(msg string) []string {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return nil
+	}
+
+	if matches := gatekeeperPattern.FindAllStringSubmatch(msg, -1); matches != nil {
+		violations := make([]string, 0, len(matches))
+		for _, m := range matches {
+			violations = append(violations, fmt.Sprintf("[%s] %s", m[1], strings.TrimSpace(m[2])))
+		}
+		return violations
+	}
+
+	if kyvernoPolicyPattern.MatchString(msg) {
+		var violations []string
+		for _, m := range kyvernoRulePattern.FindAllStringSubmatch(msg, -1) {
+			rule := strings.TrimPrefix(strings.Trim(strings.TrimSpace(m[2]), "'"), "validation error: ")
+			if path := kyvernoPathPattern.FindStringSubmatch(rule); path != nil {
+				rule = strings.Replace(rule, path[0], "", 1) + " (at " + strings.TrimSuffix(path[1], "/") + ")"
+			}
+			violations = append(violations, fmt.Sprintf("[%s] %s", m[1], rule))
+		}
+		if violations != nil {
+			return violations
+		}
+	}
+
+	return []string{msg}
+}
+
+// explainRejection maps an admission denial or validation failure of
+// obj to a DeployError naming the policy and fields. Other errors are
+// returned unchanged.
+func explainRejection(obj runtime.Object, err error) error {
+	denial, ok := ParseAdmissionDenial(err)
+	if !ok {
+		return err
+	}
+
+	object := "object"
+	if gk, kerr := KindOf(obj); kerr == nil {
+		object = gk.Kind
+	}
+	if accessor, aerr := meta.Accessor(obj); aerr == nil && accessor.GetName() != "" {
+		object += " " + accessor.GetName()
+	}
+
+	details := append(append([]string{}, denial.Violations...), denial.Fields...)
+	switch {
+	case denial.Webhook != "":
+		return kudevErrors.AdmissionDenied(object, fmt.Sprintf("admission webhook %q", denial.Webhook), details, err)
+	case denial.Policy != "":
+		return kudevErrors.AdmissionDenied(object, fmt.Sprintf("ValidatingAdmissionPolicy %q", denial.Policy), details, err)
+	default:
+		return kudevErrors.ManifestInvalid(object, details, err)
+	}
+}
@@ -0,0 +1,157 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	kudevErrors "github.com/nanaki-93/kudev/pkg/errors"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func statusError(code int32, reason metav1.StatusReason, message string, causes ...metav1.StatusCause) error {
+	status := metav1.Status{Status: metav1.StatusFailure, Code: code, Reason: reason, Message: message}
+	if len(causes) > 0 {
+		status.Details = &metav1.StatusDetails{Causes: causes}
+	}
+	return &apierrors.StatusError{ErrStatus: status}
+}
+
+func TestParseAdmissionDenial(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantOK         bool
+		wantWebhook    string
+		wantPolicy     string
+		wantViolations []string
+		wantFields     []string
+	}{
+		{
+			name: "gatekeeper",
+			err: statusError(403, "", `admission webhook "validation.gatekeeper.sh" denied the request: [must-have-owner] you must provide labels: {"owner"}
+[no-latest] container <app> uses a disallowed tag`),
+			wantOK:      true,
+			wantWebhook: "validation.gatekeeper.sh",
+			wantViolations: []string{
+				`[must-have-owner] you must provide labels: {"owner"}`,
+				"[no-latest] container <app> uses a disallowed tag",
+			},
+		},
+		{
+			name: "kyverno",
+			err: statusError(400, metav1.StatusReasonBadRequest, `admission webhook "validate.kyverno.svc-fail" denied the request:
+
+resource Deployment/dev/myapp was blocked due to the following policies
+
+require-labels:
+  check-for-labels: 'validation error: label app.kubernetes.io/name is required. rule check-for-labels failed at path /metadata/labels/app.kubernetes.io/name/'`),
+			wantOK:      true,
+			wantWebhook: "validate.kyverno.svc-fail",
+			wantViolations: []string{
+				"[check-for-labels] label app.kubernetes.io/name is required. (at /metadata/labels/app.kubernetes.io/name)",
+			},
+		},
+		{
+			name:           "unstructured webhook message",
+			err:            statusError(403, "", `admission webhook "policy.example.com" denied the request: images must come from registry.corp`),
+			wantOK:         true,
+			wantWebhook:    "policy.example.com",
+			wantViolations: []string{"images must come from registry.corp"},
+		},
+		{
+			name: "validating admission policy",
+			err: statusError(422, metav1.StatusReasonInvalid,
+				`deployments.apps "myapp" is forbidden: ValidatingAdmissionPolicy 'replica-limit' with binding 'replica-limit-binding' denied request: replicas must be at most 5`),
+			wantOK:         true,
+			wantPolicy:     "replica-limit",
+			wantViolations: []string{"replicas must be at most 5"},
+		},
+		{
+			name: "field validation",
+			err: statusError(422, metav1.StatusReasonInvalid, `Deployment.apps "myapp" is invalid`,
+				metav1.StatusCause{Type: metav1.CauseTypeFieldValueInvalid, Field: "spec.template.spec.containers[0].image", Message: "Required value"}),
+			wantOK:     true,
+			wantFields: []string{"spec.template.spec.containers[0].image: Required value"},
+		},
+		{
+			name:   "invalid without causes",
+			err:    statusError(422, metav1.StatusReasonInvalid, `Deployment.apps "myapp" is invalid`),
+			wantOK: false,
+		},
+		{
+			name:   "rbac",
+			err:    statusError(403, metav1.StatusReasonForbidden, `deployments.apps is forbidden: User "dev" cannot create resource "deployments"`),
+			wantOK: false,
+		},
+		{
+			name:   "not an API error",
+			err:    errors.New("connection refused"),
+			wantOK: false,
+		},
+		{
+			name:        "wrapped",
+			err:         fmt.Errorf("failed to upsert deployment: %w", statusError(403, "", `admission webhook "a.example.com" denied the request`)),
+			wantOK:      true,
+			wantWebhook: "a.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			denial, ok := ParseAdmissionDenial(tt.err)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseAdmissionDenial ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if denial.Webhook != tt.wantWebhook || denial.Policy != tt.wantPolicy {
+				t.Errorf("webhook, policy = %q, %q, want %q, %q", denial.Webhook, denial.Policy, tt.wantWebhook, tt.wantPolicy)
+			}
+			if strings.Join(denial.Violations, "|") != strings.Join(tt.wantViolations, "|") {
+				t.Errorf("violations = %q, want %q", denial.Violations, tt.wantViolations)
+			}
+			if strings.Join(denial.Fields, "|") != strings.Join(tt.wantFields, "|") {
+				t.Errorf("fields = %q, want %q", denial.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestApply_ExplainsAdmissionDenial(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("create", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, statusError(403, "", `admission webhook "validation.gatekeeper.sh" denied the request: [must-have-owner] you must provide labels: {"owner"}`)
+	})
+	kd := NewKubernetesDeployer(fakeClient, nil, &util.MockLogger{})
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}}
+	err := kd.Apply(context.Background(), []runtime.Object{deployment})
+
+	var deployErr *kudevErrors.DeployError
+	if !errors.As(err, &deployErr) {
+		t.Fatalf("Apply error = %v, want a DeployError", err)
+	}
+	msg := deployErr.UserMessage()
+	for _, want := range []string{`Deployment myapp was denied by admission webhook "validation.gatekeeper.sh"`, "[must-have-owner]"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q should contain %q", msg, want)
+		}
+	}
+	if deployErr.SuggestedAction() == "" {
+		t.Error("denial should carry a suggestion")
+	}
+	if !apierrors.IsForbidden(err) {
+		t.Error("the API error should stay reachable through Unwrap")
+	}
+}
@@ -0,0 +1,103 @@
+package deployer
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// ManifestRecorder stores the manifests applied by a deploy cycle.
+// Implemented by artifacts.Store.
+type ManifestRecorder interface {
+	Write(hash string, files map[string]string) (string, error)
+}
+
+// SetManifestRecorder makes every Upsert record its rendered manifests.
+// A nil recorder disables recording.
+func (kd *KubernetesDeployer) SetManifestRecorder(r ManifestRecorder) {
+	kd.recorder = r
+}
+
+// recordManifests writes the manifests for data and any extra objects
+// to the recorder. Recording is best effort and never fails a deploy.
+func (kd *KubernetesDeployer) recordManifests(data TemplateData, extra []runtime.Object) {
+	if kd.recorder == nil {
+		return
+	}
+
+	files, err := kd.manifestFiles(data, extra)
+	if err != nil {
+		kd.logger.Warn("failed to render manifests for artifacts", "error", err)
+		return
+	}
+
+	dir, err := kd.recorder.Write(data.ImageHash, files)
+	if err != nil {
+		kd.logger.Warn("failed to write manifest artifacts", "error", err)
+		return
+	}
+	kd.logger.Debug("manifests recorded", "dir", dir)
+}
+
+// manifestFiles returns the manifests applied for data (file name → YAML).
+// Sensitive values are redacted so the archive is safe to share.
+func (kd *KubernetesDeployer) manifestFiles(data TemplateData, extra []runtime.Object) (map[string]string, error) {
+	depYAML, err := kd.renderer.RenderDeploymentYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	svcYAML, err := kd.renderer.RenderServiceYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{
+		"deployment.yaml": depYAML,
+		"service.yaml":    svcYAML,
+	}
+
+	objects := extra
+	if data.ZeroDowntime {
+		objects = append([]runtime.Object{newPDB(data)}, extra...)
+	}
+	for _, obj := range objects {
+		name, content, err := marshalObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+
+	for name, content := range files {
+		files[name] = logging.Redact(content)
+	}
+	return files, nil
+}
+
+// marshalObject renders obj as YAML with apiVersion/kind filled in.
+// The file name is <kind>-<name>.yaml.
+func marshalObject(obj runtime.Object) (string, string, error) {
+	gvk, err := gvkOf(obj)
+	if err != nil {
+		return "", "", err
+	}
+
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal %s: %w", gvk.Kind, err)
+	}
+
+	name := strings.ToLower(gvk.Kind)
+	if accessor, err := meta.Accessor(obj); err == nil && accessor.GetName() != "" {
+		name += "-" + accessor.GetName()
+	}
+	return name + ".yaml", string(out), nil
+}
@@ -0,0 +1,68 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+type fakeRecorder struct {
+	hashes []string
+	files  []map[string]string
+}
+
+func (r *fakeRecorder) Write(hash string, files map[string]string) (string, error) {
+	r.hashes = append(r.hashes, hash)
+	r.files = append(r.files, files)
+	return "/tmp/" + hash, nil
+}
+
+func TestUpsert_RecordsManifests(t *testing.T) {
+	tests := []struct {
+		name         string
+		zeroDowntime bool
+		wantFiles    []string
+	}{
+		{"deployment and service", false, []string{"deployment.yaml", "service.yaml"}},
+		{"with pdb", true, []string{"deployment.yaml", "service.yaml", "poddisruptionbudget-test-app.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			kd := NewKubernetesDeployer(fake.NewSimpleClientset(), renderer, &util.MockLogger{})
+			rec := &fakeRecorder{}
+			kd.SetManifestRecorder(rec)
+
+			opts := preflightOpts(1)
+			opts.Config.Spec.ZeroDowntime = tt.zeroDowntime
+			if _, err := kd.Upsert(context.Background(), opts); err != nil {
+				t.Fatalf("Upsert failed: %v", err)
+			}
+
+			if len(rec.hashes) != 1 || rec.hashes[0] != "12345678" {
+				t.Fatalf("recorded hashes = %v, want [12345678]", rec.hashes)
+			}
+			files := rec.files[0]
+			if len(files) != len(tt.wantFiles) {
+				t.Errorf("got %d files, want %d", len(files), len(tt.wantFiles))
+			}
+			for _, name := range tt.wantFiles {
+				if files[name] == "" {
+					t.Errorf("missing %s", name)
+				}
+			}
+			if !strings.Contains(files["deployment.yaml"], "image: test-app:kudev-12345678") {
+				t.Errorf("deployment.yaml does not contain the image:\n%s", files["deployment.yaml"])
+			}
+			if tt.zeroDowntime && !strings.Contains(files["poddisruptionbudget-test-app.yaml"], "kind: PodDisruptionBudget") {
+				t.Errorf("pdb manifest missing kind:\n%s", files["poddisruptionbudget-test-app.yaml"])
+			}
+		})
+	}
+}
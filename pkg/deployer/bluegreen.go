@@ -0,0 +1,302 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Blue/green slot names.
+const (
+	ColorBlue  = "blue"
+	ColorGreen = "green"
+)
+
+// colorLabel marks which slot a Deployment, its pods and the Service selector belong to.
+const colorLabel = "kudev-color"
+
+// BlueGreenDeployer deploys each update to the inactive slot
+// (<app>-blue or <app>-green), waits for it to become Ready, then flips
+// the Service selector and scales the previous slot down.
+//
+// The Service keeps its plain <app> name, so port forwarding and
+// in-cluster clients are unaffected by the flip.
+//
+// Experimental: intended for local demos where dropped requests
+// during a redeploy are noticeable.
+type BlueGreenDeployer struct {
+	kd *KubernetesDeployer
+
+	// ReadyTimeout bounds how long to wait for the new slot before giving up.
+	// The Service keeps pointing at the old slot on timeout.
+	ReadyTimeout time.Duration
+
+	pollInterval time.Duration
+}
+
+// NewBlueGreenDeployer wraps a KubernetesDeployer with blue/green rollout.
+func NewBlueGreenDeployer(kd *KubernetesDeployer) *BlueGreenDeployer {
+	return &BlueGreenDeployer{
+		kd:           kd,
+		ReadyTimeout: 5 * time.Minute,
+		pollInterval: 2 * time.Second,
+	}
+}
+
+// Upsert deploys to the inactive slot and switches traffic once it is Ready.
+func (bg *BlueGreenDeployer) Upsert(ctx context.Context, opts DeploymentOptions) (*DeploymentStatus, error) {
+	kd := bg.kd
+	data := NewTemplateData(opts)
+
+	if err := kd.checkSafety(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	if err := kd.checkEnvSources(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	// The slot switch cannot be simulated; validate the plain manifests
+	if opts.DryRun {
+		return kd.dryRunUpsert(ctx, opts)
+	}
+
+	active, err := kd.activeColor(ctx, data.AppName, data.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	data.Color = nextColor(active)
+
+	kd.logger.Info("starting blue/green deployment",
+		"app", data.AppName,
+		"namespace", data.Namespace,
+		"from", active,
+		"to", data.Color,
+	)
+
+	deployment, err := kd.renderer.RenderDeployment(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render deployment: %w", err)
+	}
+
+	service, err := kd.renderer.RenderService(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render service: %w", err)
+	}
+	kd.recordManifests(data, opts.Objects)
+	stampExpiry(deployment, opts.Config, kd.clock.Now())
+
+	if err := kd.ensureNamespace(ctx, data.Namespace); err != nil {
+		return nil, fmt.Errorf("failed to ensure namespace: %w", err)
+	}
+
+	objects := append([]runtime.Object{}, opts.Objects...)
+	if err := kd.validateOnServer(ctx, data, append(objects, deployment, service)); err != nil {
+		return nil, err
+	}
+
+	// 0. Extra objects are shared by both slots
+	if err := kd.Apply(ctx, opts.Objects); err != nil {
+		return nil, err
+	}
+
+	// 1. Roll out the inactive slot
+	if err := kd.upsertDeployment(ctx, deployment); err != nil {
+		return nil, explainRejection(deployment, fmt.Errorf("failed to upsert deployment: %w", err))
+	}
+
+	// 2. Wait until it can serve traffic
+	if err := bg.waitForDeployment(ctx, deployment.Name, data.Namespace); err != nil {
+		return nil, fmt.Errorf("%s slot did not become ready (traffic stays on %q): %w",
+			data.Color, active, err)
+	}
+
+	// 3. Flip the Service selector
+	if err := kd.upsertService(ctx, service); err != nil {
+		return nil, explainRejection(service, fmt.Errorf("failed to switch service to %s: %w", data.Color, err))
+	}
+	kd.logger.Info("service switched", "app", data.AppName, "color", data.Color)
+
+	// 4. Retire the previous slot
+	if active != "" {
+		if err := kd.scaleDeployment(ctx, colorName(data.AppName, active), data.Namespace, 0); err != nil {
+			kd.logger.Warn("failed to scale down previous slot", "color", active, "error", err)
+		}
+	} else if err := kd.deleteDeployment(ctx, data.AppName, data.Namespace); err != nil {
+		// First blue/green deploy: remove the plain (non-colored) Deployment
+		kd.logger.Warn("failed to remove previous deployment", "error", err)
+	}
+
+	if err := kd.syncPDB(ctx, data); err != nil {
+		return nil, err
+	}
+
+	return kd.Status(ctx, data.AppName, data.Namespace)
+}
+
+// Apply delegates to the wrapped deployer.
+func (bg *BlueGreenDeployer) Apply(ctx context.Context, objects []runtime.Object) error {
+	return bg.kd.Apply(ctx, objects)
+}
+
+// Delete removes both slots and the Service.
+func (bg *BlueGreenDeployer) Delete(ctx context.Context, appName, namespace string) error {
+	return bg.kd.Delete(ctx, appName, namespace)
+}
+
+// Status returns the status of the active slot.
+func (bg *BlueGreenDeployer) Status(ctx context.Context, appName, namespace string) (*DeploymentStatus, error) {
+	return bg.kd.Status(ctx, appName, namespace)
+}
+
+// WaitForReady waits for the active slot.
+func (bg *BlueGreenDeployer) WaitForReady(ctx context.Context, opts WaitOptions) error {
+	return bg.kd.WaitForReady(ctx, opts)
+}
+
+// WaitForDeletion waits until both slots are gone.
+func (bg *BlueGreenDeployer) WaitForDeletion(ctx context.Context, opts WaitOptions) error {
+	return bg.kd.WaitForDeletion(ctx, opts)
+}
+
+// CheckBudget delegates to the wrapped deployer.
+func (bg *BlueGreenDeployer) CheckBudget(ctx context.Context) (*ClusterBudget, error) {
+	return bg.kd.CheckBudget(ctx)
+}
+
+// waitForDeployment polls until all replicas of the rollout are updated and ready.
+func (bg *BlueGreenDeployer) waitForDeployment(ctx context.Context, name, namespace string) error {
+	deadline := bg.kd.clock.Now().Add(bg.ReadyTimeout)
+
+	for {
+		d, err := bg.kd.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get deployment: %w", err)
+		}
+		if isRolledOut(d) {
+			return nil
+		}
+
+		if bg.kd.clock.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for %s to be ready", name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-bg.kd.clock.After(bg.pollInterval):
+			// Continue polling
+		}
+	}
+}
+
+// isRolledOut reports whether the latest spec is observed and fully ready.
+func isRolledOut(d *appsv1.Deployment) bool {
+	var want int32 = 1
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	return d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas >= want &&
+		d.Status.ReadyReplicas >= want
+}
+
+// activeColor returns the slot the Service currently selects, or "" if the
+// Service does not exist or is not in blue/green mode.
+func (kd *KubernetesDeployer) activeColor(ctx context.Context, appName, namespace string) (string, error) {
+	svc, err := kd.clientset.CoreV1().Services(namespace).Get(ctx, appName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get service: %w", err)
+	}
+	return svc.Spec.Selector[colorLabel], nil
+}
+
+// deleteColorSlots removes any blue/green slot Deployments of the app.
+// A Deployment named like a slot is only deleted when its labels mark it
+// as one, so an '--instance blue' deploy or an unrelated app survives.
+// It runs on every plain deploy, so a Forbidden delete (restricted RBAC)
+// is only logged.
+func (kd *KubernetesDeployer) deleteColorSlots(ctx context.Context, appName, namespace string) error {
+	for _, color := range []string{ColorBlue, ColorGreen} {
+		name := colorName(appName, color)
+		err := kd.deleteColorSlot(ctx, appName, name, color, namespace)
+		if errors.IsForbidden(err) {
+			kd.logger.Warn("cannot remove blue/green slot",
+				"name", name,
+				"namespace", namespace,
+				"error", err,
+			)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove %s slot: %w", color, err)
+		}
+	}
+	return nil
+}
+
+// deleteColorSlot deletes the Deployment name if it is the color slot of
+// appName. A missing Deployment is not an error.
+func (kd *KubernetesDeployer) deleteColorSlot(ctx context.Context, appName, name, color, namespace string) error {
+	d, err := kd.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if d.Labels[colorLabel] != color || d.Labels["app"] != appName || d.Labels["managed-by"] != "kudev" {
+		kd.logger.Debug("keeping deployment that is not a blue/green slot",
+			"name", name,
+			"namespace", namespace,
+		)
+		return nil
+	}
+	return kd.deleteDeployment(ctx, name, namespace)
+}
+
+// scaleDeployment sets the replica count of an existing Deployment.
+func (kd *KubernetesDeployer) scaleDeployment(ctx context.Context, name, namespace string, replicas int32) error {
+	deployments := kd.clientset.AppsV1().Deployments(namespace)
+
+	d, err := deployments.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	d.Spec.Replicas = &replicas
+	if _, err := deployments.Update(ctx, d, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to scale deployment: %w", err)
+	}
+
+	kd.logger.Debug("deployment scaled", "name", name, "replicas", replicas)
+	return nil
+}
+
+// nextColor returns the slot to deploy to next.
+func nextColor(active string) string {
+	if active == ColorBlue {
+		return ColorGreen
+	}
+	return ColorBlue
+}
+
+// colorName returns the Deployment name of a slot.
+func colorName(appName, color string) string {
+	return appName + "-" + color
+}
+
+// Ensure BlueGreenDeployer implements Deployer
+var _ Deployer = (*BlueGreenDeployer)(nil)
@@ -0,0 +1,210 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+// newReadyFakeClient returns a fake clientset where every Deployment
+// reports all replicas as updated and ready.
+func newReadyFakeClient(objects ...runtime.Object) *fake.Clientset {
+	fakeClient := fake.NewSimpleClientset(objects...)
+	fakeClient.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		get := action.(k8stesting.GetAction)
+		obj, err := fakeClient.Tracker().Get(appsv1.SchemeGroupVersion.WithResource("deployments"), get.GetNamespace(), get.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		d := obj.(*appsv1.Deployment).DeepCopy()
+		if d.Spec.Replicas != nil {
+			d.Status.ReadyReplicas = *d.Spec.Replicas
+			d.Status.UpdatedReplicas = *d.Spec.Replicas
+		}
+		return true, d, nil
+	})
+	return fakeClient
+}
+
+func blueGreenOpts(hash string) DeploymentOptions {
+	return DeploymentOptions{
+		Config: &config.DeploymentConfig{
+			Metadata: config.MetadataConfig{Name: "test-app"},
+			Spec: config.SpecConfig{
+				Namespace:   "default",
+				Replicas:    1,
+				ServicePort: 8080,
+			},
+		},
+		ImageRef:  "test-app:kudev-" + hash,
+		ImageHash: hash,
+	}
+}
+
+func TestBlueGreen_AlternatesSlots(t *testing.T) {
+	// Existing plain deployment from a previous 'kudev up'
+	plain := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-app", "managed-by": "kudev"},
+		},
+	}
+	fakeClient := newReadyFakeClient(plain)
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	bg := NewBlueGreenDeployer(NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{}))
+	bg.pollInterval = 10 * time.Millisecond
+	ctx := context.Background()
+
+	// First deploy goes to blue and replaces the plain deployment
+	status, err := bg.Upsert(ctx, blueGreenOpts("11111111"))
+	if err != nil {
+		t.Fatalf("first Upsert failed: %v", err)
+	}
+	if status.DeploymentName != "test-app-blue" {
+		t.Errorf("DeploymentName = %q, want test-app-blue", status.DeploymentName)
+	}
+	assertServiceColor(t, fakeClient, ColorBlue)
+	if _, err := fakeClient.Tracker().Get(appsv1.SchemeGroupVersion.WithResource("deployments"), "default", "test-app"); !errors.IsNotFound(err) {
+		t.Errorf("plain deployment should be removed, got err=%v", err)
+	}
+
+	// Second deploy goes to green and scales blue down
+	status, err = bg.Upsert(ctx, blueGreenOpts("22222222"))
+	if err != nil {
+		t.Fatalf("second Upsert failed: %v", err)
+	}
+	if status.DeploymentName != "test-app-green" {
+		t.Errorf("DeploymentName = %q, want test-app-green", status.DeploymentName)
+	}
+	assertServiceColor(t, fakeClient, ColorGreen)
+
+	blue, err := fakeClient.AppsV1().Deployments("default").Get(ctx, "test-app-blue", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("blue slot missing: %v", err)
+	}
+	if *blue.Spec.Replicas != 0 {
+		t.Errorf("blue replicas = %d, want 0", *blue.Spec.Replicas)
+	}
+
+	// Third deploy reuses blue
+	if _, err := bg.Upsert(ctx, blueGreenOpts("33333333")); err != nil {
+		t.Fatalf("third Upsert failed: %v", err)
+	}
+	assertServiceColor(t, fakeClient, ColorBlue)
+
+	// Delete removes both slots
+	if err := bg.Delete(ctx, "test-app", "default"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	for _, name := range []string{"test-app-blue", "test-app-green"} {
+		if _, err := fakeClient.Tracker().Get(appsv1.SchemeGroupVersion.WithResource("deployments"), "default", name); !errors.IsNotFound(err) {
+			t.Errorf("%s should be deleted, got err=%v", name, err)
+		}
+	}
+}
+
+func TestBlueGreen_NotReadyKeepsTraffic(t *testing.T) {
+	// Plain fake: deployments never become ready
+	fakeClient := fake.NewSimpleClientset()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	bg := NewBlueGreenDeployer(NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{}))
+	bg.pollInterval = 10 * time.Millisecond
+	bg.ReadyTimeout = 50 * time.Millisecond
+
+	if _, err := bg.Upsert(context.Background(), blueGreenOpts("11111111")); err == nil {
+		t.Fatal("expected error when slot never becomes ready")
+	}
+
+	// Service must not have been created/switched
+	if _, err := fakeClient.CoreV1().Services("default").Get(context.Background(), "test-app", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("service should not exist yet, got err=%v", err)
+	}
+}
+
+func TestUpsert_RemovesColorSlots(t *testing.T) {
+	fakeClient := newReadyFakeClient()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+	bg := NewBlueGreenDeployer(kd)
+	bg.pollInterval = 10 * time.Millisecond
+	ctx := context.Background()
+
+	if _, err := bg.Upsert(ctx, blueGreenOpts("11111111")); err != nil {
+		t.Fatalf("blue/green Upsert failed: %v", err)
+	}
+
+	// Back to a regular deploy
+	if _, err := kd.Upsert(ctx, blueGreenOpts("22222222")); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	assertServiceColor(t, fakeClient, "")
+	if _, err := fakeClient.Tracker().Get(appsv1.SchemeGroupVersion.WithResource("deployments"), "default", "test-app-blue"); !errors.IsNotFound(err) {
+		t.Errorf("blue slot should be removed, got err=%v", err)
+	}
+}
+
+func TestUpsert_KeepsInstanceNamedLikeSlot(t *testing.T) {
+	fakeClient := newReadyFakeClient()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+	ctx := context.Background()
+
+	// 'kudev up --instance blue' deploys test-app-blue
+	instance := blueGreenOpts("11111111")
+	instance.Config.Instance = "blue"
+	instance.Config.Metadata.Name = "test-app-blue"
+	if _, err := kd.Upsert(ctx, instance); err != nil {
+		t.Fatalf("instance Upsert failed: %v", err)
+	}
+
+	if _, err := kd.Upsert(ctx, blueGreenOpts("22222222")); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if err := kd.Delete(ctx, "test-app", "default"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	if _, err := fakeClient.Tracker().Get(appsv1.SchemeGroupVersion.WithResource("deployments"), "default", "test-app-blue"); err != nil {
+		t.Errorf("instance deployment should survive, got err=%v", err)
+	}
+}
+
+func TestUpsert_ColorSlotsForbidden(t *testing.T) {
+	slot := managedDeployment("test-app-blue", "test-app")
+	slot.Labels[colorLabel] = ColorBlue
+	fakeClient := fake.NewSimpleClientset(slot)
+	fakeClient.PrependReactor("delete", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, action.(k8stesting.DeleteAction).GetName(), nil)
+	})
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+	if _, err := kd.Upsert(context.Background(), blueGreenOpts("11111111")); err != nil {
+		t.Fatalf("Upsert failed without delete access: %v", err)
+	}
+}
+
+func assertServiceColor(t *testing.T, client *fake.Clientset, want string) {
+	t.Helper()
+	svc, err := client.CoreV1().Services("default").Get(context.Background(), "test-app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("service not found: %v", err)
+	}
+	if got := svc.Spec.Selector[colorLabel]; got != want {
+		t.Errorf("service color = %q, want %q", got, want)
+	}
+}
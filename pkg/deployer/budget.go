@@ -0,0 +1,245 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxBudgetSuggestions caps the apps named in ClusterBudget.Suggestions.
+const maxBudgetSuggestions = 3
+
+// ClusterBudget compares the cluster's allocatable CPU and memory with
+// what its pods request, and lists the share kudev apps take.
+type ClusterBudget struct {
+	// Nodes is the number of schedulable nodes.
+	Nodes int
+
+	// AllocatableCPU and AllocatableMemory are summed over the nodes.
+	AllocatableCPU    resource.Quantity
+	AllocatableMemory resource.Quantity
+
+	// RequestedCPU and RequestedMemory are the requests of all pods
+	// placed on the nodes.
+	RequestedCPU    resource.Quantity
+	RequestedMemory resource.Quantity
+
+	// Apps are the kudev-managed apps with pods, largest request first.
+	Apps []AppUsage
+
+	// Pending are kudev pods the scheduler could not place for lack of
+	// CPU or memory.
+	Pending []PendingPod
+}
+
+// AppUsage is what the pods of one kudev app request.
+type AppUsage struct {
+	Name      string
+	Namespace string
+	Pods      int
+
+	// CPU and Memory are the requests of one pod.
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// PendingPod is a pod waiting for resources.
+type PendingPod struct {
+	Name      string
+	Namespace string
+	App       string
+
+	// Message is the scheduler's explanation, e.g. "0/1 nodes are
+	// available: 1 Insufficient cpu."
+	Message string
+}
+
+// BudgetChecker is implemented by deployers that can report the
+// cluster's resource budget.
+type BudgetChecker interface {
+	CheckBudget(ctx context.Context) (*ClusterBudget, error)
+}
+
+// CPUPercent returns the requested share of allocatable CPU.
+func (b *ClusterBudget) CPUPercent() int64 {
+	return percent(b.RequestedCPU.MilliValue(), b.AllocatableCPU.MilliValue())
+}
+
+// MemoryPercent returns the requested share of allocatable memory.
+func (b *ClusterBudget) MemoryPercent() int64 {
+	return percent(b.RequestedMemory.Value(), b.AllocatableMemory.Value())
+}
+
+// Alerts explains why the cluster is short of resources: CPU or memory
+// requested at threshold percent or more, and Pending kudev pods.
+// Returns nil when there is nothing to report.
+func (b *ClusterBudget) Alerts(threshold int32) []string {
+	var alerts []string
+	if p := b.CPUPercent(); p >= int64(threshold) {
+		alerts = append(alerts, fmt.Sprintf("%d%% of allocatable CPU is requested (%s of %s)",
+			p, b.RequestedCPU.String(), b.AllocatableCPU.String()))
+	}
+	if p := b.MemoryPercent(); p >= int64(threshold) {
+		alerts = append(alerts, fmt.Sprintf("%d%% of allocatable memory is requested (%s of %s)",
+			p, b.RequestedMemory.String(), b.AllocatableMemory.String()))
+	}
+	for _, pod := range b.Pending {
+		alerts = append(alerts, fmt.Sprintf("pod %s/%s is Pending: %s", pod.Namespace, pod.Name, pod.Message))
+	}
+	return alerts
+}
+
+// Suggestions proposes how the largest kudev apps could free resources:
+// fewer replicas when they run several pods, smaller requests otherwise.
+func (b *ClusterBudget) Suggestions() []string {
+	var suggestions []string
+	for _, app := range b.Apps {
+		if len(suggestions) == maxBudgetSuggestions {
+			break
+		}
+		if app.CPU.IsZero() && app.Memory.IsZero() {
+			continue
+		}
+		if app.Pods > 1 {
+			suggestions = append(suggestions, fmt.Sprintf("lower spec.replicas of %s (%d pods, each requesting cpu=%s memory=%s)",
+				app.Name, app.Pods, app.CPU.String(), app.Memory.String()))
+		} else {
+			suggestions = append(suggestions, fmt.Sprintf("lower spec.resources.requests of %s (cpu=%s memory=%s)",
+				app.Name, app.CPU.String(), app.Memory.String()))
+		}
+	}
+	return suggestions
+}
+
+// CheckBudget sums the allocatable resources of the schedulable nodes
+// and the requests of the pods placed on them, and finds kudev pods
+// stuck Pending for lack of CPU or memory.
+//
+// Like Preflight, this is an estimate: requests are compared, not
+// actual usage, and taints and affinity are ignored.
+func (kd *KubernetesDeployer) CheckBudget(ctx context.Context) (*ClusterBudget, error) {
+	nodes, err := kd.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := kd.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	budget := &ClusterBudget{}
+	schedulable := make(map[string]bool)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+		schedulable[node.Name] = true
+		budget.Nodes++
+		budget.AllocatableCPU.Add(*node.Status.Allocatable.Cpu())
+		budget.AllocatableMemory.Add(*node.Status.Allocatable.Memory())
+	}
+
+	apps := make(map[string]*AppUsage)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if isTerminated(pod) {
+			continue
+		}
+		cpu, memory := podRequests(pod)
+		if schedulable[pod.Spec.NodeName] {
+			budget.RequestedCPU.Add(cpu)
+			budget.RequestedMemory.Add(memory)
+		}
+
+		if pod.Labels[LabelManagedBy] != "kudev" {
+			continue
+		}
+		name := pod.Labels[LabelInstance]
+		if name == "" {
+			name = pod.Labels["app"]
+		}
+		if message := insufficientResources(pod); message != "" {
+			budget.Pending = append(budget.Pending, PendingPod{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+				App:       name,
+				Message:   message,
+			})
+		}
+
+		key := pod.Namespace + "/" + name
+		if apps[key] == nil {
+			apps[key] = &AppUsage{Name: name, Namespace: pod.Namespace, CPU: cpu, Memory: memory}
+		}
+		apps[key].Pods++
+	}
+
+	for _, app := range apps {
+		budget.Apps = append(budget.Apps, *app)
+	}
+	sort.Slice(budget.Apps, func(i, j int) bool {
+		a, b := budget.Apps[i], budget.Apps[j]
+		if share, other := budget.share(a), budget.share(b); share != other {
+			return share > other
+		}
+		return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+	})
+	sort.Slice(budget.Pending, func(i, j int) bool {
+		return budget.Pending[i].Namespace+"/"+budget.Pending[i].Name < budget.Pending[j].Namespace+"/"+budget.Pending[j].Name
+	})
+	return budget, nil
+}
+
+// share is the larger of the CPU and memory shares app takes of the
+// allocatable resources, in permille.
+func (b *ClusterBudget) share(app AppUsage) int64 {
+	pods := int64(app.Pods)
+	cpu := permille(app.CPU.MilliValue()*pods, b.AllocatableCPU.MilliValue())
+	memory := permille(app.Memory.Value()*pods, b.AllocatableMemory.Value())
+	return max(cpu, memory)
+}
+
+// podRequests returns the summed container requests of pod.
+func podRequests(pod *corev1.Pod) (cpu, memory resource.Quantity) {
+	for _, c := range pod.Spec.Containers {
+		cpu.Add(c.Resources.Requests[corev1.ResourceCPU])
+		memory.Add(c.Resources.Requests[corev1.ResourceMemory])
+	}
+	return cpu, memory
+}
+
+// insufficientResources returns the scheduler's message for a pod that
+// is unschedulable for lack of CPU or memory, or "".
+func insufficientResources(pod *corev1.Pod) string {
+	if pod.Spec.NodeName != "" {
+		return ""
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type != corev1.PodScheduled || cond.Status != corev1.ConditionFalse || cond.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		if strings.Contains(cond.Message, "Insufficient cpu") || strings.Contains(cond.Message, "Insufficient memory") {
+			return cond.Message
+		}
+	}
+	return ""
+}
+
+// percent returns part as a percentage of total, or 0 without a total.
+func percent(part, total int64) int64 {
+	return permille(part, total) / 10
+}
+
+// permille returns part in thousandths of total, or 0 without a total.
+func permille(part, total int64) int64 {
+	if total <= 0 {
+		return 0
+	}
+	return part * 1000 / total
+}
@@ -0,0 +1,88 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+// kudevPod is a testPod labelled as a kudev app.
+func kudevPod(name, namespace, app, node, cpu, mem string) *corev1.Pod {
+	pod := testPod(name, namespace, app, node, cpu, mem)
+	pod.Labels[LabelManagedBy] = "kudev"
+	pod.Labels[LabelInstance] = app
+	return pod
+}
+
+func TestCheckBudget(t *testing.T) {
+	pending := kudevPod("api-3", "dev", "api", "", "1", "256Mi")
+	pending.Status = corev1.PodStatus{
+		Phase: corev1.PodPending,
+		Conditions: []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: "0/1 nodes are available: 1 Insufficient cpu.",
+		}},
+	}
+	finished := testPod("job-1", "default", "job", "node-1", "1", "1Gi")
+	finished.Status.Phase = corev1.PodSucceeded
+
+	fakeClient := fake.NewSimpleClientset(
+		testNode("node-1", "4", "4Gi"),
+		kudevPod("api-1", "dev", "api", "node-1", "1", "256Mi"),
+		kudevPod("api-2", "dev", "api", "node-1", "1", "256Mi"),
+		pending,
+		kudevPod("web-1", "dev", "web", "node-1", "500m", "1Gi"),
+		testPod("coredns", "kube-system", "coredns", "node-1", "1", "128Mi"),
+		finished,
+	)
+	kd := NewKubernetesDeployer(fakeClient, nil, &util.MockLogger{})
+
+	budget, err := kd.CheckBudget(context.Background())
+	if err != nil {
+		t.Fatalf("CheckBudget failed: %v", err)
+	}
+
+	if budget.Nodes != 1 || budget.CPUPercent() != 87 || budget.MemoryPercent() != 40 {
+		t.Errorf("nodes, cpu, memory = %d, %d%%, %d%%; want 1, 87%%, 40%%", budget.Nodes, budget.CPUPercent(), budget.MemoryPercent())
+	}
+	if len(budget.Pending) != 1 || budget.Pending[0].Name != "api-3" || budget.Pending[0].App != "api" {
+		t.Errorf("pending = %+v, want api-3", budget.Pending)
+	}
+	if len(budget.Apps) != 2 || budget.Apps[0].Name != "api" || budget.Apps[0].Pods != 3 {
+		t.Fatalf("apps = %+v, want api (3 pods) first", budget.Apps)
+	}
+
+	alerts := budget.Alerts(85)
+	if len(alerts) != 2 || !strings.Contains(alerts[0], "87% of allocatable CPU") || !strings.Contains(alerts[1], "api-3 is Pending") {
+		t.Errorf("alerts = %q", alerts)
+	}
+	if alerts := budget.Alerts(90); len(alerts) != 1 {
+		t.Errorf("alerts at 90%% = %q, want only the Pending pod", alerts)
+	}
+
+	suggestions := budget.Suggestions()
+	if len(suggestions) != 2 ||
+		!strings.HasPrefix(suggestions[0], "lower spec.replicas of api (3 pods") ||
+		!strings.HasPrefix(suggestions[1], "lower spec.resources.requests of web") {
+		t.Errorf("suggestions = %q", suggestions)
+	}
+}
+
+func TestCheckBudget_Empty(t *testing.T) {
+	kd := NewKubernetesDeployer(fake.NewSimpleClientset(), nil, &util.MockLogger{})
+
+	budget, err := kd.CheckBudget(context.Background())
+	if err != nil {
+		t.Fatalf("CheckBudget failed: %v", err)
+	}
+	if alerts := budget.Alerts(85); len(alerts) != 0 {
+		t.Errorf("alerts without nodes = %q, want none", alerts)
+	}
+}
@@ -0,0 +1,90 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultCleanupResources are the resource types, beyond the Deployment,
+// Service and PodDisruptionBudget, that Delete and DeleteByLabels remove
+// when they carry the kudev labels.
+var DefaultCleanupResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "configmaps"},
+	{Version: "v1", Resource: "secrets"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"},
+}
+
+// SetDynamicClient enables cleanup of the resource types in
+// CleanupResources. Without it only the typed resources are deleted.
+func (kd *KubernetesDeployer) SetDynamicClient(client dynamic.Interface) {
+	kd.dynamic = client
+}
+
+// RegisterCleanupResource adds a resource type to clean up, so Delete
+// keeps up with new kinds kudev generates.
+func (kd *KubernetesDeployer) RegisterCleanupResource(gvr schema.GroupVersionResource) {
+	for _, existing := range kd.cleanup {
+		if existing == gvr {
+			return
+		}
+	}
+	kd.cleanup = append(kd.cleanup, gvr)
+}
+
+// CleanupResources returns the resource types removed through the dynamic client.
+func (kd *KubernetesDeployer) CleanupResources() []schema.GroupVersionResource {
+	return append([]schema.GroupVersionResource{}, kd.cleanup...)
+}
+
+// listCleanup returns the objects of every cleanup resource type that match
+// selector. Resource types the cluster does not serve are skipped.
+func (kd *KubernetesDeployer) listCleanup(ctx context.Context, namespace, selector string) ([]ManagedResource, error) {
+	if kd.dynamic == nil {
+		return nil, nil
+	}
+
+	var resources []ManagedResource
+	for _, gvr := range kd.cleanup {
+		list, err := kd.dynamic.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				kd.logger.Debug("resource type not served, skipping cleanup", "resource", gvr.String())
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+
+		for _, item := range list.Items {
+			kind := item.GetKind()
+			if kind == "" {
+				kind = gvr.Resource
+			}
+			resources = append(resources, ManagedResource{Kind: kind, Name: item.GetName(), Resource: gvr})
+		}
+	}
+	return resources, nil
+}
+
+// deleteCleanup deletes one object found by listCleanup (idempotent).
+func (kd *KubernetesDeployer) deleteCleanup(ctx context.Context, r ManagedResource, namespace string) error {
+	if kd.dynamic == nil {
+		return fmt.Errorf("no dynamic client to delete %s", r)
+	}
+
+	err := kd.dynamic.Resource(r.Resource).Namespace(namespace).Delete(ctx, r.Name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s: %w", r, err)
+	}
+
+	kd.logger.Info("resource deleted",
+		"resource", r.String(),
+		"namespace", namespace,
+	)
+	return nil
+}
@@ -0,0 +1,117 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func newCleanupTestDeployer(objects ...runtime.Object) (*KubernetesDeployer, *dynamicfake.FakeDynamicClient) {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range DefaultCleanupResources {
+		listKinds[gvr] = "List"
+	}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+
+	kd := NewKubernetesDeployer(fake.NewSimpleClientset(), nil, &util.MockLogger{})
+	kd.SetDynamicClient(dyn)
+	return kd, dyn
+}
+
+func configMap(name string, labels map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetName(name)
+	u.SetNamespace("default")
+	u.SetLabels(labels)
+	return u
+}
+
+func TestDelete_CleansUpLabelledResources(t *testing.T) {
+	kd, dyn := newCleanupTestDeployer(
+		configMap("myapp-config", map[string]string{"app": "myapp", "managed-by": "kudev"}),
+		configMap("other-config", map[string]string{"app": "other", "managed-by": "kudev"}),
+		configMap("user-config", map[string]string{"app": "myapp"}),
+	)
+	ctx := context.Background()
+
+	if err := kd.Delete(ctx, "myapp", "default"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	list, err := dyn.Resource(configMapsGVR).Namespace("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := map[string]bool{}
+	for _, item := range list.Items {
+		remaining[item.GetName()] = true
+	}
+	if remaining["myapp-config"] {
+		t.Error("myapp-config should be deleted")
+	}
+	if !remaining["other-config"] || !remaining["user-config"] {
+		t.Errorf("only the app's kudev resources should be deleted, remaining: %v", remaining)
+	}
+}
+
+func TestListManaged_IncludesCleanupResources(t *testing.T) {
+	kd, _ := newCleanupTestDeployer(
+		configMap("myapp-config", map[string]string{"app": "myapp", "managed-by": "kudev"}),
+	)
+	ctx := context.Background()
+
+	resources, err := kd.ListManaged(ctx, "default")
+	if err != nil {
+		t.Fatalf("ListManaged failed: %v", err)
+	}
+	if len(resources) != 1 || resources[0].String() != "configmap/myapp-config" {
+		t.Fatalf("ListManaged() = %v, want [configmap/myapp-config]", resources)
+	}
+
+	if err := kd.DeleteManaged(ctx, "default", resources, nil); err != nil {
+		t.Fatalf("DeleteManaged failed: %v", err)
+	}
+	if resources, _ := kd.ListManaged(ctx, "default"); len(resources) != 0 {
+		t.Errorf("resources left after DeleteManaged: %v", resources)
+	}
+}
+
+func TestRegisterCleanupResource(t *testing.T) {
+	kd := NewKubernetesDeployer(fake.NewSimpleClientset(), nil, &util.MockLogger{})
+	cronJobs := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+
+	kd.RegisterCleanupResource(cronJobs)
+	kd.RegisterCleanupResource(cronJobs)
+
+	got := kd.CleanupResources()
+	if len(got) != len(DefaultCleanupResources)+1 || got[len(got)-1] != cronJobs {
+		t.Errorf("CleanupResources() = %v, want defaults plus cronjobs once", got)
+	}
+}
+
+func TestListApp(t *testing.T) {
+	kd, _ := newCleanupTestDeployer(
+		configMap("myapp-config", map[string]string{"app": "myapp", "managed-by": "kudev"}),
+		configMap("other-config", map[string]string{"app": "other", "managed-by": "kudev"}),
+	)
+
+	resources, err := kd.ListApp(context.Background(), "myapp", "default")
+	if err != nil {
+		t.Fatalf("ListApp failed: %v", err)
+	}
+	if len(resources) != 1 || resources[0].String() != "configmap/myapp-config" {
+		t.Errorf("ListApp() = %v, want [configmap/myapp-config]", resources)
+	}
+}
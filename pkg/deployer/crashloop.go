@@ -0,0 +1,151 @@
+package deployer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// crashLogLines is how many lines of the crashed container's log are reported.
+const crashLogLines int64 = 20
+
+// CrashLoopOptions configures crash-loop detection after a deploy.
+type CrashLoopOptions struct {
+	// Restarts is the restart count at which a pod counts as crash-looping.
+	Restarts int32
+
+	// Window is how long after the deploy pods are observed.
+	Window time.Duration
+
+	// PollInterval is the time between checks (default 2s).
+	PollInterval time.Duration
+}
+
+// CrashReport describes a freshly deployed pod that keeps restarting.
+type CrashReport struct {
+	PodName  string
+	Restarts int32
+
+	// Reason is the last termination reason (e.g. "Error", "OOMKilled").
+	Reason   string
+	ExitCode int32
+	Message  string
+
+	// Logs are the last lines logged by the crashed container.
+	Logs []string
+}
+
+// CrashDetector is implemented by deployers that can watch a rollout
+// for crash loops.
+type CrashDetector interface {
+	DetectCrashLoop(ctx context.Context, appName, namespace, imageRef string, opts CrashLoopOptions) (*CrashReport, error)
+}
+
+// DetectCrashLoop watches the pods running imageRef for opts.Window and
+// returns a report as soon as one restarts opts.Restarts times.
+// Returns nil when the window passes without a crash loop.
+func (kd *KubernetesDeployer) DetectCrashLoop(ctx context.Context, appName, namespace, imageRef string, opts CrashLoopOptions) (*CrashReport, error) {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	deadline := kd.clock.Now().Add(opts.Window)
+	selector := labels.SelectorFromSet(labels.Set{"app": appName}).String()
+
+	for {
+		pods, err := kd.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			status := containerStatusFor(pod, imageRef)
+			if status == nil || status.RestartCount < opts.Restarts {
+				continue
+			}
+			return kd.crashReport(ctx, pod, status), nil
+		}
+
+		if kd.clock.Now().After(deadline) {
+			return nil, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-kd.clock.After(interval):
+		}
+	}
+}
+
+// containerStatusFor returns the status of the container running imageRef.
+// Pods of older rollouts don't match and are ignored.
+func containerStatusFor(pod *corev1.Pod, imageRef string) *corev1.ContainerStatus {
+	for _, c := range pod.Spec.Containers {
+		if c.Image != imageRef {
+			continue
+		}
+		for i := range pod.Status.ContainerStatuses {
+			if pod.Status.ContainerStatuses[i].Name == c.Name {
+				return &pod.Status.ContainerStatuses[i]
+			}
+		}
+	}
+	return nil
+}
+
+// crashReport collects the termination reason and the previous
+// container's last log lines. Log errors leave Logs empty.
+func (kd *KubernetesDeployer) crashReport(ctx context.Context, pod *corev1.Pod, status *corev1.ContainerStatus) *CrashReport {
+	report := &CrashReport{
+		PodName:  pod.Name,
+		Restarts: status.RestartCount,
+	}
+
+	if t := status.LastTerminationState.Terminated; t != nil {
+		report.Reason = t.Reason
+		report.ExitCode = t.ExitCode
+		report.Message = t.Message
+	} else if w := status.State.Waiting; w != nil {
+		report.Reason = w.Reason
+		report.Message = w.Message
+	}
+
+	tail := crashLogLines
+	req := kd.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: status.Name,
+		Previous:  true,
+		TailLines: &tail,
+	})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		kd.logger.Debug("failed to fetch crash logs", "pod", pod.Name, "error", err)
+		return report
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		report.Logs = append(report.Logs, scanner.Text())
+	}
+	return report
+}
+
+// DetectCrashLoop delegates to the wrapped deployer.
+func (bg *BlueGreenDeployer) DetectCrashLoop(ctx context.Context, appName, namespace, imageRef string, opts CrashLoopOptions) (*CrashReport, error) {
+	return bg.kd.DetectCrashLoop(ctx, appName, namespace, imageRef, opts)
+}
+
+// Ensure both deployers support crash-loop detection
+var (
+	_ CrashDetector = (*KubernetesDeployer)(nil)
+	_ CrashDetector = (*BlueGreenDeployer)(nil)
+)
@@ -0,0 +1,103 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func crashPod(name, image string, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-app"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "test-app", Image: image}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "test-app",
+				RestartCount: restarts,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1},
+				},
+			}},
+		},
+	}
+}
+
+func TestDetectCrashLoop(t *testing.T) {
+	const image = "test-app:kudev-12345678"
+
+	tests := []struct {
+		name       string
+		pods       []*corev1.Pod
+		wantReport bool
+	}{
+		{
+			name:       "new pod crash-looping",
+			pods:       []*corev1.Pod{crashPod("new", image, 3)},
+			wantReport: true,
+		},
+		{
+			name: "below threshold",
+			pods: []*corev1.Pod{crashPod("new", image, 2)},
+		},
+		{
+			name: "old rollout ignored",
+			pods: []*corev1.Pod{crashPod("old", "test-app:kudev-00000000", 10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			for _, p := range tt.pods {
+				_, _ = fakeClient.CoreV1().Pods("default").Create(context.Background(), p, metav1.CreateOptions{})
+			}
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+			report, err := kd.DetectCrashLoop(context.Background(), "test-app", "default", image, CrashLoopOptions{
+				Restarts:     3,
+				Window:       10 * time.Millisecond,
+				PollInterval: time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("DetectCrashLoop failed: %v", err)
+			}
+
+			if !tt.wantReport {
+				if report != nil {
+					t.Errorf("expected no report, got %+v", report)
+				}
+				return
+			}
+
+			if report == nil {
+				t.Fatal("expected a crash report")
+			}
+			if report.PodName != "new" || report.Restarts != 3 {
+				t.Errorf("report = %+v", report)
+			}
+			if report.Reason != "Error" || report.ExitCode != 1 {
+				t.Errorf("reason = %q (exit %d), want Error (exit 1)", report.Reason, report.ExitCode)
+			}
+			if len(report.Logs) == 0 {
+				t.Error("expected last logs in report")
+			}
+		})
+	}
+}
@@ -5,10 +5,12 @@ package deployer
 import (
 	"context"
 	"fmt"
-	"time"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // Delete removes the deployment and associated service.
@@ -27,11 +29,35 @@ func (kd *KubernetesDeployer) Delete(ctx context.Context, appName, namespace str
 		deleteErrors = append(deleteErrors, fmt.Sprintf("deployment: %v", err))
 	}
 
+	// Delete blue/green slots (only exist after 'kudev watch --blue-green')
+	if err := kd.deleteColorSlots(ctx, appName, namespace); err != nil {
+		deleteErrors = append(deleteErrors, fmt.Sprintf("deployment: %v", err))
+	}
+
 	// Delete Service
 	if err := kd.deleteService(ctx, appName, namespace); err != nil {
 		deleteErrors = append(deleteErrors, fmt.Sprintf("service: %v", err))
 	}
 
+	// Delete PodDisruptionBudget (only exists with spec.zeroDowntime)
+	if err := kd.deletePDB(ctx, appName, namespace); err != nil {
+		deleteErrors = append(deleteErrors, fmt.Sprintf("pdb: %v", err))
+	}
+
+	// Delete ConfigMaps, Secrets, Ingresses, ... carrying the app's labels
+	extra, err := kd.listCleanup(ctx, namespace, labels.SelectorFromSet(labels.Set{
+		"app":        appName,
+		"managed-by": "kudev",
+	}).String())
+	if err != nil {
+		deleteErrors = append(deleteErrors, err.Error())
+	}
+	for _, r := range extra {
+		if err := kd.deleteCleanup(ctx, r, namespace); err != nil {
+			deleteErrors = append(deleteErrors, err.Error())
+		}
+	}
+
 	if len(deleteErrors) > 0 {
 		return fmt.Errorf("deletion errors: %v", deleteErrors)
 	}
@@ -99,6 +125,116 @@ func (kd *KubernetesDeployer) deleteService(ctx context.Context, name, namespace
 	return nil
 }
 
+// ManagedResource is a kudev-managed object found by ListManaged.
+type ManagedResource struct {
+	// Kind is the object kind (Deployment, Service, PodDisruptionBudget).
+	Kind string
+
+	// Name is the object name.
+	Name string
+
+	// Resource is set for resource types deleted through the dynamic
+	// client (see CleanupResources).
+	Resource schema.GroupVersionResource
+}
+
+// String returns the resource in kubectl form, e.g. "deployment/myapp".
+func (r ManagedResource) String() string {
+	return strings.ToLower(r.Kind) + "/" + r.Name
+}
+
+// ListManaged returns every resource with the kudev managed-by label in
+// namespace, Deployments first, then the cleanup resource types.
+func (kd *KubernetesDeployer) ListManaged(ctx context.Context, namespace string) ([]ManagedResource, error) {
+	return kd.listManaged(ctx, namespace, "managed-by=kudev")
+}
+
+// ListApp returns the resources of one app that Delete removes, including
+// blue/green slots, in the same order as ListManaged.
+func (kd *KubernetesDeployer) ListApp(ctx context.Context, appName, namespace string) ([]ManagedResource, error) {
+	return kd.listManaged(ctx, namespace, labels.SelectorFromSet(labels.Set{
+		"app":        appName,
+		"managed-by": "kudev",
+	}).String())
+}
+
+// listManaged lists the deletable resources matching selector.
+func (kd *KubernetesDeployer) listManaged(ctx context.Context, namespace, selector string) ([]ManagedResource, error) {
+	opts := metav1.ListOptions{LabelSelector: selector}
+	var resources []ManagedResource
+
+	deployments, err := kd.clientset.AppsV1().Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		resources = append(resources, ManagedResource{Kind: "Deployment", Name: d.Name})
+	}
+
+	services, err := kd.clientset.CoreV1().Services(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, svc := range services.Items {
+		resources = append(resources, ManagedResource{Kind: "Service", Name: svc.Name})
+	}
+
+	pdbs, err := kd.clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod disruption budgets: %w", err)
+	}
+	for _, pdb := range pdbs.Items {
+		resources = append(resources, ManagedResource{Kind: "PodDisruptionBudget", Name: pdb.Name})
+	}
+
+	extra, err := kd.listCleanup(ctx, namespace, opts.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(resources, extra...), nil
+}
+
+// DeleteManaged deletes resources (as returned by ListManaged) one by one,
+// calling progress (if not nil) after each. Already deleted resources count
+// as deleted. Deletion continues past failures; all of them are returned.
+func (kd *KubernetesDeployer) DeleteManaged(ctx context.Context, namespace string, resources []ManagedResource, progress func(ManagedResource, error)) error {
+	var deleteErrors []string
+
+	for _, r := range resources {
+		var err error
+		switch r.Kind {
+		case "Deployment":
+			err = kd.deleteDeployment(ctx, r.Name, namespace)
+		case "Service":
+			err = kd.deleteService(ctx, r.Name, namespace)
+		case "PodDisruptionBudget":
+			err = kd.clientset.PolicyV1().PodDisruptionBudgets(namespace).Delete(ctx, r.Name, metav1.DeleteOptions{})
+			if errors.IsNotFound(err) {
+				err = nil
+			}
+		default:
+			if r.Resource.Resource == "" {
+				err = fmt.Errorf("unsupported kind %s", r.Kind)
+				break
+			}
+			err = kd.deleteCleanup(ctx, r, namespace)
+		}
+
+		if progress != nil {
+			progress(r, err)
+		}
+		if err != nil {
+			deleteErrors = append(deleteErrors, fmt.Sprintf("%s: %v", r, err))
+		}
+	}
+
+	if len(deleteErrors) > 0 {
+		return fmt.Errorf("deletion errors: %v", deleteErrors)
+	}
+	return nil
+}
+
 // DeleteByLabels removes all resources with the kudev managed-by label.
 // Useful for cleanup of orphaned resources.
 func (kd *KubernetesDeployer) DeleteByLabels(ctx context.Context, namespace string) error {
@@ -106,31 +242,13 @@ func (kd *KubernetesDeployer) DeleteByLabels(ctx context.Context, namespace stri
 		"namespace", namespace,
 	)
 
-	labelSelector := "managed-by=kudev"
-
-	// Delete Deployments
-	deployments := kd.clientset.AppsV1().Deployments(namespace)
-	if err := deployments.DeleteCollection(ctx,
-		metav1.DeleteOptions{},
-		metav1.ListOptions{LabelSelector: labelSelector},
-	); err != nil && !errors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete deployments: %w", err)
-	}
-
-	// Delete Services
-	services := kd.clientset.CoreV1().Services(namespace)
-	serviceList, err := services.List(ctx, metav1.ListOptions{
-		LabelSelector: labelSelector,
-	})
+	resources, err := kd.ListManaged(ctx, namespace)
 	if err != nil {
-		return fmt.Errorf("failed to list services: %w", err)
+		return err
 	}
 
-	for _, svc := range serviceList.Items {
-		if err := services.Delete(ctx, svc.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
-			return fmt.Errorf("failed to delete service %s: %w", svc.Name, err)
-		}
-		kd.logger.Info("service deleted", "name", svc.Name)
+	if err := kd.DeleteManaged(ctx, namespace, resources, nil); err != nil {
+		return err
 	}
 
 	kd.logger.Info("all kudev resources deleted",
@@ -140,39 +258,55 @@ func (kd *KubernetesDeployer) DeleteByLabels(ctx context.Context, namespace stri
 	return nil
 }
 
-// WaitForDeletion waits until deployment is fully deleted.
-func (kd *KubernetesDeployer) WaitForDeletion(ctx context.Context, appName, namespace string, timeout time.Duration) error {
-	deadline := time.Now().Add(timeout)
+// WaitForDeletion waits until the app's deployments are fully deleted,
+// including blue/green slots, and their pods have terminated.
+func (kd *KubernetesDeployer) WaitForDeletion(ctx context.Context, opts WaitOptions) error {
+	deadline := kd.clock.Now().Add(opts.EffectiveTimeout())
+	set := labels.Set{"managed-by": "kudev"}
+	if opts.AppName != "" {
+		set["app"] = opts.AppName
+	}
+	selector := labels.SelectorFromSet(set)
 
 	for {
-		if time.Now().After(deadline) {
+		if kd.clock.Now().After(deadline) {
 			return fmt.Errorf("timeout waiting for deletion")
 		}
 
-		_, err := kd.clientset.AppsV1().Deployments(namespace).Get(
-			ctx, appName, metav1.GetOptions{},
-		)
+		deployments, err := kd.clientset.AppsV1().Deployments(opts.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("error checking deployment: %w", err)
+		}
 
-		if errors.IsNotFound(err) {
+		// Pods outlive their deployment until the garbage collector and
+		// the kubelet are done with them
+		pods, err := kd.clientset.CoreV1().Pods(opts.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("error checking pods: %w", err)
+		}
+
+		if len(deployments.Items) == 0 && len(pods.Items) == 0 {
 			kd.logger.Info("deployment fully deleted",
-				"app", appName,
-				"namespace", namespace,
+				"app", opts.AppName,
+				"namespace", opts.Namespace,
 			)
 			return nil
 		}
 
-		if err != nil {
-			return fmt.Errorf("error checking deployment: %w", err)
-		}
-
 		kd.logger.Debug("waiting for deletion",
-			"app", appName,
+			"app", opts.AppName,
+			"remaining", len(deployments.Items),
+			"pods", len(pods.Items),
 		)
 
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(2 * time.Second):
+		case <-kd.clock.After(opts.EffectivePollInterval()):
 			// Continue polling
 		}
 	}
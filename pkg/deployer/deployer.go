@@ -10,8 +10,12 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/nanaki-93/kudev/pkg/clock"
 	"github.com/nanaki-93/kudev/pkg/logging"
 )
 
@@ -20,6 +24,29 @@ type KubernetesDeployer struct {
 	clientset kubernetes.Interface
 	renderer  *Renderer
 	logger    logging.LoggerInterface
+
+	// recorder archives rendered manifests per deploy (nil disables)
+	recorder ManifestRecorder
+
+	// strategies upserts objects by kind (see Apply)
+	strategies *StrategyRegistry
+
+	// readiness maps spec.readiness.strategy values to checks (see WaitForReady)
+	readiness map[string]ReadinessCheck
+
+	// dynamic deletes the cleanup resource types (nil limits cleanup to
+	// the typed resources)
+	dynamic dynamic.Interface
+
+	// cleanup lists the extra resource types Delete removes (see cleanup.go)
+	cleanup []schema.GroupVersionResource
+
+	// clock paces the wait loops (see SetClock)
+	clock clock.Clock
+
+	// validateServer dry-runs every object before the real apply (see
+	// SetServerValidation)
+	validateServer bool
 }
 
 // NewKubernetesDeployer creates a new deployer.
@@ -28,11 +55,28 @@ func NewKubernetesDeployer(
 	renderer *Renderer,
 	logger logging.LoggerInterface,
 ) *KubernetesDeployer {
-	return &KubernetesDeployer{
-		clientset: clientset,
-		renderer:  renderer,
-		logger:    logger,
+	kd := &KubernetesDeployer{
+		clientset:  clientset,
+		renderer:   renderer,
+		logger:     logging.OrDefault(logger),
+		strategies: NewStrategyRegistry(),
+		cleanup:    append([]schema.GroupVersionResource{}, DefaultCleanupResources...),
+		clock:      clock.Real,
 	}
+	kd.registerDefaultStrategies()
+	kd.registerDefaultReadinessChecks()
+	return kd
+}
+
+// log returns the logger with ctx's values (see logging.WithContext).
+func (kd *KubernetesDeployer) log(ctx context.Context) logging.LoggerInterface {
+	return logging.WithContext(kd.logger, ctx)
+}
+
+// SetClock replaces the clock timing WaitForReady, WaitForDeletion and
+// the other wait loops, so tests can drive them without real sleeps.
+func (kd *KubernetesDeployer) SetClock(c clock.Clock) {
+	kd.clock = clock.OrReal(c)
 }
 
 // Upsert creates or updates deployment and service.
@@ -40,7 +84,19 @@ func (kd *KubernetesDeployer) Upsert(ctx context.Context, opts DeploymentOptions
 	// 1. Prepare template data
 	data := NewTemplateData(opts)
 
-	kd.logger.Info("starting deployment",
+	if err := kd.checkSafety(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	if err := kd.checkEnvSources(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return kd.dryRunUpsert(ctx, opts)
+	}
+
+	kd.log(ctx).Info("starting deployment",
 		"app", data.AppName,
 		"namespace", data.Namespace,
 		"image", data.ImageRef,
@@ -56,28 +112,48 @@ func (kd *KubernetesDeployer) Upsert(ctx context.Context, opts DeploymentOptions
 	if err != nil {
 		return nil, fmt.Errorf("failed to render service: %w", err)
 	}
+	kd.recordManifests(data, opts.Objects)
+	stampExpiry(deployment, opts.Config, kd.clock.Now())
+
+	configHash, err := kd.configHash(data)
+	if err != nil {
+		return nil, err
+	}
+	stampConfigHash(deployment, configHash)
 
 	// 3. Ensure namespace exists
 	if err := kd.ensureNamespace(ctx, data.Namespace); err != nil {
 		return nil, fmt.Errorf("failed to ensure namespace: %w", err)
 	}
 
-	// 4. Upsert Deployment
-	if err := kd.upsertDeployment(ctx, deployment); err != nil {
-		return nil, fmt.Errorf("failed to upsert deployment: %w", err)
+	// 4-5. Upsert extra objects first (pods may need their ConfigMaps),
+	// then the Deployment and Service
+	objects := append([]runtime.Object{}, opts.Objects...)
+	objects = append(objects, deployment, service)
+	if err := kd.validateOnServer(ctx, data, objects); err != nil {
+		return nil, err
+	}
+	if err := kd.Apply(ctx, objects); err != nil {
+		return nil, err
+	}
+
+	// 6. Keep the PodDisruptionBudget in sync with spec.zeroDowntime
+	if err := kd.syncPDB(ctx, data); err != nil {
+		return nil, err
 	}
 
-	// 5. Upsert Service
-	if err := kd.upsertService(ctx, service); err != nil {
-		return nil, fmt.Errorf("failed to upsert service: %w", err)
+	// 7. Leaving blue/green mode: the Service no longer filters by color,
+	// so leftover slots would receive traffic
+	if err := kd.deleteColorSlots(ctx, data.AppName, data.Namespace); err != nil {
+		return nil, err
 	}
 
-	kd.logger.Info("deployment completed successfully",
+	kd.log(ctx).Info("deployment completed successfully",
 		"app", data.AppName,
 		"namespace", data.Namespace,
 	)
 
-	// 6. Return current status
+	// 8. Return current status
 	return kd.Status(ctx, data.AppName, data.Namespace)
 }
 
@@ -94,7 +170,7 @@ func (kd *KubernetesDeployer) upsertDeployment(ctx context.Context, desired *app
 			if err != nil {
 				return fmt.Errorf("failed to create deployment: %w", err)
 			}
-			kd.logger.Info("deployment created",
+			kd.log(ctx).Info("deployment created",
 				"name", desired.Name,
 				"namespace", desired.Namespace,
 			)
@@ -106,34 +182,31 @@ func (kd *KubernetesDeployer) upsertDeployment(ctx context.Context, desired *app
 	// Update existing deployment
 	// Preserve fields that shouldn't change
 	existing.Spec.Replicas = desired.Spec.Replicas
+	existing.Spec.Strategy = desired.Spec.Strategy
 
-	// Update container image and env
-	if len(existing.Spec.Template.Spec.Containers) > 0 &&
-		len(desired.Spec.Template.Spec.Containers) > 0 {
-		existing.Spec.Template.Spec.Containers[0].Image =
-			desired.Spec.Template.Spec.Containers[0].Image
-		existing.Spec.Template.Spec.Containers[0].Env =
-			desired.Spec.Template.Spec.Containers[0].Env
-	}
+	// The pod spec is whatever the template rendered (every container,
+	// volume and pod setting), so custom templates take effect on update
+	existing.Spec.Template.Spec = desired.Spec.Template.Spec
 
-	// Update kudev labels
-	if existing.Labels == nil {
-		existing.Labels = make(map[string]string)
-	}
-	existing.Labels["kudev-hash"] = desired.Labels["kudev-hash"]
+	// Pod template annotations set elsewhere (e.g. kubectl rollout
+	// restart) are kept
+	existing.Spec.Template.Annotations = mergeLabels(existing.Spec.Template.Annotations, desired.Spec.Template.Annotations)
 
-	// Update pod template labels
-	if existing.Spec.Template.Labels == nil {
-		existing.Spec.Template.Labels = make(map[string]string)
-	}
-	existing.Spec.Template.Labels["managed-by"] = "kudev"
+	// Update labels. The selector is immutable and left as created, so
+	// Deployments from older kudev versions keep selecting on app only;
+	// the pod template labels remain a superset of it.
+	existing.Labels = mergeLabels(existing.Labels, desired.Labels)
+	existing.Spec.Template.Labels = mergeLabels(existing.Spec.Template.Labels, desired.Spec.Template.Labels)
+
+	// Annotations set elsewhere (e.g. kudev.io/frozen) are kept
+	existing.Annotations = mergeLabels(existing.Annotations, desired.Annotations)
 
 	_, err = deployments.Update(ctx, existing, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to update deployment: %w", err)
 	}
 
-	kd.logger.Info("deployment updated",
+	kd.log(ctx).Info("deployment updated",
 		"name", desired.Name,
 		"namespace", desired.Namespace,
 	)
@@ -153,7 +226,7 @@ func (kd *KubernetesDeployer) upsertService(ctx context.Context, desired *corev1
 			if err != nil {
 				return fmt.Errorf("failed to create service: %w", err)
 			}
-			kd.logger.Info("service created",
+			kd.log(ctx).Info("service created",
 				"name", desired.Name,
 				"namespace", desired.Namespace,
 			)
@@ -175,7 +248,7 @@ func (kd *KubernetesDeployer) upsertService(ctx context.Context, desired *corev1
 		return fmt.Errorf("failed to update service: %w", err)
 	}
 
-	kd.logger.Info("service updated",
+	kd.log(ctx).Info("service updated",
 		"name", desired.Name,
 		"namespace", desired.Namespace,
 	)
@@ -192,13 +265,18 @@ func (kd *KubernetesDeployer) ensureNamespace(ctx context.Context, namespace str
 
 	namespaces := kd.clientset.CoreV1().Namespaces()
 
-	_, err := namespaces.Get(ctx, namespace, metav1.GetOptions{})
-	if err == nil {
+	existing, err := namespaces.Get(ctx, namespace, metav1.GetOptions{})
+	switch {
+	case err == nil && existing.Status.Phase != corev1.NamespaceTerminating:
 		// Namespace exists
 		return nil
-	}
-
-	if !errors.IsNotFound(err) {
+	case err == nil:
+		// Deleted out-of-band; nothing can be created in it until it is gone
+		kd.log(ctx).Info("namespace is terminating, waiting to re-create it", "name", namespace)
+		if err := kd.waitForNamespaceGone(ctx, namespace); err != nil {
+			return err
+		}
+	case !errors.IsNotFound(err):
 		return fmt.Errorf("failed to check namespace: %w", err)
 	}
 
@@ -221,7 +299,7 @@ func (kd *KubernetesDeployer) ensureNamespace(ctx context.Context, namespace str
 		return fmt.Errorf("failed to create namespace: %w", err)
 	}
 
-	kd.logger.Info("namespace created", "name", namespace)
+	kd.log(ctx).Info("namespace created", "name", namespace)
 	return nil
 }
 
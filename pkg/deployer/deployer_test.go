@@ -4,15 +4,19 @@ package deployer
 
 import (
 	"context"
+	goruntime "runtime"
 	"testing"
+	"time"
 
 	"github.com/nanaki-93/kudev/test/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 
+	"github.com/nanaki-93/kudev/pkg/clock"
 	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/templates"
 )
@@ -58,6 +62,13 @@ func TestUpsert_CreateNew(t *testing.T) {
 		t.Errorf("name = %q, want %q", deployment.Name, "test-app")
 	}
 
+	if deployment.Labels[LabelVersion] != "12345678" || deployment.Labels[LabelManagedBy] != "kudev" {
+		t.Errorf("standard labels missing: %v", deployment.Labels)
+	}
+	if deployment.Spec.Selector.MatchLabels[LabelInstance] != "test-app" {
+		t.Errorf("selector = %v, want it to include %s", deployment.Spec.Selector.MatchLabels, LabelInstance)
+	}
+
 	// Verify service was created
 	service, err := fakeClient.CoreV1().Services("default").Get(
 		context.Background(), "test-app", metav1.GetOptions{},
@@ -94,7 +105,8 @@ func TestUpsert_UpdateExisting(t *testing.T) {
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{"app": "test-app"},
+					Labels:      map[string]string{"app": "test-app"},
+					Annotations: map[string]string{"kubectl.kubernetes.io/restartedAt": "2026-01-01T00:00:00Z"},
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
@@ -102,6 +114,10 @@ func TestUpsert_UpdateExisting(t *testing.T) {
 							Name:  "test-app",
 							Image: "test-app:old-image",
 						},
+						{
+							Name:  "sidecar",
+							Image: "sidecar:old",
+						},
 					},
 				},
 			},
@@ -138,6 +154,12 @@ func TestUpsert_UpdateExisting(t *testing.T) {
 				Namespace:   "default",
 				Replicas:    3, // Changed!
 				ServicePort: 8080,
+				Resources: &config.ResourcesConfig{ // Changed!
+					Limits: config.ResourceValues{Memory: "1Gi"},
+				},
+				Probes: &config.ProbesConfig{ // Changed!
+					Readiness: &config.ProbeConfig{HTTPGet: config.HTTPGetConfig{Path: "/healthz"}},
+				},
 			},
 		},
 		ImageRef:  "test-app:kudev-new-hash", // Changed!
@@ -162,10 +184,39 @@ func TestUpsert_UpdateExisting(t *testing.T) {
 		t.Errorf("image not updated")
 	}
 
+	if got := deployment.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String(); got != "1Gi" {
+		t.Errorf("memory limit = %s, want 1Gi", got)
+	}
+
+	if probe := deployment.Spec.Template.Spec.Containers[0].ReadinessProbe; probe == nil || probe.HTTPGet.Port.IntValue() != 8080 {
+		t.Errorf("readiness probe = %+v, want /healthz on the service port", probe)
+	}
+
+	// The pod spec is replaced by the rendered one, not patched
+	if n := len(deployment.Spec.Template.Spec.Containers); n != 1 {
+		t.Errorf("got %d containers, want the 1 rendered container", n)
+	}
+	if deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] == "" {
+		t.Error("pod template annotations set elsewhere should be kept")
+	}
+
 	if deployment.Labels["kudev-hash"] != "new-hash" {
 		t.Errorf("hash label not updated")
 	}
 
+	// Pods carry the hash so logs/port-forward can find the current rollout
+	if deployment.Spec.Template.Labels["kudev-hash"] != "new-hash" {
+		t.Errorf("pod template hash label not updated")
+	}
+
+	// The selector is immutable; standard labels only go on the pods
+	if len(deployment.Spec.Selector.MatchLabels) != 1 {
+		t.Errorf("selector changed: %v", deployment.Spec.Selector.MatchLabels)
+	}
+	if deployment.Spec.Template.Labels[LabelName] != "test-app" {
+		t.Errorf("pod template labels = %v, want %s", deployment.Spec.Template.Labels, LabelName)
+	}
+
 	// Verify ClusterIP was preserved
 	service, _ := fakeClient.CoreV1().Services("default").Get(
 		context.Background(), "test-app", metav1.GetOptions{},
@@ -239,6 +290,11 @@ func TestStatus_DeploymentExists(t *testing.T) {
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{"app": "test-app"},
 			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test-app", Image: "test-app:kudev-abc12345"}},
+				},
+			},
 		},
 		Status: appsv1.DeploymentStatus{
 			ReadyReplicas: 2,
@@ -293,6 +349,10 @@ func TestStatus_DeploymentExists(t *testing.T) {
 		t.Errorf("hash = %q, want %q", status.ImageHash, "abc12345")
 	}
 
+	if status.Image != "test-app:kudev-abc12345" {
+		t.Errorf("image = %q, want %q", status.Image, "test-app:kudev-abc12345")
+	}
+
 	if !status.IsReady() {
 		t.Error("IsReady() should be true")
 	}
@@ -488,3 +548,180 @@ func TestDeleteByLabels(t *testing.T) {
 		t.Error("other-app should NOT be deleted")
 	}
 }
+
+func TestListAndDeleteManaged(t *testing.T) {
+	managed := map[string]string{"managed-by": "kudev"}
+	fakeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "default", Labels: managed}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "default", Labels: managed}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+	)
+	deployer := NewKubernetesDeployer(fakeClient, nil, &util.MockLogger{})
+	ctx := context.Background()
+
+	resources, err := deployer.ListManaged(ctx, "default")
+	if err != nil {
+		t.Fatalf("ListManaged failed: %v", err)
+	}
+	want := []string{"deployment/app1", "service/app1"}
+	if len(resources) != len(want) {
+		t.Fatalf("ListManaged() = %v, want %v", resources, want)
+	}
+	for i := range want {
+		if resources[i].String() != want[i] {
+			t.Errorf("resource[%d] = %s, want %s", i, resources[i], want[i])
+		}
+	}
+
+	var progress []string
+	err = deployer.DeleteManaged(ctx, "default", resources, func(r ManagedResource, err error) {
+		if err != nil {
+			t.Errorf("%s: %v", r, err)
+		}
+		progress = append(progress, r.String())
+	})
+	if err != nil {
+		t.Fatalf("DeleteManaged failed: %v", err)
+	}
+	if len(progress) != len(want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+
+	// Every kudev app is gone, unmanaged objects stay
+	if err := deployer.WaitForDeletion(ctx, WaitOptions{Namespace: "default", Timeout: 50 * time.Millisecond}); err != nil {
+		t.Errorf("WaitForDeletion() error = %v", err)
+	}
+	if _, err := fakeClient.CoreV1().Services("default").Get(ctx, "other", metav1.GetOptions{}); err != nil {
+		t.Errorf("unmanaged service should not be deleted: %v", err)
+	}
+}
+
+func TestWaitForDeletion(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		wantErr bool
+	}{
+		{name: "nothing left"},
+		{
+			name:    "deployment still present",
+			objects: []runtime.Object{managedDeployment("test-app", "test-app")},
+			wantErr: true,
+		},
+		{
+			name:    "blue/green slot still present",
+			objects: []runtime.Object{managedDeployment("test-app-blue", "test-app")},
+			wantErr: true,
+		},
+		{
+			name:    "pod still terminating",
+			objects: []runtime.Object{managedPod("test-app-abc12", "test-app")},
+			wantErr: true,
+		},
+		{
+			name:    "other apps are ignored",
+			objects: []runtime.Object{managedDeployment("other", "other"), managedPod("other-abc12", "other")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			deployer := NewKubernetesDeployer(fake.NewSimpleClientset(tt.objects...), renderer, &util.MockLogger{})
+
+			err := deployer.WaitForDeletion(context.Background(), WaitOptions{
+				AppName:      "test-app",
+				Namespace:    "default",
+				Timeout:      50 * time.Millisecond,
+				PollInterval: 10 * time.Millisecond,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WaitForDeletion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitForReady_Timeout(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	deployer := NewKubernetesDeployer(fake.NewSimpleClientset(deployment), renderer, &util.MockLogger{})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	deployer.SetClock(clk)
+
+	// Default timeout and poll interval, on the fake clock
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err = deployer.WaitForReady(context.Background(), WaitOptions{
+			AppName:   "test-app",
+			Namespace: "default",
+		})
+	}()
+	drive(clk, DefaultWaitPollInterval, done)
+
+	if err == nil {
+		t.Error("expected timeout for degraded deployment")
+	}
+	if waited := clk.Since(start); waited <= DefaultWaitTimeout || waited > DefaultWaitTimeout+DefaultWaitPollInterval {
+		t.Errorf("gave up after %v, want just over %v", waited, DefaultWaitTimeout)
+	}
+}
+
+// drive advances clk by step whenever the code under test waits on it,
+// until done is closed.
+func drive(clk *clock.Fake, step time.Duration, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if clk.Waiters() > 0 {
+			clk.Advance(step)
+		} else {
+			goruntime.Gosched()
+		}
+	}
+}
+
+func TestWaitOptions_Defaults(t *testing.T) {
+	var opts WaitOptions
+	if opts.EffectiveTimeout() != DefaultWaitTimeout {
+		t.Errorf("EffectiveTimeout() = %v, want %v", opts.EffectiveTimeout(), DefaultWaitTimeout)
+	}
+	if opts.EffectivePollInterval() != DefaultWaitPollInterval {
+		t.Errorf("EffectivePollInterval() = %v, want %v", opts.EffectivePollInterval(), DefaultWaitPollInterval)
+	}
+}
+
+func TestDeployedImageHashes(t *testing.T) {
+	deployment := func(name, namespace string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+	}
+	fakeClient := fake.NewSimpleClientset(
+		deployment("api-blue", "dev", map[string]string{"managed-by": "kudev", "kudev-hash": "11111111"}),
+		deployment("api-green", "dev", map[string]string{"managed-by": "kudev", "kudev-hash": "22222222"}),
+		deployment("web-feature", "team", map[string]string{"managed-by": "kudev", "kudev-hash": "33333333"}),
+		deployment("other", "dev", map[string]string{"kudev-hash": "44444444"}),
+	)
+	deployer := NewKubernetesDeployer(fakeClient, nil, &util.MockLogger{})
+
+	hashes, err := deployer.DeployedImageHashes(context.Background(), "")
+	if err != nil {
+		t.Fatalf("DeployedImageHashes failed: %v", err)
+	}
+	got := map[string]bool{}
+	for _, h := range hashes {
+		got[h] = true
+	}
+	if len(hashes) != 3 || !got["11111111"] || !got["22222222"] || !got["33333333"] {
+		t.Errorf("DeployedImageHashes() = %v, want the three kudev-managed hashes", hashes)
+	}
+}
@@ -0,0 +1,167 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// StatusDryRun is the status returned by a dry-run Upsert.
+const StatusDryRun StatusCode = "DryRun"
+
+// dryRunUpsert sends every object Upsert would apply to the API server
+// with server-side dry-run, so validation and admission errors surface
+// without changing anything. Nothing is deleted and no manifests are
+// recorded.
+func (kd *KubernetesDeployer) dryRunUpsert(ctx context.Context, opts DeploymentOptions) (*DeploymentStatus, error) {
+	data := NewTemplateData(opts)
+
+	deployment, err := kd.renderer.RenderDeployment(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render deployment: %w", err)
+	}
+	service, err := kd.renderer.RenderService(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render service: %w", err)
+	}
+
+	objects := append([]runtime.Object{}, opts.Objects...)
+	objects = append(objects, deployment, service)
+	if data.ZeroDowntime {
+		objects = append(objects, newPDB(data))
+	}
+
+	status := &DeploymentStatus{
+		DeploymentName:  data.AppName,
+		Namespace:       data.Namespace,
+		DesiredReplicas: data.Replicas,
+		ImageHash:       data.ImageHash,
+		Status:          StatusDryRun.String(),
+	}
+
+	// Objects in a namespace that does not exist yet cannot be dry-run
+	created, err := kd.dryRunNamespace(ctx, data.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		status.Message = fmt.Sprintf("namespace %s would be created; its resources were not validated by the server", data.Namespace)
+		return status, nil
+	}
+
+	for _, obj := range objects {
+		if err := kd.dryRunObject(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	status.Message = fmt.Sprintf("%d object(s) accepted by the server (dry run)", len(objects))
+	return status, nil
+}
+
+// SetServerValidation makes Upsert send every object with server-side
+// dry-run before applying any of them, so a webhook denial or API
+// validation error stops the deploy before anything has changed.
+func (kd *KubernetesDeployer) SetServerValidation(enabled bool) {
+	kd.validateServer = enabled
+}
+
+// validateOnServer dry-runs copies of objects, plus the PDB when
+// spec.zeroDowntime is set, if server validation is enabled. The
+// namespace must already exist.
+func (kd *KubernetesDeployer) validateOnServer(ctx context.Context, data TemplateData, objects []runtime.Object) error {
+	if !kd.validateServer {
+		return nil
+	}
+	if data.ZeroDowntime {
+		objects = append(objects[:len(objects):len(objects)], newPDB(data))
+	}
+	for _, obj := range objects {
+		if err := kd.dryRunObject(ctx, obj.DeepCopyObject()); err != nil {
+			return fmt.Errorf("server validation failed: %w", err)
+		}
+	}
+	kd.logger.Debug("objects accepted by server validation", "count", len(objects))
+	return nil
+}
+
+// dryRunNamespace validates creating the namespace when it is missing.
+// Returns true if the namespace does not exist yet.
+func (kd *KubernetesDeployer) dryRunNamespace(ctx context.Context, namespace string) (bool, error) {
+	if namespace == "default" {
+		return false, nil
+	}
+
+	namespaces := kd.clientset.CoreV1().Namespaces()
+	_, err := namespaces.Get(ctx, namespace, metav1.GetOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to check namespace: %w", err)
+	}
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   namespace,
+			Labels: map[string]string{"managed-by": "kudev"},
+		},
+	}
+	if _, err := namespaces.Create(ctx, ns, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+		return false, fmt.Errorf("namespace %s would be rejected: %w", namespace, err)
+	}
+	return true, nil
+}
+
+// dryRunObject creates (or updates, if it exists) obj with server-side
+// dry-run. Kinds without a typed client here are skipped.
+func (kd *KubernetesDeployer) dryRunObject(ctx context.Context, obj runtime.Object) error {
+	create := metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}
+	update := metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}}
+
+	var err error
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		client := kd.clientset.AppsV1().Deployments(o.Namespace)
+		if existing, getErr := client.Get(ctx, o.Name, metav1.GetOptions{}); getErr == nil {
+			o.ResourceVersion = existing.ResourceVersion
+			o.Spec.Selector = existing.Spec.Selector // immutable, kept by Upsert
+			_, err = client.Update(ctx, o, update)
+		} else {
+			_, err = client.Create(ctx, o, create)
+		}
+	case *corev1.Service:
+		client := kd.clientset.CoreV1().Services(o.Namespace)
+		if existing, getErr := client.Get(ctx, o.Name, metav1.GetOptions{}); getErr == nil {
+			o.ResourceVersion = existing.ResourceVersion
+			o.Spec.ClusterIP = existing.Spec.ClusterIP
+			o.Spec.ClusterIPs = existing.Spec.ClusterIPs
+			_, err = client.Update(ctx, o, update)
+		} else {
+			_, err = client.Create(ctx, o, create)
+		}
+	case *policyv1.PodDisruptionBudget:
+		client := kd.clientset.PolicyV1().PodDisruptionBudgets(o.Namespace)
+		if existing, getErr := client.Get(ctx, o.Name, metav1.GetOptions{}); getErr == nil {
+			o.ResourceVersion = existing.ResourceVersion
+			_, err = client.Update(ctx, o, update)
+		} else {
+			_, err = client.Create(ctx, o, create)
+		}
+	default:
+		kd.logger.Debug("no dry-run support for object, skipping", "type", fmt.Sprintf("%T", obj))
+		return nil
+	}
+
+	if err != nil {
+		gk, _ := KindOf(obj)
+		return explainRejection(obj, fmt.Errorf("%s would be rejected: %w", gk.Kind, err))
+	}
+	return nil
+}
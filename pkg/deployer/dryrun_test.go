@@ -0,0 +1,177 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestUpsert_DryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		namespace   string
+		wantCreated []string
+		wantMessage string
+	}{
+		{
+			name:        "existing namespace",
+			namespace:   "default",
+			wantCreated: []string{"deployments", "services"},
+			wantMessage: "2 object(s) accepted",
+		},
+		{
+			name:        "missing namespace",
+			namespace:   "dev",
+			wantCreated: []string{"namespaces"},
+			wantMessage: "namespace dev would be created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+			cfg := &config.DeploymentConfig{
+				Metadata: config.MetadataConfig{Name: "test-app"},
+				Spec: config.SpecConfig{
+					Namespace:   tt.namespace,
+					Replicas:    1,
+					ServicePort: 8080,
+				},
+			}
+
+			status, err := kd.Upsert(context.Background(), DeploymentOptions{
+				Config:   cfg,
+				ImageRef: "test-app:kudev-12345678",
+				DryRun:   true,
+			})
+			if err != nil {
+				t.Fatalf("Upsert failed: %v", err)
+			}
+			if status.Status != StatusDryRun.String() || !strings.Contains(status.Message, tt.wantMessage) {
+				t.Errorf("status = %s %q, want DryRun containing %q", status.Status, status.Message, tt.wantMessage)
+			}
+
+			var created []string
+			for _, action := range fakeClient.Actions() {
+				switch a := action.(type) {
+				case k8stesting.CreateActionImpl:
+					if len(a.CreateOptions.DryRun) != 1 || a.CreateOptions.DryRun[0] != metav1.DryRunAll {
+						t.Errorf("create of %s without dry-run", a.Resource.Resource)
+					}
+					created = append(created, a.Resource.Resource)
+				case k8stesting.UpdateActionImpl, k8stesting.DeleteActionImpl:
+					t.Errorf("unexpected %s of %s in dry run", action.GetVerb(), action.GetResource().Resource)
+				}
+			}
+			if strings.Join(created, ",") != strings.Join(tt.wantCreated, ",") {
+				t.Errorf("created %v, want %v", created, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestUpsert_ServerValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		deny         bool
+		wantErr      string
+		wantDryRuns  []string
+		wantCreated  []string
+		zeroDowntime bool
+	}{
+		{
+			name:        "accepted",
+			wantDryRuns: []string{"deployments", "services"},
+			wantCreated: []string{"deployments", "services"},
+		},
+		{
+			name:         "accepted with pdb",
+			zeroDowntime: true,
+			wantDryRuns:  []string{"deployments", "services", "poddisruptionbudgets"},
+			wantCreated:  []string{"deployments", "services", "poddisruptionbudgets"},
+		},
+		{
+			name:        "denied by webhook",
+			deny:        true,
+			wantErr:     "server validation failed: Deployment would be rejected",
+			wantDryRuns: []string{"deployments"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			// Like the API server, answer dry-runs without storing anything
+			fakeClient.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				a := action.(k8stesting.CreateActionImpl)
+				if len(a.CreateOptions.DryRun) == 0 {
+					return false, nil, nil
+				}
+				if tt.deny && a.Resource.Resource == "deployments" {
+					return true, nil, errors.New(`admission webhook "validation.gatekeeper.sh" denied the request`)
+				}
+				return true, a.Object, nil
+			})
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+			kd.SetServerValidation(true)
+
+			cfg := &config.DeploymentConfig{
+				Metadata: config.MetadataConfig{Name: "test-app"},
+				Spec: config.SpecConfig{
+					Namespace:    "default",
+					Replicas:     2,
+					ServicePort:  8080,
+					ZeroDowntime: tt.zeroDowntime,
+				},
+			}
+
+			_, err := kd.Upsert(context.Background(), DeploymentOptions{
+				Config:   cfg,
+				ImageRef: "test-app:kudev-12345678",
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Upsert error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Upsert failed: %v", err)
+			}
+
+			var dryRuns, created []string
+			for _, action := range fakeClient.Actions() {
+				a, ok := action.(k8stesting.CreateActionImpl)
+				if !ok {
+					continue
+				}
+				if len(a.CreateOptions.DryRun) > 0 {
+					if len(created) > 0 {
+						t.Errorf("dry-run of %s after a real create", a.Resource.Resource)
+					}
+					dryRuns = append(dryRuns, a.Resource.Resource)
+				} else {
+					created = append(created, a.Resource.Resource)
+				}
+			}
+			if strings.Join(dryRuns, ",") != strings.Join(tt.wantDryRuns, ",") {
+				t.Errorf("dry-runs %v, want %v", dryRuns, tt.wantDryRuns)
+			}
+			if strings.Join(created, ",") != strings.Join(tt.wantCreated, ",") {
+				t.Errorf("created %v, want %v", created, tt.wantCreated)
+			}
+		})
+	}
+}
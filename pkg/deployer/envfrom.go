@@ -0,0 +1,142 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+// Kinds of env var sources.
+const (
+	EnvSourceConfigMap = "configmap"
+	EnvSourceSecret    = "secret"
+)
+
+// EnvSource is a ConfigMap or Secret key referenced by an env var.
+type EnvSource struct {
+	// Env is the environment variable name.
+	Env string
+
+	// Kind is EnvSourceConfigMap or EnvSourceSecret.
+	Kind string
+
+	// Name and Key select the referenced data.
+	Name string
+	Key  string
+
+	// Optional references may be missing.
+	Optional bool
+
+	// Problem explains why the source is unusable (empty when it is fine).
+	Problem string
+}
+
+// EnvSources lists the ConfigMap and Secret references in cfg's env vars.
+func EnvSources(cfg *config.DeploymentConfig) []EnvSource {
+	var sources []EnvSource
+	for _, env := range cfg.Spec.Env {
+		if env.ValueFrom == nil {
+			continue
+		}
+		if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+			sources = append(sources, EnvSource{Env: env.Name, Kind: EnvSourceConfigMap, Name: ref.Name, Key: ref.Key, Optional: ref.Optional})
+		}
+		if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+			sources = append(sources, EnvSource{Env: env.Name, Kind: EnvSourceSecret, Name: ref.Name, Key: ref.Key, Optional: ref.Optional})
+		}
+	}
+	return sources
+}
+
+// CheckEnvSources looks up every ConfigMap and Secret referenced by env
+// valueFrom and fills in Problem for missing objects or keys.
+// Secret values are never read beyond checking that the key exists.
+func (kd *KubernetesDeployer) CheckEnvSources(ctx context.Context, cfg *config.DeploymentConfig) ([]EnvSource, error) {
+	sources := EnvSources(cfg)
+	for i := range sources {
+		if err := kd.checkEnvSource(ctx, &sources[i], cfg.Spec.Namespace); err != nil {
+			return nil, err
+		}
+	}
+	return sources, nil
+}
+
+// checkEnvSource fills in src.Problem when its object or key is missing.
+func (kd *KubernetesDeployer) checkEnvSource(ctx context.Context, src *EnvSource, namespace string) error {
+	keys, err := kd.sourceKeys(ctx, src.Kind, src.Name, namespace)
+	if errors.IsNotFound(err) {
+		src.Problem = fmt.Sprintf("%s %q not found in namespace %q", src.Kind, src.Name, namespace)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("env %s: failed to get %s %q: %w", src.Env, src.Kind, src.Name, err)
+	}
+	if !keys[src.Key] {
+		src.Problem = fmt.Sprintf("%s %q has no key %q", src.Kind, src.Name, src.Key)
+	}
+	return nil
+}
+
+// sourceKeys returns the data keys of a ConfigMap or Secret.
+func (kd *KubernetesDeployer) sourceKeys(ctx context.Context, kind, name, namespace string) (map[string]bool, error) {
+	keys := make(map[string]bool)
+
+	if kind == EnvSourceSecret {
+		secret, err := kd.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for k := range secret.Data {
+			keys[k] = true
+		}
+		for k := range secret.StringData {
+			keys[k] = true
+		}
+		return keys, nil
+	}
+
+	cm, err := kd.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for k := range cm.Data {
+		keys[k] = true
+	}
+	for k := range cm.BinaryData {
+		keys[k] = true
+	}
+	return keys, nil
+}
+
+// checkEnvSources fails the deploy when a required ConfigMap or Secret
+// reference is broken, instead of leaving pods stuck in
+// CreateContainerConfigError. Optional references are not looked up, and
+// a reference kudev may not read (restricted RBAC) is only logged.
+func (kd *KubernetesDeployer) checkEnvSources(ctx context.Context, opts DeploymentOptions) error {
+	for _, src := range EnvSources(opts.Config) {
+		if src.Optional {
+			continue
+		}
+		err := kd.checkEnvSource(ctx, &src, opts.Config.Spec.Namespace)
+		if errors.IsForbidden(err) {
+			kd.logger.Warn("cannot check env var source",
+				"env", src.Env,
+				"kind", src.Kind,
+				"name", src.Name,
+				"error", err,
+			)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if src.Problem != "" {
+			return fmt.Errorf("env %s: %s", src.Env, src.Problem)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestCheckEnvSources(t *testing.T) {
+	appConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "default"},
+		Data:       map[string]string{"log_level": "debug"},
+	}
+
+	fromConfigMap := func(name, key string, optional bool) []config.EnvVar {
+		return []config.EnvVar{{
+			Name: "LOG_LEVEL",
+			ValueFrom: &config.EnvVarSource{
+				ConfigMapKeyRef: &config.ConfigMapKeySelector{Name: name, Key: key, Optional: optional},
+			},
+		}}
+	}
+
+	dbCredentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("s3cret")},
+	}
+
+	fromSecret := func(name, key string) []config.EnvVar {
+		return []config.EnvVar{{
+			Name: "DB_PASSWORD",
+			ValueFrom: &config.EnvVarSource{
+				SecretKeyRef: &config.SecretKeySelector{Name: name, Key: key},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		env     []config.EnvVar
+		objects []runtime.Object
+		wantErr string
+	}{
+		{name: "plain values", env: []config.EnvVar{{Name: "A", Value: "b"}}},
+		{name: "existing key", env: fromConfigMap("app-config", "log_level", false), objects: []runtime.Object{appConfig}},
+		{name: "missing configmap", env: fromConfigMap("app-config", "log_level", false), wantErr: `configmap "app-config" not found`},
+		{name: "missing key", env: fromConfigMap("app-config", "level", false), objects: []runtime.Object{appConfig}, wantErr: `has no key "level"`},
+		{name: "optional reference", env: fromConfigMap("app-config", "log_level", true)},
+		{name: "existing secret key", env: fromSecret("db-credentials", "password"), objects: []runtime.Object{dbCredentials}},
+		{name: "missing secret", env: fromSecret("db-credentials", "password"), wantErr: `secret "db-credentials" not found`},
+		{name: "missing secret key", env: fromSecret("db-credentials", "user"), objects: []runtime.Object{dbCredentials}, wantErr: `has no key "user"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			deployer := NewKubernetesDeployer(fake.NewSimpleClientset(tt.objects...), renderer, &util.MockLogger{})
+
+			cfg := config.NewDeploymentConfig("test-app")
+			cfg.Spec.Env = tt.env
+
+			err := deployer.checkEnvSources(context.Background(), DeploymentOptions{Config: cfg})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkEnvSources() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkEnvSources() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckEnvSources_Forbidden(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "db-credentials", nil)
+	})
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	deployer := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+	cfg := config.NewDeploymentConfig("test-app")
+	cfg.Spec.Env = []config.EnvVar{{
+		Name: "DB_PASSWORD",
+		ValueFrom: &config.EnvVarSource{
+			SecretKeyRef: &config.SecretKeySelector{Name: "db-credentials", Key: "password"},
+		},
+	}}
+
+	if err := deployer.checkEnvSources(context.Background(), DeploymentOptions{Config: cfg}); err != nil {
+		t.Errorf("checkEnvSources() error = %v, want only a warning", err)
+	}
+}
@@ -0,0 +1,104 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the Kubernetes Events kudev emits on the app's Deployment.
+const (
+	// ReasonDeployed is a successful deploy (or re-create).
+	ReasonDeployed = "KudevDeployed"
+
+	// ReasonRollback is a return to the previous image after a crash loop.
+	ReasonRollback = "KudevRollback"
+
+	// ReasonBuildFailed is a failed image build. Emitted as a Warning.
+	ReasonBuildFailed = "KudevBuildFailed"
+)
+
+// maxEventMessage bounds event messages; build output can be long.
+const maxEventMessage = 1024
+
+// EventEmitter is implemented by deployers that record kudev actions as
+// Kubernetes Events, so 'kubectl describe deployment' shows the history.
+type EventEmitter interface {
+	// Emit records an event with the given reason on the app's Deployment.
+	Emit(ctx context.Context, appName, namespace, reason, message string) error
+}
+
+// Emit creates a core/v1 Event on the app's Deployment. If the Deployment
+// does not exist yet (e.g. the first build failed) the event still names
+// it, so it shows up in 'kubectl get events'.
+func (kd *KubernetesDeployer) Emit(ctx context.Context, appName, namespace, reason, message string) error {
+	ref := corev1.ObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       appName,
+		Namespace:  namespace,
+	}
+	deployment, err := kd.getAppDeployment(ctx, appName, namespace)
+	switch {
+	case err == nil:
+		ref.Name = deployment.Name
+		ref.UID = deployment.UID
+		ref.ResourceVersion = deployment.ResourceVersion
+	case !errors.IsNotFound(err):
+		return fmt.Errorf("failed to get deployment: %w", err)
+	}
+
+	eventType := corev1.EventTypeNormal
+	if reason == ReasonBuildFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	if len(message) > maxEventMessage {
+		message = message[:maxEventMessage-3] + "..."
+	}
+
+	now := metav1.Now()
+	host, _ := os.Hostname()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// Same naming scheme as client-go's event recorder
+			Name:      fmt.Sprintf("%s.%x", ref.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject:      ref,
+		Reason:              reason,
+		Message:             message,
+		Type:                eventType,
+		Source:              corev1.EventSource{Component: "kudev", Host: host},
+		ReportingController: "kudev",
+		ReportingInstance:   host,
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+	}
+
+	if _, err := kd.clientset.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+
+	kd.logger.Debug("event emitted",
+		"reason", reason,
+		"deployment", ref.Name,
+		"namespace", namespace,
+	)
+	return nil
+}
+
+// Emit records the event on the active slot's Deployment.
+func (bg *BlueGreenDeployer) Emit(ctx context.Context, appName, namespace, reason, message string) error {
+	return bg.kd.Emit(ctx, appName, namespace, reason, message)
+}
+
+// Ensure both deployers emit events
+var (
+	_ EventEmitter = (*KubernetesDeployer)(nil)
+	_ EventEmitter = (*BlueGreenDeployer)(nil)
+)
@@ -0,0 +1,74 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestEmit(t *testing.T) {
+	existing := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default", UID: "uid-1"},
+	}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		reason   string
+		message  string
+		wantType string
+		wantUID  string
+	}{
+		{
+			name:     "deployed",
+			objects:  []runtime.Object{existing},
+			reason:   ReasonDeployed,
+			message:  "Deployed myapp:kudev-abc12345",
+			wantType: corev1.EventTypeNormal,
+			wantUID:  "uid-1",
+		},
+		{
+			name:     "build failed before first deploy",
+			reason:   ReasonBuildFailed,
+			message:  strings.Repeat("x", 2*maxEventMessage),
+			wantType: corev1.EventTypeWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(tt.objects...)
+			kd := NewKubernetesDeployer(fakeClient, nil, &util.MockLogger{})
+
+			if err := kd.Emit(context.Background(), "myapp", "default", tt.reason, tt.message); err != nil {
+				t.Fatalf("Emit failed: %v", err)
+			}
+
+			events, err := fakeClient.CoreV1().Events("default").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events.Items) != 1 {
+				t.Fatalf("got %d events, want 1", len(events.Items))
+			}
+			e := events.Items[0]
+			if e.Reason != tt.reason || e.Type != tt.wantType {
+				t.Errorf("event = %s/%s, want %s/%s", e.Type, e.Reason, tt.wantType, tt.reason)
+			}
+			if e.InvolvedObject.Kind != "Deployment" || e.InvolvedObject.Name != "myapp" || string(e.InvolvedObject.UID) != tt.wantUID {
+				t.Errorf("involved object = %+v", e.InvolvedObject)
+			}
+			if len(e.Message) > maxEventMessage {
+				t.Errorf("message length = %d, want at most %d", len(e.Message), maxEventMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FrozenAnnotation marks a Deployment that 'kudev watch' must not redeploy.
+// The value is the RFC3339 time the app was frozen.
+const FrozenAnnotation = "kudev.io/frozen"
+
+// Freezer is implemented by deployers that support 'kudev freeze'.
+type Freezer interface {
+	// IsFrozen reports whether automatic redeploys are paused for the app.
+	IsFrozen(ctx context.Context, appName, namespace string) (bool, error)
+}
+
+// SetFrozen adds or removes the frozen annotation on the app's Deployment.
+func (kd *KubernetesDeployer) SetFrozen(ctx context.Context, appName, namespace string, frozen bool) error {
+	deployment, err := kd.getAppDeployment(ctx, appName, namespace)
+	if err != nil {
+		return describeGetError(err, appName, namespace)
+	}
+
+	if deployment.Annotations == nil {
+		deployment.Annotations = make(map[string]string)
+	}
+	if frozen {
+		deployment.Annotations[FrozenAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	} else {
+		delete(deployment.Annotations, FrozenAnnotation)
+	}
+
+	if _, err := kd.clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update deployment: %w", err)
+	}
+
+	kd.logger.Info("deployment freeze updated",
+		"name", deployment.Name,
+		"namespace", namespace,
+		"frozen", frozen,
+	)
+	return nil
+}
+
+// IsFrozen reports whether the app's Deployment carries the frozen annotation.
+// A missing Deployment is not frozen.
+func (kd *KubernetesDeployer) IsFrozen(ctx context.Context, appName, namespace string) (bool, error) {
+	deployment, err := kd.getAppDeployment(ctx, appName, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, describeGetError(err, appName, namespace)
+	}
+	_, frozen := deployment.Annotations[FrozenAnnotation]
+	return frozen, nil
+}
+
+// IsFrozen delegates to the wrapped deployer (the annotation lives on the active slot).
+func (bg *BlueGreenDeployer) IsFrozen(ctx context.Context, appName, namespace string) (bool, error) {
+	return bg.kd.IsFrozen(ctx, appName, namespace)
+}
+
+// getAppDeployment returns the app's live Deployment: <app>, or the active
+// blue/green slot when the app runs in blue/green mode.
+// API errors are returned as-is so callers can check errors.IsNotFound.
+func (kd *KubernetesDeployer) getAppDeployment(ctx context.Context, appName, namespace string) (*appsv1.Deployment, error) {
+	deployments := kd.clientset.AppsV1().Deployments(namespace)
+
+	deployment, err := deployments.Get(ctx, appName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if color, colorErr := kd.activeColor(ctx, appName, namespace); colorErr == nil && color != "" {
+			deployment, err = deployments.Get(ctx, colorName(appName, color), metav1.GetOptions{})
+		}
+	}
+	return deployment, err
+}
+
+// describeGetError turns a getAppDeployment error into a user-facing one.
+func describeGetError(err error, appName, namespace string) error {
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("deployment not found: %s/%s", namespace, appName)
+	}
+	return fmt.Errorf("failed to get deployment: %w", err)
+}
+
+// Ensure both deployers support freezing
+var (
+	_ Freezer = (*KubernetesDeployer)(nil)
+	_ Freezer = (*BlueGreenDeployer)(nil)
+)
@@ -0,0 +1,61 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestSetFrozen(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(managedDeployment("test-app", "test-app"))
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+	ctx := context.Background()
+
+	if frozen, err := kd.IsFrozen(ctx, "test-app", "default"); err != nil || frozen {
+		t.Fatalf("IsFrozen() = %v, %v; want false, nil", frozen, err)
+	}
+
+	if err := kd.SetFrozen(ctx, "test-app", "default", true); err != nil {
+		t.Fatalf("SetFrozen(true) failed: %v", err)
+	}
+	if frozen, _ := kd.IsFrozen(ctx, "test-app", "default"); !frozen {
+		t.Error("expected app to be frozen")
+	}
+
+	// Redeploying must keep the annotation
+	if _, err := kd.Upsert(ctx, preflightOpts(1)); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	d, _ := fakeClient.AppsV1().Deployments("default").Get(ctx, "test-app", metav1.GetOptions{})
+	if _, ok := d.Annotations[FrozenAnnotation]; !ok {
+		t.Error("Upsert should preserve the frozen annotation")
+	}
+
+	if err := kd.SetFrozen(ctx, "test-app", "default", false); err != nil {
+		t.Fatalf("SetFrozen(false) failed: %v", err)
+	}
+	if frozen, _ := kd.IsFrozen(ctx, "test-app", "default"); frozen {
+		t.Error("expected app to be unfrozen")
+	}
+}
+
+func TestIsFrozen_MissingDeployment(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+	frozen, err := kd.IsFrozen(context.Background(), "missing", "default")
+	if err != nil || frozen {
+		t.Errorf("IsFrozen() = %v, %v; want false, nil", frozen, err)
+	}
+
+	if err := kd.SetFrozen(context.Background(), "missing", "default", true); err == nil {
+		t.Error("SetFrozen should fail for a missing deployment")
+	}
+}
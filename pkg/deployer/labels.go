@@ -0,0 +1,53 @@
+package deployer
+
+// Recommended Kubernetes labels, rendered next to kudev's own labels so
+// dashboards, Lens and service meshes can group kudev apps.
+//
+// See https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+const (
+	// LabelName is the application name, without the instance suffix.
+	LabelName = "app.kubernetes.io/name"
+
+	// LabelInstance is the deployed name (with the --instance suffix).
+	LabelInstance = "app.kubernetes.io/instance"
+
+	// LabelVersion is the source hash of the deployed image.
+	LabelVersion = "app.kubernetes.io/version"
+
+	// LabelManagedBy is always "kudev".
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+)
+
+// Annotations read by the cluster reaper ('kudev install-reaper').
+const (
+	// AnnotationTTL is spec.ttl, for reference.
+	AnnotationTTL = "kudev.io/ttl"
+
+	// AnnotationExpiresAt is when the app may be reaped (RFC 3339, UTC):
+	// spec.ttl after the last deploy.
+	AnnotationExpiresAt = "kudev.io/expires-at"
+)
+
+// standardLabels returns the recommended labels for data.
+func standardLabels(data TemplateData) map[string]string {
+	labels := map[string]string{
+		LabelName:      data.Name,
+		LabelInstance:  data.AppName,
+		LabelManagedBy: "kudev",
+	}
+	if data.ImageHash != "" {
+		labels[LabelVersion] = data.ImageHash
+	}
+	return labels
+}
+
+// mergeLabels copies src into dst, allocating dst when nil.
+func mergeLabels(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,36 @@
+package deployer
+
+import (
+	"testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+func TestStandardLabels(t *testing.T) {
+	tests := []struct {
+		name         string
+		instance     string
+		wantName     string
+		wantInstance string
+	}{
+		{name: "no instance", wantName: "myapp", wantInstance: "myapp"},
+		{name: "with instance", instance: "feature-x", wantName: "myapp", wantInstance: "myapp-feature-x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDeploymentConfig("myapp")
+			if err := cfg.ApplyInstance(tt.instance); err != nil {
+				t.Fatal(err)
+			}
+
+			labels := standardLabels(NewTemplateData(DeploymentOptions{Config: cfg, ImageHash: "abc12345"}))
+			if labels[LabelName] != tt.wantName || labels[LabelInstance] != tt.wantInstance {
+				t.Errorf("labels = %v, want name %q instance %q", labels, tt.wantName, tt.wantInstance)
+			}
+			if labels[LabelVersion] != "abc12345" || labels[LabelManagedBy] != "kudev" {
+				t.Errorf("labels = %v, want version and managed-by", labels)
+			}
+		})
+	}
+}
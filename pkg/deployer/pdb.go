@@ -0,0 +1,121 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// newPDB builds the PodDisruptionBudget used with spec.zeroDowntime.
+// minAvailable=1 keeps one pod serving during voluntary disruptions
+// such as node drains.
+func newPDB(data TemplateData) *policyv1.PodDisruptionBudget {
+	minAvailable := intstr.FromInt32(1)
+
+	labels := mergeLabels(map[string]string{
+		"app":        data.AppName,
+		"managed-by": "kudev",
+	}, standardLabels(data))
+	if data.Instance != "" {
+		labels["kudev-instance"] = data.Instance
+	}
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      data.AppName,
+			Namespace: data.Namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": data.AppName},
+			},
+		},
+	}
+}
+
+// syncPDB creates the PodDisruptionBudget when zero-downtime is enabled
+// and removes a previously created one otherwise. Without access to
+// PodDisruptionBudgets (restricted RBAC) the cleanup is skipped, so
+// deploys without zero-downtime keep working.
+func (kd *KubernetesDeployer) syncPDB(ctx context.Context, data TemplateData) error {
+	if !data.ZeroDowntime {
+		err := kd.deletePDB(ctx, data.AppName, data.Namespace)
+		if errors.IsForbidden(err) {
+			kd.logger.Warn("cannot check for a stale pod disruption budget",
+				"name", data.AppName,
+				"namespace", data.Namespace,
+				"error", err,
+			)
+			return nil
+		}
+		return err
+	}
+	if err := kd.upsertPDB(ctx, newPDB(data)); err != nil {
+		return fmt.Errorf("failed to upsert pod disruption budget: %w", err)
+	}
+	return nil
+}
+
+// upsertPDB creates or updates the app's PodDisruptionBudget.
+func (kd *KubernetesDeployer) upsertPDB(ctx context.Context, desired *policyv1.PodDisruptionBudget) error {
+	pdbs := kd.clientset.PolicyV1().PodDisruptionBudgets(desired.Namespace)
+
+	existing, err := pdbs.Get(ctx, desired.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if _, err := pdbs.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create pdb: %w", err)
+			}
+			kd.logger.Info("pod disruption budget created",
+				"name", desired.Name,
+				"namespace", desired.Namespace,
+			)
+			return nil
+		}
+		return fmt.Errorf("failed to get pdb: %w", err)
+	}
+
+	desired.ResourceVersion = existing.ResourceVersion
+	if _, err := pdbs.Update(ctx, desired, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update pdb: %w", err)
+	}
+
+	kd.logger.Debug("pod disruption budget updated",
+		"name", desired.Name,
+		"namespace", desired.Namespace,
+	)
+	return nil
+}
+
+// deletePDB removes the app's PodDisruptionBudget if present.
+// Only budgets labelled managed-by=kudev are deleted.
+func (kd *KubernetesDeployer) deletePDB(ctx context.Context, name, namespace string) error {
+	pdbs := kd.clientset.PolicyV1().PodDisruptionBudgets(namespace)
+
+	existing, err := pdbs.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // Idempotent
+		}
+		return fmt.Errorf("failed to get pdb: %w", err)
+	}
+	if existing.Labels["managed-by"] != "kudev" {
+		return nil
+	}
+
+	if err := pdbs.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pdb: %w", err)
+	}
+
+	kd.logger.Info("pod disruption budget deleted",
+		"name", name,
+		"namespace", namespace,
+	)
+	return nil
+}
@@ -0,0 +1,126 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func zeroDowntimeOpts(enabled bool) DeploymentOptions {
+	return DeploymentOptions{
+		Config: &config.DeploymentConfig{
+			Metadata: config.MetadataConfig{Name: "test-app"},
+			Spec: config.SpecConfig{
+				Namespace:    "default",
+				Replicas:     1,
+				ServicePort:  8080,
+				ZeroDowntime: enabled,
+			},
+		},
+		ImageRef:  "test-app:kudev-12345678",
+		ImageHash: "12345678",
+	}
+}
+
+func TestUpsert_ZeroDowntime(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+	ctx := context.Background()
+
+	if _, err := kd.Upsert(ctx, zeroDowntimeOpts(true)); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	// Surge-only rolling update
+	deployment, err := fakeClient.AppsV1().Deployments("default").Get(ctx, "test-app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("deployment not found: %v", err)
+	}
+	ru := deployment.Spec.Strategy.RollingUpdate
+	if ru == nil || ru.MaxUnavailable == nil || ru.MaxUnavailable.IntValue() != 0 {
+		t.Errorf("expected maxUnavailable=0, got %+v", ru)
+	}
+	if ru == nil || ru.MaxSurge == nil || ru.MaxSurge.IntValue() != 1 {
+		t.Errorf("expected maxSurge=1, got %+v", ru)
+	}
+
+	// PDB created
+	pdb, err := fakeClient.PolicyV1().PodDisruptionBudgets("default").Get(ctx, "test-app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("pdb not found: %v", err)
+	}
+	if pdb.Spec.MinAvailable.IntValue() != 1 {
+		t.Errorf("minAvailable = %v, want 1", pdb.Spec.MinAvailable)
+	}
+	if pdb.Spec.Selector.MatchLabels["app"] != "test-app" {
+		t.Errorf("selector = %v, want app=test-app", pdb.Spec.Selector.MatchLabels)
+	}
+
+	// Second upsert updates in place
+	if _, err := kd.Upsert(ctx, zeroDowntimeOpts(true)); err != nil {
+		t.Fatalf("second Upsert failed: %v", err)
+	}
+
+	// Disabling removes the PDB and the strategy
+	if _, err := kd.Upsert(ctx, zeroDowntimeOpts(false)); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	_, err = fakeClient.PolicyV1().PodDisruptionBudgets("default").Get(ctx, "test-app", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected pdb to be deleted, got err=%v", err)
+	}
+	deployment, _ = fakeClient.AppsV1().Deployments("default").Get(ctx, "test-app", metav1.GetOptions{})
+	if deployment.Spec.Strategy.RollingUpdate != nil {
+		t.Errorf("expected strategy to be reset, got %+v", deployment.Spec.Strategy)
+	}
+}
+
+func TestDelete_RemovesPDB(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+	ctx := context.Background()
+
+	if _, err := kd.Upsert(ctx, zeroDowntimeOpts(true)); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	if err := kd.Delete(ctx, "test-app", "default"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	_, err := fakeClient.PolicyV1().PodDisruptionBudgets("default").Get(ctx, "test-app", metav1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected pdb to be deleted, got err=%v", err)
+	}
+}
+
+func TestUpsert_PDBForbidden(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("*", "poddisruptionbudgets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Group: "policy", Resource: "poddisruptionbudgets"}, "test-app", nil)
+	})
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+	// Without zero-downtime there is no PDB to manage
+	if _, err := kd.Upsert(context.Background(), zeroDowntimeOpts(false)); err != nil {
+		t.Fatalf("Upsert failed without pdb access: %v", err)
+	}
+
+	// With zero-downtime the PDB is required
+	if _, err := kd.Upsert(context.Background(), zeroDowntimeOpts(true)); err == nil {
+		t.Error("expected Upsert with zeroDowntime to fail without pdb access")
+	}
+}
@@ -0,0 +1,73 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodePlatforms returns the distinct os/arch platforms (e.g. linux/amd64)
+// of the schedulable nodes, sorted.
+func (kd *KubernetesDeployer) NodePlatforms(ctx context.Context) ([]string, error) {
+	nodes, err := kd.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var platforms []string
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		info := node.Status.NodeInfo
+		if node.Spec.Unschedulable || info.Architecture == "" {
+			continue
+		}
+		platform := info.OperatingSystem + "/" + info.Architecture
+		if !seen[platform] {
+			seen[platform] = true
+			platforms = append(platforms, platform)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// PlatformMismatch explains why an image built for buildPlatform may not
+// start on nodes running nodePlatforms (typically an arm64 laptop and an
+// amd64 cluster), or returns "" when every node can run it.
+//
+// Only os/arch are compared: a variant (linux/arm64/v8) is ignored.
+func PlatformMismatch(buildPlatform string, nodePlatforms []string) string {
+	build := osArch(buildPlatform)
+	if build == "" || len(nodePlatforms) == 0 {
+		return ""
+	}
+
+	var other []string
+	for _, p := range nodePlatforms {
+		if osArch(p) != build {
+			other = append(other, p)
+		}
+	}
+	if len(other) == 0 {
+		return ""
+	}
+	if len(other) < len(nodePlatforms) {
+		return fmt.Sprintf("image is built for %s, but some nodes run %s; pods scheduled there will fail with \"exec format error\"",
+			build, strings.Join(other, ", "))
+	}
+	return fmt.Sprintf("image is built for %s, but the cluster nodes run %s; pods will fail with \"exec format error\"",
+		build, strings.Join(other, ", "))
+}
+
+// osArch returns the os/arch part of an os/arch[/variant] platform.
+func osArch(platform string) string {
+	parts := strings.SplitN(platform, "/", 3)
+	if len(parts) < 2 {
+		return platform
+	}
+	return parts[0] + "/" + parts[1]
+}
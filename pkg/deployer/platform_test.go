@@ -0,0 +1,73 @@
+package deployer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func platformNode(name, arch string, unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status: corev1.NodeStatus{
+			NodeInfo: corev1.NodeSystemInfo{OperatingSystem: "linux", Architecture: arch},
+		},
+	}
+}
+
+func TestNodePlatforms(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		platformNode("worker-1", "amd64", false),
+		platformNode("worker-2", "arm64", false),
+		platformNode("worker-3", "amd64", false),
+		platformNode("cordoned", "s390x", true),
+	)
+	kd := NewKubernetesDeployer(clientset, nil, &util.MockLogger{})
+
+	got, err := kd.NodePlatforms(context.Background())
+	if err != nil {
+		t.Fatalf("NodePlatforms() error = %v", err)
+	}
+	if want := []string{"linux/amd64", "linux/arm64"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NodePlatforms() = %v, want %v", got, want)
+	}
+}
+
+func TestPlatformMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		build string
+		nodes []string
+		want  string
+	}{
+		{name: "same platform", build: "linux/arm64", nodes: []string{"linux/arm64"}},
+		{name: "variant ignored", build: "linux/arm64/v8", nodes: []string{"linux/arm64"}},
+		{name: "unknown build platform", build: "", nodes: []string{"linux/amd64"}},
+		{name: "no nodes", build: "linux/arm64"},
+		{name: "all nodes differ", build: "linux/arm64", nodes: []string{"linux/amd64"}, want: "cluster nodes run linux/amd64"},
+		{name: "some nodes differ", build: "linux/amd64", nodes: []string{"linux/amd64", "linux/arm64"}, want: "some nodes run linux/arm64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PlatformMismatch(tt.build, tt.nodes)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("PlatformMismatch() = %q, want none", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) || !strings.Contains(got, "exec format error") {
+				t.Errorf("PlatformMismatch() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
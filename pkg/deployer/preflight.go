@@ -0,0 +1,157 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PreflightResult describes whether the requested replicas can be scheduled.
+type PreflightResult struct {
+	// Replicas is the number of replicas requested.
+	Replicas int32
+
+	// PodCPU and PodMemory are the summed container requests of one pod.
+	PodCPU    resource.Quantity
+	PodMemory resource.Quantity
+
+	// Nodes is the number of schedulable nodes considered.
+	Nodes int
+
+	// Schedulable is how many replicas fit in the free allocatable resources.
+	Schedulable int32
+
+	// Warnings explains why replicas would stay Pending.
+	Warnings []string
+}
+
+// OK reports whether all replicas are expected to schedule.
+func (r *PreflightResult) OK() bool {
+	return len(r.Warnings) == 0
+}
+
+// Preflight checks that the cluster has enough allocatable CPU and memory
+// for the requested replicas.
+//
+// Requests are taken from the rendered Deployment, so they always match
+// what Upsert would apply. Pods of the app itself are not counted as used,
+// since a redeploy replaces them.
+//
+// This is a best-effort estimate: taints, affinity and limit ranges are ignored.
+func (kd *KubernetesDeployer) Preflight(ctx context.Context, opts DeploymentOptions) (*PreflightResult, error) {
+	data := NewTemplateData(opts)
+
+	deployment, err := kd.renderer.RenderDeployment(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render deployment: %w", err)
+	}
+
+	result := &PreflightResult{Replicas: data.Replicas}
+	for _, c := range deployment.Spec.Template.Spec.Containers {
+		result.PodCPU.Add(c.Resources.Requests[corev1.ResourceCPU])
+		result.PodMemory.Add(c.Resources.Requests[corev1.ResourceMemory])
+	}
+
+	nodes, err := kd.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	pods, err := kd.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// Sum requests already placed on each node
+	usedCPU := make(map[string]*resource.Quantity)
+	usedMem := make(map[string]*resource.Quantity)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || isTerminated(pod) {
+			continue
+		}
+		if pod.Namespace == data.Namespace && pod.Labels["app"] == data.AppName {
+			continue
+		}
+		if usedCPU[pod.Spec.NodeName] == nil {
+			usedCPU[pod.Spec.NodeName] = &resource.Quantity{}
+			usedMem[pod.Spec.NodeName] = &resource.Quantity{}
+		}
+		cpu, memory := podRequests(pod)
+		usedCPU[pod.Spec.NodeName].Add(cpu)
+		usedMem[pod.Spec.NodeName].Add(memory)
+	}
+
+	var fitsOnSomeNode bool
+	var maxFreeCPU, maxFreeMem int64
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+		result.Nodes++
+
+		freeCPU := node.Status.Allocatable.Cpu().MilliValue()
+		freeMem := node.Status.Allocatable.Memory().Value()
+		if used := usedCPU[node.Name]; used != nil {
+			freeCPU -= used.MilliValue()
+			freeMem -= usedMem[node.Name].Value()
+		}
+		maxFreeCPU = max(maxFreeCPU, freeCPU)
+		maxFreeMem = max(maxFreeMem, freeMem)
+
+		n := podsThatFit(freeCPU, result.PodCPU.MilliValue(), freeMem, result.PodMemory.Value())
+		if n > 0 {
+			fitsOnSomeNode = true
+		}
+		result.Schedulable += n
+	}
+
+	if result.Nodes == 0 {
+		result.Warnings = append(result.Warnings, "no schedulable nodes found in the cluster")
+		return result, nil
+	}
+
+	if !fitsOnSomeNode {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"a single pod requests cpu=%s memory=%s, but the largest free node has cpu=%dm memory=%s",
+			result.PodCPU.String(), result.PodMemory.String(),
+			maxFreeCPU, resource.NewQuantity(maxFreeMem, resource.BinarySI).String(),
+		))
+	} else if result.Schedulable < result.Replicas {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"only %d of %d replicas fit in free cluster resources (cpu=%s memory=%s per pod); the rest will stay Pending",
+			result.Schedulable, result.Replicas,
+			result.PodCPU.String(), result.PodMemory.String(),
+		))
+	}
+
+	return result, nil
+}
+
+// podsThatFit returns how many pods with the given requests fit in free resources.
+// Capped to keep the sum bounded when a pod requests nothing.
+func podsThatFit(freeCPU, podCPU, freeMem, podMem int64) int32 {
+	const maxPerNode = 1 << 16
+
+	if freeCPU < 0 || freeMem < 0 {
+		return 0
+	}
+
+	n := int64(maxPerNode)
+	if podCPU > 0 {
+		n = min(n, freeCPU/podCPU)
+	}
+	if podMem > 0 {
+		n = min(n, freeMem/podMem)
+	}
+	return int32(n)
+}
+
+// isTerminated reports whether a pod no longer holds node resources.
+func isTerminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
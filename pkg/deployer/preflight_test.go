@@ -0,0 +1,149 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func testNode(name, cpu, mem string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+			},
+		},
+	}
+}
+
+func testPod(name, namespace, app, node, cpu, mem string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": app},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{
+				Name: "c",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(mem),
+					},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func preflightOpts(replicas int32) DeploymentOptions {
+	return DeploymentOptions{
+		Config: &config.DeploymentConfig{
+			Metadata: config.MetadataConfig{Name: "test-app"},
+			Spec: config.SpecConfig{
+				Namespace:   "default",
+				Replicas:    replicas,
+				ServicePort: 8080,
+			},
+		},
+		ImageRef:  "test-app:kudev-12345678",
+		ImageHash: "12345678",
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	// Default template requests: cpu=100m memory=128Mi per pod
+	tests := []struct {
+		name            string
+		replicas        int32
+		objects         []runtime.Object
+		wantOK          bool
+		wantSchedulable int32
+		wantWarning     string
+	}{
+		{
+			name:            "enough resources",
+			replicas:        3,
+			objects:         []runtime.Object{testNode("n1", "2", "4Gi")},
+			wantOK:          true,
+			wantSchedulable: 20, // cpu: 2000m / 100m = 20, memory: 4Gi / 128Mi = 32
+		},
+		{
+			name:     "not enough for all replicas",
+			replicas: 5,
+			objects: []runtime.Object{
+				testNode("n1", "1", "4Gi"),
+				testPod("busy", "kube-system", "other", "n1", "700m", "0"),
+			},
+			wantOK:          false,
+			wantSchedulable: 3,
+			wantWarning:     "only 3 of 5 replicas fit",
+		},
+		{
+			name:     "single pod does not fit",
+			replicas: 1,
+			objects: []runtime.Object{
+				testNode("n1", "1", "100Mi"),
+			},
+			wantOK:      false,
+			wantWarning: "a single pod requests",
+		},
+		{
+			name:     "own pods are not counted as used",
+			replicas: 2,
+			objects: []runtime.Object{
+				testNode("n1", "200m", "1Gi"),
+				testPod("test-app-1", "default", "test-app", "n1", "100m", "128Mi"),
+				testPod("test-app-2", "default", "test-app", "n1", "100m", "128Mi"),
+			},
+			wantOK:          true,
+			wantSchedulable: 2,
+		},
+		{
+			name:        "no nodes",
+			replicas:    1,
+			wantOK:      false,
+			wantWarning: "no schedulable nodes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(tt.objects...)
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+			result, err := kd.Preflight(context.Background(), preflightOpts(tt.replicas))
+			if err != nil {
+				t.Fatalf("Preflight failed: %v", err)
+			}
+
+			if result.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v (warnings: %v)", result.OK(), tt.wantOK, result.Warnings)
+			}
+			if tt.wantSchedulable > 0 && result.Schedulable != tt.wantSchedulable {
+				t.Errorf("Schedulable = %d, want %d", result.Schedulable, tt.wantSchedulable)
+			}
+			if tt.wantWarning != "" {
+				if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0], tt.wantWarning) {
+					t.Errorf("Warnings = %v, want containing %q", result.Warnings, tt.wantWarning)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PresenceChecker is implemented by deployers that can tell whether the
+// app's resources were removed behind kudev's back (e.g. 'kubectl delete ns').
+type PresenceChecker interface {
+	// Missing returns a description of each resource of the app that is
+	// gone from the cluster; empty when everything is in place.
+	Missing(ctx context.Context, appName, namespace string) ([]string, error)
+}
+
+// Missing reports whether the namespace, Deployment or Service of the app
+// are gone. A namespace being deleted counts as gone.
+func (kd *KubernetesDeployer) Missing(ctx context.Context, appName, namespace string) ([]string, error) {
+	if namespace != "default" {
+		ns, err := kd.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return []string{"namespace " + namespace}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check namespace: %w", err)
+		}
+		if ns.Status.Phase == corev1.NamespaceTerminating {
+			return []string{"namespace " + namespace + " (terminating)"}, nil
+		}
+	}
+
+	var missing []string
+	if _, err := kd.getAppDeployment(ctx, appName, namespace); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		missing = append(missing, fmt.Sprintf("deployment %s/%s", namespace, appName))
+	}
+	if _, err := kd.clientset.CoreV1().Services(namespace).Get(ctx, appName, metav1.GetOptions{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get service: %w", err)
+		}
+		missing = append(missing, fmt.Sprintf("service %s/%s", namespace, appName))
+	}
+
+	return missing, nil
+}
+
+// Missing checks the active slot, the shared Service and the namespace.
+func (bg *BlueGreenDeployer) Missing(ctx context.Context, appName, namespace string) ([]string, error) {
+	return bg.kd.Missing(ctx, appName, namespace)
+}
+
+// waitForNamespaceGone polls until a terminating namespace has been removed,
+// so it can be created again.
+func (kd *KubernetesDeployer) waitForNamespaceGone(ctx context.Context, namespace string) error {
+	deadline := kd.clock.Now().Add(DefaultWaitTimeout)
+
+	for {
+		_, err := kd.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check namespace: %w", err)
+		}
+		if kd.clock.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for namespace %s to finish terminating", namespace)
+		}
+
+		kd.logger.Debug("waiting for namespace to terminate", "name", namespace)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-kd.clock.After(DefaultWaitPollInterval):
+			// Continue polling
+		}
+	}
+}
+
+// Ensure both deployers can detect removed resources
+var (
+	_ PresenceChecker = (*KubernetesDeployer)(nil)
+	_ PresenceChecker = (*BlueGreenDeployer)(nil)
+)
@@ -0,0 +1,54 @@
+package deployer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestMissing(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}}
+	terminating := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "dev"}}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "dev"}}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    []string
+	}{
+		{name: "all present", objects: []runtime.Object{namespace, deployment, service}},
+		{name: "namespace deleted", want: []string{"namespace dev"}},
+		{name: "namespace terminating", objects: []runtime.Object{terminating, deployment, service},
+			want: []string{"namespace dev (terminating)"}},
+		{name: "deployment deleted", objects: []runtime.Object{namespace, service},
+			want: []string{"deployment dev/myapp"}},
+		{name: "resources deleted", objects: []runtime.Object{namespace},
+			want: []string{"deployment dev/myapp", "service dev/myapp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kd := NewKubernetesDeployer(fake.NewSimpleClientset(tt.objects...), nil, &util.MockLogger{})
+
+			got, err := kd.Missing(context.Background(), "myapp", "dev")
+			if err != nil {
+				t.Fatalf("Missing failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Missing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PodProgress is the startup state of one pod, reported while waiting
+// for readiness (see WaitOptions.Progress).
+type PodProgress struct {
+	Name string
+
+	// Containers lists init containers first, in spec order.
+	Containers []ContainerProgress
+
+	// Unschedulable is the scheduler's message while the pod has no node.
+	Unschedulable string
+}
+
+// ContainerProgress is the state of one container of a starting pod.
+type ContainerProgress struct {
+	Name string
+	Init bool
+
+	// State is a short description, e.g. "ready", "done" or
+	// "pulling image app:kudev-1234 (45%)".
+	State string
+}
+
+// String renders the pod on one line, e.g.
+// "myapp-7d9f: init:migrate done, app pulling image app:kudev-1234 (for 30s)".
+func (p PodProgress) String() string {
+	if p.Unschedulable != "" {
+		return fmt.Sprintf("%s: unschedulable: %s", p.Name, p.Unschedulable)
+	}
+	if len(p.Containers) == 0 {
+		return fmt.Sprintf("%s: pending", p.Name)
+	}
+
+	states := make([]string, len(p.Containers))
+	for i, c := range p.Containers {
+		name := c.Name
+		if c.Init {
+			name = "init:" + name
+		}
+		states[i] = name + " " + c.State
+	}
+	return p.Name + ": " + strings.Join(states, ", ")
+}
+
+// pullPercentPattern finds a percentage in image pull event messages.
+// Kubelet itself reports none, but some runtimes and pull-through proxies do.
+var pullPercentPattern = regexp.MustCompile(`(\d{1,3}(?:\.\d+)?)%`)
+
+// PodsProgress returns the startup state of the app's non-terminating
+// pods, sorted by name. Image pulls are read from the pods' events.
+func (kd *KubernetesDeployer) PodsProgress(ctx context.Context, appName, namespace string) ([]PodProgress, error) {
+	pods, err := kd.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{"app": appName}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	// Events are best effort: without them pulls show as plain waiting
+	var events []corev1.Event
+	if list, err := kd.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod",
+	}); err == nil {
+		events = list.Items
+	} else {
+		kd.logger.Debug("failed to list pod events", "error", err)
+	}
+
+	now := time.Now()
+	var progress []PodProgress
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		progress = append(progress, podProgress(pod, pullEvents(pod, events), now))
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].Name < progress[j].Name })
+	return progress, nil
+}
+
+// pullEvents returns the latest image pull event of each container of pod,
+// keyed by the event's field path (e.g. "spec.containers{app}").
+func pullEvents(pod *corev1.Pod, events []corev1.Event) map[string]corev1.Event {
+	latest := make(map[string]corev1.Event)
+	for _, ev := range events {
+		if ev.InvolvedObject.Name != pod.Name || ev.InvolvedObject.UID != pod.UID {
+			continue
+		}
+		switch ev.Reason {
+		case "Pulling", "Pulled", "Failed", "BackOff":
+		default:
+			continue
+		}
+		path := ev.InvolvedObject.FieldPath
+		if prev, ok := latest[path]; ok && eventTime(prev).After(eventTime(ev)) {
+			continue
+		}
+		latest[path] = ev
+	}
+	return latest
+}
+
+// eventTime returns when the event last occurred.
+func eventTime(ev corev1.Event) time.Time {
+	if !ev.LastTimestamp.IsZero() {
+		return ev.LastTimestamp.Time
+	}
+	if !ev.EventTime.IsZero() {
+		return ev.EventTime.Time
+	}
+	return ev.FirstTimestamp.Time
+}
+
+// podProgress describes the containers of pod, init containers first.
+func podProgress(pod *corev1.Pod, pulls map[string]corev1.Event, now time.Time) PodProgress {
+	p := PodProgress{Name: pod.Name}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			p.Unschedulable = cond.Message
+			return p
+		}
+	}
+
+	statuses := func(list []corev1.ContainerStatus) map[string]corev1.ContainerStatus {
+		byName := make(map[string]corev1.ContainerStatus, len(list))
+		for _, cs := range list {
+			byName[cs.Name] = cs
+		}
+		return byName
+	}
+	initStatuses := statuses(pod.Status.InitContainerStatuses)
+	appStatuses := statuses(pod.Status.ContainerStatuses)
+
+	for _, c := range pod.Spec.InitContainers {
+		pull, hasPull := pulls["spec.initContainers{"+c.Name+"}"]
+		cs, ok := initStatuses[c.Name]
+		p.Containers = append(p.Containers, ContainerProgress{
+			Name:  c.Name,
+			Init:  true,
+			State: containerState(cs, ok, c.Image, pull, hasPull, now),
+		})
+	}
+	for _, c := range pod.Spec.Containers {
+		pull, hasPull := pulls["spec.containers{"+c.Name+"}"]
+		cs, ok := appStatuses[c.Name]
+		p.Containers = append(p.Containers, ContainerProgress{
+			Name:  c.Name,
+			State: containerState(cs, ok, c.Image, pull, hasPull, now),
+		})
+	}
+	return p
+}
+
+// containerState describes a container from its status (hasStatus false:
+// not reported yet) and its latest image pull event, if any.
+func containerState(cs corev1.ContainerStatus, hasStatus bool, image string, pull corev1.Event, hasPull bool, now time.Time) string {
+	switch {
+	case hasStatus && cs.State.Running != nil:
+		if cs.Ready {
+			return "ready"
+		}
+		return "running (not ready)"
+
+	case hasStatus && cs.State.Terminated != nil:
+		t := cs.State.Terminated
+		if t.ExitCode == 0 {
+			return "done"
+		}
+		return fmt.Sprintf("exited %d (%s)", t.ExitCode, t.Reason)
+
+	case hasStatus && cs.State.Waiting != nil:
+		w := cs.State.Waiting
+		switch w.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+			return fmt.Sprintf("image pull failed (%s): %s", w.Reason, w.Message)
+		case "ContainerCreating", "PodInitializing", "":
+			// Still starting, most likely pulling
+		default:
+			return "waiting: " + w.Reason
+		}
+	}
+
+	if hasPull {
+		switch pull.Reason {
+		case "Pulling":
+			return pullingState(image, pull, now)
+		case "Pulled":
+			return "starting"
+		}
+	}
+	return "waiting"
+}
+
+// pullingState describes an image pull in progress, with the percentage
+// when the event reports one, else how long it has been running.
+func pullingState(image string, pull corev1.Event, now time.Time) string {
+	if m := pullPercentPattern.FindStringSubmatch(pull.Message); m != nil {
+		return fmt.Sprintf("pulling image %s (%s%%)", image, m[1])
+	}
+	// Coarse steps, so unchanged progress isn't reported on every poll
+	if elapsed := now.Sub(eventTime(pull)).Truncate(10 * time.Second); elapsed > 0 {
+		return fmt.Sprintf("pulling image %s (for %s)", image, elapsed)
+	}
+	return fmt.Sprintf("pulling image %s", image)
+}
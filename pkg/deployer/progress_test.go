@@ -0,0 +1,186 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func startingPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app-abc",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-app"},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Image: "migrate:1"}},
+			Containers:     []corev1.Container{{Name: "app", Image: "test-app:kudev-1234"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "migrate",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "app",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			}},
+		},
+	}
+}
+
+func pullEvent(reason, fieldPath, message string, at time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app-abc." + reason, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      "test-app-abc",
+			Namespace: "default",
+			FieldPath: fieldPath,
+		},
+		Reason:        reason,
+		Message:       message,
+		LastTimestamp: metav1.NewTime(at),
+	}
+}
+
+func TestPodsProgress(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		event *corev1.Event
+		want  string
+	}{
+		{
+			name: "no events",
+			want: "test-app-abc: init:migrate done, app waiting",
+		},
+		{
+			name:  "pull with percentage",
+			event: pullEvent("Pulling", "spec.containers{app}", `Pulling image "test-app:kudev-1234": 45%`, now),
+			want:  "test-app-abc: init:migrate done, app pulling image test-app:kudev-1234 (45%)",
+		},
+		{
+			name:  "pull without percentage",
+			event: pullEvent("Pulling", "spec.containers{app}", `Pulling image "test-app:kudev-1234"`, now.Add(-35*time.Second)),
+			want:  "test-app-abc: init:migrate done, app pulling image test-app:kudev-1234 (for 30s)",
+		},
+		{
+			name:  "pulled",
+			event: pullEvent("Pulled", "spec.containers{app}", "Successfully pulled image", now),
+			want:  "test-app-abc: init:migrate done, app starting",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(startingPod())
+			if tt.event != nil {
+				if _, err := client.CoreV1().Events("default").Create(context.Background(), tt.event, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			kd := NewKubernetesDeployer(client, nil, &util.MockLogger{})
+
+			pods, err := kd.PodsProgress(context.Background(), "test-app", "default")
+			if err != nil {
+				t.Fatalf("PodsProgress failed: %v", err)
+			}
+			if len(pods) != 1 {
+				t.Fatalf("got %d pods, want 1", len(pods))
+			}
+			if got := pods[0].String(); got != tt.want {
+				t.Errorf("progress = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerState(t *testing.T) {
+	tests := []struct {
+		name string
+		cs   corev1.ContainerStatus
+		want string
+	}{
+		{
+			name: "ready",
+			cs:   corev1.ContainerStatus{Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			want: "ready",
+		},
+		{
+			name: "running",
+			cs:   corev1.ContainerStatus{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			want: "running (not ready)",
+		},
+		{
+			name: "init failed",
+			cs:   corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}}},
+			want: "exited 1 (Error)",
+		},
+		{
+			name: "pull backoff",
+			cs: corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "ImagePullBackOff", Message: "not found",
+			}}},
+			want: "image pull failed (ImagePullBackOff): not found",
+		},
+		{
+			name: "crash loop",
+			cs:   corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			want: "waiting: CrashLoopBackOff",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerState(tt.cs, true, "img", corev1.Event{}, false, time.Now()); got != tt.want {
+				t.Errorf("containerState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodProgress_Unschedulable(t *testing.T) {
+	pod := startingPod()
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  corev1.PodReasonUnschedulable,
+		Message: "0/1 nodes are available: insufficient memory",
+	}}
+
+	got := podProgress(pod, nil, time.Now()).String()
+	if !strings.HasSuffix(got, "unschedulable: 0/1 nodes are available: insufficient memory") {
+		t.Errorf("progress = %q", got)
+	}
+}
+
+func TestWaitForReady_ReportsProgressChanges(t *testing.T) {
+	kd := NewKubernetesDeployer(fake.NewSimpleClientset(startingPod()), nil, &util.MockLogger{})
+
+	var reports int
+	err := kd.WaitForReady(context.Background(), WaitOptions{
+		AppName:      "test-app",
+		Namespace:    "default",
+		Timeout:      50 * time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+		Progress:     func([]PodProgress) { reports++ },
+	})
+	if err == nil {
+		t.Fatal("expected timeout: the deployment does not exist")
+	}
+	// The pod state never changes, so it is reported once
+	if reports != 1 {
+		t.Errorf("got %d progress reports, want 1", reports)
+	}
+}
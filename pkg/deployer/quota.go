@@ -0,0 +1,51 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkSafety enforces spec.safety limits before anything is applied.
+//
+// The replica cap is also checked by config validation; it is repeated here
+// so callers that build a config in code cannot bypass it.
+func (kd *KubernetesDeployer) checkSafety(ctx context.Context, opts DeploymentOptions) error {
+	safety := opts.Config.Spec.Safety
+
+	if maxReplicas := safety.EffectiveMaxReplicas(); opts.Config.Spec.Replicas > maxReplicas {
+		return fmt.Errorf("replicas %d exceeds safety limit %d (raise spec.safety.maxReplicas if intended)",
+			opts.Config.Spec.Replicas, maxReplicas)
+	}
+
+	if safety == nil || safety.MaxManagedDeployments <= 0 {
+		return nil
+	}
+
+	appName := opts.Config.Metadata.Name
+	namespace := opts.Config.Spec.Namespace
+
+	deployments, err := kd.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "managed-by=kudev",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count managed deployments: %w", err)
+	}
+
+	// Redeploying an existing app (or its blue/green slots) doesn't add one
+	others := 0
+	for _, d := range deployments.Items {
+		if d.Labels["app"] != appName {
+			others++
+		}
+	}
+
+	if others+1 > safety.MaxManagedDeployments {
+		return fmt.Errorf("namespace %q already has %d kudev-managed deployments (spec.safety.maxManagedDeployments: %d)\n\n"+
+			"Remove unused apps with 'kudev down' or raise the limit",
+			namespace, others, safety.MaxManagedDeployments)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func managedDeployment(name, app string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": app, "managed-by": "kudev"},
+		},
+	}
+}
+
+func managedPod(name, app string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": app, "managed-by": "kudev"},
+		},
+	}
+}
+
+func TestUpsert_SafetyLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		safety   *config.SafetyConfig
+		objects  []runtime.Object
+		wantErr  string
+	}{
+		{
+			name:     "default replica cap",
+			replicas: 101,
+			wantErr:  "exceeds safety limit 100",
+		},
+		{
+			name:     "custom replica cap",
+			replicas: 6,
+			safety:   &config.SafetyConfig{MaxReplicas: 5},
+			wantErr:  "exceeds safety limit 5",
+		},
+		{
+			name:     "raised replica cap",
+			replicas: 150,
+			safety:   &config.SafetyConfig{MaxReplicas: 200},
+		},
+		{
+			name:     "managed deployment limit reached",
+			replicas: 1,
+			safety:   &config.SafetyConfig{MaxManagedDeployments: 2},
+			objects: []runtime.Object{
+				managedDeployment("other-1", "other-1"),
+				managedDeployment("other-2", "other-2"),
+			},
+			wantErr: "already has 2 kudev-managed deployments",
+		},
+		{
+			name:     "redeploying own app does not count",
+			replicas: 1,
+			safety:   &config.SafetyConfig{MaxManagedDeployments: 2},
+			objects: []runtime.Object{
+				managedDeployment("other-1", "other-1"),
+				managedDeployment("test-app", "test-app"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(tt.objects...)
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			kd := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+			opts := DeploymentOptions{
+				Config: &config.DeploymentConfig{
+					Metadata: config.MetadataConfig{Name: "test-app"},
+					Spec: config.SpecConfig{
+						Namespace:   "default",
+						Replicas:    tt.replicas,
+						ServicePort: 8080,
+						Safety:      tt.safety,
+					},
+				},
+				ImageRef:  "test-app:kudev-12345678",
+				ImageHash: "12345678",
+			}
+
+			_, err := kd.Upsert(context.Background(), opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Upsert failed: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Upsert error = %v, want containing %q", err, tt.wantErr)
+			}
+
+			// Nothing must have been applied
+			if _, getErr := fakeClient.AppsV1().Deployments("default").Get(context.Background(), "test-app", metav1.GetOptions{}); getErr == nil && len(tt.objects) == 0 {
+				t.Error("deployment should not be created when a safety limit is hit")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+// ReadinessCheck reports whether the app is ready to serve.
+// Errors are treated as "not ready yet" and retried until the wait times out.
+type ReadinessCheck func(ctx context.Context, opts WaitOptions) (bool, error)
+
+// RegisterReadinessCheck adds or replaces the check used for a
+// spec.readiness.strategy value.
+func (kd *KubernetesDeployer) RegisterReadinessCheck(strategy string, check ReadinessCheck) {
+	kd.readiness[strategy] = check
+}
+
+// registerDefaultReadinessChecks installs the built-in strategies.
+func (kd *KubernetesDeployer) registerDefaultReadinessChecks() {
+	kd.readiness = map[string]ReadinessCheck{
+		config.ReadinessRollout:   kd.rolloutReady,
+		config.ReadinessEndpoints: kd.endpointsReady,
+		config.ReadinessHTTP:      kd.httpReady,
+		config.ReadinessCommand:   commandReady,
+	}
+}
+
+// rolloutReady checks that all replicas of the (active) Deployment are ready.
+func (kd *KubernetesDeployer) rolloutReady(ctx context.Context, opts WaitOptions) (bool, error) {
+	status, err := kd.Status(ctx, opts.AppName, opts.Namespace)
+	if err != nil {
+		// Deployment might not exist yet
+		return false, err
+	}
+
+	kd.logger.Debug("waiting for deployment",
+		"app", opts.AppName,
+		"ready", status.ReadyReplicas,
+		"desired", status.DesiredReplicas,
+	)
+	return status.IsReady(), nil
+}
+
+// rolloutComplete reports whether the (active) Deployment's latest spec
+// is rolled out and its old pods are gone. Until then the old pods still
+// back the Service and would pass the endpoint and HTTP checks.
+func (kd *KubernetesDeployer) rolloutComplete(ctx context.Context, opts WaitOptions) (bool, error) {
+	d, err := kd.getAppDeployment(ctx, opts.AppName, opts.Namespace)
+	if err != nil {
+		return false, describeGetError(err, opts.AppName, opts.Namespace)
+	}
+	return isRolledOut(d) && d.Status.Replicas <= d.Status.UpdatedReplicas, nil
+}
+
+// endpointsReady checks that the rollout completed and the Service has at
+// least one ready endpoint.
+func (kd *KubernetesDeployer) endpointsReady(ctx context.Context, opts WaitOptions) (bool, error) {
+	if done, err := kd.rolloutComplete(ctx, opts); err != nil || !done {
+		return false, err
+	}
+
+	slices, err := kd.clientset.DiscoveryV1().EndpointSlices(opts.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + opts.AppName,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	for _, slice := range slices.Items {
+		for _, ep := range slice.Endpoints {
+			// A nil Ready condition means ready
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// httpReady sends a GET through the API server's Service proxy, so it
+// works before any local port-forward exists. Like endpointsReady, it
+// waits for the rollout first.
+func (kd *KubernetesDeployer) httpReady(ctx context.Context, opts WaitOptions) (bool, error) {
+	if done, err := kd.rolloutComplete(ctx, opts); err != nil || !done {
+		return false, err
+	}
+
+	svc, err := kd.clientset.CoreV1().Services(opts.Namespace).Get(ctx, opts.AppName, metav1.GetOptions{})
+	if err != nil {
+		return false, describeGetError(err, opts.AppName, opts.Namespace)
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return false, fmt.Errorf("service %s has no ports", opts.AppName)
+	}
+
+	port := strconv.Itoa(int(svc.Spec.Ports[0].Port))
+	path := opts.Readiness.EffectivePath()
+	if _, err := kd.clientset.CoreV1().Services(opts.Namespace).
+		ProxyGet("http", opts.AppName, port, path, nil).DoRaw(ctx); err != nil {
+		return false, fmt.Errorf("GET %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// commandReady runs spec.readiness.command and treats exit 0 as ready.
+func commandReady(ctx context.Context, opts WaitOptions) (bool, error) {
+	if opts.Readiness == nil || len(opts.Readiness.Command) == 0 {
+		return false, fmt.Errorf("spec.readiness.command is not set")
+	}
+
+	args := opts.Readiness.Command
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = opts.WorkDir
+	cmd.Env = append(os.Environ(),
+		"KUDEV_APP="+opts.AppName,
+		"KUDEV_NAMESPACE="+opts.Namespace,
+	)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return false, fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return true, nil
+}
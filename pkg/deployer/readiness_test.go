@@ -0,0 +1,151 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func endpointSlice(service string, ready *bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service + "-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ready}},
+		},
+	}
+}
+
+// rollingDeployment returns test-app with one desired replica, updated
+// new pods and total pods (old ones included) in its status.
+func rollingDeployment(updated, total int32) *appsv1.Deployment {
+	replicas := int32(1)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			Replicas:        total,
+			UpdatedReplicas: updated,
+			ReadyReplicas:   total,
+		},
+	}
+}
+
+func TestWaitForReady_Strategies(t *testing.T) {
+	ready, notReady := true, false
+	rolledOut := rollingDeployment(1, 1)
+
+	tests := []struct {
+		name      string
+		readiness *config.ReadinessConfig
+		objects   []runtime.Object
+		wantErr   bool
+	}{
+		{
+			name:      "endpoints ready",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessEndpoints},
+			objects:   []runtime.Object{rolledOut, endpointSlice("test-app", &ready)},
+		},
+		{
+			name:      "endpoints not ready",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessEndpoints},
+			objects:   []runtime.Object{rolledOut, endpointSlice("test-app", &notReady)},
+			wantErr:   true,
+		},
+		{
+			name:      "endpoints of another service",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessEndpoints},
+			objects:   []runtime.Object{rolledOut, endpointSlice("other", &ready)},
+			wantErr:   true,
+		},
+		{
+			name:      "endpoints of old pods during a rollout",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessEndpoints},
+			objects:   []runtime.Object{rollingDeployment(0, 1), endpointSlice("test-app", &ready)},
+			wantErr:   true,
+		},
+		{
+			name:      "old pods not yet terminated",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessEndpoints},
+			objects:   []runtime.Object{rollingDeployment(1, 2), endpointSlice("test-app", &ready)},
+			wantErr:   true,
+		},
+		{
+			name:      "http before the rollout",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessHTTP},
+			objects:   []runtime.Object{rollingDeployment(0, 1)},
+			wantErr:   true,
+		},
+		{
+			name:      "command exits 0",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessCommand, Command: []string{"true"}},
+		},
+		{
+			name:      "command fails",
+			readiness: &config.ReadinessConfig{Strategy: config.ReadinessCommand, Command: []string{"false"}},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown strategy",
+			readiness: &config.ReadinessConfig{Strategy: "telepathy"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+			deployer := NewKubernetesDeployer(fake.NewSimpleClientset(tt.objects...), renderer, &util.MockLogger{})
+
+			err := deployer.WaitForReady(context.Background(), WaitOptions{
+				AppName:      "test-app",
+				Namespace:    "default",
+				Timeout:      50 * time.Millisecond,
+				PollInterval: 10 * time.Millisecond,
+				Readiness:    tt.readiness,
+				WorkDir:      t.TempDir(),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WaitForReady() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterReadinessCheck(t *testing.T) {
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	deployer := NewKubernetesDeployer(fake.NewSimpleClientset(), renderer, &util.MockLogger{})
+
+	calls := 0
+	deployer.RegisterReadinessCheck("custom", func(ctx context.Context, opts WaitOptions) (bool, error) {
+		calls++
+		return calls >= 2, nil
+	})
+
+	err := deployer.WaitForReady(context.Background(), WaitOptions{
+		AppName:      "test-app",
+		Namespace:    "default",
+		Timeout:      time.Second,
+		PollInterval: time.Millisecond,
+		Readiness:    &config.ReadinessConfig{Strategy: "custom"},
+	})
+	if err != nil {
+		t.Fatalf("WaitForReady failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("check called %d times, want 2", calls)
+	}
+}
@@ -3,11 +3,15 @@ package deployer
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"text/template"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
 )
 
 // Renderer handles YAML template rendering.
@@ -54,6 +58,33 @@ func NewRenderer(deploymentTpl, serviceTpl string) (*Renderer, error) {
 	}, nil
 }
 
+// NewRendererForConfig creates a renderer from the templates set in
+// spec.templates, falling back to the built-in ones.
+func NewRendererForConfig(cfg *config.DeploymentConfig) (*Renderer, error) {
+	deploymentTpl, err := readTemplate(cfg.DeploymentTemplatePath(), templates.DeploymentTemplate)
+	if err != nil {
+		return nil, err
+	}
+	serviceTpl, err := readTemplate(cfg.ServiceTemplatePath(), templates.ServiceTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return NewRenderer(deploymentTpl, serviceTpl)
+}
+
+// readTemplate reads the template at path, or returns builtin if path
+// is empty.
+func readTemplate(path, builtin string) (string, error) {
+	if path == "" {
+		return builtin, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	return string(data), nil
+}
+
 // RenderDeployment renders the Deployment template with the given data.
 // Returns a typed Kubernetes Deployment object.
 func (r *Renderer) RenderDeployment(data TemplateData) (*appsv1.Deployment, error) {
@@ -1,9 +1,12 @@
 package deployer
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/templates"
 )
 
@@ -29,6 +32,57 @@ func TestNewRenderer_InvalidTemplate(t *testing.T) {
 	}
 }
 
+func TestNewRendererForConfig(t *testing.T) {
+	root := t.TempDir()
+	customTpl := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .AppName }}-custom
+  namespace: {{ .Namespace }}
+`
+	if err := os.WriteFile(filepath.Join(root, "deployment.yaml"), []byte(customTpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.DeploymentConfig{
+		ProjectRoot: root,
+		Metadata:    config.MetadataConfig{Name: "myapp"},
+		Spec: config.SpecConfig{
+			Namespace:   "default",
+			Replicas:    1,
+			ServicePort: 8080,
+			Templates:   &config.TemplatesConfig{Deployment: "deployment.yaml"},
+		},
+	}
+	renderer, err := NewRendererForConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewRendererForConfig failed: %v", err)
+	}
+
+	data := NewTemplateData(DeploymentOptions{Config: cfg, ImageRef: "myapp:kudev-abc12345", ImageHash: "abc12345"})
+	deployment, err := renderer.RenderDeployment(data)
+	if err != nil {
+		t.Fatalf("RenderDeployment failed: %v", err)
+	}
+	if deployment.Name != "myapp-custom" {
+		t.Errorf("deployment name = %q, want the custom template's myapp-custom", deployment.Name)
+	}
+
+	// spec.templates.service is unset: the built-in template is used
+	service, err := renderer.RenderService(data)
+	if err != nil {
+		t.Fatalf("RenderService failed: %v", err)
+	}
+	if service.Name != "myapp" {
+		t.Errorf("service name = %q, want myapp", service.Name)
+	}
+
+	cfg.Spec.Templates.Deployment = "missing.yaml"
+	if _, err := NewRendererForConfig(cfg); err == nil {
+		t.Error("expected error for a missing template")
+	}
+}
+
 func TestRenderDeployment(t *testing.T) {
 	renderer, err := NewRenderer(
 		templates.DeploymentTemplate,
@@ -213,3 +267,155 @@ func TestRenderAll(t *testing.T) {
 		t.Error("missing YAML document separator")
 	}
 }
+
+func TestRenderDeployment_WithInstance(t *testing.T) {
+	renderer, err := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	if err != nil {
+		t.Fatalf("NewRenderer failed: %v", err)
+	}
+
+	data := TemplateData{
+		AppName:     "test-app-feature-x",
+		Namespace:   "default",
+		ImageRef:    "test-app:kudev-12345678",
+		ImageHash:   "12345678",
+		ServicePort: 8080,
+		Replicas:    1,
+		Instance:    "feature-x",
+	}
+
+	deployment, err := renderer.RenderDeployment(data)
+	if err != nil {
+		t.Fatalf("RenderDeployment failed: %v", err)
+	}
+
+	if deployment.Labels["kudev-instance"] != "feature-x" {
+		t.Errorf("kudev-instance label = %q, want %q", deployment.Labels["kudev-instance"], "feature-x")
+	}
+	if deployment.Spec.Template.Labels["kudev-instance"] != "feature-x" {
+		t.Error("pod template missing kudev-instance label")
+	}
+
+	service, err := renderer.RenderService(data)
+	if err != nil {
+		t.Fatalf("RenderService failed: %v", err)
+	}
+
+	if service.Labels["kudev-instance"] != "feature-x" {
+		t.Error("service missing kudev-instance label")
+	}
+}
+
+func TestRenderDeployment_CustomTemplateValues(t *testing.T) {
+	customTpl := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .AppName }}
+  namespace: {{ .Namespace }}
+  labels:
+    tier: {{ .Values.tier }}
+    context: {{ .Config.Spec.KubeContext }}
+    {{- if .Values.missing }}
+    unexpected: "true"
+    {{- end }}
+spec:
+  replicas: {{ .Replicas }}
+`
+	renderer, err := NewRenderer(customTpl, templates.ServiceTemplate)
+	if err != nil {
+		t.Fatalf("NewRenderer failed: %v", err)
+	}
+
+	data := NewTemplateData(DeploymentOptions{
+		Config: &config.DeploymentConfig{
+			Metadata: config.MetadataConfig{Name: "myapp"},
+			Spec: config.SpecConfig{
+				Namespace:      "default",
+				Replicas:       1,
+				ServicePort:    8080,
+				KubeContext:    "kind-dev",
+				TemplateValues: map[string]interface{}{"tier": "backend"},
+			},
+		},
+		ImageRef:  "myapp:kudev-abc12345",
+		ImageHash: "abc12345",
+	})
+
+	deployment, err := renderer.RenderDeployment(data)
+	if err != nil {
+		t.Fatalf("RenderDeployment failed: %v", err)
+	}
+
+	if deployment.Labels["tier"] != "backend" {
+		t.Errorf("tier label = %q, want backend", deployment.Labels["tier"])
+	}
+	if deployment.Labels["context"] != "kind-dev" {
+		t.Errorf("context label = %q, want kind-dev", deployment.Labels["context"])
+	}
+	if _, ok := deployment.Labels["unexpected"]; ok {
+		t.Error("missing value should be falsy")
+	}
+}
+
+func TestNewTemplateData_ValuesNeverNil(t *testing.T) {
+	data := NewTemplateData(DeploymentOptions{
+		Config: config.NewDeploymentConfig("myapp"),
+	})
+
+	if data.Values == nil {
+		t.Error("Values should default to an empty map")
+	}
+	if data.Config == nil {
+		t.Error("Config should be exposed")
+	}
+}
+
+func TestRenderDeployment_EnvFromConfigMap(t *testing.T) {
+	renderer, err := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	if err != nil {
+		t.Fatalf("NewRenderer failed: %v", err)
+	}
+
+	data := TemplateData{
+		AppName:     "test-app",
+		Namespace:   "default",
+		ImageRef:    "test-app:kudev-12345678",
+		ImageHash:   "12345678",
+		ServicePort: 8080,
+		Replicas:    1,
+		Env: []EnvVar{
+			{Name: "PLAIN", Value: "x"},
+			{Name: "LOG_LEVEL", ConfigMapKeyRef: &config.ConfigMapKeySelector{Name: "app-config", Key: "log_level"}},
+			{Name: "FEATURE", ConfigMapKeyRef: &config.ConfigMapKeySelector{Name: "flags", Key: "feature", Optional: true}},
+		},
+	}
+
+	deployment, err := renderer.RenderDeployment(data)
+	if err != nil {
+		t.Fatalf("RenderDeployment failed: %v", err)
+	}
+
+	env := deployment.Spec.Template.Spec.Containers[0].Env
+	if len(env) != 3 {
+		t.Fatalf("expected 3 env vars, got %d", len(env))
+	}
+	if env[0].Value != "x" || env[0].ValueFrom != nil {
+		t.Errorf("plain env var = %+v", env[0])
+	}
+
+	ref := env[1].ValueFrom
+	if ref == nil || ref.ConfigMapKeyRef == nil {
+		t.Fatalf("LOG_LEVEL has no configMapKeyRef: %+v", env[1])
+	}
+	if ref.ConfigMapKeyRef.Name != "app-config" || ref.ConfigMapKeyRef.Key != "log_level" {
+		t.Errorf("configMapKeyRef = %+v", ref.ConfigMapKeyRef)
+	}
+	if env[1].Value != "" {
+		t.Errorf("LOG_LEVEL should not have a value, got %q", env[1].Value)
+	}
+
+	optional := env[2].ValueFrom.ConfigMapKeyRef.Optional
+	if optional == nil || !*optional {
+		t.Error("FEATURE should be optional")
+	}
+}
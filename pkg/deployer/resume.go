@@ -0,0 +1,57 @@
+package deployer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// AnnotationConfigHash is a hash of the rendered Deployment and Service,
+// so a restarted 'kudev watch' can tell whether the running app still
+// matches .kudev.yaml before resuming it without a redeploy.
+const AnnotationConfigHash = "kudev.io/config-hash"
+
+// ConfigHash returns the hash Upsert stamps for opts: it changes with
+// anything rendered into the manifests (image, env, ports, resources,
+// ...), but not with the TTL expiry stamped at apply time.
+func (kd *KubernetesDeployer) ConfigHash(opts DeploymentOptions) (string, error) {
+	return kd.configHash(NewTemplateData(opts))
+}
+
+func (kd *KubernetesDeployer) configHash(data TemplateData) (string, error) {
+	manifests, err := kd.renderer.RenderAll(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render manifests: %w", err)
+	}
+	sum := sha256.Sum256([]byte(manifests))
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// stampConfigHash records hash on the applied deployment.
+func stampConfigHash(deployment *appsv1.Deployment, hash string) {
+	deployment.Annotations = mergeLabels(deployment.Annotations, map[string]string{
+		AnnotationConfigHash: hash,
+	})
+}
+
+// CanResume reports whether a restarted 'kudev watch' can keep the
+// running app instead of rebuilding and redeploying it: it must run
+// sourceHash, be healthy, and have been deployed with configHash.
+// Otherwise the reason is returned.
+func CanResume(status *DeploymentStatus, sourceHash, configHash string) (bool, string) {
+	switch {
+	case status.ImageHash != sourceHash:
+		return false, fmt.Sprintf("the source changed since the last deploy (kudev-hash %s, now %s)", status.ImageHash, sourceHash)
+	case status.Image == "":
+		return false, "the running deployment has no image"
+	case !StatusCode(status.Status).IsHealthy():
+		return false, fmt.Sprintf("the running deployment is %s (%d/%d replicas ready)", status.Status, status.ReadyReplicas, status.DesiredReplicas)
+	case status.ConfigHash == "":
+		return false, "the running deployment has no " + AnnotationConfigHash + " annotation"
+	case status.ConfigHash != configHash:
+		return false, "the configuration changed since the last deploy"
+	}
+	return true, ""
+}
@@ -0,0 +1,113 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func resumeOptions(env ...config.EnvVar) DeploymentOptions {
+	return DeploymentOptions{
+		Config: &config.DeploymentConfig{
+			Metadata: config.MetadataConfig{Name: "test-app"},
+			Spec: config.SpecConfig{
+				Namespace:   "default",
+				Replicas:    1,
+				ServicePort: 8080,
+				Env:         env,
+			},
+		},
+		ImageRef:  "test-app:kudev-12345678",
+		ImageHash: "12345678",
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(fake.NewSimpleClientset(), renderer, &util.MockLogger{})
+
+	base, err := kd.ConfigHash(resumeOptions())
+	if err != nil {
+		t.Fatalf("ConfigHash failed: %v", err)
+	}
+	again, _ := kd.ConfigHash(resumeOptions())
+	if base != again {
+		t.Errorf("ConfigHash not deterministic: %s != %s", base, again)
+	}
+
+	changed, _ := kd.ConfigHash(resumeOptions(config.EnvVar{Name: "LOG_LEVEL", Value: "debug"}))
+	if changed == base {
+		t.Error("ConfigHash should change with spec.env")
+	}
+}
+
+func TestUpsert_StampsConfigHash(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	kd := NewKubernetesDeployer(client, renderer, &util.MockLogger{})
+	ctx := context.Background()
+
+	// Created, then updated with new env while frozen
+	if _, err := kd.Upsert(ctx, resumeOptions()); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if err := kd.SetFrozen(ctx, "test-app", "default", true); err != nil {
+		t.Fatalf("SetFrozen failed: %v", err)
+	}
+
+	opts := resumeOptions(config.EnvVar{Name: "LOG_LEVEL", Value: "debug"})
+	status, err := kd.Upsert(ctx, opts)
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	want, _ := kd.ConfigHash(opts)
+	if status.ConfigHash != want {
+		t.Errorf("ConfigHash = %q, want %q", status.ConfigHash, want)
+	}
+	if frozen, _ := kd.IsFrozen(ctx, "test-app", "default"); !frozen {
+		t.Error("update dropped the kudev.io/frozen annotation")
+	}
+}
+
+func TestCanResume(t *testing.T) {
+	healthy := DeploymentStatus{
+		Status:          "Running",
+		ReadyReplicas:   1,
+		DesiredReplicas: 1,
+		ImageHash:       "12345678",
+		Image:           "test-app:kudev-12345678",
+		ConfigHash:      "c0ffee",
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(s *DeploymentStatus)
+		wantReason string
+	}{
+		{name: "resumable", modify: func(s *DeploymentStatus) {}},
+		{name: "source changed", modify: func(s *DeploymentStatus) { s.ImageHash = "87654321" }, wantReason: "source changed"},
+		{name: "no image", modify: func(s *DeploymentStatus) { s.Image = "" }, wantReason: "no image"},
+		{name: "unhealthy", modify: func(s *DeploymentStatus) { s.Status = "Degraded"; s.ReadyReplicas = 0 }, wantReason: "is Degraded (0/1 replicas ready)"},
+		{name: "older kudev", modify: func(s *DeploymentStatus) { s.ConfigHash = "" }, wantReason: "no kudev.io/config-hash"},
+		{name: "config changed", modify: func(s *DeploymentStatus) { s.ConfigHash = "beef" }, wantReason: "configuration changed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := healthy
+			tt.modify(&status)
+
+			ok, reason := CanResume(&status, "12345678", "c0ffee")
+			if ok != (tt.wantReason == "") || !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("CanResume() = %v, %q, want reason containing %q", ok, reason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -3,30 +3,25 @@ package deployer
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
 // Status returns the current deployment status.
 func (kd *KubernetesDeployer) Status(ctx context.Context, appName, namespace string) (*DeploymentStatus, error) {
-	kd.logger.Debug("getting deployment status",
+	kd.log(ctx).Debug("getting deployment status",
 		"app", appName,
 		"namespace", namespace,
 	)
 
-	// Get deployment
-	deployment, err := kd.clientset.AppsV1().Deployments(namespace).Get(
-		ctx, appName, metav1.GetOptions{},
-	)
+	// Get deployment (the active slot in blue/green mode)
+	deployment, err := kd.getAppDeployment(ctx, appName, namespace)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil, fmt.Errorf("deployment not found: %s/%s", namespace, appName)
-		}
-		return nil, fmt.Errorf("failed to get deployment: %w", err)
+		return nil, describeGetError(err, appName, namespace)
 	}
 
 	// Get pods by label selector
@@ -65,7 +60,22 @@ func (kd *KubernetesDeployer) Status(ctx context.Context, appName, namespace str
 		Pods:            podStatuses,
 		Message:         buildStatusMessage(statusCode, deployment.Status.ReadyReplicas, desiredReplicas),
 		ImageHash:       imageHash,
+		ConfigHash:      deployment.Annotations[AnnotationConfigHash],
 		LastUpdated:     time.Now(),
+		ExpiresAt:       expiresAt(deployment),
+	}
+	if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+		status.Image = containers[0].Image
+	}
+
+	// Events explain why pods are not running; they are best effort
+	if statusCode != StatusRunning {
+		events, err := kd.warningEvents(ctx, namespace, deployment.Name, pods)
+		if err != nil {
+			kd.log(ctx).Debug("failed to get warning events", "error", err)
+		}
+		status.Events = events
+		status.Message = withLatestWarning(status.Message, events)
 	}
 
 	return status, nil
@@ -94,6 +104,11 @@ func buildPodStatuses(pods *corev1.PodList) []PodStatus {
 			if cs.State.Terminated != nil && cs.State.Terminated.Message != "" {
 				status.Message = cs.State.Terminated.Message
 			}
+
+			// OOM kills leave no event, only the container's last state
+			if last := cs.LastTerminationState.Terminated; last != nil && last.Reason == "OOMKilled" && status.Message == "" {
+				status.Message = fmt.Sprintf("container %s was OOMKilled (exceeded its memory limit)", cs.Name)
+			}
 		}
 
 		statuses = append(statuses, status)
@@ -151,39 +166,90 @@ func buildStatusMessage(status StatusCode, ready, desired int32) string {
 	}
 }
 
-// WaitForReady waits until deployment is ready or timeout.
-func (kd *KubernetesDeployer) WaitForReady(ctx context.Context, appName, namespace string, timeout time.Duration) error {
-	deadline := time.Now().Add(timeout)
+// WaitForReady waits until the readiness check of opts.Readiness passes
+// or timeout. Without a readiness config, all replicas must be ready.
+func (kd *KubernetesDeployer) WaitForReady(ctx context.Context, opts WaitOptions) error {
+	strategy := opts.Readiness.EffectiveStrategy()
+	check, ok := kd.readiness[strategy]
+	if !ok {
+		return fmt.Errorf("unknown readiness strategy %q", strategy)
+	}
+
+	deadline := kd.clock.Now().Add(opts.EffectiveTimeout())
+	var lastErr error
+	var lastProgress string
 
 	for {
-		if time.Now().After(deadline) {
-			return fmt.Errorf("timeout waiting for deployment to be ready")
+		if kd.clock.Now().After(deadline) {
+			if lastErr != nil {
+				return fmt.Errorf("timeout waiting for deployment to be ready (%s): %w", strategy, lastErr)
+			}
+			return fmt.Errorf("timeout waiting for deployment to be ready (%s)", strategy)
 		}
 
-		status, err := kd.Status(ctx, appName, namespace)
+		ready, err := check(ctx, opts)
 		if err != nil {
-			// Deployment might not exist yet
-			kd.logger.Debug("waiting for deployment", "error", err)
-		} else if status.IsReady() {
-			kd.logger.Info("deployment is ready",
-				"app", appName,
-				"replicas", status.ReadyReplicas,
+			lastErr = err
+			kd.log(ctx).Debug("waiting for readiness", "strategy", strategy, "error", err)
+		} else if ready {
+			kd.log(ctx).Info("deployment is ready",
+				"app", opts.AppName,
+				"strategy", strategy,
 			)
 			return nil
-		} else {
-			kd.logger.Debug("waiting for deployment",
-				"app", appName,
-				"ready", status.ReadyReplicas,
-				"desired", status.DesiredReplicas,
-			)
+		}
+
+		if opts.Progress != nil {
+			lastProgress = kd.reportProgress(ctx, opts, lastProgress)
 		}
 
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(2 * time.Second):
+		case <-kd.clock.After(opts.EffectivePollInterval()):
 			// Continue polling
 		}
 	}
 }
+
+// reportProgress passes the pods' startup state to opts.Progress if it
+// differs from last (the previous report), returning the new report.
+func (kd *KubernetesDeployer) reportProgress(ctx context.Context, opts WaitOptions, last string) string {
+	pods, err := kd.PodsProgress(ctx, opts.AppName, opts.Namespace)
+	if err != nil {
+		kd.log(ctx).Debug("failed to get pod progress", "error", err)
+		return last
+	}
+
+	lines := make([]string, len(pods))
+	for i, p := range pods {
+		lines[i] = p.String()
+	}
+	if report := strings.Join(lines, "\n"); report != last {
+		opts.Progress(pods)
+		return report
+	}
+	return last
+}
+
+// DeployedImageHashes returns the kudev-hash of every kudev-managed
+// Deployment in namespace ("" for all namespaces), blue/green slots and
+// --instance variants included. Images built from these hashes are still
+// in use and must not be pruned.
+func (kd *KubernetesDeployer) DeployedImageHashes(ctx context.Context, namespace string) ([]string, error) {
+	deployments, err := kd.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "managed-by=kudev",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	var hashes []string
+	for _, d := range deployments.Items {
+		if h := d.Labels["kudev-hash"]; h != "" {
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes, nil
+}
@@ -0,0 +1,148 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// UpsertStrategy creates or updates a single object of one kind.
+// The object may be typed or *unstructured.Unstructured.
+type UpsertStrategy func(ctx context.Context, obj runtime.Object) error
+
+// StrategyRegistry maps object kinds to their upsert strategy.
+type StrategyRegistry struct {
+	strategies map[schema.GroupKind]UpsertStrategy
+}
+
+// NewStrategyRegistry creates an empty registry.
+func NewStrategyRegistry() *StrategyRegistry {
+	return &StrategyRegistry{
+		strategies: make(map[schema.GroupKind]UpsertStrategy),
+	}
+}
+
+// Register sets the strategy for a kind, replacing any previous one.
+func (r *StrategyRegistry) Register(gk schema.GroupKind, s UpsertStrategy) {
+	r.strategies[gk] = s
+}
+
+// Get returns the strategy for a kind.
+func (r *StrategyRegistry) Get(gk schema.GroupKind) (UpsertStrategy, bool) {
+	s, ok := r.strategies[gk]
+	return s, ok
+}
+
+// KindOf returns the group and kind of obj.
+// Typed objects without TypeMeta are resolved through the client-go scheme.
+func KindOf(obj runtime.Object) (schema.GroupKind, error) {
+	gvk, err := gvkOf(obj)
+	if err != nil {
+		return schema.GroupKind{}, err
+	}
+	return gvk.GroupKind(), nil
+}
+
+// gvkOf returns the full group/version/kind of obj.
+func gvkOf(obj runtime.Object) (schema.GroupVersionKind, error) {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Kind != "" {
+		return gvk, nil
+	}
+
+	gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("cannot determine kind of %T: %w", obj, err)
+	}
+	return gvks[0], nil
+}
+
+// RegisterStrategy adds or replaces the upsert strategy for a kind,
+// so Apply can handle objects beyond the built-in ones.
+func (kd *KubernetesDeployer) RegisterStrategy(gk schema.GroupKind, s UpsertStrategy) {
+	kd.strategies.Register(gk, s)
+}
+
+// Apply creates or updates objects in order, using the strategy
+// registered for each object's kind.
+func (kd *KubernetesDeployer) Apply(ctx context.Context, objects []runtime.Object) error {
+	for _, obj := range objects {
+		gk, err := KindOf(obj)
+		if err != nil {
+			return err
+		}
+
+		strategy, ok := kd.strategies.Get(gk)
+		if !ok {
+			return fmt.Errorf("no upsert strategy registered for kind %s", gk)
+		}
+
+		if err := strategy(ctx, obj); err != nil {
+			name := ""
+			if accessor, aerr := meta.Accessor(obj); aerr == nil {
+				name = " " + accessor.GetName()
+			}
+			return explainRejection(obj, fmt.Errorf("failed to upsert %s%s: %w", strings.ToLower(gk.Kind), name, err))
+		}
+	}
+	return nil
+}
+
+// registerDefaultStrategies registers the kinds kudev renders itself.
+func (kd *KubernetesDeployer) registerDefaultStrategies() {
+	kd.strategies.Register(schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		func(ctx context.Context, obj runtime.Object) error {
+			d, ok := obj.(*appsv1.Deployment)
+			if !ok {
+				d = &appsv1.Deployment{}
+				if err := fromUnstructured(obj, d); err != nil {
+					return err
+				}
+			}
+			return kd.upsertDeployment(ctx, d)
+		})
+
+	kd.strategies.Register(schema.GroupKind{Kind: "Service"},
+		func(ctx context.Context, obj runtime.Object) error {
+			s, ok := obj.(*corev1.Service)
+			if !ok {
+				s = &corev1.Service{}
+				if err := fromUnstructured(obj, s); err != nil {
+					return err
+				}
+			}
+			return kd.upsertService(ctx, s)
+		})
+
+	kd.strategies.Register(schema.GroupKind{Group: "policy", Kind: "PodDisruptionBudget"},
+		func(ctx context.Context, obj runtime.Object) error {
+			p, ok := obj.(*policyv1.PodDisruptionBudget)
+			if !ok {
+				p = &policyv1.PodDisruptionBudget{}
+				if err := fromUnstructured(obj, p); err != nil {
+					return err
+				}
+			}
+			return kd.upsertPDB(ctx, p)
+		})
+}
+
+// fromUnstructured converts an unstructured object into out.
+func fromUnstructured(obj runtime.Object, out interface{}) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unsupported object type %T", obj)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, out); err != nil {
+		return fmt.Errorf("failed to convert %s: %w", u.GetKind(), err)
+	}
+	return nil
+}
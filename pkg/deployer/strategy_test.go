@@ -0,0 +1,115 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func newStrategyTestDeployer(t *testing.T) (*KubernetesDeployer, *fake.Clientset) {
+	t.Helper()
+	fakeClient := fake.NewSimpleClientset()
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	return NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{}), fakeClient
+}
+
+func TestKindOf(t *testing.T) {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("networking.k8s.io/v1")
+	u.SetKind("Ingress")
+
+	tests := []struct {
+		name string
+		obj  runtime.Object
+		want schema.GroupKind
+	}{
+		{"typed without TypeMeta", &corev1.ConfigMap{}, schema.GroupKind{Kind: "ConfigMap"}},
+		{"typed policy group", newPDB(TemplateData{AppName: "a"}), schema.GroupKind{Group: "policy", Kind: "PodDisruptionBudget"}},
+		{"unstructured", u, schema.GroupKind{Group: "networking.k8s.io", Kind: "Ingress"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KindOf(tt.obj)
+			if err != nil {
+				t.Fatalf("KindOf failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("KindOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply_UnregisteredKind(t *testing.T) {
+	kd, _ := newStrategyTestDeployer(t)
+
+	err := kd.Apply(context.Background(), []runtime.Object{&corev1.ConfigMap{}})
+	if err == nil || !strings.Contains(err.Error(), "no upsert strategy registered for kind ConfigMap") {
+		t.Errorf("expected unregistered kind error, got %v", err)
+	}
+}
+
+func TestApply_RegisteredStrategy(t *testing.T) {
+	kd, fakeClient := newStrategyTestDeployer(t)
+	ctx := context.Background()
+
+	kd.RegisterStrategy(schema.GroupKind{Kind: "ConfigMap"}, func(ctx context.Context, obj runtime.Object) error {
+		cm := obj.(*corev1.ConfigMap)
+		_, err := fakeClient.CoreV1().ConfigMaps(cm.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	})
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}}
+	opts := preflightOpts(1)
+	opts.Objects = []runtime.Object{cm}
+
+	if _, err := kd.Upsert(ctx, opts); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	if _, err := fakeClient.CoreV1().ConfigMaps("default").Get(ctx, "settings", metav1.GetOptions{}); err != nil {
+		t.Errorf("configmap not created: %v", err)
+	}
+}
+
+func TestApply_Unstructured(t *testing.T) {
+	kd, fakeClient := newStrategyTestDeployer(t)
+	ctx := context.Background()
+
+	svc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name":      "extra",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(80)},
+			},
+		},
+	}}
+
+	if err := kd.Apply(ctx, []runtime.Object{svc}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	got, err := fakeClient.CoreV1().Services("default").Get(ctx, "extra", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("service not created: %v", err)
+	}
+	if len(got.Spec.Ports) != 1 || got.Spec.Ports[0].Port != 80 {
+		t.Errorf("ports = %+v, want port 80", got.Spec.Ports)
+	}
+}
@@ -0,0 +1,90 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+// stampExpiry sets the reaper annotations on deployment when cfg has a
+// spec.ttl. Every deploy pushes the expiry back. It is stamped on the
+// applied object only, so rendered manifests stay reproducible.
+func stampExpiry(deployment *appsv1.Deployment, cfg *config.DeploymentConfig, now time.Time) {
+	if cfg == nil || cfg.Spec.TTL.Duration <= 0 {
+		return
+	}
+	ttl := cfg.Spec.TTL.Duration
+	deployment.Annotations = mergeLabels(deployment.Annotations, map[string]string{
+		AnnotationTTL:       ttl.String(),
+		AnnotationExpiresAt: now.Add(ttl).UTC().Format(time.RFC3339),
+	})
+}
+
+// expiresAt returns the expiry stamped on deployment, or nil when it has
+// none (no spec.ttl) or it does not parse.
+func expiresAt(deployment *appsv1.Deployment) *time.Time {
+	value, ok := deployment.Annotations[AnnotationExpiresAt]
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// ExpiredApp is a kudev app whose spec.ttl ran out.
+type ExpiredApp struct {
+	Name      string
+	Namespace string
+	ExpiresAt time.Time
+}
+
+// ExpiredApps lists the kudev apps whose expiry is before now, in
+// namespace (all namespaces when empty), sorted by namespace and name.
+// An app with several Deployments (blue/green slots) goes by the latest
+// expiry, as the reaper does.
+func (kd *KubernetesDeployer) ExpiredApps(ctx context.Context, namespace string, now time.Time) ([]ExpiredApp, error) {
+	deployments, err := kd.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "managed-by=kudev",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	latest := make(map[ExpiredApp]time.Time)
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		expiry := expiresAt(d)
+		app := d.Labels["app"]
+		if expiry == nil || app == "" {
+			continue
+		}
+		key := ExpiredApp{Name: app, Namespace: d.Namespace}
+		if expiry.After(latest[key]) {
+			latest[key] = *expiry
+		}
+	}
+
+	var expired []ExpiredApp
+	for app, expiry := range latest {
+		if expiry.Before(now) {
+			app.ExpiresAt = expiry
+			expired = append(expired, app)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		if expired[i].Namespace != expired[j].Namespace {
+			return expired[i].Namespace < expired[j].Namespace
+		}
+		return expired[i].Name < expired[j].Name
+	})
+	return expired, nil
+}
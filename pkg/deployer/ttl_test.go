@@ -0,0 +1,87 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestStampExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	cfg := config.NewDeploymentConfig("myapp")
+
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"keep": "me"}}}
+	stampExpiry(d, cfg, now)
+	if _, ok := d.Annotations[AnnotationExpiresAt]; ok {
+		t.Errorf("expiry stamped without spec.ttl: %v", d.Annotations)
+	}
+
+	cfg.Spec.TTL = config.Duration{Duration: 72 * time.Hour}
+	stampExpiry(d, cfg, now)
+	if got := d.Annotations[AnnotationExpiresAt]; got != "2026-10-18T12:00:00Z" {
+		t.Errorf("%s = %q, want 2026-10-18T12:00:00Z", AnnotationExpiresAt, got)
+	}
+	if got := d.Annotations[AnnotationTTL]; got != "72h0m0s" {
+		t.Errorf("%s = %q, want 72h0m0s", AnnotationTTL, got)
+	}
+	if d.Annotations["keep"] != "me" {
+		t.Error("existing annotations were dropped")
+	}
+}
+
+func expiringDeployment(name, namespace, app, expiresAt string) *appsv1.Deployment {
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels:    map[string]string{"app": app, "managed-by": "kudev"},
+	}}
+	if expiresAt != "" {
+		d.Annotations = map[string]string{AnnotationExpiresAt: expiresAt}
+	}
+	return d
+}
+
+func TestExpiredApps(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	fakeClient := fake.NewSimpleClientset(
+		expiringDeployment("old", "dev", "old", "2026-10-14T12:00:00Z"),
+		expiringDeployment("fresh", "dev", "fresh", "2026-10-16T12:00:00Z"),
+		expiringDeployment("no-ttl", "dev", "no-ttl", ""),
+		expiringDeployment("garbage", "dev", "garbage", "tomorrow"),
+		expiringDeployment("stale", "team", "stale", "2026-10-01T00:00:00Z"),
+		// blue/green: the slot deployed last keeps the app alive
+		expiringDeployment("bg-blue", "dev", "bg", "2026-10-10T00:00:00Z"),
+		expiringDeployment("bg-green", "dev", "bg", "2026-10-20T00:00:00Z"),
+	)
+	deployer := NewKubernetesDeployer(fakeClient, nil, &util.MockLogger{})
+
+	expired, err := deployer.ExpiredApps(context.Background(), "", now)
+	if err != nil {
+		t.Fatalf("ExpiredApps() error = %v", err)
+	}
+	var got []string
+	for _, app := range expired {
+		got = append(got, fmt.Sprintf("%s/%s@%s", app.Namespace, app.Name, app.ExpiresAt.Format(time.RFC3339)))
+	}
+	want := "dev/old@2026-10-14T12:00:00Z,team/stale@2026-10-01T00:00:00Z"
+	if strings.Join(got, ",") != want {
+		t.Errorf("ExpiredApps() = %v, want %s", got, want)
+	}
+
+	expired, err = deployer.ExpiredApps(context.Background(), "team", now)
+	if err != nil {
+		t.Fatalf("ExpiredApps() error = %v", err)
+	}
+	if len(expired) != 1 || expired[0].Name != "stale" {
+		t.Errorf("ExpiredApps(team) = %v, want only stale", expired)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/runtime"
+
 	"github.com/nanaki-93/kudev/pkg/config"
 )
 
@@ -21,63 +23,159 @@ type TemplateData struct {
 	ServicePort int32
 	Replicas    int32
 	Env         []EnvVar
+
+	// ContainerName is the app container's name
+	// (see config.DeploymentConfig.EffectiveContainerName).
+	ContainerName string
+
+	// Instance is the optional instance identifier (see --instance).
+	// Rendered as the kudev-instance label when set.
+	Instance string
+
+	// Name is AppName without the instance suffix, rendered as the
+	// app.kubernetes.io/name label.
+	Name string
+
+	// ZeroDowntime renders a surge-only rolling update strategy.
+	ZeroDowntime bool
+
+	// Resources are the container requests and limits, with defaults
+	// applied (see config.ResourcesConfig). Empty values are not rendered.
+	Resources Resources
+
+	// LivenessProbe and ReadinessProbe are rendered when set
+	// (see config.ProbesConfig).
+	LivenessProbe  *Probe
+	ReadinessProbe *Probe
+
+	// Color is the blue/green slot (see BlueGreenDeployer).
+	// When set, the Deployment is named <app>-<color> and the
+	// Service selects only pods of that color.
+	Color string
+
+	// Config is the full loaded configuration, for custom templates
+	// that need fields not mapped above (e.g. {{ .Config.Spec.KubeContext }}).
+	Config *config.DeploymentConfig
+
+	// Values holds spec.templateValues (never nil).
+	Values map[string]interface{}
+}
+
+// Resources holds container resource quantities as rendered into templates.
+type Resources struct {
+	Requests config.ResourceValues
+	Limits   config.ResourceValues
+}
+
+// Probe is an HTTP GET probe as rendered into templates.
+type Probe struct {
+	Path string
+
+	// Port defaults to the service port.
+	Port int32
+
+	InitialDelaySeconds int32
+	PeriodSeconds       int32
 }
 
 type EnvVar struct {
 	Name  string
 	Value string
+
+	// ConfigMapKeyRef is set when the value comes from a ConfigMap
+	// (Value is then ignored).
+	ConfigMapKeyRef *config.ConfigMapKeySelector
+
+	// SecretKeyRef is set when the value comes from a Secret
+	// (Value is then ignored).
+	SecretKeyRef *config.SecretKeySelector
 }
 
 // DeploymentStatus represents the current state of a deployment.
 type DeploymentStatus struct {
 	// DeploymentName is the name of the deployment.
-	DeploymentName string
+	DeploymentName string `json:"deploymentName"`
 
 	// Namespace is the Kubernetes namespace.
-	Namespace string
+	Namespace string `json:"namespace"`
 
 	// ReadyReplicas is the number of ready pod replicas.
-	ReadyReplicas int32
+	ReadyReplicas int32 `json:"readyReplicas"`
 
 	// DesiredReplicas is the desired number of replicas.
-	DesiredReplicas int32
+	DesiredReplicas int32 `json:"desiredReplicas"`
 
 	// Status is a human-readable status string.
 	// Values: "Running", "Pending", "Degraded", "Failed", "Unknown"
-	Status string
+	Status string `json:"status"`
 
 	// Pods contains status information for each pod.
-	Pods []PodStatus
+	Pods []PodStatus `json:"pods"`
 
-	// Message is a helpful status message for the user.
-	Message string
+	// Message is a helpful status message for the user. When pods are
+	// not running it names the latest warning event.
+	Message string `json:"message,omitempty"`
+
+	// Events are the recent warning events of the deployment and its
+	// pods (e.g. FailedScheduling, image pull failures), newest first.
+	// Only collected when not all replicas are ready.
+	Events []StatusEvent `json:"events,omitempty"`
 
 	// ImageHash is the currently deployed source hash.
-	ImageHash string
+	ImageHash string `json:"imageHash,omitempty"`
+
+	// Image is the image the app container runs.
+	Image string `json:"image,omitempty"`
+
+	// ConfigHash is the kudev.io/config-hash of the deployed manifests
+	// (see ConfigHash), empty when deployed without one.
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// ExpiresAt is when the app may be reaped (see spec.ttl), nil without
+	// a TTL.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 
 	// LastUpdated is when the deployment was last updated.
-	LastUpdated time.Time
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// StatusEvent is a Kubernetes warning event shown in DeploymentStatus.
+type StatusEvent struct {
+	// Object is the kind and name the event is about, e.g. "Pod/myapp-abc".
+	Object string `json:"object"`
+
+	// Reason is the event reason, e.g. "FailedScheduling".
+	Reason string `json:"reason"`
+
+	// Message is the event message.
+	Message string `json:"message"`
+
+	// Count is how many times the event occurred.
+	Count int32 `json:"count,omitempty"`
+
+	// LastSeen is when the event last occurred.
+	LastSeen time.Time `json:"lastSeen"`
 }
 
 // PodStatus represents the status of an individual pod.
 type PodStatus struct {
 	// Name is the pod name.
-	Name string
+	Name string `json:"name"`
 
 	// Status is the pod phase (Running, Pending, Failed, etc).
-	Status string
+	Status string `json:"status"`
 
 	// Ready indicates if the pod is ready to serve traffic.
-	Ready bool
+	Ready bool `json:"ready"`
 
 	// Restarts is the total container restart count.
-	Restarts int32
+	Restarts int32 `json:"restarts"`
 
 	// CreatedAt is when the pod was created.
-	CreatedAt time.Time
+	CreatedAt time.Time `json:"createdAt"`
 
 	// Message is additional status info (e.g., crash reason).
-	Message string
+	Message string `json:"message,omitempty"`
 }
 
 // DeploymentOptions contains input for deployment operations.
@@ -91,6 +189,15 @@ type DeploymentOptions struct {
 
 	// ImageHash is the source code hash (from Phase 2).
 	ImageHash string
+
+	// Objects are additional objects (e.g. ConfigMaps) applied before
+	// the Deployment and Service. Each kind needs a registered
+	// UpsertStrategy; typed and unstructured objects are accepted.
+	Objects []runtime.Object
+
+	// DryRun sends the objects with server-side dry-run instead of
+	// applying them, surfacing validation and admission errors only.
+	DryRun bool
 }
 
 // Deployer is the interface for Kubernetes deployment operations.
@@ -100,6 +207,10 @@ type Deployer interface {
 	// Returns the status after deployment.
 	Upsert(ctx context.Context, opts DeploymentOptions) (*DeploymentStatus, error)
 
+	// Apply creates or updates arbitrary objects in order, using the
+	// upsert strategy registered for each object's kind.
+	Apply(ctx context.Context, objects []runtime.Object) error
+
 	// Delete removes the deployment and associated service.
 	// It only deletes resources with the `managed-by: kudev` label.
 	// Safe to call multiple times (idempotent).
@@ -108,6 +219,66 @@ type Deployer interface {
 	// Status returns the current deployment status.
 	// Returns error if deployment doesn't exist.
 	Status(ctx context.Context, appName, namespace string) (*DeploymentStatus, error)
+
+	// WaitForReady blocks until the app is ready according to
+	// opts.Readiness, the timeout expires or ctx is cancelled.
+	WaitForReady(ctx context.Context, opts WaitOptions) error
+
+	// WaitForDeletion blocks until the app's deployments and their pods
+	// are gone, the timeout expires or ctx is cancelled.
+	WaitForDeletion(ctx context.Context, opts WaitOptions) error
+}
+
+// Defaults for WaitOptions.
+const (
+	DefaultWaitTimeout      = 5 * time.Minute
+	DefaultWaitPollInterval = 2 * time.Second
+)
+
+// WaitOptions contains input for WaitForReady and WaitForDeletion.
+type WaitOptions struct {
+	// AppName is the application (metadata.name) to wait for.
+	// WaitForDeletion waits for every kudev-managed app when empty.
+	AppName string
+
+	// Namespace is the namespace the application runs in.
+	Namespace string
+
+	// Timeout bounds the wait.
+	// Default: DefaultWaitTimeout (if not specified or 0)
+	Timeout time.Duration
+
+	// PollInterval is the delay between checks.
+	// Default: DefaultWaitPollInterval (if not specified or 0)
+	PollInterval time.Duration
+
+	// Readiness selects how WaitForReady decides the app is ready.
+	// Default: rollout (if nil)
+	Readiness *config.ReadinessConfig
+
+	// WorkDir is where the command readiness strategy runs
+	// (usually the project root).
+	WorkDir string
+
+	// Progress, when set, receives the pods' startup state (container
+	// states, image pulls) whenever it changed while WaitForReady waits.
+	Progress func([]PodProgress)
+}
+
+// EffectiveTimeout returns the timeout, falling back to the default.
+func (o WaitOptions) EffectiveTimeout() time.Duration {
+	if o.Timeout <= 0 {
+		return DefaultWaitTimeout
+	}
+	return o.Timeout
+}
+
+// EffectivePollInterval returns the poll interval, falling back to the default.
+func (o WaitOptions) EffectivePollInterval() time.Duration {
+	if o.PollInterval <= 0 {
+		return DefaultWaitPollInterval
+	}
+	return o.PollInterval
 }
 
 // StatusCode represents deployment health.
@@ -146,10 +317,30 @@ func NewTemplateData(opts DeploymentOptions) TemplateData {
 	// Convert config.EnvVar to deployer.EnvVar
 	var envVars []EnvVar
 	for _, e := range opts.Config.Spec.Env {
-		envVars = append(envVars, EnvVar{
+		env := EnvVar{
 			Name:  e.Name,
 			Value: e.Value,
-		})
+		}
+		if e.ValueFrom != nil {
+			env.ConfigMapKeyRef = e.ValueFrom.ConfigMapKeyRef
+			env.SecretKeyRef = e.ValueFrom.SecretKeyRef
+		}
+		envVars = append(envVars, env)
+	}
+	for _, e := range opts.Config.ServiceURLEnv() {
+		envVars = append(envVars, EnvVar{Name: e.Name, Value: e.Value})
+	}
+
+	// Non-nil so templates can index .Values without guarding
+	values := opts.Config.Spec.TemplateValues
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	var liveness, readiness *Probe
+	if probes := opts.Config.Spec.Probes; probes != nil {
+		liveness = newProbe(probes.Liveness, opts.Config.Spec.ServicePort)
+		readiness = newProbe(probes.Readiness, opts.Config.Spec.ServicePort)
 	}
 
 	return TemplateData{
@@ -160,7 +351,48 @@ func NewTemplateData(opts DeploymentOptions) TemplateData {
 		ServicePort: opts.Config.Spec.ServicePort,
 		Replicas:    opts.Config.Spec.Replicas,
 		Env:         envVars,
+		Instance:    opts.Config.Instance,
+		Name:        baseName(opts.Config),
+
+		ContainerName: opts.Config.EffectiveContainerName(),
+
+		ZeroDowntime: opts.Config.Spec.ZeroDowntime,
+		Resources: Resources{
+			Requests: opts.Config.Spec.Resources.EffectiveRequests(),
+			Limits:   opts.Config.Spec.Resources.EffectiveLimits(),
+		},
+		LivenessProbe:  liveness,
+		ReadinessProbe: readiness,
+
+		Config: opts.Config,
+		Values: values,
+	}
+}
+
+// newProbe converts a configured probe, defaulting its port to servicePort.
+// Returns nil for a nil probe.
+func newProbe(p *config.ProbeConfig, servicePort int32) *Probe {
+	if p == nil {
+		return nil
+	}
+	port := p.HTTPGet.Port
+	if port == 0 {
+		port = servicePort
+	}
+	return &Probe{
+		Path:                p.HTTPGet.Path,
+		Port:                port,
+		InitialDelaySeconds: p.InitialDelaySeconds,
+		PeriodSeconds:       p.PeriodSeconds,
+	}
+}
+
+// baseName returns the application name without the --instance suffix.
+func baseName(cfg *config.DeploymentConfig) string {
+	if cfg.Instance == "" {
+		return cfg.Metadata.Name
 	}
+	return strings.TrimSuffix(cfg.Metadata.Name, "-"+cfg.Instance)
 }
 
 // Validate checks that TemplateData has all required fields.
@@ -46,6 +46,31 @@ func TestNewTemplateData(t *testing.T) {
 	if len(data.Env) != 1 {
 		t.Errorf("len(Env) = %d, want 1", len(data.Env))
 	}
+
+	if data.Resources.Requests.CPU != config.DefaultCPURequest || data.Resources.Limits.Memory != config.DefaultMemoryLimit {
+		t.Errorf("Resources = %+v, want defaults", data.Resources)
+	}
+}
+
+func TestNewTemplateData_ServiceURLs(t *testing.T) {
+	cfg := &config.DeploymentConfig{
+		Metadata: config.MetadataConfig{Name: "web"},
+		Spec: config.SpecConfig{
+			Namespace:   "default",
+			ServicePort: 3000,
+			Env:         []config.EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+		},
+		Siblings: []config.ServiceRef{{Name: "api", Namespace: "default", Port: 8080}},
+	}
+
+	data := NewTemplateData(DeploymentOptions{Config: cfg, ImageRef: "web:latest"})
+
+	if len(data.Env) != 2 {
+		t.Fatalf("Env = %+v, want LOG_LEVEL and API_URL", data.Env)
+	}
+	if data.Env[1].Name != "API_URL" || data.Env[1].Value != "http://api.default.svc.cluster.local:8080" {
+		t.Errorf("Env[1] = %+v", data.Env[1])
+	}
 }
 
 func TestTemplateDataValidate(t *testing.T) {
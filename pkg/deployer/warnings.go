@@ -0,0 +1,69 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaxStatusEvents caps the warning events kept in DeploymentStatus.
+const MaxStatusEvents = 5
+
+// warningEvents returns the most recent warning events about the
+// deployment, its ReplicaSets and the given pods, newest first.
+func (kd *KubernetesDeployer) warningEvents(ctx context.Context, namespace, deploymentName string, pods *corev1.PodList) ([]StatusEvent, error) {
+	list, err := kd.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "type=" + corev1.EventTypeWarning,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	podNames := make(map[string]bool, len(pods.Items))
+	for _, pod := range pods.Items {
+		podNames[pod.Name] = true
+	}
+
+	var events []StatusEvent
+	for _, ev := range list.Items {
+		// The fake clientset ignores field selectors
+		if ev.Type != corev1.EventTypeWarning {
+			continue
+		}
+		obj := ev.InvolvedObject
+		switch {
+		case obj.Kind == "Pod" && podNames[obj.Name]:
+		case obj.Kind == "Deployment" && obj.Name == deploymentName:
+		case obj.Kind == "ReplicaSet" && strings.HasPrefix(obj.Name, deploymentName+"-"):
+		default:
+			continue
+		}
+		events = append(events, StatusEvent{
+			Object:   obj.Kind + "/" + obj.Name,
+			Reason:   ev.Reason,
+			Message:  strings.TrimSpace(ev.Message),
+			Count:    ev.Count,
+			LastSeen: eventTime(ev),
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastSeen.After(events[j].LastSeen)
+	})
+	if len(events) > MaxStatusEvents {
+		events = events[:MaxStatusEvents]
+	}
+	return events, nil
+}
+
+// withLatestWarning appends the newest warning event to a status message.
+func withLatestWarning(message string, events []StatusEvent) string {
+	if len(events) == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s - %s: %s", message, events[0].Reason, events[0].Message)
+}
@@ -0,0 +1,115 @@
+package deployer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func testEvent(name, kind, object, eventType, reason string, age time.Duration) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: object},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " message",
+		Count:          2,
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-age)),
+	}
+}
+
+func TestStatus_WarningEvents(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app-abc123",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-app"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+
+	fakeClient := fake.NewSimpleClientset(deployment, pod,
+		testEvent("e1", "Pod", "test-app-abc123", corev1.EventTypeWarning, "FailedScheduling", time.Minute),
+		testEvent("e2", "Pod", "test-app-abc123", corev1.EventTypeNormal, "Scheduled", 0),
+		testEvent("e3", "ReplicaSet", "test-app-7d9f", corev1.EventTypeWarning, "FailedCreate", 2*time.Minute),
+		testEvent("e4", "Pod", "other-app-xyz", corev1.EventTypeWarning, "BackOff", 0),
+	)
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	deployer := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+	status, err := deployer.Status(context.Background(), "test-app", "default")
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+
+	if len(status.Events) != 2 {
+		t.Fatalf("events = %+v, want FailedScheduling and FailedCreate", status.Events)
+	}
+	if ev := status.Events[0]; ev.Object != "Pod/test-app-abc123" || ev.Reason != "FailedScheduling" || ev.Count != 2 {
+		t.Errorf("newest event = %+v", ev)
+	}
+	if status.Events[1].Reason != "FailedCreate" {
+		t.Errorf("second event = %+v", status.Events[1])
+	}
+
+	want := "Waiting for pods to start (0/1 ready) - FailedScheduling: FailedScheduling message"
+	if status.Message != want {
+		t.Errorf("message = %q, want %q", status.Message, want)
+	}
+}
+
+func TestStatus_NoEventsWhenRunning(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+
+	fakeClient := fake.NewSimpleClientset(deployment,
+		testEvent("e1", "Deployment", "test-app", corev1.EventTypeWarning, "ProgressDeadlineExceeded", time.Hour),
+	)
+	renderer, _ := NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	deployer := NewKubernetesDeployer(fakeClient, renderer, &util.MockLogger{})
+
+	status, err := deployer.Status(context.Background(), "test-app", "default")
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if len(status.Events) != 0 || strings.Contains(status.Message, "ProgressDeadlineExceeded") {
+		t.Errorf("running deployment reports old warnings: %+v", status)
+	}
+}
+
+func TestBuildPodStatuses_OOMKilled(t *testing.T) {
+	pods := &corev1.PodList{Items: []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app-abc123"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: 2,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+				},
+			}},
+		},
+	}}}
+
+	statuses := buildPodStatuses(pods)
+	if got := statuses[0].Message; !strings.Contains(got, "OOMKilled") {
+		t.Errorf("message = %q, want OOMKilled", got)
+	}
+}
@@ -0,0 +1,75 @@
+package diag
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dump lists the files written by Capture.
+type Dump struct {
+	// Goroutines holds the stacks of every goroutine, as text
+	Goroutines string
+
+	// Heap holds a heap profile (open with 'go tool pprof')
+	Heap string
+}
+
+// profiles are fetched by Capture: file suffix and pprof endpoint.
+var profiles = []struct {
+	suffix string
+	path   string
+}{
+	{"goroutines.txt", "/debug/pprof/goroutine?debug=2"},
+	{"heap.pprof", "/debug/pprof/heap"},
+}
+
+// Capture fetches goroutine stacks and a heap profile from the Server at
+// addr and writes them to dir as kudev-<timestamp>-{goroutines.txt,heap.pprof}.
+func Capture(ctx context.Context, addr, dir string, now time.Time) (*Dump, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	prefix := filepath.Join(dir, "kudev-"+now.Format("20060102-150405"))
+	var files []string
+	for _, p := range profiles {
+		path := prefix + "-" + p.suffix
+		if err := fetch(ctx, "http://"+addr+p.path, path); err != nil {
+			return nil, err
+		}
+		files = append(files, path)
+	}
+	return &Dump{Goroutines: files[0], Heap: files[1]}, nil
+}
+
+// fetch downloads url to path.
+func fetch(ctx context.Context, url, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach kudev pprof endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,111 @@
+// Package diag exposes runtime diagnostics of long-running kudev commands
+// (net/http/pprof) and captures goroutine and heap dumps from them.
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// Server serves net/http/pprof on a loopback address.
+//
+// Endpoints:
+//
+//	GET /debug/pprof/                   Index
+//	GET /debug/pprof/goroutine?debug=2  Goroutine stacks
+//	GET /debug/pprof/heap               Heap profile
+//
+// Example:
+//
+//	go tool pprof http://localhost:6060/debug/pprof/heap
+type Server struct {
+	addr     string
+	logger   logging.LoggerInterface
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer creates a pprof server for addr (e.g. "localhost:6060").
+// A missing host means 127.0.0.1; non-loopback hosts are rejected, since
+// profiles expose memory contents such as env values.
+func NewServer(addr string, logger logging.LoggerInterface) (*Server, error) {
+	addr, err := loopbackAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return &Server{
+		addr:   addr,
+		logger: logging.OrDefault(logger),
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}, nil
+}
+
+// Start binds the listener and serves in the background.
+// The server shuts down when ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	s.listener = ln
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error(err, "pprof server stopped")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		s.server.Shutdown(shutdownCtx)
+	}()
+
+	s.logger.Info("pprof endpoint listening", "addr", ln.Addr().String())
+	return nil
+}
+
+// Addr returns the bound address (useful when listening on port 0).
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.addr
+	}
+	return s.listener.Addr().String()
+}
+
+// loopbackAddr fills in a missing host and rejects non-loopback ones.
+func loopbackAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid pprof address %q (expected host:port, e.g. localhost:6060): %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return "", fmt.Errorf("pprof address %q must be on localhost (e.g. localhost:6060)", addr)
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
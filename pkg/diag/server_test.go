@@ -0,0 +1,86 @@
+package diag
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{"localhost", "localhost:6060", "localhost:6060", false},
+		{"ipv4 loopback", "127.0.0.1:6060", "127.0.0.1:6060", false},
+		{"ipv6 loopback", "[::1]:6060", "[::1]:6060", false},
+		{"no host", ":6060", "127.0.0.1:6060", false},
+		{"all interfaces", "0.0.0.0:6060", "", true},
+		{"remote host", "example.com:6060", "", true},
+		{"no port", "localhost", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loopbackAddr(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loopbackAddr(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("loopbackAddr(%q) = %q, want %q", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapture(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, err := NewServer("127.0.0.1:0", &util.MockLogger{})
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 10, 14, 5, 0, 0, time.UTC)
+	dump, err := Capture(ctx, server.Addr(), dir, now)
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+
+	if !strings.HasSuffix(dump.Goroutines, "kudev-20260310-140500-goroutines.txt") {
+		t.Errorf("Goroutines = %q", dump.Goroutines)
+	}
+	stacks, err := os.ReadFile(dump.Goroutines)
+	if err != nil {
+		t.Fatalf("failed to read goroutine dump: %v", err)
+	}
+	if !strings.Contains(string(stacks), "goroutine ") {
+		t.Errorf("goroutine dump has no stacks:\n%s", stacks)
+	}
+
+	info, err := os.Stat(dump.Heap)
+	if err != nil {
+		t.Fatalf("heap profile missing: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("heap profile is empty")
+	}
+}
+
+func TestCapture_Unreachable(t *testing.T) {
+	_, err := Capture(context.Background(), "127.0.0.1:1", t.TempDir(), time.Now())
+	if err == nil {
+		t.Fatal("expected error for unreachable endpoint")
+	}
+}
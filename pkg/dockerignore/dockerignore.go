@@ -0,0 +1,130 @@
+// Package dockerignore maintains the part of .dockerignore that kudev
+// generates from spec.exclude, so the docker build context leaves out the
+// same files as the source hash.
+package dockerignore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers delimiting the part of .dockerignore kudev owns. Lines outside
+// them are never touched.
+const (
+	blockBegin = "# >>> kudev: generated from spec.exclude in .kudev.yaml, do not edit >>>"
+	blockEnd   = "# <<< kudev <<<"
+)
+
+// Sync makes the kudev block of dir/.dockerignore list exclusions
+// (spec.exclude).
+//
+// The block is appended when missing, replaced when outdated and removed
+// when exclusions is empty; a file left empty is deleted. The file is
+// only written when its content changes, so watchers see no churn.
+// Returns whether the file changed.
+func Sync(dir string, exclusions []string) (bool, error) {
+	path := filepath.Join(dir, ".dockerignore")
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated := merge(string(existing), exclusions)
+	if updated == string(existing) {
+		return false, nil
+	}
+
+	if updated == "" {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return true, nil
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// merge returns content with its kudev block set to exclusions.
+func merge(content string, exclusions []string) string {
+	kept, found := withoutBlock(content)
+
+	// A file kudev never managed is left as it is
+	if !found && len(exclusions) == 0 {
+		return content
+	}
+
+	lines := trimTrailingBlank(kept)
+	if len(exclusions) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, blockBegin)
+		for _, exc := range exclusions {
+			lines = append(lines, pattern(exc))
+		}
+		lines = append(lines, blockEnd)
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// WithoutBlock returns .dockerignore content without the kudev block and
+// with trailing blank lines dropped, so content compares equal before and
+// after Sync. The source hash uses it: the block follows spec.exclude,
+// not the sources, and rewriting it must not change the image tag.
+func WithoutBlock(content []byte) []byte {
+	kept, _ := withoutBlock(string(content))
+	lines := trimTrailingBlank(kept)
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// withoutBlock splits content into lines, leaving out the kudev block,
+// and reports whether there was one.
+func withoutBlock(content string) ([]string, bool) {
+	var kept []string
+	inBlock, found := false, false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.TrimSpace(line) == blockBegin:
+			inBlock, found = true, true
+		case strings.TrimSpace(line) == blockEnd:
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+	return kept, found
+}
+
+// trimTrailingBlank drops the blank lines kudev left behind the user's
+// entries.
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// pattern converts a spec.exclude entry to .dockerignore
+// syntax. Docker anchors patterns at the context root, while the source
+// hash matches a bare name (".env", "*.log") at any depth, so those get
+// a "**/" prefix; entries with a path ("build/out") stay anchored.
+func pattern(exclusion string) string {
+	pattern := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(exclusion), "./"), "/")
+	if strings.Contains(pattern, "/") {
+		return pattern
+	}
+	return "**/" + pattern
+}
@@ -0,0 +1,112 @@
+package dockerignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	block := blockBegin + "\n**/.env\nbuild/out\n" + blockEnd + "\n"
+
+	tests := []struct {
+		name       string
+		content    string
+		exclusions []string
+		want       string
+	}{
+		{
+			name:       "new file",
+			exclusions: []string{".env", "./build/out/"},
+			want:       block,
+		},
+		{
+			name:       "appended after user entries",
+			content:    "# mine\nsecrets/\n\n",
+			exclusions: []string{".env", "build/out"},
+			want:       "# mine\nsecrets/\n\n" + block,
+		},
+		{
+			name:       "outdated block replaced in place",
+			content:    "secrets/\n\n" + blockBegin + "\n**/old\n" + blockEnd + "\n",
+			exclusions: []string{".env", "build/out"},
+			want:       "secrets/\n\n" + block,
+		},
+		{
+			name:    "block removed without exclusions",
+			content: "secrets/\n\n" + block,
+			want:    "secrets/\n",
+		},
+		{
+			name:    "only block removed",
+			content: block,
+			want:    "",
+		},
+		{
+			name:    "user file untouched without exclusions",
+			content: "secrets/",
+			want:    "secrets/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := merge(tt.content, tt.exclusions); got != tt.want {
+				t.Errorf("merge() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".dockerignore")
+
+	changed, err := Sync(dir, []string{"*.log"})
+	if err != nil || !changed {
+		t.Fatalf("first sync: changed=%v err=%v, want a write", changed, err)
+	}
+
+	// Idempotent: the same exclusions leave the file alone
+	changed, err = Sync(dir, []string{"*.log"})
+	if err != nil || changed {
+		t.Errorf("second sync: changed=%v err=%v, want no write", changed, err)
+	}
+
+	// Removing every exclusion deletes the file kudev created
+	if changed, err = Sync(dir, nil); err != nil || !changed {
+		t.Fatalf("clearing sync: changed=%v err=%v", changed, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf(".dockerignore still exists: %v", err)
+	}
+
+	// Nothing to do without a file or exclusions
+	if changed, err = Sync(dir, nil); err != nil || changed {
+		t.Errorf("empty sync: changed=%v err=%v", changed, err)
+	}
+}
+
+func TestWithoutBlock(t *testing.T) {
+	block := blockBegin + "\n**/.env\n" + blockEnd + "\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "only block", content: block, want: ""},
+		{name: "user entries before block", content: "secrets/\n\n" + block, want: "secrets/\n"},
+		{name: "no block", content: "secrets/\n\n", want: "secrets/\n"},
+		{name: "no trailing newline", content: "secrets/", want: "secrets/\n"},
+		{name: "empty", content: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(WithoutBlock([]byte(tt.content))); got != tt.want {
+				t.Errorf("WithoutBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
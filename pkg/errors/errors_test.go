@@ -45,6 +45,8 @@ func TestKudevErrorInterface(t *testing.T) {
 		{"KubeAuthError", KubeconfigNotFound(), ExitKubeAuth},
 		{"BuildError", DockerNotRunning(nil), ExitBuild},
 		{"DeployError", DeploymentNotFound("x", "y"), ExitDeploy},
+		{"AdmissionDenied", AdmissionDenied("Deployment x", "webhook", []string{"[p] no"}, nil), ExitDeploy},
+		{"ManifestInvalid", ManifestInvalid("Deployment x", []string{"spec: bad"}, nil), ExitDeploy},
 		{"WatchError", WatcherFailed(nil), ExitWatch},
 	}
 
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Config errors
 
@@ -115,6 +118,34 @@ func NamespaceCreateFailed(namespace string, cause error) *DeployError {
 	}
 }
 
+// AdmissionDenied reports an object refused by admission control; by
+// names the webhook or policy, details list the violations.
+func AdmissionDenied(object, by string, details []string, cause error) *DeployError {
+	return &DeployError{
+		Message:    object + " was denied by " + by + detailList(details),
+		Suggestion: "Change .kudev.yaml so the manifests satisfy the policy ('kudev render' shows them), or ask the cluster admins for an exception. Use --validate-server to check before deploying",
+		Cause:      cause,
+	}
+}
+
+// ManifestInvalid reports an object the API server failed to validate.
+func ManifestInvalid(object string, details []string, cause error) *DeployError {
+	return &DeployError{
+		Message:    object + " failed API validation" + detailList(details),
+		Suggestion: "Fix the fields above in .kudev.yaml; 'kudev render' shows the generated manifests",
+		Cause:      cause,
+	}
+}
+
+// detailList formats details as an indented list below a message.
+func detailList(details []string) string {
+	var b strings.Builder
+	for _, d := range details {
+		b.WriteString("\n  - " + d)
+	}
+	return b.String()
+}
+
 func PortForwardFailed(port int32, cause error) *DeployError {
 	return &DeployError{
 		Message:    fmt.Sprintf("Port forwarding failed on port %d", port),
@@ -0,0 +1,262 @@
+// pkg/export/export.go
+
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/version"
+	"github.com/nanaki-93/kudev/templates"
+)
+
+// Format identifies the export output flavour.
+type Format string
+
+const (
+	// FormatPlain writes rendered Kubernetes manifests.
+	FormatPlain Format = "plain"
+
+	// FormatKustomize writes a kustomize base with image/replica overrides.
+	FormatKustomize Format = "kustomize"
+
+	// FormatHelm writes a Helm chart with a values.yaml.
+	FormatHelm Format = "helm"
+)
+
+// ParseFormat validates a user supplied format name.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatPlain:
+		return FormatPlain, nil
+	case FormatKustomize:
+		return FormatKustomize, nil
+	case FormatHelm:
+		return FormatHelm, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q (supported: plain, kustomize, helm)", s)
+	}
+}
+
+// Exporter turns a kudev configuration into files for real deployment pipelines.
+type Exporter struct {
+	renderer *deployer.Renderer
+}
+
+// NewExporter creates a new exporter.
+func NewExporter(renderer *deployer.Renderer) *Exporter {
+	return &Exporter{renderer: renderer}
+}
+
+// Export writes files for the given format into outputDir.
+// Returns the list of written file paths.
+func (e *Exporter) Export(format Format, data deployer.TemplateData, outputDir string) ([]string, error) {
+	files, err := e.Files(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write in stable order
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var written []string
+	for _, name := range names {
+		path := filepath.Join(outputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+// Files returns the generated files (relative path → content) without writing them.
+func (e *Exporter) Files(format Format, data deployer.TemplateData) (map[string]string, error) {
+	switch format {
+	case FormatPlain:
+		return e.plainFiles(data)
+	case FormatKustomize:
+		return e.kustomizeFiles(data)
+	case FormatHelm:
+		return helmFiles(data)
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+func (e *Exporter) plainFiles(data deployer.TemplateData) (map[string]string, error) {
+	depYAML, err := e.renderer.RenderDeploymentYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	svcYAML, err := e.renderer.RenderServiceYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"deployment.yaml": depYAML,
+		"service.yaml":    svcYAML,
+	}, nil
+}
+
+// kustomization mirrors the subset of kustomize.config.k8s.io/v1beta1 we generate.
+type kustomization struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Namespace  string             `json:"namespace,omitempty"`
+	Resources  []string           `json:"resources"`
+	Images     []kustomizeImage   `json:"images,omitempty"`
+	Replicas   []kustomizeReplica `json:"replicas,omitempty"`
+}
+
+type kustomizeImage struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"`
+	NewTag  string `json:"newTag,omitempty"`
+}
+
+type kustomizeReplica struct {
+	Name  string `json:"name"`
+	Count int32  `json:"count"`
+}
+
+func (e *Exporter) kustomizeFiles(data deployer.TemplateData) (map[string]string, error) {
+	files, err := e.plainFiles(data)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, tag := splitImageRef(data.ImageRef)
+	k := kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Namespace:  data.Namespace,
+		Resources:  []string{"deployment.yaml", "service.yaml"},
+		Images: []kustomizeImage{
+			{Name: repo, NewName: repo, NewTag: tag},
+		},
+		Replicas: []kustomizeReplica{
+			{Name: data.AppName, Count: data.Replicas},
+		},
+	}
+
+	out, err := yaml.Marshal(k)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal kustomization: %w", err)
+	}
+
+	result := map[string]string{
+		"base/kustomization.yaml": string(out),
+	}
+	for name, content := range files {
+		result["base/"+name] = content
+	}
+	return result, nil
+}
+
+// helmValues is the values.yaml generated for the chart.
+type helmValues struct {
+	Replicas int32 `json:"replicas"`
+	Image    struct {
+		Repository string `json:"repository"`
+		Tag        string `json:"tag"`
+		PullPolicy string `json:"pullPolicy"`
+	} `json:"image"`
+	Service struct {
+		Type string `json:"type"`
+		Port int32  `json:"port"`
+	} `json:"service"`
+	Env       []helmEnv      `json:"env,omitempty"`
+	Resources *helmResources `json:"resources,omitempty"`
+}
+
+type helmResources struct {
+	Requests config.ResourceValues `json:"requests,omitempty"`
+	Limits   config.ResourceValues `json:"limits,omitempty"`
+}
+
+// helmEnv is a container env var; ConfigMap and Secret references are
+// kept as valueFrom so the chart reads the same keys as kudev.
+type helmEnv struct {
+	Name      string               `json:"name"`
+	Value     string               `json:"value,omitempty"`
+	ValueFrom *config.EnvVarSource `json:"valueFrom,omitempty"`
+}
+
+func helmFiles(data deployer.TemplateData) (map[string]string, error) {
+	chart := map[string]any{
+		"apiVersion":  "v2",
+		"name":        data.AppName,
+		"description": fmt.Sprintf("Helm chart for %s (generated by kudev %s)", data.AppName, version.Version),
+		"type":        "application",
+		"version":     "0.1.0",
+		"appVersion":  data.ImageHash,
+	}
+	chartYAML, err := yaml.Marshal(chart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Chart.yaml: %w", err)
+	}
+
+	var values helmValues
+	values.Replicas = data.Replicas
+	values.Image.Repository, values.Image.Tag = splitImageRef(data.ImageRef)
+	values.Image.PullPolicy = "IfNotPresent"
+	values.Service.Type = "ClusterIP"
+	values.Service.Port = data.ServicePort
+	for _, env := range data.Env {
+		values.Env = append(values.Env, newHelmEnv(env))
+	}
+	if data.Resources != (deployer.Resources{}) {
+		values.Resources = &helmResources{Requests: data.Resources.Requests, Limits: data.Resources.Limits}
+	}
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal values.yaml: %w", err)
+	}
+
+	dir := data.AppName
+	return map[string]string{
+		filepath.Join(dir, "Chart.yaml"):                   string(chartYAML),
+		filepath.Join(dir, "values.yaml"):                  string(valuesYAML),
+		filepath.Join(dir, "templates", "deployment.yaml"): templates.HelmDeploymentTemplate,
+		filepath.Join(dir, "templates", "service.yaml"):    templates.HelmServiceTemplate,
+	}, nil
+}
+
+// newHelmEnv converts a rendered env var for values.yaml.
+func newHelmEnv(env deployer.EnvVar) helmEnv {
+	if env.ConfigMapKeyRef == nil && env.SecretKeyRef == nil {
+		return helmEnv{Name: env.Name, Value: env.Value}
+	}
+	return helmEnv{Name: env.Name, ValueFrom: &config.EnvVarSource{
+		ConfigMapKeyRef: env.ConfigMapKeyRef,
+		SecretKeyRef:    env.SecretKeyRef,
+	}}
+}
+
+// splitImageRef splits "repo:tag" into its parts.
+// Registry ports ("localhost:5000/app") are not mistaken for tags.
+func splitImageRef(ref string) (repo, tag string) {
+	idx := strings.LastIndex(ref, ":")
+	if idx == -1 || strings.Contains(ref[idx:], "/") {
+		return ref, "latest"
+	}
+	return ref[:idx], ref[idx+1:]
+}
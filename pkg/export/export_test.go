@@ -0,0 +1,119 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/templates"
+)
+
+func testData() deployer.TemplateData {
+	return deployer.TemplateData{
+		AppName:     "myapp",
+		Namespace:   "dev",
+		ImageRef:    "myapp:kudev-abc12345",
+		ImageHash:   "abc12345",
+		ServicePort: 8080,
+		Replicas:    2,
+		Env: []deployer.EnvVar{
+			{Name: "LOG_LEVEL", Value: "info"},
+			{Name: "DB_PASSWORD", SecretKeyRef: &config.SecretKeySelector{Name: "db-credentials", Key: "password"}},
+		},
+		Resources: deployer.Resources{
+			Requests: config.ResourceValues{CPU: "100m", Memory: "128Mi"},
+			Limits:   config.ResourceValues{CPU: "500m", Memory: "512Mi"},
+		},
+	}
+}
+
+func newTestExporter(t *testing.T) *Exporter {
+	renderer, err := deployer.NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	if err != nil {
+		t.Fatalf("NewRenderer failed: %v", err)
+	}
+	return NewExporter(renderer)
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, valid := range []string{"plain", "kustomize", "helm", "HELM"} {
+		if _, err := ParseFormat(valid); err != nil {
+			t.Errorf("ParseFormat(%q) unexpected error: %v", valid, err)
+		}
+	}
+
+	if _, err := ParseFormat("jsonnet"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestExport_Plain(t *testing.T) {
+	files, err := newTestExporter(t).Files(FormatPlain, testData())
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+
+	if !strings.Contains(files["deployment.yaml"], "image: myapp:kudev-abc12345") {
+		t.Error("deployment.yaml should contain the image ref")
+	}
+	if _, ok := files["service.yaml"]; !ok {
+		t.Error("service.yaml missing")
+	}
+}
+
+func TestExport_Kustomize(t *testing.T) {
+	files, err := newTestExporter(t).Files(FormatKustomize, testData())
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+
+	k := files["base/kustomization.yaml"]
+	for _, want := range []string{"newTag: kudev-abc12345", "count: 2", "- deployment.yaml"} {
+		if !strings.Contains(k, want) {
+			t.Errorf("kustomization.yaml missing %q:\n%s", want, k)
+		}
+	}
+}
+
+func TestExport_HelmWritesChart(t *testing.T) {
+	outDir := t.TempDir()
+
+	written, err := newTestExporter(t).Export(FormatHelm, testData(), outDir)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(written) != 4 {
+		t.Errorf("wrote %d files, want 4", len(written))
+	}
+
+	values, err := os.ReadFile(filepath.Join(outDir, "myapp", "values.yaml"))
+	if err != nil {
+		t.Fatalf("values.yaml not written: %v", err)
+	}
+	for _, want := range []string{"repository: myapp", "tag: kudev-abc12345", "replicas: 2", "LOG_LEVEL", "memory: 512Mi", "secretKeyRef:", "name: db-credentials", "key: password"} {
+		if !strings.Contains(string(values), want) {
+			t.Errorf("values.yaml missing %q:\n%s", want, values)
+		}
+	}
+}
+
+func TestSplitImageRef(t *testing.T) {
+	tests := []struct {
+		ref, repo, tag string
+	}{
+		{"myapp:v1", "myapp", "v1"},
+		{"myapp", "myapp", "latest"},
+		{"localhost:5000/myapp", "localhost:5000/myapp", "latest"},
+		{"localhost:5000/myapp:v2", "localhost:5000/myapp", "v2"},
+	}
+
+	for _, tt := range tests {
+		repo, tag := splitImageRef(tt.ref)
+		if repo != tt.repo || tag != tt.tag {
+			t.Errorf("splitImageRef(%q) = (%q, %q), want (%q, %q)", tt.ref, repo, tag, tt.repo, tt.tag)
+		}
+	}
+}
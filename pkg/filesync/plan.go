@@ -0,0 +1,84 @@
+// Package filesync copies changed source files into running pods, so
+// 'kudev watch' can skip the image rebuild for files covered by spec.sync.
+package filesync
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+// Change is a single file to copy into (or remove from) the pods.
+type Change struct {
+	// LocalPath is the absolute path on this machine.
+	LocalPath string
+
+	// RemotePath is the absolute path in the container.
+	RemotePath string
+
+	// Deleted is true when the local file is gone and should be removed.
+	Deleted bool
+}
+
+// Plan maps changed paths (relative to baseDir, slash or OS separated) to
+// container paths using rules.
+//
+// ok is false when any path is not covered by a rule; the caller must then
+// rebuild instead of syncing. Whether a change is a copy or a delete is
+// decided by the current state of the local file, so renames and editors
+// that replace files atomically are handled alike.
+func Plan(rules []config.SyncRule, baseDir string, paths []string) (changes []Change, ok bool) {
+	if len(rules) == 0 || len(paths) == 0 {
+		return nil, false
+	}
+
+	seen := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		rel := filepath.ToSlash(filepath.Clean(p))
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		remote, matched := remotePath(rules, rel)
+		if !matched {
+			return nil, false
+		}
+
+		local := filepath.Join(baseDir, filepath.FromSlash(rel))
+		info, err := os.Stat(local)
+		switch {
+		case os.IsNotExist(err):
+			changes = append(changes, Change{LocalPath: local, RemotePath: remote, Deleted: true})
+		case err != nil:
+			return nil, false
+		case info.IsDir():
+			// Files created inside arrive as their own events
+			continue
+		default:
+			changes = append(changes, Change{LocalPath: local, RemotePath: remote})
+		}
+	}
+
+	return changes, true
+}
+
+// remotePath returns the container path for rel under the first matching rule.
+func remotePath(rules []config.SyncRule, rel string) (string, bool) {
+	for _, rule := range rules {
+		local := filepath.ToSlash(filepath.Clean(rule.Local))
+		if local == "." {
+			return path.Join(rule.Remote, rel), true
+		}
+		if rel == local {
+			return rule.Remote, true
+		}
+		if strings.HasPrefix(rel, local+"/") {
+			return path.Join(rule.Remote, strings.TrimPrefix(rel, local+"/")), true
+		}
+	}
+	return "", false
+}
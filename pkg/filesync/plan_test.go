@@ -0,0 +1,106 @@
+package filesync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+func TestPlan(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src", "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"src/app.js", "src/lib/util.js", "static/index.html"} {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules := []config.SyncRule{
+		{Local: "src", Remote: "/app/src"},
+		{Local: "static/index.html", Remote: "/usr/share/nginx/html/index.html"},
+	}
+
+	tests := []struct {
+		name   string
+		rules  []config.SyncRule
+		paths  []string
+		want   []Change
+		wantOK bool
+	}{
+		{
+			name:  "covered files",
+			rules: rules,
+			paths: []string{"src/app.js", "src/lib/util.js", "src/app.js", "static/index.html"},
+			want: []Change{
+				{LocalPath: filepath.Join(dir, "src", "app.js"), RemotePath: "/app/src/app.js"},
+				{LocalPath: filepath.Join(dir, "src", "lib", "util.js"), RemotePath: "/app/src/lib/util.js"},
+				{LocalPath: filepath.Join(dir, "static", "index.html"), RemotePath: "/usr/share/nginx/html/index.html"},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "deleted file",
+			rules:  rules,
+			paths:  []string{"src/old.js"},
+			want:   []Change{{LocalPath: filepath.Join(dir, "src", "old.js"), RemotePath: "/app/src/old.js", Deleted: true}},
+			wantOK: true,
+		},
+		{
+			name:   "directory is skipped",
+			rules:  rules,
+			paths:  []string{"src/lib"},
+			wantOK: true,
+		},
+		{
+			name:  "whole context",
+			rules: []config.SyncRule{{Local: ".", Remote: "/app"}},
+			paths: []string{"src/app.js"},
+			want: []Change{
+				{LocalPath: filepath.Join(dir, "src", "app.js"), RemotePath: "/app/src/app.js"},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "uncovered file needs rebuild",
+			rules:  rules,
+			paths:  []string{"src/app.js", "package.json"},
+			wantOK: false,
+		},
+		{
+			name:   "prefix is not a parent directory",
+			rules:  rules,
+			paths:  []string{"srcfoo/app.js"},
+			wantOK: false,
+		},
+		{
+			name:   "no rules",
+			paths:  []string{"src/app.js"},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Plan(tt.rules, dir, tt.paths)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("change[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
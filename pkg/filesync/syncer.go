@@ -0,0 +1,171 @@
+package filesync
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// ExecFunc runs command in a container of pod, feeding it stdin.
+type ExecFunc func(ctx context.Context, pod *corev1.Pod, container string, command []string, stdin io.Reader) error
+
+// PodSyncer copies changes into the running pods of an app, the way
+// 'kubectl cp' does: a tar stream piped into 'tar -x' in the container.
+type PodSyncer struct {
+	clientset kubernetes.Interface
+	logger    logging.LoggerInterface
+	exec      ExecFunc
+}
+
+// NewPodSyncer creates a syncer that execs into pods through the API server.
+func NewPodSyncer(clientset kubernetes.Interface, restConfig *rest.Config, logger logging.LoggerInterface) *PodSyncer {
+	return &PodSyncer{
+		clientset: clientset,
+		logger:    logging.OrDefault(logger),
+		exec:      spdyExec(clientset, restConfig),
+	}
+}
+
+// Sync applies changes to the app container (see spec.containerName) of
+// every running pod kudev deployed for appName. Returns the number of
+// pods updated.
+func (s *PodSyncer) Sync(ctx context.Context, appName, namespace, container string, changes []Change) (int, error) {
+	pods, err := s.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s,managed-by=kudev", appName),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var targets []*corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			targets = append(targets, pod)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, fmt.Errorf("no running pods found for app %s in namespace %s", appName, namespace)
+	}
+
+	archive, removed, err := buildArchive(changes)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, pod := range targets {
+		s.logger.Debug("syncing files", "pod", pod.Name, "files", len(changes))
+
+		if archive.Len() > 0 {
+			cmd := []string{"tar", "-xmf", "-", "-C", "/"}
+			if err := s.exec(ctx, pod, container, cmd, bytes.NewReader(archive.Bytes())); err != nil {
+				return 0, fmt.Errorf("failed to copy files into pod %s: %w", pod.Name, err)
+			}
+		}
+		if len(removed) > 0 {
+			cmd := append([]string{"rm", "-f", "--"}, removed...)
+			if err := s.exec(ctx, pod, container, cmd, nil); err != nil {
+				return 0, fmt.Errorf("failed to remove files from pod %s: %w", pod.Name, err)
+			}
+		}
+	}
+
+	return len(targets), nil
+}
+
+// buildArchive packs the copied files into a tar stream rooted at "/" and
+// returns the remote paths to remove.
+func buildArchive(changes []Change) (*bytes.Buffer, []string, error) {
+	var buf bytes.Buffer
+	var removed []string
+
+	tw := tar.NewWriter(&buf)
+	written := 0
+	for _, c := range changes {
+		if c.Deleted {
+			removed = append(removed, c.RemotePath)
+			continue
+		}
+
+		data, err := os.ReadFile(c.LocalPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", c.LocalPath, err)
+		}
+		info, err := os.Stat(c.LocalPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to stat %s: %w", c.LocalPath, err)
+		}
+
+		hdr := &tar.Header{
+			Name:    strings.TrimPrefix(c.RemotePath, "/"),
+			Mode:    int64(info.Mode().Perm()),
+			Size:    int64(len(data)),
+			ModTime: info.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+		written++
+	}
+	if err := tw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if written == 0 {
+		buf.Reset()
+	}
+	return &buf, removed, nil
+}
+
+// spdyExec returns an ExecFunc using the pods/exec subresource.
+func spdyExec(clientset kubernetes.Interface, restConfig *rest.Config) ExecFunc {
+	return func(ctx context.Context, pod *corev1.Pod, container string, command []string, stdin io.Reader) error {
+		opts := &corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stderr:    true,
+		}
+
+		req := clientset.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Name(pod.Name).
+			Namespace(pod.Namespace).
+			SubResource("exec").
+			VersionedParams(opts, scheme.ParameterCodec)
+
+		executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", req.URL())
+		if err != nil {
+			return fmt.Errorf("failed to create executor: %w", err)
+		}
+
+		var stderr bytes.Buffer
+		err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdin:  stdin,
+			Stderr: &stderr,
+		})
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return fmt.Errorf("%w: %s", err, msg)
+			}
+			return err
+		}
+		return nil
+	}
+}
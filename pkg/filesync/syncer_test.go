@@ -0,0 +1,123 @@
+package filesync
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+type execCall struct {
+	pod       string
+	container string
+	command   []string
+	files     map[string]string
+}
+
+func TestPodSyncer_Sync(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "app.js")
+	if err := os.WriteFile(local, []byte("console.log(1)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	running := corev1.PodStatus{Phase: corev1.PodRunning}
+	labels := map[string]string{"app": "myapp", "managed-by": "kudev"}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "myapp-1", Namespace: "default", Labels: labels}, Status: running},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "myapp-2", Namespace: "default", Labels: labels}, Status: running},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default", Labels: labels},
+			Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foreign", Namespace: "default",
+			Labels: map[string]string{"app": "myapp"}}, Status: running},
+	)
+
+	var calls []execCall
+	s := &PodSyncer{
+		clientset: clientset,
+		logger:    &util.MockLogger{},
+		exec: func(ctx context.Context, pod *corev1.Pod, container string, command []string, stdin io.Reader) error {
+			call := execCall{pod: pod.Name, container: container, command: command}
+			if stdin != nil {
+				call.files = readArchive(t, stdin)
+			}
+			calls = append(calls, call)
+			return nil
+		},
+	}
+
+	n, err := s.Sync(context.Background(), "myapp", "default", "app", []Change{
+		{LocalPath: local, RemotePath: "/app/src/app.js"},
+		{LocalPath: filepath.Join(dir, "old.js"), RemotePath: "/app/src/old.js", Deleted: true},
+	})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("synced %d pods, want 2", n)
+	}
+
+	if len(calls) != 4 {
+		t.Fatalf("got %d exec calls, want 4: %+v", len(calls), calls)
+	}
+	for i, pod := range []string{"myapp-1", "myapp-2"} {
+		copyCall, rmCall := calls[2*i], calls[2*i+1]
+		if copyCall.pod != pod || rmCall.pod != pod {
+			t.Errorf("calls for %s went to %s and %s", pod, copyCall.pod, rmCall.pod)
+		}
+		if copyCall.container != "app" || rmCall.container != "app" {
+			t.Errorf("calls for %s went to containers %q and %q, want app", pod, copyCall.container, rmCall.container)
+		}
+		if got := copyCall.files["app/src/app.js"]; got != "console.log(1)" {
+			t.Errorf("archive for %s = %v", pod, copyCall.files)
+		}
+		if want := []string{"rm", "-f", "--", "/app/src/old.js"}; !reflect.DeepEqual(rmCall.command, want) {
+			t.Errorf("remove command = %v, want %v", rmCall.command, want)
+		}
+	}
+}
+
+func TestPodSyncer_NoRunningPods(t *testing.T) {
+	s := &PodSyncer{
+		clientset: fake.NewSimpleClientset(),
+		logger:    &util.MockLogger{},
+		exec: func(ctx context.Context, pod *corev1.Pod, container string, command []string, stdin io.Reader) error {
+			t.Error("exec should not be called")
+			return nil
+		},
+	}
+
+	if _, err := s.Sync(context.Background(), "myapp", "default", "app", []Change{{RemotePath: "/app/x", Deleted: true}}); err == nil {
+		t.Error("expected error without running pods")
+	}
+}
+
+func readArchive(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("invalid archive: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(data)
+	}
+}
@@ -0,0 +1,183 @@
+// Package framework recognises common web frameworks from project files,
+// so 'kudev init' can default to the port the app actually listens on.
+package framework
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Framework is a web framework recognised in a project.
+type Framework struct {
+	// Name is the display name, e.g. "Spring Boot".
+	Name string
+
+	// Port is the framework's default port, or the one the project
+	// configures (Spring Boot's server.port).
+	Port int32
+
+	// Source is the file the framework was recognised from, relative to
+	// the project directory.
+	Source string
+}
+
+// rule recognises one framework from one of files.
+type rule struct {
+	name  string
+	port  int32
+	files []string
+
+	// match reports whether a file's content shows the framework
+	match func(content string) bool
+
+	// configuredPort returns the port the project sets, or 0 (optional)
+	configuredPort func(dir string) int32
+}
+
+var (
+	railsGem    = regexp.MustCompile(`(?m)^\s*gem\s+["']rails["']`)
+	fastAPIDep  = regexp.MustCompile(`(?i)\bfastapi\b`)
+	flaskDep    = regexp.MustCompile(`(?i)\bflask\b`)
+	pythonFiles = []string{"requirements.txt", "pyproject.toml", "Pipfile"}
+)
+
+// rules are tried in order; the first match wins. Frameworks that build
+// on another come first (Next.js apps may depend on express too).
+var rules = []rule{
+	{
+		name:  "Spring Boot",
+		port:  8080,
+		files: []string{"pom.xml", "build.gradle", "build.gradle.kts"},
+		match: func(content string) bool {
+			return strings.Contains(content, "spring-boot") || strings.Contains(content, "org.springframework.boot")
+		},
+		configuredPort: springServerPort,
+	},
+	{
+		name:  "Rails",
+		port:  3000,
+		files: []string{"Gemfile"},
+		match: func(content string) bool { return railsGem.MatchString(content) },
+	},
+	{
+		name:  "Django",
+		port:  8000,
+		files: []string{"manage.py"},
+		match: func(content string) bool { return strings.Contains(content, "django") },
+	},
+	{
+		name:  "FastAPI",
+		port:  8000,
+		files: pythonFiles,
+		match: func(content string) bool { return fastAPIDep.MatchString(content) },
+	},
+	{
+		name:  "Flask",
+		port:  5000,
+		files: pythonFiles,
+		match: func(content string) bool { return flaskDep.MatchString(content) },
+	},
+	{
+		name:  "Next.js",
+		port:  3000,
+		files: []string{"package.json"},
+		match: func(content string) bool { return hasNodeDependency(content, "next") },
+	},
+	{
+		name:  "Express",
+		port:  3000,
+		files: []string{"package.json"},
+		match: func(content string) bool { return hasNodeDependency(content, "express") },
+	},
+}
+
+// Detect returns the framework of the project in dir, or nil when none
+// is recognised.
+func Detect(dir string) *Framework {
+	for _, r := range rules {
+		for _, file := range r.files {
+			content, err := os.ReadFile(filepath.Join(dir, file))
+			if err != nil || !r.match(string(content)) {
+				continue
+			}
+			fw := &Framework{Name: r.name, Port: r.port, Source: file}
+			if r.configuredPort != nil {
+				if port := r.configuredPort(dir); port != 0 {
+					fw.Port = port
+				}
+			}
+			return fw
+		}
+	}
+	return nil
+}
+
+// hasNodeDependency reports whether package.json content lists name in
+// dependencies or devDependencies.
+func hasNodeDependency(content, name string) bool {
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal([]byte(content), &pkg) != nil {
+		return false
+	}
+	_, dep := pkg.Dependencies[name]
+	_, devDep := pkg.DevDependencies[name]
+	return dep || devDep
+}
+
+// springServerPort returns server.port from the Spring Boot application
+// properties or YAML, or 0 when it is not set to a number.
+func springServerPort(dir string) int32 {
+	resources := filepath.Join(dir, "src", "main", "resources")
+
+	if file, err := os.Open(filepath.Join(resources, "application.properties")); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), "=")
+			if ok && strings.TrimSpace(key) == "server.port" {
+				return parsePort(value)
+			}
+		}
+	}
+
+	for _, name := range []string{"application.yml", "application.yaml"} {
+		content, err := os.ReadFile(filepath.Join(resources, name))
+		if err != nil {
+			continue
+		}
+		// Only the common "server:\n  port: 8081" layout is recognised
+		inServer := false
+		for _, line := range strings.Split(string(content), "\n") {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				inServer = trimmed == "server:"
+				continue
+			}
+			if value, ok := strings.CutPrefix(trimmed, "port:"); ok && inServer {
+				return parsePort(value)
+			}
+		}
+	}
+	return 0
+}
+
+// parsePort parses a TCP port, returning 0 for anything else (e.g. a
+// ${PORT:8080} placeholder).
+func parsePort(value string) int32 {
+	port, err := strconv.ParseInt(strings.Trim(strings.TrimSpace(value), `"'`), 10, 32)
+	if err != nil || port < 1 || port > 65535 {
+		return 0
+	}
+	return int32(port)
+}
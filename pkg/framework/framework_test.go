@@ -0,0 +1,137 @@
+package framework
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantName   string
+		wantPort   int32
+		wantSource string
+	}{
+		{
+			name:       "spring boot maven",
+			files:      map[string]string{"pom.xml": "<artifactId>spring-boot-starter-web</artifactId>"},
+			wantName:   "Spring Boot",
+			wantPort:   8080,
+			wantSource: "pom.xml",
+		},
+		{
+			name: "spring boot with server.port",
+			files: map[string]string{
+				"build.gradle.kts":                          `id("org.springframework.boot") version "3.3.0"`,
+				"src/main/resources/application.properties": "spring.application.name=demo\nserver.port = 8081\n",
+			},
+			wantName:   "Spring Boot",
+			wantPort:   8081,
+			wantSource: "build.gradle.kts",
+		},
+		{
+			name: "spring boot with yaml port",
+			files: map[string]string{
+				"pom.xml":                            "spring-boot",
+				"src/main/resources/application.yml": "spring:\n  application:\n    name: demo\nserver:\n  port: 9090\n",
+			},
+			wantName:   "Spring Boot",
+			wantPort:   9090,
+			wantSource: "pom.xml",
+		},
+		{
+			name: "spring boot with placeholder port",
+			files: map[string]string{
+				"pom.xml": "spring-boot",
+				"src/main/resources/application.properties": "server.port=${PORT:8080}\n",
+			},
+			wantName:   "Spring Boot",
+			wantPort:   8080,
+			wantSource: "pom.xml",
+		},
+		{
+			name:       "express",
+			files:      map[string]string{"package.json": `{"dependencies": {"express": "^4.19.0"}}`},
+			wantName:   "Express",
+			wantPort:   3000,
+			wantSource: "package.json",
+		},
+		{
+			name:       "next.js with express",
+			files:      map[string]string{"package.json": `{"dependencies": {"next": "14.2.0", "express": "^4.19.0"}}`},
+			wantName:   "Next.js",
+			wantPort:   3000,
+			wantSource: "package.json",
+		},
+		{
+			name:       "flask",
+			files:      map[string]string{"requirements.txt": "Flask==3.0.3\ngunicorn\n"},
+			wantName:   "Flask",
+			wantPort:   5000,
+			wantSource: "requirements.txt",
+		},
+		{
+			name:       "fastapi in pyproject",
+			files:      map[string]string{"pyproject.toml": "[project]\ndependencies = [\"fastapi>=0.110\", \"uvicorn\"]\n"},
+			wantName:   "FastAPI",
+			wantPort:   8000,
+			wantSource: "pyproject.toml",
+		},
+		{
+			name:       "django",
+			files:      map[string]string{"manage.py": "os.environ.setdefault('DJANGO_SETTINGS_MODULE', 'site.settings')\nfrom django.core.management import execute_from_command_line\n"},
+			wantName:   "Django",
+			wantPort:   8000,
+			wantSource: "manage.py",
+		},
+		{
+			name:       "rails",
+			files:      map[string]string{"Gemfile": "source \"https://rubygems.org\"\ngem \"rails\", \"~> 7.1\"\n"},
+			wantName:   "Rails",
+			wantPort:   3000,
+			wantSource: "Gemfile",
+		},
+		{
+			name:  "node without a known framework",
+			files: map[string]string{"package.json": `{"dependencies": {"lodash": "^4.17.21"}}`},
+		},
+		{
+			name:  "gemfile without rails",
+			files: map[string]string{"Gemfile": "gem \"sinatra\"\n"},
+		},
+		{
+			name: "empty project",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fw := Detect(dir)
+			if tt.wantName == "" {
+				if fw != nil {
+					t.Errorf("Detect() = %+v, want nil", fw)
+				}
+				return
+			}
+			if fw == nil {
+				t.Fatalf("Detect() = nil, want %s", tt.wantName)
+			}
+			if fw.Name != tt.wantName || fw.Port != tt.wantPort || fw.Source != tt.wantSource {
+				t.Errorf("Detect() = %+v, want %s on %d from %s", fw, tt.wantName, tt.wantPort, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Package gitignore matches paths against .gitignore files, so hashing
+// and watching can skip what git ignores (build output, caches, ...).
+//
+// It implements the common subset of gitignore(5): comments, negation
+// (!), directory-only patterns (trailing /), anchored patterns (any /
+// other than a trailing one), "*", "?", "[...]" and "**". Nested
+// .gitignore files apply below their directory.
+package gitignore
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the files read in each directory.
+const FileName = ".gitignore"
+
+// Matcher holds the rules of the .gitignore files added so far.
+// The zero value ignores nothing; a nil *Matcher is valid too.
+type Matcher struct {
+	rules []rule
+}
+
+type rule struct {
+	// base is the directory of the .gitignore, relative to the root
+	// ("" for the root itself)
+	base string
+
+	pattern  *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Load reads every .gitignore below root, skipping ignored directories
+// and .git.
+func Load(root string) (*Matcher, error) {
+	m := &Matcher{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if d.Name() == ".git" || m.Match(rel, true) {
+			return filepath.SkipDir
+		}
+		return m.AddDir(root, rel)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AddDir reads the .gitignore of root/relDir, if there is one. Parents
+// must be added before their subdirectories, as a top-down walk does.
+func (m *Matcher) AddDir(root, relDir string) error {
+	file, err := os.Open(filepath.Join(root, relDir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	base := filepath.ToSlash(relDir)
+	if base == "." {
+		base = ""
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if r, ok := parseRule(base, scanner.Text()); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+	return scanner.Err()
+}
+
+// Match reports whether relPath (relative to the root) is ignored: it
+// matches a rule, or one of its parent directories does.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	if relPath == "." {
+		return false
+	}
+
+	// Git never looks inside an ignored directory
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchOne(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matchOne(relPath, isDir)
+}
+
+// matchOne applies the rules to relPath alone; the last match wins.
+func (m *Matcher) matchOne(relPath string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.matches(relPath, isDir) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+func (r rule) matches(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(relPath, r.base+"/") {
+			return false
+		}
+		relPath = strings.TrimPrefix(relPath, r.base+"/")
+	}
+	if !r.anchored {
+		relPath = path.Base(relPath)
+	}
+	return r.pattern.MatchString(relPath)
+}
+
+// parseRule parses one .gitignore line.
+func parseRule(base, line string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	r := rule{base: base}
+	switch {
+	case strings.HasPrefix(line, "!"):
+		r.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\`):
+		// \# and \! stand for a literal first character
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	pattern, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.pattern = pattern
+	return r, true
+}
+
+// globToRegexp converts a gitignore glob to a regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// Zero or more leading directories
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**") && i+2 == len(glob):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package gitignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), `# build output
+bin/
+*.log
+!keep.log
+/dist
+docs/**/*.pdf
+\#notes
+`)
+	writeFile(t, filepath.Join(root, "web", ".gitignore"), "node_modules\ncoverage/\n")
+
+	m, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "bin", isDir: true, want: true},
+		{path: "bin/app", want: true},
+		{path: "cmd/bin", isDir: true, want: true},
+		{path: "bin", want: false}, // a file named bin
+		{path: "debug.log", want: true},
+		{path: "logs/app.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "dist", isDir: true, want: true},
+		{path: "web/dist", isDir: true, want: false}, // anchored to the root
+		{path: "docs/a/b/guide.pdf", want: true},
+		{path: "docs/guide.pdf", want: true},
+		{path: "guide.pdf", want: false},
+		{path: "#notes", want: true},
+		{path: "web/node_modules/react/index.js", want: true},
+		{path: "web/coverage", isDir: true, want: true},
+		{path: "coverage", isDir: true, want: false}, // web/.gitignore only applies below web
+		{path: "main.go", want: false},
+		{path: ".", isDir: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_SkipsIgnoredDirectories(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), "vendor/\n")
+	// Rules inside an ignored directory never apply
+	writeFile(t, filepath.Join(root, "vendor", ".gitignore"), "!*\n")
+
+	m, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !m.Match("vendor/lib.go", false) {
+		t.Error("vendor/lib.go should be ignored")
+	}
+}
+
+func TestMatch_NilMatcher(t *testing.T) {
+	var m *Matcher
+	if m.Match("bin/app", false) {
+		t.Error("nil matcher should ignore nothing")
+	}
+}
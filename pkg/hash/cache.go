@@ -0,0 +1,168 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+)
+
+// CacheDirName is the cache directory inside a project's .kudev directory.
+const CacheDirName = "cache"
+
+// cacheVersion identifies the cache file format; other versions are
+// discarded.
+const cacheVersion = 1
+
+// racyWindow keeps recently modified files out of the cache: a file
+// written again within the mtime granularity could keep its size and
+// mtime while its content changes.
+const racyWindow = 2 * time.Second
+
+// CachePath returns the hash cache file of sourceDir in projectRoot.
+// Every build context gets its own file.
+func CachePath(projectRoot, sourceDir string) string {
+	if abs, err := filepath.Abs(sourceDir); err == nil {
+		sourceDir = abs
+	}
+	sum := sha256.Sum256([]byte(sourceDir))
+	name := "hash-" + hex.EncodeToString(sum[:])[:8] + ".json"
+	return filepath.Join(projectRoot, ".kudev", CacheDirName, name)
+}
+
+// cacheEntry is a file hash and the stat it was computed from.
+type cacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Hash    string `json:"hash"`
+}
+
+// matches reports whether a file with this size and mtime still has
+// the cached hash.
+func (e cacheEntry) matches(size int64, modTime time.Time) bool {
+	return e.Hash != "" && e.Size == size && e.ModTime == modTime.UnixNano()
+}
+
+type cacheFile struct {
+	Version   int                   `json:"version"`
+	Algorithm string                `json:"algorithm"`
+	Files     map[string]cacheEntry `json:"files"`
+}
+
+// fileCache keeps file hashes by relative path between runs, in memory
+// and in a file, so only files whose size or mtime changed are re-read.
+type fileCache struct {
+	path string
+
+	mu        sync.Mutex
+	loaded    bool
+	algorithm string
+	files     map[string]cacheEntry
+}
+
+func newFileCache(path string) *fileCache {
+	return &fileCache{path: path}
+}
+
+// entries returns the hashes cached for algorithm, reading the cache
+// file on first use. The map must not be modified. An unreadable cache,
+// or one of another algorithm, is empty.
+func (fc *fileCache) entries(algorithm string) map[string]cacheEntry {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if !fc.loaded {
+		fc.loaded = true
+		fc.algorithm, fc.files = readCacheFile(fc.path)
+	}
+	if fc.algorithm != cacheAlgorithm(algorithm) {
+		return nil
+	}
+	return fc.files
+}
+
+// replace stores the hashes of the latest run, which drops removed
+// files, and writes them to disk when they changed.
+func (fc *fileCache) replace(algorithm string, files map[string]cacheEntry) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	algorithm = cacheAlgorithm(algorithm)
+	if fc.algorithm == algorithm && sameEntries(fc.files, files) {
+		return nil
+	}
+	fc.algorithm, fc.files = algorithm, files
+	fc.loaded = true
+	return writeCacheFile(fc.path, algorithm, files)
+}
+
+// cacheAlgorithm names the default algorithm, so switching between an
+// unset and an explicit sha256 keeps the cache.
+func cacheAlgorithm(algorithm string) string {
+	if algorithm == "" {
+		return config.HashSHA256
+	}
+	return algorithm
+}
+
+func readCacheFile(path string) (string, map[string]cacheEntry) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil
+	}
+	var cf cacheFile
+	if err := json.Unmarshal(data, &cf); err != nil || cf.Version != cacheVersion {
+		return "", nil
+	}
+	return cf.Algorithm, cf.Files
+}
+
+// writeCacheFile writes atomically (temp file + rename), so concurrent
+// kudev processes never read a truncated cache.
+func writeCacheFile(path, algorithm string, files map[string]cacheEntry) error {
+	data, err := json.Marshal(cacheFile{Version: cacheVersion, Algorithm: algorithm, Files: files})
+	if err != nil {
+		return fmt.Errorf("failed to marshal hash cache: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace hash cache: %w", err)
+	}
+	return nil
+}
+
+func sameEntries(a, b map[string]cacheEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
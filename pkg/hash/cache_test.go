@@ -0,0 +1,160 @@
+// pkg/hash/cache_test.go
+
+package hash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeAged writes a file with an mtime old enough to be cached.
+func writeAged(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCalculate_CacheReusesUnchangedFiles(t *testing.T) {
+	root := t.TempDir()
+	mainFile := filepath.Join(root, "main.go")
+	old := time.Now().Add(-time.Hour)
+	writeAged(t, mainFile, "package main", old)
+
+	want, err := NewCalculator(root, nil).WithCache(root).Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if _, err := os.Stat(CachePath(root, root)); err != nil {
+		t.Fatalf("cache file not written: %v", err)
+	}
+
+	// Same size and mtime: the cached hash is trusted, so a fresh
+	// calculator reading the cache from disk does not see the edit
+	writeAged(t, mainFile, "package xxxx", old)
+
+	got, err := NewCalculator(root, nil).WithCache(root).Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("hash = %s, want cached %s", got, want)
+	}
+}
+
+func TestCalculate_CacheRehashesChangedFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		mtime   time.Duration
+	}{
+		{name: "size changed", content: "package main // edited", mtime: -time.Hour},
+		{name: "mtime changed", content: "package xxxx", mtime: -time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			mainFile := filepath.Join(root, "main.go")
+			writeAged(t, mainFile, "package main", time.Now().Add(-time.Hour))
+
+			calc := NewCalculator(root, nil).WithCache(root)
+			before, err := calc.Calculate(context.Background())
+			if err != nil {
+				t.Fatalf("Calculate failed: %v", err)
+			}
+
+			writeAged(t, mainFile, tt.content, time.Now().Add(tt.mtime))
+
+			after, err := calc.Calculate(context.Background())
+			if err != nil {
+				t.Fatalf("Calculate failed: %v", err)
+			}
+			want, _ := NewCalculator(root, nil).Calculate(context.Background())
+			if after == before || after != want {
+				t.Errorf("hash = %s, want uncached %s (before %s)", after, want, before)
+			}
+		})
+	}
+}
+
+func TestCalculate_CacheSkipsRecentlyModifiedFiles(t *testing.T) {
+	root := t.TempDir()
+	mainFile := filepath.Join(root, "main.go")
+	now := time.Now()
+	writeAged(t, mainFile, "package main", now)
+
+	calc := NewCalculator(root, nil).WithCache(root)
+	if _, err := calc.Calculate(context.Background()); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// Rewritten within the mtime granularity: must not hit the cache
+	writeAged(t, mainFile, "package xxxx", now)
+
+	got, err := calc.Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	want, _ := NewCalculator(root, nil).Calculate(context.Background())
+	if got != want {
+		t.Errorf("hash = %s, want uncached %s", got, want)
+	}
+}
+
+func TestCalculate_CacheDropsRemovedFiles(t *testing.T) {
+	root := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	writeAged(t, filepath.Join(root, "main.go"), "package main", old)
+	writeAged(t, filepath.Join(root, "util.go"), "package main", old)
+
+	calc := NewCalculator(root, nil).WithCache(root)
+	if _, err := calc.Calculate(context.Background()); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	os.Remove(filepath.Join(root, "util.go"))
+	if _, err := calc.Calculate(context.Background()); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	_, files := readCacheFile(CachePath(root, root))
+	if _, ok := files["util.go"]; ok || len(files) != 1 {
+		t.Errorf("cache files = %v, want only main.go", files)
+	}
+}
+
+func TestCalculate_CorruptCacheIsIgnored(t *testing.T) {
+	root := t.TempDir()
+	writeAged(t, filepath.Join(root, "main.go"), "package main", time.Now().Add(-time.Hour))
+
+	path := CachePath(root, root)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("{not json"), 0644)
+
+	got, err := NewCalculator(root, nil).WithCache(root).Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	want, _ := NewCalculator(root, nil).Calculate(context.Background())
+	if got != want {
+		t.Errorf("hash = %s, want %s", got, want)
+	}
+}
+
+func TestCachePath_PerSourceDir(t *testing.T) {
+	root := t.TempDir()
+	a := CachePath(root, filepath.Join(root, "a"))
+	b := CachePath(root, filepath.Join(root, "b"))
+	if a == b {
+		t.Errorf("CachePath should differ per source dir, both %s", a)
+	}
+	if filepath.Dir(a) != filepath.Join(root, ".kudev", CacheDirName) {
+		t.Errorf("CachePath = %s, want it in .kudev/cache", a)
+	}
+}
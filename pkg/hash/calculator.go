@@ -5,19 +5,74 @@ package hash
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+	stdhash "hash"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"sync"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/dockerignore"
+	"github.com/nanaki-93/kudev/pkg/gitignore"
 )
 
 // Calculator computes deterministic hashes of source code.
 type Calculator struct {
 	sourceDir  string
 	exclusions []string
+
+	// extraFiles are hashed on top of sourceDir (see WithExtraFiles).
+	extraFiles []string
+
+	// useGitignore also skips files ignored by .gitignore files.
+	useGitignore bool
+
+	// workers bounds how many files are hashed concurrently.
+	workers int
+
+	// cache keeps file hashes between runs; nil hashes every file.
+	cache *fileCache
+
+	opts Options
+}
+
+// Options configure the hash (spec.hash). The zero value is the
+// default: 8 hex characters of a SHA-256 over file paths and contents.
+type Options struct {
+	// Length is the number of hex characters returned.
+	Length int
+
+	// Algorithm is config.HashSHA256 or config.HashSHA512.
+	Algorithm string
+
+	// IncludeModes also hashes each file's permission bits.
+	IncludeModes bool
+}
+
+func (o Options) length() int {
+	if o.Length == 0 {
+		return config.DefaultHashLength
+	}
+	return o.Length
+}
+
+// newHasher returns the hash function of the algorithm.
+func (o Options) newHasher() (func() stdhash.Hash, error) {
+	switch o.Algorithm {
+	case "", config.HashSHA256:
+		return sha256.New, nil
+	case config.HashSHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unknown hash algorithm %q", o.Algorithm)
+	}
 }
 
 // NewCalculator creates a new hash calculator.
@@ -27,17 +82,175 @@ func NewCalculator(sourceDir string, exclusions []string) *Calculator {
 	return &Calculator{
 		sourceDir:  sourceDir,
 		exclusions: exclusions,
+		workers:    runtime.GOMAXPROCS(0),
+	}
+}
+
+// ForConfig creates the calculator for a project: its build context plus
+// the Dockerfile and config file when they sit outside it, spec.exclude,
+// spec.useGitignore and spec.hash, with the hash cache in .kudev/cache.
+func ForConfig(cfg *config.DeploymentConfig) *Calculator {
+	return NewCalculator(cfg.BuildContextDir(), cfg.Spec.BuildContextExclusions).
+		WithExtraFiles(cfg.BuildInputsOutsideContext()...).
+		WithGitignore(cfg.Spec.UseGitignore).
+		WithOptions(Options{
+			Length:       cfg.Spec.Hash.EffectiveLength(),
+			Algorithm:    cfg.Spec.Hash.EffectiveAlgorithm(),
+			IncludeModes: cfg.Spec.Hash.HashModes(),
+		}).
+		WithCache(cfg.ProjectRoot)
+}
+
+// WithOptions sets the hash length, algorithm and whether file modes
+// are hashed.
+func (c *Calculator) WithOptions(opts Options) *Calculator {
+	c.opts = opts
+	return c
+}
+
+// WithExtraFiles also hashes files outside the source directory, such
+// as a Dockerfile at the root of a monorepo. They are never excluded.
+func (c *Calculator) WithExtraFiles(paths ...string) *Calculator {
+	c.extraFiles = paths
+	return c
+}
+
+// WithGitignore makes the calculator also skip what the .gitignore
+// files of the source directory ignore (spec.useGitignore).
+func (c *Calculator) WithGitignore(enabled bool) *Calculator {
+	c.useGitignore = enabled
+	return c
+}
+
+// WithCache keeps per-file hashes in the .kudev/cache directory of
+// projectRoot, so later runs only re-read files whose size or mtime
+// changed. An empty projectRoot disables the cache.
+func (c *Calculator) WithCache(projectRoot string) *Calculator {
+	if projectRoot == "" {
+		c.cache = nil
+		return c
 	}
+	c.cache = newFileCache(CachePath(projectRoot, c.sourceDir))
+	return c
+}
+
+// fileJob is a file found by the walk, waiting to be hashed.
+type fileJob struct {
+	absPath string
+	relPath string
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
 }
 
+// fileResult is the hash of one file and the stat it was computed from.
+type fileResult struct {
+	relPath string
+	entry   cacheEntry
+	mode    fs.FileMode
+}
+
+// dockerignoreFile is the build context's .dockerignore, relative to the
+// source directory.
+const dockerignoreFile = ".dockerignore"
+
 // Calculate computes the hash of all source files.
-// Returns an 8-character hash string.
+// Returns Options.Length hex characters (8 by default).
+//
+// Files are hashed by a bounded pool of workers while the walk is still
+// running; the result does not depend on the order they finish in.
 func (c *Calculator) Calculate(ctx context.Context) (string, error) {
+	newHasher, err := c.opts.newHasher()
+	if err != nil {
+		return "", err
+	}
+	length := c.opts.length()
+	if size := hex.EncodedLen(newHasher().Size()); length < 1 || length > size {
+		return "", fmt.Errorf("hash length must be between 1 and %d, got %d", size, length)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Files modified around now may change again without a new mtime
+	racyAfter := time.Now().Add(-racyWindow)
+
+	var cached map[string]cacheEntry
+	if c.cache != nil {
+		cached = c.cache.entries(c.opts.Algorithm)
+	}
+
+	jobs := make(chan fileJob)
+	results := make(chan fileResult)
+
+	// Hash files concurrently; the first failure stops everything
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	workers := c.workers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				entry, ok := cached[job.relPath]
+				if !ok || !entry.matches(job.size, job.modTime) {
+					hash, err := hashFile(newHasher(), job.absPath, job.relPath)
+					if err != nil {
+						fail(fmt.Errorf("failed to hash file %s: %w", job.relPath, err))
+						continue
+					}
+					entry = cacheEntry{Size: job.size, ModTime: job.modTime.UnixNano(), Hash: hash}
+					if job.modTime.After(racyAfter) {
+						// Hash it again next time rather than trust the mtime
+						entry.ModTime = 0
+					}
+				}
+				select {
+				case results <- fileResult{relPath: job.relPath, entry: entry, mode: job.mode}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
 	// Collect all file hashes
 	var fileHashes []string
+	entries := make(map[string]cacheEntry)
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for res := range results {
+			h := res.entry.Hash
+			if c.opts.IncludeModes {
+				// Modes are not cached: chmod leaves the mtime alone
+				h += fmt.Sprintf(":%o", res.mode.Perm())
+			}
+			fileHashes = append(fileHashes, h)
+			entries[res.relPath] = res.entry
+		}
+	}()
+
+	// .gitignore files are read as the walk reaches their directory
+	var ignored *gitignore.Matcher
+	if c.useGitignore {
+		ignored = &gitignore.Matcher{}
+	}
 
 	// Walk the directory
-	err := filepath.WalkDir(c.sourceDir, func(path string, d fs.DirEntry, err error) error {
+	walkErr := filepath.WalkDir(c.sourceDir, func(path string, d fs.DirEntry, err error) error {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
@@ -57,59 +270,134 @@ func (c *Calculator) Calculate(ctx context.Context) (string, error) {
 
 		// Skip directories but check if we should skip entire subtree
 		if d.IsDir() {
-			if c.shouldExclude(relPath) {
+			if c.shouldExclude(relPath) || ignored.Match(relPath, true) {
 				return filepath.SkipDir
 			}
+			if ignored != nil {
+				return ignored.AddDir(c.sourceDir, relPath)
+			}
 			return nil
 		}
 
 		// Skip excluded files
-		if c.shouldExclude(relPath) {
+		if c.shouldExclude(relPath) || ignored.Match(relPath, false) {
 			return nil
 		}
 
-		// Hash the file
-		hash, err := c.hashFile(path, relPath)
+		// A .dockerignore holding nothing but kudev's block is generated
+		// from spec.exclude (see hashFile)
+		if relPath == dockerignoreFile {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if len(dockerignore.WithoutBlock(content)) == 0 {
+				return nil
+			}
+		}
+
+		info, err := d.Info()
 		if err != nil {
-			return fmt.Errorf("failed to hash file %s: %w", relPath, err)
+			return err
 		}
 
-		fileHashes = append(fileHashes, hash)
-		return nil
+		// Hand the file to a worker
+		job := fileJob{absPath: path, relPath: relPath, size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+		select {
+		case jobs <- job:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	})
 
-	if err != nil {
-		return "", fmt.Errorf("failed to walk directory: %w", err)
+	if walkErr == nil {
+		walkErr = c.queueExtraFiles(ctx, jobs)
+	}
+
+	close(jobs)
+	wg.Wait()
+	close(results)
+	<-collected
+
+	// A worker failure cancels the walk; report the cause, not the cancellation
+	if firstErr != nil {
+		return "", firstErr
+	}
+	if walkErr != nil {
+		return "", fmt.Errorf("failed to walk directory: %w", walkErr)
+	}
+	// Workers drop their results once cancelled, even after the walk
+	// finished; a hash of the remaining files must not be returned
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
 
 	if len(fileHashes) == 0 {
 		return "", fmt.Errorf("no files found in %s (all excluded?)", c.sourceDir)
 	}
 
-	// Sort for determinism (filesystem order varies)
+	// A cache that cannot be written only costs a full re-hash next time
+	if c.cache != nil {
+		_ = c.cache.replace(c.opts.Algorithm, entries)
+	}
+
+	// Sort for determinism (filesystem and worker order vary)
 	sort.Strings(fileHashes)
 
 	// Combine all file hashes into final hash
-	finalHasher := sha256.New()
+	finalHasher := newHasher()
 	for _, h := range fileHashes {
 		io.WriteString(finalHasher, h)
 	}
 
 	fullHash := hex.EncodeToString(finalHasher.Sum(nil))
 
-	// Return first 8 characters
-	return fullHash[:8], nil
+	return fullHash[:length], nil
+}
+
+// queueExtraFiles hands the extra files to the workers, named by their
+// path relative to the source directory.
+func (c *Calculator) queueExtraFiles(ctx context.Context, jobs chan<- fileJob) error {
+	for _, path := range c.extraFiles {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(c.sourceDir, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+
+		job := fileJob{absPath: path, relPath: relPath, size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
 }
 
-// hashFile computes the hash of a single file.
+// hashFile computes the hash of a single file with hasher.
 // Includes both path and content for complete uniqueness.
-func (c *Calculator) hashFile(absPath, relPath string) (string, error) {
-	hasher := sha256.New()
+func hashFile(hasher stdhash.Hash, absPath, relPath string) (string, error) {
 
 	// Include relative path in hash
 	// This ensures renaming a file changes the hash
 	io.WriteString(hasher, relPath)
 
+	// The kudev block of .dockerignore follows spec.exclude, not the
+	// sources; rewriting it before a build must not change the hash
+	if relPath == dockerignoreFile {
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return "", err
+		}
+		hasher.Write(dockerignore.WithoutBlock(content))
+		return hex.EncodeToString(hasher.Sum(nil)), nil
+	}
+
 	// Read and hash file content
 	file, err := os.Open(absPath)
 	if err != nil {
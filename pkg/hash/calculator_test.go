@@ -4,9 +4,13 @@ package hash
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/dockerignore"
 )
 
 func TestCalculate_Deterministic(t *testing.T) {
@@ -42,6 +46,46 @@ func TestCalculate_Deterministic(t *testing.T) {
 	}
 }
 
+func TestCalculate_IndependentOfWorkerCount(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := 0; i < 200; i++ {
+		dir := filepath.Join(tmpDir, fmt.Sprintf("pkg%d", i%10))
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.go", i)), []byte(fmt.Sprintf("package p%d", i)), 0644)
+	}
+
+	serial := NewCalculator(tmpDir, nil)
+	serial.workers = 1
+	want, err := serial.Calculate(context.Background())
+	if err != nil {
+		t.Fatalf("serial calculation failed: %v", err)
+	}
+
+	parallel := NewCalculator(tmpDir, nil)
+	parallel.workers = 8
+	for i := 0; i < 5; i++ {
+		got, err := parallel.Calculate(context.Background())
+		if err != nil {
+			t.Fatalf("parallel calculation failed: %v", err)
+		}
+		if got != want {
+			t.Fatalf("parallel hash = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestCalculate_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewCalculator(tmpDir, nil).Calculate(ctx); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
+
 func TestCalculate_ChangesWithContent(t *testing.T) {
 	tmpDir := t.TempDir()
 	mainFile := filepath.Join(tmpDir, "main.go")
@@ -144,6 +188,99 @@ func TestCalculate_CustomExclusions(t *testing.T) {
 	}
 }
 
+func TestCalculate_IgnoresDockerignoreBlock(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644)
+	ctx := context.Background()
+
+	before, err := NewCalculator(tmpDir, nil).Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// A .dockerignore created by the build holds only kudev's block
+	if _, err := dockerignore.Sync(tmpDir, []string{"*.log"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := NewCalculator(tmpDir, nil).Calculate(ctx); got != before {
+		t.Errorf("generated .dockerignore changed the hash: %s != %s", got, before)
+	}
+
+	// User entries still count, but the block appended to them does not
+	os.WriteFile(filepath.Join(tmpDir, ".dockerignore"), []byte("secrets/\n"), 0644)
+	withUser, _ := NewCalculator(tmpDir, nil).Calculate(ctx)
+	if withUser == before {
+		t.Error("user .dockerignore entries should affect the hash")
+	}
+	if _, err := dockerignore.Sync(tmpDir, []string{"*.log"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := NewCalculator(tmpDir, nil).Calculate(ctx); got != withUser {
+		t.Errorf("kudev block changed the hash: %s != %s", got, withUser)
+	}
+}
+
+func TestCalculate_ExtraFiles(t *testing.T) {
+	root := t.TempDir()
+	contextDir := filepath.Join(root, "services", "api")
+	os.MkdirAll(contextDir, 0755)
+	os.WriteFile(filepath.Join(contextDir, "main.go"), []byte("package main"), 0644)
+	dockerfile := filepath.Join(root, "Dockerfile")
+	os.WriteFile(dockerfile, []byte("FROM golang:1.22"), 0644)
+
+	calc := NewCalculator(contextDir, nil).WithExtraFiles(dockerfile)
+	ctx := context.Background()
+
+	hash1, err := calc.Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	os.WriteFile(dockerfile, []byte("FROM golang:1.23"), 0644)
+	hash2, err := calc.Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if hash1 == hash2 {
+		t.Error("editing the Dockerfile outside the context should change the hash")
+	}
+
+	os.Remove(dockerfile)
+	if _, err := calc.Calculate(ctx); err == nil {
+		t.Error("expected an error for a missing extra file")
+	}
+}
+
+func TestCalculate_Gitignore(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("bin/\n"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, "web", "out"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "web", ".gitignore"), []byte("out\n"), 0644)
+
+	ctx := context.Background()
+	calc := NewCalculator(tmpDir, nil).WithGitignore(true)
+	before, err := calc.Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// Build output ignored by the root and nested .gitignore
+	os.MkdirAll(filepath.Join(tmpDir, "bin"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "bin", "app"), []byte("binary"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "web", "out", "bundle.js"), []byte("js"), 0644)
+
+	after, _ := calc.Calculate(ctx)
+	if before != after {
+		t.Errorf("gitignored files should not affect hash: %s != %s", before, after)
+	}
+
+	// Without the option they count
+	if plain, _ := NewCalculator(tmpDir, nil).Calculate(ctx); plain == after {
+		t.Error("gitignored files should affect hash without WithGitignore")
+	}
+}
+
 func TestShouldExclude(t *testing.T) {
 	calc := NewCalculator("/project", nil)
 
@@ -219,3 +356,84 @@ func TestLoadDockerignore_NotExists(t *testing.T) {
 		t.Errorf("expected nil patterns, got %v", patterns)
 	}
 }
+
+func TestCalculate_Options(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644)
+	ctx := context.Background()
+
+	short, err := NewCalculator(tmpDir, nil).Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	long, err := NewCalculator(tmpDir, nil).WithOptions(Options{Length: 16}).Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if len(long) != 16 || long[:8] != short {
+		t.Errorf("16-char hash = %s, want a longer %s", long, short)
+	}
+
+	sha512Hash, err := NewCalculator(tmpDir, nil).WithOptions(Options{Algorithm: "sha512"}).Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if len(sha512Hash) != 8 || sha512Hash == short {
+		t.Errorf("sha512 hash = %s, want 8 characters differing from sha256 %s", sha512Hash, short)
+	}
+
+	if _, err := NewCalculator(tmpDir, nil).WithOptions(Options{Algorithm: "md5"}).Calculate(ctx); err == nil {
+		t.Error("expected error for unknown algorithm")
+	}
+	if _, err := NewCalculator(tmpDir, nil).WithOptions(Options{Length: 65}).Calculate(ctx); err == nil {
+		t.Error("expected error for a length beyond sha256")
+	}
+}
+
+func TestCalculate_IncludeModes(t *testing.T) {
+	root := t.TempDir()
+	script := filepath.Join(root, "run.sh")
+	writeAged(t, script, "#!/bin/sh", time.Now().Add(-time.Hour))
+	ctx := context.Background()
+
+	withModes := NewCalculator(root, nil).WithOptions(Options{IncludeModes: true}).WithCache(root)
+	without := NewCalculator(root, nil)
+
+	before, _ := withModes.Calculate(ctx)
+	plainBefore, _ := without.Calculate(ctx)
+
+	// chmod keeps the mtime, so the cached content hash is reused
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	after, _ := withModes.Calculate(ctx)
+	plainAfter, _ := without.Calculate(ctx)
+	if after == before {
+		t.Errorf("hash with includeModes should change on chmod: %s", after)
+	}
+	if plainAfter != plainBefore {
+		t.Errorf("hash without includeModes changed on chmod: %s != %s", plainAfter, plainBefore)
+	}
+}
+
+func TestCalculate_CacheKeyedByAlgorithm(t *testing.T) {
+	root := t.TempDir()
+	writeAged(t, filepath.Join(root, "main.go"), "package main", time.Now().Add(-time.Hour))
+	ctx := context.Background()
+
+	if _, err := NewCalculator(root, nil).WithCache(root).Calculate(ctx); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	opts := Options{Algorithm: "sha512"}
+	got, err := NewCalculator(root, nil).WithOptions(opts).WithCache(root).Calculate(ctx)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	want, _ := NewCalculator(root, nil).WithOptions(opts).Calculate(ctx)
+	if got != want {
+		t.Errorf("sha512 hash = %s, want %s (sha256 cache must not be reused)", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+// Package hosts manages kudev's entries in the system hosts file, so apps
+// can be reached locally as <app>.kudev.local, mirroring cluster DNS names.
+package hosts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Domain is the suffix of the registered hostnames.
+const Domain = "kudev.local"
+
+// kudev's entries live between these lines; the rest of the file is
+// never touched.
+const (
+	beginMarker = "# BEGIN kudev"
+	endMarker   = "# END kudev"
+)
+
+// Hostname returns the local hostname of an app, e.g. "myapp.kudev.local".
+func Hostname(app string) string {
+	return app + "." + Domain
+}
+
+// Entry maps a hostname to an address.
+type Entry struct {
+	Address  string
+	Hostname string
+}
+
+// DefaultPath returns the system hosts file path.
+func DefaultPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "etc", "hosts")
+	}
+	return "/etc/hosts"
+}
+
+// File edits kudev's block of a hosts file.
+type File struct {
+	path string
+
+	// write replaces the file content (replaceable in tests)
+	write func(path string, data []byte) error
+}
+
+// NewFile creates a File for the hosts file at path. Writes that are
+// denied fall back to 'sudo tee', which prompts for a password.
+func NewFile(path string) *File {
+	return &File{path: path, write: writeWithSudo}
+}
+
+// Path returns the hosts file path.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Entries returns kudev's entries, in file order.
+func (f *File) Entries() ([]Entry, error) {
+	_, entries, _, err := f.read()
+	return entries, err
+}
+
+// Add registers entries, replacing the address of hostnames already
+// present. The file is only written when something changes, so repeated
+// calls don't prompt for sudo. Reports whether the file was written.
+func (f *File) Add(entries ...Entry) (bool, error) {
+	before, current, after, err := f.read()
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	for _, e := range entries {
+		i := indexOf(current, e.Hostname)
+		switch {
+		case i < 0:
+			current = append(current, e)
+			changed = true
+		case current[i].Address != e.Address:
+			current[i].Address = e.Address
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, f.save(before, current, after)
+}
+
+// Remove unregisters hostnames; without any, every kudev entry is removed.
+// Reports whether the file was written.
+func (f *File) Remove(hostnames ...string) (bool, error) {
+	before, current, after, err := f.read()
+	if err != nil {
+		return false, err
+	}
+
+	var kept []Entry
+	if len(hostnames) > 0 {
+		for _, e := range current {
+			if !contains(hostnames, e.Hostname) {
+				kept = append(kept, e)
+			}
+		}
+	}
+	if len(kept) == len(current) {
+		return false, nil
+	}
+	return true, f.save(before, kept, after)
+}
+
+// read splits the file into the lines before kudev's block, its entries
+// and the lines after it. A missing block yields no entries and all lines
+// in before.
+func (f *File) read() (before []string, entries []Entry, after []string, err error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil, fmt.Errorf("failed to read hosts file %s: %w", f.path, err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+
+	begin, end := -1, -1
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case beginMarker:
+			begin = i
+		case endMarker:
+			if begin >= 0 && end < 0 {
+				end = i
+			}
+		}
+	}
+	if begin < 0 || end < 0 {
+		return lines, nil, nil, nil
+	}
+
+	for _, line := range lines[begin+1 : end] {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, name := range fields[1:] {
+			entries = append(entries, Entry{Address: fields[0], Hostname: name})
+		}
+	}
+	return lines[:begin], entries, lines[end+1:], nil
+}
+
+// save writes the file back with kudev's block rebuilt from entries.
+// The block is dropped when entries is empty.
+func (f *File) save(before []string, entries []Entry, after []string) error {
+	lines := append([]string{}, before...)
+	if len(entries) > 0 {
+		sorted := append([]Entry{}, entries...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Hostname < sorted[j].Hostname })
+
+		lines = append(lines, beginMarker)
+		for _, e := range sorted {
+			lines = append(lines, e.Address+"\t"+e.Hostname)
+		}
+		lines = append(lines, endMarker)
+	}
+	lines = append(lines, after...)
+
+	data := []byte(strings.Join(lines, "\n") + "\n")
+	if err := f.write(f.path, data); err != nil {
+		return fmt.Errorf("failed to update hosts file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// writeWithSudo replaces the file in place (keeping its owner and mode),
+// retrying through 'sudo tee' when permission is denied.
+func writeWithSudo(path string, data []byte) error {
+	err := os.WriteFile(path, data, 0644)
+	if err == nil || !errors.Is(err, fs.ErrPermission) || runtime.GOOS == "windows" {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Updating %s requires administrator rights (sudo)\n", path)
+	cmd := exec.Command("sudo", "tee", path)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = io.Discard
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo tee failed: %w", err)
+	}
+	return nil
+}
+
+func indexOf(entries []Entry, hostname string) int {
+	for i, e := range entries {
+		if e.Hostname == hostname {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const systemHosts = `127.0.0.1	localhost
+::1	localhost
+`
+
+func newTestFile(t *testing.T, content string) (*File, *int) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hosts")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writes := 0
+	f := NewFile(path)
+	f.write = func(path string, data []byte) error {
+		writes++
+		return os.WriteFile(path, data, 0644)
+	}
+	return f, &writes
+}
+
+func readFile(t *testing.T, f *File) string {
+	t.Helper()
+	data, err := os.ReadFile(f.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFile_AddAndRemove(t *testing.T) {
+	f, writes := newTestFile(t, systemHosts)
+
+	changed, err := f.Add(
+		Entry{Address: "127.0.0.1", Hostname: Hostname("web")},
+		Entry{Address: "127.0.0.1", Hostname: Hostname("api")},
+	)
+	if err != nil || !changed {
+		t.Fatalf("Add() = %v, %v", changed, err)
+	}
+
+	want := systemHosts + "# BEGIN kudev\n127.0.0.1\tapi.kudev.local\n127.0.0.1\tweb.kudev.local\n# END kudev\n"
+	if got := readFile(t, f); got != want {
+		t.Errorf("hosts file =\n%s\nwant\n%s", got, want)
+	}
+
+	// Already registered: no write, so no sudo prompt
+	if changed, err := f.Add(Entry{Address: "127.0.0.1", Hostname: "api.kudev.local"}); err != nil || changed {
+		t.Errorf("repeated Add() = %v, %v", changed, err)
+	}
+	if *writes != 1 {
+		t.Errorf("got %d writes, want 1", *writes)
+	}
+
+	// Address change
+	if _, err := f.Add(Entry{Address: "::1", Hostname: "api.kudev.local"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := f.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0] != (Entry{Address: "::1", Hostname: "api.kudev.local"}) {
+		t.Errorf("Entries() = %v", entries)
+	}
+
+	if _, err := f.Remove("api.kudev.local"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := f.Entries(); len(entries) != 1 || entries[0].Hostname != "web.kudev.local" {
+		t.Errorf("Entries() after Remove = %v", entries)
+	}
+
+	// Removing everything drops the block and leaves the file as it was
+	if _, err := f.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, f); got != systemHosts {
+		t.Errorf("hosts file =\n%s\nwant\n%s", got, systemHosts)
+	}
+}
+
+func TestFile_KeepsLinesAroundBlock(t *testing.T) {
+	content := "127.0.0.1 localhost\n# BEGIN kudev\n127.0.0.1 old.kudev.local\n# END kudev\n10.0.0.1 nas\n"
+	f, _ := newTestFile(t, content)
+
+	if _, err := f.Add(Entry{Address: "127.0.0.1", Hostname: "new.kudev.local"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "127.0.0.1 localhost\n# BEGIN kudev\n127.0.0.1\tnew.kudev.local\n127.0.0.1\told.kudev.local\n# END kudev\n10.0.0.1 nas\n"
+	if got := readFile(t, f); got != want {
+		t.Errorf("hosts file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFile_MissingFile(t *testing.T) {
+	f, writes := newTestFile(t, "")
+
+	entries, err := f.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Entries() = %v, %v", entries, err)
+	}
+	if changed, err := f.Remove(); err != nil || changed || *writes != 0 {
+		t.Errorf("Remove() = %v, %v (%d writes)", changed, err, *writes)
+	}
+}
@@ -0,0 +1,124 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ConfigLoader produces the REST config used to build clients.
+type ConfigLoader func() (*rest.Config, error)
+
+// LoadRESTConfig loads the REST config for the current kubeconfig context
+// (KUBECONFIG or ~/.kube/config).
+func LoadRESTConfig() (*rest.Config, error) {
+	return LoadRESTConfigForContext("")
+}
+
+// LoadRESTConfigForContext loads the REST config for the named kubeconfig
+// context, or the current one when name is empty.
+func LoadRESTConfigForContext(name string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: name}
+	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+
+	restConfig, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return restConfig, nil
+}
+
+// ClientCache builds the Kubernetes client once and hands the same
+// instance to every caller. Long-running commands (watch loops, status
+// refreshes) then share one HTTP connection pool instead of re-reading
+// kubeconfig and re-dialing the API server per operation.
+//
+// It is safe for concurrent use.
+type ClientCache struct {
+	load ConfigLoader
+
+	mu         sync.Mutex
+	clientset  kubernetes.Interface
+	restConfig *rest.Config
+	informers  informers.SharedInformerFactory
+
+	// stopInformers is closed by Reset to stop the factory's informers
+	stopInformers chan struct{}
+}
+
+// NewClientCache creates a cache that builds clients from load.
+func NewClientCache(load ConfigLoader) *ClientCache {
+	return &ClientCache{load: load}
+}
+
+// Get returns the cached clientset and REST config, creating them on
+// first use. Errors are not cached, so a later call retries.
+func (c *ClientCache) Get() (kubernetes.Interface, *rest.Config, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.get()
+}
+
+// get is Get with c.mu held.
+func (c *ClientCache) get() (kubernetes.Interface, *rest.Config, error) {
+	if c.clientset != nil {
+		return c.clientset, c.restConfig, nil
+	}
+
+	restConfig, err := c.load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	c.clientset = clientset
+	c.restConfig = restConfig
+	return clientset, restConfig, nil
+}
+
+// Informers returns a shared informer factory on the cached clientset,
+// so watchers of the same resource share one watch and cache, and the
+// channel to Start it with. Callers request their informers, then Start
+// the factory with that channel. Reset closes it: callers holding on to
+// the factory should then call Informers again.
+func (c *ClientCache) Informers() (informers.SharedInformerFactory, <-chan struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clientset, _, err := c.get()
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.informers == nil {
+		c.informers = informers.NewSharedInformerFactory(clientset, 0)
+		c.stopInformers = make(chan struct{})
+	}
+	return c.informers, c.stopInformers, nil
+}
+
+// Reset drops the cached client, e.g. after the kubeconfig context changed.
+// The next Get builds a new one. Informers started from the old factory
+// are stopped.
+func (c *ClientCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.informers != nil {
+		close(c.stopInformers)
+		c.informers.Shutdown()
+	}
+	c.clientset = nil
+	c.restConfig = nil
+	c.informers = nil
+	c.stopInformers = nil
+}
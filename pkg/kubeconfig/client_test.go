@@ -0,0 +1,104 @@
+package kubeconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestClientCache_Get(t *testing.T) {
+	loads := 0
+	cache := NewClientCache(func() (*rest.Config, error) {
+		loads++
+		return &rest.Config{Host: "https://127.0.0.1:6443"}, nil
+	})
+
+	first, cfg, err := cache.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if cfg.Host != "https://127.0.0.1:6443" {
+		t.Errorf("Host = %q", cfg.Host)
+	}
+
+	second, _, err := cache.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if first != second {
+		t.Error("expected the same clientset on repeated Get")
+	}
+	if loads != 1 {
+		t.Errorf("config loaded %d times, want 1", loads)
+	}
+
+	cache.Reset()
+	third, _, _ := cache.Get()
+	if third == first {
+		t.Error("expected a new clientset after Reset")
+	}
+	if loads != 2 {
+		t.Errorf("config loaded %d times after Reset, want 2", loads)
+	}
+}
+
+func TestClientCache_ErrorNotCached(t *testing.T) {
+	fail := true
+	cache := NewClientCache(func() (*rest.Config, error) {
+		if fail {
+			return nil, errors.New("no kubeconfig")
+		}
+		return &rest.Config{Host: "https://127.0.0.1:6443"}, nil
+	})
+
+	if _, _, err := cache.Get(); err == nil {
+		t.Fatal("expected error")
+	}
+
+	fail = false
+	if _, _, err := cache.Get(); err != nil {
+		t.Errorf("expected retry to succeed, got %v", err)
+	}
+}
+
+func TestClientCache_Informers(t *testing.T) {
+	cache := NewClientCache(func() (*rest.Config, error) {
+		return &rest.Config{Host: "https://127.0.0.1:6443"}, nil
+	})
+
+	first, stop, err := cache.Informers()
+	if err != nil {
+		t.Fatalf("Informers failed: %v", err)
+	}
+	second, _, _ := cache.Informers()
+	if first != second {
+		t.Error("expected the same informer factory on repeated Informers")
+	}
+
+	// A started informer must not keep Reset from returning
+	first.Core().V1().Pods().Informer()
+	first.Start(stop)
+
+	done := make(chan struct{})
+	go func() {
+		cache.Reset()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Reset did not return")
+	}
+	select {
+	case <-stop:
+	default:
+		t.Error("expected Reset to close the stop channel")
+	}
+
+	third, _, _ := cache.Informers()
+	if third == first {
+		t.Error("expected a new informer factory after Reset")
+	}
+}
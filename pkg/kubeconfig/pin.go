@@ -0,0 +1,62 @@
+package kubeconfig
+
+import (
+	"fmt"
+)
+
+// ContextDriftError reports that the kubeconfig current-context changed
+// after a long-running command pinned it.
+type ContextDriftError struct {
+	Pinned  string
+	Current string
+}
+
+func (e *ContextDriftError) Error() string {
+	return fmt.Sprintf("kubeconfig context changed from %q to %q since startup", e.Pinned, e.Current)
+}
+
+// ContextPin remembers the context a long-running command (e.g. watch)
+// started with, so later deploys can refuse to target another cluster.
+type ContextPin struct {
+	pinned Context
+	load   func() (*Context, error)
+}
+
+// PinCurrentContext pins the current kubeconfig context.
+func PinCurrentContext() (*ContextPin, error) {
+	return NewContextPin(LoadCurrentContext)
+}
+
+// NewContextPin pins the context returned by load; Check calls load again.
+func NewContextPin(load func() (*Context, error)) (*ContextPin, error) {
+	current, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &ContextPin{pinned: *current, load: load}, nil
+}
+
+// Name returns the pinned context name.
+func (p *ContextPin) Name() string {
+	return p.pinned.Name
+}
+
+// Check re-reads kubeconfig and returns a *ContextDriftError when the
+// current context (or the server it points to) differs from the pinned one.
+func (p *ContextPin) Check() error {
+	current, err := p.load()
+	if err != nil {
+		return fmt.Errorf("failed to re-read kubeconfig context: %w", err)
+	}
+
+	if current.Name != p.pinned.Name {
+		return &ContextDriftError{Pinned: p.pinned.Name, Current: current.Name}
+	}
+	if current.ClusterServer != p.pinned.ClusterServer {
+		return &ContextDriftError{
+			Pinned:  fmt.Sprintf("%s (%s)", p.pinned.Name, p.pinned.ClusterServer),
+			Current: fmt.Sprintf("%s (%s)", current.Name, current.ClusterServer),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package kubeconfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestContextPin_Check(t *testing.T) {
+	kindDev := Context{Name: "kind-dev", ClusterServer: "https://127.0.0.1:6443"}
+
+	tests := []struct {
+		name      string
+		current   Context
+		wantDrift bool
+	}{
+		{name: "unchanged", current: kindDev},
+		{name: "switched context", current: Context{Name: "prod", ClusterServer: "https://prod:6443"}, wantDrift: true},
+		{name: "same name, other server", current: Context{Name: "kind-dev", ClusterServer: "https://10.0.0.1:6443"}, wantDrift: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := kindDev
+			pin, err := NewContextPin(func() (*Context, error) {
+				c := current
+				return &c, nil
+			})
+			if err != nil {
+				t.Fatalf("NewContextPin failed: %v", err)
+			}
+			if pin.Name() != "kind-dev" {
+				t.Errorf("Name() = %q, want kind-dev", pin.Name())
+			}
+
+			current = tt.current
+			err = pin.Check()
+
+			var drift *ContextDriftError
+			if got := errors.As(err, &drift); got != tt.wantDrift {
+				t.Errorf("Check() error = %v, wantDrift %v", err, tt.wantDrift)
+			}
+		})
+	}
+}
+
+func TestContextPin_LoadError(t *testing.T) {
+	if _, err := NewContextPin(func() (*Context, error) {
+		return nil, errors.New("no kubeconfig")
+	}); err == nil {
+		t.Error("expected error when the context cannot be loaded")
+	}
+}
@@ -0,0 +1,63 @@
+package kubeconfig
+
+import (
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// tracingRoundTripper logs every Kubernetes API request with its latency.
+type tracingRoundTripper struct {
+	next   http.RoundTripper
+	logger logging.LoggerInterface
+}
+
+// NewTracingRoundTripper wraps next so each request is logged as
+// method, path, status and latency.
+func NewTracingRoundTripper(next http.RoundTripper, logger logging.LoggerInterface) http.RoundTripper {
+	return &tracingRoundTripper{
+		next:   next,
+		logger: logging.OrDefault(logger),
+	}
+}
+
+func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	path := req.URL.Path
+	if req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+
+	if err != nil {
+		t.logger.Info("api request failed",
+			"method", req.Method,
+			"path", path,
+			"latency", latency,
+			"error", err,
+		)
+		return resp, err
+	}
+
+	t.logger.Info("api request",
+		"method", req.Method,
+		"path", path,
+		"status", resp.StatusCode,
+		"latency", latency,
+	)
+	return resp, nil
+}
+
+// EnableTracing installs the tracing transport on a REST config.
+// Clients created from cfg afterwards (including port-forward dialers)
+// log every API call.
+func EnableTracing(cfg *rest.Config, logger logging.LoggerInterface) {
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return NewTracingRoundTripper(rt, logger)
+	})
+}
@@ -0,0 +1,66 @@
+package kubeconfig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestTracingRoundTripper(t *testing.T) {
+	logger := &util.MockLogger{}
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Request: req}, nil
+	})
+
+	rt := NewTracingRoundTripper(next, logger)
+	req := httptest.NewRequest(http.MethodGet, "https://cluster/api/v1/namespaces/default/pods?labelSelector=app%3Dx", nil)
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if len(logger.Messages) != 1 || logger.Messages[0] != "api request" {
+		t.Errorf("expected one 'api request' log, got %v", logger.Messages)
+	}
+}
+
+func TestTracingRoundTripper_Error(t *testing.T) {
+	logger := &util.MockLogger{}
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	rt := NewTracingRoundTripper(next, logger)
+	req := httptest.NewRequest(http.MethodPost, "https://cluster/apis/apps/v1/deployments", nil)
+
+	if _, err := rt.RoundTrip(req); err == nil {
+		t.Fatal("expected error to be passed through")
+	}
+	if len(logger.Messages) != 1 || logger.Messages[0] != "api request failed" {
+		t.Errorf("expected one 'api request failed' log, got %v", logger.Messages)
+	}
+}
+
+func TestEnableTracing(t *testing.T) {
+	cfg := &rest.Config{}
+	EnableTracing(cfg, &util.MockLogger{})
+
+	if cfg.WrapTransport == nil {
+		t.Fatal("WrapTransport should be set")
+	}
+	if _, ok := cfg.WrapTransport(http.DefaultTransport).(*tracingRoundTripper); !ok {
+		t.Error("WrapTransport should install the tracing round tripper")
+	}
+}
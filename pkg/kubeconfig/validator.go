@@ -111,6 +111,7 @@ func defaultAllowedContexts() []string {
 		"docker-desktop",
 		"docker-for-desktop",
 		"minikube",
+		"rancher-desktop",
 		"kind-*",
 		"k3d-*",
 		"*-local*",
@@ -0,0 +1,171 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+// CorrelationIDKey is the log key of the ID set by WithCorrelationID.
+const CorrelationIDKey = "correlationID"
+
+// ContextLogger is a LoggerInterface that also takes a context, whose
+// values (see ContextWithValues) are added to the log line.
+//
+// Call sites migrate one at a time: wrap any LoggerInterface with
+// AsContextLogger, or bind a context with WithContext.
+type ContextLogger interface {
+	LoggerInterface
+
+	InfoContext(ctx context.Context, msg string, keysAndValues ...interface{})
+	ErrorContext(ctx context.Context, err error, msg string, keysAndValues ...interface{})
+	DebugContext(ctx context.Context, msg string, keysAndValues ...interface{})
+	WarnContext(ctx context.Context, msg string, keysAndValues ...interface{})
+}
+
+type contextValuesKey struct{}
+
+// ContextWithValues returns ctx carrying keysAndValues in addition to
+// those already on it; context-aware logging adds them to every line.
+func ContextWithValues(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	existing := ValuesFromContext(ctx)
+	values := make([]interface{}, 0, len(existing)+len(keysAndValues))
+	values = append(values, existing...)
+	values = append(values, keysAndValues...)
+	return context.WithValue(ctx, contextValuesKey{}, values)
+}
+
+// ValuesFromContext returns the values set with ContextWithValues.
+func ValuesFromContext(ctx context.Context) []interface{} {
+	if ctx == nil {
+		return nil
+	}
+	values, _ := ctx.Value(contextValuesKey{}).([]interface{})
+	return values
+}
+
+// WithCorrelationID tags the log lines written with ctx with id, so the
+// lines of one operation (e.g. a watch rebuild) can be grouped.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return ContextWithValues(ctx, CorrelationIDKey, id)
+}
+
+// CorrelationID returns the innermost ID set with WithCorrelationID, or "".
+func CorrelationID(ctx context.Context) string {
+	values := ValuesFromContext(ctx)
+	for i := len(values) - 2; i >= 0; i -= 2 {
+		if values[i] == CorrelationIDKey {
+			id, _ := values[i+1].(string)
+			return id
+		}
+	}
+	return ""
+}
+
+// NewCorrelationID returns a random 8-character ID.
+func NewCorrelationID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// withContextValues prepends the context's values to keysAndValues.
+func withContextValues(ctx context.Context, keysAndValues []interface{}) []interface{} {
+	values := ValuesFromContext(ctx)
+	if len(values) == 0 {
+		return keysAndValues
+	}
+	out := make([]interface{}, 0, len(values)+len(keysAndValues))
+	out = append(out, values...)
+	return append(out, keysAndValues...)
+}
+
+// replaceContextValues returns ctx with its logged values replaced.
+func replaceContextValues(ctx context.Context, values []interface{}) context.Context {
+	return context.WithValue(ctx, contextValuesKey{}, values)
+}
+
+// ErrorFields returns structured fields describing err beyond its
+// message: the exit code and suggestion of kudev errors.
+func ErrorFields(err error) []interface{} {
+	var kerr interface {
+		ExitCode() int
+		SuggestedAction() string
+	}
+	if err == nil || !errors.As(err, &kerr) {
+		return nil
+	}
+
+	fields := []interface{}{"exitCode", kerr.ExitCode()}
+	if suggestion := kerr.SuggestedAction(); suggestion != "" {
+		fields = append(fields, "suggestion", suggestion)
+	}
+	return fields
+}
+
+// AsContextLogger returns l as a ContextLogger. Loggers without context
+// support are adapted: the context's values become ordinary fields.
+func AsContextLogger(l LoggerInterface) ContextLogger {
+	l = OrDefault(l)
+	if cl, ok := l.(ContextLogger); ok {
+		return cl
+	}
+	return contextAdapter{l}
+}
+
+// contextAdapter adds context values to a LoggerInterface.
+type contextAdapter struct {
+	LoggerInterface
+}
+
+func (a contextAdapter) InfoContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	a.Info(msg, withContextValues(ctx, keysAndValues)...)
+}
+
+func (a contextAdapter) ErrorContext(ctx context.Context, err error, msg string, keysAndValues ...interface{}) {
+	a.Error(err, msg, withContextValues(ctx, keysAndValues)...)
+}
+
+func (a contextAdapter) DebugContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	a.Debug(msg, withContextValues(ctx, keysAndValues)...)
+}
+
+func (a contextAdapter) WarnContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	a.Warn(msg, withContextValues(ctx, keysAndValues)...)
+}
+
+// WithContext returns a LoggerInterface that logs through l with ctx,
+// so existing Info/Error/... calls pick up the context's values.
+func WithContext(l LoggerInterface, ctx context.Context) LoggerInterface {
+	if len(ValuesFromContext(ctx)) == 0 {
+		return OrDefault(l)
+	}
+	return boundLogger{logger: AsContextLogger(l), ctx: ctx}
+}
+
+// boundLogger is a ContextLogger with a fixed context.
+type boundLogger struct {
+	logger ContextLogger
+	ctx    context.Context
+}
+
+func (b boundLogger) Info(msg string, keysAndValues ...interface{}) {
+	b.logger.InfoContext(b.ctx, msg, keysAndValues...)
+}
+
+func (b boundLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	b.logger.ErrorContext(b.ctx, err, msg, keysAndValues...)
+}
+
+func (b boundLogger) Debug(msg string, keysAndValues ...interface{}) {
+	b.logger.DebugContext(b.ctx, msg, keysAndValues...)
+}
+
+func (b boundLogger) Warn(msg string, keysAndValues ...interface{}) {
+	b.logger.WarnContext(b.ctx, msg, keysAndValues...)
+}
+
+func (b boundLogger) WithValues(keysAndValues ...interface{}) LoggerInterface {
+	return boundLogger{logger: AsContextLogger(b.logger.WithValues(keysAndValues...)), ctx: b.ctx}
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	kerrors "github.com/nanaki-93/kudev/pkg/errors"
+)
+
+func TestContextWithValues(t *testing.T) {
+	ctx := ContextWithValues(context.Background(), "app", "api")
+	ctx = WithCorrelationID(ctx, "first")
+	inner := WithCorrelationID(ctx, "second")
+
+	if got := CorrelationID(inner); got != "second" {
+		t.Errorf("CorrelationID() = %q, want the innermost ID", got)
+	}
+	if got := CorrelationID(ctx); got != "first" {
+		t.Errorf("CorrelationID() = %q, parent context changed", got)
+	}
+	if got := CorrelationID(context.Background()); got != "" {
+		t.Errorf("CorrelationID() = %q without an ID", got)
+	}
+
+	want := []interface{}{"app", "api", CorrelationIDKey, "first"}
+	if got := ValuesFromContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValuesFromContext() = %v, want %v", got, want)
+	}
+}
+
+func TestNewCorrelationID(t *testing.T) {
+	a, b := NewCorrelationID(), NewCorrelationID()
+	if len(a) != 8 || a == b {
+		t.Errorf("NewCorrelationID() = %q, %q", a, b)
+	}
+}
+
+func TestErrorFields(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []interface{}
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "plain error", err: errors.New("boom"), want: nil},
+		{
+			name: "kudev error",
+			err:  &kerrors.ConfigError{Message: "bad config", Suggestion: "run kudev init"},
+			want: []interface{}{"exitCode", kerrors.ExitConfig, "suggestion", "run kudev init"},
+		},
+		{
+			name: "wrapped kudev error",
+			err:  fmt.Errorf("loading: %w", &kerrors.BuildError{Message: "docker failed"}),
+			want: []interface{}{"exitCode", kerrors.ExitBuild},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorFields(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ErrorFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsContextLogger_Adapter(t *testing.T) {
+	inner := &recordingLogger{}
+	l := AsContextLogger(inner)
+
+	ctx := WithCorrelationID(context.Background(), "abc")
+	l.WarnContext(ctx, "slow", "elapsed", "3s")
+
+	want := []interface{}{CorrelationIDKey, "abc", "elapsed", "3s"}
+	if inner.msg != "slow" || !reflect.DeepEqual(inner.kv, want) {
+		t.Errorf("adapter logged %q %v, want %v", inner.msg, inner.kv, want)
+	}
+
+	// Context loggers are returned as they are
+	slogger := NewTextLogger(nil, false)
+	if AsContextLogger(slogger) != ContextLogger(slogger) {
+		t.Error("AsContextLogger wrapped a ContextLogger")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	inner := &recordingLogger{}
+
+	if WithContext(inner, context.Background()) != LoggerInterface(inner) {
+		t.Error("WithContext without values should return the logger")
+	}
+
+	l := WithContext(inner, WithCorrelationID(context.Background(), "abc"))
+	l.Error(errors.New("boom"), "failed", "stage", "build")
+
+	want := []interface{}{CorrelationIDKey, "abc", "stage", "build"}
+	if inner.err == nil || inner.msg != "failed" || !reflect.DeepEqual(inner.kv, want) {
+		t.Errorf("bound logger logged %v %q %v, want %v", inner.err, inner.msg, inner.kv, want)
+	}
+}
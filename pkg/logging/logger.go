@@ -1,12 +1,16 @@
 package logging
 
 import (
+	"context"
 	"flag"
+	"os"
 	"sync"
 
 	"k8s.io/klog/v2"
 )
 
+// LoggerInterface is the logger kudev passes around. See ContextLogger
+// for the context-aware extension.
 type LoggerInterface interface {
 	Info(msg string, keysAndValues ...interface{})
 	Error(err error, msg string, keysAndValues ...interface{})
@@ -14,35 +18,66 @@ type LoggerInterface interface {
 	Warn(msg string, keysAndValues ...interface{})
 	WithValues(keysAndValues ...interface{}) LoggerInterface
 }
+
+// Logger logs through klog, the logger client-go writes to.
 type Logger struct {
 	klog.Logger
 }
 
-var _ LoggerInterface = (*Logger)(nil)
+var _ ContextLogger = (*Logger)(nil)
 
 var (
 	globalLogger LoggerInterface
-	once         sync.Once
 	mutex        sync.RWMutex
+
+	// klogFlags holds klog's flags in a private FlagSet so we never touch
+	// (or parse) the process-wide flag.CommandLine owned by cobra.
+	klogFlags     *flag.FlagSet
+	klogFlagsOnce sync.Once
 )
 
+// InitLogger (re)initializes the global logger with the given verbosity:
+// a slog text logger on stderr (see NewTextLogger), with klog set to the
+// same verbosity for client-go's own output.
+// Safe to call multiple times and from multiple goroutines; the last call wins.
+// Output is masked by the DefaultRedactor.
 func InitLogger(debug bool) LoggerInterface {
-	once.Do(func() {
-		globalLogger = Init(debug)
-	})
+	Init(debug)
+	l := NewRedactingLogger(NewTextLogger(os.Stderr, debug), defaultRedactor)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	globalLogger = l
 	return globalLogger
 }
 
 // Get returns the global logger instance, initializing if needed
 func Get() LoggerInterface {
-	once.Do(func() {
-		globalLogger = Init(false)
-	})
 	mutex.RLock()
-	defer mutex.RUnlock()
+	l := globalLogger
+	mutex.RUnlock()
+	if l != nil {
+		return l
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if globalLogger == nil {
+		Init(false)
+		globalLogger = NewRedactingLogger(NewTextLogger(os.Stderr, false), defaultRedactor)
+	}
 	return globalLogger
 }
 
+// OrDefault returns l, or the global logger when l is nil.
+// Constructors use it so a missing logger never causes a nil-pointer panic.
+func OrDefault(l LoggerInterface) LoggerInterface {
+	if l == nil {
+		return Get()
+	}
+	return l
+}
+
 // SetLogger sets the global logger (for testing with mocks)
 func SetLogger(l LoggerInterface) {
 	mutex.Lock()
@@ -55,7 +90,6 @@ func ResetLogger() {
 	mutex.Lock()
 	defer mutex.Unlock()
 	globalLogger = nil
-	once = sync.Once{}
 }
 
 // Config holds logging configuration
@@ -75,19 +109,21 @@ func DefaultConfig() *Config {
 }
 
 func Init(debug bool) *Logger {
-	klog.InitFlags(nil)
-	klog.SetOutput(nil)
-	klog.SetLogger(klog.NewKlogr())
+	klogFlagsOnce.Do(func() {
+		klogFlags = flag.NewFlagSet("klog", flag.ContinueOnError)
+		klog.InitFlags(klogFlags)
+		klog.SetOutput(nil)
+		klog.SetLogger(klog.NewKlogr())
+	})
 
 	verbosity := "0"
 	if debug {
 		verbosity = "4"
 	}
-	if err := flag.Set("v", verbosity); err != nil {
+	if err := klogFlags.Set("v", verbosity); err != nil {
 		panic("Error during setting the log verbosity:" + err.Error())
 	}
 
-	flag.Parse()
 	return &Logger{
 		Logger: klog.Background(),
 	}
@@ -117,3 +153,31 @@ func (l *Logger) WithValues(keysAndValues ...interface{}) LoggerInterface {
 		Logger: l.Logger.WithValues(keysAndValues...),
 	}
 }
+
+func (l *Logger) InfoContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.Info(msg, withContextValues(ctx, keysAndValues)...)
+}
+
+func (l *Logger) ErrorContext(ctx context.Context, err error, msg string, keysAndValues ...interface{}) {
+	l.Error(err, msg, append(ErrorFields(err), withContextValues(ctx, keysAndValues)...)...)
+}
+
+func (l *Logger) DebugContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.Debug(msg, withContextValues(ctx, keysAndValues)...)
+}
+
+func (l *Logger) WarnContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.Warn(msg, withContextValues(ctx, keysAndValues)...)
+}
+
+// NopLogger discards all log output.
+// Used as a safe placeholder before the real logger is initialized.
+type NopLogger struct{}
+
+var _ LoggerInterface = NopLogger{}
+
+func (NopLogger) Info(msg string, keysAndValues ...interface{})             {}
+func (NopLogger) Error(err error, msg string, keysAndValues ...interface{}) {}
+func (NopLogger) Debug(msg string, keysAndValues ...interface{})            {}
+func (NopLogger) Warn(msg string, keysAndValues ...interface{})             {}
+func (n NopLogger) WithValues(keysAndValues ...interface{}) LoggerInterface { return n }
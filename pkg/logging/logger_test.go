@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInitLogger_MultipleCalls(t *testing.T) {
+	defer ResetLogger()
+
+	// Must not panic on repeated initialization (klog flags registered once)
+	InitLogger(false)
+	l := InitLogger(true)
+
+	if l == nil {
+		t.Fatal("InitLogger returned nil")
+	}
+	if Get() != l {
+		t.Error("Get() should return the logger from the last InitLogger call")
+	}
+}
+
+func TestGet_Concurrent(t *testing.T) {
+	ResetLogger()
+	defer ResetLogger()
+
+	var wg sync.WaitGroup
+	results := make([]LoggerInterface, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = Get()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, l := range results {
+		if l == nil {
+			t.Fatalf("Get() returned nil in goroutine %d", i)
+		}
+		if l != results[0] {
+			t.Errorf("goroutine %d got a different logger instance", i)
+		}
+	}
+}
+
+func TestOrDefault(t *testing.T) {
+	defer ResetLogger()
+
+	if OrDefault(nil) == nil {
+		t.Error("OrDefault(nil) should return the global logger")
+	}
+
+	nop := NopLogger{}
+	if OrDefault(nop) != nop {
+		t.Error("OrDefault should return a non-nil logger unchanged")
+	}
+}
+
+func TestNopLogger(t *testing.T) {
+	var l LoggerInterface = NopLogger{}
+
+	// None of these may panic
+	l.Info("info")
+	l.Debug("debug", "key", "value")
+	l.Warn("warn")
+	l.Error(nil, "error")
+	if l.WithValues("k", "v") == nil {
+		t.Error("WithValues should not return nil")
+	}
+}
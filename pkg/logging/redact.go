@@ -0,0 +1,193 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedPlaceholder replaces masked values in output.
+const RedactedPlaceholder = "****"
+
+// minSecretLength avoids masking very short values ("1", "on"),
+// which would shred unrelated output.
+const minSecretLength = 4
+
+// sensitiveNamePattern matches env var and log keys that hold secrets.
+var sensitiveNamePattern = regexp.MustCompile(`(?i)(SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|CREDENTIAL)`)
+
+// IsSensitiveName reports whether a variable or key name suggests a secret value.
+//
+// Examples: DB_PASSWORD, GITHUB_TOKEN, clientSecret, STRIPE_API_KEY
+func IsSensitiveName(name string) bool {
+	return sensitiveNamePattern.MatchString(name)
+}
+
+// Redactor masks registered secret values in text.
+// It is safe for concurrent use.
+type Redactor struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// NewRedactor creates an empty redactor.
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+// AddValue registers a literal secret value to mask.
+func (r *Redactor) AddValue(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, v := range r.values {
+		if v == value {
+			return
+		}
+	}
+	r.values = append(r.values, value)
+
+	// Longest first, so a secret containing another is masked whole
+	sort.Slice(r.values, func(i, j int) bool {
+		return len(r.values[i]) > len(r.values[j])
+	})
+}
+
+// AddEnv registers value if name looks sensitive (see IsSensitiveName).
+func (r *Redactor) AddEnv(name, value string) {
+	if IsSensitiveName(name) {
+		r.AddValue(value)
+	}
+}
+
+// Redact returns s with every registered value replaced by RedactedPlaceholder.
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, RedactedPlaceholder)
+	}
+	return s
+}
+
+// RedactError returns err with registered values masked in its message.
+// err is returned unchanged when it contains nothing to mask.
+func (r *Redactor) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if redacted := r.Redact(msg); redacted != msg {
+		return errors.New(redacted)
+	}
+	return err
+}
+
+// RedactKeysAndValues masks structured log pairs.
+// Values under sensitive keys are always masked; string and error
+// values are scanned for registered secrets.
+func (r *Redactor) RedactKeysAndValues(keysAndValues []interface{}) []interface{} {
+	if len(keysAndValues) == 0 {
+		return keysAndValues
+	}
+
+	out := make([]interface{}, len(keysAndValues))
+	copy(out, keysAndValues)
+
+	for i := 1; i < len(out); i += 2 {
+		if key, ok := out[i-1].(string); ok && IsSensitiveName(key) {
+			out[i] = RedactedPlaceholder
+			continue
+		}
+		switch v := out[i].(type) {
+		case string:
+			out[i] = r.Redact(v)
+		case error:
+			out[i] = r.RedactError(v)
+		}
+	}
+	return out
+}
+
+var defaultRedactor = NewRedactor()
+
+// DefaultRedactor returns the process-wide redactor used by the global logger.
+// Register secrets here (e.g. from config env vars) to mask them in all log output.
+func DefaultRedactor() *Redactor {
+	return defaultRedactor
+}
+
+// Redact masks secrets registered with the default redactor.
+// Use for output that bypasses the logger (rendered YAML, recorded history).
+func Redact(s string) string {
+	return defaultRedactor.Redact(s)
+}
+
+// redactingLogger masks secrets before delegating to another logger.
+type redactingLogger struct {
+	inner    LoggerInterface
+	redactor *Redactor
+}
+
+var _ ContextLogger = (*redactingLogger)(nil)
+
+// NewRedactingLogger wraps inner so messages, errors and values are redacted.
+func NewRedactingLogger(inner LoggerInterface, redactor *Redactor) LoggerInterface {
+	return &redactingLogger{inner: inner, redactor: redactor}
+}
+
+func (l *redactingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.inner.Info(l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+func (l *redactingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.inner.Error(l.redactor.RedactError(err), l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+func (l *redactingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.inner.Debug(l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+func (l *redactingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.inner.Warn(l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+func (l *redactingLogger) WithValues(keysAndValues ...interface{}) LoggerInterface {
+	return &redactingLogger{
+		inner:    l.inner.WithValues(l.redactor.RedactKeysAndValues(keysAndValues)...),
+		redactor: l.redactor,
+	}
+}
+
+func (l *redactingLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	AsContextLogger(l.inner).InfoContext(l.redactContext(ctx), l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+func (l *redactingLogger) ErrorContext(ctx context.Context, err error, msg string, keysAndValues ...interface{}) {
+	AsContextLogger(l.inner).ErrorContext(l.redactContext(ctx), l.redactor.RedactError(err), l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+func (l *redactingLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	AsContextLogger(l.inner).DebugContext(l.redactContext(ctx), l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+func (l *redactingLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	AsContextLogger(l.inner).WarnContext(l.redactContext(ctx), l.redactor.Redact(msg), l.redactor.RedactKeysAndValues(keysAndValues)...)
+}
+
+// redactContext masks the values ctx adds to log lines.
+func (l *redactingLogger) redactContext(ctx context.Context) context.Context {
+	values := ValuesFromContext(ctx)
+	if len(values) == 0 {
+		return ctx
+	}
+	return replaceContextValues(ctx, l.redactor.RedactKeysAndValues(values))
+}
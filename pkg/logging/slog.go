@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// SlogLogger is the default logger, writing through log/slog.
+// Debug messages need a handler enabled at slog.LevelDebug.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+var _ ContextLogger = (*SlogLogger)(nil)
+
+// NewSlogLogger logs through l.
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: l}
+}
+
+// NewTextLogger logs key=value lines to w, including debug messages
+// when debug is set.
+func NewTextLogger(w io.Writer, debug bool) *SlogLogger {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	return NewSlogLogger(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})))
+}
+
+// Slog returns the underlying slog logger.
+func (l *SlogLogger) Slog() *slog.Logger {
+	return l.logger
+}
+
+func (l *SlogLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.InfoContext(context.Background(), msg, keysAndValues...)
+}
+
+func (l *SlogLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.ErrorContext(context.Background(), err, msg, keysAndValues...)
+}
+
+func (l *SlogLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.DebugContext(context.Background(), msg, keysAndValues...)
+}
+
+func (l *SlogLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.WarnContext(context.Background(), msg, keysAndValues...)
+}
+
+func (l *SlogLogger) InfoContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logger.Log(ctx, slog.LevelInfo, msg, withContextValues(ctx, keysAndValues)...)
+}
+
+// ErrorContext logs err under "error", followed by its ErrorFields.
+func (l *SlogLogger) ErrorContext(ctx context.Context, err error, msg string, keysAndValues ...interface{}) {
+	var fields []interface{}
+	if err != nil {
+		fields = append([]interface{}{"error", err.Error()}, ErrorFields(err)...)
+	}
+	l.logger.Log(ctx, slog.LevelError, msg, append(fields, withContextValues(ctx, keysAndValues)...)...)
+}
+
+func (l *SlogLogger) DebugContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logger.Log(ctx, slog.LevelDebug, msg, withContextValues(ctx, keysAndValues)...)
+}
+
+func (l *SlogLogger) WarnContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.logger.Log(ctx, slog.LevelWarn, msg, withContextValues(ctx, keysAndValues)...)
+}
+
+func (l *SlogLogger) WithValues(keysAndValues ...interface{}) LoggerInterface {
+	return &SlogLogger{logger: l.logger.With(keysAndValues...)}
+}
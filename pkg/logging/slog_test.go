@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	kerrors "github.com/nanaki-93/kudev/pkg/errors"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewTextLogger(&buf, false)
+	ctx := WithCorrelationID(context.Background(), "abc")
+
+	l.InfoContext(ctx, "deployed", "app", "api")
+	l.Debug("hidden")
+	l.Warn("slow")
+	l.ErrorContext(ctx, &kerrors.ConfigError{Message: "bad config", Suggestion: "fix it"}, "load failed")
+	l.WithValues("service", "web").Info("scoped")
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=INFO msg=deployed correlationID=abc app=api`,
+		`level=WARN msg=slow`,
+		`level=ERROR msg="load failed" error="bad config" exitCode=2 suggestion="fix it" correlationID=abc`,
+		`msg=scoped service=web`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "hidden") {
+		t.Errorf("debug message logged without debug:\n%s", out)
+	}
+}
+
+func TestSlogLogger_Debug(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewTextLogger(&buf, true)
+
+	l.Debug("details", "n", 3)
+	l.Error(errors.New("boom"), "failed")
+
+	out := buf.String()
+	if !strings.Contains(out, `level=DEBUG msg=details n=3`) {
+		t.Errorf("debug message missing:\n%s", out)
+	}
+	if !strings.Contains(out, `level=ERROR msg=failed error=boom`) {
+		t.Errorf("error missing:\n%s", out)
+	}
+}
@@ -3,6 +3,8 @@ package logs
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -11,53 +13,286 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// DefaultDiscoveryTimeout is how long discovery waits for a pod when the
+// caller passes no timeout.
+const DefaultDiscoveryTimeout = 5 * time.Minute
+
+// DefaultDiscoveryPollInterval is the pause between discovery attempts.
+const DefaultDiscoveryPollInterval = 2 * time.Second
+
+// DiscoveryOptions configure how PodDiscovery picks and waits for pods.
+// Zero fields take their defaults.
+type DiscoveryOptions struct {
+	// Timeout is the wait of Discover* calls passed a zero timeout.
+	// Default: DefaultDiscoveryTimeout
+	Timeout time.Duration
+
+	// PollInterval is the pause between attempts.
+	// Default: DefaultDiscoveryPollInterval
+	PollInterval time.Duration
+
+	// Phases are the pod phases DiscoverPod and DiscoverRolloutPod accept.
+	// Adding Pending attaches to a pod as soon as it is scheduled, e.g. to
+	// follow its logs from the container's first line.
+	// Default: Running
+	Phases []corev1.PodPhase
+
+	// NewestFirst makes DiscoverPod prefer Ready pods, then the most
+	// recently created one, instead of the first pod listed. During a
+	// rolling update this picks the replacement over the retiring pod.
+	NewestFirst bool
+
+	// Progress, when set, is called after each attempt that found no pod.
+	Progress func(DiscoveryAttempt)
+}
+
+// DiscoveryAttempt describes a discovery attempt that found no pod.
+type DiscoveryAttempt struct {
+	// Attempt counts from 1.
+	Attempt int
+
+	// Elapsed is the time since discovery started.
+	Elapsed time.Duration
+
+	// Phases counts the pods matching the label selector by phase;
+	// pods being deleted count as "Terminating".
+	Phases map[string]int
+}
+
+// String summarizes the pods seen, e.g. "2 pods (Pending: 2)".
+func (a DiscoveryAttempt) String() string {
+	total := 0
+	keys := make([]string, 0, len(a.Phases))
+	for phase, n := range a.Phases {
+		total += n
+		keys = append(keys, phase)
+	}
+	if total == 0 {
+		return "no pods"
+	}
+	sort.Strings(keys)
+
+	counts := make([]string, len(keys))
+	for i, phase := range keys {
+		counts[i] = fmt.Sprintf("%s: %d", phase, a.Phases[phase])
+	}
+	noun := "pods"
+	if total == 1 {
+		noun = "pod"
+	}
+	return fmt.Sprintf("%d %s (%s)", total, noun, strings.Join(counts, ", "))
+}
+
 // PodDiscovery finds pods by label selector.
 type PodDiscovery struct {
 	clientset kubernetes.Interface
+	opts      DiscoveryOptions
 }
 
 // NewPodDiscovery creates a new pod discovery instance.
 func NewPodDiscovery(clientset kubernetes.Interface) *PodDiscovery {
-	return &PodDiscovery{clientset: clientset}
+	pd := &PodDiscovery{clientset: clientset}
+	pd.SetOptions(DiscoveryOptions{})
+	return pd
 }
 
-// DiscoverPod finds a pod by app label.
-// Waits up to timeout for a pod to exist and be running.
-func (pd *PodDiscovery) DiscoverPod(ctx context.Context, appName, namespace string, timeout time.Duration) (*corev1.Pod, error) {
-	selector := labels.SelectorFromSet(labels.Set{"app": appName})
+// SetOptions replaces the discovery options, applying defaults.
+func (pd *PodDiscovery) SetOptions(opts DiscoveryOptions) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultDiscoveryTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultDiscoveryPollInterval
+	}
+	if len(opts.Phases) == 0 {
+		opts.Phases = []corev1.PodPhase{corev1.PodRunning}
+	}
+	pd.opts = opts
+}
 
-	deadline := time.Now().Add(timeout)
+// DiscoverPod finds a pod kudev deployed for appName in one of the
+// accepted phases (default: running).
+// Waits up to timeout for such a pod to exist.
+func (pd *PodDiscovery) DiscoverPod(ctx context.Context, appName, namespace string, timeout time.Duration) (*corev1.Pod, error) {
+	what := fmt.Sprintf("pod with label app=%s", appName)
+	return pd.waitFor(ctx, namespace, podSelector(appName), what, timeout, func(pods []corev1.Pod) *corev1.Pod {
+		// Only pods of the current rollout
+		hashes := pd.currentHashes(ctx, appName, namespace)
+		return pd.choose(pods, pd.opts.NewestFirst, func(pod *corev1.Pod) bool {
+			return matchesHash(pod, hashes)
+		})
+	})
+}
 
-	for {
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for pod with label app=%s", appName)
+// DiscoverReadyPod finds a Ready, non-terminating pod kudev deployed for appName.
+// When several qualify, the newest one is returned, so that during a rolling
+// update callers attach to the replacement rather than the pod being retired.
+// Waits up to timeout for such a pod to appear.
+func (pd *PodDiscovery) DiscoverReadyPod(ctx context.Context, appName, namespace string, timeout time.Duration) (*corev1.Pod, error) {
+	what := fmt.Sprintf("ready pod with label app=%s", appName)
+	return pd.waitFor(ctx, namespace, podSelector(appName), what, timeout, func(pods []corev1.Pod) *corev1.Pod {
+		hashes := pd.currentHashes(ctx, appName, namespace)
+		var newest *corev1.Pod
+		for i := range pods {
+			pod := &pods[i]
+			if pod.DeletionTimestamp != nil || !isPodReady(pod) || !matchesHash(pod, hashes) {
+				continue
+			}
+			if newest == nil || pod.CreationTimestamp.After(newest.CreationTimestamp.Time) {
+				newest = pod
+			}
 		}
+		return newest
+	})
+}
+
+// DiscoverRolloutPod finds the newest non-terminating pod in an accepted
+// phase of the rollout labeled kudev-hash=hash. Waits up to timeout for
+// one to appear.
+func (pd *PodDiscovery) DiscoverRolloutPod(ctx context.Context, appName, namespace, hash string, timeout time.Duration) (*corev1.Pod, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		"app":        appName,
+		"managed-by": "kudev",
+		"kudev-hash": hash,
+	}).String()
 
+	what := fmt.Sprintf("pod with label app=%s,kudev-hash=%s", appName, hash)
+	return pd.waitFor(ctx, namespace, selector, what, timeout, func(pods []corev1.Pod) *corev1.Pod {
+		return pd.choose(pods, true, nil)
+	})
+}
+
+// waitFor lists the pods matching selector until pick returns one,
+// reporting each unsuccessful attempt to the Progress callback.
+// A zero timeout uses the configured default.
+func (pd *PodDiscovery) waitFor(
+	ctx context.Context,
+	namespace, selector, what string,
+	timeout time.Duration,
+	pick func([]corev1.Pod) *corev1.Pod,
+) (*corev1.Pod, error) {
+	if timeout <= 0 {
+		timeout = pd.opts.Timeout
+	}
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	for attempt := 1; ; attempt++ {
 		pods, err := pd.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
-			LabelSelector: selector.String(),
+			LabelSelector: selector,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to list pods: %w", err)
 		}
+		if pod := pick(pods.Items); pod != nil {
+			return pod, nil
+		}
 
-		// Find a running pod
-		for i := range pods.Items {
-			pod := &pods.Items[i]
-			if pod.Status.Phase == corev1.PodRunning {
-				return pod, nil
-			}
+		progress := DiscoveryAttempt{
+			Attempt: attempt,
+			Elapsed: time.Since(start),
+			Phases:  countPhases(pods.Items),
+		}
+		if pd.opts.Progress != nil {
+			pd.opts.Progress(progress)
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("timeout waiting for %s after %s (found %s)", what, timeout, progress)
 		}
 
 		// Wait and retry
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-time.After(2 * time.Second):
+		case <-time.After(min(pd.opts.PollInterval, remaining)):
 			// Continue polling
 		}
 	}
 }
 
+// choose returns a non-terminating pod in an accepted phase that match
+// accepts (nil accepts all). With newestFirst, Ready pods win over others
+// and newer pods over older ones; otherwise the first qualifying pod is
+// returned. Returns nil when none qualifies.
+func (pd *PodDiscovery) choose(pods []corev1.Pod, newestFirst bool, match func(*corev1.Pod) bool) *corev1.Pod {
+	var best *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || !pd.acceptsPhase(pod.Status.Phase) {
+			continue
+		}
+		if match != nil && !match(pod) {
+			continue
+		}
+		if !newestFirst {
+			return pod
+		}
+		if best == nil || preferPod(pod, best) {
+			best = pod
+		}
+	}
+	return best
+}
+
+// acceptsPhase reports whether phase is one of the configured phases.
+func (pd *PodDiscovery) acceptsPhase(phase corev1.PodPhase) bool {
+	for _, p := range pd.opts.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// preferPod reports whether a is a better pick than b: Ready first,
+// then newest.
+func preferPod(a, b *corev1.Pod) bool {
+	if aReady, bReady := isPodReady(a), isPodReady(b); aReady != bReady {
+		return aReady
+	}
+	return a.CreationTimestamp.After(b.CreationTimestamp.Time)
+}
+
+// countPhases counts pods by phase for progress reports.
+func countPhases(pods []corev1.Pod) map[string]int {
+	counts := make(map[string]int)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			counts["Terminating"]++
+			continue
+		}
+		phase := string(pod.Status.Phase)
+		if phase == "" {
+			phase = "Unknown"
+		}
+		counts[phase]++
+	}
+	return counts
+}
+
+// RunningPods lists the running, non-terminating pods kudev deployed for
+// appName that belong to the current rollout, sorted by name.
+func (pd *PodDiscovery) RunningPods(ctx context.Context, appName, namespace string) ([]corev1.Pod, error) {
+	pods, err := pd.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: podSelector(appName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	hashes := pd.currentHashes(ctx, appName, namespace)
+	var running []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil && matchesHash(&pod, hashes) {
+			running = append(running, pod)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].Name < running[j].Name })
+	return running, nil
+}
+
 // WaitForPodReady waits for a specific pod to be ready.
 func (pd *PodDiscovery) WaitForPodReady(ctx context.Context, name, namespace string, timeout time.Duration) error {
 	deadline := time.Now().Add(timeout)
@@ -79,12 +314,88 @@ func (pd *PodDiscovery) WaitForPodReady(ctx context.Context, name, namespace str
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(2 * time.Second):
+		case <-time.After(pd.opts.PollInterval):
+			// Continue polling
+		}
+	}
+}
+
+// WaitForPodStarted waits for a pending pod to leave the Pending phase,
+// i.e. for its containers to start. A zero timeout uses the configured
+// default.
+func (pd *PodDiscovery) WaitForPodStarted(ctx context.Context, name, namespace string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = pd.opts.Timeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		pod, err := pd.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod: %w", err)
+		}
+		if pod.Status.Phase != corev1.PodPending {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for pod %s to start", name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pd.opts.PollInterval):
 			// Continue polling
 		}
 	}
 }
 
+// podSelector matches the pods kudev deployed for appName. Requiring
+// managed-by=kudev keeps logs and port-forwards off unrelated pods that
+// happen to share the app label.
+func podSelector(appName string) string {
+	return labels.SelectorFromSet(labels.Set{
+		"app":        appName,
+		"managed-by": "kudev",
+	}).String()
+}
+
+// currentHashes returns the kudev-hash of the app's scaled-up Deployments
+// (both slots during a blue/green switch). Returns nil when unknown, e.g.
+// without permission to read Deployments; pods are then not filtered by hash.
+func (pd *PodDiscovery) currentHashes(ctx context.Context, appName, namespace string) map[string]bool {
+	deployments, err := pd.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: podSelector(appName),
+	})
+	if err != nil {
+		return nil
+	}
+
+	var hashes map[string]bool
+	for _, d := range deployments.Items {
+		if d.Spec.Replicas != nil && *d.Spec.Replicas == 0 {
+			continue // Retired blue/green slot
+		}
+		if h := d.Labels["kudev-hash"]; h != "" {
+			if hashes == nil {
+				hashes = make(map[string]bool)
+			}
+			hashes[h] = true
+		}
+	}
+	return hashes
+}
+
+// matchesHash reports whether pod belongs to one of the current rollouts.
+// A nil set matches every pod.
+func matchesHash(pod *corev1.Pod, hashes map[string]bool) bool {
+	if hashes == nil {
+		return true
+	}
+	return hashes[pod.Labels["kudev-hash"]]
+}
+
 func isPodReady(pod *corev1.Pod) bool {
 	for _, condition := range pod.Status.Conditions {
 		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
@@ -0,0 +1,167 @@
+// pkg/logs/handle.go
+
+package logs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRetryDelay is the pause before a TailHandle reconnects after its
+// stream ended.
+const DefaultRetryDelay = 2 * time.Second
+
+// TailHandle controls log streaming started with Start. The stream runs
+// in the background, reconnecting (and rediscovering the pod) whenever it
+// ends, until Stop is called or the context is cancelled.
+type TailHandle struct {
+	tailer    *KubernetesLogTailer
+	appName   string
+	namespace string
+
+	switches chan target
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Start streams logs of appName in the background and returns its handle.
+func (lt *KubernetesLogTailer) Start(ctx context.Context, appName, namespace string) *TailHandle {
+	h := &TailHandle{
+		tailer:    lt,
+		appName:   appName,
+		namespace: namespace,
+		switches:  make(chan target, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go h.run(ctx)
+	return h
+}
+
+// target is what a TailHandle streams next. The zero value is the app's
+// current pod.
+type target struct {
+	// pod streams this pod
+	pod string
+
+	// hash streams a pod of the rollout with this kudev-hash
+	hash string
+}
+
+// SwitchPod ends the current stream and continues with podName, showing
+// its backlog. An empty podName rediscovers the app's current pod.
+// Requests made before the previous one was handled replace it.
+func (h *TailHandle) SwitchPod(podName string) {
+	h.request(target{pod: podName})
+}
+
+// SwitchRollout ends the current stream and continues with a running pod
+// of the rollout labeled kudev-hash=hash, once there is one. Used after a
+// redeploy so the old, terminating pod is not tailed until it dies.
+func (h *TailHandle) SwitchRollout(hash string) {
+	h.request(target{hash: hash})
+}
+
+// Restart ends the current stream and rediscovers the app's current pod,
+// e.g. after a redeploy.
+func (h *TailHandle) Restart() {
+	h.request(target{})
+}
+
+func (h *TailHandle) request(t target) {
+	select {
+	case <-h.switches:
+	default:
+	}
+	select {
+	case h.switches <- t:
+	default:
+	}
+}
+
+// Stop ends streaming and waits for it to finish.
+// Safe to call more than once.
+func (h *TailHandle) Stop() {
+	h.stopOnce.Do(func() { close(h.stop) })
+	<-h.done
+}
+
+// Done is closed once streaming has finished.
+func (h *TailHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// run owns the stream: one at a time, replaced on switch requests and
+// reconnected when it ends.
+func (h *TailHandle) run(ctx context.Context) {
+	defer close(h.done)
+
+	var next target
+	switched := false
+	for {
+		streamCtx, cancel := context.WithCancel(ctx)
+		ended := make(chan error, 1)
+		go func(t target, announce bool) {
+			ended <- h.tailer.tail(streamCtx, h.appName, h.namespace, t, announce)
+		}(next, switched)
+
+		var err error
+		select {
+		case <-ctx.Done():
+			cancel()
+			<-ended
+			return
+
+		case <-h.stop:
+			cancel()
+			<-ended
+			return
+
+		case next = <-h.switches:
+			cancel()
+			<-ended
+			h.tailer.resetPosition()
+			switched = true
+			continue
+
+		case err = <-ended:
+			cancel()
+		}
+
+		// The pod went away or the stream failed: find the current pod
+		if err != nil {
+			h.tailer.logger.Info("log stream ended, reconnecting...",
+				"error", err,
+			)
+		}
+		next, switched = target{}, false
+
+		requested, ok, stopped := h.wait(ctx)
+		if stopped {
+			return
+		}
+		if ok {
+			h.tailer.resetPosition()
+			next, switched = requested, true
+		}
+	}
+}
+
+// wait sleeps retryDelay, returning early on a switch request or stop.
+func (h *TailHandle) wait(ctx context.Context) (next target, switched, stopped bool) {
+	timer := time.NewTimer(h.tailer.retryDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return target{}, false, true
+	case <-h.stop:
+		return target{}, false, true
+	case next := <-h.switches:
+		return next, true, false
+	case <-timer.C:
+		return target{}, false, false
+	}
+}
@@ -0,0 +1,168 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// syncBuffer is a bytes.Buffer safe for the stream goroutine and the test.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) count(s string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Count(b.buf.String(), s)
+}
+
+func newHandleTestTailer(out *syncBuffer) *KubernetesLogTailer {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp-abc123",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "myapp", "managed-by": "kudev"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(pod), logging.NopLogger{}, out)
+	lt.retryDelay = 10 * time.Millisecond
+	return lt
+}
+
+// waitForCount polls until out holds s at least n times.
+func waitForCount(t *testing.T, out *syncBuffer, s string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for out.count(s) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%q printed %d times, want >= %d", s, out.count(s), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTailHandle_ReconnectsAndStops(t *testing.T) {
+	out := &syncBuffer{}
+	h := newHandleTestTailer(out).Start(context.Background(), "myapp", "default")
+
+	// The fake log stream ends right away; the handle keeps reconnecting
+	waitForCount(t, out, "fake logs", 2)
+
+	h.Stop()
+	h.Stop() // idempotent
+
+	select {
+	case <-h.Done():
+	default:
+		t.Error("Done not closed after Stop")
+	}
+
+	printed := out.count("fake logs")
+	time.Sleep(50 * time.Millisecond)
+	if out.count("fake logs") != printed {
+		t.Error("streaming continued after Stop")
+	}
+}
+
+func TestTailHandle_SwitchPod(t *testing.T) {
+	out := &syncBuffer{}
+	h := newHandleTestTailer(out).Start(context.Background(), "myapp", "default")
+	defer h.Stop()
+
+	waitForCount(t, out, "fake logs", 1)
+	before := out.count("fake logs")
+
+	h.SwitchPod("myapp-def456")
+	waitForCount(t, out, "fake logs", before+1)
+
+	h.Restart()
+	waitForCount(t, out, "fake logs", before+2)
+}
+
+func TestTailHandle_ContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := newHandleTestTailer(&syncBuffer{}).Start(ctx, "myapp", "default")
+
+	cancel()
+	select {
+	case <-h.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("handle did not finish after context cancel")
+	}
+}
+
+func TestTailLogsWithRetry_ReturnsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := newHandleTestTailer(&syncBuffer{}).TailLogsWithRetry(ctx, "myapp", "default")
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestTailHandle_SwitchRollout(t *testing.T) {
+	oldPod := kudevPod("myapp-old", corev1.PodRunning)
+	oldPod.Labels["kudev-hash"] = "aaa"
+	newPod := kudevPod("myapp-new", corev1.PodRunning)
+	newPod.Labels["kudev-hash"] = "bbb"
+
+	out := &syncBuffer{}
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(oldPod, newPod), logging.NopLogger{}, out)
+	lt.retryDelay = 10 * time.Millisecond
+
+	h := lt.Start(context.Background(), "myapp", "default")
+	defer h.Stop()
+	waitForCount(t, out, "fake logs", 1)
+
+	h.SwitchRollout("bbb")
+	waitForCount(t, out, "──── logs now from pod myapp-new (kudev-hash bbb) ────\n", 1)
+
+	h.SwitchPod("myapp-old")
+	waitForCount(t, out, "──── logs now from pod myapp-old ────\n", 1)
+}
+
+func TestDiscoverRolloutPod(t *testing.T) {
+	oldPod := kudevPod("myapp-old", corev1.PodRunning)
+	oldPod.Labels["kudev-hash"] = "aaa"
+	newPod := kudevPod("myapp-new", corev1.PodRunning)
+	newPod.Labels["kudev-hash"] = "bbb"
+	terminating := kudevPod("myapp-new-gone", corev1.PodRunning)
+	terminating.Labels["kudev-hash"] = "bbb"
+	now := metav1.Now()
+	terminating.DeletionTimestamp = &now
+
+	discovery := NewPodDiscovery(fake.NewSimpleClientset(oldPod, newPod, terminating))
+
+	pod, err := discovery.DiscoverRolloutPod(context.Background(), "myapp", "default", "bbb", time.Second)
+	if err != nil {
+		t.Fatalf("DiscoverRolloutPod failed: %v", err)
+	}
+	if pod.Name != "myapp-new" {
+		t.Errorf("pod = %s, want myapp-new", pod.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := discovery.DiscoverRolloutPod(ctx, "myapp", "default", "ccc", time.Minute); err == nil {
+		t.Error("expected timeout for a rollout without pods")
+	}
+}
@@ -0,0 +1,166 @@
+// pkg/logs/multi.go
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podColors are the ANSI colors pod name prefixes cycle through
+// (red is left out; it reads as an error).
+var podColors = []string{
+	"\033[36m", // Cyan
+	"\033[33m", // Yellow
+	"\033[32m", // Green
+	"\033[35m", // Magenta
+	"\033[34m", // Blue
+}
+
+// TailAllPods streams the logs of every pod of the app at once, prefixing
+// each line with its pod name, and picks up new pods as they start.
+// With SetAllContainers, every container of each pod is streamed and
+// prefixed with pod/container. Blocks until ctx is cancelled.
+func (lt *KubernetesLogTailer) TailAllPods(ctx context.Context, appName, namespace string) error {
+	lt.logger.Info("streaming logs of all pods",
+		"app", appName,
+		"namespace", namespace,
+		"allContainers", lt.allContainers,
+	)
+
+	var (
+		mu      sync.Mutex // serializes lines of concurrent streams
+		wg      sync.WaitGroup
+		streams = make(map[string]*podStream)
+		active  = make(map[string]bool)
+		ended   = make(chan string)
+	)
+	defer wg.Wait()
+
+	ticker := time.NewTicker(lt.retryDelay)
+	defer ticker.Stop()
+
+	for {
+		pods, err := lt.discovery.RunningPods(ctx, appName, namespace)
+		if err != nil && ctx.Err() == nil {
+			lt.logger.Debug("failed to list pods", "error", err)
+		}
+
+		for _, pod := range pods {
+			for _, container := range lt.streamedContainers(pod) {
+				key := streamLabel(pod.Name, container)
+				if active[key] {
+					continue
+				}
+				ps, ok := streams[key]
+				if !ok {
+					// A stream keeps its tailer across reconnects, so
+					// resumed streams skip the lines already printed
+					ps = lt.newPodStream(pod.Name, container, len(streams), &mu)
+					streams[key] = ps
+				}
+
+				active[key] = true
+				wg.Add(1)
+				go func(ps *podStream) {
+					defer wg.Done()
+					if err := ps.tailer.streamLogs(ctx, ps.pod, namespace); err != nil && ctx.Err() == nil {
+						lt.logger.Debug("pod log stream ended", "stream", ps.name, "error", err)
+					}
+					select {
+					case ended <- ps.name:
+					case <-ctx.Done():
+					}
+				}(ps)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case name := <-ended:
+			// Picked up again on the next poll if the pod is still running
+			delete(active, name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// streamedContainers returns the containers of pod TailAllPods streams:
+// all of them with allContainers, else only the configured one ("" being
+// the pod's only or default container).
+func (lt *KubernetesLogTailer) streamedContainers(pod corev1.Pod) []string {
+	if !lt.allContainers {
+		return []string{lt.container}
+	}
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// streamLabel is the prefix label of a stream: the pod name, plus the
+// container name when each container is streamed separately.
+func streamLabel(podName, container string) string {
+	if container == "" {
+		return podName
+	}
+	return podName + "/" + container
+}
+
+// podStream is the log stream of one pod (or one container of a pod)
+// in TailAllPods.
+type podStream struct {
+	name   string
+	pod    string
+	tailer *KubernetesLogTailer
+}
+
+// newPodStream creates a stream whose lines are prefixed with the pod name
+// (and the container name, if given) in the index-th color.
+func (lt *KubernetesLogTailer) newPodStream(podName, container string, index int, mu *sync.Mutex) *podStream {
+	label := podName
+	if lt.allContainers {
+		label = streamLabel(podName, container)
+	}
+	prefix := fmt.Sprintf("[%s] ", label)
+	if lt.color {
+		prefix = podColors[index%len(podColors)] + "[" + label + "]\033[0m "
+	}
+
+	tailer := *lt
+	tailer.lastSeen = time.Time{}
+	tailer.container = container
+	tailer.output = &prefixWriter{prefix: prefix, mu: mu, out: lt.output}
+	return &podStream{name: streamLabel(podName, container), pod: podName, tailer: &tailer}
+}
+
+// prefixWriter prefixes each write (one log line) and serializes writes
+// of all pods to the shared output.
+type prefixWriter struct {
+	prefix string
+	mu     *sync.Mutex
+	out    io.Writer
+}
+
+// NewPrefixWriter returns a writer prefixing each write (one log line)
+// with prefix. Writers sharing mu never interleave their lines, e.g. the
+// tailers of several services writing to one terminal.
+func NewPrefixWriter(out io.Writer, prefix string, mu *sync.Mutex) io.Writer {
+	return &prefixWriter{prefix: prefix, mu: mu, out: out}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := io.WriteString(w.out, w.prefix); err != nil {
+		return 0, err
+	}
+	return w.out.Write(p)
+}
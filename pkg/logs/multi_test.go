@@ -0,0 +1,163 @@
+package logs
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+func kudevPod(name string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "myapp", "managed-by": "kudev"},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestRunningPods(t *testing.T) {
+	terminating := kudevPod("myapp-old", corev1.PodRunning)
+	now := metav1.Now()
+	terminating.DeletionTimestamp = &now
+
+	client := fake.NewSimpleClientset(
+		kudevPod("myapp-b", corev1.PodRunning),
+		kudevPod("myapp-a", corev1.PodRunning),
+		kudevPod("myapp-pending", corev1.PodPending),
+		terminating,
+	)
+
+	pods, err := NewPodDiscovery(client).RunningPods(context.Background(), "myapp", "default")
+	if err != nil {
+		t.Fatalf("RunningPods failed: %v", err)
+	}
+
+	var names []string
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "myapp-a,myapp-b" {
+		t.Errorf("pods = %v, want [myapp-a myapp-b]", names)
+	}
+}
+
+func TestTailAllPods(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		kudevPod("myapp-a", corev1.PodRunning),
+		kudevPod("myapp-b", corev1.PodRunning),
+	)
+
+	out := &syncBuffer{}
+	lt := NewKubernetesLogTailer(client, logging.NopLogger{}, out)
+	lt.retryDelay = 10 * time.Millisecond
+	lt.SetColor(false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- lt.TailAllPods(ctx, "myapp", "default") }()
+
+	waitForCount(t, out, "[myapp-a] fake logs\n", 1)
+	waitForCount(t, out, "[myapp-b] fake logs\n", 1)
+
+	// Scale-up: new pods are picked up
+	if _, err := client.CoreV1().Pods("default").Create(ctx, kudevPod("myapp-c", corev1.PodRunning), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForCount(t, out, "[myapp-c] fake logs\n", 1)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("err = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("TailAllPods did not return after cancel")
+	}
+}
+
+func TestNewPodStream_ColorPrefix(t *testing.T) {
+	out := &syncBuffer{}
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(), logging.NopLogger{}, out)
+
+	ps := lt.newPodStream("myapp-a", "", 1, new(sync.Mutex))
+	ps.tailer.writeLine("hello", time.Time{})
+
+	if out.count(podColors[1]+"[myapp-a]\033[0m hello\n") != 1 {
+		t.Errorf("unexpected output: %q", out.buf.String())
+	}
+}
+
+func TestTailAllPods_AllContainers(t *testing.T) {
+	pod := kudevPod("myapp-a", corev1.PodRunning)
+	pod.Spec.Containers = []corev1.Container{{Name: "app"}, {Name: "proxy"}}
+
+	out := &syncBuffer{}
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(pod), logging.NopLogger{}, out)
+	lt.retryDelay = 10 * time.Millisecond
+	lt.SetColor(false)
+	lt.SetAllContainers(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- lt.TailAllPods(ctx, "myapp", "default") }()
+
+	waitForCount(t, out, "[myapp-a/app] fake logs\n", 1)
+	waitForCount(t, out, "[myapp-a/proxy] fake logs\n", 1)
+
+	cancel()
+	<-done
+}
+
+func TestNewPodStream_Container(t *testing.T) {
+	tests := []struct {
+		name          string
+		allContainers bool
+		wantPrefix    string
+	}{
+		{name: "selected container", wantPrefix: "[myapp-a] "},
+		{name: "all containers", allContainers: true, wantPrefix: "[myapp-a/proxy] "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &syncBuffer{}
+			lt := NewKubernetesLogTailer(fake.NewSimpleClientset(), logging.NopLogger{}, out)
+			lt.SetColor(false)
+			lt.SetAllContainers(tt.allContainers)
+
+			ps := lt.newPodStream("myapp-a", "proxy", 0, new(sync.Mutex))
+			if ps.tailer.logOptions().Container != "proxy" {
+				t.Errorf("container = %q, want proxy", ps.tailer.logOptions().Container)
+			}
+			ps.tailer.writeLine("hello", time.Time{})
+			if out.count(tt.wantPrefix+"hello\n") != 1 {
+				t.Errorf("unexpected output: %q", out.buf.String())
+			}
+		})
+	}
+}
+
+func TestNewPrefixWriter(t *testing.T) {
+	out := &syncBuffer{}
+	var mu sync.Mutex
+	api := NewKubernetesLogTailer(fake.NewSimpleClientset(), logging.NopLogger{}, NewPrefixWriter(out, "[api] ", &mu))
+	worker := NewKubernetesLogTailer(fake.NewSimpleClientset(), logging.NopLogger{}, NewPrefixWriter(out, "[worker] ", &mu))
+
+	api.writeLine("hello", time.Time{})
+	worker.writeLine("hi", time.Time{})
+
+	if got, want := out.buf.String(), "[api] hello\n[worker] hi\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -7,9 +7,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
+	"regexp"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/nanaki-93/kudev/pkg/logging"
@@ -21,12 +25,41 @@ type LogTailer interface {
 	TailLogs(ctx context.Context, appName, namespace string) error
 }
 
+// DefaultTailLines is how many existing lines are shown when tailing starts.
+const DefaultTailLines int64 = 100
+
 // KubernetesLogTailer implements LogTailer using client-go.
 type KubernetesLogTailer struct {
 	clientset kubernetes.Interface
 	discovery *PodDiscovery
 	logger    logging.LoggerInterface
 	output    io.Writer
+
+	// tailLines is the initial backlog size (negative: whole log)
+	tailLines int64
+
+	// since limits the initial backlog to lines this recent (0: no limit)
+	since time.Duration
+
+	// container selects the container of multi-container pods
+	container string
+
+	// allContainers makes TailAllPods stream every container of each pod
+	allContainers bool
+
+	// filter drops lines whose message doesn't match (nil: keep all)
+	filter *regexp.Regexp
+
+	// retryDelay is the pause before a TailHandle reconnects, and
+	// between pod scans of TailAllPods
+	retryDelay time.Duration
+
+	// color enables colored pod name prefixes in TailAllPods
+	color bool
+
+	// lastSeen is the timestamp of the last printed line;
+	// reconnects resume after it instead of replaying the backlog
+	lastSeen time.Time
 }
 
 // NewKubernetesLogTailer creates a new log tailer.
@@ -35,42 +68,155 @@ func NewKubernetesLogTailer(
 	logger logging.LoggerInterface,
 	output io.Writer,
 ) *KubernetesLogTailer {
-	return &KubernetesLogTailer{
-		clientset: clientset,
-		discovery: NewPodDiscovery(clientset),
-		logger:    logger,
-		output:    output,
+	lt := &KubernetesLogTailer{
+		clientset:  clientset,
+		discovery:  NewPodDiscovery(clientset),
+		logger:     logging.OrDefault(logger),
+		output:     output,
+		tailLines:  DefaultTailLines,
+		retryDelay: DefaultRetryDelay,
+		color:      true,
 	}
+	lt.SetDiscoveryOptions(DiscoveryOptions{NewestFirst: true})
+	return lt
+}
+
+// SetDiscoveryOptions configures how the pod to stream is found.
+// Without a Progress callback, each unsuccessful attempt is logged.
+func (lt *KubernetesLogTailer) SetDiscoveryOptions(opts DiscoveryOptions) {
+	if opts.Progress == nil {
+		opts.Progress = lt.logProgress
+	}
+	lt.discovery.SetOptions(opts)
+}
+
+// logProgress reports a discovery attempt that found no pod.
+func (lt *KubernetesLogTailer) logProgress(a DiscoveryAttempt) {
+	lt.logger.Info("still waiting for pod...",
+		"attempt", a.Attempt,
+		"elapsed", a.Elapsed.Round(time.Second).String(),
+		"found", a.String(),
+	)
+}
+
+// SetTailLines sets how many existing lines are shown when tailing starts.
+// A negative value shows the whole log.
+func (lt *KubernetesLogTailer) SetTailLines(n int64) {
+	lt.tailLines = n
+}
+
+// SetSince limits the initial backlog to lines newer than d.
+// Zero shows the backlog regardless of age.
+func (lt *KubernetesLogTailer) SetSince(d time.Duration) {
+	lt.since = d
+}
+
+// SetContainer selects the container to stream; empty uses the pod's
+// only (or default) container.
+func (lt *KubernetesLogTailer) SetContainer(name string) {
+	lt.container = name
+}
+
+// SetAllContainers makes TailAllPods stream every container of each pod,
+// prefixing lines with pod/container. Overrides SetContainer there.
+func (lt *KubernetesLogTailer) SetAllContainers(enabled bool) {
+	lt.allContainers = enabled
+}
+
+// SetFilter only prints lines whose message (without the timestamp)
+// matches re. Nil prints every line.
+func (lt *KubernetesLogTailer) SetFilter(re *regexp.Regexp) {
+	lt.filter = re
+}
+
+// SetColor enables or disables colored pod name prefixes.
+func (lt *KubernetesLogTailer) SetColor(enabled bool) {
+	lt.color = enabled
 }
 
 // TailLogs streams logs from pods with the given app label.
 func (lt *KubernetesLogTailer) TailLogs(ctx context.Context, appName, namespace string) error {
-	lt.logger.Info("waiting for pods...",
-		"app", appName,
-		"namespace", namespace,
-	)
+	return lt.tail(ctx, appName, namespace, target{}, false)
+}
 
-	// Wait for a running pod
-	pod, err := lt.discovery.DiscoverPod(ctx, appName, namespace, 5*time.Minute)
-	if err != nil {
-		return fmt.Errorf("failed to discover pod: %w", err)
+// tail streams the pod t names, discovering it first unless t.pod is set.
+// With announce, a marker line shows where the previous pod's lines end.
+func (lt *KubernetesLogTailer) tail(ctx context.Context, appName, namespace string, t target, announce bool) error {
+	podName, hash := t.pod, t.hash
+	pending := false
+	if podName == "" {
+		lt.logger.Info("waiting for pods...",
+			"app", appName,
+			"namespace", namespace,
+			"phases", lt.discovery.opts.Phases,
+		)
+
+		// Wait for a pod in an accepted phase (default: running)
+		var pod *corev1.Pod
+		var err error
+		if hash != "" {
+			pod, err = lt.discovery.DiscoverRolloutPod(ctx, appName, namespace, hash, 0)
+		} else {
+			pod, err = lt.discovery.DiscoverPod(ctx, appName, namespace, 0)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to discover pod: %w", err)
+		}
+		podName, hash = pod.Name, pod.Labels["kudev-hash"]
+		pending = pod.Status.Phase == corev1.PodPending
+	}
+
+	if pending {
+		// Logs can only be streamed once the container started
+		lt.logger.Info("found pending pod, waiting for it to start",
+			"pod", podName,
+		)
+		if err := lt.discovery.WaitForPodStarted(ctx, podName, namespace, 0); err != nil {
+			return err
+		}
 	}
 
 	lt.logger.Info("found pod, streaming logs",
-		"pod", pod.Name,
+		"pod", podName,
 	)
 
-	return lt.streamLogs(ctx, pod.Name, namespace)
+	if announce {
+		lt.announce(podName, hash)
+	}
+
+	opts := lt.logOptions()
+	if pending {
+		// Attached before the first line: follow from container start
+		opts.TailLines, opts.SinceSeconds = nil, nil
+	}
+	return lt.stream(ctx, podName, namespace, opts)
+}
+
+// announce prints the marker between two pods' logs.
+func (lt *KubernetesLogTailer) announce(podName, hash string) {
+	if hash != "" {
+		fmt.Fprintf(lt.output, "──── logs now from pod %s (kudev-hash %s) ────\n", podName, hash)
+		return
+	}
+	fmt.Fprintf(lt.output, "──── logs now from pod %s ────\n", podName)
+}
+
+// resetPosition forgets the last printed line, so the next stream starts
+// with the backlog. Used when switching to another pod, whose lines are
+// unrelated to the previous pod's.
+func (lt *KubernetesLogTailer) resetPosition() {
+	lt.lastSeen = time.Time{}
 }
 
 // streamLogs streams logs from a specific pod.
 func (lt *KubernetesLogTailer) streamLogs(ctx context.Context, podName, namespace string) error {
-	// Configure log options
-	opts := &corev1.PodLogOptions{
-		Follow:     true,          // Stream new logs
-		TailLines:  int64Ptr(100), // Start with last 100 lines
-		Timestamps: true,          // Include timestamps
-	}
+	return lt.stream(ctx, podName, namespace, lt.logOptions())
+}
+
+// stream streams logs from a specific pod with the given options.
+func (lt *KubernetesLogTailer) stream(ctx context.Context, podName, namespace string, opts *corev1.PodLogOptions) error {
+	// Lines up to here were printed by a previous stream
+	resumeAfter := lt.lastSeen
 
 	// Get log stream
 	req := lt.clientset.CoreV1().Pods(namespace).GetLogs(podName, opts)
@@ -91,7 +237,7 @@ func (lt *KubernetesLogTailer) streamLogs(ctx context.Context, podName, namespac
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			fmt.Fprintln(lt.output, scanner.Text())
+			lt.writeLine(scanner.Text(), resumeAfter)
 		}
 	}
 
@@ -106,28 +252,66 @@ func (lt *KubernetesLogTailer) streamLogs(ctx context.Context, podName, namespac
 	return nil
 }
 
-// TailLogsWithRetry streams logs with automatic reconnection on failures.
-func (lt *KubernetesLogTailer) TailLogsWithRetry(ctx context.Context, appName, namespace string) error {
-	for {
-		err := lt.TailLogs(ctx, appName, namespace)
+// logOptions returns the options for the next log stream.
+// The first stream starts with the configured backlog; reconnects resume
+// from the last printed line.
+func (lt *KubernetesLogTailer) logOptions() *corev1.PodLogOptions {
+	opts := &corev1.PodLogOptions{
+		Container:  lt.container,
+		Follow:     true, // Stream new logs
+		Timestamps: true, // Needed to resume after reconnects
+	}
 
-		// Check if we should stop
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+	if !lt.lastSeen.IsZero() {
+		since := metav1.NewTime(lt.lastSeen)
+		opts.SinceTime = &since
+		return opts
+	}
 
-		if err != nil {
-			lt.logger.Info("log stream ended, reconnecting...",
-				"error", err,
-			)
-			time.Sleep(2 * time.Second)
-			continue
+	if lt.tailLines >= 0 {
+		opts.TailLines = int64Ptr(lt.tailLines)
+	}
+	if lt.since > 0 {
+		opts.SinceSeconds = int64Ptr(int64(math.Ceil(lt.since.Seconds())))
+	}
+	return opts
+}
+
+// writeLine prints a log line unless it was already printed before
+// resumeAfter or doesn't match the filter. SinceTime only has second
+// precision, so a resumed stream repeats the lines of the last second.
+func (lt *KubernetesLogTailer) writeLine(line string, resumeAfter time.Time) {
+	message := line
+	if ts, ok := parseLogTimestamp(line); ok {
+		if !resumeAfter.IsZero() && !ts.After(resumeAfter) {
+			return
 		}
+		lt.lastSeen = ts
+		_, message, _ = strings.Cut(line, " ")
+	}
+	if lt.filter != nil && !lt.filter.MatchString(message) {
+		return
+	}
+	fmt.Fprintln(lt.output, line)
+}
 
-		return nil
+// parseLogTimestamp reads the RFC3339 timestamp the API server prefixes
+// each line with when Timestamps is set.
+func parseLogTimestamp(line string) (time.Time, bool) {
+	prefix, _, _ := strings.Cut(line, " ")
+	ts, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, false
 	}
+	return ts, true
+}
+
+// TailLogsWithRetry streams logs with automatic reconnection on failures.
+// Blocks until ctx is cancelled; use Start for a stream that can be
+// stopped or switched to another pod.
+func (lt *KubernetesLogTailer) TailLogsWithRetry(ctx context.Context, appName, namespace string) error {
+	<-lt.Start(ctx, appName, namespace).Done()
+	return ctx.Err()
 }
 
 func int64Ptr(i int64) *int64 {
@@ -1,13 +1,20 @@
 package logs
 
 import (
+	"bytes"
 	"context"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
 )
 
 func TestDiscoverPod_Found(t *testing.T) {
@@ -15,7 +22,7 @@ func TestDiscoverPod_Found(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "myapp-abc123",
 			Namespace: "default",
-			Labels:    map[string]string{"app": "myapp"},
+			Labels:    map[string]string{"app": "myapp", "managed-by": "kudev"},
 		},
 		Status: corev1.PodStatus{
 			Phase: corev1.PodRunning,
@@ -52,6 +59,119 @@ func TestDiscoverPod_Timeout(t *testing.T) {
 	}
 }
 
+func TestDiscoverPod_OnlyCurrentKudevPods(t *testing.T) {
+	running := corev1.PodStatus{Phase: corev1.PodRunning}
+	replicas := int32(1)
+
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default",
+				Labels: map[string]string{"app": "myapp", "managed-by": "kudev", "kudev-hash": "newhash"}},
+			Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+		},
+		&corev1.Pod{
+			// Someone else's pod reusing the app label
+			ObjectMeta: metav1.ObjectMeta{Name: "foreign", Namespace: "default",
+				Labels: map[string]string{"app": "myapp"}},
+			Status: running,
+		},
+		&corev1.Pod{
+			// Leftover from the previous rollout
+			ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "default",
+				Labels: map[string]string{"app": "myapp", "managed-by": "kudev", "kudev-hash": "oldhash"}},
+			Status: running,
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "current", Namespace: "default",
+				Labels: map[string]string{"app": "myapp", "managed-by": "kudev", "kudev-hash": "newhash"}},
+			Status: running,
+		},
+	}
+
+	discovery := NewPodDiscovery(fake.NewSimpleClientset(objects...))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	foundPod, err := discovery.DiscoverPod(ctx, "myapp", "default", 10*time.Second)
+	if err != nil {
+		t.Fatalf("DiscoverPod failed: %v", err)
+	}
+	if foundPod.Name != "current" {
+		t.Errorf("found pod %q, want %q", foundPod.Name, "current")
+	}
+}
+
+func TestDiscoverReadyPod_PrefersNewestReady(t *testing.T) {
+	now := time.Now()
+	readyCond := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	deleting := metav1.NewTime(now)
+
+	pods := []*corev1.Pod{
+		{
+			// Old pod still ready
+			ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default",
+				Labels: map[string]string{"app": "myapp", "managed-by": "kudev"}, CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: readyCond},
+		},
+		{
+			// New pod ready
+			ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "default",
+				Labels: map[string]string{"app": "myapp", "managed-by": "kudev"}, CreationTimestamp: metav1.NewTime(now.Add(-time.Minute))},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: readyCond},
+		},
+		{
+			// Newest pod still starting
+			ObjectMeta: metav1.ObjectMeta{Name: "starting", Namespace: "default",
+				Labels: map[string]string{"app": "myapp", "managed-by": "kudev"}, CreationTimestamp: metav1.NewTime(now)},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			// Terminating pod is never chosen
+			ObjectMeta: metav1.ObjectMeta{Name: "terminating", Namespace: "default",
+				Labels: map[string]string{"app": "myapp", "managed-by": "kudev"}, CreationTimestamp: metav1.NewTime(now),
+				DeletionTimestamp: &deleting, Finalizers: []string{"test"}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: readyCond},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(pods[0], pods[1], pods[2], pods[3])
+	discovery := NewPodDiscovery(fakeClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	foundPod, err := discovery.DiscoverReadyPod(ctx, "myapp", "default", 10*time.Second)
+	if err != nil {
+		t.Fatalf("DiscoverReadyPod failed: %v", err)
+	}
+
+	if foundPod.Name != "new" {
+		t.Errorf("found pod %q, want %q", foundPod.Name, "new")
+	}
+}
+
+func TestDiscoverReadyPod_NotReadyTimesOut(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myapp-abc123",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "myapp", "managed-by": "kudev"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	fakeClient := fake.NewSimpleClientset(pod)
+	discovery := NewPodDiscovery(fakeClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := discovery.DiscoverReadyPod(ctx, "myapp", "default", 100*time.Millisecond); err == nil {
+		t.Error("expected error for pod that is running but not ready")
+	}
+}
+
 func TestIsPodReady(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -98,3 +218,237 @@ func TestIsPodReady(t *testing.T) {
 		})
 	}
 }
+
+func TestLogOptions(t *testing.T) {
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(), &util.MockLogger{}, &bytes.Buffer{})
+
+	opts := lt.logOptions()
+	if opts.TailLines == nil || *opts.TailLines != DefaultTailLines {
+		t.Errorf("initial TailLines = %v, want %d", opts.TailLines, DefaultTailLines)
+	}
+	if opts.SinceTime != nil {
+		t.Error("initial stream should not set SinceTime")
+	}
+
+	lt.SetTailLines(-1)
+	if opts := lt.logOptions(); opts.TailLines != nil {
+		t.Errorf("TailLines = %d, want nil (whole log)", *opts.TailLines)
+	}
+
+	// After printing a line, reconnects resume from it
+	lt.writeLine("2024-01-15T10:00:05.123456789Z started", time.Time{})
+	opts = lt.logOptions()
+	if opts.TailLines != nil {
+		t.Error("reconnect should not replay the backlog")
+	}
+	if opts.SinceTime == nil || opts.SinceTime.Unix() != time.Date(2024, 1, 15, 10, 0, 5, 0, time.UTC).Unix() {
+		t.Errorf("SinceTime = %v, want 2024-01-15T10:00:05Z", opts.SinceTime)
+	}
+}
+
+func TestWriteLine_DedupesAfterReconnect(t *testing.T) {
+	var out bytes.Buffer
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(), &util.MockLogger{}, &out)
+
+	// First stream
+	lt.writeLine("2024-01-15T10:00:05.100Z a", time.Time{})
+	lt.writeLine("2024-01-15T10:00:05.200Z b", time.Time{})
+
+	// Reconnect: SinceTime=10:00:05 replays a and b
+	resumeAfter := lt.lastSeen
+	lt.writeLine("2024-01-15T10:00:05.100Z a", resumeAfter)
+	lt.writeLine("2024-01-15T10:00:05.200Z b", resumeAfter)
+	lt.writeLine("2024-01-15T10:00:05.300Z c", resumeAfter)
+	lt.writeLine("2024-01-15T10:00:05.300Z c2", resumeAfter)
+	lt.writeLine("no timestamp", resumeAfter)
+
+	want := "2024-01-15T10:00:05.100Z a\n" +
+		"2024-01-15T10:00:05.200Z b\n" +
+		"2024-01-15T10:00:05.300Z c\n" +
+		"2024-01-15T10:00:05.300Z c2\n" +
+		"no timestamp\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestLogOptions_SinceAndContainer(t *testing.T) {
+	tests := []struct {
+		name      string
+		since     time.Duration
+		container string
+		wantSince int64 // 0: unset
+	}{
+		{name: "defaults"},
+		{name: "since minutes", since: 10 * time.Minute, wantSince: 600},
+		{name: "sub-second rounds up", since: 300 * time.Millisecond, wantSince: 1},
+		{name: "container", container: "sidecar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lt := NewKubernetesLogTailer(fake.NewSimpleClientset(), &util.MockLogger{}, &bytes.Buffer{})
+			lt.SetSince(tt.since)
+			lt.SetContainer(tt.container)
+
+			opts := lt.logOptions()
+			if opts.Container != tt.container {
+				t.Errorf("Container = %q, want %q", opts.Container, tt.container)
+			}
+			switch {
+			case tt.wantSince == 0 && opts.SinceSeconds != nil:
+				t.Errorf("SinceSeconds = %d, want unset", *opts.SinceSeconds)
+			case tt.wantSince != 0 && (opts.SinceSeconds == nil || *opts.SinceSeconds != tt.wantSince):
+				t.Errorf("SinceSeconds = %v, want %d", opts.SinceSeconds, tt.wantSince)
+			}
+		})
+	}
+
+	// Reconnects resume from the last line instead
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(), &util.MockLogger{}, &bytes.Buffer{})
+	lt.SetSince(time.Hour)
+	lt.writeLine("2024-01-15T10:00:05Z started", time.Time{})
+	if opts := lt.logOptions(); opts.SinceSeconds != nil || opts.SinceTime == nil {
+		t.Errorf("reconnect options = %+v, want SinceTime only", opts)
+	}
+}
+
+func TestWriteLine_Filter(t *testing.T) {
+	var out bytes.Buffer
+	lt := NewKubernetesLogTailer(fake.NewSimpleClientset(), &util.MockLogger{}, &out)
+	lt.SetFilter(regexp.MustCompile(`^(ERROR|WARN)`))
+
+	lt.writeLine("2024-01-15T10:00:05.100Z INFO started", time.Time{})
+	lt.writeLine("2024-01-15T10:00:05.200Z ERROR boom", time.Time{})
+	lt.writeLine("WARN no timestamp", time.Time{})
+	lt.writeLine("2024-01-15T10:00:05.300Z debug ERROR inside", time.Time{})
+
+	want := "2024-01-15T10:00:05.200Z ERROR boom\n" +
+		"WARN no timestamp\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// Filtered lines still move the resume position
+	if got := lt.lastSeen.Format(time.RFC3339Nano); got != "2024-01-15T10:00:05.3Z" {
+		t.Errorf("lastSeen = %s, want the last line's timestamp", got)
+	}
+}
+
+func TestDiscoverPod_Options(t *testing.T) {
+	now := time.Now()
+	readyCond := []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+
+	oldReady := kudevPod("old-ready", corev1.PodRunning)
+	oldReady.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	oldReady.Status.Conditions = readyCond
+	newReady := kudevPod("new-ready", corev1.PodRunning)
+	newReady.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+	newReady.Status.Conditions = readyCond
+	starting := kudevPod("starting", corev1.PodRunning)
+	starting.CreationTimestamp = metav1.NewTime(now)
+	pending := kudevPod("pending", corev1.PodPending)
+
+	tests := []struct {
+		name    string
+		pods    []runtime.Object
+		opts    DiscoveryOptions
+		wantPod string
+	}{
+		{name: "newest ready first", pods: []runtime.Object{oldReady, newReady, starting}, opts: DiscoveryOptions{NewestFirst: true}, wantPod: "new-ready"},
+		{name: "newest when none ready", pods: []runtime.Object{starting, kudevPod("older", corev1.PodRunning)}, opts: DiscoveryOptions{NewestFirst: true}, wantPod: "starting"},
+		{name: "pending accepted", pods: []runtime.Object{pending}, opts: DiscoveryOptions{Phases: []corev1.PodPhase{corev1.PodPending, corev1.PodRunning}}, wantPod: "pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discovery := NewPodDiscovery(fake.NewSimpleClientset(tt.pods...))
+			discovery.SetOptions(tt.opts)
+
+			pod, err := discovery.DiscoverPod(context.Background(), "myapp", "default", time.Second)
+			if err != nil {
+				t.Fatalf("DiscoverPod failed: %v", err)
+			}
+			if pod.Name != tt.wantPod {
+				t.Errorf("pod = %s, want %s", pod.Name, tt.wantPod)
+			}
+		})
+	}
+}
+
+func TestDiscoverPod_Progress(t *testing.T) {
+	discovery := NewPodDiscovery(fake.NewSimpleClientset(kudevPod("myapp-a", corev1.PodPending)))
+
+	var attempts []DiscoveryAttempt
+	discovery.SetOptions(DiscoveryOptions{
+		PollInterval: 10 * time.Millisecond,
+		Progress:     func(a DiscoveryAttempt) { attempts = append(attempts, a) },
+	})
+
+	_, err := discovery.DiscoverPod(context.Background(), "myapp", "default", 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "found 1 pod (Pending: 1)") {
+		t.Errorf("err = %v, want timeout naming the pending pod", err)
+	}
+	if len(attempts) < 2 {
+		t.Fatalf("got %d progress reports, want several", len(attempts))
+	}
+	for i, a := range attempts {
+		if a.Attempt != i+1 {
+			t.Errorf("attempts[%d].Attempt = %d, want %d", i, a.Attempt, i+1)
+		}
+	}
+}
+
+func TestDiscoveryAttempt_String(t *testing.T) {
+	tests := []struct {
+		phases map[string]int
+		want   string
+	}{
+		{phases: nil, want: "no pods"},
+		{phases: map[string]int{"Pending": 1}, want: "1 pod (Pending: 1)"},
+		{phases: map[string]int{"Running": 1, "Pending": 2}, want: "3 pods (Pending: 2, Running: 1)"},
+	}
+
+	for _, tt := range tests {
+		if got := (DiscoveryAttempt{Phases: tt.phases}).String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTailLogs_PendingPod(t *testing.T) {
+	client := fake.NewSimpleClientset(kudevPod("myapp-a", corev1.PodPending))
+
+	out := &syncBuffer{}
+	lt := NewKubernetesLogTailer(client, &util.MockLogger{}, out)
+	lt.SetDiscoveryOptions(DiscoveryOptions{
+		PollInterval: 10 * time.Millisecond,
+		Phases:       []corev1.PodPhase{corev1.PodPending, corev1.PodRunning},
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- lt.TailLogs(context.Background(), "myapp", "default") }()
+
+	// Attached while pending: nothing is streamed until the pod starts
+	time.Sleep(50 * time.Millisecond)
+	if out.count("fake logs") != 0 {
+		t.Fatal("streamed logs of a pending pod")
+	}
+
+	started := kudevPod("myapp-a", corev1.PodRunning)
+	if _, err := client.CoreV1().Pods("default").UpdateStatus(context.Background(), started, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("TailLogs failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("TailLogs did not stream after the pod started")
+	}
+	if out.count("fake logs") != 1 {
+		t.Errorf("unexpected output: %q", out.buf.String())
+	}
+}
@@ -0,0 +1,85 @@
+// Package output renders command results either as human-readable text
+// or serialized as JSON or YAML for scripts and editor extensions.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Format is an output format accepted by --output.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat parses a --output value; empty means text.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unsupported output format %q (supported: text, json, yaml)", s)
+}
+
+// Formatter writes a value to w.
+type Formatter interface {
+	Write(w io.Writer, v any) error
+}
+
+// TextFunc renders a value for humans.
+type TextFunc func(w io.Writer, v any) error
+
+// Write implements Formatter.
+func (f TextFunc) Write(w io.Writer, v any) error {
+	return f(w, v)
+}
+
+// New returns the formatter for format; text is used for FormatText.
+func New(format Format, text TextFunc) (Formatter, error) {
+	switch format {
+	case FormatText:
+		return text, nil
+	case FormatJSON:
+		return jsonFormatter{}, nil
+	case FormatYAML:
+		return yamlFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported output format %q", format)
+}
+
+// jsonFormatter writes indented JSON, one document per Write.
+type jsonFormatter struct{}
+
+func (jsonFormatter) Write(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// yamlFormatter writes YAML documents, each starting with "---" so that
+// repeated writes (e.g. status --watch) form a valid stream.
+type yamlFormatter struct{}
+
+func (yamlFormatter) Write(w io.Writer, v any) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "---\n%s", data)
+	return err
+}
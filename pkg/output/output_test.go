@@ -0,0 +1,73 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+type item struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{in: "", want: FormatText},
+		{in: "text", want: FormatText},
+		{in: "JSON", want: FormatJSON},
+		{in: "yaml", want: FormatYAML},
+		{in: "yml", want: FormatYAML},
+		{in: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseFormat(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_Write(t *testing.T) {
+	text := TextFunc(func(w io.Writer, v any) error {
+		_, err := fmt.Fprintf(w, "%s is ready\n", v.(item).Name)
+		return err
+	})
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{format: FormatText, want: "myapp is ready\n"},
+		{format: FormatJSON, want: "{\n  \"name\": \"myapp\",\n  \"ready\": true\n}\n"},
+		{format: FormatYAML, want: "---\nname: myapp\nready: true\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			f, err := New(tt.format, text)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := f.Write(&buf, item{Name: "myapp", Ready: true}); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Write() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
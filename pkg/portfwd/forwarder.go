@@ -3,16 +3,22 @@ package portfwd
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
 
+	"github.com/nanaki-93/kudev/pkg/clock"
 	"github.com/nanaki-93/kudev/pkg/logging"
 	"github.com/nanaki-93/kudev/pkg/logs"
 )
@@ -23,20 +29,131 @@ type PortForwarder interface {
 	// Returns when forwarding is established.
 	Forward(ctx context.Context, appName, namespace string, localPort, podPort int32) error
 
+	// ForwardPorts is Forward for several ports at once.
+	ForwardPorts(ctx context.Context, appName, namespace string, ports []Port) error
+
 	// Stop terminates port forwarding.
 	Stop()
 }
 
+// Port is one forwarded port.
+type Port struct {
+	// Local is the port on this machine
+	Local int32
+
+	// Pod is the port in the pod
+	Pod int32
+
+	// Protocol is TCP (the default when empty), UDP or SCTP.
+	// Only TCP can be port-forwarded; see NodePortExposer for the others.
+	Protocol corev1.Protocol
+}
+
+// String returns "local:pod", the port-forward notation, with the
+// protocol appended unless it is TCP (e.g. "5353:53/udp").
+func (p Port) String() string {
+	if !p.Forwardable() {
+		return fmt.Sprintf("%d:%d/%s", p.Local, p.Pod, strings.ToLower(string(p.Protocol)))
+	}
+	return fmt.Sprintf("%d:%d", p.Local, p.Pod)
+}
+
+// Forwardable reports whether the port can be port-forwarded (is TCP).
+func (p Port) Forwardable() bool {
+	return p.protocol() == corev1.ProtocolTCP
+}
+
+func (p Port) protocol() corev1.Protocol {
+	if p.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return corev1.Protocol(strings.ToUpper(string(p.Protocol)))
+}
+
+// Reconnect defaults of KubernetesPortForwarder.
+const (
+	// DefaultMaxReconnectAttempts is how many reconnects in a row may fail
+	// before forwarding gives up.
+	DefaultMaxReconnectAttempts = 10
+
+	// DefaultReconnectBackoff is the delay before the first reconnect;
+	// it doubles after each failed attempt.
+	DefaultReconnectBackoff = 500 * time.Millisecond
+
+	// DefaultMaxReconnectBackoff caps the delay between reconnects.
+	DefaultMaxReconnectBackoff = 30 * time.Second
+)
+
+// Stats are the reconnect metrics of a KubernetesPortForwarder.
+type Stats struct {
+	// Disconnects counts forwarding sessions that ended with an error
+	Disconnects int
+
+	// Reconnects counts successful reconnects
+	Reconnects int
+
+	// FailedAttempts counts reconnect attempts that failed
+	FailedAttempts int
+
+	// LastError is the most recent disconnect or reconnect error
+	LastError string
+
+	// LastReconnect is when forwarding was last re-established
+	LastReconnect time.Time
+
+	// GaveUp is set once MaxReconnectAttempts reconnects failed in a row
+	GaveUp bool
+}
+
+// session is one established forwarding connection.
+type session struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     <-chan error
+}
+
+// close stops the session; safe to call more than once.
+func (s *session) close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
 // KubernetesPortForwarder implements PortForwarder using client-go.
+//
+// Forward establishes the first connection and hands it to a single
+// supervisor goroutine, which reconnects (to a new ready pod if needed)
+// with jittered exponential backoff whenever the connection drops.
+// All ports of ForwardPorts share the connection and are served
+// concurrently by it.
 type KubernetesPortForwarder struct {
 	clientset  kubernetes.Interface
 	restConfig *rest.Config
 	discovery  *logs.PodDiscovery
 	logger     logging.LoggerInterface
 
-	// Internal state
-	stopChan  chan struct{}
-	readyChan chan struct{}
+	// MaxReconnectAttempts bounds consecutive failed reconnects
+	MaxReconnectAttempts int
+
+	// ReconnectBackoff and MaxReconnectBackoff shape the retry delay
+	ReconnectBackoff    time.Duration
+	MaxReconnectBackoff time.Duration
+
+	// Clock times the reconnect backoff (clock.Real by default)
+	Clock clock.Clock
+
+	// Address is the local address to listen on (e.g. 127.0.0.1, ::1 or
+	// 0.0.0.0). Empty means localhost: 127.0.0.1 and ::1.
+	Address string
+
+	// connect establishes one session (replaced in tests)
+	connect func(ctx context.Context, appName, namespace string, ports []Port) (*session, error)
+
+	mu       sync.Mutex
+	current  *session
+	stats    Stats
+	cancel   context.CancelFunc
+	stopped  chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
 }
 
 // NewKubernetesPortForwarder creates a new port forwarder.
@@ -45,131 +162,325 @@ func NewKubernetesPortForwarder(
 	restConfig *rest.Config,
 	logger logging.LoggerInterface,
 ) *KubernetesPortForwarder {
-	return &KubernetesPortForwarder{
-		clientset:  clientset,
-		restConfig: restConfig,
-		discovery:  logs.NewPodDiscovery(clientset),
-		logger:     logger,
+	pf := &KubernetesPortForwarder{
+		clientset:            clientset,
+		restConfig:           restConfig,
+		discovery:            logs.NewPodDiscovery(clientset),
+		logger:               logging.OrDefault(logger),
+		MaxReconnectAttempts: DefaultMaxReconnectAttempts,
+		ReconnectBackoff:     DefaultReconnectBackoff,
+		MaxReconnectBackoff:  DefaultMaxReconnectBackoff,
+		Clock:                clock.Real,
+		stopped:              make(chan struct{}),
 	}
+	pf.connect = pf.dial
+	return pf
 }
 
-// Forward starts port forwarding to a pod.
+// Forward starts port forwarding to a pod and keeps it up until ctx is
+// cancelled or Stop is called.
+// Returns when the first connection is established.
 func (pf *KubernetesPortForwarder) Forward(ctx context.Context, appName, namespace string, localPort, podPort int32) error {
-	// 1. Check port availability
-	if err := checkPortAvailable(localPort); err != nil {
-		return fmt.Errorf("port %d is not available: %w\n\nTry a different port with --local-port flag", localPort, err)
+	return pf.ForwardPorts(ctx, appName, namespace, []Port{{Local: localPort, Pod: podPort}})
+}
+
+// ForwardPorts forwards all ports to the same pod over one connection,
+// keeping them up until ctx is cancelled or Stop is called.
+// Returns when the first connection is established.
+func (pf *KubernetesPortForwarder) ForwardPorts(ctx context.Context, appName, namespace string, ports []Port) error {
+	if len(ports) == 0 {
+		return fmt.Errorf("no ports to forward")
+	}
+	for _, p := range ports {
+		if !p.Forwardable() {
+			return fmt.Errorf("port %s can't be port-forwarded: Kubernetes port-forward is TCP-only (expose it with a NodePort instead)", p)
+		}
+	}
+	if pf.isStopped() {
+		return fmt.Errorf("port forwarder is stopped")
+	}
+
+	pf.mu.Lock()
+	if pf.done != nil {
+		pf.mu.Unlock()
+		return fmt.Errorf("port forwarding is already running")
+	}
+	pf.mu.Unlock()
+
+	// Check port availability
+	for _, p := range ports {
+		if err := checkPortAvailable(pf.Address, p.Local); err != nil {
+			return fmt.Errorf("port %d is not available: %w\n\nTry a different port with --local-port flag", p.Local, err)
+		}
 	}
 
+	sess, err := pf.connect(ctx, appName, namespace, ports)
+	if err != nil {
+		return err
+	}
+
+	// Stop cancels ctx so a pending reconnect doesn't wait out pod discovery
+	ctx, cancel := context.WithCancel(ctx)
+
+	pf.mu.Lock()
+	pf.current = sess
+	pf.cancel = cancel
+	pf.done = make(chan struct{})
+	pf.mu.Unlock()
+
+	go pf.supervise(ctx, sess, appName, namespace, ports)
+	return nil
+}
+
+// dial waits for a ready pod and opens a forwarding session to it.
+func (pf *KubernetesPortForwarder) dial(ctx context.Context, appName, namespace string, ports []Port) (*session, error) {
 	pf.logger.Info("waiting for pod to be ready...",
 		"app", appName,
 		"namespace", namespace,
 	)
 
-	// 2. Wait for a running pod
-	pod, err := pf.discovery.DiscoverPod(ctx, appName, namespace, 5*time.Minute)
+	// Wait for a ready pod (during rolling updates this is the
+	// replacement pod once it passes readiness, never a terminating one)
+	pod, err := pf.discovery.DiscoverReadyPod(ctx, appName, namespace, 5*time.Minute)
 	if err != nil {
-		return fmt.Errorf("failed to find pod: %w", err)
+		return nil, fmt.Errorf("failed to find pod: %w", err)
 	}
 
 	pf.logger.Info("found pod",
 		"pod", pod.Name,
 	)
 
-	// 3. Create channels
-	pf.stopChan = make(chan struct{}, 1)
-	pf.readyChan = make(chan struct{})
-
-	// 4. Build port forward URL
+	// Build port forward URL
 	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", namespace, pod.Name)
 	hostURL, err := url.Parse(pf.restConfig.Host)
 	if err != nil {
-		return fmt.Errorf("failed to parse host URL: %w", err)
+		return nil, fmt.Errorf("failed to parse host URL: %w", err)
 	}
 	hostURL.Path = path
 
-	// 5. Create SPDY transport
+	// Create SPDY transport
 	transport, upgrader, err := spdy.RoundTripperFor(pf.restConfig)
 	if err != nil {
-		return fmt.Errorf("failed to create transport: %w", err)
+		return nil, fmt.Errorf("failed to create transport: %w", err)
 	}
 
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, hostURL)
 
-	// 6. Create port forwarder
-	ports := []string{fmt.Sprintf("%d:%d", localPort, podPort)}
+	// Create port forwarder
+	specs := make([]string, len(ports))
+	for i, p := range ports {
+		specs[i] = p.String()
+	}
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
 
-	// Use io.Discard for output (we'll log manually)
-	fw, err := portforward.New(dialer, ports, pf.stopChan, pf.readyChan, nil, nil)
+	fw, err := portforward.NewOnAddresses(dialer, []string{pf.listenAddress()}, specs, stopChan, readyChan, nil, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create port forwarder: %w", err)
+		return nil, fmt.Errorf("failed to create port forwarder: %w", err)
 	}
 
-	// 7. Start forwarding in goroutine
-	errChan := make(chan error, 1)
+	// Forward in the background; done receives exactly one result
+	done := make(chan error, 1)
 	go func() {
-		errChan <- fw.ForwardPorts()
+		done <- fw.ForwardPorts()
 	}()
 
-	// 8. Wait for ready or error
+	sess := &session{stop: stopChan, done: done}
+
+	// Wait for ready or error
 	select {
-	case <-pf.readyChan:
+	case <-readyChan:
 		pf.logger.Info("port forwarding ready",
-			"local", fmt.Sprintf("localhost:%d", localPort),
-			"pod", fmt.Sprintf("%s:%d", pod.Name, podPort),
+			"address", pf.listenAddress(),
+			"pod", pod.Name,
+			"ports", specs,
 		)
+		return sess, nil
 
-		// Start background monitor
-		go pf.monitor(ctx, errChan, appName, namespace, localPort, podPort)
-
-		return nil
-
-	case err := <-errChan:
-		return fmt.Errorf("port forwarding failed: %w", err)
+	case err := <-done:
+		return nil, fmt.Errorf("port forwarding failed: %w", err)
 
 	case <-ctx.Done():
-		pf.Stop()
-		return ctx.Err()
+		sess.close()
+		<-done
+		return nil, ctx.Err()
+	}
+}
+
+// listenAddress returns Address, defaulting to localhost.
+func (pf *KubernetesPortForwarder) listenAddress() string {
+	if pf.Address == "" {
+		return "localhost"
 	}
+	return pf.Address
 }
 
-// monitor watches for errors and attempts reconnection.
-func (pf *KubernetesPortForwarder) monitor(ctx context.Context, errChan chan error, appName, namespace string, localPort, podPort int32) {
+// supervise owns the active session and replaces it whenever it drops,
+// until ctx is cancelled, Stop is called or reconnecting gives up.
+func (pf *KubernetesPortForwarder) supervise(ctx context.Context, sess *session, appName, namespace string, ports []Port) {
+	defer close(pf.done)
+
 	for {
+		var err error
 		select {
 		case <-ctx.Done():
+			pf.closeCurrent()
+			return
+		case <-pf.stopped:
+			pf.closeCurrent()
+			return
+		case err = <-sess.done:
+		}
+
+		if err == nil {
+			// Closed cleanly (only happens when stopped)
 			return
+		}
 
-		case err := <-errChan:
-			if err != nil {
-				pf.logger.Info("port forward disconnected, reconnecting...",
-					"error", err,
-				)
-
-				// Wait a bit before reconnecting
-				time.Sleep(2 * time.Second)
-
-				// Try to reconnect
-				if ctx.Err() == nil {
-					if err := pf.Forward(ctx, appName, namespace, localPort, podPort); err != nil {
-						pf.logger.Error(err, "reconnection failed")
-					}
-				}
-			}
+		pf.logger.Info("port forward disconnected, reconnecting...",
+			"error", err,
+		)
+		pf.updateStats(func(st *Stats) {
+			st.Disconnects++
+			st.LastError = err.Error()
+		})
+
+		sess = pf.reconnect(ctx, appName, namespace, ports)
+		if sess == nil {
 			return
 		}
 	}
 }
 
-// Stop terminates port forwarding.
+// reconnect retries connect with jittered exponential backoff.
+// Returns nil when cancelled, stopped or out of attempts.
+func (pf *KubernetesPortForwarder) reconnect(ctx context.Context, appName, namespace string, ports []Port) *session {
+	clk := clock.OrReal(pf.Clock)
+	var lastErr error
+	for attempt := 1; attempt <= pf.MaxReconnectAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-pf.stopped:
+			return nil
+		case <-clk.After(jitter(backoff(attempt, pf.ReconnectBackoff, pf.MaxReconnectBackoff))):
+		}
+
+		sess, err := pf.connect(ctx, appName, namespace, ports)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			lastErr = err
+			pf.logger.Debug("reconnect attempt failed",
+				"attempt", attempt,
+				"error", err,
+			)
+			pf.updateStats(func(st *Stats) {
+				st.FailedAttempts++
+				st.LastError = err.Error()
+			})
+			continue
+		}
+
+		pf.mu.Lock()
+		pf.current = sess
+		pf.stats.Reconnects++
+		pf.stats.LastReconnect = clk.Now()
+		pf.mu.Unlock()
+
+		// Stop raced with the reconnect: don't leave the session running
+		if pf.isStopped() {
+			sess.close()
+			return nil
+		}
+		return sess
+	}
+
+	pf.updateStats(func(st *Stats) { st.GaveUp = true })
+	pf.logger.Error(lastErr, "port forwarding gave up",
+		"attempts", pf.MaxReconnectAttempts,
+	)
+	return nil
+}
+
+// Stop terminates port forwarding and waits for the supervisor to exit.
+// Safe to call more than once, and before Forward.
 func (pf *KubernetesPortForwarder) Stop() {
-	if pf.stopChan != nil {
-		close(pf.stopChan)
+	pf.stopOnce.Do(func() { close(pf.stopped) })
+	pf.closeCurrent()
+
+	pf.mu.Lock()
+	cancel, done := pf.cancel, pf.done
+	pf.mu.Unlock()
+	if cancel != nil {
+		cancel()
 	}
+	if done != nil {
+		<-done
+	}
+}
+
+// Stats returns a snapshot of the reconnect metrics.
+func (pf *KubernetesPortForwarder) Stats() Stats {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	return pf.stats
+}
+
+func (pf *KubernetesPortForwarder) updateStats(fn func(st *Stats)) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	fn(&pf.stats)
+}
+
+func (pf *KubernetesPortForwarder) closeCurrent() {
+	pf.mu.Lock()
+	sess := pf.current
+	pf.mu.Unlock()
+	if sess != nil {
+		sess.close()
+	}
+}
+
+func (pf *KubernetesPortForwarder) isStopped() bool {
+	select {
+	case <-pf.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns base doubled for every attempt after the first, capped at max.
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
 }
 
-// checkPortAvailable checks if a local port is available.
-func checkPortAvailable(port int32) error {
-	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// jitter spreads d over [d/2, d] so reconnects don't move in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// checkPortAvailable checks if a local port is available on address.
+// For localhost (or an empty address) the port must be free on all
+// interfaces, as before custom addresses existed.
+func checkPortAvailable(address string, port int32) error {
+	if address == "localhost" {
+		address = ""
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(int(port))))
 	if err != nil {
 		return err
 	}
@@ -177,6 +488,12 @@ func checkPortAvailable(port int32) error {
 	return nil
 }
 
+// PortAvailable reports whether a local port can be listened on at
+// address (see checkPortAvailable).
+func PortAvailable(address string, port int32) bool {
+	return checkPortAvailable(address, port) == nil
+}
+
 // SuggestAlternativePort finds an available port near the requested one.
 func SuggestAlternativePort(preferredPort int32) (int32, error) {
 	// Try ports around the preferred one
@@ -185,7 +502,7 @@ func SuggestAlternativePort(preferredPort int32) (int32, error) {
 			if p < 1024 || p > 65535 {
 				continue
 			}
-			if checkPortAvailable(p) == nil {
+			if checkPortAvailable("", p) == nil {
 				return p, nil
 			}
 		}
@@ -193,5 +510,23 @@ func SuggestAlternativePort(preferredPort int32) (int32, error) {
 	return 0, fmt.Errorf("no available ports found near %d", preferredPort)
 }
 
+// SuggestPortExcluding is SuggestAlternativePort skipping the ports in
+// taken, e.g. those already assigned to other forwards that aren't
+// listening yet.
+func SuggestPortExcluding(preferredPort int32, taken map[int32]bool) (int32, error) {
+	start := preferredPort
+	for range 10 {
+		port, err := SuggestAlternativePort(start)
+		if err != nil {
+			return 0, err
+		}
+		if !taken[port] {
+			return port, nil
+		}
+		start = port + 1
+	}
+	return 0, fmt.Errorf("no available ports found near %d", preferredPort)
+}
+
 // Ensure KubernetesPortForwarder implements PortForwarder
 var _ PortForwarder = (*KubernetesPortForwarder)(nil)
@@ -3,8 +3,15 @@
 package portfwd
 
 import (
+	"context"
+	"errors"
 	"net"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/clock"
+	"github.com/nanaki-93/kudev/test/util"
 )
 
 func TestCheckPortAvailable_Free(t *testing.T) {
@@ -17,7 +24,7 @@ func TestCheckPortAvailable_Free(t *testing.T) {
 	ln.Close()
 
 	// Port should be available now
-	err = checkPortAvailable(int32(port))
+	err = checkPortAvailable("", int32(port))
 	if err != nil {
 		t.Errorf("port should be available: %v", err)
 	}
@@ -34,12 +41,55 @@ func TestCheckPortAvailable_InUse(t *testing.T) {
 	port := ln.Addr().(*net.TCPAddr).Port
 
 	// Port should NOT be available
-	err = checkPortAvailable(int32(port))
+	err = checkPortAvailable("", int32(port))
 	if err == nil {
 		t.Error("port should NOT be available")
 	}
 }
 
+func TestCheckPortAvailable_Address(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		listen  string
+		address string
+	}{
+		{name: "ipv4 loopback", network: "tcp4", listen: "127.0.0.1:0", address: "127.0.0.1"},
+		{name: "localhost", network: "tcp4", listen: "127.0.0.1:0", address: "localhost"},
+		{name: "ipv6 loopback", network: "tcp6", listen: "[::1]:0", address: "::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen(tt.network, tt.listen)
+			if err != nil {
+				t.Skipf("cannot listen on %s: %v", tt.listen, err)
+			}
+			defer ln.Close()
+
+			port := int32(ln.Addr().(*net.TCPAddr).Port)
+			if err := checkPortAvailable(tt.address, port); err == nil {
+				t.Errorf("port %d on %s should NOT be available", port, tt.address)
+			}
+		})
+	}
+}
+
+func TestPortAvailable(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if PortAvailable("localhost", int32(ln.Addr().(*net.TCPAddr).Port)) {
+		t.Error("PortAvailable() = true for a port in use")
+	}
+	if !PortAvailable("localhost", freePort(t)) {
+		t.Error("PortAvailable() = false for a free port")
+	}
+}
+
 func TestSuggestAlternativePort(t *testing.T) {
 	// Occupy a port
 	ln, err := net.Listen("tcp", ":0")
@@ -61,11 +111,26 @@ func TestSuggestAlternativePort(t *testing.T) {
 	}
 
 	// Alternative should be available
-	if err := checkPortAvailable(alt); err != nil {
+	if err := checkPortAvailable("", alt); err != nil {
 		t.Errorf("suggested port %d not available: %v", alt, err)
 	}
 }
 
+func TestSuggestPortExcluding(t *testing.T) {
+	preferred := freePort(t)
+
+	port, err := SuggestPortExcluding(preferred, map[int32]bool{preferred: true})
+	if err != nil {
+		t.Fatalf("SuggestPortExcluding failed: %v", err)
+	}
+	if port == preferred {
+		t.Errorf("got taken port %d", port)
+	}
+	if !PortAvailable("", port) {
+		t.Errorf("suggested port %d not available", port)
+	}
+}
+
 func TestSuggestAlternativePort_PreferredAvailable(t *testing.T) {
 	// Find a free port
 	ln, err := net.Listen("tcp", ":0")
@@ -85,3 +150,265 @@ func TestSuggestAlternativePort_PreferredAvailable(t *testing.T) {
 		t.Errorf("should return preferred port, got %d", alt)
 	}
 }
+
+// fakeSessions hands out sessions whose end the test controls.
+type fakeSessions struct {
+	mu       sync.Mutex
+	calls    int
+	failFrom int // connect fails from this call on (0 = never)
+	sessions []chan error
+	ports    [][]Port // ports of each connect call
+}
+
+func (f *fakeSessions) connect(ctx context.Context, appName, namespace string, ports []Port) (*session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	f.ports = append(f.ports, ports)
+	if f.failFrom > 0 && f.calls >= f.failFrom {
+		return nil, errors.New("no ready pod")
+	}
+	done := make(chan error, 1)
+	f.sessions = append(f.sessions, done)
+	sess := &session{stop: make(chan struct{}), done: done}
+	go func() {
+		<-sess.stop
+		done <- nil
+	}()
+	return sess, nil
+}
+
+// drop ends the i-th session with an error, as a lost connection would.
+func (f *fakeSessions) drop(i int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[i] <- errors.New("lost connection to pod")
+}
+
+func newTestForwarder(fake *fakeSessions) *KubernetesPortForwarder {
+	pf := NewKubernetesPortForwarder(nil, nil, &util.MockLogger{})
+	pf.connect = fake.connect
+	pf.ReconnectBackoff = time.Millisecond
+	pf.MaxReconnectBackoff = 2 * time.Millisecond
+	pf.MaxReconnectAttempts = 3
+	return pf
+}
+
+func freePort(t *testing.T) int32 {
+	t.Helper()
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return int32(ln.Addr().(*net.TCPAddr).Port)
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestForwarder_Reconnects(t *testing.T) {
+	fake := &fakeSessions{}
+	pf := newTestForwarder(fake)
+
+	if err := pf.Forward(context.Background(), "app", "default", freePort(t), 8080); err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+
+	fake.drop(0)
+	waitFor(t, func() bool { return pf.Stats().Reconnects == 1 })
+
+	fake.drop(1)
+	waitFor(t, func() bool { return pf.Stats().Reconnects == 2 })
+
+	pf.Stop()
+
+	stats := pf.Stats()
+	if stats.Disconnects != 2 || stats.FailedAttempts != 0 || stats.GaveUp {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.LastReconnect.IsZero() {
+		t.Error("LastReconnect not set")
+	}
+}
+
+func TestForwarder_ReconnectWaitsForBackoff(t *testing.T) {
+	fake := &fakeSessions{}
+	pf := newTestForwarder(fake)
+	pf.ReconnectBackoff = DefaultReconnectBackoff
+	pf.MaxReconnectBackoff = DefaultMaxReconnectBackoff
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	pf.Clock = clk
+	defer pf.Stop()
+
+	if err := pf.Forward(context.Background(), "app", "default", freePort(t), 8080); err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+
+	fake.drop(0)
+	clk.BlockUntil(1)
+	if got := pf.Stats().Reconnects; got != 0 {
+		t.Fatalf("reconnected before the backoff elapsed (%d reconnects)", got)
+	}
+
+	// The first delay is at most ReconnectBackoff (after jitter)
+	clk.Advance(DefaultReconnectBackoff)
+	waitFor(t, func() bool { return pf.Stats().Reconnects == 1 })
+	if got := pf.Stats().LastReconnect; !got.Equal(clk.Now()) {
+		t.Errorf("LastReconnect = %v, want the fake clock's %v", got, clk.Now())
+	}
+}
+
+func TestForwarder_ForwardPorts(t *testing.T) {
+	fake := &fakeSessions{}
+	pf := newTestForwarder(fake)
+	defer pf.Stop()
+
+	ports := []Port{{Local: freePort(t), Pod: 8080}, {Local: freePort(t), Pod: 5005}}
+	if err := pf.ForwardPorts(context.Background(), "app", "default", ports); err != nil {
+		t.Fatalf("ForwardPorts failed: %v", err)
+	}
+
+	// Reconnects restore every port
+	fake.drop(0)
+	waitFor(t, func() bool { return pf.Stats().Reconnects == 1 })
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for i, got := range fake.ports {
+		if len(got) != 2 || got[0] != ports[0] || got[1] != ports[1] {
+			t.Errorf("connect %d ports = %v, want %v", i, got, ports)
+		}
+	}
+}
+
+func TestForwarder_ForwardPortsUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	busy := int32(ln.Addr().(*net.TCPAddr).Port)
+
+	fake := &fakeSessions{}
+	pf := newTestForwarder(fake)
+
+	err = pf.ForwardPorts(context.Background(), "app", "default", []Port{{Local: freePort(t), Pod: 8080}, {Local: busy, Pod: 5005}})
+	if err == nil {
+		t.Fatal("expected an error for a busy local port")
+	}
+	if fake.calls != 0 {
+		t.Error("connected although a port was busy")
+	}
+
+	if err := pf.ForwardPorts(context.Background(), "app", "default", nil); err == nil {
+		t.Error("expected an error without ports")
+	}
+}
+
+func TestPort_String(t *testing.T) {
+	if got := (Port{Local: 5005, Pod: 5006}).String(); got != "5005:5006" {
+		t.Errorf("String() = %q, want %q", got, "5005:5006")
+	}
+}
+
+func TestForwarder_GivesUp(t *testing.T) {
+	fake := &fakeSessions{failFrom: 2}
+	pf := newTestForwarder(fake)
+
+	if err := pf.Forward(context.Background(), "app", "default", freePort(t), 8080); err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+
+	fake.drop(0)
+	waitFor(t, func() bool { return pf.Stats().GaveUp })
+
+	stats := pf.Stats()
+	if stats.FailedAttempts != 3 {
+		t.Errorf("FailedAttempts = %d, want 3", stats.FailedAttempts)
+	}
+	if stats.LastError != "no ready pod" {
+		t.Errorf("LastError = %q", stats.LastError)
+	}
+
+	pf.Stop()
+}
+
+func TestForwarder_StopIdempotent(t *testing.T) {
+	fake := &fakeSessions{}
+	pf := newTestForwarder(fake)
+
+	// Before Forward
+	pf.Stop()
+	pf.Stop()
+
+	if err := pf.Forward(context.Background(), "app", "default", freePort(t), 8080); err == nil {
+		t.Error("expected Forward to fail after Stop")
+	}
+}
+
+func TestForwarder_StopsOnContextCancel(t *testing.T) {
+	fake := &fakeSessions{}
+	pf := newTestForwarder(fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := pf.Forward(ctx, "app", "default", freePort(t), 8080); err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		pf.Stop()
+		pf.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+	if n := pf.Stats().Reconnects; n != 0 {
+		t.Errorf("Reconnects = %d, want 0", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{7, 30 * time.Second},
+		{50, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		got := backoff(tt.attempt, DefaultReconnectBackoff, DefaultMaxReconnectBackoff)
+		if got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 100 * time.Millisecond
+	for i := 0; i < 100; i++ {
+		got := jitter(d)
+		if got < d/2 || got > d {
+			t.Fatalf("jitter(%s) = %s, want within [%s, %s]", d, got, d/2, d)
+		}
+	}
+}
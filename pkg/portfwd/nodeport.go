@@ -0,0 +1,176 @@
+// pkg/portfwd/nodeport.go
+
+package portfwd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// nodePortSuffix names the temporary Service: <app>-kudev-nodeport.
+const nodePortSuffix = "-kudev-nodeport"
+
+// Kubernetes' default NodePort range; a local port inside it is requested
+// as the node port, so the address is predictable.
+const (
+	minNodePort = 30000
+	maxNodePort = 32767
+)
+
+// NodePortExposer exposes ports that port-forward can't carry (UDP and
+// SCTP; the Kubernetes port-forward protocol is TCP-only) through a
+// temporary NodePort Service. Unlike a forward, the app is then reached
+// at a node address, not on localhost.
+type NodePortExposer struct {
+	clientset kubernetes.Interface
+	logger    logging.LoggerInterface
+}
+
+// NewNodePortExposer creates a new exposer.
+func NewNodePortExposer(clientset kubernetes.Interface, logger logging.LoggerInterface) *NodePortExposer {
+	return &NodePortExposer{
+		clientset: clientset,
+		logger:    logging.OrDefault(logger),
+	}
+}
+
+// NodePortExposure describes the temporary Service created by Expose.
+type NodePortExposure struct {
+	// Service is the name of the temporary Service
+	Service string
+
+	// Ports are the exposed ports, in the order given to Expose
+	Ports []ExposedPort
+
+	// NodeAddresses are the addresses of the cluster's nodes
+	// (empty when nodes can't be listed)
+	NodeAddresses []string
+}
+
+// ExposedPort is a pod port reachable on every node at NodePort.
+type ExposedPort struct {
+	Port     Port
+	NodePort int32
+}
+
+// Expose creates the temporary NodePort Service for ports, replacing a
+// leftover one from an earlier run. It carries the app's labels, so
+// 'kudev down' removes it as well if Remove never ran.
+func (e *NodePortExposer) Expose(ctx context.Context, appName, namespace string, ports []Port) (*NodePortExposure, error) {
+	name := NodePortServiceName(appName)
+	services := e.clientset.CoreV1().Services(namespace)
+
+	if err := e.Remove(ctx, appName, namespace); err != nil {
+		return nil, err
+	}
+
+	svc := nodePortService(name, appName, namespace, ports, true)
+	created, err := services.Create(ctx, svc, metav1.CreateOptions{})
+	if errors.IsInvalid(err) {
+		// A requested node port is taken or outside the cluster's range:
+		// let Kubernetes pick
+		e.logger.Debug("requested node ports rejected, retrying with allocated ones", "error", err)
+		created, err = services.Create(ctx, nodePortService(name, appName, namespace, ports, false), metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NodePort service: %w", err)
+	}
+
+	exposure := &NodePortExposure{
+		Service:       created.Name,
+		NodeAddresses: e.nodeAddresses(ctx),
+	}
+	for i, p := range ports {
+		exposure.Ports = append(exposure.Ports, ExposedPort{Port: p, NodePort: created.Spec.Ports[i].NodePort})
+	}
+
+	e.logger.Info("exposed ports through temporary NodePort service",
+		"service", created.Name,
+		"namespace", namespace,
+	)
+	return exposure, nil
+}
+
+// Remove deletes the temporary Service. Safe to call when it doesn't exist.
+func (e *NodePortExposer) Remove(ctx context.Context, appName, namespace string) error {
+	err := e.clientset.CoreV1().Services(namespace).Delete(ctx, NodePortServiceName(appName), metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete NodePort service: %w", err)
+	}
+	return nil
+}
+
+// NodePortServiceName returns the name of the app's temporary Service,
+// shortened to fit the 63 character limit.
+func NodePortServiceName(appName string) string {
+	if max := 63 - len(nodePortSuffix); len(appName) > max {
+		appName = strings.TrimRight(appName[:max], "-")
+	}
+	return appName + nodePortSuffix
+}
+
+// nodePortService builds the Service. With requestLocal, local ports in
+// the NodePort range are requested as the node port.
+func nodePortService(name, appName, namespace string, ports []Port, requestLocal bool) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":             appName,
+				"managed-by":      "kudev",
+				"kudev-temporary": "true",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			Selector: map[string]string{
+				"app":        appName,
+				"managed-by": "kudev",
+			},
+		},
+	}
+
+	for _, p := range ports {
+		sp := corev1.ServicePort{
+			Name:       strings.ToLower(fmt.Sprintf("%s-%d", p.protocol(), p.Pod)),
+			Protocol:   p.protocol(),
+			Port:       p.Pod,
+			TargetPort: intstr.FromInt32(p.Pod),
+		}
+		if requestLocal && p.Local >= minNodePort && p.Local <= maxNodePort {
+			sp.NodePort = p.Local
+		}
+		svc.Spec.Ports = append(svc.Spec.Ports, sp)
+	}
+	return svc
+}
+
+// nodeAddresses lists the nodes' internal and external IPs.
+// Returns nil when nodes can't be listed (e.g. no permission).
+func (e *NodePortExposer) nodeAddresses(ctx context.Context) []string {
+	nodes, err := e.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		e.logger.Debug("failed to list nodes", "error", err)
+		return nil
+	}
+
+	var addresses []string
+	for _, node := range nodes.Items {
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP || addr.Type == corev1.NodeExternalIP {
+				addresses = append(addresses, addr.Address)
+			}
+		}
+	}
+	return addresses
+}
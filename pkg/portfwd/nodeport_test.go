@@ -0,0 +1,116 @@
+// pkg/portfwd/nodeport_test.go
+
+package portfwd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestNodePortExposer_ExposeAndRemove(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: "node-1"},
+			{Type: corev1.NodeInternalIP, Address: "172.18.0.2"},
+		}},
+	}
+	leftover := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp-kudev-nodeport", Namespace: "default"},
+	}
+	client := fake.NewSimpleClientset(node, leftover)
+	e := NewNodePortExposer(client, &util.MockLogger{})
+	ctx := context.Background()
+
+	ports := []Port{
+		{Local: 30053, Pod: 53, Protocol: corev1.ProtocolUDP},
+		{Local: 9999, Pod: 9999, Protocol: corev1.ProtocolSCTP},
+	}
+	exposure, err := e.Expose(ctx, "myapp", "default", ports)
+	if err != nil {
+		t.Fatalf("Expose failed: %v", err)
+	}
+
+	if exposure.Service != "myapp-kudev-nodeport" {
+		t.Errorf("Service = %q", exposure.Service)
+	}
+	if len(exposure.NodeAddresses) != 1 || exposure.NodeAddresses[0] != "172.18.0.2" {
+		t.Errorf("NodeAddresses = %v, want [172.18.0.2]", exposure.NodeAddresses)
+	}
+
+	svc, err := client.CoreV1().Services("default").Get(ctx, "myapp-kudev-nodeport", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("type = %s, want NodePort", svc.Spec.Type)
+	}
+	if svc.Labels["managed-by"] != "kudev" || svc.Spec.Selector["app"] != "myapp" {
+		t.Errorf("labels = %v, selector = %v", svc.Labels, svc.Spec.Selector)
+	}
+
+	udp, sctp := svc.Spec.Ports[0], svc.Spec.Ports[1]
+	if udp.Protocol != corev1.ProtocolUDP || udp.TargetPort.IntValue() != 53 || udp.NodePort != 30053 {
+		t.Errorf("udp port = %+v", udp)
+	}
+	// 9999 is outside the NodePort range: left to Kubernetes
+	if sctp.Protocol != corev1.ProtocolSCTP || sctp.NodePort != 0 {
+		t.Errorf("sctp port = %+v", sctp)
+	}
+
+	if err := e.Remove(ctx, "myapp", "default"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := e.Remove(ctx, "myapp", "default"); err != nil {
+		t.Errorf("second Remove failed: %v", err)
+	}
+}
+
+func TestNodePortServiceName(t *testing.T) {
+	if got := NodePortServiceName("myapp"); got != "myapp-kudev-nodeport" {
+		t.Errorf("NodePortServiceName() = %q", got)
+	}
+
+	long := NodePortServiceName(strings.Repeat("a", 60))
+	if len(long) > 63 || !strings.HasSuffix(long, nodePortSuffix) {
+		t.Errorf("NodePortServiceName() = %q (%d chars)", long, len(long))
+	}
+}
+
+func TestPort_Forwardable(t *testing.T) {
+	tests := []struct {
+		port Port
+		want bool
+		str  string
+	}{
+		{port: Port{Local: 8080, Pod: 8080}, want: true, str: "8080:8080"},
+		{port: Port{Local: 8080, Pod: 8080, Protocol: "tcp"}, want: true, str: "8080:8080"},
+		{port: Port{Local: 5353, Pod: 53, Protocol: corev1.ProtocolUDP}, want: false, str: "5353:53/udp"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.port.Forwardable(); got != tt.want {
+			t.Errorf("%+v Forwardable() = %v, want %v", tt.port, got, tt.want)
+		}
+		if got := tt.port.String(); got != tt.str {
+			t.Errorf("%+v String() = %q, want %q", tt.port, got, tt.str)
+		}
+	}
+}
+
+func TestForwardPorts_RejectsUDP(t *testing.T) {
+	fake := &fakeSessions{}
+	pf := newTestForwarder(fake)
+
+	err := pf.ForwardPorts(context.Background(), "app", "default", []Port{{Local: freePort(t), Pod: 53, Protocol: corev1.ProtocolUDP}})
+	if err == nil || !strings.Contains(err.Error(), "TCP-only") {
+		t.Errorf("err = %v, want TCP-only error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package portfwd
+
+import "fmt"
+
+// PortRequest is what one service asks to forward: its ports, with the
+// configured local ports, and the address they listen on.
+type PortRequest struct {
+	Service string
+	Address string
+	Ports   []Port
+}
+
+// PortPlan is the forward assigned to one service.
+type PortPlan struct {
+	Service string
+	Ports   []Port
+
+	// Moved maps a substituted local port to the configured one
+	Moved map[int32]int32
+}
+
+// PlanPorts assigns collision-free local ports to the requests, in order:
+// a port is kept when it is free and no earlier service has it, else it
+// moves to a free port nearby. With strict a busy or repeated port is an
+// error instead.
+func PlanPorts(requests []PortRequest, strict bool) ([]PortPlan, error) {
+	taken := make(map[int32]bool)
+	owner := make(map[int32]string)
+
+	plans := make([]PortPlan, 0, len(requests))
+	for _, req := range requests {
+		plan := PortPlan{Service: req.Service, Moved: make(map[int32]int32)}
+
+		for _, p := range req.Ports {
+			local := p.Local
+			if taken[local] || !PortAvailable(req.Address, local) {
+				if strict {
+					if other, ok := owner[local]; ok {
+						return nil, fmt.Errorf("local port %d of service %s is also used by service %s", local, req.Service, other)
+					}
+					return nil, fmt.Errorf("local port %d of service %s is busy", local, req.Service)
+				}
+				free, err := SuggestPortExcluding(local, taken)
+				if err != nil {
+					return nil, fmt.Errorf("service %s: %w", req.Service, err)
+				}
+				plan.Moved[free] = local
+				local = free
+			}
+
+			taken[local] = true
+			owner[local] = req.Service
+			p.Local = local
+			plan.Ports = append(plan.Ports, p)
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
@@ -0,0 +1,135 @@
+package portfwd
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestPlanPorts(t *testing.T) {
+	// A port held open for the whole test
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	busy := int32(ln.Addr().(*net.TCPAddr).Port)
+
+	a, b := freePort(t), freePort(t)
+
+	tests := []struct {
+		name     string
+		requests []PortRequest
+		strict   bool
+
+		// wantMoved is how many ports of each service move
+		wantMoved []int
+		wantErr   string
+	}{
+		{
+			name: "free ports are kept",
+			requests: []PortRequest{
+				{Service: "api", Ports: []Port{{Local: a, Pod: 8080}}},
+				{Service: "web", Ports: []Port{{Local: b, Pod: 3000}}},
+			},
+			wantMoved: []int{0, 0},
+		},
+		{
+			name: "repeated port moves for the later service",
+			requests: []PortRequest{
+				{Service: "api", Ports: []Port{{Local: a, Pod: 8080}}},
+				{Service: "web", Ports: []Port{{Local: a, Pod: 8080}}},
+			},
+			wantMoved: []int{0, 1},
+		},
+		{
+			name: "repeated port within a service moves",
+			requests: []PortRequest{
+				{Service: "api", Ports: []Port{{Local: a, Pod: 8080}, {Local: a, Pod: 9090}}},
+			},
+			wantMoved: []int{1},
+		},
+		{
+			name: "busy port moves",
+			requests: []PortRequest{
+				{Service: "api", Ports: []Port{{Local: busy, Pod: 8080}}},
+			},
+			wantMoved: []int{1},
+		},
+		{
+			name:   "strict: repeated port",
+			strict: true,
+			requests: []PortRequest{
+				{Service: "api", Ports: []Port{{Local: a, Pod: 8080}}},
+				{Service: "web", Ports: []Port{{Local: a, Pod: 8080}}},
+			},
+			wantErr: "also used by service api",
+		},
+		{
+			name:   "strict: busy port",
+			strict: true,
+			requests: []PortRequest{
+				{Service: "api", Ports: []Port{{Local: busy, Pod: 8080}}},
+			},
+			wantErr: "of service api is busy",
+		},
+		{
+			name:   "strict: free ports are kept",
+			strict: true,
+			requests: []PortRequest{
+				{Service: "api", Ports: []Port{{Local: a, Pod: 8080}}},
+				{Service: "web", Ports: []Port{{Local: b, Pod: 3000}}},
+			},
+			wantMoved: []int{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plans, err := PlanPorts(tt.requests, tt.strict)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PlanPorts() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanPorts() error = %v", err)
+			}
+			if len(plans) != len(tt.requests) {
+				t.Fatalf("got %d plans, want %d", len(plans), len(tt.requests))
+			}
+
+			assigned := make(map[int32]string)
+			for i, plan := range plans {
+				req := tt.requests[i]
+				if plan.Service != req.Service || len(plan.Ports) != len(req.Ports) {
+					t.Fatalf("plan %d = %+v for request %+v", i, plan, req)
+				}
+
+				moved := 0
+				for j, p := range plan.Ports {
+					configured := req.Ports[j].Local
+					if p.Pod != req.Ports[j].Pod {
+						t.Errorf("%s: pod port %d, want %d", plan.Service, p.Pod, req.Ports[j].Pod)
+					}
+					if other, dup := assigned[p.Local]; dup {
+						t.Errorf("local port %d assigned to %s and %s", p.Local, other, plan.Service)
+					}
+					assigned[p.Local] = plan.Service
+
+					if p.Local == configured {
+						continue
+					}
+					moved++
+					if plan.Moved[p.Local] != configured {
+						t.Errorf("%s: port %d moved to %d, Moved = %v", plan.Service, configured, p.Local, plan.Moved)
+					}
+				}
+				if moved != tt.wantMoved[i] {
+					t.Errorf("%s: %d ports moved, want %d (got %+v)", plan.Service, moved, tt.wantMoved[i], plan.Ports)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+// Package ports tracks which local ports kudev projects have claimed, so
+// several projects on one machine don't fight over the same port-forward.
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RegistryVersion identifies the registry file format.
+const RegistryVersion = 1
+
+// FileName is the registry file name inside ~/.kudev.
+const FileName = "ports.json"
+
+// maxPort is the highest valid TCP port.
+const maxPort = 65535
+
+// Automatic ports are derived from the app name within
+// [AutoRangeStart, AutoRangeStart+AutoRangeSize).
+const (
+	AutoRangeStart = 20000
+	AutoRangeSize  = 10000
+)
+
+// Claim records that a project forwards a local port.
+type Claim struct {
+	Port        int32     `json:"port"`
+	App         string    `json:"app"`
+	ProjectRoot string    `json:"projectRoot"`
+	ClaimedAt   time.Time `json:"claimedAt"`
+}
+
+// registryFile is the content of the registry file.
+type registryFile struct {
+	Version int     `json:"version"`
+	Claims  []Claim `json:"claims,omitempty"`
+}
+
+// Registry reads and writes the machine-wide port registry.
+// It is safe for concurrent use within a single process.
+type Registry struct {
+	path string
+	mu   sync.Mutex
+
+	// inUse reports whether a port is bound by any process (replaceable in tests)
+	inUse func(port int32) bool
+}
+
+// NewRegistry creates a registry backed by the file at path.
+func NewRegistry(path string) *Registry {
+	return &Registry{path: path, inUse: isBound}
+}
+
+// DefaultPath returns ~/.kudev/ports.json.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".kudev", FileName), nil
+}
+
+// Path returns the registry file path.
+func (r *Registry) Path() string {
+	return r.path
+}
+
+// Claim records port for the project, replacing the project's previous claim.
+func (r *Registry) Claim(port int32, app, projectRoot string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	claims := f.Claims[:0]
+	for _, c := range f.Claims {
+		if c.ProjectRoot != projectRoot {
+			claims = append(claims, c)
+		}
+	}
+	f.Claims = append(claims, Claim{
+		Port:        port,
+		App:         app,
+		ProjectRoot: projectRoot,
+		ClaimedAt:   time.Now().UTC(),
+	})
+	sort.Slice(f.Claims, func(i, j int) bool { return f.Claims[i].Port < f.Claims[j].Port })
+
+	return r.save(f)
+}
+
+// Lookup returns the project's own claim, or nil.
+func (r *Registry) Lookup(projectRoot string) (*Claim, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range f.Claims {
+		if f.Claims[i].ProjectRoot == projectRoot {
+			return &f.Claims[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Conflict returns another project's claim on port, or nil.
+// Claims of projects whose directory no longer exists are ignored.
+func (r *Registry) Conflict(port int32, projectRoot string) (*Claim, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range liveClaims(f.Claims) {
+		if c.Port == port && c.ProjectRoot != projectRoot {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// SuggestFree returns the first port from start upward that no other
+// project has claimed and no process is listening on.
+func (r *Registry) SuggestFree(start int32, projectRoot string) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := r.load()
+	if err != nil {
+		return 0, err
+	}
+
+	claimed := make(map[int32]bool)
+	for _, c := range liveClaims(f.Claims) {
+		if c.ProjectRoot != projectRoot {
+			claimed[c.Port] = true
+		}
+	}
+
+	if start < 1024 {
+		start = 1024
+	}
+	for port := start; port <= maxPort; port++ {
+		if !claimed[port] && !r.inUse(port) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free local port found from %d", start)
+}
+
+// Preferred returns the port derived from the app name, so the same app
+// gets the same port on every machine unless it is taken.
+func Preferred(app string) int32 {
+	h := fnv.New32a()
+	h.Write([]byte(app))
+	return AutoRangeStart + int32(h.Sum32()%AutoRangeSize)
+}
+
+// Allocate picks the project's automatic port and claims it.
+//
+// previous is the port allocated on an earlier run (0 if none); it is kept
+// unless another project has claimed it since. Otherwise the first free
+// port from Preferred(app) is used.
+func (r *Registry) Allocate(app, projectRoot string, previous int32) (int32, error) {
+	port := previous
+	if port != 0 {
+		claim, err := r.Conflict(port, projectRoot)
+		if err != nil {
+			return 0, err
+		}
+		if claim != nil {
+			port = 0
+		}
+	}
+
+	if port == 0 {
+		free, err := r.SuggestFree(Preferred(app), projectRoot)
+		if err != nil {
+			return 0, err
+		}
+		port = free
+	}
+
+	if err := r.Claim(port, app, projectRoot); err != nil {
+		return 0, err
+	}
+	return port, nil
+}
+
+// liveClaims drops claims whose project directory was removed.
+func liveClaims(claims []Claim) []Claim {
+	var live []Claim
+	for _, c := range claims {
+		if _, err := os.Stat(c.ProjectRoot); err == nil {
+			live = append(live, c)
+		}
+	}
+	return live
+}
+
+// isBound reports whether something already listens on the local port.
+func isBound(port int32) bool {
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return true
+	}
+	l.Close()
+	return false
+}
+
+func (r *Registry) load() (*registryFile, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &registryFile{Version: RegistryVersion}, nil
+		}
+		return nil, fmt.Errorf("failed to read port registry %s: %w", r.path, err)
+	}
+
+	f := &registryFile{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse port registry %s: %w", r.path, err)
+	}
+	if f.Version == 0 {
+		f.Version = RegistryVersion
+	}
+	return f, nil
+}
+
+// save writes atomically (temp file + rename); the registry is shared
+// by every kudev process on the machine.
+func (r *Registry) save(f *registryFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal port registry: %w", err)
+	}
+
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp registry file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write port registry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write port registry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to replace port registry: %w", err)
+	}
+	return nil
+}
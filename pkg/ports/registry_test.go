@@ -0,0 +1,145 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestRegistry(t *testing.T, bound ...int32) *Registry {
+	t.Helper()
+	r := NewRegistry(filepath.Join(t.TempDir(), FileName))
+	busy := make(map[int32]bool)
+	for _, p := range bound {
+		busy[p] = true
+	}
+	r.inUse = func(port int32) bool { return busy[port] }
+	return r
+}
+
+func TestRegistry_ClaimAndConflict(t *testing.T) {
+	r := newTestRegistry(t)
+	api, web := t.TempDir(), t.TempDir()
+
+	if err := r.Claim(8080, "api", api); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+
+	// Same project: no conflict with itself
+	if c, _ := r.Conflict(8080, api); c != nil {
+		t.Errorf("project conflicts with its own claim: %+v", c)
+	}
+
+	c, err := r.Conflict(8080, web)
+	if err != nil {
+		t.Fatalf("Conflict failed: %v", err)
+	}
+	if c == nil || c.App != "api" {
+		t.Fatalf("expected conflict with api, got %+v", c)
+	}
+
+	// Re-claiming moves the project's claim
+	if err := r.Claim(9090, "api", api); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if c, _ := r.Conflict(8080, web); c != nil {
+		t.Errorf("old claim should be released, got %+v", c)
+	}
+	own, _ := r.Lookup(api)
+	if own == nil || own.Port != 9090 {
+		t.Errorf("Lookup() = %+v, want port 9090", own)
+	}
+}
+
+func TestRegistry_IgnoresRemovedProjects(t *testing.T) {
+	r := newTestRegistry(t)
+	gone := filepath.Join(t.TempDir(), "deleted")
+	if err := os.Mkdir(gone, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Claim(8080, "old", gone); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	if c, _ := r.Conflict(8080, t.TempDir()); c != nil {
+		t.Errorf("claim of removed project should be ignored, got %+v", c)
+	}
+}
+
+func TestRegistry_SuggestFree(t *testing.T) {
+	r := newTestRegistry(t, 8081)
+	other := t.TempDir()
+	if err := r.Claim(8080, "other", other); err != nil {
+		t.Fatal(err)
+	}
+
+	// 8080 claimed, 8081 bound by some process
+	got, err := r.SuggestFree(8080, t.TempDir())
+	if err != nil {
+		t.Fatalf("SuggestFree failed: %v", err)
+	}
+	if got != 8082 {
+		t.Errorf("SuggestFree() = %d, want 8082", got)
+	}
+
+	// The claiming project may keep its own port
+	if got, _ := r.SuggestFree(8080, other); got != 8080 {
+		t.Errorf("SuggestFree() for owner = %d, want 8080", got)
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	if Preferred("api") != Preferred("api") {
+		t.Error("Preferred() must be deterministic")
+	}
+	for _, app := range []string{"api", "web", "a-very-long-service-name"} {
+		p := Preferred(app)
+		if p < AutoRangeStart || p >= AutoRangeStart+AutoRangeSize {
+			t.Errorf("Preferred(%q) = %d, outside auto range", app, p)
+		}
+	}
+}
+
+func TestRegistry_Allocate(t *testing.T) {
+	preferred := Preferred("api")
+
+	tests := []struct {
+		name     string
+		previous int32
+		taken    int32 // claimed by another project
+		want     int32
+	}{
+		{name: "first run uses preferred port", want: preferred},
+		{name: "preferred port taken", taken: preferred, want: preferred + 1},
+		{name: "previous port is kept", previous: 31000, want: 31000},
+		{name: "previous port taken", previous: 31000, taken: 31000, want: preferred},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t)
+			root := t.TempDir()
+			if tt.taken != 0 {
+				if err := r.Claim(tt.taken, "other", t.TempDir()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := r.Allocate("api", root, tt.previous)
+			if err != nil {
+				t.Fatalf("Allocate failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Allocate() = %d, want %d", got, tt.want)
+			}
+
+			own, _ := r.Lookup(root)
+			if own == nil || own.Port != got {
+				t.Errorf("allocated port not claimed: %+v", own)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Package reaper installs a CronJob that deletes expired kudev apps from
+// shared dev clusters.
+//
+// Apps opt in with spec.ttl: every deploy stamps the Deployment with
+// deployer.AnnotationExpiresAt, and the CronJob deletes the resources of
+// each app whose expiry has passed, the same ones 'kudev down' removes.
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// Name is the name of every reaper object.
+const Name = "kudev-reaper"
+
+// Defaults for Options.
+const (
+	DefaultNamespace = "kudev-system"
+	DefaultSchedule  = "*/30 * * * *"
+	DefaultImage     = "bitnami/kubectl:latest"
+)
+
+// Options configures the reaper.
+type Options struct {
+	// Namespace holds the CronJob and its ServiceAccount.
+	Namespace string
+
+	// Schedule is the CronJob schedule, in cron format.
+	Schedule string
+
+	// Image must provide bash and kubectl.
+	Image string
+}
+
+// withDefaults fills unset options.
+func (o Options) withDefaults() Options {
+	if o.Namespace == "" {
+		o.Namespace = DefaultNamespace
+	}
+	if o.Schedule == "" {
+		o.Schedule = DefaultSchedule
+	}
+	if o.Image == "" {
+		o.Image = DefaultImage
+	}
+	return o
+}
+
+// typedResources are the kinds deleted besides deployer.DefaultCleanupResources.
+var typedResources = []schema.GroupVersionResource{
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Version: "v1", Resource: "services"},
+	{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"},
+}
+
+// resources returns what the reaper deletes.
+func resources() []schema.GroupVersionResource {
+	return append(append([]schema.GroupVersionResource{}, typedResources...), deployer.DefaultCleanupResources...)
+}
+
+// Script is the reaper's bash script. It lists kudev Deployments with an
+// expiry and deletes every kudev resource of the expired apps. An app
+// with several Deployments (blue/green slots) goes by the latest expiry.
+// RFC 3339 UTC timestamps compare correctly as strings.
+func Script() string {
+	kinds := make([]string, 0, len(resources()))
+	for _, r := range resources() {
+		kind := r.Resource
+		if r.Group != "" {
+			kind += "." + r.Group
+		}
+		kinds = append(kinds, kind)
+	}
+
+	return fmt.Sprintf(`set -euo pipefail
+now=$(date -u +%%Y-%%m-%%dT%%H:%%M:%%SZ)
+kubectl get deployments --all-namespaces -l managed-by=kudev -o go-template='%s' |
+sort -r | awk '!seen[$1 FS $2]++' |
+while read -r ns app expires; do
+  if [[ "$expires" < "$now" ]]; then
+    echo "reaping $ns/$app (expired $expires)"
+    kubectl delete %s -n "$ns" -l "app=$app,managed-by=kudev" --ignore-not-found ||
+      echo "failed to reap $ns/$app" >&2
+  fi
+done
+`, listTemplate, strings.Join(kinds, ","))
+}
+
+// listTemplate is the kubectl go-template printing "<namespace> <app>
+// <expires-at>" for each Deployment with an expiry.
+const listTemplate = `{{range .items}}{{$ns := .metadata.namespace}}{{$app := index .metadata.labels "app"}}` +
+	`{{with .metadata.annotations}}{{with index . "` + deployer.AnnotationExpiresAt + `"}}` +
+	`{{$ns}} {{$app}} {{.}}{{"\n"}}{{end}}{{end}}{{end}}`
+
+// Objects returns the reaper's objects in apply order.
+func Objects(opts Options) []runtime.Object {
+	opts = opts.withDefaults()
+	labels := map[string]string{
+		deployer.LabelName:      Name,
+		deployer.LabelManagedBy: "kudev",
+	}
+	meta := metav1.ObjectMeta{Name: Name, Namespace: opts.Namespace, Labels: labels}
+	clusterMeta := metav1.ObjectMeta{Name: Name, Labels: labels}
+
+	var rules []rbacv1.PolicyRule
+	for _, r := range resources() {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{r.Group},
+			Resources: []string{r.Resource},
+			Verbs:     []string{"list", "delete", "deletecollection"},
+		})
+	}
+
+	var oneJob int32 = 1
+	var noRetries int32 = 0
+	return []runtime.Object{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: opts.Namespace, Labels: labels},
+		},
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta,
+		},
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+			ObjectMeta: clusterMeta,
+			Rules:      rules,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: clusterMeta,
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: Name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: Name, Namespace: opts.Namespace}},
+		},
+		&batchv1.CronJob{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+			ObjectMeta: meta,
+			Spec: batchv1.CronJobSpec{
+				Schedule:                   opts.Schedule,
+				ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+				SuccessfulJobsHistoryLimit: &oneJob,
+				FailedJobsHistoryLimit:     &oneJob,
+				JobTemplate: batchv1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{
+						BackoffLimit: &noRetries,
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: labels},
+							Spec: corev1.PodSpec{
+								ServiceAccountName: Name,
+								RestartPolicy:      corev1.RestartPolicyNever,
+								Containers: []corev1.Container{{
+									Name:    "reaper",
+									Image:   opts.Image,
+									Command: []string{"/bin/bash", "-c", Script()},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Install creates or updates the reaper's objects.
+func Install(ctx context.Context, clientset kubernetes.Interface, opts Options, logger logging.LoggerInterface) error {
+	logger = logging.OrDefault(logger)
+	for _, obj := range Objects(opts) {
+		if err := install(ctx, clientset, obj); err != nil {
+			return err
+		}
+		logger.Debug("reaper object applied", "type", fmt.Sprintf("%T", obj))
+	}
+	return nil
+}
+
+// install creates or updates one object.
+func install(ctx context.Context, clientset kubernetes.Interface, obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *corev1.Namespace:
+		return upsert(ctx, clientset.CoreV1().Namespaces(), "namespace", o)
+	case *corev1.ServiceAccount:
+		return upsert(ctx, clientset.CoreV1().ServiceAccounts(o.Namespace), "service account", o)
+	case *rbacv1.ClusterRole:
+		return upsert(ctx, clientset.RbacV1().ClusterRoles(), "cluster role", o)
+	case *rbacv1.ClusterRoleBinding:
+		return upsert(ctx, clientset.RbacV1().ClusterRoleBindings(), "cluster role binding", o)
+	case *batchv1.CronJob:
+		return upsert(ctx, clientset.BatchV1().CronJobs(o.Namespace), "cronjob", o)
+	}
+	return fmt.Errorf("unsupported reaper object %T", obj)
+}
+
+// client is the part of a typed client-go client upsert needs.
+type client[T any] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Create(ctx context.Context, obj T, opts metav1.CreateOptions) (T, error)
+	Update(ctx context.Context, obj T, opts metav1.UpdateOptions) (T, error)
+}
+
+// upsert creates desired, or updates it in place when it exists.
+func upsert[T metav1.Object](ctx context.Context, c client[T], kind string, desired T) error {
+	existing, err := c.Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if _, err := c.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create %s %s: %w", kind, desired.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s: %w", kind, desired.GetName(), err)
+	}
+
+	desired.SetResourceVersion(existing.GetResourceVersion())
+	if _, err := c.Update(ctx, desired, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update %s %s: %w", kind, desired.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestInstall(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	ctx := context.Background()
+
+	if err := Install(ctx, clientset, Options{}, &util.MockLogger{}); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	// Installing again updates in place
+	if err := Install(ctx, clientset, Options{Schedule: "0 * * * *"}, &util.MockLogger{}); err != nil {
+		t.Fatalf("second Install() error = %v", err)
+	}
+
+	cronJob, err := clientset.BatchV1().CronJobs(DefaultNamespace).Get(ctx, Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("cronjob not created: %v", err)
+	}
+	if cronJob.Spec.Schedule != "0 * * * *" {
+		t.Errorf("schedule = %q, want %q", cronJob.Spec.Schedule, "0 * * * *")
+	}
+	pod := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	if pod.ServiceAccountName != Name || pod.Containers[0].Image != DefaultImage {
+		t.Errorf("pod spec = %+v", pod)
+	}
+
+	binding, err := clientset.RbacV1().ClusterRoleBindings().Get(ctx, Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("cluster role binding not created: %v", err)
+	}
+	if s := binding.Subjects[0]; s.Name != Name || s.Namespace != DefaultNamespace {
+		t.Errorf("subject = %+v", s)
+	}
+}
+
+func TestScript(t *testing.T) {
+	script := Script()
+	for _, want := range []string{
+		"deployments.apps,services,poddisruptionbudgets.policy,configmaps,secrets",
+		`-l "app=$app,managed-by=kudev"`,
+		deployer.AnnotationExpiresAt,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script lacks %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestListTemplate(t *testing.T) {
+	// kubectl runs the template on the list as JSON-like maps
+	list := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{
+				"namespace":   "dev",
+				"labels":      map[string]interface{}{"app": "myapp"},
+				"annotations": map[string]interface{}{deployer.AnnotationExpiresAt: "2026-10-18T12:00:00Z"},
+			}},
+			map[string]interface{}{"metadata": map[string]interface{}{
+				"namespace": "dev",
+				"labels":    map[string]interface{}{"app": "no-ttl"},
+			}},
+		},
+	}
+
+	tmpl, err := template.New("list").Parse(listTemplate)
+	if err != nil {
+		t.Fatalf("template does not parse: %v", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, list); err != nil {
+		t.Fatalf("template failed: %v", err)
+	}
+	if out.String() != "dev myapp 2026-10-18T12:00:00Z\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -4,18 +4,22 @@ package registry
 
 import (
 	"context"
+	"fmt"
+	"os/exec"
+	"strings"
 
 	"github.com/nanaki-93/kudev/pkg/logging"
 )
 
 // dockerDesktopLoader handles image loading for Docker Desktop.
 type dockerDesktopLoader struct {
+	engine Engine
 	logger logging.LoggerInterface
 }
 
 // newDockerDesktopLoader creates a new Docker Desktop loader.
-func newDockerDesktopLoader(logger logging.LoggerInterface) *dockerDesktopLoader {
-	return &dockerDesktopLoader{logger: logger}
+func newDockerDesktopLoader(engine Engine, logger logging.LoggerInterface) *dockerDesktopLoader {
+	return &dockerDesktopLoader{engine: engine, logger: logger}
 }
 
 // Name returns the loader identifier.
@@ -37,5 +41,48 @@ func (d *dockerDesktopLoader) Load(ctx context.Context, imageRef string) error {
 	return nil
 }
 
-// Ensure dockerDesktopLoader implements Loader
-var _ Loader = (*dockerDesktopLoader)(nil)
+// Remove does nothing: the cluster uses the local daemon's images, so
+// removing them locally removes them from the cluster.
+func (d *dockerDesktopLoader) Remove(ctx context.Context, imageRefs []string) error {
+	return nil
+}
+
+// Check verifies the Docker daemon, which the cluster shares, is reachable.
+func (d *dockerDesktopLoader) Check(ctx context.Context) error {
+	return checkDocker(ctx, d.engine)
+}
+
+// checkDocker verifies the daemon is reachable, through engine when set,
+// else with the docker CLI.
+func checkDocker(ctx context.Context, engine Engine) error {
+	if engine != nil {
+		if err := engine.Ping(ctx); err != nil {
+			return fmt.Errorf(
+				"docker daemon not reachable\n\n"+
+					"Error: %w\n\n"+
+					"  - Ensure Docker is running: docker info",
+				err,
+			)
+		}
+		return nil
+	}
+
+	output, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf(
+			"docker daemon not reachable\n\n"+
+				"Output: %s\n"+
+				"Error: %w\n\n"+
+				"  - Ensure Docker is running: docker info",
+			strings.TrimSpace(string(output)), err,
+		)
+	}
+	return nil
+}
+
+// Ensure dockerDesktopLoader implements Loader and Checker
+var (
+	_ Loader  = (*dockerDesktopLoader)(nil)
+	_ Checker = (*dockerDesktopLoader)(nil)
+	_ Remover = (*dockerDesktopLoader)(nil)
+)
@@ -0,0 +1,88 @@
+// pkg/registry/engine.go
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Engine is the Docker daemon as the api build backend reaches it,
+// through the Docker SDK (spec.build.backend: api). When a Registry has
+// one, loaders export images and run commands in node containers through
+// it, so images are loaded from the daemon they were built in without
+// the docker CLI.
+type Engine interface {
+	// Ping checks that the daemon answers.
+	Ping(ctx context.Context) error
+
+	// SaveImage exports a local image as a 'docker save' tarball.
+	SaveImage(ctx context.Context, ref string) (io.ReadCloser, error)
+
+	// ContainersWithLabel returns the names of the running containers
+	// carrying label (key=value).
+	ContainersWithLabel(ctx context.Context, label string) ([]string, error)
+
+	// Exec runs cmd in a running container, feeding it stdin when
+	// non-nil, and returns its combined output.
+	Exec(ctx context.Context, container string, cmd []string, stdin io.Reader) (string, error)
+}
+
+// WithEngine makes the loaders use engine instead of the docker CLI.
+func (r *Registry) WithEngine(engine Engine) *Registry {
+	r.engine = engine
+	return r
+}
+
+// importImage streams the 'docker save' tarball of imageRef into the
+// stdin of dst and returns the combined output. The image is exported
+// through engine when set, else with the docker CLI.
+func importImage(ctx context.Context, engine Engine, imageRef string, dst []string) (string, error) {
+	if engine == nil {
+		return pipe(ctx, []string{"docker", "save", imageRef}, dst)
+	}
+
+	tarball, err := engine.SaveImage(ctx, imageRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to export image: %w", err)
+	}
+	defer tarball.Close()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, dst[0], dst[1:]...)
+	cmd.Stdin = tarball
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+	return output.String(), err
+}
+
+// saveImageFile exports imageRef through engine into a temporary
+// tarball, for CLIs that only load images from a file. The caller
+// removes the file.
+func saveImageFile(ctx context.Context, engine Engine, imageRef string) (string, error) {
+	tarball, err := engine.SaveImage(ctx, imageRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to export image: %w", err)
+	}
+	defer tarball.Close()
+
+	file, err := os.CreateTemp("", "kudev-image-*.tar")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, tarball); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to export image: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,143 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+// fakeEngine records the calls loaders make instead of the docker CLI.
+type fakeEngine struct {
+	pingErr error
+	nodes   []string
+	// execOutput is returned by Exec, with execErr
+	execOutput string
+	execErr    error
+
+	labels []string
+	execs  []string
+	stdins []string
+}
+
+func (f *fakeEngine) Ping(ctx context.Context) error {
+	return f.pingErr
+}
+
+func (f *fakeEngine) SaveImage(ctx context.Context, ref string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("tarball of " + ref)), nil
+}
+
+func (f *fakeEngine) ContainersWithLabel(ctx context.Context, label string) ([]string, error) {
+	f.labels = append(f.labels, label)
+	return f.nodes, nil
+}
+
+func (f *fakeEngine) Exec(ctx context.Context, container string, cmd []string, stdin io.Reader) (string, error) {
+	f.execs = append(f.execs, container+": "+strings.Join(cmd, " "))
+	if stdin != nil {
+		data, _ := io.ReadAll(stdin)
+		f.stdins = append(f.stdins, string(data))
+	}
+	return f.execOutput, f.execErr
+}
+
+func TestKindLoader_LoadWithEngine(t *testing.T) {
+	// No kind or docker CLI is needed
+	t.Setenv("PATH", t.TempDir())
+	engine := &fakeEngine{nodes: []string{"dev-control-plane", "dev-worker"}}
+
+	err := NewRegistry("kind-dev", &util.MockLogger{}).WithEngine(engine).Load(context.Background(), "myapp:kudev-abc12345")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if strings.Join(engine.labels, ",") != "io.x-k8s.kind.cluster=dev" {
+		t.Errorf("node labels = %v", engine.labels)
+	}
+	wantExecs := []string{
+		"dev-control-plane: ctr --namespace=k8s.io images import --all-platforms --digests -",
+		"dev-worker: ctr --namespace=k8s.io images import --all-platforms --digests -",
+	}
+	if strings.Join(engine.execs, "\n") != strings.Join(wantExecs, "\n") {
+		t.Errorf("execs = %v, want %v", engine.execs, wantExecs)
+	}
+	for _, stdin := range engine.stdins {
+		if stdin != "tarball of myapp:kudev-abc12345" {
+			t.Errorf("import stdin = %q", stdin)
+		}
+	}
+}
+
+func TestKindLoader_RemoveWithEngine(t *testing.T) {
+	engine := &fakeEngine{
+		nodes:      []string{"dev-control-plane"},
+		execOutput: `no such image "myapp:kudev-abc12345"`,
+		execErr:    errors.New("crictl exited with code 1"),
+	}
+
+	loader, err := NewRegistry("kind-dev", &util.MockLogger{}).WithEngine(engine).Remove(context.Background(), []string{"myapp:kudev-abc12345"})
+	if err != nil {
+		t.Fatalf("Remove should skip missing images, got %v", err)
+	}
+	if loader != "kind" {
+		t.Errorf("loader = %q", loader)
+	}
+	if strings.Join(engine.execs, ",") != "dev-control-plane: crictl rmi myapp:kudev-abc12345" {
+		t.Errorf("execs = %v", engine.execs)
+	}
+
+	engine.execOutput, engine.execs = "permission denied", nil
+	if _, err := NewRegistry("kind-dev", &util.MockLogger{}).WithEngine(engine).Remove(context.Background(), []string{"myapp:kudev-abc12345"}); err == nil {
+		t.Error("expected other removal failures to be reported")
+	}
+}
+
+func TestRegistry_CheckWithEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		context string
+		engine  *fakeEngine
+		wantErr string
+	}{
+		{name: "kind cluster running", context: "kind-dev", engine: &fakeEngine{nodes: []string{"dev-control-plane"}}},
+		{name: "kind cluster missing", context: "kind-dev", engine: &fakeEngine{}, wantErr: `kind cluster "dev" not found`},
+		{name: "docker desktop", context: "docker-desktop", engine: &fakeEngine{}},
+		{name: "docker not running", context: "docker-desktop", engine: &fakeEngine{pingErr: errors.New("connection refused")}, wantErr: "docker daemon not reachable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", t.TempDir())
+
+			_, err := NewRegistry(tt.context, &util.MockLogger{}).WithEngine(tt.engine).Check(context.Background())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportImage(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	out, err := importImage(context.Background(), &fakeEngine{}, "myapp:v1", []string{"/bin/sh", "-c", "cat; echo; echo loaded"})
+	if err != nil {
+		t.Fatalf("importImage failed: %v", err)
+	}
+	if out != "tarball of myapp:v1\nloaded\n" {
+		t.Errorf("output = %q", out)
+	}
+}
@@ -4,6 +4,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -14,12 +15,16 @@ import (
 // kindLoader handles image loading for Kind clusters.
 type kindLoader struct {
 	clusterName string
+	engine      Engine
 	logger      logging.LoggerInterface
 }
 
+// kindClusterLabel is the label kind puts on a cluster's node containers.
+const kindClusterLabel = "io.x-k8s.kind.cluster"
+
 // newKindLoader creates a new Kind loader.
 // clusterName is extracted from the context (e.g., "kind-dev" → "dev").
-func newKindLoader(clusterName string, logger logging.LoggerInterface) *kindLoader {
+func newKindLoader(clusterName string, engine Engine, logger logging.LoggerInterface) *kindLoader {
 	// Default to "kind" if no cluster name provided
 	if clusterName == "" {
 		clusterName = "kind"
@@ -27,6 +32,7 @@ func newKindLoader(clusterName string, logger logging.LoggerInterface) *kindLoad
 
 	return &kindLoader{
 		clusterName: clusterName,
+		engine:      engine,
 		logger:      logger,
 	}
 }
@@ -41,8 +47,14 @@ func (k *kindLoader) ClusterName() string {
 	return k.clusterName
 }
 
-// Load loads an image into Kind using `kind load docker-image`.
+// Load loads an image into Kind using `kind load docker-image`, or with
+// an engine by importing it into every node's containerd the way kind
+// does.
 func (k *kindLoader) Load(ctx context.Context, imageRef string) error {
+	if k.engine != nil {
+		return k.loadWithEngine(ctx, imageRef)
+	}
+
 	k.logger.Info("loading image via kind",
 		"image", imageRef,
 		"cluster", k.clusterName,
@@ -85,6 +97,109 @@ func (k *kindLoader) Load(ctx context.Context, imageRef string) error {
 	return nil
 }
 
+// loadWithEngine exports the image through the engine and imports it
+// into the k8s.io namespace of each node container.
+func (k *kindLoader) loadWithEngine(ctx context.Context, imageRef string) error {
+	nodes, err := k.nodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	importCmd := []string{"ctr", "--namespace=" + containerdNamespace, "images", "import", "--all-platforms", "--digests", "-"}
+	for _, node := range nodes {
+		k.logger.Info("loading image into kind node",
+			"image", imageRef,
+			"node", node,
+		)
+		tarball, err := k.engine.SaveImage(ctx, imageRef)
+		if err != nil {
+			return fmt.Errorf("failed to export image %s: %w", imageRef, err)
+		}
+		output, err := k.engine.Exec(ctx, node, importCmd, tarball)
+		tarball.Close()
+		if err != nil {
+			return fmt.Errorf(
+				"kind load failed on node %s\n\n"+
+					"Output: %s\n"+
+					"Error: %w\n\n"+
+					"Troubleshooting:\n"+
+					"  - Ensure Kind cluster exists: kind get clusters\n"+
+					"  - Check image exists: docker images %s",
+				node, strings.TrimSpace(output), err, imageRef,
+			)
+		}
+	}
+
+	k.logger.Info("image loaded to kind cluster successfully",
+		"image", imageRef,
+		"cluster", k.clusterName,
+	)
+	return nil
+}
+
+// nodes returns the node containers of the cluster: found by label
+// through the engine, else with 'kind get nodes'.
+func (k *kindLoader) nodes(ctx context.Context) ([]string, error) {
+	if k.engine == nil {
+		output, err := exec.CommandContext(ctx, "kind", "get", "nodes", "--name", k.clusterName).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes of kind cluster %q: %w", k.clusterName, err)
+		}
+		return strings.Fields(string(output)), nil
+	}
+
+	nodes, err := k.engine.ContainersWithLabel(ctx, kindClusterLabel+"="+k.clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes of kind cluster %q: %w", k.clusterName, err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf(
+			"kind cluster %q not found\n\n"+
+				"  - List clusters: kind get clusters\n"+
+				"  - Create it: kind create cluster --name %s",
+			k.clusterName, k.clusterName,
+		)
+	}
+	return nodes, nil
+}
+
+// Remove deletes images from every node of the Kind cluster with
+// 'crictl rmi' inside the node containers.
+func (k *kindLoader) Remove(ctx context.Context, imageRefs []string) error {
+	nodes, err := k.nodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, node := range nodes {
+		k.logger.Debug("removing images from kind node", "node", node, "count", len(imageRefs))
+		if err := k.removeFromNode(ctx, node, imageRefs); err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", node, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeFromNode runs 'crictl rmi' for every image in node, skipping the
+// images it does not have.
+func (k *kindLoader) removeFromNode(ctx context.Context, node string, imageRefs []string) error {
+	if k.engine == nil {
+		return removeEach(ctx, imageRefs, func(ref string) []string {
+			return []string{"docker", "exec", node, "crictl", "rmi", ref}
+		})
+	}
+
+	var errs []error
+	for _, ref := range imageRefs {
+		output, err := k.engine.Exec(ctx, node, []string{"crictl", "rmi", ref}, nil)
+		if err != nil && !isImageNotFound(output) {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", ref, strings.TrimSpace(output), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // checkKind verifies kind CLI is available.
 func (k *kindLoader) checkKind(ctx context.Context) error {
 	cmd := exec.CommandContext(ctx, "kind", "version")
@@ -110,5 +225,42 @@ func (k *kindLoader) checkKind(ctx context.Context) error {
 	return nil
 }
 
-// Ensure kindLoader implements Loader
-var _ Loader = (*kindLoader)(nil)
+// Check verifies the kind CLI works and the cluster exists. With an
+// engine the CLI is not needed: the daemon must answer and run the
+// cluster's nodes.
+func (k *kindLoader) Check(ctx context.Context) error {
+	if k.engine != nil {
+		if err := checkDocker(ctx, k.engine); err != nil {
+			return err
+		}
+		_, err := k.nodes(ctx)
+		return err
+	}
+
+	if err := k.checkKind(ctx); err != nil {
+		return err
+	}
+
+	output, err := exec.CommandContext(ctx, "kind", "get", "clusters").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list kind clusters: %w", err)
+	}
+	for _, name := range strings.Fields(string(output)) {
+		if name == k.clusterName {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"kind cluster %q not found\n\n"+
+			"  - List clusters: kind get clusters\n"+
+			"  - Create it: kind create cluster --name %s",
+		k.clusterName, k.clusterName,
+	)
+}
+
+// Ensure kindLoader implements Loader and Checker
+var (
+	_ Loader  = (*kindLoader)(nil)
+	_ Checker = (*kindLoader)(nil)
+	_ Remover = (*kindLoader)(nil)
+)
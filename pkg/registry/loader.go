@@ -17,6 +17,7 @@ const (
 	ClusterTypeDockerDesktop ClusterType = "docker-desktop"
 	ClusterTypeMinikube      ClusterType = "minikube"
 	ClusterTypeKind          ClusterType = "kind"
+	ClusterTypeRancher       ClusterType = "rancher-desktop"
 	ClusterTypeUnknown       ClusterType = "unknown"
 )
 
@@ -29,10 +30,19 @@ type Loader interface {
 	Name() string
 }
 
+// Checker is implemented by loaders that can verify up front that
+// loading will work (CLI installed, cluster present), without an image.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
 // Registry orchestrates image loading based on cluster type.
 type Registry struct {
 	kubeContext string
 	logger      logging.LoggerInterface
+
+	// engine, when set, replaces the docker CLI (see WithEngine)
+	engine Engine
 }
 
 // NewRegistry creates a new registry loader.
@@ -40,7 +50,7 @@ type Registry struct {
 func NewRegistry(kubeContext string, logger logging.LoggerInterface) *Registry {
 	return &Registry{
 		kubeContext: kubeContext,
-		logger:      logger,
+		logger:      logging.OrDefault(logger),
 	}
 }
 
@@ -80,17 +90,35 @@ func (r *Registry) Load(ctx context.Context, imageRef string) error {
 	return nil
 }
 
+// Check verifies images can be loaded into the current cluster and
+// returns the name of the loader that would be used.
+func (r *Registry) Check(ctx context.Context) (string, error) {
+	loader, err := r.getLoader(detectClusterType(r.kubeContext))
+	if err != nil {
+		return "", err
+	}
+	if checker, ok := loader.(Checker); ok {
+		if err := checker.Check(ctx); err != nil {
+			return loader.Name(), err
+		}
+	}
+	return loader.Name(), nil
+}
+
 // getLoader returns the appropriate loader for the cluster type.
 func (r *Registry) getLoader(clusterType ClusterType, clusterName string) (Loader, error) {
 	switch clusterType {
 	case ClusterTypeDockerDesktop:
-		return newDockerDesktopLoader(r.logger), nil
+		return newDockerDesktopLoader(r.engine, r.logger), nil
 
 	case ClusterTypeMinikube:
-		return newMinikubeLoader(r.logger), nil
+		return newMinikubeLoader(r.engine, r.logger), nil
 
 	case ClusterTypeKind:
-		return newKindLoader(clusterName, r.logger), nil
+		return newKindLoader(clusterName, r.engine, r.logger), nil
+
+	case ClusterTypeRancher:
+		return newRancherLoader(r.engine, r.logger), nil
 
 	case ClusterTypeUnknown:
 		return nil, fmt.Errorf(
@@ -98,7 +126,8 @@ func (r *Registry) getLoader(clusterType ClusterType, clusterName string) (Loade
 				"Supported clusters:\n"+
 				"  - Docker Desktop (context: docker-desktop)\n"+
 				"  - Minikube (context: minikube)\n"+
-				"  - Kind (context: kind-<cluster-name>)\n\n"+
+				"  - Kind (context: kind-<cluster-name>)\n"+
+				"  - Rancher Desktop (context: rancher-desktop)\n\n"+
 				"Tips:\n"+
 				"  - Check current context: kubectl config current-context\n"+
 				"  - List contexts: kubectl config get-contexts\n"+
@@ -123,6 +152,9 @@ func detectClusterType(kubeContext string) (ClusterType, string) {
 	case strings.Contains(ctx, "minikube"):
 		return ClusterTypeMinikube, ""
 
+	case strings.Contains(ctx, "rancher-desktop"):
+		return ClusterTypeRancher, ""
+
 	case strings.HasPrefix(ctx, "kind-"):
 		// Extract cluster name: "kind-dev" → "dev"
 		clusterName := strings.TrimPrefix(ctx, "kind-")
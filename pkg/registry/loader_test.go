@@ -2,6 +2,11 @@ package registry
 
 import (
 	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/nanaki-93/kudev/test/util"
@@ -25,6 +30,9 @@ func TestDetectClusterType(t *testing.T) {
 		{"kind-production", ClusterTypeKind, "production"},
 		{"Kind-Dev", ClusterTypeKind, "dev"}, // Case insensitive
 
+		{"rancher-desktop", ClusterTypeRancher, ""},
+		{"Rancher-Desktop", ClusterTypeRancher, ""},
+
 		{"unknown-context", ClusterTypeUnknown, ""},
 		{"gke_project_zone_cluster", ClusterTypeUnknown, ""},
 		{"arn:aws:eks:region:account:cluster/name", ClusterTypeUnknown, ""},
@@ -58,6 +66,7 @@ func TestRegistry_GetLoader(t *testing.T) {
 		{"docker-desktop", "docker-desktop", false},
 		{"minikube", "minikube", false},
 		{"kind-dev", "kind", false},
+		{"rancher-desktop", "rancher-desktop", false},
 		{"unknown", "", true},
 	}
 
@@ -88,7 +97,7 @@ func TestRegistry_GetLoader(t *testing.T) {
 
 func TestDockerDesktopLoader_Load(t *testing.T) {
 	logger := &util.MockLogger{}
-	loader := newDockerDesktopLoader(logger)
+	loader := newDockerDesktopLoader(nil, logger)
 
 	// Should always succeed (no-op)
 	err := loader.Load(context.Background(), "myapp:kudev-abc123")
@@ -123,7 +132,7 @@ func TestKindLoader_ClusterName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			loader := newKindLoader(tt.input, logger)
+			loader := newKindLoader(tt.input, nil, logger)
 			if loader.ClusterName() != tt.expected {
 				t.Errorf("ClusterName() = %q, want %q", loader.ClusterName(), tt.expected)
 			}
@@ -145,4 +154,101 @@ func TestLoaderInterface(t *testing.T) {
 	var _ Loader = (*dockerDesktopLoader)(nil)
 	var _ Loader = (*minikubeLoader)(nil)
 	var _ Loader = (*kindLoader)(nil)
+	var _ Loader = (*rancherLoader)(nil)
+}
+
+func TestParseContainerEngine(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		want     string
+	}{
+		{"containerd", `{"version":10,"containerEngine":{"name":"containerd"}}`, "containerd"},
+		{"moby", `{"containerEngine":{"name":"moby","allowedImages":{}}}`, "moby"},
+		{"missing engine", `{"kubernetes":{"enabled":true}}`, "containerd"},
+		{"invalid JSON", `not json`, "containerd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseContainerEngine([]byte(tt.settings)); got != tt.want {
+				t.Errorf("parseContainerEngine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipe(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	ctx := context.Background()
+
+	out, err := pipe(ctx, []string{"sh", "-c", "echo image-data"}, []string{"sh", "-c", "cat; echo loaded"})
+	if err != nil {
+		t.Fatalf("pipe failed: %v", err)
+	}
+	if out != "image-data\nloaded\n" {
+		t.Errorf("output = %q", out)
+	}
+
+	if _, err := pipe(ctx, []string{"yes"}, []string{"sh", "-c", "exit 3"}); err == nil {
+		t.Error("expected error when the importer fails")
+	}
+}
+
+// fakeCLI puts an executable name on an otherwise empty PATH that
+// prints output.
+func fakeCLI(t *testing.T, name, output string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake CLIs are shell scripts")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestRegistry_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		context    string
+		cli        string
+		wantLoader string
+		wantErr    string
+	}{
+		{name: "kind cluster exists", context: "kind-dev", cli: "kind", wantLoader: "kind"},
+		{name: "kind cluster missing", context: "kind-other", cli: "kind", wantLoader: "kind", wantErr: `kind cluster "other" not found`},
+		{name: "kind not installed", context: "kind-dev", wantLoader: "kind", wantErr: "kind CLI not found"},
+		{name: "minikube", context: "minikube", cli: "minikube", wantLoader: "minikube"},
+		{name: "docker not running", context: "docker-desktop", wantLoader: "docker-desktop", wantErr: "docker daemon not reachable"},
+		{name: "unknown cluster", context: "gke_prod", wantErr: "unknown cluster type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cli != "" {
+				fakeCLI(t, tt.cli, "dev\\nkind\\n")
+			} else {
+				t.Setenv("PATH", t.TempDir())
+			}
+
+			loader, err := NewRegistry(tt.context, &util.MockLogger{}).Check(context.Background())
+			if loader != tt.wantLoader {
+				t.Errorf("Check() loader = %q, want %q", loader, tt.wantLoader)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
 }
@@ -5,6 +5,7 @@ package registry
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -13,12 +14,13 @@ import (
 
 // minikubeLoader handles image loading for Minikube.
 type minikubeLoader struct {
+	engine Engine
 	logger logging.LoggerInterface
 }
 
 // newMinikubeLoader creates a new Minikube loader.
-func newMinikubeLoader(logger logging.LoggerInterface) *minikubeLoader {
-	return &minikubeLoader{logger: logger}
+func newMinikubeLoader(engine Engine, logger logging.LoggerInterface) *minikubeLoader {
+	return &minikubeLoader{engine: engine, logger: logger}
 }
 
 // Name returns the loader identifier.
@@ -26,7 +28,9 @@ func (m *minikubeLoader) Name() string {
 	return "minikube"
 }
 
-// Load loads an image into Minikube using `minikube image load`.
+// Load loads an image into Minikube using `minikube image load`. With an
+// engine the image is exported through it into a tarball first, as
+// minikube would otherwise read it with its own docker client.
 func (m *minikubeLoader) Load(ctx context.Context, imageRef string) error {
 	m.logger.Info("loading image via minikube",
 		"image", imageRef,
@@ -38,8 +42,18 @@ func (m *minikubeLoader) Load(ctx context.Context, imageRef string) error {
 		return err
 	}
 
+	source := imageRef
+	if m.engine != nil {
+		file, err := saveImageFile(ctx, m.engine, imageRef)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(file)
+		source = file
+	}
+
 	// Run minikube image load
-	cmd := exec.CommandContext(ctx, "minikube", "image", "load", imageRef)
+	cmd := exec.CommandContext(ctx, "minikube", "image", "load", source)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -87,5 +101,24 @@ func (m *minikubeLoader) checkMinikube(ctx context.Context) error {
 	return nil
 }
 
-// Ensure minikubeLoader implements Loader
-var _ Loader = (*minikubeLoader)(nil)
+// Remove deletes images from the Minikube node with 'minikube image rm'.
+func (m *minikubeLoader) Remove(ctx context.Context, imageRefs []string) error {
+	if err := m.checkMinikube(ctx); err != nil {
+		return err
+	}
+	return removeEach(ctx, imageRefs, func(ref string) []string {
+		return []string{"minikube", "image", "rm", ref}
+	})
+}
+
+// Check verifies the minikube CLI works.
+func (m *minikubeLoader) Check(ctx context.Context) error {
+	return m.checkMinikube(ctx)
+}
+
+// Ensure minikubeLoader implements Loader and Checker
+var (
+	_ Loader  = (*minikubeLoader)(nil)
+	_ Checker = (*minikubeLoader)(nil)
+	_ Remover = (*minikubeLoader)(nil)
+)
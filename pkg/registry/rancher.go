@@ -0,0 +1,205 @@
+// pkg/registry/rancher.go
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// Container engines of Rancher Desktop (Preferences → Container Engine).
+const (
+	rancherEngineContainerd = "containerd"
+	rancherEngineMoby       = "moby"
+)
+
+// containerdNamespace is the containerd namespace the kubelet pulls from.
+const containerdNamespace = "k8s.io"
+
+// rancherLoader handles image loading for Rancher Desktop.
+//
+// With the dockerd (moby) engine the cluster shares the Docker daemon, as
+// with Docker Desktop. With the containerd engine the image is exported
+// with 'docker save' (or through the Engine, when set) and imported into
+// containerd's k8s.io namespace via 'nerdctl load' or, if nerdctl is
+// missing, 'ctr images import'.
+type rancherLoader struct {
+	engine Engine
+	logger logging.LoggerInterface
+}
+
+// newRancherLoader creates a new Rancher Desktop loader.
+func newRancherLoader(engine Engine, logger logging.LoggerInterface) *rancherLoader {
+	return &rancherLoader{engine: engine, logger: logger}
+}
+
+// Name returns the loader identifier.
+func (r *rancherLoader) Name() string {
+	return "rancher-desktop"
+}
+
+// Load loads an image into Rancher Desktop's Kubernetes.
+func (r *rancherLoader) Load(ctx context.Context, imageRef string) error {
+	if r.containerEngine(ctx) == rancherEngineMoby {
+		r.logger.Info("image available to Rancher Desktop automatically",
+			"image", imageRef,
+			"reason", "moby engine shares daemon with K8s",
+		)
+		return nil
+	}
+
+	importArgs, err := importCommand()
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("loading image via containerd",
+		"image", imageRef,
+		"command", strings.Join(importArgs, " "),
+	)
+
+	output, err := importImage(ctx, r.engine, imageRef, importArgs)
+	if err != nil {
+		return fmt.Errorf(
+			"rancher desktop image load failed\n\n"+
+				"Command: docker save %s | %s\n"+
+				"Output: %s\n"+
+				"Error: %w\n\n"+
+				"Troubleshooting:\n"+
+				"  - Ensure Rancher Desktop is running with Kubernetes enabled\n"+
+				"  - Check image exists: docker images %s\n"+
+				"  - Check the cluster sees it: nerdctl --namespace k8s.io images",
+			imageRef, strings.Join(importArgs, " "),
+			strings.TrimSpace(output), err, imageRef,
+		)
+	}
+
+	r.logger.Info("image loaded to rancher desktop successfully",
+		"image", imageRef,
+	)
+
+	return nil
+}
+
+// Remove deletes images from containerd's k8s.io namespace. With the
+// moby engine the cluster uses the local daemon's images, so there is
+// nothing to do.
+func (r *rancherLoader) Remove(ctx context.Context, imageRefs []string) error {
+	if r.containerEngine(ctx) == rancherEngineMoby {
+		return nil
+	}
+
+	if _, err := exec.LookPath("nerdctl"); err == nil {
+		return removeEach(ctx, imageRefs, func(ref string) []string {
+			return []string{"nerdctl", "--namespace", containerdNamespace, "rmi", ref}
+		})
+	}
+	if _, err := exec.LookPath("ctr"); err == nil {
+		return removeEach(ctx, imageRefs, func(ref string) []string {
+			return []string{"ctr", "--namespace", containerdNamespace, "images", "rm", qualifiedRef(ref)}
+		})
+	}
+	return fmt.Errorf("neither nerdctl nor ctr found; add Rancher Desktop's ~/.rd/bin to your PATH")
+}
+
+// containerEngine asks rdctl for the configured engine. Without rdctl
+// (or on unreadable output) containerd, the Rancher Desktop default, is assumed.
+func (r *rancherLoader) containerEngine(ctx context.Context) string {
+	output, err := exec.CommandContext(ctx, "rdctl", "list-settings").Output()
+	if err != nil {
+		r.logger.Debug("rdctl not available, assuming containerd engine", "error", err)
+		return rancherEngineContainerd
+	}
+
+	engine := parseContainerEngine(output)
+	r.logger.Debug("rancher desktop container engine", "engine", engine)
+	return engine
+}
+
+// parseContainerEngine reads containerEngine.name from 'rdctl list-settings'.
+func parseContainerEngine(settings []byte) string {
+	var parsed struct {
+		ContainerEngine struct {
+			Name string `json:"name"`
+		} `json:"containerEngine"`
+	}
+	if err := json.Unmarshal(settings, &parsed); err != nil || parsed.ContainerEngine.Name == "" {
+		return rancherEngineContainerd
+	}
+	return parsed.ContainerEngine.Name
+}
+
+// importCommand returns the command that reads an image tarball from stdin
+// into the k8s.io namespace: nerdctl if available, else ctr.
+func importCommand() ([]string, error) {
+	if _, err := exec.LookPath("nerdctl"); err == nil {
+		return []string{"nerdctl", "--namespace", containerdNamespace, "load"}, nil
+	}
+	if _, err := exec.LookPath("ctr"); err == nil {
+		return []string{"ctr", "--namespace", containerdNamespace, "images", "import", "-"}, nil
+	}
+	return nil, fmt.Errorf(
+		"neither nerdctl nor ctr found\n\n" +
+			"Rancher Desktop's containerd engine needs one of them to load images:\n" +
+			"  - nerdctl ships with Rancher Desktop (~/.rd/bin); add it to your PATH\n" +
+			"  - Or switch the container engine to dockerd (moby) in Preferences",
+	)
+}
+
+// pipe runs src | dst and returns src's stderr followed by dst's output.
+func pipe(ctx context.Context, src, dst []string) (string, error) {
+	var srcErr, dstOut bytes.Buffer
+	output := func() string { return srcErr.String() + dstOut.String() }
+
+	save := exec.CommandContext(ctx, src[0], src[1:]...)
+	load := exec.CommandContext(ctx, dst[0], dst[1:]...)
+	save.Stderr = &srcErr
+	load.Stdout = &dstOut
+	load.Stderr = &dstOut
+
+	stdout, err := save.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	load.Stdin = stdout
+
+	if err := save.Start(); err != nil {
+		return "", fmt.Errorf("failed to start %s: %w", src[0], err)
+	}
+	if err := load.Run(); err != nil {
+		// Nobody reads the pipe anymore; don't let src block on it
+		save.Process.Kill()
+		save.Wait()
+		return output(), err
+	}
+	if err := save.Wait(); err != nil {
+		return output(), err
+	}
+	return output(), nil
+}
+
+// Check verifies the tools Load needs for the configured engine are
+// available: the docker daemon, plus nerdctl or ctr for containerd.
+func (r *rancherLoader) Check(ctx context.Context) error {
+	if err := checkDocker(ctx, r.engine); err != nil {
+		return err
+	}
+	if r.containerEngine(ctx) == rancherEngineMoby {
+		return nil
+	}
+	_, err := importCommand()
+	return err
+}
+
+// Ensure rancherLoader implements Loader and Checker
+var (
+	_ Loader  = (*rancherLoader)(nil)
+	_ Checker = (*rancherLoader)(nil)
+	_ Remover = (*rancherLoader)(nil)
+)
@@ -0,0 +1,77 @@
+// pkg/registry/remove.go
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Remover is implemented by loaders that can delete loaded images from
+// the cluster again.
+type Remover interface {
+	// Remove deletes the images from the cluster's nodes. Images that
+	// are not there are skipped.
+	Remove(ctx context.Context, imageRefs []string) error
+}
+
+// Remove deletes images from the current cluster and returns the name of
+// the loader used. Clusters sharing the local Docker daemon need nothing
+// beyond removing the images locally.
+func (r *Registry) Remove(ctx context.Context, imageRefs []string) (string, error) {
+	loader, err := r.getLoader(detectClusterType(r.kubeContext))
+	if err != nil {
+		return "", err
+	}
+	remover, ok := loader.(Remover)
+	if !ok {
+		return loader.Name(), fmt.Errorf("removing images is not supported for %s clusters", loader.Name())
+	}
+	if len(imageRefs) == 0 {
+		return loader.Name(), nil
+	}
+
+	r.logger.Info("removing images from cluster",
+		"count", len(imageRefs),
+		"loader", loader.Name(),
+	)
+	return loader.Name(), remover.Remove(ctx, imageRefs)
+}
+
+// removeEach runs the command built by argv for every image, skipping
+// images the cluster does not have. The failures are reported together.
+func removeEach(ctx context.Context, imageRefs []string, argv func(ref string) []string) error {
+	var errs []error
+	for _, ref := range imageRefs {
+		args := argv(ref)
+		output, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+		if err != nil && !isImageNotFound(string(output)) {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", ref, strings.TrimSpace(string(output)), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isImageNotFound reports whether a removal failed only because the
+// image was already gone.
+func isImageNotFound(output string) bool {
+	output = strings.ToLower(output)
+	return strings.Contains(output, "not found") || strings.Contains(output, "no such image")
+}
+
+// qualifiedRef expands a short image reference the way containerd stores
+// it: "myapp:tag" becomes "docker.io/library/myapp:tag". Tools like ctr
+// do not resolve short names themselves.
+func qualifiedRef(ref string) string {
+	i := strings.Index(ref, "/")
+	if i == -1 {
+		return "docker.io/library/" + ref
+	}
+	if domain := ref[:i]; strings.ContainsAny(domain, ".:") || domain == "localhost" {
+		return ref
+	}
+	return "docker.io/" + ref
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestQualifiedRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"myapp:kudev-a1b2c3d4", "docker.io/library/myapp:kudev-a1b2c3d4"},
+		{"team/myapp:kudev-a1b2c3d4", "docker.io/team/myapp:kudev-a1b2c3d4"},
+		{"ghcr.io/team/myapp:kudev-a1b2c3d4", "ghcr.io/team/myapp:kudev-a1b2c3d4"},
+		{"localhost:5000/myapp:kudev-a1b2c3d4", "localhost:5000/myapp:kudev-a1b2c3d4"},
+		{"localhost/myapp:kudev-a1b2c3d4", "localhost/myapp:kudev-a1b2c3d4"},
+	}
+
+	for _, tt := range tests {
+		if got := qualifiedRef(tt.ref); got != tt.want {
+			t.Errorf("qualifiedRef(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestIsImageNotFound(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{`E1015 rpc error: code = NotFound desc = no such image "myapp:kudev-a1b2c3d4" present`, true},
+		{"Error: No such image: myapp:kudev-a1b2c3d4", true},
+		{"ctr: image \"docker.io/library/myapp:kudev-a1b2c3d4\": not found", true},
+		{"Error response from daemon: conflict: unable to remove repository reference (must force) - container abc is using its referenced image", false},
+	}
+
+	for _, tt := range tests {
+		if got := isImageNotFound(tt.output); got != tt.want {
+			t.Errorf("isImageNotFound(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	tests := []struct {
+		name       string
+		context    string
+		cli        string
+		wantLoader string
+		wantErr    string
+	}{
+		{name: "docker desktop shares the daemon", context: "docker-desktop", wantLoader: "docker-desktop"},
+		{name: "minikube", context: "minikube", cli: "minikube", wantLoader: "minikube"},
+		{name: "minikube not installed", context: "minikube", wantLoader: "minikube", wantErr: "minikube CLI not found"},
+		{name: "unknown cluster", context: "gke_prod", wantErr: "unknown cluster type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cli != "" {
+				fakeCLI(t, tt.cli, "")
+			} else {
+				t.Setenv("PATH", t.TempDir())
+			}
+
+			loader, err := NewRegistry(tt.context, &util.MockLogger{}).Remove(context.Background(), []string{"myapp:kudev-a1b2c3d4"})
+			if loader != tt.wantLoader {
+				t.Errorf("Remove() loader = %q, want %q", loader, tt.wantLoader)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Remove() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Remove() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// pkg/snapshot/snapshot.go
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// SnapshotVersion is the current snapshot file format version.
+const SnapshotVersion = "kudev.io/snapshot-v1"
+
+// Snapshot captures all kudev-managed resources of an application.
+type Snapshot struct {
+	// Version identifies the snapshot file format.
+	Version string `json:"version"`
+
+	// AppName is the application the snapshot was taken from.
+	AppName string `json:"appName"`
+
+	// Namespace is the namespace the snapshot was taken from.
+	Namespace string `json:"namespace"`
+
+	// CreatedAt is when the snapshot was taken.
+	CreatedAt time.Time `json:"createdAt"`
+
+	Deployments []appsv1.Deployment `json:"deployments,omitempty"`
+	Services    []corev1.Service    `json:"services,omitempty"`
+	ConfigMaps  []corev1.ConfigMap  `json:"configMaps,omitempty"`
+	Secrets     []corev1.Secret     `json:"secrets,omitempty"`
+}
+
+// Count returns the total number of captured objects.
+func (s *Snapshot) Count() int {
+	return len(s.Deployments) + len(s.Services) + len(s.ConfigMaps) + len(s.Secrets)
+}
+
+// Manager captures and restores snapshots.
+type Manager struct {
+	clientset kubernetes.Interface
+	logger    logging.LoggerInterface
+}
+
+// NewManager creates a new snapshot manager.
+func NewManager(clientset kubernetes.Interface, logger logging.LoggerInterface) *Manager {
+	return &Manager{
+		clientset: clientset,
+		logger:    logging.OrDefault(logger),
+	}
+}
+
+// Capture lists all resources labelled app=<appName>,managed-by=kudev.
+func (m *Manager) Capture(ctx context.Context, appName, namespace string) (*Snapshot, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		"app":        appName,
+		"managed-by": "kudev",
+	}).String()
+	listOpts := metav1.ListOptions{LabelSelector: selector}
+
+	snap := &Snapshot{
+		Version:   SnapshotVersion,
+		AppName:   appName,
+		Namespace: namespace,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	deployments, err := m.clientset.AppsV1().Deployments(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		cleanObjectMeta(&d.ObjectMeta)
+		d.Status = appsv1.DeploymentStatus{}
+		snap.Deployments = append(snap.Deployments, d)
+	}
+
+	services, err := m.clientset.CoreV1().Services(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	for _, s := range services.Items {
+		cleanObjectMeta(&s.ObjectMeta)
+		// ClusterIPs are allocated by the cluster and cannot be reused reliably
+		s.Spec.ClusterIP = ""
+		s.Spec.ClusterIPs = nil
+		s.Status = corev1.ServiceStatus{}
+		snap.Services = append(snap.Services, s)
+	}
+
+	configMaps, err := m.clientset.CoreV1().ConfigMaps(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configmaps: %w", err)
+	}
+	for _, cm := range configMaps.Items {
+		cleanObjectMeta(&cm.ObjectMeta)
+		snap.ConfigMaps = append(snap.ConfigMaps, cm)
+	}
+
+	secrets, err := m.clientset.CoreV1().Secrets(namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, sec := range secrets.Items {
+		cleanObjectMeta(&sec.ObjectMeta)
+		snap.Secrets = append(snap.Secrets, sec)
+	}
+
+	if snap.Count() == 0 {
+		return nil, fmt.Errorf("no kudev-managed resources found for %s/%s", namespace, appName)
+	}
+
+	m.logger.Info("snapshot captured",
+		"app", appName,
+		"namespace", namespace,
+		"objects", snap.Count(),
+	)
+
+	return snap, nil
+}
+
+// Restore creates or updates every object in the snapshot.
+// Objects are restored into the namespace recorded in the snapshot.
+func (m *Manager) Restore(ctx context.Context, snap *Snapshot) error {
+	if snap.Version != SnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %q (expected %q)", snap.Version, SnapshotVersion)
+	}
+
+	ns := snap.Namespace
+
+	for i := range snap.ConfigMaps {
+		cm := &snap.ConfigMaps[i]
+		client := m.clientset.CoreV1().ConfigMaps(ns)
+		if _, err := client.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to restore configmap %s: %w", cm.Name, err)
+			}
+			existing, err := client.Get(ctx, cm.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get configmap %s: %w", cm.Name, err)
+			}
+			cm.ResourceVersion = existing.ResourceVersion
+			if _, err := client.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to update configmap %s: %w", cm.Name, err)
+			}
+		}
+		m.logger.Info("configmap restored", "name", cm.Name, "namespace", ns)
+	}
+
+	for i := range snap.Secrets {
+		sec := &snap.Secrets[i]
+		client := m.clientset.CoreV1().Secrets(ns)
+		if _, err := client.Create(ctx, sec, metav1.CreateOptions{}); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to restore secret %s: %w", sec.Name, err)
+			}
+			existing, err := client.Get(ctx, sec.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get secret %s: %w", sec.Name, err)
+			}
+			sec.ResourceVersion = existing.ResourceVersion
+			if _, err := client.Update(ctx, sec, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to update secret %s: %w", sec.Name, err)
+			}
+		}
+		m.logger.Info("secret restored", "name", sec.Name, "namespace", ns)
+	}
+
+	for i := range snap.Deployments {
+		d := &snap.Deployments[i]
+		client := m.clientset.AppsV1().Deployments(ns)
+		if _, err := client.Create(ctx, d, metav1.CreateOptions{}); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to restore deployment %s: %w", d.Name, err)
+			}
+			existing, err := client.Get(ctx, d.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get deployment %s: %w", d.Name, err)
+			}
+			d.ResourceVersion = existing.ResourceVersion
+			if _, err := client.Update(ctx, d, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to update deployment %s: %w", d.Name, err)
+			}
+		}
+		m.logger.Info("deployment restored", "name", d.Name, "namespace", ns)
+	}
+
+	for i := range snap.Services {
+		s := &snap.Services[i]
+		client := m.clientset.CoreV1().Services(ns)
+		if _, err := client.Create(ctx, s, metav1.CreateOptions{}); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to restore service %s: %w", s.Name, err)
+			}
+			existing, err := client.Get(ctx, s.Name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get service %s: %w", s.Name, err)
+			}
+			// CRITICAL: Preserve ClusterIP (cannot be changed)
+			s.Spec.ClusterIP = existing.Spec.ClusterIP
+			s.Spec.ClusterIPs = existing.Spec.ClusterIPs
+			s.ResourceVersion = existing.ResourceVersion
+			if _, err := client.Update(ctx, s, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to update service %s: %w", s.Name, err)
+			}
+		}
+		m.logger.Info("service restored", "name", s.Name, "namespace", ns)
+	}
+
+	return nil
+}
+
+// cleanObjectMeta strips server-populated fields so objects can be re-created.
+func cleanObjectMeta(meta *metav1.ObjectMeta) {
+	meta.ResourceVersion = ""
+	meta.UID = ""
+	meta.CreationTimestamp = metav1.Time{}
+	meta.Generation = 0
+	meta.ManagedFields = nil
+	meta.OwnerReferences = nil
+}
+
+// Save writes the snapshot as YAML, creating parent directories (private
+// to the user) if needed.
+func Save(snap *Snapshot, path string) error {
+	data, err := yaml.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	// Snapshots may contain secrets - keep them private
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load reads a snapshot file written by Save.
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+
+	snap := &Snapshot{}
+	if err := yaml.Unmarshal(data, snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// DefaultPath returns the default snapshot location in the user cache
+// directory, outside the project so captured Secrets can't be committed:
+//
+//	<user cache dir>/kudev/snapshots/<app>-<timestamp>.yaml
+func DefaultPath(appName string, now time.Time) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.yaml", appName, now.UTC().Format("20060102-150405"))
+	return filepath.Join(cacheDir, "kudev", "snapshots", name), nil
+}
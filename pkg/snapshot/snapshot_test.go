@@ -0,0 +1,136 @@
+package snapshot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func kudevLabels() map[string]string {
+	return map[string]string{"app": "test-app", "managed-by": "kudev"}
+}
+
+func TestCaptureAndRestore(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-app",
+			Namespace:       "default",
+			Labels:          kudevLabels(),
+			ResourceVersion: "42",
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app",
+			Namespace: "default",
+			Labels:    kudevLabels(),
+		},
+		Spec: corev1.ServiceSpec{ClusterIP: "10.0.0.1"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-app-secret",
+			Namespace: "default",
+			Labels:    kudevLabels(),
+		},
+	}
+	// Not managed by kudev - must be ignored
+	other := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test-app"},
+		},
+	}
+
+	fakeClient := fake.NewSimpleClientset(deployment, service, secret, other)
+	manager := NewManager(fakeClient, &util.MockLogger{})
+	ctx := context.Background()
+
+	snap, err := manager.Capture(ctx, "test-app", "default")
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+
+	if snap.Count() != 3 {
+		t.Errorf("Count() = %d, want 3", snap.Count())
+	}
+	if snap.Deployments[0].ResourceVersion != "" {
+		t.Error("resourceVersion should be stripped")
+	}
+	if snap.Services[0].Spec.ClusterIP != "" {
+		t.Error("clusterIP should be stripped")
+	}
+
+	// Round-trip through disk
+	path := filepath.Join(t.TempDir(), "snap.yaml")
+	if err := Save(snap, path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Restore into an empty cluster
+	emptyClient := fake.NewSimpleClientset()
+	if err := NewManager(emptyClient, &util.MockLogger{}).Restore(ctx, loaded); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if _, err := emptyClient.AppsV1().Deployments("default").Get(ctx, "test-app", metav1.GetOptions{}); err != nil {
+		t.Errorf("deployment not restored: %v", err)
+	}
+	if _, err := emptyClient.CoreV1().Secrets("default").Get(ctx, "test-app-secret", metav1.GetOptions{}); err != nil {
+		t.Errorf("secret not restored: %v", err)
+	}
+
+	// Restoring again over existing objects must succeed
+	if err := NewManager(fakeClient, &util.MockLogger{}).Restore(ctx, loaded); err != nil {
+		t.Fatalf("Restore over existing objects failed: %v", err)
+	}
+}
+
+func TestCapture_NothingFound(t *testing.T) {
+	manager := NewManager(fake.NewSimpleClientset(), &util.MockLogger{})
+
+	if _, err := manager.Capture(context.Background(), "missing", "default"); err == nil {
+		t.Error("expected error when nothing is managed by kudev")
+	}
+}
+
+func TestRestore_UnsupportedVersion(t *testing.T) {
+	manager := NewManager(fake.NewSimpleClientset(), &util.MockLogger{})
+
+	err := manager.Restore(context.Background(), &Snapshot{Version: "v0"})
+	if err == nil {
+		t.Error("expected error for unsupported version")
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	if dir, _ := os.UserCacheDir(); dir != cacheDir {
+		t.Skip("user cache directory is not XDG_CACHE_HOME on this platform")
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err := DefaultPath("myapp", now)
+	if err != nil {
+		t.Fatalf("DefaultPath failed: %v", err)
+	}
+	want := filepath.Join(cacheDir, "kudev", "snapshots", "myapp-20240102-030405.yaml")
+	if got != want {
+		t.Errorf("DefaultPath() = %q, want %q", got, want)
+	}
+}
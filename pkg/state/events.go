@@ -0,0 +1,63 @@
+package state
+
+import "time"
+
+// MaxEvents caps the number of events kept in the state file.
+// Older events are dropped first.
+const MaxEvents = 1000
+
+// EventType classifies a recorded event.
+type EventType string
+
+const (
+	// EventDeploy is a successful build + deploy cycle.
+	EventDeploy EventType = "deploy"
+
+	// EventFailure is a cycle that failed at some stage.
+	EventFailure EventType = "failure"
+
+	// EventRollback is a return to a previously deployed image.
+	EventRollback EventType = "rollback"
+)
+
+// Event is a single entry in the project history.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+
+	// Stage is where a failure happened: hash, tag, build, load or deploy.
+	Stage string `json:"stage,omitempty"`
+
+	Hash  string `json:"hash,omitempty"`
+	Image string `json:"image,omitempty"`
+
+	// Digest is the registry digest of a pre-built image.
+	Digest string `json:"digest,omitempty"`
+
+	// ImageID is the local image ID (sha256:...) of a built image.
+	ImageID string `json:"imageId,omitempty"`
+
+	// DurationMs is the cycle time from change detection to completion.
+	DurationMs int64 `json:"durationMs,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
+
+// Duration returns the cycle time as a time.Duration.
+func (e Event) Duration() time.Duration {
+	return time.Duration(e.DurationMs) * time.Millisecond
+}
+
+// Record appends an event to the state file, trimming old entries.
+func (s *Store) Record(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	return s.Update(func(st *State) error {
+		st.Events = append(st.Events, e)
+		if len(st.Events) > MaxEvents {
+			st.Events = st.Events[len(st.Events)-MaxEvents:]
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+// Package state persists per-project kudev state under .kudev/.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StateVersion identifies the state file format.
+const StateVersion = 1
+
+// FileName is the state file name inside the .kudev directory.
+const FileName = "state.json"
+
+// State is the content of the project state file.
+type State struct {
+	Version int     `json:"version"`
+	Events  []Event `json:"events,omitempty"`
+
+	// LocalPort is the port allocated for `localPort: auto`
+	LocalPort int32 `json:"localPort,omitempty"`
+
+	// DebugAddr is the pprof address of a running 'kudev watch/up --pprof',
+	// read by 'kudev debug-dump'
+	DebugAddr string `json:"debugAddr,omitempty"`
+
+	// PortSubstitutions are the busy local ports a running 'kudev up/watch'
+	// forwards on another port instead, shown by 'kudev status'
+	PortSubstitutions []PortSubstitution `json:"portSubstitutions,omitempty"`
+}
+
+// PortSubstitution is a configured local port replaced by a free one.
+type PortSubstitution struct {
+	Configured int32 `json:"configured"`
+	Actual     int32 `json:"actual"`
+}
+
+// Store reads and writes the project state file.
+// It is safe for concurrent use within a single process.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store for the given project root.
+//
+// The state file lives at <projectRoot>/.kudev/state.json.
+func NewStore(projectRoot string) *Store {
+	return &Store{
+		path: filepath.Join(projectRoot, ".kudev", FileName),
+	}
+}
+
+// Path returns the state file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the state file.
+// A missing file yields an empty state, not an error.
+func (s *Store) Load() (*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Update loads the state, applies fn and writes the result back.
+// Nothing is written if fn returns an error.
+func (s *Store) Update(fn func(st *State) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+	return s.save(st)
+}
+
+func (s *Store) load() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{Version: StateVersion}, nil
+		}
+		return nil, fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+
+	st := &State{}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	if st.Version == 0 {
+		st.Version = StateVersion
+	}
+	return st, nil
+}
+
+// save writes atomically (temp file + rename) so a crash mid-write
+// never leaves a truncated state file behind.
+func (s *Store) save(st *State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, FileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_LoadMissingFile(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if st.Version != StateVersion {
+		t.Errorf("Version = %d, want %d", st.Version, StateVersion)
+	}
+	if len(st.Events) != 0 {
+		t.Errorf("expected no events, got %d", len(st.Events))
+	}
+}
+
+func TestStore_RecordAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	events := []Event{
+		{Type: EventDeploy, Hash: "abcd1234", DurationMs: 1500},
+		{Type: EventFailure, Stage: "build", Message: "exit status 1"},
+	}
+	for _, e := range events {
+		if err := store.Record(e); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".kudev", FileName)); err != nil {
+		t.Fatalf("state file not created: %v", err)
+	}
+
+	// Fresh store reads from disk
+	st, err := NewStore(dir).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(st.Events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(st.Events))
+	}
+	if st.Events[0].Time.IsZero() {
+		t.Error("Record should set Time when empty")
+	}
+	if st.Events[0].Duration() != 1500*time.Millisecond {
+		t.Errorf("Duration = %v, want 1.5s", st.Events[0].Duration())
+	}
+	if st.Events[1].Stage != "build" {
+		t.Errorf("Stage = %q, want build", st.Events[1].Stage)
+	}
+}
+
+func TestStore_RecordTrimsOldEvents(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	err := store.Update(func(st *State) error {
+		for i := 0; i < MaxEvents; i++ {
+			st.Events = append(st.Events, Event{Type: EventDeploy, Message: "old"})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if err := store.Record(Event{Type: EventFailure, Message: "new"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	st, _ := store.Load()
+	if len(st.Events) != MaxEvents {
+		t.Fatalf("expected %d events, got %d", MaxEvents, len(st.Events))
+	}
+	if st.Events[len(st.Events)-1].Message != "new" {
+		t.Error("newest event should be kept")
+	}
+}
+
+func TestStore_LoadCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	if err := os.MkdirAll(filepath.Dir(store.Path()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.Path(), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load(); err == nil {
+		t.Error("expected error for corrupt state file")
+	}
+}
+
+func TestStore_PortSubstitutions(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	subs := []PortSubstitution{{Configured: 8080, Actual: 8081}}
+	if err := store.Update(func(st *State) error {
+		st.PortSubstitutions = subs
+		return nil
+	}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(st.PortSubstitutions) != 1 || st.PortSubstitutions[0] != subs[0] {
+		t.Errorf("PortSubstitutions = %v, want %v", st.PortSubstitutions, subs)
+	}
+}
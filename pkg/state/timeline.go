@@ -0,0 +1,94 @@
+package state
+
+import "time"
+
+// Streak is a run of consecutive failures.
+type Streak struct {
+	Start time.Time
+	End   time.Time
+	Count int
+}
+
+// Timeline summarizes one day of events.
+type Timeline struct {
+	Day    time.Time
+	Events []Event
+
+	Deploys   int
+	Failures  int
+	Rollbacks int
+
+	// Streaks lists failure runs of two or more, oldest first.
+	Streaks []Streak
+
+	// AvgCycle is the mean duration of successful deploys.
+	AvgCycle time.Duration
+}
+
+// LongestStreak returns the longest failure streak (zero value if none).
+func (t *Timeline) LongestStreak() Streak {
+	var longest Streak
+	for _, s := range t.Streaks {
+		if s.Count > longest.Count {
+			longest = s
+		}
+	}
+	return longest
+}
+
+// BuildTimeline selects the events that happened on day (in day's location)
+// and computes the session summary.
+func BuildTimeline(events []Event, day time.Time) *Timeline {
+	loc := day.Location()
+	y, m, d := day.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 1)
+
+	t := &Timeline{Day: start}
+
+	var cycleTotal time.Duration
+	var cycles int
+	var current Streak
+
+	closeStreak := func() {
+		if current.Count >= 2 {
+			t.Streaks = append(t.Streaks, current)
+		}
+		current = Streak{}
+	}
+
+	for _, e := range events {
+		if e.Time.Before(start) || !e.Time.Before(end) {
+			continue
+		}
+		t.Events = append(t.Events, e)
+
+		switch e.Type {
+		case EventFailure:
+			t.Failures++
+			if current.Count == 0 {
+				current.Start = e.Time
+			}
+			current.End = e.Time
+			current.Count++
+
+		case EventDeploy:
+			t.Deploys++
+			if e.DurationMs > 0 {
+				cycleTotal += e.Duration()
+				cycles++
+			}
+			closeStreak()
+
+		case EventRollback:
+			t.Rollbacks++
+			closeStreak()
+		}
+	}
+	closeStreak()
+
+	if cycles > 0 {
+		t.AvgCycle = cycleTotal / time.Duration(cycles)
+	}
+	return t
+}
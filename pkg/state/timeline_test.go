@@ -0,0 +1,60 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+
+	events := []Event{
+		{Time: day.Add(-time.Hour), Type: EventFailure}, // previous day
+		{Time: at(9, 0), Type: EventDeploy, DurationMs: 10000},
+		{Time: at(9, 5), Type: EventFailure, Stage: "build"},
+		{Time: at(9, 6), Type: EventFailure, Stage: "build"},
+		{Time: at(9, 8), Type: EventFailure, Stage: "deploy"},
+		{Time: at(9, 10), Type: EventDeploy, DurationMs: 20000},
+		{Time: at(10, 0), Type: EventFailure, Stage: "build"},
+		{Time: at(10, 1), Type: EventRollback},
+		{Time: at(11, 0), Type: EventFailure},
+		{Time: at(11, 1), Type: EventFailure},
+		{Time: day.AddDate(0, 0, 1), Type: EventDeploy}, // next day
+	}
+
+	tl := BuildTimeline(events, at(15, 0))
+
+	if !tl.Day.Equal(day) {
+		t.Errorf("Day = %v, want %v", tl.Day, day)
+	}
+	if len(tl.Events) != 9 {
+		t.Errorf("Events = %d, want 9", len(tl.Events))
+	}
+	if tl.Deploys != 2 || tl.Failures != 6 || tl.Rollbacks != 1 {
+		t.Errorf("counts = %d/%d/%d, want 2/6/1", tl.Deploys, tl.Failures, tl.Rollbacks)
+	}
+	if tl.AvgCycle != 15*time.Second {
+		t.Errorf("AvgCycle = %v, want 15s", tl.AvgCycle)
+	}
+
+	// Single failure at 10:00 is not a streak; trailing run at 11:00 is
+	if len(tl.Streaks) != 2 {
+		t.Fatalf("Streaks = %d, want 2", len(tl.Streaks))
+	}
+	longest := tl.LongestStreak()
+	if longest.Count != 3 || !longest.Start.Equal(at(9, 5)) || !longest.End.Equal(at(9, 8)) {
+		t.Errorf("LongestStreak = %+v, want 3 failures 09:05-09:08", longest)
+	}
+}
+
+func TestBuildTimeline_Empty(t *testing.T) {
+	tl := BuildTimeline(nil, time.Now())
+
+	if len(tl.Events) != 0 || tl.AvgCycle != 0 || len(tl.Streaks) != 0 {
+		t.Errorf("expected empty timeline, got %+v", tl)
+	}
+	if tl.LongestStreak().Count != 0 {
+		t.Error("LongestStreak should be zero for empty timeline")
+	}
+}
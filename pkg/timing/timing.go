@@ -0,0 +1,173 @@
+// Package timing records how long each phase of a command takes
+// (see --profile-run).
+//
+// A Recorder travels in the context; code marks phases with
+//
+//	defer timing.Phase(ctx, "build")()
+//
+// which is a no-op when no Recorder is attached.
+package timing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Span is one timed phase.
+type Span struct {
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Recorder collects spans. Safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	start time.Time
+	spans []Span
+}
+
+// NewRecorder creates a recorder; its clock starts now.
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns ctx carrying r.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder in ctx, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Phase starts timing name and returns the function that ends it.
+// Without a recorder in ctx the returned function does nothing.
+func Phase(ctx context.Context, name string) func() {
+	r := FromContext(ctx)
+	if r == nil {
+		return func() {}
+	}
+	return r.Phase(name)
+}
+
+// Phase starts timing name and returns the function that ends it.
+// Calling the returned function more than once records only the first call.
+func (r *Recorder) Phase(name string) func() {
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.spans = append(r.spans, Span{Name: name, Start: start, Duration: time.Since(start)})
+		})
+	}
+}
+
+// Spans returns the recorded spans in completion order.
+func (r *Recorder) Spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Span{}, r.spans...)
+}
+
+// phaseStats aggregates the spans of one phase name.
+type phaseStats struct {
+	name  string
+	count int
+	total time.Duration
+	max   time.Duration
+	first time.Time
+}
+
+// WriteTable prints a per-phase breakdown: count, total, average and max
+// duration and share of the wall time, in order of first occurrence.
+func (r *Recorder) WriteTable(w io.Writer) error {
+	spans := r.Spans()
+	wall := time.Since(r.start)
+
+	byName := make(map[string]*phaseStats)
+	var stats []*phaseStats
+	for _, s := range spans {
+		st, ok := byName[s.Name]
+		if !ok {
+			st = &phaseStats{name: s.Name, first: s.Start}
+			byName[s.Name] = st
+			stats = append(stats, st)
+		}
+		st.count++
+		st.total += s.Duration
+		if s.Duration > st.max {
+			st.max = s.Duration
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].first.Before(stats[j].first) })
+
+	fmt.Fprintf(w, "Timing (wall %s):\n", round(wall))
+	if len(stats) == 0 {
+		fmt.Fprintln(w, "  no phases recorded")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  PHASE\tCOUNT\tTOTAL\tAVG\tMAX\tWALL %")
+	for _, st := range stats {
+		share := 0.0
+		if wall > 0 {
+			share = 100 * float64(st.total) / float64(wall)
+		}
+		fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t%s\t%.0f%%\n",
+			st.name, st.count, round(st.total), round(st.total/time.Duration(st.count)), round(st.max), share)
+	}
+	return tw.Flush()
+}
+
+// chromeEvent is a complete ("X") event of the Chrome trace format,
+// loadable in chrome://tracing and Perfetto.
+type chromeEvent struct {
+	Name  string `json:"name"`
+	Phase string `json:"ph"`
+	TS    int64  `json:"ts"`  // microseconds since the recorder started
+	Dur   int64  `json:"dur"` // microseconds
+	PID   int    `json:"pid"`
+	TID   int    `json:"tid"`
+}
+
+// WriteChromeTrace writes the spans in Chrome trace JSON format.
+func (r *Recorder) WriteChromeTrace(w io.Writer) error {
+	spans := r.Spans()
+	events := make([]chromeEvent, 0, len(spans))
+	for _, s := range spans {
+		events = append(events, chromeEvent{
+			Name:  s.Name,
+			Phase: "X",
+			TS:    s.Start.Sub(r.start).Microseconds(),
+			Dur:   s.Duration.Microseconds(),
+			PID:   1,
+			TID:   1,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]any{"traceEvents": events})
+}
+
+// round shortens durations for display.
+func round(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
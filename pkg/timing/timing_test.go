@@ -0,0 +1,133 @@
+package timing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhase_NoRecorder(t *testing.T) {
+	end := Phase(context.Background(), "build")
+	end()
+
+	if FromContext(context.Background()) != nil {
+		t.Error("expected no recorder in a plain context")
+	}
+}
+
+func TestPhase_Records(t *testing.T) {
+	r := NewRecorder()
+	ctx := WithRecorder(context.Background(), r)
+
+	end := Phase(ctx, "build")
+	time.Sleep(2 * time.Millisecond)
+	end()
+	end() // second call is ignored
+
+	spans := r.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name != "build" {
+		t.Errorf("Name = %q, want build", spans[0].Name)
+	}
+	if spans[0].Duration < 2*time.Millisecond {
+		t.Errorf("Duration = %s, want >= 2ms", spans[0].Duration)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	r := NewRecorder()
+	base := r.start
+	r.spans = []Span{
+		{Name: "hash", Start: base, Duration: 10 * time.Millisecond},
+		{Name: "build", Start: base.Add(10 * time.Millisecond), Duration: 300 * time.Millisecond},
+		{Name: "hash", Start: base.Add(time.Second), Duration: 30 * time.Millisecond},
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	out := buf.String()
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"header", []string{"PHASE", "COUNT", "TOTAL", "AVG", "MAX", "WALL %"}},
+		{"hash aggregated", []string{"hash", "2", "40ms", "20ms", "30ms"}},
+		{"build", []string{"build", "1", "300ms"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := lineWith(out, tt.want[0])
+			if line == "" {
+				t.Fatalf("no line with %q in:\n%s", tt.want[0], out)
+			}
+			for _, w := range tt.want[1:] {
+				if !strings.Contains(line, w) {
+					t.Errorf("line %q missing %q", line, w)
+				}
+			}
+		})
+	}
+
+	if strings.Index(out, "hash") > strings.Index(out, "build") {
+		t.Errorf("expected phases in order of first occurrence:\n%s", out)
+	}
+}
+
+func TestWriteTable_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewRecorder().WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no phases recorded") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
+func TestWriteChromeTrace(t *testing.T) {
+	r := NewRecorder()
+	r.spans = []Span{
+		{Name: "build", Start: r.start.Add(5 * time.Millisecond), Duration: 20 * time.Millisecond},
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteChromeTrace(&buf); err != nil {
+		t.Fatalf("WriteChromeTrace failed: %v", err)
+	}
+
+	var trace struct {
+		TraceEvents []chromeEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(trace.TraceEvents) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(trace.TraceEvents))
+	}
+
+	ev := trace.TraceEvents[0]
+	if ev.Name != "build" || ev.Phase != "X" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev.TS != 5000 || ev.Dur != 20000 {
+		t.Errorf("TS/Dur = %d/%d, want 5000/20000", ev.TS, ev.Dur)
+	}
+}
+
+// lineWith returns the first line of out whose first field is prefix.
+func lineWith(out, prefix string) string {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == prefix {
+			return line
+		}
+	}
+	return ""
+}
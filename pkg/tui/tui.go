@@ -0,0 +1,341 @@
+// Package tui is the full-screen terminal view of 'kudev watch --tui':
+// a status header (build, deploy, pod health, source hash), a scrolling
+// log pane and single-key commands. It is a bubbletea program; the
+// screen is the model's View, and status, pod health and log output
+// reach it as messages.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/watch"
+)
+
+// MaxLogLines caps the lines kept for the log pane.
+const MaxLogLines = 1000
+
+// Screen size used until the terminal reports its own.
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// Keys are the actions bound to keys.
+type Keys struct {
+	// Rebuild is bound to r
+	Rebuild func()
+
+	// TogglePause is bound to p
+	TogglePause func()
+
+	// Quit is bound to q and Ctrl+C
+	Quit func()
+}
+
+// Messages updating the model.
+type (
+	statusMsg watch.Status
+
+	deploymentMsg struct {
+		status *deployer.DeploymentStatus
+		err    error
+	}
+
+	// logMsg holds complete log lines, already cleaned
+	logMsg []string
+)
+
+// styles are the colors of the screen, rendered for the terminal's
+// color profile.
+type styles struct {
+	title, ok, warn, fail lipgloss.Style
+}
+
+func newStyles(r *lipgloss.Renderer) styles {
+	return styles{
+		title: r.NewStyle().Reverse(true),
+		ok:    r.NewStyle().Foreground(lipgloss.Color("2")),
+		warn:  r.NewStyle().Foreground(lipgloss.Color("3")),
+		fail:  r.NewStyle().Foreground(lipgloss.Color("1")),
+	}
+}
+
+// model is the bubbletea model of the screen.
+type model struct {
+	app       string
+	namespace string
+	keys      Keys
+	styles    styles
+
+	width, height int
+
+	status     watch.Status
+	deployment *deployer.DeploymentStatus
+	podsErr    error
+	logs       []string
+}
+
+func newModel(app, namespace string, s styles) model {
+	return model{
+		app:       app,
+		namespace: namespace,
+		styles:    s,
+		width:     defaultWidth,
+		height:    defaultHeight,
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case statusMsg:
+		m.status = watch.Status(msg)
+
+	case deploymentMsg:
+		m.deployment, m.podsErr = msg.status, msg.err
+
+	case logMsg:
+		m.logs = append(m.logs, msg...)
+		if len(m.logs) > MaxLogLines {
+			m.logs = m.logs[len(m.logs)-MaxLogLines:]
+		}
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r", "R":
+			return m, action(m.keys.Rebuild)
+		case "p", "P":
+			return m, action(m.keys.TogglePause)
+		case "q", "Q", "ctrl+c":
+			return m, tea.Sequence(action(m.keys.Quit), tea.Quit)
+		}
+	}
+	return m, nil
+}
+
+// action runs f, if set, as a command.
+func action(f func()) tea.Cmd {
+	if f == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		f()
+		return nil
+	}
+}
+
+func (m model) View() string {
+	return strings.Join(m.render(), "\n")
+}
+
+// render lays out the screen as m.height lines of at most m.width
+// columns.
+func (m model) render() []string {
+	width := m.width
+	pad := func(s string) string {
+		return ansi.Truncate(s, width, "")
+	}
+
+	phase := string(m.status.Phase)
+	if phase == "" {
+		phase = string(watch.PhaseWatching)
+	}
+	phase = m.styles.ok.Render(phase)
+	if m.status.Paused {
+		phase += m.styles.warn.Render(" · PAUSED (changes are picked up on resume)")
+	}
+	if m.status.Backoff != "" {
+		phase += m.styles.fail.Render(" · " + m.status.Backoff + " (r to retry now)")
+	}
+
+	title := fmt.Sprintf(" kudev watch · %s (namespace %s)", m.app, m.namespace)
+	header := []string{
+		m.styles.title.Render(pad(title + strings.Repeat(" ", width))),
+		pad(" Status   " + phase),
+		m.resultStyle(m.status.Build).Render(pad(" Build    " + orDash(m.status.Build))),
+		m.resultStyle(m.status.Deploy).Render(pad(" Deploy   " + orDash(m.status.Deploy))),
+		pad(" Pods     " + m.podsLine()),
+		pad(" Hash     " + orDash(m.status.Hash)),
+		strings.Repeat("─", width),
+	}
+
+	footer := []string{
+		strings.Repeat("─", width),
+		pad(" r rebuild   p pause/resume watching   q quit"),
+	}
+
+	paneHeight := m.height - len(header) - len(footer)
+	if paneHeight < 1 {
+		paneHeight = 1
+	}
+	logs := m.logs
+	if len(logs) > paneHeight {
+		logs = logs[len(logs)-paneHeight:]
+	}
+
+	lines := append([]string{}, header...)
+	for _, line := range logs {
+		lines = append(lines, pad(line))
+	}
+	for i := len(logs); i < paneHeight; i++ {
+		lines = append(lines, "")
+	}
+	return append(lines, footer...)
+}
+
+// podsLine summarizes pod health, e.g.
+// "1/2 ready · ● myapp-abc Running · ○ myapp-def Pending (3 restarts)".
+func (m model) podsLine() string {
+	if m.podsErr != nil {
+		return "unavailable: " + m.podsErr.Error()
+	}
+	d := m.deployment
+	if d == nil {
+		return "-"
+	}
+
+	parts := []string{fmt.Sprintf("%d/%d ready", d.ReadyReplicas, d.DesiredReplicas)}
+	for _, pod := range d.Pods {
+		marker := "○"
+		if pod.Ready {
+			marker = "●"
+		}
+		part := fmt.Sprintf("%s %s %s", marker, pod.Name, pod.Status)
+		if pod.Restarts > 0 {
+			part += fmt.Sprintf(" (%d restarts)", pod.Restarts)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// resultStyle colors a build or deploy result red for failures, yellow
+// for warnings.
+func (m model) resultStyle(result string) lipgloss.Style {
+	switch {
+	case strings.Contains(result, "failed") || strings.HasPrefix(result, "refused") || strings.HasPrefix(result, "crash-looping"):
+		return m.styles.fail
+	case strings.Contains(result, "not ready") || strings.HasPrefix(result, "skipped") || strings.HasPrefix(result, "rolled back"):
+		return m.styles.warn
+	}
+	return lipgloss.NewStyle()
+}
+
+// UI runs the screen. Its setters and Write are safe for concurrent use.
+type UI struct {
+	out io.Writer
+
+	mu sync.Mutex
+	// model takes the messages sent before Run starts program
+	model   model
+	program *tea.Program
+	partial string
+}
+
+// New creates a UI drawing on out, which must be a terminal for Run.
+func New(out io.Writer, app, namespace string) *UI {
+	return &UI{
+		out:   out,
+		model: newModel(app, namespace, newStyles(lipgloss.NewRenderer(out))),
+	}
+}
+
+// send delivers msg to the running program, or applies it to the model
+// Run will start with.
+func (u *UI) send(msg tea.Msg) {
+	u.mu.Lock()
+	if u.program == nil {
+		updated, _ := u.model.Update(msg)
+		u.model = updated.(model)
+		u.mu.Unlock()
+		return
+	}
+	program := u.program
+	u.mu.Unlock()
+
+	// Returns at once when the program has ended
+	program.Send(msg)
+}
+
+// SetStatus shows the orchestrator status.
+func (u *UI) SetStatus(s watch.Status) {
+	u.send(statusMsg(s))
+}
+
+// SetDeployment shows the deployment's pod health (err: status unavailable).
+func (u *UI) SetDeployment(status *deployer.DeploymentStatus, err error) {
+	u.send(deploymentMsg{status: status, err: err})
+}
+
+// Write appends output to the log pane, line by line.
+func (u *UI) Write(p []byte) (int, error) {
+	u.mu.Lock()
+	lines := strings.Split(u.partial+string(p), "\n")
+	u.partial = lines[len(lines)-1]
+	u.mu.Unlock()
+
+	if complete := lines[:len(lines)-1]; len(complete) > 0 {
+		cleaned := make(logMsg, len(complete))
+		for i, line := range complete {
+			cleaned[i] = cleanLine(line)
+		}
+		u.send(cleaned)
+	}
+	return len(p), nil
+}
+
+// Run shows the screen on the alternate screen, reading keys from in,
+// until ctx is done or the quit key is pressed. The terminal is
+// restored on return.
+func (u *UI) Run(ctx context.Context, in io.Reader, keys Keys) error {
+	u.mu.Lock()
+	m := u.model
+	m.keys = keys
+	u.program = tea.NewProgram(m,
+		tea.WithContext(ctx),
+		tea.WithInput(in),
+		tea.WithOutput(u.out),
+		tea.WithAltScreen(),
+		// Ctrl+C is a key (bound to quit); the command handles signals
+		tea.WithoutSignalHandler(),
+	)
+	program := u.program
+	u.mu.Unlock()
+
+	_, err := program.Run()
+	if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// cleanLine strips escape sequences, which would otherwise move the
+// cursor or leak colors into the layout, carriage returns and tabs.
+func cleanLine(line string) string {
+	line = ansi.Strip(line)
+	line = strings.ReplaceAll(line, "\r", "")
+	return strings.ReplaceAll(line, "\t", "    ")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/watch"
+)
+
+// update feeds msgs to m through its Update function.
+func update(t *testing.T, m model, msgs ...tea.Msg) model {
+	t.Helper()
+	for _, msg := range msgs {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	return m
+}
+
+func TestUI_Write(t *testing.T) {
+	u := New(io.Discard, "myapp", "default")
+
+	fmt.Fprint(u, "first\n\x1b[32mgreen\x1b[0m\tline\r\npart")
+	fmt.Fprint(u, "ial\n")
+
+	want := []string{"first", "green    line", "partial"}
+	if strings.Join(u.model.logs, "|") != strings.Join(want, "|") {
+		t.Errorf("logs = %q, want %q", u.model.logs, want)
+	}
+
+	for i := 0; i < MaxLogLines+10; i++ {
+		fmt.Fprintln(u, i)
+	}
+	if len(u.model.logs) != MaxLogLines {
+		t.Errorf("kept %d lines, want %d", len(u.model.logs), MaxLogLines)
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	m := update(t, New(io.Discard, "myapp", "default").model,
+		tea.WindowSizeMsg{Width: 120, Height: 15},
+		statusMsg(watch.Status{
+			Phase:  watch.PhaseBuilding,
+			Paused: true,
+			Hash:   "1a2b3c4d",
+			Build:  "failed: exit status 1",
+		}),
+		deploymentMsg{status: &deployer.DeploymentStatus{
+			ReadyReplicas:   1,
+			DesiredReplicas: 2,
+			Pods: []deployer.PodStatus{
+				{Name: "myapp-abc", Status: "Running", Ready: true},
+				{Name: "myapp-def", Status: "Pending", Restarts: 3},
+			},
+		}},
+	)
+	for i := 1; i <= 20; i++ {
+		m = update(t, m, logMsg{fmt.Sprintf("log line %d", i)})
+	}
+
+	lines := strings.Split(m.View(), "\n")
+	if len(lines) != 15 {
+		t.Fatalf("got %d lines, want 15", len(lines))
+	}
+
+	screen := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"kudev watch · myapp (namespace default)",
+		"building",
+		"PAUSED",
+		" Build    failed: exit status 1",
+		"1/2 ready · ● myapp-abc Running · ○ myapp-def Pending (3 restarts)",
+		"Hash     1a2b3c4d",
+		"r rebuild",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen lacks %q:\n%s", want, screen)
+		}
+	}
+
+	// 15 lines minus 7 header and 2 footer lines leave the last 6 log lines
+	if lines[7] != "log line 15" || lines[12] != "log line 20" {
+		t.Errorf("log pane = %q", lines[7:13])
+	}
+
+	// A narrower terminal truncates every line
+	m = update(t, m, tea.WindowSizeMsg{Width: 20, Height: 15})
+	for _, line := range strings.Split(m.View(), "\n") {
+		if n := len([]rune(line)); n > 20 {
+			t.Errorf("line %q is %d columns wide", line, n)
+		}
+	}
+}
+
+func TestModel_Keys(t *testing.T) {
+	var rebuilt, toggled, quit int
+	m := New(io.Discard, "myapp", "default").model
+	m.keys = Keys{
+		Rebuild:     func() { rebuilt++ },
+		TogglePause: func() { toggled++ },
+		Quit:        func() { quit++ },
+	}
+
+	press := func(key tea.KeyMsg) tea.Cmd {
+		_, cmd := m.Update(key)
+		return cmd
+	}
+	runes := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	press(runes("r"))()
+	press(runes("P"))()
+	if rebuilt != 1 || toggled != 1 {
+		t.Errorf("rebuilt %d, toggled %d times, want 1 and 1", rebuilt, toggled)
+	}
+	if cmd := press(runes("x")); cmd != nil {
+		t.Error("unbound keys should do nothing")
+	}
+
+	for _, key := range []tea.KeyMsg{runes("q"), {Type: tea.KeyCtrlC}} {
+		cmd := press(key)
+		if cmd == nil {
+			t.Fatalf("%s: no command", key)
+		}
+		// Quit runs first, then the program ends
+		msgs := runCmd(cmd)
+		if len(msgs) == 0 {
+			t.Fatalf("%s: no messages", key)
+		}
+		if _, ok := msgs[len(msgs)-1].(tea.QuitMsg); !ok {
+			t.Errorf("%s: last message = %#v, want QuitMsg", key, msgs[len(msgs)-1])
+		}
+	}
+	if quit != 2 {
+		t.Errorf("quit %d times, want 2", quit)
+	}
+}
+
+// runCmd runs cmd like the program would, expanding batches and
+// sequences, and returns the messages in order.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	msg := cmd()
+	// tea.Batch and tea.Sequence return a list of commands
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice {
+		var msgs []tea.Msg
+		for i := 0; i < v.Len(); i++ {
+			if c, ok := v.Index(i).Interface().(tea.Cmd); ok && c != nil {
+				msgs = append(msgs, runCmd(c)...)
+			}
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+func TestModel_PodsUnavailable(t *testing.T) {
+	m := update(t, New(io.Discard, "myapp", "default").model,
+		deploymentMsg{err: errors.New("deployment not found")})
+
+	if got := m.podsLine(); got != "unavailable: deployment not found" {
+		t.Errorf("podsLine() = %q", got)
+	}
+}
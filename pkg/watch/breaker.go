@@ -0,0 +1,137 @@
+package watch
+
+import (
+	"fmt"
+	"time"
+)
+
+// Rebuilds failing over and over at the same stage (e.g. every build while
+// the Docker daemon is down) trip a circuit breaker: rebuilds are suspended
+// for a backoff, then retried once; each failed retry doubles the backoff.
+const (
+	// breakerThreshold is how many consecutive failures at one stage
+	// suspend rebuilds.
+	breakerThreshold = 5
+
+	// breakerBackoff is the first pause, doubled up to breakerMaxBackoff.
+	breakerBackoff    = 10 * time.Second
+	breakerMaxBackoff = 5 * time.Minute
+)
+
+// breakerHints say what to check when rebuilds keep failing at a stage.
+var breakerHints = map[string]string{
+	"hash":    "Check that the build context is readable.",
+	"tag":     "Check that the build context is readable.",
+	"build":   "Is the Docker daemon running? Check with 'docker info'.",
+	"load":    "Is the cluster reachable? Check with 'kubectl get nodes'.",
+	"deploy":  "Is the cluster reachable? Check with 'kubectl get nodes'.",
+	"context": "Switch back to the kubeconfig context watch started with.",
+}
+
+// breaker counts consecutive failures at one stage.
+type breaker struct {
+	stage    string
+	failures int
+
+	// backoff is the current pause (zero while rebuilds run normally);
+	// rebuilds are suspended until until
+	backoff time.Duration
+	until   time.Time
+}
+
+// record counts a finished cycle and reports whether it (re)opened the
+// breaker. Any cycle that got past the failing stage closes it.
+func (b *breaker) record(c *rebuildCycle, now time.Time) bool {
+	if c.result != CycleFailed {
+		*b = breaker{}
+		return false
+	}
+	if c.stage != b.stage {
+		*b = breaker{stage: c.stage}
+	}
+
+	b.failures++
+	if b.failures < breakerThreshold {
+		return false
+	}
+	switch {
+	case b.backoff == 0:
+		b.backoff = breakerBackoff
+	case 2*b.backoff > breakerMaxBackoff:
+		b.backoff = breakerMaxBackoff
+	default:
+		b.backoff *= 2
+	}
+	b.until = now.Add(b.backoff)
+	return true
+}
+
+// tripped reports whether rebuilds were suspended by the last failures.
+func (b *breaker) tripped() bool {
+	return b.backoff > 0
+}
+
+// open reports whether rebuilds are suspended at now.
+func (b *breaker) open(now time.Time) bool {
+	return now.Before(b.until)
+}
+
+// describe summarizes the breaker for Status.Backoff.
+func (b *breaker) describe() string {
+	return fmt.Sprintf("%s failed %d times in a row, retrying at %s", b.stage, b.failures, b.until.Format("15:04:05"))
+}
+
+// updateBreaker feeds a finished cycle to the breaker, announcing when
+// rebuilds are suspended and when the failing stage works again.
+func (o *Orchestrator) updateBreaker(c *rebuildCycle) {
+	o.mu.Lock()
+	wasTripped := o.breaker.tripped()
+	opened := o.breaker.record(c, o.clk().Now())
+	b := o.breaker
+	if opened || !b.tripped() {
+		o.stopRetry()
+	}
+	if opened {
+		o.retry = o.clk().AfterFunc(b.backoff, func() {
+			o.Trigger(fmt.Sprintf("retrying %s after %d failures", b.stage, b.failures))
+		})
+	}
+	o.mu.Unlock()
+
+	switch {
+	case opened:
+		printBreakerBanner(b)
+		o.update(func(s *Status) { s.Backoff = b.describe() })
+	case wasTripped && !b.tripped():
+		fmt.Println("✓ Recovered, rebuilding on change again")
+		o.update(func(s *Status) { s.Backoff = "" })
+	}
+}
+
+// stopRetry cancels a pending retry. The caller holds o.mu.
+func (o *Orchestrator) stopRetry() {
+	if o.retry != nil {
+		o.retry.Stop()
+		o.retry = nil
+	}
+}
+
+// suspended reports whether file changes wait for the breaker's retry.
+func (o *Orchestrator) suspended() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.breaker.open(o.clk().Now())
+}
+
+// printBreakerBanner explains why rebuilds stopped and what to check.
+func printBreakerBanner(b breaker) {
+	fmt.Println()
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Printf("  ⚠ %s failed %d times in a row, rebuilds paused for %s\n", b.stage, b.failures, b.backoff)
+	if hint := breakerHints[b.stage]; hint != "" {
+		fmt.Printf("  %s\n", hint)
+	}
+	fmt.Println("  Changes saved meanwhile are included in the retry.")
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Println()
+}
@@ -0,0 +1,112 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/clock"
+)
+
+func failedCycle(stage string) *rebuildCycle {
+	c := newCycle(time.Now())
+	c.fail(stage)
+	return c
+}
+
+func TestBreaker_Record(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	var b breaker
+
+	for i := 1; i < breakerThreshold; i++ {
+		if b.record(failedCycle("build"), now) {
+			t.Fatalf("opened after %d failures", i)
+		}
+	}
+	// A failure at another stage starts over
+	b.record(failedCycle("load"), now)
+	if b.failures != 1 || b.stage != "load" {
+		t.Fatalf("stage change: failures=%d stage=%s, want 1 load", b.failures, b.stage)
+	}
+
+	for i := 1; i < breakerThreshold; i++ {
+		b.record(failedCycle("load"), now)
+	}
+	if !b.tripped() || !b.open(now) || b.backoff != breakerBackoff {
+		t.Fatalf("after %d failures: tripped=%v backoff=%s, want open for %s", breakerThreshold, b.tripped(), b.backoff, breakerBackoff)
+	}
+	if b.open(now.Add(breakerBackoff)) {
+		t.Error("still open after the backoff")
+	}
+
+	// Failed retries double the backoff, up to the maximum
+	var backoffs []time.Duration
+	for i := 0; i < 7; i++ {
+		if !b.record(failedCycle("load"), now) {
+			t.Fatal("failed retry did not reopen the breaker")
+		}
+		backoffs = append(backoffs, b.backoff)
+	}
+	want := []time.Duration{20 * time.Second, 40 * time.Second, 80 * time.Second, 160 * time.Second, breakerMaxBackoff, breakerMaxBackoff, breakerMaxBackoff}
+	for i := range want {
+		if backoffs[i] != want[i] {
+			t.Errorf("backoffs = %v, want %v", backoffs, want)
+			break
+		}
+	}
+
+	// Getting past the stage closes it, even when not ready
+	notReady := newCycle(now)
+	notReady.result = CycleNotReady
+	if b.record(notReady, now) || b.tripped() || b.failures != 0 {
+		t.Errorf("not closed by a %s cycle: %+v", CycleNotReady, b)
+	}
+}
+
+func TestOrchestrator_Breaker(t *testing.T) {
+	var statuses []Status
+	clk := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+	o := &Orchestrator{
+		triggers: make(chan string, 1),
+		finished: make(chan struct{}),
+		onStatus: func(s Status) { statuses = append(statuses, s) },
+		clock:    clk,
+	}
+
+	for i := 0; i < breakerThreshold; i++ {
+		o.endCycle(failedCycle("build"))
+	}
+	if !o.suspended() {
+		t.Fatal("rebuilds not suspended after repeated build failures")
+	}
+	if got := o.Status().Backoff; got == "" {
+		t.Error("Status.Backoff not set")
+	}
+
+	// The retry fires after the backoff
+	clk.Advance(breakerBackoff - time.Second)
+	if !o.suspended() || len(o.triggers) != 0 {
+		t.Fatal("retried before the backoff elapsed")
+	}
+	clk.Advance(time.Second)
+	select {
+	case reason := <-o.triggers:
+		if reason != "retrying build after 5 failures" {
+			t.Errorf("trigger reason = %q", reason)
+		}
+	default:
+		t.Fatal("no retry triggered")
+	}
+	if o.suspended() {
+		t.Error("still suspended after the backoff")
+	}
+
+	ok := newCycle(time.Now())
+	ok.result = CycleOK
+	o.endCycle(ok)
+	if o.suspended() || o.Status().Backoff != "" {
+		t.Errorf("still suspended after a successful cycle: %+v", o.Status())
+	}
+	if o.retry != nil {
+		t.Error("retry timer not cleared")
+	}
+}
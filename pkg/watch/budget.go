@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+)
+
+// budgetCheckTimeout bounds one cluster capacity check.
+const budgetCheckTimeout = 10 * time.Second
+
+// budgetConfig returns spec.watch.resourceBudget (nil when unset; its
+// methods apply defaults).
+func (o *Orchestrator) budgetConfig() *config.ResourceBudgetConfig {
+	if o.config.Spec.Watch == nil {
+		return nil
+	}
+	return o.config.Spec.Watch.ResourceBudget
+}
+
+// budgetInterval returns the capacity check interval, or 0 when the
+// check is disabled or the deployer cannot report the budget.
+func (o *Orchestrator) budgetInterval() time.Duration {
+	if _, ok := o.deployer.(deployer.BudgetChecker); !ok || !o.budgetConfig().Enabled() {
+		return 0
+	}
+	return o.budgetConfig().EffectiveInterval()
+}
+
+// checkBudget warns when the cluster is near capacity. An alert is
+// printed when it changes, not on every check, and once more when it
+// clears.
+func (o *Orchestrator) checkBudget(ctx context.Context) {
+	checker, ok := o.deployer.(deployer.BudgetChecker)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, budgetCheckTimeout)
+	defer cancel()
+	budget, err := checker.CheckBudget(ctx)
+	if err != nil {
+		o.logger.Debug("resource budget check skipped", "error", err)
+		return
+	}
+
+	alert := formatBudgetAlert(budget, o.budgetConfig().EffectiveThreshold())
+	if alert == o.budgetAlert {
+		return
+	}
+	if alert == "" {
+		fmt.Println("✓ Cluster capacity is back below the resource budget")
+	} else {
+		fmt.Print(alert)
+	}
+	o.budgetAlert = alert
+}
+
+// formatBudgetAlert renders the alerts of budget with suggestions, or
+// returns "" when the cluster has room.
+func formatBudgetAlert(budget *deployer.ClusterBudget, threshold int32) string {
+	alerts := budget.Alerts(threshold)
+	if len(alerts) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠ Cluster near capacity (%d node(s)):\n", budget.Nodes)
+	for _, a := range alerts {
+		fmt.Fprintf(&b, "  - %s\n", a)
+	}
+	if suggestions := budget.Suggestions(); len(suggestions) > 0 {
+		b.WriteString("💡 To free resources:\n")
+		for _, s := range suggestions {
+			fmt.Fprintf(&b, "  - %s\n", s)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+type mockBudgetDeployer struct {
+	mockDeployer
+	budget *deployer.ClusterBudget
+}
+
+func (m *mockBudgetDeployer) CheckBudget(ctx context.Context) (*deployer.ClusterBudget, error) {
+	return m.budget, nil
+}
+
+func testBudget(requestedCPU string) *deployer.ClusterBudget {
+	return &deployer.ClusterBudget{
+		Nodes:             1,
+		AllocatableCPU:    resource.MustParse("4"),
+		AllocatableMemory: resource.MustParse("8Gi"),
+		RequestedCPU:      resource.MustParse(requestedCPU),
+		RequestedMemory:   resource.MustParse("1Gi"),
+		Apps: []deployer.AppUsage{
+			{Name: "api", Namespace: "dev", Pods: 3, CPU: resource.MustParse("1"), Memory: resource.MustParse("256Mi")},
+		},
+	}
+}
+
+func TestOrchestrator_CheckBudget(t *testing.T) {
+	dep := &mockBudgetDeployer{budget: testBudget("3800m")}
+	o := &Orchestrator{
+		config:   &config.DeploymentConfig{Metadata: config.MetadataConfig{Name: "api"}},
+		logger:   &util.MockLogger{},
+		deployer: dep,
+	}
+
+	if got := o.budgetInterval(); got != config.DefaultBudgetInterval {
+		t.Errorf("budgetInterval() = %v, want %v", got, config.DefaultBudgetInterval)
+	}
+
+	o.checkBudget(context.Background())
+	if !strings.Contains(o.budgetAlert, "95% of allocatable CPU") || !strings.Contains(o.budgetAlert, "lower spec.replicas of api (3 pods") {
+		t.Errorf("alert = %q", o.budgetAlert)
+	}
+
+	dep.budget = testBudget("1")
+	o.checkBudget(context.Background())
+	if o.budgetAlert != "" {
+		t.Errorf("alert = %q after capacity was freed, want none", o.budgetAlert)
+	}
+}
+
+func TestOrchestrator_BudgetInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		deployer deployer.Deployer
+		budget   *config.ResourceBudgetConfig
+		want     time.Duration
+	}{
+		{"custom interval", &mockBudgetDeployer{}, &config.ResourceBudgetConfig{Interval: config.Duration{Duration: 30 * time.Second}}, 30 * time.Second},
+		{"disabled", &mockBudgetDeployer{}, &config.ResourceBudgetConfig{Disabled: true}, 0},
+		{"deployer without budget", &mockDeployer{}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Orchestrator{
+				config: &config.DeploymentConfig{Spec: config.SpecConfig{
+					Watch: &config.WatchConfig{ResourceBudget: tt.budget},
+				}},
+				deployer: tt.deployer,
+			}
+			if got := o.budgetInterval(); got != tt.want {
+				t.Errorf("budgetInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package watch
+
+import (
+	"fmt"
+	"time"
+)
+
+// Cycle results, as printed in the summary line.
+const (
+	CycleOK       = "OK"
+	CycleNotReady = "NOTREADY"
+	CycleFrozen   = "FROZEN"
+	CycleFailed   = "FAIL"
+)
+
+// bell is the terminal bell character.
+const bell = "\a"
+
+// rebuildCycle is the outcome of one rebuild, printed by endCycle as a
+// single greppable line, e.g. "CYCLE 14 OK 8.2s hash=ab12cd34" or
+// "CYCLE 15 FAIL 3.1s hash=ab12cd34 stage=build".
+type rebuildCycle struct {
+	start  time.Time
+	hash   string
+	result string
+
+	// stage is where a failed cycle stopped
+	stage string
+}
+
+// newCycle starts a cycle; it counts as failed until a result is set.
+func newCycle(start time.Time) *rebuildCycle {
+	return &rebuildCycle{start: start, result: CycleFailed}
+}
+
+// fail marks the cycle as failed at stage.
+func (c *rebuildCycle) fail(stage string) {
+	c.result = CycleFailed
+	c.stage = stage
+}
+
+// summary formats the cycle's line.
+func (c *rebuildCycle) summary(number int, elapsed time.Duration) string {
+	line := fmt.Sprintf("CYCLE %d %s %.1fs", number, c.result, elapsed.Seconds())
+	if c.hash != "" {
+		line += " hash=" + c.hash
+	}
+	if c.stage != "" {
+		line += " stage=" + c.stage
+	}
+	return line
+}
+
+// failed reports whether the cycle counts as failed for MaxCycles.
+func (c *rebuildCycle) failed() bool {
+	return c.result == CycleFailed || c.result == CycleNotReady
+}
+
+// endCycle prints the summary of c and rings the bell if enabled, then
+// updates the breaker. Once MaxCycles cycles are done, Run returns.
+func (o *Orchestrator) endCycle(c *rebuildCycle) {
+	o.cycles++
+	if c.failed() {
+		o.failedCycles++
+	}
+
+	summary := c.summary(o.cycles, time.Since(c.start))
+	if o.bell {
+		summary += bell
+	}
+	fmt.Println(summary)
+	o.updateBreaker(c)
+
+	if o.maxCycles > 0 && o.cycles == o.maxCycles {
+		close(o.finished)
+	}
+}
+
+// isFinished reports whether MaxCycles cycles are done.
+func (o *Orchestrator) isFinished() bool {
+	select {
+	case <-o.finished:
+		return true
+	default:
+		return false
+	}
+}
+
+// cyclesResult is what Run returns after MaxCycles cycles.
+func (o *Orchestrator) cyclesResult() error {
+	if o.failedCycles > 0 {
+		return fmt.Errorf("%d of %d cycles failed", o.failedCycles, o.cycles)
+	}
+	return nil
+}
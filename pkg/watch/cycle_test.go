@@ -0,0 +1,50 @@
+package watch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRebuildCycle_Summary(t *testing.T) {
+	ok := newCycle(time.Now())
+	ok.hash = "ab12cd34"
+	ok.result = CycleOK
+	if got := ok.summary(14, 8240*time.Millisecond); got != "CYCLE 14 OK 8.2s hash=ab12cd34" {
+		t.Errorf("summary() = %q", got)
+	}
+
+	failed := newCycle(time.Now())
+	failed.hash = "ab12cd34"
+	failed.fail("build")
+	if got := failed.summary(15, 3100*time.Millisecond); got != "CYCLE 15 FAIL 3.1s hash=ab12cd34 stage=build" {
+		t.Errorf("summary() = %q", got)
+	}
+
+	// Failing before the hash is known
+	early := newCycle(time.Now())
+	early.fail("hash")
+	if got := early.summary(1, 0); got != "CYCLE 1 FAIL 0.0s stage=hash" {
+		t.Errorf("summary() = %q", got)
+	}
+}
+
+func TestOrchestrator_CyclesResult(t *testing.T) {
+	o := &Orchestrator{maxCycles: 2, finished: make(chan struct{})}
+
+	ok := newCycle(time.Now())
+	ok.result = CycleOK
+	o.endCycle(ok)
+	if o.isFinished() {
+		t.Fatal("finished after 1 of 2 cycles")
+	}
+
+	notReady := newCycle(time.Now())
+	notReady.result = CycleNotReady
+	o.endCycle(notReady)
+	if !o.isFinished() {
+		t.Fatal("not finished after 2 of 2 cycles")
+	}
+	if err := o.cyclesResult(); err == nil || err.Error() != "1 of 2 cycles failed" {
+		t.Errorf("cyclesResult() = %v, want 1 of 2 cycles failed", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/nanaki-93/kudev/pkg/clock"
 	"github.com/nanaki-93/kudev/pkg/logging"
 )
 
@@ -13,12 +14,17 @@ type DebounceConfig struct {
 	// Window is how long to wait for more events before triggering.
 	// Default: 500ms
 	Window time.Duration
+
+	// Clock schedules the window timer.
+	// Default: clock.Real
+	Clock clock.Clock
 }
 
 // DefaultDebounceConfig returns sensible defaults.
 func DefaultDebounceConfig() DebounceConfig {
 	return DebounceConfig{
 		Window: 500 * time.Millisecond,
+		Clock:  clock.Real,
 	}
 }
 
@@ -28,15 +34,21 @@ type Debouncer struct {
 	logger logging.LoggerInterface
 
 	mu     sync.Mutex
-	timer  *time.Timer
+	timer  clock.Timer
 	events []FileChangeEvent
+
+	// gen identifies the current timer; due is set when it fires, so a
+	// timer that fired just before being reset doesn't flush early
+	gen uint64
+	due bool
 }
 
 // NewDebouncer creates a new debouncer.
 func NewDebouncer(config DebounceConfig, logger logging.LoggerInterface) *Debouncer {
+	config.Clock = clock.OrReal(config.Clock)
 	return &Debouncer{
 		config: config,
-		logger: logger,
+		logger: logging.OrDefault(logger),
 		events: make([]FileChangeEvent, 0),
 	}
 }
@@ -55,7 +67,9 @@ func (d *Debouncer) Debounce(ctx context.Context, input <-chan FileChangeEvent)
 func (d *Debouncer) processEvents(ctx context.Context, input <-chan FileChangeEvent, output chan<- []FileChangeEvent) {
 	defer close(output)
 
-	triggerChan := make(chan struct{})
+	// Buffered so a timer firing while an event is being added isn't
+	// lost; whether the batch is due is tracked by d.due
+	triggerChan := make(chan struct{}, 1)
 
 	for {
 		select {
@@ -75,6 +89,11 @@ func (d *Debouncer) processEvents(ctx context.Context, input <-chan FileChangeEv
 		case <-triggerChan:
 			// Timer fired, send batched events
 			d.mu.Lock()
+			if !d.due {
+				d.mu.Unlock()
+				continue
+			}
+			d.due = false
 			if len(d.events) > 0 {
 				eventsCopy := make([]FileChangeEvent, len(d.events))
 				copy(eventsCopy, d.events)
@@ -110,11 +129,16 @@ func (d *Debouncer) addEvent(event FileChangeEvent, triggerChan chan struct{}) {
 	)
 
 	// Reset timer
-	if d.timer != nil {
-		d.timer.Stop()
-	}
+	d.stopTimer()
+
+	gen := d.gen
+	d.timer = d.config.Clock.AfterFunc(d.config.Window, func() {
+		d.mu.Lock()
+		if gen == d.gen {
+			d.due = true
+		}
+		d.mu.Unlock()
 
-	d.timer = time.AfterFunc(d.config.Window, func() {
 		select {
 		case triggerChan <- struct{}{}:
 		default:
@@ -139,10 +163,18 @@ func (d *Debouncer) cancelTimer() {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	d.stopTimer()
+}
+
+// stopTimer stops the pending timer and invalidates it in case it has
+// already fired. The caller holds d.mu.
+func (d *Debouncer) stopTimer() {
 	if d.timer != nil {
 		d.timer.Stop()
 		d.timer = nil
 	}
+	d.gen++
+	d.due = false
 }
 
 // Reset clears the event buffer without triggering.
@@ -151,8 +183,5 @@ func (d *Debouncer) Reset() {
 	defer d.mu.Unlock()
 
 	d.events = d.events[:0]
-	if d.timer != nil {
-		d.timer.Stop()
-		d.timer = nil
-	}
+	d.stopTimer()
 }
@@ -5,120 +5,145 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nanaki-93/kudev/pkg/clock"
 	"github.com/nanaki-93/kudev/test/util"
 )
 
+const testWindow = 100 * time.Millisecond
+
+// newTestDebouncer returns a debouncer driven by a fake clock.
+func newTestDebouncer() (*Debouncer, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return NewDebouncer(DebounceConfig{Window: testWindow, Clock: clk}, &util.MockLogger{}), clk
+}
+
+// waitPending waits until the debouncer has batched n events (and so
+// has scheduled the timer for the last one).
+func waitPending(t *testing.T, d *Debouncer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		d.mu.Lock()
+		got := len(d.events)
+		d.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("debouncer never batched %d events", n)
+}
+
+// receive returns the next batch, failing if none arrives.
+func receive(t *testing.T, output <-chan []FileChangeEvent) []FileChangeEvent {
+	t.Helper()
+	select {
+	case batch := <-output:
+		return batch
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for batch")
+		return nil
+	}
+}
+
 func TestDebouncer_BatchesEvents(t *testing.T) {
-	config := DebounceConfig{Window: 100 * time.Millisecond}
-	debouncer := NewDebouncer(config, &util.MockLogger{})
+	debouncer, clk := newTestDebouncer()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	input := make(chan FileChangeEvent)
 	output := debouncer.Debounce(ctx, input)
 
 	// Send rapid events
-	go func() {
-		input <- FileChangeEvent{Path: "file1.go", Op: "write"}
-		input <- FileChangeEvent{Path: "file2.go", Op: "write"}
-		input <- FileChangeEvent{Path: "file3.go", Op: "write"}
-		close(input)
-	}()
+	input <- FileChangeEvent{Path: "file1.go", Op: "write"}
+	input <- FileChangeEvent{Path: "file2.go", Op: "write"}
+	input <- FileChangeEvent{Path: "file3.go", Op: "write"}
+	waitPending(t, debouncer, 3)
+
+	clk.Advance(testWindow)
 
 	// Should receive single batch
-	select {
-	case batch := <-output:
-		if len(batch) != 3 {
-			t.Errorf("expected 3 events in batch, got %d", len(batch))
-		}
-	case <-time.After(1 * time.Second):
-		t.Fatal("timeout waiting for batch")
+	if batch := receive(t, output); len(batch) != 3 {
+		t.Errorf("expected 3 events in batch, got %d", len(batch))
 	}
 }
 
 func TestDebouncer_ResetsTimerOnNewEvent(t *testing.T) {
-	config := DebounceConfig{Window: 200 * time.Millisecond}
-	debouncer := NewDebouncer(config, &util.MockLogger{})
+	debouncer, clk := newTestDebouncer()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	input := make(chan FileChangeEvent)
 	output := debouncer.Debounce(ctx, input)
 
-	start := time.Now()
-
-	// Send events with delays (but within window)
-	go func() {
-		input <- FileChangeEvent{Path: "file1.go", Op: "write"}
-		time.Sleep(100 * time.Millisecond)
-		input <- FileChangeEvent{Path: "file2.go", Op: "write"}
-		time.Sleep(100 * time.Millisecond)
-		input <- FileChangeEvent{Path: "file3.go", Op: "write"}
-		close(input)
-	}()
-
-	// Wait for batch
-	<-output
-	elapsed := time.Since(start)
-
-	// Should have waited ~200ms after last event timer not reset
-	if elapsed < 200*time.Millisecond {
-		t.Errorf("debounce triggered too early: %v", elapsed)
+	input <- FileChangeEvent{Path: "file1.go", Op: "write"}
+	waitPending(t, debouncer, 1)
+	clk.Advance(testWindow / 2)
+
+	input <- FileChangeEvent{Path: "file2.go", Op: "write"}
+	waitPending(t, debouncer, 2)
+
+	// The first timer was replaced
+	if n := clk.Waiters(); n != 1 {
+		t.Fatalf("expected 1 pending timer, got %d", n)
+	}
+
+	// A full window after the first event, but not after the second
+	clk.Advance(testWindow / 2)
+	if n := clk.Waiters(); n != 1 {
+		t.Fatalf("debounce triggered too early: %d pending timers", n)
+	}
+
+	clk.Advance(testWindow / 2)
+	if batch := receive(t, output); len(batch) != 2 {
+		t.Errorf("expected 2 events in batch, got %d", len(batch))
 	}
 }
 
 func TestDebouncer_SeparateBatches(t *testing.T) {
-	config := DebounceConfig{Window: 50 * time.Millisecond}
-	debouncer := NewDebouncer(config, &util.MockLogger{})
+	debouncer, clk := newTestDebouncer()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	input := make(chan FileChangeEvent)
 	output := debouncer.Debounce(ctx, input)
 
-	// Send two separate batches
-	go func() {
-		// First batch
-		input <- FileChangeEvent{Path: "file1.go", Op: "write"}
-
-		// Wait for first batch to trigger
-		time.Sleep(150 * time.Millisecond)
-
-		// Second batch
-		input <- FileChangeEvent{Path: "file2.go", Op: "write"}
-
-		time.Sleep(100 * time.Millisecond)
-		close(input)
-	}()
+	// First batch
+	input <- FileChangeEvent{Path: "file1.go", Op: "write"}
+	waitPending(t, debouncer, 1)
+	clk.Advance(testWindow)
+	if batch := receive(t, output); len(batch) != 1 || batch[0].Path != "file1.go" {
+		t.Errorf("first batch = %v, want [file1.go]", batch)
+	}
 
-	// Should receive two batches
-	batchCount := 0
-	for range output {
-		batchCount++
+	// Second batch
+	input <- FileChangeEvent{Path: "file2.go", Op: "write"}
+	waitPending(t, debouncer, 1)
+	clk.Advance(testWindow)
+	if batch := receive(t, output); len(batch) != 1 || batch[0].Path != "file2.go" {
+		t.Errorf("second batch = %v, want [file2.go]", batch)
 	}
 
-	if batchCount != 2 {
-		t.Errorf("expected 2 batches, got %d", batchCount)
+	close(input)
+	if _, ok := <-output; ok {
+		t.Error("expected output to close after input")
 	}
 }
 
 func TestDebouncer_CancelStopsProcessing(t *testing.T) {
-	config := DebounceConfig{Window: 100 * time.Millisecond}
-	debouncer := NewDebouncer(config, &util.MockLogger{})
+	debouncer, clk := newTestDebouncer()
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	input := make(chan FileChangeEvent)
 	output := debouncer.Debounce(ctx, input)
 
-	// Send event
+	// Send event, cancel before window expires
 	input <- FileChangeEvent{Path: "file1.go", Op: "write"}
-
-	// Cancel before window expires
-	time.Sleep(50 * time.Millisecond)
+	waitPending(t, debouncer, 1)
 	cancel()
 
 	// Output should close without sending
@@ -127,30 +152,42 @@ func TestDebouncer_CancelStopsProcessing(t *testing.T) {
 		if ok {
 			t.Error("should not receive event after cancel")
 		}
-	case <-time.After(200 * time.Millisecond):
+	case <-time.After(5 * time.Second):
 		t.Fatal("output channel should be closed")
 	}
+
+	if n := clk.Waiters(); n != 0 {
+		t.Errorf("expected timer stopped on cancel, %d pending", n)
+	}
 }
 
 func TestDebouncer_Reset(t *testing.T) {
-	config := DebounceConfig{Window: 100 * time.Millisecond}
-	debouncer := NewDebouncer(config, &util.MockLogger{})
+	debouncer, clk := newTestDebouncer()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	input := make(chan FileChangeEvent)
-	_ = debouncer.Debounce(ctx, input)
+	output := debouncer.Debounce(ctx, input)
 
-	// Add events
+	// Add events, reset before trigger
 	input <- FileChangeEvent{Path: "file1.go", Op: "write"}
-
-	// Reset before trigger
-	time.Sleep(50 * time.Millisecond)
+	waitPending(t, debouncer, 1)
 	debouncer.Reset()
 
-	// Verify events cleared
+	// Verify events and timer cleared
 	if len(debouncer.events) != 0 {
 		t.Errorf("expected 0 events after reset, got %d", len(debouncer.events))
 	}
+	if n := clk.Waiters(); n != 0 {
+		t.Errorf("expected 0 pending timers after reset, got %d", n)
+	}
+
+	// Nothing is sent once the window passes
+	clk.Advance(testWindow)
+	select {
+	case batch := <-output:
+		t.Errorf("unexpected batch after reset: %v", batch)
+	case <-time.After(10 * time.Millisecond):
+	}
 }
@@ -2,21 +2,63 @@ package watch
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/clock"
 	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/pkg/deployer"
+	kudevErrors "github.com/nanaki-93/kudev/pkg/errors"
+	"github.com/nanaki-93/kudev/pkg/filesync"
 	"github.com/nanaki-93/kudev/pkg/hash"
+	"github.com/nanaki-93/kudev/pkg/kubeconfig"
 	"github.com/nanaki-93/kudev/pkg/logging"
 	"github.com/nanaki-93/kudev/pkg/registry"
+	"github.com/nanaki-93/kudev/pkg/state"
+	"github.com/nanaki-93/kudev/pkg/timing"
 )
 
+// printDeployFailure prints a failed deploy. Rejections explained by the
+// deployer (e.g. admission webhook denials) are shown with their
+// violations and suggestion instead of the raw API error.
+func printDeployFailure(err error) {
+	var kerr *kudevErrors.DeployError
+	if !errors.As(err, &kerr) {
+		fmt.Printf("❌ Deploy failed: %v\n", err)
+		return
+	}
+	fmt.Printf("❌ Deploy failed: %s\n", kerr.UserMessage())
+	if suggestion := kerr.SuggestedAction(); suggestion != "" {
+		fmt.Printf("💡 %s\n", suggestion)
+	}
+}
+
 // RebuildFunc is the function signature for rebuild callbacks.
 type RebuildFunc func(ctx context.Context) error
 
+// LogSwitcher moves log streaming to a pod of the new rollout after a
+// redeploy, instead of tailing the old pod until it terminates
+// (see logs.TailHandle).
+type LogSwitcher interface {
+	SwitchRollout(hash string)
+}
+
+// ImagePruner deletes old images after a successful redeploy, keeping
+// those with the given source hashes.
+type ImagePruner interface {
+	Prune(ctx context.Context, keepHashes []string)
+}
+
+// FileSyncer copies changed files into the running pods (see spec.sync).
+type FileSyncer interface {
+	// Sync applies changes to the app's pods and returns how many were updated.
+	Sync(ctx context.Context, appName, namespace, container string, changes []filesync.Change) (int, error)
+}
+
 // Orchestrator coordinates file watching and rebuild triggering.
 type Orchestrator struct {
 	config     *config.DeploymentConfig
@@ -30,11 +72,68 @@ type Orchestrator struct {
 	deployer deployer.Deployer
 	registry *registry.Registry
 
+	// history persists rebuild events (nil disables recording)
+	history *state.Store
+
+	// triggers receives external rebuild requests (see Trigger).
+	triggers chan string
+
 	// State
 	mu            sync.Mutex
 	lastHash      string
 	rebuilding    bool
 	rebuildQueued bool
+	forceQueued   bool
+
+	// lastDeploy is the last successful deploy (rollback target)
+	lastDeploy *deployer.DeploymentOptions
+
+	// crashCancel stops the crash-loop check of the previous deploy
+	crashCancel context.CancelFunc
+
+	// contextPin guards against deploying after a kubeconfig context switch
+	contextPin *kubeconfig.ContextPin
+
+	// onContextDrift runs when drifted turns true
+	onContextDrift func()
+	drifted        bool
+
+	// syncer copies spec.sync files into pods instead of rebuilding (optional)
+	syncer FileSyncer
+
+	// logs follows every redeploy to the new pods (optional)
+	logs LogSwitcher
+
+	// pruner deletes old images after each redeploy (optional)
+	pruner ImagePruner
+
+	// budgetAlert is the last cluster capacity alert printed, so it is
+	// not repeated on every check (Run's goroutine only)
+	budgetAlert string
+
+	// status is reported to onStatus on every change (see Status)
+	status   Status
+	onStatus func(Status)
+
+	// resumed wakes Run up after Resume
+	resumed chan struct{}
+
+	// cycles counts finished rebuilds; bell rings after each one
+	cycles       int
+	failedCycles int
+	bell         bool
+
+	// finished is closed after maxCycles cycles (zero: never)
+	maxCycles int
+	finished  chan struct{}
+
+	// breaker suspends rebuilds after repeated failures at one stage;
+	// retry triggers the rebuild that probes for recovery
+	breaker breaker
+	retry   clock.Timer
+
+	// clock times debouncing and the breaker's retries (nil means clock.Real)
+	clock clock.Clock
 }
 
 // OrchestratorConfig configures the orchestrator.
@@ -44,34 +143,114 @@ type OrchestratorConfig struct {
 	Deployer deployer.Deployer
 	Registry *registry.Registry
 	Logger   logging.LoggerInterface
+
+	// State records rebuild events for 'kudev history' (optional)
+	State *state.Store
+
+	// LastDeploy is the deploy already running when Run starts,
+	// used as rollback target after a crash loop (optional)
+	LastDeploy *deployer.DeploymentOptions
+
+	// ContextPin is the kubeconfig context watch started with; deploys
+	// are refused while the current context differs (optional)
+	ContextPin *kubeconfig.ContextPin
+
+	// OnContextDrift is called once each time the current context moves
+	// away from ContextPin, e.g. to rebuild cached clients (optional)
+	OnContextDrift func()
+
+	// Syncer copies changes covered by spec.sync into the running pods
+	// instead of rebuilding (optional)
+	Syncer FileSyncer
+
+	// Logs is the log stream to move to the new pods after each
+	// redeploy (optional)
+	Logs LogSwitcher
+
+	// Pruner deletes old images after each successful redeploy, keeping
+	// the new one and the rollback target (optional)
+	Pruner ImagePruner
+
+	// OnStatus is called with the new status whenever it changes, from
+	// the orchestrator's goroutines (optional)
+	OnStatus func(Status)
+
+	// Bell rings the terminal bell after each rebuild, next to its
+	// CYCLE summary line
+	Bell bool
+
+	// MaxCycles makes Run return after this many rebuilds, with an
+	// error if any failed or ended not ready. Zero means no limit.
+	MaxCycles int
+
+	// Clock times debouncing and retries after repeated failures
+	// (optional, for tests)
+	Clock clock.Clock
 }
 
 // NewOrchestrator creates a new watch orchestrator.
 func NewOrchestrator(cfg OrchestratorConfig) (*Orchestrator, error) {
+	cfg.Logger = logging.OrDefault(cfg.Logger)
+
 	// Create watcher
 	watcher, err := NewFSWatcher(cfg.Config.Spec.BuildContextExclusions, cfg.Logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create watcher: %w", err)
 	}
+	watcher.WithGitignore(cfg.Config.Spec.UseGitignore).
+		WithExtraFiles(cfg.Config.BuildInputsOutsideContext()...)
 
 	// Create debouncer
-	debouncer := NewDebouncer(DefaultDebounceConfig(), cfg.Logger)
+	debounceConfig := DefaultDebounceConfig()
+	debounceConfig.Clock = clock.OrReal(cfg.Clock)
+	debouncer := NewDebouncer(debounceConfig, cfg.Logger)
 
 	// Create hash calculator
-	calculator := hash.NewCalculator(cfg.Config.ProjectRoot, cfg.Config.Spec.BuildContextExclusions)
+	calculator := hash.ForConfig(cfg.Config)
 
 	return &Orchestrator{
-		config:     cfg.Config,
-		watcher:    watcher,
-		debouncer:  debouncer,
-		calculator: calculator,
-		logger:     cfg.Logger,
-		builder:    cfg.Builder,
-		deployer:   cfg.Deployer,
-		registry:   cfg.Registry,
+		config:         cfg.Config,
+		watcher:        watcher,
+		debouncer:      debouncer,
+		calculator:     calculator,
+		logger:         cfg.Logger,
+		builder:        cfg.Builder,
+		deployer:       cfg.Deployer,
+		registry:       cfg.Registry,
+		history:        cfg.State,
+		triggers:       make(chan string, 1),
+		lastDeploy:     cfg.LastDeploy,
+		contextPin:     cfg.ContextPin,
+		onContextDrift: cfg.OnContextDrift,
+		syncer:         cfg.Syncer,
+		logs:           cfg.Logs,
+		pruner:         cfg.Pruner,
+		status:         Status{Phase: PhaseWatching},
+		onStatus:       cfg.OnStatus,
+		resumed:        make(chan struct{}, 1),
+		bell:           cfg.Bell,
+		maxCycles:      cfg.MaxCycles,
+		finished:       make(chan struct{}),
+		clock:          cfg.Clock,
 	}, nil
 }
 
+// clk returns the orchestrator's clock.
+func (o *Orchestrator) clk() clock.Clock {
+	return clock.OrReal(o.clock)
+}
+
+// Trigger requests a rebuild regardless of whether the source hash changed.
+// Safe to call from any goroutine; requests arriving while one is already
+// pending are coalesced.
+func (o *Orchestrator) Trigger(reason string) {
+	select {
+	case o.triggers <- reason:
+	default:
+		// A trigger is already pending
+	}
+}
+
 // Run starts watching for changes and triggering rebuilds.
 // Blocks until context is cancelled.
 func (o *Orchestrator) Run(ctx context.Context) error {
@@ -81,14 +260,15 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to calculate initial hash: %w", err)
 	}
 	o.lastHash = initialHash
+	o.update(func(s *Status) { s.Hash = initialHash })
 
 	o.logger.Info("starting watch mode",
-		"directory", o.config.ProjectRoot,
+		"directory", o.config.BuildContextDir(),
 		"hash", initialHash,
 	)
 
 	// Start watching
-	events, err := o.watcher.Watch(ctx, o.config.ProjectRoot)
+	events, err := o.watcher.Watch(ctx, o.config.BuildContextDir())
 	if err != nil {
 		return fmt.Errorf("failed to start watcher: %w", err)
 	}
@@ -96,10 +276,30 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 	// Debounce events
 	batches := o.debouncer.Debounce(ctx, events)
 
+	// Scheduled rebuilds (spec.watch.rebuildEvery)
+	var scheduled <-chan time.Time
+	if every := o.rebuildEvery(); every > 0 {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		scheduled = ticker.C
+		o.logger.Info("scheduled rebuilds enabled", "every", every)
+	}
+
+	// Cluster capacity checks (spec.watch.resourceBudget)
+	var budgetChecks <-chan time.Time
+	if every := o.budgetInterval(); every > 0 {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		budgetChecks = ticker.C
+	}
+
 	fmt.Println("Watching for changes...")
 	fmt.Println("Press Ctrl+C to stop")
 	fmt.Println()
 
+	// Changes seen while paused, handled on Resume
+	var pausedChanges bool
+
 	// Process batches
 	for {
 		select {
@@ -107,18 +307,60 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 			o.watcher.Close()
 			return nil
 
+		case <-o.finished:
+			o.watcher.Close()
+			return o.cyclesResult()
+
 		case batch, ok := <-batches:
 			if !ok {
 				return nil
 			}
+			if o.paused() {
+				o.logger.Debug("watching paused, change deferred", "files", len(batch))
+				pausedChanges = true
+				continue
+			}
+			if o.suspended() {
+				// The retry rebuilds regardless of the hash
+				o.logger.Debug("rebuilds suspended after repeated failures, change deferred", "files", len(batch))
+				continue
+			}
+
+			o.handleBatch(ctx, batch, false)
 
-			o.handleBatch(ctx, batch)
+		case <-o.resumed:
+			if pausedChanges {
+				pausedChanges = false
+				o.handleBatch(ctx, nil, false)
+			}
+
+		case <-scheduled:
+			if !o.paused() && !o.suspended() {
+				o.Trigger("scheduled rebuild")
+			}
+
+		case <-budgetChecks:
+			o.checkBudget(ctx)
+
+		case reason := <-o.triggers:
+			o.logger.Info("rebuild triggered", "reason", reason)
+			fmt.Printf("[Rebuild triggered: %s]\n", reason)
+			o.handleBatch(ctx, nil, true)
 		}
 	}
 }
 
+// rebuildEvery returns the scheduled rebuild interval, or 0 if disabled.
+func (o *Orchestrator) rebuildEvery() time.Duration {
+	if o.config.Spec.Watch == nil {
+		return 0
+	}
+	return o.config.Spec.Watch.RebuildEvery.Duration
+}
+
 // handleBatch processes a batch of file change events.
-func (o *Orchestrator) handleBatch(ctx context.Context, events []FileChangeEvent) {
+// If force is true, the rebuild happens even when the source hash is unchanged.
+func (o *Orchestrator) handleBatch(ctx context.Context, events []FileChangeEvent, force bool) {
 	// Log changed files
 	for _, event := range events {
 		o.logger.Debug("file changed",
@@ -127,10 +369,16 @@ func (o *Orchestrator) handleBatch(ctx context.Context, events []FileChangeEvent
 		)
 	}
 
+	// No more rebuilds after MaxCycles
+	if o.isFinished() {
+		return
+	}
+
 	// Check if rebuild is already in progress
 	o.mu.Lock()
 	if o.rebuilding {
 		o.rebuildQueued = true
+		o.forceQueued = o.forceQueued || force
 		o.mu.Unlock()
 		o.logger.Debug("rebuild already in progress, queueing")
 		return
@@ -138,45 +386,93 @@ func (o *Orchestrator) handleBatch(ctx context.Context, events []FileChangeEvent
 	o.rebuilding = true
 	o.mu.Unlock()
 
-	// Trigger rebuild
+	// Sync files into the pods when possible, otherwise rebuild
 	go func() {
-		o.triggerRebuild(ctx)
+		if force || !o.syncFiles(ctx, events) {
+			o.triggerRebuild(ctx, force)
+		}
+		o.setPhase(PhaseWatching)
 
 		o.mu.Lock()
 		o.rebuilding = false
 		shouldRebuildAgain := o.rebuildQueued
+		forceAgain := o.forceQueued
 		o.rebuildQueued = false
+		o.forceQueued = false
 		o.mu.Unlock()
 
 		// If another change came in during rebuild, rebuild again
-		if shouldRebuildAgain && ctx.Err() == nil {
-			o.handleBatch(ctx, nil)
+		if shouldRebuildAgain && ctx.Err() == nil && !o.isFinished() {
+			o.handleBatch(ctx, nil, forceAgain)
 		}
 	}()
 }
 
-// triggerRebuild performs the rebuild if source has changed.
-func (o *Orchestrator) triggerRebuild(ctx context.Context) {
+// triggerRebuild performs the rebuild if source has changed (or force is set).
+func (o *Orchestrator) triggerRebuild(ctx context.Context, force bool) {
 	start := time.Now()
 
+	// Tag this rebuild's log lines, down to the deployer's
+	ctx = logging.WithCorrelationID(ctx, logging.NewCorrelationID())
+	log := logging.WithContext(o.logger, ctx)
+
+	// Every rebuild ends with a summary line, except when nothing changed
+	cycle := newCycle(start)
+	summarize := true
+	defer func() {
+		if summarize {
+			o.endCycle(cycle)
+		}
+	}()
+
 	// Calculate new hash
+	endHash := timing.Phase(ctx, "hash")
 	newHash, err := o.calculator.Calculate(ctx)
+	endHash()
 	if err != nil {
-		o.logger.Error(err, "failed to calculate hash")
+		log.Error(err, "failed to calculate hash")
+		o.recordFailure("hash", "", start, err)
+		cycle.fail("hash")
 		return
 	}
 
+	cycle.hash = newHash
+
 	// Check if hash changed
-	if newHash == o.lastHash {
-		o.logger.Debug("hash unchanged, skipping rebuild",
-			"hash", newHash,
-		)
-		fmt.Println("[No changes detected, skipping rebuild]")
+	if newHash == o.lastHash && !force {
+		// Nothing to build, but the app may have been deleted out-of-band
+		missing := o.missingResources(ctx)
+		if len(missing) == 0 {
+			log.Debug("hash unchanged, skipping rebuild",
+				"hash", newHash,
+			)
+			fmt.Println("[No changes detected, skipping rebuild]")
+			summarize = false
+			return
+		}
+
+		fmt.Printf("⚠ Gone from the cluster: %s\n", strings.Join(missing, ", "))
+		if o.recreate(ctx, cycle) {
+			return
+		}
+		// No previous deploy to replay: rebuild from scratch
+	}
+
+	// Never build for or deploy to a cluster other than the one watch
+	// started on; lastHash stays put so the change is picked up later
+	if err := o.checkContext(); err != nil {
+		o.update(func(s *Status) { s.Deploy = "refused: " + err.Error() })
+		o.recordFailure("context", newHash, start, err)
+		cycle.fail("context")
 		return
 	}
 
+	previousHash := o.lastHash
 	o.lastHash = newHash
 
+	// The previous rollout is being replaced; stop judging it
+	o.stopCrashWatch()
+
 	// Print rebuild status
 	fmt.Println()
 	fmt.Println("═══════════════════════════════════════════════════")
@@ -184,66 +480,523 @@ func (o *Orchestrator) triggerRebuild(ctx context.Context) {
 	fmt.Println("═══════════════════════════════════════════════════")
 	fmt.Println()
 
-	// Generate tag
+	// Generate tag (forced rebuilds get a timestamp so the image actually changes)
 	tagger := builder.NewTagger(o.calculator)
-	tag, err := tagger.GenerateTag(ctx, false)
+	tag, err := tagger.GenerateTag(ctx, force)
 	if err != nil {
-		o.logger.Error(err, "failed to generate tag")
+		log.Error(err, "failed to generate tag")
 		fmt.Printf("❌ Failed to generate tag: %v\n", err)
+		o.update(func(s *Status) { s.Build = "failed: " + err.Error() })
+		o.recordFailure("tag", newHash, start, err)
+		cycle.fail("tag")
 		return
 	}
 
 	// Build
 	fmt.Printf("Building %s:%s...\n", o.config.Spec.ImageName, tag)
-	opts := builder.BuildOptions{
-		SourceDir:      o.config.ProjectRoot,
-		DockerfilePath: o.config.Spec.DockerfilePath,
-		ImageName:      o.config.Spec.ImageName,
-		ImageTag:       tag,
-	}
+	o.setPhase(PhaseBuilding)
+	opts := builder.NewBuildOptions(o.config, tag)
+	opts.Output = o.config.Spec.Watch.EffectiveBuildOutput()
 
+	endBuild := timing.Phase(ctx, "build")
 	imageRef, err := o.builder.Build(ctx, opts)
+	endBuild()
 	if err != nil {
-		o.logger.Error(err, "build failed")
+		log.Error(err, "build failed")
 		fmt.Printf("❌ Build failed: %v\n", err)
+		o.update(func(s *Status) { s.Build = "failed: " + err.Error() })
+		o.recordFailure("build", newHash, start, err)
+		cycle.fail("build")
+		o.emit(ctx, deployer.ReasonBuildFailed, logging.Redact(fmt.Sprintf("Build of %s failed: %v", tag, err)))
 		return
 	}
 
 	// Load image
 	fmt.Println("Loading image to cluster...")
-	if err := o.registry.Load(ctx, imageRef.FullRef); err != nil {
-		o.logger.Error(err, "image load failed")
+	o.setPhase(PhaseLoading)
+	endLoad := timing.Phase(ctx, "load")
+	err = o.registry.Load(ctx, imageRef.FullRef)
+	endLoad()
+	if err != nil {
+		log.Error(err, "image load failed")
 		fmt.Printf("❌ Image load failed: %v\n", err)
+		o.update(func(s *Status) { s.Build = "image load failed: " + err.Error() })
+		o.recordFailure("load", newHash, start, err)
+		cycle.fail("load")
+		return
+	}
+	built := fmt.Sprintf("%s built in %s", imageRef.FullRef, time.Since(start).Round(time.Second))
+	o.update(func(s *Status) { s.Build = built })
+
+	// Paused via 'kudev freeze': keep building, but leave the running pods alone
+	if o.isFrozen(ctx) {
+		// Forget this hash so the first rebuild after unfreezing deploys
+		o.lastHash = previousHash
+		fmt.Println()
+		fmt.Printf("❄ Build ready: %s (built in %s)\n", imageRef.FullRef, time.Since(start).Round(time.Millisecond))
+		fmt.Println("  Deploy skipped: app is frozen. Run 'kudev unfreeze' to resume.")
+		o.update(func(s *Status) { s.Deploy = "skipped: app is frozen" })
+		cycle.result = CycleFrozen
+		fmt.Println()
 		return
 	}
 
 	// Deploy
 	fmt.Println("Deploying...")
+	o.setPhase(PhaseDeploying)
 	deployOpts := deployer.DeploymentOptions{
 		Config:    o.config,
 		ImageRef:  imageRef.FullRef,
 		ImageHash: newHash,
 	}
 
+	endDeploy := timing.Phase(ctx, "deploy")
 	status, err := o.deployer.Upsert(ctx, deployOpts)
+	endDeploy()
 	if err != nil {
-		o.logger.Error(err, "deploy failed")
-		fmt.Printf("❌ Deploy failed: %v\n", err)
+		log.Error(err, "deploy failed")
+		printDeployFailure(err)
+		o.update(func(s *Status) { s.Deploy = "failed: " + err.Error() })
+		o.recordFailure("deploy", newHash, start, err)
+		cycle.fail("deploy")
 		return
 	}
 
 	// Success!
+	o.mu.Lock()
+	previousDeploy := o.lastDeploy
+	o.lastDeploy = &deployOpts
+	o.mu.Unlock()
+
+	o.emit(ctx, deployer.ReasonDeployed, fmt.Sprintf("Deployed %s (source hash %s)", deployOpts.ImageRef, newHash))
+
+	readyErr := o.waitForReady(ctx)
+	status = o.statusAfterWait(ctx, status)
+	o.switchLogs(newHash)
+
 	elapsed := time.Since(start)
+	o.record(state.Event{
+		Type:       state.EventDeploy,
+		Hash:       newHash,
+		Image:      imageRef.FullRef,
+		ImageID:    imageRef.ID,
+		DurationMs: elapsed.Milliseconds(),
+	})
+	o.update(func(s *Status) {
+		s.Hash = newHash
+		s.Deploy = deployResult("deployed", elapsed, readyErr)
+	})
+	cycle.result = CycleOK
 	fmt.Println()
 	fmt.Println("═══════════════════════════════════════════════════")
-	fmt.Printf("  ✓ Rebuild complete in %s\n", elapsed.Round(time.Millisecond))
+	if readyErr != nil {
+		cycle.result = CycleNotReady
+		fmt.Printf("  ⚠ Deployed in %s, but not ready: %v\n", elapsed.Round(time.Millisecond), readyErr)
+	} else {
+		fmt.Printf("  ✓ Rebuild complete in %s\n", elapsed.Round(time.Millisecond))
+	}
 	fmt.Printf("  Status: %s (%d/%d replicas)\n", status.Status, status.ReadyReplicas, status.DesiredReplicas)
 	fmt.Println("═══════════════════════════════════════════════════")
 	fmt.Println()
+	o.pruneImages(ctx, newHash, previousDeploy)
 	fmt.Println("Watching for changes...")
+
+	o.startCrashWatch(ctx, deployOpts, previousDeploy)
+}
+
+// pruneImages lets the pruner delete old images, keeping hash and the
+// previous deploy's image, which a crash loop rolls back to.
+func (o *Orchestrator) pruneImages(ctx context.Context, hash string, previous *deployer.DeploymentOptions) {
+	if o.pruner == nil {
+		return
+	}
+	keep := []string{hash}
+	if previous != nil && previous.ImageHash != "" {
+		keep = append(keep, previous.ImageHash)
+	}
+	o.pruner.Prune(ctx, keep)
+}
+
+// missingResources returns the app's resources deleted out-of-band, if the
+// deployer can tell.
+func (o *Orchestrator) missingResources(ctx context.Context) []string {
+	checker, ok := o.deployer.(deployer.PresenceChecker)
+	if !ok {
+		return nil
+	}
+
+	missing, err := checker.Missing(ctx, o.config.Metadata.Name, o.config.Spec.Namespace)
+	if err != nil {
+		o.logger.Debug("failed to check for deleted resources", "error", err)
+		return nil
+	}
+	return missing
+}
+
+// recreate redeploys the last deploy after its resources were deleted
+// out-of-band; Upsert re-creates the namespace and every resource.
+// Returns false when there is no previous deploy to replay.
+func (o *Orchestrator) recreate(ctx context.Context, cycle *rebuildCycle) bool {
+	start := cycle.start
+	o.mu.Lock()
+	last := o.lastDeploy
+	o.mu.Unlock()
+	if last == nil {
+		return false
+	}
+
+	if err := o.checkContext(); err != nil {
+		o.recordFailure("context", last.ImageHash, start, err)
+		cycle.fail("context")
+		return true
+	}
+
+	o.stopCrashWatch()
+
+	fmt.Printf("Re-creating %s in namespace %s...\n", o.config.Metadata.Name, o.config.Spec.Namespace)
+	status, err := o.deployer.Upsert(ctx, *last)
+	if err != nil {
+		o.logger.Error(err, "re-create failed")
+		fmt.Printf("❌ Re-create failed: %v\n", err)
+		o.update(func(s *Status) { s.Deploy = "re-create failed: " + err.Error() })
+		o.recordFailure("deploy", last.ImageHash, start, err)
+		cycle.fail("deploy")
+		return true
+	}
+
+	o.emit(ctx, deployer.ReasonDeployed, fmt.Sprintf("Re-created %s after its resources were deleted", last.ImageRef))
+
+	readyErr := o.waitForReady(ctx)
+	status = o.statusAfterWait(ctx, status)
+	o.switchLogs(last.ImageHash)
+
+	elapsed := time.Since(start)
+	o.record(state.Event{
+		Type:       state.EventDeploy,
+		Hash:       last.ImageHash,
+		Image:      last.ImageRef,
+		DurationMs: elapsed.Milliseconds(),
+	})
+	o.update(func(s *Status) { s.Deploy = deployResult("re-created", elapsed, readyErr) })
+	cycle.result = CycleOK
+	if readyErr != nil {
+		cycle.result = CycleNotReady
+		fmt.Printf("⚠ Re-created in %s, but not ready: %v\n", elapsed.Round(time.Millisecond), readyErr)
+	} else {
+		fmt.Printf("✓ Re-created in %s (%d/%d replicas)\n", elapsed.Round(time.Millisecond), status.ReadyReplicas, status.DesiredReplicas)
+	}
+
+	o.startCrashWatch(ctx, *last, nil)
+	return true
+}
+
+// syncFiles copies the changed files into the running pods when every one
+// of them is covered by spec.sync. Returns false when the batch needs a
+// rebuild instead, including when the sync itself fails.
+func (o *Orchestrator) syncFiles(ctx context.Context, events []FileChangeEvent) bool {
+	if o.syncer == nil || len(events) == 0 {
+		return false
+	}
+
+	paths := make([]string, 0, len(events))
+	for _, event := range events {
+		paths = append(paths, event.Path)
+	}
+	changes, ok := filesync.Plan(o.config.Spec.Sync, o.config.BuildContextDir(), paths)
+	if !ok {
+		return false
+	}
+	if len(changes) == 0 {
+		return true
+	}
+
+	// Frozen apps are left alone; the rebuild path reports it
+	if o.isFrozen(ctx) {
+		return false
+	}
+
+	start := time.Now()
+	o.setPhase(PhaseSyncing)
+	endSync := timing.Phase(ctx, "sync")
+	pods, err := o.syncer.Sync(ctx, o.config.Metadata.Name, o.config.Spec.Namespace, o.config.EffectiveContainerName(), changes)
+	endSync()
+	if err != nil {
+		o.logger.Error(err, "file sync failed, rebuilding")
+		fmt.Printf("⚠ File sync failed, rebuilding instead: %v\n", err)
+		return false
+	}
+
+	// lastHash is left alone: the image is now behind the pods, so the
+	// next change outside spec.sync rebuilds with the synced files too
+	fmt.Printf("⇄ Synced %d file(s) to %d pod(s) in %s\n", len(changes), pods, time.Since(start).Round(time.Millisecond))
+	o.update(func(s *Status) { s.Deploy = fmt.Sprintf("synced %d file(s) to %d pod(s)", len(changes), pods) })
+	return true
+}
+
+// checkContext reports (and prints) a kubeconfig context switch since startup.
+func (o *Orchestrator) checkContext() error {
+	if o.contextPin == nil {
+		return nil
+	}
+
+	err := o.contextPin.Check()
+	if err == nil {
+		o.drifted = false
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("❌ Deploy refused: %v\n", err)
+	var drift *kubeconfig.ContextDriftError
+	if errors.As(err, &drift) {
+		if !o.drifted && o.onContextDrift != nil {
+			o.onContextDrift()
+		}
+		o.drifted = true
+		fmt.Printf("  Switch back with 'kubectl config use-context %s' to resume,\n", o.contextPin.Name())
+		fmt.Println("  or restart 'kudev watch' to confirm the new context.")
+	}
+	fmt.Println()
+	return err
+}
+
+// waitForReady applies spec.readiness after a redeploy, bounded by
+// config.DefaultReadinessWatchTimeout unless spec.readiness.timeout is set.
+func (o *Orchestrator) waitForReady(ctx context.Context) error {
+	defer timing.Phase(ctx, "ready")()
+
+	readiness := o.config.Spec.Readiness
+	timeout := config.DefaultReadinessWatchTimeout
+	if readiness != nil && readiness.Timeout.Duration > 0 {
+		timeout = readiness.Timeout.Duration
+	}
+
+	fmt.Printf("Waiting for readiness (%s)...\n", readiness.EffectiveStrategy())
+	o.setPhase(PhaseWaiting)
+	return o.deployer.WaitForReady(ctx, deployer.WaitOptions{
+		AppName:   o.config.Metadata.Name,
+		Namespace: o.config.Spec.Namespace,
+		Timeout:   timeout,
+		Readiness: readiness,
+		WorkDir:   o.config.ProjectRoot,
+		Progress: func(pods []deployer.PodProgress) {
+			for _, p := range pods {
+				fmt.Printf("  %s\n", p)
+			}
+		},
+	})
+}
+
+// statusAfterWait returns the app's status once waitForReady returned;
+// deployed is the status Upsert returned, used when it cannot be read.
+func (o *Orchestrator) statusAfterWait(ctx context.Context, deployed *deployer.DeploymentStatus) *deployer.DeploymentStatus {
+	status, err := o.deployer.Status(ctx, o.config.Metadata.Name, o.config.Spec.Namespace)
+	if err != nil || status == nil {
+		o.logger.Debug("failed to refresh status after the readiness wait", "error", err)
+		return deployed
+	}
+	return status
+}
+
+// crashLoopConfig returns spec.watch.crashLoop (nil when unset; its
+// methods apply defaults).
+func (o *Orchestrator) crashLoopConfig() *config.CrashLoopConfig {
+	if o.config.Spec.Watch == nil {
+		return nil
+	}
+	return o.config.Spec.Watch.CrashLoop
+}
+
+// startCrashWatch checks the new rollout for crash loops in the background,
+// so file changes are still picked up while it runs.
+func (o *Orchestrator) startCrashWatch(ctx context.Context, deployed deployer.DeploymentOptions, previous *deployer.DeploymentOptions) {
+	detector, ok := o.deployer.(deployer.CrashDetector)
+	if !ok {
+		return
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	o.mu.Lock()
+	o.crashCancel = cancel
+	o.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		o.checkCrashLoop(watchCtx, detector, deployed, previous)
+	}()
+}
+
+// stopCrashWatch cancels a running crash-loop check.
+func (o *Orchestrator) stopCrashWatch() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.crashCancel != nil {
+		o.crashCancel()
+		o.crashCancel = nil
+	}
+}
+
+// checkCrashLoop reports a crash-looping rollout inline and, if
+// spec.watch.crashLoop.rollback is set, redeploys the previous image.
+func (o *Orchestrator) checkCrashLoop(ctx context.Context, detector deployer.CrashDetector, deployed deployer.DeploymentOptions, previous *deployer.DeploymentOptions) {
+	crashCfg := o.crashLoopConfig()
+
+	report, err := detector.DetectCrashLoop(ctx, o.config.Metadata.Name, o.config.Spec.Namespace, deployed.ImageRef,
+		deployer.CrashLoopOptions{
+			Restarts: crashCfg.EffectiveRestarts(),
+			Window:   crashCfg.EffectiveWindow(),
+		})
+	if err != nil {
+		if ctx.Err() == nil {
+			o.logger.Debug("crash loop check failed", "error", err)
+		}
+		return
+	}
+	if report == nil {
+		return
+	}
+
+	printCrashReport(report)
+	o.update(func(s *Status) {
+		s.Deploy = fmt.Sprintf("crash-looping: pod %s restarted %d times", report.PodName, report.Restarts)
+	})
+	o.record(state.Event{
+		Type:    state.EventFailure,
+		Stage:   "crashloop",
+		Hash:    deployed.ImageHash,
+		Image:   deployed.ImageRef,
+		Message: logging.Redact(fmt.Sprintf("pod %s restarted %d times: %s", report.PodName, report.Restarts, report.Reason)),
+	})
+
+	if !crashCfg.RollbackEnabled() || previous == nil {
+		fmt.Println("Fix the code and save to rebuild.")
+		fmt.Println()
+		fmt.Println("Watching for changes...")
+		return
+	}
+
+	start := time.Now()
+	if err := o.checkContext(); err != nil {
+		o.recordFailure("context", previous.ImageHash, start, err)
+		return
+	}
+
+	fmt.Printf("↺ Rolling back to %s...\n", previous.ImageRef)
+	if _, err := o.deployer.Upsert(ctx, *previous); err != nil {
+		fmt.Printf("❌ Rollback failed: %v\n", err)
+		o.update(func(s *Status) { s.Deploy = "rollback failed: " + err.Error() })
+		o.recordFailure("rollback", previous.ImageHash, start, err)
+		return
+	}
+
+	o.mu.Lock()
+	o.lastDeploy = previous
+	o.mu.Unlock()
+	o.switchLogs(previous.ImageHash)
+
+	o.emit(ctx, deployer.ReasonRollback, fmt.Sprintf("Rolled back to %s: pod %s of %s was crash-looping", previous.ImageRef, report.PodName, deployed.ImageRef))
+	o.record(state.Event{
+		Type:       state.EventRollback,
+		Hash:       previous.ImageHash,
+		Image:      previous.ImageRef,
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+	fmt.Printf("✓ Rolled back to %s\n", previous.ImageRef)
+	o.update(func(s *Status) {
+		s.Hash = previous.ImageHash
+		s.Deploy = "rolled back to " + previous.ImageRef + " (crash loop)"
+	})
+	fmt.Println()
+	fmt.Println("Watching for changes...")
+}
+
+// printCrashReport shows why the new pod keeps crashing.
+func printCrashReport(r *deployer.CrashReport) {
+	fmt.Println()
+	fmt.Println("═══════════════════════════════════════════════════")
+	fmt.Printf("  ✗ Pod %s is crash-looping (%d restarts)\n", r.PodName, r.Restarts)
+	if r.Reason != "" {
+		if r.ExitCode != 0 {
+			fmt.Printf("  Reason: %s (exit code %d)\n", r.Reason, r.ExitCode)
+		} else {
+			fmt.Printf("  Reason: %s\n", r.Reason)
+		}
+	}
+	if r.Message != "" {
+		fmt.Printf("  Message: %s\n", r.Message)
+	}
+	fmt.Println("═══════════════════════════════════════════════════")
+
+	if len(r.Logs) > 0 {
+		fmt.Println("Last logs:")
+		for _, line := range r.Logs {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	fmt.Println()
+}
+
+// isFrozen reports whether redeploys are paused with 'kudev freeze'.
+// Lookup errors are treated as not frozen so a flaky API never blocks the loop.
+func (o *Orchestrator) isFrozen(ctx context.Context) bool {
+	freezer, ok := o.deployer.(deployer.Freezer)
+	if !ok {
+		return false
+	}
+
+	frozen, err := freezer.IsFrozen(ctx, o.config.Metadata.Name, o.config.Spec.Namespace)
+	if err != nil {
+		o.logger.Debug("failed to check freeze annotation", "error", err)
+		return false
+	}
+	return frozen
+}
+
+// switchLogs moves log streaming to the rollout labeled kudev-hash=hash.
+func (o *Orchestrator) switchLogs(hash string) {
+	if o.logs != nil {
+		o.logs.SwitchRollout(hash)
+	}
+}
+
+// emit records a Kubernetes Event on the app's Deployment, if the deployer
+// supports it. Best-effort: errors are only logged.
+func (o *Orchestrator) emit(ctx context.Context, reason, message string) {
+	emitter, ok := o.deployer.(deployer.EventEmitter)
+	if !ok {
+		return
+	}
+	if err := emitter.Emit(ctx, o.config.Metadata.Name, o.config.Spec.Namespace, reason, message); err != nil {
+		o.logger.Debug("failed to emit event", "reason", reason, "error", err)
+	}
+}
+
+// recordFailure records a failed rebuild cycle.
+func (o *Orchestrator) recordFailure(stage, hash string, start time.Time, err error) {
+	o.record(state.Event{
+		Type:       state.EventFailure,
+		Stage:      stage,
+		Hash:       hash,
+		DurationMs: time.Since(start).Milliseconds(),
+		Message:    logging.Redact(err.Error()),
+	})
+}
+
+// record persists an event. History is best-effort: errors are only logged.
+func (o *Orchestrator) record(e state.Event) {
+	if o.history == nil {
+		return
+	}
+	if err := o.history.Record(e); err != nil {
+		o.logger.Debug("failed to record history event", "error", err)
+	}
 }
 
 // Close stops the orchestrator and releases resources.
 func (o *Orchestrator) Close() error {
+	o.stopCrashWatch()
+	o.mu.Lock()
+	o.stopRetry()
+	o.mu.Unlock()
 	return o.watcher.Close()
 }
@@ -4,10 +4,23 @@ package watch
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/nanaki-93/kudev/pkg/builder"
+	"github.com/nanaki-93/kudev/pkg/config"
 	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/filesync"
+	"github.com/nanaki-93/kudev/pkg/hash"
+	"github.com/nanaki-93/kudev/pkg/kubeconfig"
+	"github.com/nanaki-93/kudev/pkg/registry"
+	"github.com/nanaki-93/kudev/pkg/state"
+	"github.com/nanaki-93/kudev/test/util"
 )
 
 type mockBuilder struct {
@@ -31,10 +44,15 @@ func (m *mockDeployer) Upsert(ctx context.Context, opts deployer.DeploymentOptio
 	return &deployer.DeploymentStatus{Status: "Running"}, nil
 }
 
-func (m *mockDeployer) Delete(ctx context.Context, name, ns string) error { return nil }
+func (m *mockDeployer) Apply(ctx context.Context, objects []runtime.Object) error { return nil }
+func (m *mockDeployer) Delete(ctx context.Context, name, ns string) error         { return nil }
 func (m *mockDeployer) Status(ctx context.Context, name, ns string) (*deployer.DeploymentStatus, error) {
 	return &deployer.DeploymentStatus{}, nil
 }
+func (m *mockDeployer) WaitForReady(ctx context.Context, opts deployer.WaitOptions) error { return nil }
+func (m *mockDeployer) WaitForDeletion(ctx context.Context, opts deployer.WaitOptions) error {
+	return nil
+}
 
 func TestOrchestrator_SkipsIfHashUnchanged(t *testing.T) {
 	// This would require more setup with temp directories
@@ -46,3 +64,577 @@ func TestOrchestrator_OnlyOneRebuildAtATime(t *testing.T) {
 	// Test that concurrent events don't cause concurrent rebuilds
 	t.Skip("requires full integration setup")
 }
+
+func TestOrchestrator_RecordsBuildFailure(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := state.NewStore(dir)
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Spec:        config.SpecConfig{ImageName: "test"},
+		},
+		calculator: hash.NewCalculator(dir, nil),
+		logger:     &util.MockLogger{},
+		builder:    &mockBuilder{buildErr: errors.New("boom")},
+		deployer:   &mockDeployer{},
+		history:    store,
+	}
+
+	o.triggerRebuild(context.Background(), true)
+
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(st.Events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(st.Events))
+	}
+	e := st.Events[0]
+	if e.Type != state.EventFailure || e.Stage != "build" || e.Message != "boom" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+// emittingDeployer records the reasons of emitted events.
+type emittingDeployer struct {
+	mockDeployer
+	reasons []string
+}
+
+func (m *emittingDeployer) Emit(ctx context.Context, appName, namespace, reason, message string) error {
+	m.reasons = append(m.reasons, reason)
+	return nil
+}
+
+func TestOrchestrator_EmitsBuildFailedEvent(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dep := &emittingDeployer{}
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Spec:        config.SpecConfig{ImageName: "test"},
+		},
+		calculator: hash.NewCalculator(dir, nil),
+		logger:     &util.MockLogger{},
+		builder:    &mockBuilder{buildErr: errors.New("boom")},
+		deployer:   dep,
+	}
+
+	o.triggerRebuild(context.Background(), true)
+
+	if len(dep.reasons) != 1 || dep.reasons[0] != deployer.ReasonBuildFailed {
+		t.Errorf("emitted %v, want [%s]", dep.reasons, deployer.ReasonBuildFailed)
+	}
+}
+
+// crashingDeployer reports a crash loop for every rollout.
+type crashingDeployer struct {
+	mockDeployer
+	deployed []string
+}
+
+func (m *crashingDeployer) Upsert(ctx context.Context, opts deployer.DeploymentOptions) (*deployer.DeploymentStatus, error) {
+	m.deployed = append(m.deployed, opts.ImageRef)
+	return m.mockDeployer.Upsert(ctx, opts)
+}
+
+func (m *crashingDeployer) DetectCrashLoop(ctx context.Context, appName, namespace, imageRef string, opts deployer.CrashLoopOptions) (*deployer.CrashReport, error) {
+	return &deployer.CrashReport{PodName: "test-abc", Restarts: opts.Restarts, Reason: "Error", ExitCode: 1}, nil
+}
+
+func TestOrchestrator_CrashLoop(t *testing.T) {
+	previous := &deployer.DeploymentOptions{ImageRef: "test:kudev-aaaaaaaa", ImageHash: "aaaaaaaa"}
+	deployed := deployer.DeploymentOptions{ImageRef: "test:kudev-bbbbbbbb", ImageHash: "bbbbbbbb"}
+
+	tests := []struct {
+		name         string
+		rollback     bool
+		previous     *deployer.DeploymentOptions
+		wantDeployed []string
+		wantEvents   []state.EventType
+	}{
+		{
+			name:       "report only",
+			previous:   previous,
+			wantEvents: []state.EventType{state.EventFailure},
+		},
+		{
+			name:         "rollback",
+			rollback:     true,
+			previous:     previous,
+			wantDeployed: []string{"test:kudev-aaaaaaaa"},
+			wantEvents:   []state.EventType{state.EventFailure, state.EventRollback},
+		},
+		{
+			name:       "rollback without previous deploy",
+			rollback:   true,
+			wantEvents: []state.EventType{state.EventFailure},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := state.NewStore(dir)
+			dep := &crashingDeployer{}
+			o := &Orchestrator{
+				config: &config.DeploymentConfig{
+					Metadata: config.MetadataConfig{Name: "test"},
+					Spec: config.SpecConfig{
+						Watch: &config.WatchConfig{
+							CrashLoop: &config.CrashLoopConfig{Rollback: tt.rollback},
+						},
+					},
+				},
+				logger:   &util.MockLogger{},
+				deployer: dep,
+				history:  store,
+			}
+
+			o.checkCrashLoop(context.Background(), dep, deployed, tt.previous)
+
+			if len(dep.deployed) != len(tt.wantDeployed) {
+				t.Fatalf("deployed %v, want %v", dep.deployed, tt.wantDeployed)
+			}
+			for i := range tt.wantDeployed {
+				if dep.deployed[i] != tt.wantDeployed[i] {
+					t.Errorf("deployed[%d] = %q, want %q", i, dep.deployed[i], tt.wantDeployed[i])
+				}
+			}
+
+			st, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if len(st.Events) != len(tt.wantEvents) {
+				t.Fatalf("got %d events, want %d: %+v", len(st.Events), len(tt.wantEvents), st.Events)
+			}
+			for i, want := range tt.wantEvents {
+				if st.Events[i].Type != want {
+					t.Errorf("event[%d] = %s, want %s", i, st.Events[i].Type, want)
+				}
+			}
+			if st.Events[0].Stage != "crashloop" {
+				t.Errorf("failure stage = %q, want crashloop", st.Events[0].Stage)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_RefusesDeployAfterContextSwitch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	current := kubeconfig.Context{Name: "kind-dev"}
+	pin, err := kubeconfig.NewContextPin(func() (*kubeconfig.Context, error) {
+		c := current
+		return &c, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := state.NewStore(dir)
+	b := &mockBuilder{}
+	drifts := 0
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Spec:        config.SpecConfig{ImageName: "test"},
+		},
+		calculator:     hash.NewCalculator(dir, nil),
+		logger:         &util.MockLogger{},
+		builder:        b,
+		deployer:       &mockDeployer{},
+		history:        store,
+		contextPin:     pin,
+		onContextDrift: func() { drifts++ },
+	}
+
+	current = kubeconfig.Context{Name: "prod"}
+	o.triggerRebuild(context.Background(), true)
+	o.triggerRebuild(context.Background(), true)
+	if drifts != 1 {
+		t.Errorf("OnContextDrift called %d times for one switch, want 1", drifts)
+	}
+
+	if b.buildCount != 0 {
+		t.Errorf("built %d times after a context switch, want 0", b.buildCount)
+	}
+	if o.lastHash != "" {
+		t.Errorf("lastHash = %q, want it unchanged so the change is retried", o.lastHash)
+	}
+
+	st, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(st.Events) != 2 || st.Events[0].Stage != "context" {
+		t.Errorf("unexpected events: %+v", st.Events)
+	}
+
+	// Switching back and away again is a new drift
+	current = kubeconfig.Context{Name: "kind-dev"}
+	if err := o.checkContext(); err != nil {
+		t.Fatalf("checkContext after switching back: %v", err)
+	}
+	current = kubeconfig.Context{Name: "prod"}
+	o.checkContext()
+	if drifts != 2 {
+		t.Errorf("OnContextDrift called %d times for two switches, want 2", drifts)
+	}
+}
+
+type mockSyncer struct {
+	synced     [][]filesync.Change
+	containers []string
+	err        error
+}
+
+func (m *mockSyncer) Sync(ctx context.Context, appName, namespace, container string, changes []filesync.Change) (int, error) {
+	m.synced = append(m.synced, changes)
+	m.containers = append(m.containers, container)
+	return 1, m.err
+}
+
+func TestOrchestrator_SyncFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "app.js"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		paths      []string
+		syncErr    error
+		wantSynced bool
+		wantResult bool
+	}{
+		{name: "covered change is synced", paths: []string{"src/app.js"}, wantSynced: true, wantResult: true},
+		{name: "uncovered change rebuilds", paths: []string{"src/app.js", "package.json"}},
+		{name: "failed sync rebuilds", paths: []string{"src/app.js"}, syncErr: errors.New("no tar"), wantSynced: true},
+		{name: "no events rebuilds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer := &mockSyncer{err: tt.syncErr}
+			o := &Orchestrator{
+				config: &config.DeploymentConfig{
+					ProjectRoot: dir,
+					Metadata:    config.MetadataConfig{Name: "test"},
+					Spec: config.SpecConfig{
+						ContainerName: "web",
+						Sync:          []config.SyncRule{{Local: "src", Remote: "/app/src"}},
+					},
+				},
+				logger:   &util.MockLogger{},
+				deployer: &mockDeployer{},
+				syncer:   syncer,
+			}
+
+			var events []FileChangeEvent
+			for _, p := range tt.paths {
+				events = append(events, FileChangeEvent{Path: p, Op: "write"})
+			}
+
+			if got := o.syncFiles(context.Background(), events); got != tt.wantResult {
+				t.Errorf("syncFiles() = %v, want %v", got, tt.wantResult)
+			}
+			if synced := len(syncer.synced) > 0; synced != tt.wantSynced {
+				t.Errorf("synced = %v, want %v", synced, tt.wantSynced)
+			}
+			for _, container := range syncer.containers {
+				if container != "web" {
+					t.Errorf("synced into container %q, want web", container)
+				}
+			}
+		})
+	}
+}
+
+// vanishedDeployer reports its resources as deleted until the next Upsert.
+type vanishedDeployer struct {
+	mockDeployer
+	missing  []string
+	deployed []string
+}
+
+func (m *vanishedDeployer) Upsert(ctx context.Context, opts deployer.DeploymentOptions) (*deployer.DeploymentStatus, error) {
+	m.deployed = append(m.deployed, opts.ImageRef)
+	m.missing = nil
+	return m.mockDeployer.Upsert(ctx, opts)
+}
+
+func (m *vanishedDeployer) Missing(ctx context.Context, appName, namespace string) ([]string, error) {
+	return m.missing, nil
+}
+
+func TestOrchestrator_RecreatesDeletedResources(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	calculator := hash.NewCalculator(dir, nil)
+	current, err := calculator.Calculate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &mockBuilder{}
+	dep := &vanishedDeployer{missing: []string{"namespace dev"}}
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Metadata:    config.MetadataConfig{Name: "test"},
+			Spec:        config.SpecConfig{ImageName: "test", Namespace: "dev"},
+		},
+		calculator: calculator,
+		logger:     &util.MockLogger{},
+		builder:    b,
+		deployer:   dep,
+		lastHash:   current,
+		lastDeploy: &deployer.DeploymentOptions{ImageRef: "test:kudev-aaaaaaaa", ImageHash: current},
+	}
+
+	o.triggerRebuild(context.Background(), false)
+
+	if b.buildCount != 0 {
+		t.Errorf("built %d times, want the last deploy replayed without a build", b.buildCount)
+	}
+	if len(dep.deployed) != 1 || dep.deployed[0] != "test:kudev-aaaaaaaa" {
+		t.Errorf("deployed %v, want [test:kudev-aaaaaaaa]", dep.deployed)
+	}
+
+	// Everything is back: the next unchanged cycle is a no-op
+	o.triggerRebuild(context.Background(), false)
+	if len(dep.deployed) != 1 {
+		t.Errorf("deployed %d times, want 1", len(dep.deployed))
+	}
+}
+
+// chanWatcher delivers the events sent on its channel.
+type chanWatcher struct {
+	events chan FileChangeEvent
+}
+
+func (w *chanWatcher) Watch(ctx context.Context, sourceDir string) (<-chan FileChangeEvent, error) {
+	return w.events, nil
+}
+
+func (w *chanWatcher) Close() error { return nil }
+
+func TestOrchestrator_ReportsStatus(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var phases []Phase
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Spec:        config.SpecConfig{ImageName: "test"},
+		},
+		calculator: hash.NewCalculator(dir, nil),
+		logger:     &util.MockLogger{},
+		builder:    &mockBuilder{buildErr: errors.New("boom")},
+		deployer:   &mockDeployer{},
+		onStatus:   func(s Status) { phases = append(phases, s.Phase) },
+	}
+
+	o.triggerRebuild(context.Background(), true)
+
+	if got := o.Status().Build; got != "failed: boom" {
+		t.Errorf("Build = %q, want %q", got, "failed: boom")
+	}
+	if len(phases) == 0 || phases[0] != PhaseBuilding {
+		t.Errorf("phases = %v, want building first", phases)
+	}
+}
+
+func TestOrchestrator_PauseDefersChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := &chanWatcher{events: make(chan FileChangeEvent, 1)}
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Spec:        config.SpecConfig{ImageName: "test"},
+		},
+		watcher:    watcher,
+		debouncer:  NewDebouncer(DebounceConfig{Window: 5 * time.Millisecond}, &util.MockLogger{}),
+		calculator: hash.NewCalculator(dir, nil),
+		logger:     &util.MockLogger{},
+		builder:    &mockBuilder{buildErr: errors.New("boom")},
+		deployer:   &mockDeployer{},
+		triggers:   make(chan string, 1),
+		resumed:    make(chan struct{}, 1),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go o.Run(ctx)
+
+	o.Pause()
+	for o.Status().Hash == "" {
+		time.Sleep(time.Millisecond) // Run computes the initial hash
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main // changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher.events <- FileChangeEvent{Path: "main.go", Op: "write"}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := o.Status().Build; got != "" {
+		t.Fatalf("built while paused: %q", got)
+	}
+
+	o.Resume()
+	deadline := time.Now().Add(5 * time.Second)
+	for o.Status().Build == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("change made while paused was not built after Resume")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOrchestrator_MaxCycles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Spec:        config.SpecConfig{ImageName: "test"},
+		},
+		watcher:    &chanWatcher{events: make(chan FileChangeEvent)},
+		debouncer:  NewDebouncer(DebounceConfig{Window: 5 * time.Millisecond}, &util.MockLogger{}),
+		calculator: hash.NewCalculator(dir, nil),
+		logger:     &util.MockLogger{},
+		builder:    &mockBuilder{buildErr: errors.New("boom")},
+		deployer:   &mockDeployer{},
+		triggers:   make(chan string, 1),
+		resumed:    make(chan struct{}, 1),
+		maxCycles:  1,
+		finished:   make(chan struct{}),
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- o.Run(context.Background()) }()
+	o.Trigger("test")
+
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "1 of 1 cycles failed" {
+			t.Errorf("Run() error = %v, want 1 of 1 cycles failed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after MaxCycles")
+	}
+}
+
+type mockPruner struct {
+	kept [][]string
+}
+
+func (m *mockPruner) Prune(ctx context.Context, keepHashes []string) {
+	m.kept = append(m.kept, keepHashes)
+}
+
+func TestOrchestrator_PrunesAfterDeploy(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	calculator := hash.NewCalculator(dir, nil)
+	current, err := calculator.Calculate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pruner := &mockPruner{}
+	o := &Orchestrator{
+		config: &config.DeploymentConfig{
+			ProjectRoot: dir,
+			Metadata:    config.MetadataConfig{Name: "test"},
+			Spec:        config.SpecConfig{ImageName: "test", Namespace: "default"},
+		},
+		calculator: calculator,
+		logger:     &util.MockLogger{},
+		builder:    &mockBuilder{},
+		deployer:   &mockDeployer{},
+		registry:   registry.NewRegistry("docker-desktop", &util.MockLogger{}),
+		lastDeploy: &deployer.DeploymentOptions{ImageRef: "test:kudev-aaaaaaaa", ImageHash: "aaaaaaaa"},
+		pruner:     pruner,
+	}
+	defer o.stopCrashWatch()
+
+	o.triggerRebuild(context.Background(), true)
+
+	if len(pruner.kept) != 1 {
+		t.Fatalf("pruned %d times, want 1", len(pruner.kept))
+	}
+	if got := pruner.kept[0]; len(got) != 2 || got[0] != current || got[1] != "aaaaaaaa" {
+		t.Errorf("kept %v, want the new hash and the rollback target", got)
+	}
+
+	// Failed rebuilds leave the images alone
+	o.builder = &mockBuilder{buildErr: errors.New("boom")}
+	o.triggerRebuild(context.Background(), true)
+	if len(pruner.kept) != 1 {
+		t.Errorf("pruned %d times after a failed build, want 1", len(pruner.kept))
+	}
+}
+
+// statusDeployer reports status from Status instead of the empty one.
+type statusDeployer struct {
+	mockDeployer
+	status *deployer.DeploymentStatus
+	err    error
+}
+
+func (m *statusDeployer) Status(ctx context.Context, name, ns string) (*deployer.DeploymentStatus, error) {
+	return m.status, m.err
+}
+
+func TestOrchestrator_StatusAfterWait(t *testing.T) {
+	deployed := &deployer.DeploymentStatus{Status: "Pending", ReadyReplicas: 0, DesiredReplicas: 2}
+	ready := &deployer.DeploymentStatus{Status: "Running", ReadyReplicas: 2, DesiredReplicas: 2}
+
+	dep := &statusDeployer{status: ready}
+	o := &Orchestrator{
+		config:   &config.DeploymentConfig{Metadata: config.MetadataConfig{Name: "test"}},
+		logger:   &util.MockLogger{},
+		deployer: dep,
+	}
+	if got := o.statusAfterWait(context.Background(), deployed); got != ready {
+		t.Errorf("statusAfterWait() = %+v, want the status after the wait", got)
+	}
+
+	dep.status, dep.err = nil, errors.New("boom")
+	if got := o.statusAfterWait(context.Background(), deployed); got != deployed {
+		t.Errorf("statusAfterWait() = %+v, want the deployed status on error", got)
+	}
+}
@@ -0,0 +1,75 @@
+package watch
+
+import (
+	"context"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/clock"
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// DigestResolver returns the registry digest an image reference
+// currently points to (see docker.Builder.RemoteDigest).
+type DigestResolver func(ctx context.Context, ref string) (string, error)
+
+// DigestPoller replaces file watching for a pre-built image
+// (spec.image.ref): it polls the registry and reports when the tag
+// moves to a new digest.
+type DigestPoller struct {
+	ref      string
+	interval time.Duration
+	resolve  DigestResolver
+	clock    clock.Clock
+	logger   logging.LoggerInterface
+}
+
+// NewDigestPoller polls ref every interval.
+func NewDigestPoller(ref string, interval time.Duration, resolve DigestResolver, logger logging.LoggerInterface) *DigestPoller {
+	return &DigestPoller{
+		ref:      ref,
+		interval: interval,
+		resolve:  resolve,
+		clock:    clock.Real,
+		logger:   logging.OrDefault(logger),
+	}
+}
+
+// SetClock replaces the clock timing the polls (for tests).
+func (p *DigestPoller) SetClock(c clock.Clock) {
+	p.clock = clock.OrReal(c)
+}
+
+// Run polls until ctx is cancelled, calling onChange with each digest
+// that differs from current (the deployed one; "" if unknown).
+//
+// A failed poll is logged and retried on the next tick. When onChange
+// fails, the digest counts as not deployed, so the next poll retries it.
+func (p *DigestPoller) Run(ctx context.Context, current string, onChange func(ctx context.Context, digest string) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock.After(p.interval):
+		}
+
+		digest, err := p.resolve(ctx, p.ref)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			p.logger.Error(err, "failed to poll image digest", "image", p.ref)
+			continue
+		}
+		if digest == current {
+			p.logger.Debug("image digest unchanged", "image", p.ref, "digest", digest)
+			continue
+		}
+
+		p.logger.Info("image digest changed", "image", p.ref, "from", current, "to", digest)
+		if err := onChange(ctx, digest); err != nil {
+			p.logger.Error(err, "failed to redeploy new image digest", "image", p.ref, "digest", digest)
+			continue
+		}
+		current = digest
+	}
+}
@@ -0,0 +1,70 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/clock"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestDigestPoller_Run(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC))
+
+	// Each poll returns the next answer
+	answers := []struct {
+		digest string
+		err    error
+	}{
+		{digest: "sha256:aaa"},                    // unchanged
+		{err: errors.New("registry unavailable")}, // logged, retried
+		{digest: "sha256:bbb"},                    // onChange fails, retried
+		{digest: "sha256:bbb"},                    // deployed
+		{digest: "sha256:bbb"},                    // unchanged
+	}
+	var mu sync.Mutex
+	polls := 0
+	resolve := func(ctx context.Context, ref string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		a := answers[polls]
+		polls++
+		return a.digest, a.err
+	}
+
+	var changes []string
+	onChange := func(ctx context.Context, digest string) error {
+		changes = append(changes, digest)
+		if len(changes) == 1 {
+			return errors.New("rollout failed")
+		}
+		return nil
+	}
+
+	poller := NewDigestPoller("ghcr.io/org/api:main", 30*time.Second, resolve, &util.MockLogger{})
+	poller.SetClock(fake)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- poller.Run(ctx, "sha256:aaa", onChange) }()
+
+	for range answers {
+		fake.BlockUntil(1)
+		fake.Advance(30 * time.Second)
+	}
+	fake.BlockUntil(1)
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if polls != len(answers) {
+		t.Errorf("polled %d times, want %d", polls, len(answers))
+	}
+	if len(changes) != 2 || changes[0] != "sha256:bbb" || changes[1] != "sha256:bbb" {
+		t.Errorf("onChange calls = %v, want a failed and a retried sha256:bbb", changes)
+	}
+}
@@ -0,0 +1,94 @@
+package watch
+
+import (
+	"fmt"
+	"time"
+)
+
+// Phase is what the orchestrator is doing.
+type Phase string
+
+const (
+	PhaseWatching  Phase = "watching"
+	PhaseSyncing   Phase = "syncing files"
+	PhaseBuilding  Phase = "building"
+	PhaseLoading   Phase = "loading image"
+	PhaseDeploying Phase = "deploying"
+	PhaseWaiting   Phase = "waiting for readiness"
+)
+
+// Status is a snapshot of the orchestrator for status displays
+// (see OrchestratorConfig.OnStatus).
+type Status struct {
+	Phase Phase
+
+	// Paused is set while file changes are ignored (see Pause)
+	Paused bool
+
+	// Hash is the source hash of the running deploy
+	Hash string
+
+	// Build and Deploy describe the outcome of the last build and deploy,
+	// e.g. "myapp:kudev-1a2b3c4d built in 12s" or "failed: ..."
+	Build  string
+	Deploy string
+
+	// Backoff is set while rebuilds are suspended after repeated
+	// failures at one stage, e.g. "build failed 5 times in a row,
+	// retrying at 15:04:05"
+	Backoff string
+}
+
+// Pause ignores file changes (and scheduled rebuilds) until Resume.
+// Explicit rebuilds through Trigger still run.
+func (o *Orchestrator) Pause() {
+	o.update(func(s *Status) { s.Paused = true })
+}
+
+// Resume watches file changes again; changes made while paused are
+// picked up right away.
+func (o *Orchestrator) Resume() {
+	o.update(func(s *Status) { s.Paused = false })
+	select {
+	case o.resumed <- struct{}{}:
+	default:
+		// A resume is already pending
+	}
+}
+
+// Status returns the current status.
+func (o *Orchestrator) Status() Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.status
+}
+
+// paused reports whether file changes are ignored.
+func (o *Orchestrator) paused() bool {
+	return o.Status().Paused
+}
+
+// update applies fn to the status and reports the result to OnStatus.
+func (o *Orchestrator) update(fn func(s *Status)) {
+	o.mu.Lock()
+	fn(&o.status)
+	status := o.status
+	o.mu.Unlock()
+
+	if o.onStatus != nil {
+		o.onStatus(status)
+	}
+}
+
+// setPhase updates the phase.
+func (o *Orchestrator) setPhase(phase Phase) {
+	o.update(func(s *Status) { s.Phase = phase })
+}
+
+// deployResult describes a finished deploy for Status.Deploy.
+func deployResult(what string, elapsed time.Duration, readyErr error) string {
+	if readyErr != nil {
+		return fmt.Sprintf("%s in %s, not ready: %v", what, elapsed.Round(time.Second), readyErr)
+	}
+	return fmt.Sprintf("%s in %s", what, elapsed.Round(time.Second))
+}
@@ -0,0 +1,119 @@
+// pkg/watch/trigger.go
+
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/logging"
+)
+
+// Triggerer accepts external rebuild requests.
+type Triggerer interface {
+	Trigger(reason string)
+}
+
+// TriggerServer exposes a local HTTP endpoint to force rebuilds.
+//
+// Endpoints:
+//
+//	POST /trigger   Force a rebuild (202 Accepted)
+//
+// Example:
+//
+//	curl -X POST http://localhost:4848/trigger
+type TriggerServer struct {
+	addr      string
+	triggerer Triggerer
+	logger    logging.LoggerInterface
+	server    *http.Server
+	listener  net.Listener
+}
+
+// NewTriggerServer creates a new trigger server listening on addr (e.g.
+// ":4848"). The endpoint is unauthenticated, so a missing host means
+// 127.0.0.1; use 0.0.0.0 explicitly to accept other machines.
+func NewTriggerServer(addr string, triggerer Triggerer, logger logging.LoggerInterface) *TriggerServer {
+	ts := &TriggerServer{
+		addr:      localAddr(addr),
+		triggerer: triggerer,
+		logger:    logging.OrDefault(logger),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/trigger", ts.handleTrigger)
+
+	ts.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return ts
+}
+
+// localAddr defaults a missing host in addr to 127.0.0.1. Invalid
+// addresses are returned unchanged for Start to report.
+func localAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// Start binds the listener and serves in the background.
+// The server shuts down when ctx is cancelled.
+func (ts *TriggerServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", ts.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", ts.addr, err)
+	}
+	ts.listener = ln
+
+	go func() {
+		if err := ts.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ts.logger.Error(err, "trigger server stopped")
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		ts.server.Shutdown(shutdownCtx)
+	}()
+
+	ts.logger.Info("trigger endpoint listening", "addr", ln.Addr().String())
+	return nil
+}
+
+// Addr returns the bound address (useful when listening on port 0).
+func (ts *TriggerServer) Addr() string {
+	if ts.listener == nil {
+		return ts.addr
+	}
+	return ts.listener.Addr().String()
+}
+
+// handleTrigger handles POST /trigger.
+func (ts *TriggerServer) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "http trigger from " + r.RemoteAddr
+	}
+
+	ts.triggerer.Trigger(reason)
+
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintln(w, "rebuild triggered")
+}
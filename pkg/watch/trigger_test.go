@@ -0,0 +1,104 @@
+// pkg/watch/trigger_test.go
+
+package watch
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+type mockTriggerer struct {
+	mu      sync.Mutex
+	reasons []string
+}
+
+func (m *mockTriggerer) Trigger(reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reasons = append(m.reasons, reason)
+}
+
+func TestTriggerServer_Post(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	triggerer := &mockTriggerer{}
+	server := NewTriggerServer("127.0.0.1:0", triggerer, &util.MockLogger{})
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	resp, err := http.Post("http://"+server.Addr()+"/trigger?reason=codegen", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+
+	triggerer.mu.Lock()
+	defer triggerer.mu.Unlock()
+	if len(triggerer.reasons) != 1 || triggerer.reasons[0] != "codegen" {
+		t.Errorf("reasons = %v, want [codegen]", triggerer.reasons)
+	}
+}
+
+func TestTriggerServer_RejectsGet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	triggerer := &mockTriggerer{}
+	server := NewTriggerServer("127.0.0.1:0", triggerer, &util.MockLogger{})
+	if err := server.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	resp, err := http.Get("http://" + server.Addr() + "/trigger")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+	if len(triggerer.reasons) != 0 {
+		t.Error("GET should not trigger a rebuild")
+	}
+}
+
+func TestOrchestrator_TriggerCoalesces(t *testing.T) {
+	o := &Orchestrator{triggers: make(chan string, 1)}
+
+	o.Trigger("first")
+	o.Trigger("second") // must not block
+
+	if got := <-o.triggers; got != "first" {
+		t.Errorf("trigger = %q, want %q", got, "first")
+	}
+}
+
+func TestLocalAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{":4848", "127.0.0.1:4848"},
+		{"localhost:4848", "localhost:4848"},
+		{"0.0.0.0:4848", "0.0.0.0:4848"},
+		{"[::1]:4848", "[::1]:4848"},
+		{"4848", "4848"},
+	}
+
+	for _, tt := range tests {
+		if got := localAddr(tt.addr); got != tt.want {
+			t.Errorf("localAddr(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 
+	"github.com/nanaki-93/kudev/pkg/gitignore"
 	"github.com/nanaki-93/kudev/pkg/logging"
 )
 
@@ -43,6 +44,15 @@ type FSWatcher struct {
 	watcher    *fsnotify.Watcher
 	exclusions []string
 	logger     logging.LoggerInterface
+
+	// useGitignore also ignores what .gitignore files do; ignored holds
+	// their rules, reloaded whenever one of them changes.
+	useGitignore bool
+	ignored      *gitignore.Matcher
+
+	// extraFiles are watched on top of the source directory (see
+	// WithExtraFiles).
+	extraFiles map[string]bool
 }
 
 // NewFSWatcher creates a new file system watcher.
@@ -55,10 +65,29 @@ func NewFSWatcher(exclusions []string, logger logging.LoggerInterface) (*FSWatch
 	return &FSWatcher{
 		watcher:    w,
 		exclusions: append(defaultExclusions, exclusions...),
-		logger:     logger,
+		logger:     logging.OrDefault(logger),
 	}, nil
 }
 
+// WithGitignore makes the watcher also skip paths ignored by the
+// .gitignore files of the watched directory (spec.useGitignore).
+func (w *FSWatcher) WithGitignore(enabled bool) *FSWatcher {
+	w.useGitignore = enabled
+	return w
+}
+
+// WithExtraFiles also reports changes to files outside the watched
+// directory, such as a Dockerfile at the root of a monorepo. Their
+// directories are watched, since editors often replace a file rather
+// than write it; events for other files there are dropped.
+func (w *FSWatcher) WithExtraFiles(paths ...string) *FSWatcher {
+	w.extraFiles = make(map[string]bool, len(paths))
+	for _, path := range paths {
+		w.extraFiles[filepath.Clean(path)] = true
+	}
+	return w
+}
+
 // defaultExclusions are always ignored.
 var defaultExclusions = []string{
 	".git",
@@ -81,10 +110,21 @@ var defaultExclusions = []string{
 
 // Watch starts watching the source directory.
 func (w *FSWatcher) Watch(ctx context.Context, sourceDir string) (<-chan FileChangeEvent, error) {
+	if w.useGitignore {
+		if err := w.loadGitignore(sourceDir); err != nil {
+			return nil, err
+		}
+	}
+
 	// Add directories recursively
 	if err := w.addDirectoriesRecursively(sourceDir); err != nil {
 		return nil, fmt.Errorf("failed to add directories: %w", err)
 	}
+	for path := range w.extraFiles {
+		if err := w.watcher.Add(filepath.Dir(path)); err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+	}
 
 	events := make(chan FileChangeEvent)
 
@@ -116,7 +156,7 @@ func (w *FSWatcher) addDirectoriesRecursively(root string) error {
 		}
 
 		// Check exclusions
-		if w.shouldExclude(relPath) {
+		if w.shouldExclude(relPath) || w.ignored.Match(relPath, true) {
 			return filepath.SkipDir
 		}
 
@@ -151,9 +191,20 @@ func (w *FSWatcher) processEvents(ctx context.Context, sourceDir string, out cha
 				continue
 			}
 
-			// Check exclusions
-			if w.shouldExclude(relPath) {
-				continue
+			// Edited .gitignore rules apply from the next event on
+			if w.useGitignore && filepath.Base(relPath) == gitignore.FileName {
+				if err := w.loadGitignore(sourceDir); err != nil {
+					w.logger.Error(err, "failed to reload .gitignore", "path", relPath)
+				}
+			}
+
+			// Check exclusions; outside the source directory only the
+			// extra files count
+			if !w.extraFiles[filepath.Clean(event.Name)] {
+				outside := relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+				if outside || w.shouldExclude(relPath) || w.isGitignored(event.Name, relPath) {
+					continue
+				}
 			}
 
 			// Convert operation
@@ -225,6 +276,29 @@ func (w *FSWatcher) shouldExclude(relPath string) bool {
 	return false
 }
 
+// loadGitignore (re)reads the .gitignore files below sourceDir.
+func (w *FSWatcher) loadGitignore(sourceDir string) error {
+	ignored, err := gitignore.Load(sourceDir)
+	if err != nil {
+		return fmt.Errorf("failed to read .gitignore files: %w", err)
+	}
+	w.ignored = ignored
+	return nil
+}
+
+// isGitignored checks a changed path against the .gitignore rules.
+// A removed path can no longer be stat'ed, so it is ignored if it
+// matches either as a file or as a directory.
+func (w *FSWatcher) isGitignored(absPath, relPath string) bool {
+	if w.ignored == nil {
+		return false
+	}
+	if info, err := os.Stat(absPath); err == nil {
+		return w.ignored.Match(relPath, info.IsDir())
+	}
+	return w.ignored.Match(relPath, false) || w.ignored.Match(relPath, true)
+}
+
 // opToString converts fsnotify operation to string.
 func (w *FSWatcher) opToString(op fsnotify.Op) string {
 	switch {
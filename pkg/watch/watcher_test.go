@@ -52,6 +52,43 @@ func TestFSWatcher_DetectsFileChange(t *testing.T) {
 	}
 }
 
+func TestFSWatcher_ExtraFiles(t *testing.T) {
+	root := t.TempDir()
+	contextDir := filepath.Join(root, "services", "api")
+	os.MkdirAll(contextDir, 0755)
+	dockerfile := filepath.Join(root, "Dockerfile")
+	os.WriteFile(dockerfile, []byte("FROM golang:1.22"), 0644)
+
+	watcher, err := NewFSWatcher(nil, &util.MockLogger{})
+	if err != nil {
+		t.Fatalf("NewFSWatcher failed: %v", err)
+	}
+	defer watcher.Close()
+	watcher.WithExtraFiles(dockerfile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := watcher.Watch(ctx, contextDir)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// Other files next to the Dockerfile are not watched
+	time.Sleep(100 * time.Millisecond) // Let watcher start
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("# monorepo"), 0644)
+	os.WriteFile(dockerfile, []byte("FROM golang:1.23"), 0644)
+
+	select {
+	case event := <-events:
+		if want := filepath.Join("..", "..", "Dockerfile"); event.Path != want {
+			t.Errorf("path = %s, want %s", event.Path, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+}
+
 func TestFSWatcher_ExcludesGit(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -80,6 +117,39 @@ func TestFSWatcher_ExcludesGit(t *testing.T) {
 	}
 }
 
+func TestFSWatcher_Gitignore(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("bin/\n*.out\n"), 0644)
+	os.Mkdir(filepath.Join(tmpDir, "bin"), 0755)
+
+	watcher, _ := NewFSWatcher(nil, &util.MockLogger{})
+	defer watcher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := watcher.WithGitignore(true).Watch(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// Ignored files first, then a real change: only the latter arrives
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile(filepath.Join(tmpDir, "bin", "app"), []byte("binary"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "report.out"), []byte("out"), 0644)
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644)
+
+	select {
+	case event := <-events:
+		if event.Path != "main.go" {
+			t.Errorf("got event for %s, want main.go", event.Path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+}
+
 func TestFSWatcher_DetectsNewFile(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -9,3 +9,14 @@ var DeploymentTemplate string
 //
 //go:embed service.yaml
 var ServiceTemplate string
+
+// HelmDeploymentTemplate is the Deployment template written by 'kudev export --format helm'.
+// It is copied verbatim into the chart; Helm renders it, not kudev.
+//
+//go:embed helm/deployment.yaml
+var HelmDeploymentTemplate string
+
+// HelmServiceTemplate is the Service template written by 'kudev export --format helm'.
+//
+//go:embed helm/service.yaml
+var HelmServiceTemplate string
@@ -13,28 +13,65 @@ import (
 )
 
 type testTemplateData struct {
-	AppName     string
-	Namespace   string
-	ImageRef    string
-	ImageHash   string
-	Replicas    int32
-	ServicePort int32
-	Env         []testEnvVar
+	AppName       string
+	ContainerName string
+	Namespace     string
+	ImageRef      string
+	ImageHash     string
+	Replicas      int32
+	ServicePort   int32
+	Env           []testEnvVar
+	Instance      string
+	Name          string
+
+	ZeroDowntime bool
+	Color        string
+	Resources    testResources
+
+	LivenessProbe  *testProbe
+	ReadinessProbe *testProbe
+}
+
+type testProbe struct {
+	Path                string
+	Port                int32
+	InitialDelaySeconds int32
+	PeriodSeconds       int32
+}
+
+type testResources struct {
+	Requests testResourceValues
+	Limits   testResourceValues
+}
+
+type testResourceValues struct {
+	CPU    string
+	Memory string
 }
 
 type testEnvVar struct {
-	Name  string
-	Value string
+	Name            string
+	Value           string
+	ConfigMapKeyRef *testKeyRef
+	SecretKeyRef    *testKeyRef
+}
+
+type testKeyRef struct {
+	Name     string
+	Key      string
+	Optional bool
 }
 
 func TestDeploymentTemplateValid(t *testing.T) {
 	data := testTemplateData{
-		AppName:     "test-app",
-		Namespace:   "test-ns",
-		ImageRef:    "test-app:kudev-12345678",
-		ImageHash:   "12345678",
-		Replicas:    1,
-		ServicePort: 8080,
+		AppName:       "test-app",
+		ContainerName: "web",
+		Name:          "test",
+		Namespace:     "test-ns",
+		ImageRef:      "test-app:kudev-12345678",
+		ImageHash:     "12345678",
+		Replicas:      1,
+		ServicePort:   8080,
 	}
 
 	tpl, err := template.New("deployment").Parse(DeploymentTemplate)
@@ -65,13 +102,22 @@ func TestDeploymentTemplateValid(t *testing.T) {
 	if deployment.Labels["managed-by"] != "kudev" {
 		t.Error("missing managed-by label")
 	}
+
+	if deployment.Labels["app.kubernetes.io/name"] != "test" || deployment.Labels["app.kubernetes.io/version"] != "12345678" {
+		t.Errorf("standard labels = %v", deployment.Labels)
+	}
+
+	if name := deployment.Spec.Template.Spec.Containers[0].Name; name != "web" {
+		t.Errorf("container name = %q, want %q", name, "web")
+	}
 }
 
 func TestServiceTemplateValid(t *testing.T) {
 	data := testTemplateData{
-		AppName:     "test-app",
-		Namespace:   "test-ns",
-		ServicePort: 8080,
+		AppName:       "test-app",
+		ContainerName: "test-app",
+		Namespace:     "test-ns",
+		ServicePort:   8080,
 	}
 
 	tpl, err := template.New("service").Parse(ServiceTemplate)
@@ -100,15 +146,18 @@ func TestServiceTemplateValid(t *testing.T) {
 
 func TestDeploymentTemplateWithEnv(t *testing.T) {
 	data := testTemplateData{
-		AppName:     "test-app",
-		Namespace:   "default",
-		ImageRef:    "test-app:latest",
-		ImageHash:   "12345678",
-		Replicas:    1,
-		ServicePort: 8080,
+		AppName:       "test-app",
+		ContainerName: "test-app",
+		Namespace:     "default",
+		ImageRef:      "test-app:latest",
+		ImageHash:     "12345678",
+		Replicas:      1,
+		ServicePort:   8080,
 		Env: []testEnvVar{
 			{Name: "LOG_LEVEL", Value: "debug"},
 			{Name: "DATABASE_URL", Value: "postgres://localhost/db"},
+			{Name: "FEATURE", ConfigMapKeyRef: &testKeyRef{Name: "flags", Key: "feature"}},
+			{Name: "DB_PASSWORD", SecretKeyRef: &testKeyRef{Name: "db-credentials", Key: "password", Optional: true}},
 		},
 	}
 
@@ -128,8 +177,103 @@ func TestDeploymentTemplateWithEnv(t *testing.T) {
 	}
 
 	envVars := deployment.Spec.Template.Spec.Containers[0].Env
-	if len(envVars) != 2 {
-		t.Errorf("expected 2 env vars, got %d", len(envVars))
+	if len(envVars) != 4 {
+		t.Fatalf("expected 4 env vars, got %d", len(envVars))
+	}
+	if ref := envVars[2].ValueFrom; ref == nil || ref.ConfigMapKeyRef == nil || ref.ConfigMapKeyRef.Key != "feature" {
+		t.Errorf("FEATURE should come from configmap flags/feature, got %+v", envVars[2])
+	}
+	ref := envVars[3].ValueFrom
+	if ref == nil || ref.SecretKeyRef == nil || ref.SecretKeyRef.Name != "db-credentials" {
+		t.Fatalf("DB_PASSWORD should come from secret db-credentials, got %+v", envVars[3])
+	}
+	if ref.SecretKeyRef.Optional == nil || !*ref.SecretKeyRef.Optional {
+		t.Error("DB_PASSWORD should be optional")
+	}
+}
+
+func TestDeploymentTemplateWithResources(t *testing.T) {
+	data := testTemplateData{
+		AppName:       "test-app",
+		ContainerName: "test-app",
+		Namespace:     "default",
+		ImageRef:      "test-app:latest",
+		ImageHash:     "12345678",
+		Replicas:      1,
+		ServicePort:   8080,
+		Resources: testResources{
+			Requests: testResourceValues{CPU: "250m", Memory: "256Mi"},
+			Limits:   testResourceValues{Memory: "1Gi"},
+		},
+	}
+
+	tpl, err := template.New("deployment").Parse(DeploymentTemplate)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		t.Fatalf("failed to execute template: %v", err)
+	}
+
+	var deployment appsv1.Deployment
+	if err := yaml.Unmarshal(buf.Bytes(), &deployment); err != nil {
+		t.Fatalf("invalid deployment YAML: %v", err)
+	}
+
+	resources := deployment.Spec.Template.Spec.Containers[0].Resources
+	if got := resources.Requests.Cpu().String(); got != "250m" {
+		t.Errorf("requests.cpu = %s, want 250m", got)
+	}
+	if got := resources.Requests.Memory().String(); got != "256Mi" {
+		t.Errorf("requests.memory = %s, want 256Mi", got)
+	}
+	if got := resources.Limits.Memory().String(); got != "1Gi" {
+		t.Errorf("limits.memory = %s, want 1Gi", got)
+	}
+	if _, ok := resources.Limits[corev1.ResourceCPU]; ok {
+		t.Error("empty limits.cpu should not be rendered")
+	}
+}
+
+func TestDeploymentTemplateWithProbes(t *testing.T) {
+	data := testTemplateData{
+		AppName:        "test-app",
+		ContainerName:  "test-app",
+		Namespace:      "default",
+		ImageRef:       "test-app:latest",
+		ImageHash:      "12345678",
+		Replicas:       1,
+		ServicePort:    8080,
+		ReadinessProbe: &testProbe{Path: "/healthz", Port: 8080, PeriodSeconds: 5},
+	}
+
+	tpl, err := template.New("deployment").Parse(DeploymentTemplate)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		t.Fatalf("failed to execute template: %v", err)
+	}
+
+	var deployment appsv1.Deployment
+	if err := yaml.Unmarshal(buf.Bytes(), &deployment); err != nil {
+		t.Fatalf("invalid deployment YAML: %v", err)
+	}
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.LivenessProbe != nil {
+		t.Error("liveness probe should not be rendered when unset")
+	}
+	probe := container.ReadinessProbe
+	if probe == nil || probe.HTTPGet == nil {
+		t.Fatal("readiness probe not rendered")
+	}
+	if probe.HTTPGet.Path != "/healthz" || probe.HTTPGet.Port.IntValue() != 8080 || probe.PeriodSeconds != 5 {
+		t.Errorf("readiness probe = %+v", probe)
 	}
 }
 
@@ -142,3 +286,13 @@ func TestTemplatesAreEmbedded(t *testing.T) {
 		t.Error("ServiceTemplate is empty")
 	}
 }
+
+func TestHelmTemplatesAreEmbedded(t *testing.T) {
+	if HelmDeploymentTemplate == "" {
+		t.Error("HelmDeploymentTemplate is empty")
+	}
+
+	if HelmServiceTemplate == "" {
+		t.Error("HelmServiceTemplate is empty")
+	}
+}
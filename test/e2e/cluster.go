@@ -0,0 +1,225 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/nanaki-93/kudev/pkg/config"
+	"github.com/nanaki-93/kudev/pkg/deployer"
+	"github.com/nanaki-93/kudev/pkg/kubeconfig"
+	"github.com/nanaki-93/kudev/templates"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+// Environment variables configuring the suite.
+const (
+	// EnvKindCluster names a kind cluster to run against (see StartKind).
+	EnvKindCluster = "KUDEV_E2E_KIND"
+
+	// EnvKeepCluster keeps a kind cluster created by the suite.
+	EnvKeepCluster = "KUDEV_E2E_KEEP"
+
+	// EnvImage overrides DefaultImage.
+	EnvImage = "KUDEV_E2E_IMAGE"
+)
+
+// DefaultImage is the image DeployApp runs. It must serve HTTP on
+// ImagePort and log to stdout.
+const DefaultImage = "nginx:1.27-alpine"
+
+// ImagePort is the port the image serves on.
+const ImagePort int32 = 80
+
+// ReadyTimeout bounds waiting for a deployed app (image pulls included).
+const ReadyTimeout = 3 * time.Minute
+
+// Cluster is the cluster the suite runs against.
+type Cluster struct {
+	// Context is the kubeconfig context in use.
+	Context string
+
+	Clientset  kubernetes.Interface
+	RestConfig *rest.Config
+	Dynamic    dynamic.Interface
+}
+
+var (
+	connectOnce sync.Once
+	connected   *Cluster
+	connectErr  error
+)
+
+// Connect returns the test cluster. The test is skipped when no local
+// cluster is reachable.
+func Connect(t testing.TB) *Cluster {
+	t.Helper()
+	connectOnce.Do(func() {
+		connected, connectErr = connect()
+	})
+	if connectErr != nil {
+		t.Skipf("no cluster for e2e tests: %v", connectErr)
+	}
+	return connected
+}
+
+// connect checks the current context is local and reachable.
+func connect() (*Cluster, error) {
+	validator, err := kubeconfig.NewContextValidator(false)
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(); err != nil {
+		return nil, err
+	}
+
+	restConfig, err := kubeconfig.LoadRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := clientset.Discovery().ServerVersion(); err != nil {
+		return nil, fmt.Errorf("cluster %s unreachable: %w", validator.CurrentContext, err)
+	}
+
+	return &Cluster{
+		Context:    validator.CurrentContext,
+		Clientset:  clientset,
+		RestConfig: restConfig,
+		Dynamic:    dynamicClient,
+	}, nil
+}
+
+// Namespace creates a namespace for the test, deleted when it ends.
+func (c *Cluster) Namespace(t testing.TB) string {
+	t.Helper()
+	name := "kudev-e2e-" + randomSuffix()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{"managed-by": "kudev"},
+	}}
+	if _, err := c.Clientset.CoreV1().Namespaces().Create(context.Background(), ns, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create namespace %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		_ = c.Clientset.CoreV1().Namespaces().Delete(context.Background(), name, metav1.DeleteOptions{})
+	})
+	return name
+}
+
+// Deployer returns a deployer for the cluster, with cleanup of the extra
+// resource types enabled.
+func (c *Cluster) Deployer(t testing.TB) *deployer.KubernetesDeployer {
+	t.Helper()
+	renderer, err := deployer.NewRenderer(templates.DeploymentTemplate, templates.ServiceTemplate)
+	if err != nil {
+		t.Fatalf("failed to create renderer: %v", err)
+	}
+	dep := deployer.NewKubernetesDeployer(c.Clientset, renderer, &util.MockLogger{})
+	dep.SetDynamicClient(c.Dynamic)
+	return dep
+}
+
+// AppConfig returns the config of an app serving Image in a new namespace,
+// with a free local port.
+func (c *Cluster) AppConfig(t testing.TB, name string) *config.DeploymentConfig {
+	t.Helper()
+	cfg := config.NewDeploymentConfig(name)
+	cfg.Spec.Namespace = c.Namespace(t)
+	cfg.Spec.ServicePort = ImagePort
+	cfg.Spec.LocalPort = FreePort(t)
+	return cfg
+}
+
+// DeployApp deploys cfg with Image, waits until it is ready and deletes
+// it when the test ends.
+func (c *Cluster) DeployApp(t testing.TB, cfg *config.DeploymentConfig) *deployer.KubernetesDeployer {
+	t.Helper()
+	ctx := context.Background()
+	dep := c.Deployer(t)
+
+	if _, err := dep.Upsert(ctx, deployer.DeploymentOptions{
+		Config:    cfg,
+		ImageRef:  Image(),
+		ImageHash: "e2e",
+	}); err != nil {
+		t.Fatalf("failed to deploy %s: %v", cfg.Metadata.Name, err)
+	}
+	t.Cleanup(func() {
+		_ = dep.Delete(context.Background(), cfg.Metadata.Name, cfg.Spec.Namespace)
+	})
+
+	if err := dep.WaitForReady(ctx, deployer.WaitOptions{
+		AppName:   cfg.Metadata.Name,
+		Namespace: cfg.Spec.Namespace,
+		Timeout:   ReadyTimeout,
+	}); err != nil {
+		t.Fatalf("%s not ready: %v", cfg.Metadata.Name, err)
+	}
+	return dep
+}
+
+// Image returns the image apps are deployed with.
+func Image() string {
+	if image := os.Getenv(EnvImage); image != "" {
+		return image
+	}
+	return DefaultImage
+}
+
+// FreePort returns a local TCP port that is free right now.
+func FreePort(t testing.TB) int32 {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer l.Close()
+	return int32(l.Addr().(*net.TCPAddr).Port)
+}
+
+// Eventually polls cond until it returns true or timeout, then fails the
+// test with the last error.
+func Eventually(t testing.TB, timeout time.Duration, cond func() (bool, error)) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		ok, err := cond()
+		if ok {
+			return
+		}
+		lastErr = err
+		time.Sleep(500 * time.Millisecond)
+	}
+	t.Fatalf("condition not met within %s (last error: %v)", timeout, lastErr)
+}
+
+// randomSuffix returns 8 random hex characters.
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
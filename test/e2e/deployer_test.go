@@ -0,0 +1,54 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nanaki-93/kudev/pkg/deployer"
+)
+
+func TestDeployer_Lifecycle(t *testing.T) {
+	cluster := Connect(t)
+	cfg := cluster.AppConfig(t, "lifecycle")
+	dep := cluster.DeployApp(t, cfg)
+	ctx := context.Background()
+
+	status, err := dep.Status(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.Status != deployer.StatusRunning.String() || status.ReadyReplicas != 1 {
+		t.Errorf("status = %s (%d/%d ready), want Running 1/1", status.Status, status.ReadyReplicas, status.DesiredReplicas)
+	}
+
+	// Scaling goes through an update of the existing Deployment
+	cfg.Spec.Replicas = 2
+	cluster.DeployApp(t, cfg)
+	status, err = dep.Status(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.DesiredReplicas != 2 {
+		t.Errorf("desired replicas = %d, want 2", status.DesiredReplicas)
+	}
+
+	if err := dep.Delete(ctx, cfg.Metadata.Name, cfg.Spec.Namespace); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := dep.WaitForDeletion(ctx, deployer.WaitOptions{
+		AppName:   cfg.Metadata.Name,
+		Namespace: cfg.Spec.Namespace,
+		Timeout:   ReadyTimeout,
+	}); err != nil {
+		t.Fatalf("WaitForDeletion() error = %v", err)
+	}
+	resources, err := dep.ListApp(ctx, cfg.Metadata.Name, cfg.Spec.Namespace)
+	if err != nil {
+		t.Fatalf("ListApp() error = %v", err)
+	}
+	if len(resources) != 0 {
+		t.Errorf("left after Delete: %v", resources)
+	}
+}
@@ -0,0 +1,20 @@
+// Package e2e runs kudev's deployer, port forwarding and log streaming
+// against a real cluster. The suite is behind the e2e build tag:
+//
+//	KUDEV_E2E_KIND=kudev-e2e go test -tags e2e ./test/e2e/...
+//
+// With KUDEV_E2E_KIND the named kind cluster is created when missing (and
+// deleted afterwards unless KUDEV_E2E_KEEP is set). Without it the current
+// kubeconfig context is used, which must be a local one (docker-desktop,
+// minikube, kind-*); tests are skipped when no cluster is reachable.
+// envtest is not supported: it runs no kubelet, so pods never start.
+//
+// Feature tests use the helpers of this package:
+//
+//	func TestMyFeature(t *testing.T) {
+//		cluster := e2e.Connect(t)
+//		cfg := cluster.AppConfig(t, "myfeature")
+//		dep := cluster.DeployApp(t, cfg)
+//		...
+//	}
+package e2e
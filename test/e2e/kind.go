@@ -0,0 +1,68 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// StartKind points KUBECONFIG at the kind cluster name, creating it when
+// missing. The returned stop function deletes a cluster it created
+// (unless EnvKeepCluster is set) and removes the kubeconfig.
+func StartKind(name string) (stop func(), err error) {
+	if _, err := exec.LookPath("kind"); err != nil {
+		return nil, fmt.Errorf("%s is set but kind is not installed: %w", EnvKindCluster, err)
+	}
+
+	clusters, err := exec.Command("kind", "get", "clusters").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kind clusters: %w", err)
+	}
+	created := false
+	if !containsLine(string(clusters), name) {
+		fmt.Fprintf(os.Stderr, "creating kind cluster %s...\n", name)
+		cmd := exec.Command("kind", "create", "cluster", "--name", name, "--wait", "2m")
+		cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to create kind cluster %s: %w", name, err)
+		}
+		created = true
+	}
+
+	dir, err := os.MkdirTemp("", "kudev-e2e")
+	if err != nil {
+		return nil, err
+	}
+	kubeconfigPath := filepath.Join(dir, "kubeconfig")
+	out, err := exec.Command("kind", "get", "kubeconfig", "--name", name).Output()
+	if err == nil {
+		err = os.WriteFile(kubeconfigPath, out, 0600)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to get kubeconfig of kind cluster %s: %w", name, err)
+	}
+	os.Setenv("KUBECONFIG", kubeconfigPath)
+
+	return func() {
+		os.RemoveAll(dir)
+		if created && os.Getenv(EnvKeepCluster) == "" {
+			fmt.Fprintf(os.Stderr, "deleting kind cluster %s...\n", name)
+			_ = exec.Command("kind", "delete", "cluster", "--name", name).Run()
+		}
+	}, nil
+}
+
+// containsLine reports whether text has a line equal to line.
+func containsLine(text, line string) bool {
+	for _, l := range strings.Split(text, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/logs"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+// syncBuffer is a bytes.Buffer safe for the tailer's goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogs_TailLogs(t *testing.T) {
+	cluster := Connect(t)
+	cfg := cluster.AppConfig(t, "logs")
+	cluster.DeployApp(t, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out syncBuffer
+	tailer := logs.NewKubernetesLogTailer(cluster.Clientset, &util.MockLogger{}, &out)
+	tailer.SetTailLines(-1)
+	tailer.SetColor(false)
+	go func() { _ = tailer.TailLogs(ctx, cfg.Metadata.Name, cfg.Spec.Namespace) }()
+
+	// The image logs on startup
+	Eventually(t, 30*time.Second, func() (bool, error) {
+		if out.String() == "" {
+			return false, fmt.Errorf("no log output yet")
+		}
+		return true, nil
+	})
+}
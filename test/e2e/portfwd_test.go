@@ -0,0 +1,43 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nanaki-93/kudev/pkg/portfwd"
+	"github.com/nanaki-93/kudev/test/util"
+)
+
+func TestPortForward(t *testing.T) {
+	cluster := Connect(t)
+	cfg := cluster.AppConfig(t, "portfwd")
+	cluster.DeployApp(t, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pf := portfwd.NewKubernetesPortForwarder(cluster.Clientset, cluster.RestConfig, &util.MockLogger{})
+	pf.Address = "127.0.0.1"
+	if err := pf.Forward(ctx, cfg.Metadata.Name, cfg.Spec.Namespace, cfg.Spec.LocalPort, ImagePort); err != nil {
+		t.Fatalf("Forward() error = %v", err)
+	}
+	defer pf.Stop()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/", cfg.Spec.LocalPort)
+	Eventually(t, 30*time.Second, func() (bool, error) {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return false, fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		return true, nil
+	})
+}
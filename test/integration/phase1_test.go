@@ -163,7 +163,7 @@ func TestPhase1_DefaultsApplied(t *testing.T) {
 		{"Replicas", cfg.Spec.Replicas, int32(1)},
 		{"LocalPort", cfg.Spec.LocalPort, int32(8080)},
 		{"ServicePort", cfg.Spec.ServicePort, int32(8080)},
-		{"APIVersion", cfg.APIVersion, "kudev.io/v1alpha1"},
+		{"APIVersion", cfg.APIVersion, "kudev.io/v1alpha2"},
 		{"Kind", cfg.Kind, "DeploymentConfig"},
 	}
 